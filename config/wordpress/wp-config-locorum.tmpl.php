@@ -16,11 +16,46 @@ if ( ! empty( $_SERVER['HTTP_X_FORWARDED_HOST'] ) && empty( $_SERVER['HTTP_HOST'
 	$_SERVER['HTTP_HOST'] = $_SERVER['HTTP_X_FORWARDED_HOST'];
 }
 
+{{- if .SQLite }}
+
+// ── Database (SQLite) ───────────────────────────────────────────────────
+// Served by the SQLite Database Integration drop-in (wp-content/db.php).
+// The file lives on the site's database volume, mounted into the PHP
+// container; there is no database server.
+if ( ! defined( 'DB_ENGINE' ) )   define( 'DB_ENGINE',   'sqlite' );
+if ( ! defined( 'DB_DIR' ) )      define( 'DB_DIR',      '{{ .SQLiteDir }}/' );
+if ( ! defined( 'DB_FILE' ) )     define( 'DB_FILE',     '{{ .SQLiteFile }}' );
+if ( ! defined( 'DB_NAME' ) )     define( 'DB_NAME',     'wordpress' );
+if ( ! defined( 'DB_USER' ) )     define( 'DB_USER',     '' );
+if ( ! defined( 'DB_PASSWORD' ) ) define( 'DB_PASSWORD', '' );
+if ( ! defined( 'DB_HOST' ) )     define( 'DB_HOST',     '' );
+{{- else }}
+
 // ── Database credentials ────────────────────────────────────────────────
 if ( ! defined( 'DB_NAME' ) )     define( 'DB_NAME',     'wordpress' );
 if ( ! defined( 'DB_USER' ) )     define( 'DB_USER',     'wordpress' );
 if ( ! defined( 'DB_PASSWORD' ) ) define( 'DB_PASSWORD', getenv( 'MYSQL_PASSWORD' ) ?: '{{ phpEscape .DBPassword }}' );
 if ( ! defined( 'DB_HOST' ) )     define( 'DB_HOST',     'database' );
+{{- end }}
+
+{{- if or (eq .CacheBackend "redis") (eq .CacheBackend "valkey") }}
+
+// ── Object cache (Redis protocol) ───────────────────────────────────────
+// Read by Redis object-cache drop-ins (Redis Object Cache, Object Cache
+// Pro). Valkey speaks the same protocol, so they work against it too.
+if ( ! defined( 'WP_REDIS_HOST' ) ) define( 'WP_REDIS_HOST', '{{ .CacheHost }}' );
+if ( ! defined( 'WP_REDIS_PORT' ) ) define( 'WP_REDIS_PORT', {{ .CachePort }} );
+{{- else if eq .CacheBackend "memcached" }}
+
+// ── Object cache (Memcached) ────────────────────────────────────────────
+// Read by Memcached object-cache drop-ins. Set $memcached_servers in
+// wp-config.php before the require_once line to override. Declared
+// global because WP-CLI evaluates wp-config inside a function.
+global $memcached_servers;
+if ( empty( $memcached_servers ) ) {
+	$memcached_servers = array( array( '{{ .CacheHost }}', {{ .CachePort }} ) );
+}
+{{- end }}
 
 // ── URLs ────────────────────────────────────────────────────────────────
 // Baked in by Locorum at site-start time (internal/sites/wpconfig.go,
@@ -45,15 +80,19 @@ if ( ! defined( 'DB_HOST' ) )     define( 'DB_HOST',     'database' );
 //   1. exact match against the primary domain (case-insensitive);
 //   2. regex match against the per-slug LAN suffix (empty when LAN
 //      access is disabled for this site, or unsupported for the site
-//      type — currently subdomain multisite).
+//      type — currently subdomain multisite);
+//   3. regex match against the site's configured aliases (empty when
+//      it has none).
 // Anything else falls back to the baked primary URL — a malformed
 // `Host:` cannot redirect users off-platform.
 $locorum_primary_host = '{{ phpEscape .PrimaryHost }}';
 $locorum_lan_regex    = '{{ phpEscape .LANHostRegex }}';
+$locorum_alias_regex  = '{{ phpEscape .AliasRegex }}';
 $locorum_request_host = isset( $_SERVER['HTTP_HOST'] ) ? strtolower( (string) $_SERVER['HTTP_HOST'] ) : '';
 $locorum_proto        = ( ! empty( $_SERVER['HTTPS'] ) && 'off' !== $_SERVER['HTTPS'] ) ? 'https' : 'http';
 $locorum_host_allowed = ( $locorum_request_host === $locorum_primary_host )
-	|| ( '' !== $locorum_lan_regex && 1 === preg_match( $locorum_lan_regex, $locorum_request_host ) );
+	|| ( '' !== $locorum_lan_regex && 1 === preg_match( $locorum_lan_regex, $locorum_request_host ) )
+	|| ( '' !== $locorum_alias_regex && 1 === preg_match( $locorum_alias_regex, $locorum_request_host ) );
 if ( $locorum_host_allowed ) {
 	$locorum_home = $locorum_proto . '://' . $locorum_request_host;
 } else {
@@ -61,7 +100,7 @@ if ( $locorum_host_allowed ) {
 }
 if ( ! defined( 'WP_HOME' ) )    define( 'WP_HOME',    $locorum_home );
 if ( ! defined( 'WP_SITEURL' ) ) define( 'WP_SITEURL', $locorum_home . '{{ phpEscape .DocrootSuffix }}' );
-unset( $locorum_primary_host, $locorum_lan_regex, $locorum_request_host, $locorum_proto, $locorum_host_allowed, $locorum_home );
+unset( $locorum_primary_host, $locorum_lan_regex, $locorum_alias_regex, $locorum_request_host, $locorum_proto, $locorum_host_allowed, $locorum_home );
 
 // ── Debug ───────────────────────────────────────────────────────────────
 if ( ! defined( 'WP_DEBUG' ) )         define( 'WP_DEBUG',         true );
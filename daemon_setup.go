@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,7 +13,10 @@ import (
 	application "github.com/PeterBooker/locorum/internal/app"
 	"github.com/PeterBooker/locorum/internal/cli"
 	"github.com/PeterBooker/locorum/internal/daemon"
+	"github.com/PeterBooker/locorum/internal/devdns"
 	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/health"
+	"github.com/PeterBooker/locorum/internal/router/traefik"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/version"
 )
@@ -63,7 +67,10 @@ func isDaemonMode() bool {
 // window to open if the user can't run multiple Locorum at once on a
 // shared machine), but are fatal in daemon mode (no IPC = nothing for
 // the CLI to talk to).
-func startDaemonServices(ctx context.Context, homeDir string, sm *sites.SiteManager) (*daemon.Lock, *daemon.Server, error) {
+//
+// runner may be nil (health checks disabled); otherwise its findings
+// feed events.subscribe alongside the site lifecycle callbacks.
+func startDaemonServices(ctx context.Context, homeDir string, sm *sites.SiteManager, runner *health.Runner) (*daemon.Lock, *daemon.Server, error) {
 	if err := daemon.EnsureStateDir(homeDir); err != nil {
 		return nil, nil, err
 	}
@@ -81,6 +88,10 @@ func startDaemonServices(ctx context.Context, homeDir string, sm *sites.SiteMana
 
 	srv := daemon.NewServer(ln, slog.With("subsys", "ipc"))
 	daemon.RegisterMethods(srv, sm)
+	srv.Events().AttachSites(sm)
+	if runner != nil {
+		srv.Events().AttachHealth(runner)
+	}
 
 	go func() {
 		if err := srv.Serve(ctx); err != nil {
@@ -92,6 +103,31 @@ func startDaemonServices(ctx context.Context, homeDir string, sm *sites.SiteMana
 	return lock, srv, nil
 }
 
+// startDevDNS binds the embedded DNS server when settings turn it on.
+// Returns nil when it is off or the bind fails. A bind failure is only
+// logged: everything else works without the server, and the
+// dns-resolver health check reports it as down.
+func startDevDNS(ctx context.Context, sm *sites.SiteManager) *devdns.Server {
+	cfg := sm.Config()
+	if cfg == nil || !cfg.DNSEnabled() {
+		return nil
+	}
+	addr := cfg.DNSListen()
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		slog.Warn("devdns: bind failed", "addr", addr, "err", err.Error())
+		return nil
+	}
+	srv := devdns.NewServer(conn, sm, slog.With("subsys", "devdns"))
+	go func() {
+		if err := srv.Serve(ctx); err != nil {
+			slog.Warn("devdns: server stopped", "err", err.Error())
+		}
+	}()
+	slog.Info("devdns: serving", "addr", addr, "tld", cfg.DNSTLD())
+	return srv
+}
+
 // runHeadlessDaemon blocks until SIGTERM/SIGINT or ctx cancel. Used in
 // daemon mode where we have no Gio window event loop to keep the
 // process alive.
@@ -113,11 +149,14 @@ func runHeadlessDaemon(ctx context.Context) {
 // Errors during init are fatal here — the daemon has nothing to fall
 // back on, unlike the GUI which still has a usable window if Docker is
 // down.
-func runDaemonMode(homeDir string, sm *sites.SiteManager, a *application.App, d *docker.Docker) {
+//
+// runner is nil when health checks are disabled. Headless, its only
+// consumer is events.subscribe.
+func runDaemonMode(homeDir string, sm *sites.SiteManager, a *application.App, d *docker.Docker, runner *health.Runner) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	lock, srv, err := startDaemonServices(ctx, homeDir, sm)
+	lock, srv, err := startDaemonServices(ctx, homeDir, sm, runner)
 	if err != nil {
 		reportLockError(err)
 		cancel()
@@ -136,6 +175,14 @@ func runDaemonMode(homeDir string, sm *sites.SiteManager, a *application.App, d
 	if err := sm.ReconcileState(); err != nil {
 		slog.Warn("reconcile state failed", "err", err.Error())
 	}
+	if dns := startDevDNS(ctx, sm); dns != nil {
+		defer func() { _ = dns.Close() }()
+	}
+	go sm.WatchRequests(ctx, traefik.ContainerName)
+	go sm.WatchMailRelay(ctx, 5*time.Second)
+	if runner != nil {
+		runner.Start(ctx)
+	}
 
 	slog.Info("daemon ready")
 	runHeadlessDaemon(ctx)
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/PeterBooker/locorum/internal/assets"
+	"github.com/PeterBooker/locorum/internal/dbui"
 	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/router"
 	"github.com/PeterBooker/locorum/internal/utils"
@@ -41,6 +42,10 @@ type App struct {
 	homeDir     string
 	configFiles embed.FS
 
+	// dbuiEngine is the global database UI ("adminer" or
+	// "phpmyadmin"); empty means Adminer.
+	dbuiEngine string
+
 	// provider is the cached Docker daemon identification, populated by
 	// Initialize once Ping has succeeded. Reads via Provider() are
 	// concurrency-safe — pmu in *Docker guards the underlying cache.
@@ -57,6 +62,12 @@ func New(configFiles embed.FS, d *docker.Docker, homeDir string, rtr router.Rout
 	}
 }
 
+// SetDBUIEngine picks the global database UI BringUpGlobals runs. Call
+// before Initialize; the setting is read once per launch.
+func (a *App) SetDBUIEngine(engine string) {
+	a.dbuiEngine = engine
+}
+
 // Initialize runs the startup sequence: filesystem, cleanup, networks,
 // global services, router. Returns the first error encountered so the UI
 // can surface it.
@@ -128,7 +139,7 @@ func (a *App) WipeLabelled(ctx context.Context) error {
 	return nil
 }
 
-// BringUpGlobals creates the global network, brings up mail + adminer,
+// BringUpGlobals creates the global network, brings up mail + the DB UI,
 // and (re)starts the router with the canonical service routes pre-
 // registered. Used at the end of Initialize and from
 // SiteManager.ResetInfrastructure.
@@ -161,8 +172,15 @@ func (a *App) BringUpGlobals(ctx context.Context) error {
 	if err := a.ensureGlobalContainer(ctx, docker.MailSpec()); err != nil {
 		return fmt.Errorf("global mail: %w", err)
 	}
-	if err := a.ensureGlobalContainer(ctx, docker.AdminerSpec()); err != nil {
-		return fmt.Errorf("global adminer: %w", err)
+	key, err := dbui.LoadKey(a.homeDir)
+	if err != nil {
+		return fmt.Errorf("global db ui: %w", err)
+	}
+	if err := dbui.WriteAssets(a.homeDir); err != nil {
+		return fmt.Errorf("global db ui: %w", err)
+	}
+	if err := a.ensureGlobalContainer(ctx, docker.DBUISpec(a.dbuiEngine, a.homeDir, dbui.EncodeKey(key))); err != nil {
+		return fmt.Errorf("global db ui: %w", err)
 	}
 	if err := a.rtr.EnsureRunning(ctx); err != nil {
 		return err
@@ -170,14 +188,19 @@ func (a *App) BringUpGlobals(ctx context.Context) error {
 	if err := a.rtr.UpsertService(ctx, router.ServiceRoute{
 		Name:      "mail",
 		Hostnames: []string{"mail.localhost"},
-		Backend:   "http://locorum-global-mail:8025",
+		Backend:   "http://" + docker.MailContainerName + ":" + strconv.Itoa(docker.MailAPIPort),
 	}); err != nil {
 		return err
 	}
+	// Installs from before the DB UI was selectable routed
+	// db.localhost through a service named "adminer".
+	if err := a.rtr.RemoveService(ctx, "adminer"); err != nil {
+		slog.Debug("removing legacy adminer route", "err", err.Error())
+	}
 	if err := a.rtr.UpsertService(ctx, router.ServiceRoute{
-		Name:      "adminer",
-		Hostnames: []string{"db.localhost"},
-		Backend:   "http://locorum-global-adminer:8080",
+		Name:      "dbui",
+		Hostnames: []string{dbui.Host},
+		Backend:   "http://" + docker.DBUIContainerName + ":" + strconv.Itoa(docker.DBUIPort(a.dbuiEngine)),
 	}); err != nil {
 		return err
 	}
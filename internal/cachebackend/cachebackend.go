@@ -0,0 +1,111 @@
+// Package cachebackend is the per-site object cache choice: Redis,
+// Valkey, Memcached, or no cache at all. It plays the same role for the
+// cache container that internal/dbengine plays for the database — the
+// version lists, default version, port and container spec for each
+// backend are looked up here so callers never switch on the kind
+// themselves.
+package cachebackend
+
+import (
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// Kind is the backend identifier persisted on Site.CacheBackend. Stable
+// string — it is written to the sites table, config.yaml and exports, and
+// doubles as the container's service name.
+type Kind string
+
+const (
+	Redis     Kind = "redis"
+	Valkey    Kind = "valkey"
+	Memcached Kind = "memcached"
+	None      Kind = "none"
+)
+
+// Default is the backend new sites land on when the user accepts the
+// defaults. Redis, matching every site created before the choice existed.
+const Default = Redis
+
+// AllKinds lists every supported backend in stable display order. The UI
+// dropdown reads this so adding a backend touches one file.
+func AllKinds() []Kind { return []Kind{Redis, Valkey, Memcached, None} }
+
+// IsValid reports whether k is a known backend kind.
+func IsValid(k Kind) bool {
+	for _, v := range AllKinds() {
+		if v == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the site's backend, falling back to Default for rows
+// that predate the cache-backend migration or carry an unknown value.
+func Resolve(site *types.Site) Kind {
+	k := Kind(site.CacheBackend)
+	if !IsValid(k) {
+		return Default
+	}
+	return k
+}
+
+// KnownVersions is the ordered (newest first) list shown in the UI
+// dropdown. Empty for None.
+func KnownVersions(k Kind) []string {
+	switch k {
+	case Redis:
+		return []string{"8.0", "7.4", "7.2"}
+	case Valkey:
+		return []string{"8.1", "8.0", "7.2"}
+	case Memcached:
+		return []string{"1.6"}
+	}
+	return nil
+}
+
+// DefaultVersion is the version a new site lands on for k: the newest
+// entry in KnownVersions, or "" for None.
+func DefaultVersion(k Kind) string {
+	if vs := KnownVersions(k); len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
+
+// Port is the backend's TCP port inside its container. Redis and Valkey
+// speak the same protocol on the same port; None has no port.
+func Port(k Kind) int {
+	switch k {
+	case Redis, Valkey:
+		return 6379
+	case Memcached:
+		return 11211
+	}
+	return 0
+}
+
+// Service is the site service name of the cache container — the suffix
+// passed to docker.SiteContainerName and the hostname PHP connects to.
+// Returns "" for None, which has no container.
+func Service(k Kind) string {
+	if k == None {
+		return ""
+	}
+	return string(k)
+}
+
+// ContainerSpec returns the cache container spec for site, or ok=false
+// when the site runs without an object cache.
+func ContainerSpec(site *types.Site) (spec docker.ContainerSpec, ok bool) {
+	switch Resolve(site) {
+	case Redis:
+		return docker.RedisSpec(site), true
+	case Valkey:
+		return docker.ValkeySpec(site), true
+	case Memcached:
+		return docker.MemcachedSpec(site), true
+	}
+	return docker.ContainerSpec{}, false
+}
@@ -0,0 +1,72 @@
+package cachebackend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func TestResolve_FallsBackToDefault(t *testing.T) {
+	for _, raw := range []string{"", "varnish"} {
+		if got := Resolve(&types.Site{CacheBackend: raw}); got != Default {
+			t.Errorf("Resolve(%q) = %q, want %q", raw, got, Default)
+		}
+	}
+	if got := Resolve(&types.Site{CacheBackend: "none"}); got != None {
+		t.Errorf("Resolve(none) = %q", got)
+	}
+}
+
+func TestKnownVersions(t *testing.T) {
+	for _, k := range AllKinds() {
+		vs := KnownVersions(k)
+		if k == None {
+			if len(vs) != 0 || DefaultVersion(k) != "" {
+				t.Errorf("None should have no versions, got %v", vs)
+			}
+			continue
+		}
+		if len(vs) == 0 {
+			t.Errorf("%s: no known versions", k)
+			continue
+		}
+		if DefaultVersion(k) != vs[0] {
+			t.Errorf("%s: DefaultVersion = %q, want newest %q", k, DefaultVersion(k), vs[0])
+		}
+	}
+}
+
+func TestContainerSpec(t *testing.T) {
+	cases := []struct {
+		kind    Kind
+		wantOK  bool
+		service string
+	}{
+		{Redis, true, "redis"},
+		{Valkey, true, "valkey"},
+		{Memcached, true, "memcached"},
+		{None, false, ""},
+	}
+	for _, tc := range cases {
+		site := &types.Site{Slug: "demo", CacheBackend: string(tc.kind), CacheVersion: DefaultVersion(tc.kind)}
+		spec, ok := ContainerSpec(site)
+		if ok != tc.wantOK {
+			t.Errorf("%s: ok = %v, want %v", tc.kind, ok, tc.wantOK)
+			continue
+		}
+		if Service(tc.kind) != tc.service {
+			t.Errorf("%s: Service = %q, want %q", tc.kind, Service(tc.kind), tc.service)
+		}
+		if !ok {
+			continue
+		}
+		if want := docker.SiteContainerName("demo", tc.service); spec.Name != want {
+			t.Errorf("%s: container name = %q, want %q", tc.kind, spec.Name, want)
+		}
+		if !strings.Contains(spec.Image, site.CacheVersion) {
+			t.Errorf("%s: image %q does not carry version %q", tc.kind, spec.Image, site.CacheVersion)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Package captoken holds the named capability tokens that bound what a
+// daemon peer may do. The daemon's profiles (full, readonly) and the
+// single MCP scope are coarse; a token narrows a connection further to
+// a set of methods and a set of sites, until it expires or is revoked.
+// Each agent gets its own token, so each has its own blast radius and
+// its own label in the activity log.
+//
+// Only the SHA-256 of a token's secret is stored. The secret is shown
+// once, by Generate's caller, and is a random 256-bit value, so an
+// unsalted hash is enough to make a leaked database useless.
+//
+// Nothing here talks to storage or the daemon: the storage package
+// persists Token rows and the daemon's dispatcher calls AllowsMethod
+// and AllowsSite on every call.
+package captoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrTokenInvalid is wrapped by every Validate failure so callers can
+// map it to a user-facing "bad input" error.
+var ErrTokenInvalid = errors.New("invalid token")
+
+// Prefix starts every secret, so a token is recognisable in a config
+// file or a secret scanner, and cannot be mistaken for the single
+// shared HTTP MCP token.
+const Prefix = "lct_"
+
+// secretByteLen is the size of the random part of a secret. 32 bytes
+// matches the shared MCP token.
+const secretByteLen = 32
+
+// Profiles a token may carry. They mirror the daemon's wire-stable
+// profile names; the daemon cannot be imported from here.
+const (
+	ProfileFull     = "full"
+	ProfileReadOnly = "readonly"
+)
+
+// Site is one site a token may act on. Both forms are kept so a call
+// can name the site either way; slugs do not change once a site exists.
+type Site struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+}
+
+// Token is one named capability. Allow and Deny hold method patterns
+// matched with path.Match ("site.*", "site.wp_cli", "*"); a method must
+// match an Allow pattern and no Deny pattern. An empty Sites list
+// allows every site. ExpiresAt is RFC 3339, empty for a token that
+// never expires.
+type Token struct {
+	ID         int64    `json:"id"`
+	Label      string   `json:"label"`
+	Hash       string   `json:"-"`
+	Profile    string   `json:"profile"`
+	Allow      []string `json:"allow"`
+	Deny       []string `json:"deny,omitempty"`
+	Sites      []Site   `json:"sites,omitempty"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+}
+
+// Call is one daemon call made with a token, as the dispatcher reports
+// it for the audit trail. SiteID and Slug are whatever the call's
+// params named; Err is the refusal or the method's failure.
+type Call struct {
+	TokenID  int64
+	Label    string
+	Method   string
+	SiteID   string
+	Slug     string
+	ReadOnly bool
+	Err      error
+}
+
+var labelRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// Validate checks a token before it is stored.
+func (t Token) Validate() error {
+	if !labelRe.MatchString(t.Label) {
+		return fmt.Errorf("%w: label %q must be lowercase letters, digits, '.', '-' or '_'", ErrTokenInvalid, t.Label)
+	}
+	if t.Hash == "" {
+		return fmt.Errorf("%w: hash is required", ErrTokenInvalid)
+	}
+	switch t.Profile {
+	case ProfileFull, ProfileReadOnly:
+	default:
+		return fmt.Errorf("%w: unknown profile %q", ErrTokenInvalid, t.Profile)
+	}
+	if len(t.Allow) == 0 {
+		return fmt.Errorf("%w: allow must list at least one method pattern", ErrTokenInvalid)
+	}
+	for _, patterns := range [][]string{t.Allow, t.Deny} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil || p == "" {
+				return fmt.Errorf("%w: bad method pattern %q", ErrTokenInvalid, p)
+			}
+		}
+	}
+	for _, s := range t.Sites {
+		if s.ID == "" || s.Slug == "" {
+			return fmt.Errorf("%w: sites need both id and slug", ErrTokenInvalid)
+		}
+	}
+	if t.ExpiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, t.ExpiresAt); err != nil {
+			return fmt.Errorf("%w: expiresAt %q is not RFC 3339", ErrTokenInvalid, t.ExpiresAt)
+		}
+	}
+	return nil
+}
+
+// AllowsMethod reports whether method may be called with t. Deny wins
+// over Allow.
+func (t Token) AllowsMethod(method string) bool {
+	if matchAny(t.Deny, method) {
+		return false
+	}
+	return matchAny(t.Allow, method)
+}
+
+// AllowsSite reports whether a call naming siteID or slug (either may
+// be empty) is inside t's sites.
+func (t Token) AllowsSite(siteID, slug string) bool {
+	if len(t.Sites) == 0 {
+		return true
+	}
+	for _, s := range t.Sites {
+		if (siteID != "" && siteID == s.ID) || (slug != "" && slug == s.Slug) {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether t has expired at now. An unparsable expiry
+// counts as expired: failing closed beats a token that lives forever.
+func (t Token) Expired(now time.Time) bool {
+	if t.ExpiresAt == "" {
+		return false
+	}
+	exp, err := time.Parse(time.RFC3339, t.ExpiresAt)
+	return err != nil || !now.Before(exp)
+}
+
+func matchAny(patterns []string, method string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate returns a new random secret and its hash. Only the hash is
+// stored; the secret goes to the user once.
+func Generate() (secret, hash string, err error) {
+	buf := make([]byte, secretByteLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate token: %w", err)
+	}
+	secret = Prefix + base64.RawURLEncoding.EncodeToString(buf)
+	return secret, Hash(secret), nil
+}
+
+// Hash returns the stored form of secret: hex SHA-256.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// LooksLikeSecret reports whether s has the shape Generate produces.
+// Used to tell a capability token from the shared MCP token before
+// doing a lookup.
+func LooksLikeSecret(s string) bool {
+	return strings.HasPrefix(s, Prefix) && len(s) > len(Prefix)
+}
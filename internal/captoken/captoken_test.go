@@ -0,0 +1,91 @@
+package captoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func validToken() Token {
+	return Token{Label: "review-bot", Hash: Hash("lct_x"), Profile: ProfileFull, Allow: []string{"*"}}
+}
+
+func TestValidate(t *testing.T) {
+	if err := validToken().Validate(); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	cases := []struct {
+		name string
+		mut  func(*Token)
+	}{
+		{"empty label", func(tk *Token) { tk.Label = "" }},
+		{"label with space", func(tk *Token) { tk.Label = "review bot" }},
+		{"no hash", func(tk *Token) { tk.Hash = "" }},
+		{"bad profile", func(tk *Token) { tk.Profile = "sandbox" }},
+		{"no allow", func(tk *Token) { tk.Allow = nil }},
+		{"bad pattern", func(tk *Token) { tk.Deny = []string{"site.["} }},
+		{"site without slug", func(tk *Token) { tk.Sites = []Site{{ID: "id1"}} }},
+		{"bad expiry", func(tk *Token) { tk.ExpiresAt = "tomorrow" }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tk := validToken()
+			tc.mut(&tk)
+			if err := tk.Validate(); !errors.Is(err, ErrTokenInvalid) {
+				t.Errorf("Validate = %v, want ErrTokenInvalid", err)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tk := Token{
+		Allow: []string{"site.*", "snapshot.list"},
+		Deny:  []string{"site.delete"},
+		Sites: []Site{{ID: "id1", Slug: "shop"}},
+	}
+	for method, want := range map[string]bool{
+		"site.wp_cli":     true,
+		"site.delete":     false,
+		"snapshot.list":   true,
+		"snapshot.create": false,
+		"cert.renew":      false,
+	} {
+		if got := tk.AllowsMethod(method); got != want {
+			t.Errorf("AllowsMethod(%q) = %v, want %v", method, got, want)
+		}
+	}
+	if !tk.AllowsSite("id1", "") || !tk.AllowsSite("", "shop") || tk.AllowsSite("id2", "blog") {
+		t.Error("AllowsSite does not match the site list")
+	}
+	if !(Token{}).AllowsSite("id2", "") {
+		t.Error("a token without sites should allow every site")
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	for exp, want := range map[string]bool{
+		"":                     false,
+		"2026-06-15T13:00:00Z": false,
+		"2026-06-15T12:00:00Z": true,
+		"garbage":              true,
+	} {
+		if got := (Token{ExpiresAt: exp}).Expired(now); got != want {
+			t.Errorf("Expired(%q) = %v, want %v", exp, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	secret, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !LooksLikeSecret(secret) || hash != Hash(secret) || len(hash) != 64 {
+		t.Errorf("Generate = %q, %q", secret, hash)
+	}
+	if LooksLikeSecret("3q2-7w") {
+		t.Error("shared-token shape taken for a capability token")
+	}
+}
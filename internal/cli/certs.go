@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/daemon"
+	"github.com/PeterBooker/locorum/internal/sites"
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
+)
+
+// runCerts dispatches `locorum certs …`, the inventory and renewal of the
+// TLS certificates the router serves.
+func runCerts(ctx context.Context, env *Env) ExitCode {
+	if len(env.Args) == 0 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum certs <list|renew> [args...]")
+		return ExitUsage
+	}
+	verb := env.Args[0]
+	rest := *env
+	rest.Args = env.Args[1:]
+	switch verb {
+	case "list", "ls":
+		return runCertsList(ctx, &rest)
+	case "renew":
+		return runCertsRenew(ctx, &rest)
+	case "help", "-h", "--help":
+		_, _ = fmt.Fprintln(env.Stdout, "certs list [--json]          List issued certificates and their expiry")
+		_, _ = fmt.Fprintln(env.Stdout, "certs renew [--all] [--json] Re-issue certificates that are due (or all of them)")
+		return ExitOK
+	default:
+		_, _ = fmt.Fprintf(env.Stderr, "locorum certs: unknown verb %q\n", verb)
+		return ExitUsage
+	}
+}
+
+func runCertsList(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("certs list", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var inv tlspkg.Inventory
+	if err := cli.Call(ctx, "cert.list", nil, &inv); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		if err := printJSON(env.Stdout, inv); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	now := time.Now()
+	if inv.Root != nil {
+		_, _ = fmt.Fprintf(env.Stdout, "Root CA: %s (expires %s)\n\n", inv.Root.Path, inv.Root.NotAfter.Format(time.DateOnly))
+	} else {
+		_, _ = fmt.Fprintln(env.Stdout, "Root CA: not found")
+		_, _ = fmt.Fprintln(env.Stdout)
+	}
+	tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tEXPIRES\tSTATUS\tHOSTNAMES")
+	for _, c := range inv.Certs {
+		expires := "-"
+		if !c.NotAfter.IsZero() {
+			expires = c.NotAfter.Format(time.DateOnly)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Name, expires, certStatus(c, now), strings.Join(c.Hostnames, ", "))
+	}
+	_ = tw.Flush()
+	return ExitOK
+}
+
+func runCertsRenew(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("certs renew", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	all := fs.Bool("all", false, "re-issue every certificate, not only those due")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 0 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum certs renew [--all] [--json]")
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var res sites.CertRenewResult
+	if err := cli.Call(ctx, "cert.renew", map[string]any{"all": *all}, &res); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		_ = printJSON(env.Stdout, res)
+	} else {
+		if len(res.Renewed) == 0 && len(res.Removed) == 0 && len(res.Failed) == 0 {
+			_, _ = fmt.Fprintln(env.Stdout, "No certificates need renewal.")
+		}
+		for _, name := range res.Renewed {
+			_, _ = fmt.Fprintf(env.Stdout, "renewed  %s\n", name)
+		}
+		for _, name := range res.Removed {
+			_, _ = fmt.Fprintf(env.Stdout, "removed  %s (site not running)\n", name)
+		}
+		failed := make([]string, 0, len(res.Failed))
+		for name := range res.Failed {
+			failed = append(failed, name)
+		}
+		sort.Strings(failed)
+		for _, name := range failed {
+			_, _ = fmt.Fprintf(env.Stderr, "failed   %s: %s\n", name, res.Failed[name])
+		}
+	}
+	if len(res.Failed) > 0 {
+		return ExitError
+	}
+	return ExitOK
+}
+
+// certStatus is the STATUS column for one cert.
+func certStatus(c tlspkg.CertInfo, now time.Time) string {
+	switch {
+	case c.Error != "":
+		return "unreadable"
+	case !c.CurrentRoot:
+		return "old CA"
+	case !c.NotAfter.After(now):
+		return "expired"
+	case c.NeedsRenewal(now):
+		return "due"
+	default:
+		return "ok"
+	}
+}
@@ -73,6 +73,9 @@ var commands = []struct {
 	{"site", "list / describe / start / stop / wp"},
 	{"snapshot", "list / create / restore"},
 	{"hook", "list / run"},
+	{"remote", "list / add / rm SSH remotes for site pull"},
+	{"certs", "list / renew TLS certificates"},
+	{"mail", "list / search / read / purge captured mail"},
 	{"mcp", "MCP server (stdio) for AI agents"},
 	{"daemon", "run a headless daemon (no GUI)"},
 	{"version", "print build identity"},
@@ -105,6 +108,12 @@ func Dispatch(args []string, env *Env) (ExitCode, bool) {
 		return runSnapshot(ctx, &subEnv), true
 	case "hook":
 		return runHook(ctx, &subEnv), true
+	case "remote":
+		return runRemote(ctx, &subEnv), true
+	case "certs":
+		return runCerts(ctx, &subEnv), true
+	case "mail":
+		return runMail(ctx, &subEnv), true
 	case "mcp":
 		return runMCP(ctx, &subEnv), true
 	case "daemon":
@@ -125,7 +134,7 @@ func Dispatch(args []string, env *Env) (ExitCode, bool) {
 // main.go to decide whether to skip Gio bring-up.
 func isCLIVerb(verb string) bool {
 	switch verb {
-	case "site", "snapshot", "hook", "mcp", "daemon", "version", "help",
+	case "site", "snapshot", "hook", "remote", "certs", "mail", "mcp", "daemon", "version", "help",
 		"-h", "--help":
 		return true
 	}
@@ -193,6 +202,20 @@ func dial(ctx context.Context, env *Env, hello daemon.HelloOptions) (*daemon.Cli
 	return cli, nil
 }
 
+// useColour reports whether ANSI sequences may be written to w: a
+// terminal, with NO_COLOR unset.
+func useColour(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
 // errToExit maps an IPC / dial error to a documented exit code so
 // scripts can branch on numeric values without parsing strings.
 func errToExit(err error) ExitCode {
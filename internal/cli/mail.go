@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/daemon"
+	"github.com/PeterBooker/locorum/internal/mail"
+)
+
+// runMail dispatches `locorum mail …`, the captured-mail inbox every
+// site's outgoing mail lands in.
+func runMail(ctx context.Context, env *Env) ExitCode {
+	if len(env.Args) == 0 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mail <list|search|read|purge> [args...]")
+		return ExitUsage
+	}
+	verb := env.Args[0]
+	rest := *env
+	rest.Args = env.Args[1:]
+	switch verb {
+	case "list", "ls":
+		return runMailList(ctx, &rest, false)
+	case "search":
+		return runMailList(ctx, &rest, true)
+	case "read", "show":
+		return runMailRead(ctx, &rest)
+	case "purge":
+		return runMailPurge(ctx, &rest)
+	case "help", "-h", "--help":
+		_, _ = fmt.Fprintln(env.Stdout, "mail list [--site S] [--limit N] [--json]           List captured mail, newest first")
+		_, _ = fmt.Fprintln(env.Stdout, "mail search [--site S] [--limit N] [--json] <query> Search captured mail (e.g. subject:\"Password Reset\")")
+		_, _ = fmt.Fprintln(env.Stdout, "mail read [--site S] [--html] [--json] <id>         Print one message")
+		_, _ = fmt.Fprintln(env.Stdout, "mail purge [--site S] [--query Q] [--all]           Delete captured mail")
+		return ExitOK
+	default:
+		_, _ = fmt.Fprintf(env.Stderr, "locorum mail: unknown verb %q\n", verb)
+		return ExitUsage
+	}
+}
+
+func runMailList(ctx context.Context, env *Env, search bool) ExitCode {
+	name := "mail list"
+	if search {
+		name = "mail search"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	site := fs.String("site", "", "only mail sent by this site (slug or id)")
+	limit := fs.Int("limit", 0, "max messages to return (default 50)")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	query := strings.Join(fs.Args(), " ")
+	if search && strings.TrimSpace(query) == "" {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mail search [--site S] [--limit N] [--json] <query>")
+		return ExitUsage
+	}
+	if !search && fs.NArg() != 0 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mail list [--site S] [--limit N] [--json]")
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	extra := map[string]any{}
+	if query != "" {
+		extra["query"] = query
+	}
+	if *limit > 0 {
+		extra["limit"] = *limit
+	}
+	method := "mail.list"
+	if search {
+		method = "mail.search"
+	}
+	var page mail.Page
+	if err := cli.Call(ctx, method, mailParams(*site, extra), &page); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		if err := printJSON(env.Stdout, page); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+	if len(page.Messages) == 0 {
+		_, _ = fmt.Fprintln(env.Stdout, "No captured mail.")
+		return ExitOK
+	}
+	tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tRECEIVED\tSITE\tFROM\tTO\tSUBJECT")
+	for _, m := range page.Messages {
+		site := m.Site
+		if site == "" {
+			site = "-"
+		}
+		to := make([]string, 0, len(m.To))
+		for _, a := range m.To {
+			to = append(to, a.Address)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			m.ID, m.Created.Local().Format(time.DateTime), site,
+			m.From.Address, strings.Join(to, ", "), m.Subject)
+	}
+	_ = tw.Flush()
+	if page.Total > page.Start+len(page.Messages) {
+		_, _ = fmt.Fprintf(env.Stdout, "\n%d of %d shown; use --limit for more.\n", len(page.Messages), page.Total)
+	}
+	return ExitOK
+}
+
+func runMailRead(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("mail read", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	site := fs.String("site", "", "require the message to come from this site")
+	html := fs.Bool("html", false, "print the HTML body instead of the text body")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mail read [--site S] [--html] [--json] <id>")
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var msg mail.Message
+	if err := cli.Call(ctx, "mail.get", mailParams(*site, map[string]any{"id": fs.Arg(0)}), &msg); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		if err := printJSON(env.Stdout, msg); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	header := func(name string, addrs []mail.Address) {
+		if len(addrs) == 0 {
+			return
+		}
+		parts := make([]string, 0, len(addrs))
+		for _, a := range addrs {
+			parts = append(parts, a.String())
+		}
+		_, _ = fmt.Fprintf(env.Stdout, "%-9s%s\n", name+":", strings.Join(parts, ", "))
+	}
+	header("From", []mail.Address{msg.From})
+	header("To", msg.To)
+	header("Cc", msg.Cc)
+	header("Reply-To", msg.ReplyTo)
+	_, _ = fmt.Fprintf(env.Stdout, "%-9s%s\n", "Subject:", msg.Subject)
+	_, _ = fmt.Fprintf(env.Stdout, "%-9s%s\n", "Date:", msg.Created.Local().Format(time.RFC1123Z))
+	if msg.Site != "" {
+		_, _ = fmt.Fprintf(env.Stdout, "%-9s%s\n", "Site:", msg.Site)
+	}
+	for _, a := range msg.Attachments {
+		_, _ = fmt.Fprintf(env.Stdout, "%-9s%s (%s, %d bytes)\n", "Attached:", a.FileName, a.ContentType, a.Size)
+	}
+	_, _ = fmt.Fprintln(env.Stdout)
+	body := msg.Text
+	if *html || body == "" {
+		body = msg.HTML
+	}
+	_, _ = fmt.Fprintln(env.Stdout, strings.TrimRight(body, "\n"))
+	return ExitOK
+}
+
+func runMailPurge(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("mail purge", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	site := fs.String("site", "", "only mail sent by this site (slug or id)")
+	query := fs.String("query", "", "only mail matching this search")
+	all := fs.Bool("all", false, "empty the whole mailbox")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	filtered := *site != "" || *query != ""
+	if fs.NArg() != 0 || filtered == *all {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mail purge [--site S] [--query Q] | --all")
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	extra := map[string]any{}
+	if *query != "" {
+		extra["query"] = *query
+	}
+	if *all {
+		extra["all"] = true
+	}
+	if err := cli.Call(ctx, "mail.purge", mailParams(*site, extra), nil); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	_, _ = fmt.Fprintln(env.Stdout, "Captured mail purged.")
+	return ExitOK
+}
+
+// mailParams adds the site reference only when one was given; the mail
+// methods treat the site as an optional filter.
+func mailParams(site string, extra map[string]any) map[string]any {
+	if site == "" {
+		return extra
+	}
+	return siteIDParams(site, extra)
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/PeterBooker/locorum/internal/captoken"
 	"github.com/PeterBooker/locorum/internal/daemon"
 	"github.com/PeterBooker/locorum/internal/mcp"
 )
@@ -16,7 +19,7 @@ import (
 // with bearer auth, for remote / multi-agent setups).
 func runMCP(ctx context.Context, env *Env) ExitCode {
 	if len(env.Args) == 0 {
-		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mcp <serve|rotate-token> [flags]")
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mcp <serve|rotate-token|token> [flags]")
 		return ExitUsage
 	}
 	verb := env.Args[0]
@@ -27,15 +30,23 @@ func runMCP(ctx context.Context, env *Env) ExitCode {
 		return runMCPServe(ctx, &rest)
 	case "rotate-token":
 		return runMCPRotateToken(&rest)
+	case "token":
+		return runMCPToken(ctx, &rest)
 	case "help", "-h", "--help":
 		_, _ = fmt.Fprintln(env.Stdout, "mcp serve --stdio [--profile full|readonly]")
 		_, _ = fmt.Fprintln(env.Stdout, "    Run an MCP server on stdin/stdout. Reads LOCORUM_MCP_SCOPE")
-		_, _ = fmt.Fprintln(env.Stdout, "    from env to scope every tool call to a single site (defence-in-depth).")
+		_, _ = fmt.Fprintln(env.Stdout, "    from env to scope every tool call to a single site (defence-in-depth),")
+		_, _ = fmt.Fprintln(env.Stdout, "    and LOCORUM_MCP_TOKEN to act under a capability token.")
 		_, _ = fmt.Fprintln(env.Stdout, "mcp serve --http 127.0.0.1:2484 [--profile full|readonly]")
 		_, _ = fmt.Fprintln(env.Stdout, "    Serve MCP over HTTP. Loopback bind only; bearer-token auth")
-		_, _ = fmt.Fprintln(env.Stdout, "    using the secret in ~/.locorum/state/mcp_token.")
+		_, _ = fmt.Fprintln(env.Stdout, "    using the secret in ~/.locorum/state/mcp_token, or a capability token.")
 		_, _ = fmt.Fprintln(env.Stdout, "mcp rotate-token")
 		_, _ = fmt.Fprintln(env.Stdout, "    Regenerate the HTTP MCP bearer token.")
+		_, _ = fmt.Fprintln(env.Stdout, "mcp token create --label L [--profile full|readonly] [--allow site.*,...] [--deny site.delete,...]")
+		_, _ = fmt.Fprintln(env.Stdout, "                 [--sites slug,...] [--expires 720h]")
+		_, _ = fmt.Fprintln(env.Stdout, "    Create a capability token and print its secret, once.")
+		_, _ = fmt.Fprintln(env.Stdout, "mcp token list [--json]")
+		_, _ = fmt.Fprintln(env.Stdout, "mcp token revoke <label>")
 		return ExitOK
 	default:
 		_, _ = fmt.Fprintf(env.Stderr, "locorum mcp: unknown verb %q\n", verb)
@@ -43,6 +54,11 @@ func runMCP(ctx context.Context, env *Env) ExitCode {
 	}
 }
 
+// mcpTokenEnv carries a capability token into `mcp serve --stdio`.
+// Agents' MCP configs set it per agent, which is what gives each its
+// own grants and its own label in the activity log.
+const mcpTokenEnv = "LOCORUM_MCP_TOKEN"
+
 // runMCPRotateToken regenerates the bearer token used by HTTP MCP and
 // prints the new value. Existing in-flight HTTP MCP servers continue
 // to use the old value until restarted; the user copies the new token
@@ -84,13 +100,14 @@ func runMCPServe(ctx context.Context, env *Env) ExitCode {
 
 	scope := os.Getenv("LOCORUM_MCP_SCOPE")
 
-	// Open the IPC client up front. We pass the profile + scope in
-	// the hello so the daemon enforces both — this server is a thin
-	// shim, the daemon is the security boundary.
+	// Open the IPC client up front. We pass the profile + scope, and
+	// any capability token, in the hello so the daemon enforces them —
+	// this server is a thin shim, the daemon is the security boundary.
 	cli, err := dial(ctx, env, daemon.HelloOptions{
 		PeerKind: "mcp",
 		Profile:  *profile,
 		MCPScope: scope,
+		Token:    os.Getenv(mcpTokenEnv),
 	})
 	if err != nil {
 		// Diagnostics go to stderr — stdout is reserved for MCP frames
@@ -130,8 +147,18 @@ func runMCPServe(ctx context.Context, env *Env) ExitCode {
 		return ExitError
 	}
 	httpSrv, err := mcp.NewHTTPServer(mcp.HTTPOptions{
-		Bind:   *httpBind,
-		Token:  token,
+		Bind:  *httpBind,
+		Token: token,
+		// A request bearing a capability token gets a connection of
+		// its own, so the daemon holds it to that token's grants.
+		DialToken: func(ctx context.Context, secret string) (*daemon.Client, error) {
+			return daemon.DialClient(ctx, daemon.SocketPath(env.HomeDir), daemon.HelloOptions{
+				PeerKind: "mcp",
+				Profile:  *profile,
+				MCPScope: scope,
+				Token:    secret,
+			})
+		},
 		Server: srv,
 		Logger: logger,
 	})
@@ -152,3 +179,155 @@ func runMCPServe(ctx context.Context, env *Env) ExitCode {
 	}
 	return ExitOK
 }
+
+// runMCPToken dispatches `locorum mcp token …`, which manages the
+// named capability tokens agents present to the daemon.
+func runMCPToken(ctx context.Context, env *Env) ExitCode {
+	if len(env.Args) == 0 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mcp token <create|list|revoke> [args...]")
+		return ExitUsage
+	}
+	verb := env.Args[0]
+	rest := *env
+	rest.Args = env.Args[1:]
+	switch verb {
+	case "create":
+		return runMCPTokenCreate(ctx, &rest)
+	case "list", "ls":
+		return runMCPTokenList(ctx, &rest)
+	case "revoke", "rm":
+		return runMCPTokenRevoke(ctx, &rest)
+	default:
+		_, _ = fmt.Fprintf(env.Stderr, "locorum mcp token: unknown verb %q\n", verb)
+		return ExitUsage
+	}
+}
+
+func runMCPTokenCreate(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("mcp token create", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	label := fs.String("label", "", "name shown in the activity log, e.g. ci-agent")
+	profile := fs.String("profile", daemon.ProfileFull, "trust tier: full | readonly")
+	allow := fs.String("allow", "", "comma-separated method patterns to allow (default: all)")
+	deny := fs.String("deny", "", "comma-separated method patterns to deny, e.g. site.delete")
+	siteList := fs.String("sites", "", "comma-separated slugs or ids the token may act on (default: all)")
+	expires := fs.Duration("expires", 0, "lifetime, e.g. 720h (default: never)")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 0 || *label == "" {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mcp token create --label L [--profile P] [--allow A,...] [--deny D,...] [--sites S,...] [--expires 720h]")
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	params := map[string]any{"label": *label, "profile": *profile}
+	if *allow != "" {
+		params["allow"] = strings.Split(*allow, ",")
+	}
+	if *deny != "" {
+		params["deny"] = strings.Split(*deny, ",")
+	}
+	if *siteList != "" {
+		params["sites"] = strings.Split(*siteList, ",")
+	}
+	if *expires != 0 {
+		params["expiresIn"] = expires.String()
+	}
+	var resp struct {
+		Secret string         `json:"secret"`
+		Token  captoken.Token `json:"token"`
+	}
+	if err := cli.Call(ctx, "token.create", params, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	// The secret goes to stdout alone so it can be piped into a secret
+	// store; it is not stored and cannot be shown again.
+	_, _ = fmt.Fprintln(env.Stdout, resp.Secret)
+	_, _ = fmt.Fprintf(env.Stderr, "created token %s; set %s to it in the agent's MCP config\n", resp.Token.Label, mcpTokenEnv)
+	return ExitOK
+}
+
+func runMCPTokenList(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("mcp token list", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var resp struct {
+		Tokens []captoken.Token `json:"tokens"`
+	}
+	if err := cli.Call(ctx, "token.list", nil, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		if err := printJSON(env.Stdout, resp.Tokens); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+	tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "LABEL\tPROFILE\tALLOW\tDENY\tSITES\tEXPIRES\tLAST USED")
+	for _, t := range resp.Tokens {
+		sites := "all"
+		if len(t.Sites) > 0 {
+			slugs := make([]string, len(t.Sites))
+			for i, s := range t.Sites {
+				slugs[i] = s.Slug
+			}
+			sites = strings.Join(slugs, ",")
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Label, t.Profile, strings.Join(t.Allow, ","), orDash(strings.Join(t.Deny, ",")),
+			sites, orDash(t.ExpiresAt), orDash(t.LastUsedAt))
+	}
+	_ = tw.Flush()
+	return ExitOK
+}
+
+func runMCPTokenRevoke(ctx context.Context, env *Env) ExitCode {
+	if len(env.Args) != 1 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum mcp token revoke <label>")
+		return ExitUsage
+	}
+	label := env.Args[0]
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	if err := cli.Call(ctx, "token.revoke", map[string]any{"label": label}, nil); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "revoked token %s\n", label)
+	return ExitOK
+}
+
+// orDash returns s, or "-" for an empty table cell.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/PeterBooker/locorum/internal/daemon"
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/sites"
+)
+
+// remotePasswordEnv carries an SSH password or key passphrase into
+// `remote add` without putting it on the command line, where it would
+// show up in shell history and the process list.
+const remotePasswordEnv = "LOCORUM_REMOTE_PASSWORD"
+
+// runRemote dispatches `locorum remote …`, which manages the SSH
+// remotes `site pull` reads from and `site push` writes to.
+func runRemote(ctx context.Context, env *Env) ExitCode {
+	if len(env.Args) == 0 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum remote <list|add|rm> [args...]")
+		return ExitUsage
+	}
+	verb := env.Args[0]
+	rest := *env
+	rest.Args = env.Args[1:]
+	switch verb {
+	case "list", "ls":
+		return runRemoteList(ctx, &rest)
+	case "add":
+		return runRemoteAdd(ctx, &rest)
+	case "rm", "remove":
+		return runRemoteRemove(ctx, &rest)
+	case "help", "-h", "--help":
+		_, _ = fmt.Fprintln(env.Stdout, "remote list <slug>                       List a site's SSH remotes")
+		_, _ = fmt.Fprintln(env.Stdout, "remote add --name N --host H --user U --path P [--port N] [--key F] [--db-command C] [--production] <slug>")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Add a remote; password or key passphrase via $"+remotePasswordEnv)
+		_, _ = fmt.Fprintln(env.Stdout, "                                         --production remotes refuse `site push`")
+		_, _ = fmt.Fprintln(env.Stdout, "remote rm <slug> <name>                  Remove a remote")
+		return ExitOK
+	default:
+		_, _ = fmt.Fprintf(env.Stderr, "locorum remote: unknown verb %q\n", verb)
+		return ExitUsage
+	}
+}
+
+func runRemoteList(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("remote list", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum remote list <slug>")
+		return ExitUsage
+	}
+	target := fs.Arg(0)
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var resp struct {
+		Remotes []remote.Remote `json:"remotes"`
+	}
+	if err := cli.Call(ctx, "remote.list", siteIDParams(target, nil), &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		if err := printJSON(env.Stdout, resp.Remotes); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+	tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tTARGET\tPRODUCTION\tHOST KEY")
+	for _, r := range resp.Remotes {
+		hostKey := r.HostKey
+		if hostKey == "" {
+			hostKey = "(pinned on first connect)"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n", r.Name, r.String(), r.Production, hostKey)
+	}
+	_ = tw.Flush()
+	return ExitOK
+}
+
+func runRemoteAdd(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("remote add", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	name := fs.String("name", "", "remote name, e.g. staging")
+	host := fs.String("host", "", "SSH host")
+	port := fs.Int("port", 0, "SSH port (default 22)")
+	user := fs.String("user", "", "SSH user")
+	wpPath := fs.String("path", "", "absolute WordPress root on the remote")
+	key := fs.String("key", "", "private key file (default: ssh-agent, then ~/.ssh/id_*)")
+	dbCommand := fs.String("db-command", "", "remote command that prints the SQL dump (default: wp db export)")
+	production := fs.Bool("production", false, "mark as production: pull only, never push")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 || *name == "" || *host == "" || *user == "" || *wpPath == "" {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum remote add --name N --host H --user U --path P [--port N] [--key F] [--db-command C] [--production] <slug>")
+		return ExitUsage
+	}
+	target := fs.Arg(0)
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	params := siteIDParams(target, map[string]any{
+		"name":       *name,
+		"host":       *host,
+		"port":       *port,
+		"user":       *user,
+		"path":       *wpPath,
+		"keyPath":    *key,
+		"password":   os.Getenv(remotePasswordEnv),
+		"dbCommand":  *dbCommand,
+		"production": *production,
+	})
+	var resp struct {
+		Remote remote.Remote `json:"remote"`
+	}
+	if err := cli.Call(ctx, "remote.add", params, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "%s: added remote %s (%s)\n", target, resp.Remote.Name, resp.Remote.String())
+	return ExitOK
+}
+
+func runRemoteRemove(ctx context.Context, env *Env) ExitCode {
+	if len(env.Args) != 2 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum remote rm <slug> <name>")
+		return ExitUsage
+	}
+	target, name := env.Args[0], env.Args[1]
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	if err := cli.Call(ctx, "remote.remove", siteIDParams(target, map[string]any{"name": name}), nil); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "%s: removed remote %s\n", target, name)
+	return ExitOK
+}
+
+// ─── site pull ─────────────────────────────────────────────────────────
+
+func runSitePull(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site pull", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	from := fs.String("from", "", "remote name (see `locorum remote list`)")
+	skipDB := fs.Bool("skip-db", false, "pull uploads only")
+	skipUploads := fs.Bool("skip-uploads", false, "pull the database only")
+	noAuto := fs.Bool("no-search-replace", false, "skip the automatic remote→local URL rewrite")
+	skipSnapshot := fs.Bool("skip-snapshot", false, "don't snapshot the local database first")
+	var replace pairFlags
+	fs.Var(&replace, "replace", "extra search-replace pair FROM=TO (repeatable)")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 || *from == "" {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site pull --from REMOTE [--skip-db|--skip-uploads] [--replace FROM=TO] <slug-or-id>")
+		return ExitUsage
+	}
+	target := fs.Arg(0)
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	params := siteIDParams(target, map[string]any{
+		"remote":        *from,
+		"skipDb":        *skipDB,
+		"skipUploads":   *skipUploads,
+		"searchReplace": []sites.SearchReplacePair(replace),
+		"disableAuto":   *noAuto,
+		"skipSnapshot":  *skipSnapshot,
+	})
+	var resp sites.PullResult
+	if err := cli.Call(ctx, "site.pull", params, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		_ = printJSON(env.Stdout, resp)
+		return ExitOK
+	}
+	if resp.HostKeyPinned {
+		_, _ = fmt.Fprintf(env.Stdout, "Pinned host key %s for %s\n", resp.HostKey, resp.Remote)
+	}
+	if resp.Database {
+		_, _ = fmt.Fprintf(env.Stdout, "%s: database pulled from %s\n", target, resp.Remote)
+	}
+	if !*skipUploads {
+		_, _ = fmt.Fprintf(env.Stdout, "%s: %d uploads (%d bytes) pulled from %s\n", target, resp.UploadFiles, resp.UploadBytes, resp.Remote)
+	}
+	return ExitOK
+}
+
+// ─── site push ─────────────────────────────────────────────────────────
+
+func runSitePush(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site push", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	to := fs.String("to", "", "remote name (see `locorum remote list`)")
+	confirm := fs.String("confirm", "", "the remote's hostname, to confirm the push")
+	acceptKey := fs.String("accept-host-key", "", "pin this host key fingerprint if the remote has none pinned yet")
+	noAuto := fs.Bool("no-search-replace", false, "skip the automatic local→remote URL rewrite")
+	var replace pairFlags
+	fs.Var(&replace, "replace", "extra search-replace pair FROM=TO (repeatable)")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 || *to == "" {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site push --to REMOTE --confirm HOST [--accept-host-key FP] [--replace FROM=TO] <slug-or-id>")
+		return ExitUsage
+	}
+	target := fs.Arg(0)
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	// Show the user exactly what they're about to overwrite before
+	// asking for the hostname; the daemon checks it again regardless.
+	var list struct {
+		Remotes []remote.Remote `json:"remotes"`
+	}
+	if err := cli.Call(ctx, "remote.list", siteIDParams(target, nil), &list); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	var r *remote.Remote
+	for i := range list.Remotes {
+		if list.Remotes[i].Name == *to {
+			r = &list.Remotes[i]
+		}
+	}
+	if r == nil {
+		_, _ = fmt.Fprintf(env.Stderr, "locorum: %s has no remote %q\n", target, *to)
+		return ExitNotFound
+	}
+	if r.Production {
+		_, _ = fmt.Fprintf(env.Stderr, "locorum: %s (%s) is marked production; push refused\n", r.Name, r.String())
+		return ExitConflict
+	}
+	if !strings.EqualFold(*confirm, r.Host) {
+		_, _ = fmt.Fprintf(env.Stderr, "This will REPLACE the database at %s.\n", r.String())
+		if r.HostKey != "" {
+			_, _ = fmt.Fprintf(env.Stderr, "Host key: %s\n", r.HostKey)
+		} else {
+			_, _ = fmt.Fprintln(env.Stderr, "No host key is pinned yet: pull from the remote first, or pass --accept-host-key with the fingerprint you have verified.")
+		}
+		_, _ = fmt.Fprintln(env.Stderr, "The remote database is backed up first. Re-run with --confirm "+r.Host+" to proceed.")
+		return ExitUsage
+	}
+
+	params := siteIDParams(target, map[string]any{
+		"remote":        *to,
+		"confirmHost":   *confirm,
+		"acceptHostKey": *acceptKey,
+		"searchReplace": []sites.SearchReplacePair(replace),
+		"disableAuto":   *noAuto,
+	})
+	var resp sites.PushResult
+	if err := cli.Call(ctx, "site.push", params, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		_ = printJSON(env.Stdout, resp)
+		return ExitOK
+	}
+	if resp.HostKeyPinned {
+		_, _ = fmt.Fprintf(env.Stdout, "Pinned host key %s for %s\n", resp.HostKey, resp.Remote)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "%s: database pushed to %s (remote backup: %s)\n", target, resp.Host, resp.BackupPath)
+	for _, p := range resp.SearchReplace {
+		_, _ = fmt.Fprintf(env.Stdout, "  %s → %s\n", p.From, p.To)
+	}
+	return ExitOK
+}
+
+// pairFlags collects repeatable FROM=TO flags.
+type pairFlags []sites.SearchReplacePair
+
+func (p *pairFlags) String() string { return "" }
+
+func (p *pairFlags) Set(v string) error {
+	from, to, ok := strings.Cut(v, "=")
+	if !ok || from == "" || to == "" {
+		return fmt.Errorf("want FROM=TO, got %q", v)
+	}
+	*p = append(*p, sites.SearchReplacePair{From: from, To: to})
+	return nil
+}
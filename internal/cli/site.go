@@ -2,21 +2,27 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/PeterBooker/locorum/internal/daemon"
+	"github.com/PeterBooker/locorum/internal/mail"
 	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+	"github.com/PeterBooker/locorum/internal/utils"
 )
 
 // runSite is the dispatcher for `locorum site …`. Each verb has its own
 // flag set so adding one doesn't require touching the others.
 func runSite(ctx context.Context, env *Env) ExitCode {
 	if len(env.Args) == 0 {
-		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site <list|describe|start|stop|wp|logs> [args...]")
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site <list|describe|start|stop|import|wp|logs|xdebug|mail|db-ui|access|sync-config|pull|push> [args...]")
 		return ExitUsage
 	}
 	verb := env.Args[0]
@@ -33,12 +39,28 @@ func runSite(ctx context.Context, env *Env) ExitCode {
 		return runSiteToggle(ctx, &rest, "site.stop", "stopped")
 	case "create":
 		return runSiteCreate(ctx, &rest)
+	case "import":
+		return runSiteImport(ctx, &rest)
 	case "delete", "rm":
 		return runSiteDelete(ctx, &rest)
 	case "wp":
 		return runSiteWP(ctx, &rest)
 	case "logs":
 		return runSiteLogs(ctx, &rest)
+	case "xdebug":
+		return runSiteXdebug(ctx, &rest)
+	case "mail":
+		return runSiteMail(ctx, &rest)
+	case "db-ui":
+		return runSiteDBUI(ctx, &rest)
+	case "access":
+		return runSiteAccess(ctx, &rest)
+	case "sync-config":
+		return runSiteSyncConfig(ctx, &rest)
+	case "pull":
+		return runSitePull(ctx, &rest)
+	case "push":
+		return runSitePush(ctx, &rest)
 	case "help", "-h", "--help":
 		_, _ = fmt.Fprintln(env.Stdout, "site list                                List sites")
 		_, _ = fmt.Fprintln(env.Stdout, "site describe <slug-or-id>               Print one site's full state")
@@ -46,10 +68,27 @@ func runSite(ctx context.Context, env *Env) ExitCode {
 		_, _ = fmt.Fprintln(env.Stdout, "site stop <slug-or-id>                   Stop a site")
 		_, _ = fmt.Fprintln(env.Stdout, "site create --name N --git-remote URL --branch B [--clone-db] [--dry-run]")
 		_, _ = fmt.Fprintln(env.Stdout, "                                         Create a worktree-bound site")
+		_, _ = fmt.Fprintln(env.Stdout, "site create --from-config DIR [--with-hooks] [--no-start]")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Create a site from a committed .locorum/config.yaml")
+		_, _ = fmt.Fprintln(env.Stdout, "site import <archive.tar.gz> [--name N] [--files-dir D] [--with-hooks]")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Create a site from an export archive")
 		_, _ = fmt.Fprintln(env.Stdout, "site delete <slug-or-id> [--force] [--purge-volume] [--dry-run]")
 		_, _ = fmt.Fprintln(env.Stdout, "                                         Delete a site")
 		_, _ = fmt.Fprintln(env.Stdout, "site wp <slug-or-id> -- <args...>        Run a wp-cli command")
-		_, _ = fmt.Fprintln(env.Stdout, "site logs <slug-or-id> --service S       Tail container logs")
+		_, _ = fmt.Fprintln(env.Stdout, "site logs [-f] [--service S[,S]] [--since T] [--grep RE] <slug-or-id>")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Print or follow container logs")
+		_, _ = fmt.Fprintln(env.Stdout, "site xdebug <slug-or-id> <mode>          Set Xdebug mode: "+strings.Join(sites.XdebugModes, "|"))
+		_, _ = fmt.Fprintln(env.Stdout, "site mail [--relay-host H] [--relay-user U] [--relay-domains D] <slug-or-id> <"+strings.Join(mail.Modes, "|")+">")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Set where outgoing mail goes; relay password via $"+mailRelayPasswordEnv)
+		_, _ = fmt.Fprintln(env.Stdout, "site db-ui [--open] <slug-or-id>         Print a one-click DB UI login link")
+		_, _ = fmt.Fprintln(env.Stdout, "site access [--auth on|off] [--rotate-password] [--allow CIDRS] [--allow-lan] [--no-allowlist] <slug-or-id>")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Show or change basic auth and the IP allowlist")
+		_, _ = fmt.Fprintln(env.Stdout, "site sync-config [--apply|--discard] <slug-or-id>")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Review or apply hand edits to .locorum/config.yaml")
+		_, _ = fmt.Fprintln(env.Stdout, "site pull --from REMOTE [--skip-db|--skip-uploads] <slug-or-id>")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Pull the database and uploads from an SSH remote")
+		_, _ = fmt.Fprintln(env.Stdout, "site push --to REMOTE --confirm HOST [--accept-host-key FP] [--replace FROM=TO] <slug-or-id>")
+		_, _ = fmt.Fprintln(env.Stdout, "                                         Back up, then replace a remote's database with this one")
 		return ExitOK
 	default:
 		_, _ = fmt.Fprintf(env.Stderr, "locorum site: unknown verb %q\n", verb)
@@ -170,6 +209,9 @@ func printDescribeText(w fmtWriter, d sites.SiteDescription) {
 	_, _ = fmt.Fprintf(w, "Name:      %s\n", d.Name)
 	_, _ = fmt.Fprintf(w, "Slug:      %s\n", d.Slug)
 	_, _ = fmt.Fprintf(w, "URL:       %s\n", d.URL)
+	if len(d.Aliases) > 0 {
+		_, _ = fmt.Fprintf(w, "Aliases:   %s\n", strings.Join(d.Aliases, ", "))
+	}
 	_, _ = fmt.Fprintf(w, "Status:    %s\n", statusString(d.Started))
 	_, _ = fmt.Fprintf(w, "Files:     %s\n", d.FilesDir)
 	if d.PublicDir != "" && d.PublicDir != "/" {
@@ -180,12 +222,21 @@ func printDescribeText(w fmtWriter, d sites.SiteDescription) {
 		_, _ = fmt.Fprintf(w, "Multisite: %s\n", d.Multisite)
 	}
 	_, _ = fmt.Fprintf(w, "PHP:       %s\n", d.PHP.Version)
+	if len(d.PHP.INI) > 0 {
+		_, _ = fmt.Fprintf(w, "PHP INI:   %s\n", phpINILine(d.PHP.INI))
+	}
+	if len(d.PHP.ExtensionsEnabled) > 0 || len(d.PHP.ExtensionsDisabled) > 0 {
+		_, _ = fmt.Fprintf(w, "PHP ext:   %s\n", phpExtensionsLine(d.PHP.ExtensionsEnabled, d.PHP.ExtensionsDisabled))
+	}
 	dbLine := d.Database.Engine + " " + d.Database.Version
 	if d.Database.HostPort > 0 {
 		dbLine += fmt.Sprintf(" (host port %d)", d.Database.HostPort)
 	}
 	_, _ = fmt.Fprintf(w, "Database:  %s\n", dbLine)
-	_, _ = fmt.Fprintf(w, "Redis:     %s\n", d.Redis.Version)
+	_, _ = fmt.Fprintf(w, "Cache:     %s\n", strings.TrimSpace(d.Cache.Backend+" "+d.Cache.Version))
+	if len(d.Resources) > 0 {
+		_, _ = fmt.Fprintf(w, "Limits:    %s\n", resourcesLine(d.Resources))
+	}
 	if d.Hooks.Total > 0 {
 		_, _ = fmt.Fprintf(w, "Hooks:     %d configured\n", d.Hooks.Total)
 	}
@@ -195,6 +246,9 @@ func printDescribeText(w fmtWriter, d sites.SiteDescription) {
 	if d.Profiling.Enabled {
 		_, _ = fmt.Fprintln(w, "Profiling: enabled (SPX)")
 	}
+	if d.Xdebug.Enabled {
+		_, _ = fmt.Fprintf(w, "Xdebug:    %s\n", d.Xdebug.Mode)
+	}
 	if len(d.Activity) > 0 {
 		_, _ = fmt.Fprintln(w, "")
 		_, _ = fmt.Fprintln(w, "Recent activity:")
@@ -206,6 +260,60 @@ func printDescribeText(w fmtWriter, d sites.SiteDescription) {
 	}
 }
 
+// resourcesLine renders the per-role limit overrides as
+// "php 1024MB 2cpu, database 2048MB", keys in sorted order.
+func resourcesLine(limits types.ResourceLimits) string {
+	keys := make([]string, 0, len(limits))
+	for k := range limits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		l := limits[k]
+		part := k
+		if l.MemoryMB > 0 {
+			part += fmt.Sprintf(" %dMB", l.MemoryMB)
+		}
+		if l.CPUs > 0 {
+			part += fmt.Sprintf(" %gcpu", l.CPUs)
+		}
+		if l.Pids > 0 {
+			part += fmt.Sprintf(" %dpids", l.Pids)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// phpINILine renders the per-site php.ini directives as
+// "max_input_vars=5000, opcache.validate_timestamps=0", sorted by name.
+func phpINILine(ini map[string]string) string {
+	keys := make([]string, 0, len(ini))
+	for k := range ini {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+ini[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// phpExtensionsLine renders the per-site extension switches as
+// "+pcov +xhprof -newrelic".
+func phpExtensionsLine(enabled, disabled []string) string {
+	parts := make([]string, 0, len(enabled)+len(disabled))
+	for _, e := range enabled {
+		parts = append(parts, "+"+e)
+	}
+	for _, e := range disabled {
+		parts = append(parts, "-"+e)
+	}
+	return strings.Join(parts, " ")
+}
+
 // statusString renders the bool as a human word — "running" instead of
 // "true" reads better in a terminal.
 func statusString(started bool) string {
@@ -236,8 +344,21 @@ func runSiteToggle(ctx context.Context, env *Env, method, verb string) ExitCode
 	}
 	defer func() { _ = cli.Close() }()
 
+	progress := followSteps(ctx, env, cli, target)
+
 	var resp map[string]any
-	if err := cli.Call(ctx, method, siteIDParams(target, nil), &resp); err != nil {
+	err = cli.Call(ctx, method, siteIDParams(target, nil), &resp)
+	// The plan's last events can trail the response by a moment. A
+	// call that fails before any plan runs (unknown site) has none.
+	wait := time.Second
+	if err != nil {
+		wait = 250 * time.Millisecond
+	}
+	select {
+	case <-progress:
+	case <-time.After(wait):
+	}
+	if err != nil {
 		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
 		return errToExit(err)
 	}
@@ -245,6 +366,49 @@ func runSiteToggle(ctx context.Context, env *Env, method, verb string) ExitCode
 	return ExitOK
 }
 
+// followSteps prints target's lifecycle steps to stderr as they finish
+// and returns a channel closed once the plan is done. Progress is a
+// nicety: if the subscription fails the returned channel is already
+// closed and the command runs as before.
+func followSteps(ctx context.Context, env *Env, cli *daemon.Client, target string) <-chan struct{} {
+	done := make(chan struct{})
+	f := daemon.EventFilter{Kinds: []string{daemon.EventStepDone, daemon.EventPlanDone}}
+	if looksLikeUUID(target) {
+		f.SiteID = target
+	} else {
+		f.Slug = target
+	}
+	events, err := cli.Subscribe(ctx, f)
+	if err != nil {
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		for ev := range events {
+			if ev.Kind == daemon.EventPlanDone {
+				return
+			}
+			var step struct {
+				Step       string `json:"step"`
+				Status     string `json:"status"`
+				DurationMs int64  `json:"durationMs"`
+				Error      string `json:"error"`
+			}
+			if json.Unmarshal(ev.Data, &step) != nil {
+				continue
+			}
+			dur := (time.Duration(step.DurationMs) * time.Millisecond).Round(100 * time.Millisecond)
+			line := fmt.Sprintf("  %-11s %s (%s)", step.Status, step.Step, dur)
+			if step.Error != "" {
+				line += ": " + step.Error
+			}
+			_, _ = fmt.Fprintln(env.Stderr, line)
+		}
+	}()
+	return done
+}
+
 // ─── site wp -- <args...> ──────────────────────────────────────────────
 
 // runSiteWP parses `locorum site wp <slug> -- arg1 arg2 …`. The `--`
@@ -291,22 +455,333 @@ func runSiteWP(ctx context.Context, env *Env) ExitCode {
 	return ExitOK
 }
 
+// ─── site xdebug ───────────────────────────────────────────────────────
+
+// runSiteXdebug parses `locorum site xdebug <slug> <mode>`. The site
+// must be stopped; the new mode applies on the next start.
+func runSiteXdebug(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site xdebug", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 2 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site xdebug <slug-or-id> <"+strings.Join(sites.XdebugModes, "|")+">")
+		return ExitUsage
+	}
+	target, mode := fs.Arg(0), strings.ToLower(fs.Arg(1))
+	if !sites.ValidXdebugMode(mode) {
+		_, _ = fmt.Fprintf(env.Stderr, "locorum: unknown xdebug mode %q (want one of %s)\n", mode, strings.Join(sites.XdebugModes, ", "))
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var resp map[string]any
+	if err := cli.Call(ctx, "site.xdebug", siteIDParams(target, map[string]any{"mode": mode}), &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "%s: xdebug %s (applies on next start)\n", target, mode)
+	return ExitOK
+}
+
+// ─── site mail ─────────────────────────────────────────────────────────
+
+// mailRelayPasswordEnv carries the smarthost password into `site mail`,
+// keeping it off the command line like remotePasswordEnv.
+const mailRelayPasswordEnv = "LOCORUM_MAIL_RELAY_PASSWORD"
+
+func runSiteMail(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site mail", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	host := fs.String("relay-host", "", "smarthost for relay mode, host or host:port (default port 587)")
+	user := fs.String("relay-user", "", "smarthost username; the password is read from $"+mailRelayPasswordEnv)
+	domains := fs.String("relay-domains", "", "comma-separated recipient domains relay mode may deliver to")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 2 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site mail [--relay-host H] [--relay-user U] [--relay-domains D] <slug-or-id> <"+strings.Join(mail.Modes, "|")+">")
+		return ExitUsage
+	}
+	target, mode := fs.Arg(0), strings.ToLower(fs.Arg(1))
+	if !sites.ValidMailMode(mode) {
+		_, _ = fmt.Fprintf(env.Stderr, "locorum: unknown mail mode %q (want one of %s)\n", mode, strings.Join(mail.Modes, ", "))
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	extra := map[string]any{"mode": mode}
+	if mode == mail.ModeRelay {
+		extra["relayHost"] = *host
+		extra["relayUser"] = *user
+		extra["relayPassword"] = os.Getenv(mailRelayPasswordEnv)
+		if *domains != "" {
+			extra["relayDomains"] = strings.Split(*domains, ",")
+		}
+	}
+	var resp struct {
+		Mail sites.MailSettings `json:"mail"`
+	}
+	if err := cli.Call(ctx, "site.mail", siteIDParams(target, extra), &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if resp.Mail.Mode == mail.ModeRelay {
+		_, _ = fmt.Fprintf(env.Stdout, "%s: mail relayed via %s to %s (applies on next start)\n",
+			target, resp.Mail.RelayHost, strings.Join(resp.Mail.RelayDomains, ", "))
+		return ExitOK
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "%s: mail %s (applies on next start)\n", target, resp.Mail.Mode)
+	return ExitOK
+}
+
+// ─── site db-ui ────────────────────────────────────────────────────────
+
+// runSiteDBUI prints a link that logs the global DB UI into the site's
+// database. The link expires after a couple of minutes; --open hands
+// it straight to the browser instead.
+func runSiteDBUI(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site db-ui", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	open := fs.Bool("open", false, "open the link in the default browser")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site db-ui [--open] <slug-or-id>")
+		return ExitUsage
+	}
+	target := fs.Arg(0)
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := cli.Call(ctx, "site.db_ui", siteIDParams(target, nil), &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *open {
+		if err := utils.OpenURL(resp.URL); err != nil {
+			_, _ = fmt.Fprintln(env.Stderr, "locorum: opening browser:", err)
+			return ExitError
+		}
+		return ExitOK
+	}
+	_, _ = fmt.Fprintln(env.Stdout, resp.URL)
+	return ExitOK
+}
+
+// ─── site access ───────────────────────────────────────────────────────
+
+// runSiteAccess parses `locorum site access <slug>`. Without flags it
+// prints the current access controls, basic-auth password included.
+func runSiteAccess(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site access", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	auth := fs.String("auth", "", "turn HTTP basic auth on or off")
+	rotate := fs.Bool("rotate-password", false, "generate a new basic-auth password")
+	allow := fs.String("allow", "", "comma-separated networks (CIDR or IP) allowed to connect; replaces the allowlist")
+	allowLAN := fs.Bool("allow-lan", false, "allow the detected LAN subnet; replaces the allowlist, combined with --allow")
+	noAllowlist := fs.Bool("no-allowlist", false, "remove the allowlist")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 || (*auth != "" && *auth != "on" && *auth != "off") || (*noAllowlist && (*allow != "" || *allowLAN)) {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site access [--auth on|off] [--rotate-password] [--allow CIDRS] [--allow-lan] [--no-allowlist] [--json] <slug-or-id>")
+		return ExitUsage
+	}
+	target := fs.Arg(0)
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	extra := map[string]any{
+		"auth":           *auth,
+		"rotatePassword": *rotate,
+		"allowLan":       *allowLAN,
+		"clearAllowlist": *noAllowlist,
+	}
+	if *allow != "" {
+		extra["allow"] = strings.Split(*allow, ",")
+	}
+	var resp struct {
+		Access        sites.SiteAccess `json:"access"`
+		AlwaysAllowed []string         `json:"alwaysAllowed"`
+		ClientsNATed  bool             `json:"clientsNATed"`
+	}
+	if err := cli.Call(ctx, "site.access", siteIDParams(target, extra), &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		_ = printJSON(env.Stdout, resp)
+		return ExitOK
+	}
+
+	a := resp.Access
+	tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	if a.AuthEnabled {
+		_, _ = fmt.Fprintf(tw, "basic auth:\ton\n")
+		_, _ = fmt.Fprintf(tw, "user:\t%s\n", a.AuthUser)
+		_, _ = fmt.Fprintf(tw, "password:\t%s\n", a.AuthPassword)
+	} else {
+		_, _ = fmt.Fprintf(tw, "basic auth:\toff\n")
+	}
+	if len(a.IPAllowlist) == 0 {
+		_, _ = fmt.Fprintf(tw, "allowlist:\tnone (any network)\n")
+	} else {
+		_, _ = fmt.Fprintf(tw, "allowlist:\t%s\n", strings.Join(a.IPAllowlist, ", "))
+		_, _ = fmt.Fprintf(tw, "always allowed:\t%s\n", strings.Join(resp.AlwaysAllowed, ", "))
+	}
+	_ = tw.Flush()
+	if len(a.IPAllowlist) > 0 && resp.ClientsNATed {
+		_, _ = fmt.Fprintln(env.Stderr, "note: Docker Desktop routes LAN clients through its VM ("+sites.DockerDesktopVMNet+"), "+
+			"so the allowlist cannot tell them apart from this machine; use basic auth to keep them out.")
+	}
+	return ExitOK
+}
+
+// ─── site sync-config ──────────────────────────────────────────────────
+
+func runSiteSyncConfig(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site sync-config", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	apply := fs.Bool("apply", false, "copy the config.yaml edits onto the site")
+	discard := fs.Bool("discard", false, "rewrite config.yaml from the site's current settings")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 || (*apply && *discard) {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site sync-config [--apply|--discard] [--json] <slug-or-id>")
+		return ExitUsage
+	}
+	target := fs.Arg(0)
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	method, params := "site.config_diff", siteIDParams(target, nil)
+	switch {
+	case *apply:
+		method, params = "site.sync_config", siteIDParams(target, map[string]any{"mode": "apply"})
+	case *discard:
+		method, params = "site.sync_config", siteIDParams(target, map[string]any{"mode": "discard"})
+	}
+	var resp struct {
+		Path      string                    `json:"path"`
+		Changes   []sites.ConfigFieldChange `json:"changes"`
+		Warnings  []string                  `json:"warnings"`
+		Applied   bool                      `json:"applied"`
+		Discarded bool                      `json:"discarded"`
+	}
+	if err := cli.Call(ctx, method, params, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	if *jsonOut {
+		_ = printJSON(env.Stdout, resp)
+		return ExitOK
+	}
+
+	switch {
+	case resp.Discarded:
+		_, _ = fmt.Fprintf(env.Stdout, "%s: config.yaml rewritten from current settings\n", target)
+		return ExitOK
+	case len(resp.Changes) == 0:
+		_, _ = fmt.Fprintf(env.Stdout, "%s: config.yaml has no unapplied changes\n", target)
+		return ExitOK
+	}
+	for _, w := range resp.Warnings {
+		_, _ = fmt.Fprintln(env.Stderr, "warning:", w)
+	}
+	tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FIELD\tCURRENT\tCONFIG.YAML\t")
+	for _, c := range resp.Changes {
+		note := ""
+		if c.Blocked != "" {
+			note = "not applied: " + c.Blocked
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Field, c.Current, c.Proposed, note)
+	}
+	_ = tw.Flush()
+	if resp.Applied {
+		_, _ = fmt.Fprintf(env.Stdout, "Applied %s to %s\n", resp.Path, target)
+	} else {
+		_, _ = fmt.Fprintln(env.Stdout, "Run with --apply to apply, or --discard to keep the current settings.")
+	}
+	return ExitOK
+}
+
 // ─── site logs ─────────────────────────────────────────────────────────
 
 func runSiteLogs(ctx context.Context, env *Env) ExitCode {
 	fs := flag.NewFlagSet("site logs", flag.ContinueOnError)
 	fs.SetOutput(env.Stderr)
-	service := fs.String("service", "php", "container service: web | php | database | redis")
-	lines := fs.Int("lines", 200, "number of trailing lines to fetch")
+	service := fs.String("service", "", "container services, comma-separated: web, php, database, redis, valkey, memcached (default php; every service with --follow, --since, --until or --grep)")
+	lines := fs.Int("lines", 0, "number of trailing lines to fetch per service (default 200; 100 when streaming)")
+	var follow bool
+	fs.BoolVar(&follow, "follow", false, "keep printing new lines until interrupted")
+	fs.BoolVar(&follow, "f", false, "shorthand for --follow")
+	since := fs.String("since", "", "only lines after this time: RFC 3339 or a duration ago, e.g. 10m")
+	until := fs.String("until", "", "only lines before this time: RFC 3339 or a duration ago")
+	grep := fs.String("grep", "", "only lines matching this regular expression")
 	if err := fs.Parse(env.Args); err != nil {
 		return ExitUsage
 	}
 	if fs.NArg() != 1 {
-		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site logs <slug-or-id> [--service S] [--lines N]")
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site logs [-f] [--service S[,S]] [--lines N] [--since T] [--until T] [--grep RE] <slug-or-id>")
 		return ExitUsage
 	}
 	target := fs.Arg(0)
 
+	now := time.Now()
+	sinceT, err := parseLogTime(*since, now)
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum: --since:", err)
+		return ExitUsage
+	}
+	untilT, err := parseLogTime(*until, now)
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum: --until:", err)
+		return ExitUsage
+	}
+	var services []string
+	for _, s := range strings.Split(*service, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			services = append(services, s)
+		}
+	}
+
 	cli, err := dial(ctx, env, daemon.HelloOptions{})
 	if err != nil {
 		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
@@ -314,8 +789,26 @@ func runSiteLogs(ctx context.Context, env *Env) ExitCode {
 	}
 	defer func() { _ = cli.Close() }()
 
+	streaming := follow || *since != "" || *until != "" || *grep != "" || len(services) > 1
+	if streaming {
+		params := map[string]any{"services": services, "lines": *lines, "grep": *grep, "follow": follow}
+		if !sinceT.IsZero() {
+			params["since"] = sinceT
+		}
+		if !untilT.IsZero() {
+			params["until"] = untilT
+		}
+		return streamSiteLogs(ctx, env, cli, siteIDParams(target, params))
+	}
+
+	if len(services) == 0 {
+		services = []string{"php"}
+	}
+	if *lines <= 0 {
+		*lines = 200
+	}
 	params := siteIDParams(target, map[string]any{
-		"service": *service,
+		"service": services[0],
 		"lines":   *lines,
 	})
 	var resp struct {
@@ -333,6 +826,77 @@ func runSiteLogs(ctx context.Context, env *Env) ExitCode {
 	return ExitOK
 }
 
+// serviceColours is the ANSI foreground per service, so interleaved
+// lines can be told apart at a glance.
+var serviceColours = map[string]string{
+	"web":       "36", // cyan
+	"php":       "35", // magenta
+	"database":  "33", // yellow
+	"redis":     "32", // green
+	"valkey":    "32",
+	"memcached": "32",
+}
+
+// streamSiteLogs runs site.logs_stream and prints each pushed line as
+// "service | text". Ctrl-C cancels ctx, which closes the connection and
+// with it the daemon-side stream.
+func streamSiteLogs(ctx context.Context, env *Env, cli *daemon.Client, params map[string]any) ExitCode {
+	events := cli.Events()
+	colour := useColour(env.Stdout)
+	printed := make(chan struct{})
+	go func() {
+		defer close(printed)
+		for ev := range events {
+			if ev.Kind != daemon.EventLogLine {
+				continue
+			}
+			var l struct {
+				Service string `json:"service"`
+				Stream  string `json:"stream"`
+				Text    string `json:"text"`
+			}
+			if json.Unmarshal(ev.Data, &l) != nil {
+				continue
+			}
+			label := fmt.Sprintf("%-9s |", l.Service)
+			if colour {
+				label = "\x1b[" + serviceColours[l.Service] + "m" + label + "\x1b[0m"
+				if l.Stream == "stderr" {
+					l.Text = "\x1b[31m" + l.Text + "\x1b[0m"
+				}
+			}
+			_, _ = fmt.Fprintln(env.Stdout, label, l.Text)
+		}
+	}()
+
+	err := cli.Call(ctx, "site.logs_stream", params, nil)
+	// Every pushed line precedes the response, so closing the client
+	// now lets the printer finish what is queued and stop.
+	_ = cli.Close()
+	<-printed
+	if err != nil && ctx.Err() == nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	return ExitOK
+}
+
+// parseLogTime reads a --since/--until value: empty, RFC 3339, or a
+// duration before now.
+func parseLogTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a duration like 10m", v)
+	}
+	return now.Add(-d), nil
+}
+
 // ─── helpers ───────────────────────────────────────────────────────────
 
 // fmtWriter is the io.Writer-superset accepted by Fprintf. Aliased so
@@ -4,20 +4,27 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/PeterBooker/locorum/internal/daemon"
+	"github.com/PeterBooker/locorum/internal/hooks"
 	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/sites/configyaml"
 )
 
 // runSiteCreate dispatches `locorum site create`. v1 supports the
-// worktree flow (--git-remote required); a future revision will add
-// blank-WP creation matching the GUI's New Site modal. For now,
-// blank-WP is a GUI-only path because it requires file-system pickers
-// the CLI can't reproduce cleanly.
+// worktree flow (--git-remote required) and recreating a site from a
+// committed .locorum/config.yaml (--from-config); a future revision
+// will add blank-WP creation matching the GUI's New Site modal. For
+// now, blank-WP is a GUI-only path because it requires file-system
+// pickers the CLI can't reproduce cleanly.
 func runSiteCreate(ctx context.Context, env *Env) ExitCode {
 	fs := flag.NewFlagSet("site create", flag.ContinueOnError)
 	fs.SetOutput(env.Stderr)
+	fromConfig := fs.String("from-config", "", "project directory containing .locorum/config.yaml")
+	withHooks := fs.Bool("with-hooks", false, "with --from-config: also recreate the hooks declared in the YAML, which run commands on this machine")
+	noStart := fs.Bool("no-start", false, "with --from-config: create the site without starting it")
 	name := fs.String("name", "", "human-readable site name (required)")
 	gitRemote := fs.String("git-remote", "", "upstream git URL (required for v1)")
 	branch := fs.String("branch", "", "branch to track (required when --git-remote is set)")
@@ -25,16 +32,22 @@ func runSiteCreate(ctx context.Context, env *Env) ExitCode {
 	cloneDB := fs.Bool("clone-db", false, "copy parent's DB and run search-replace")
 	dryRun := fs.Bool("dry-run", false, "describe the plan without executing")
 	php := fs.String("php", "", "PHP version override")
-	dbEngine := fs.String("db-engine", "", "DB engine override (mysql|mariadb)")
+	dbEngine := fs.String("db-engine", "", "DB engine override (mysql|mariadb|sqlite)")
 	dbVersion := fs.String("db-version", "", "DB version override")
-	redis := fs.String("redis", "", "Redis version override")
+	cache := fs.String("cache", "", "object cache backend override (redis|valkey|memcached|none)")
+	cacheVersion := fs.String("cache-version", "", "object cache version override")
+	redis := fs.String("redis", "", "deprecated alias for --cache-version")
 	worktreeRoot := fs.String("worktree-root", "", "host path for the worktree directory (defaults to <parent>.worktrees/)")
 	jsonOut := fs.Bool("json", false, "emit JSON")
 	if err := fs.Parse(env.Args); err != nil {
 		return ExitUsage
 	}
+	if *fromConfig != "" {
+		return runSiteCreateFromConfig(ctx, env, *fromConfig, *withHooks, !*noStart, *jsonOut)
+	}
 	if strings.TrimSpace(*name) == "" || strings.TrimSpace(*gitRemote) == "" || strings.TrimSpace(*branch) == "" {
 		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site create --name N --git-remote URL --branch B [--clone-db] [--dry-run]")
+		_, _ = fmt.Fprintln(env.Stderr, "       locorum site create --from-config DIR [--with-hooks] [--no-start]")
 		return ExitUsage
 	}
 
@@ -45,6 +58,9 @@ func runSiteCreate(ctx context.Context, env *Env) ExitCode {
 	}
 	defer func() { _ = cli.Close() }()
 
+	if *cacheVersion == "" {
+		*cacheVersion = *redis
+	}
 	params := map[string]any{
 		"name":         *name,
 		"gitRemote":    *gitRemote,
@@ -55,7 +71,8 @@ func runSiteCreate(ctx context.Context, env *Env) ExitCode {
 		"phpVersion":   *php,
 		"dbEngine":     *dbEngine,
 		"dbVersion":    *dbVersion,
-		"redisVersion": *redis,
+		"cacheBackend": *cache,
+		"cacheVersion": *cacheVersion,
 		"worktreeRoot": *worktreeRoot,
 	}
 	var resp sites.CreateWorktreeResult
@@ -83,6 +100,132 @@ func runSiteCreate(ctx context.Context, env *Env) ExitCode {
 	return ExitOK
 }
 
+// runSiteCreateFromConfig handles `site create --from-config DIR`.
+// Deprecation warnings from the YAML, and the hooks left out without
+// --with-hooks, go to stderr so --json output stays machine-readable.
+func runSiteCreateFromConfig(ctx context.Context, env *Env, dir string, withHooks, start, jsonOut bool) ExitCode {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return ExitUsage
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	params := map[string]any{
+		"dir":       abs,
+		"withHooks": withHooks,
+		"start":     start,
+	}
+	var resp sites.CreateFromConfigResult
+	if err := cli.Call(ctx, "site.create_from_config", params, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	for _, w := range resp.Warnings {
+		_, _ = fmt.Fprintln(env.Stderr, "warning:", w)
+	}
+	printSkippedHooks(env, resp.SkippedHooks, resp.Site.Slug)
+
+	if jsonOut {
+		if err := printJSON(env.Stdout, resp); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Created %s (slug=%s) from %s\n", resp.Site.Name, resp.Site.Slug, abs)
+	_, _ = fmt.Fprintf(env.Stdout, "URL: https://%s\n", resp.Site.Domain)
+	if resp.Hooks > 0 {
+		_, _ = fmt.Fprintf(env.Stdout, "Hooks: %d recreated\n", resp.Hooks)
+	}
+	return ExitOK
+}
+
+// printSkippedHooks lists, on stderr, the hooks a create or import
+// left out without --with-hooks, and how to add them once reviewed.
+func printSkippedHooks(env *Env, skipped []hooks.Hook, slug string) {
+	if len(skipped) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(env.Stderr, "%d hooks in %s were not added:\n", len(skipped), configyaml.Filename)
+	for _, h := range skipped {
+		_, _ = fmt.Fprintf(env.Stderr, "  %s [%s] %s\n", h.Event, h.TaskType, h.Command)
+	}
+	_, _ = fmt.Fprintf(env.Stderr, "once reviewed, add them with: locorum site sync-config --apply %s\n", slug)
+}
+
+// runSiteImport dispatches `locorum site import`. The archive path is
+// made absolute here because the daemon resolves paths against its own
+// working directory, not the shell's.
+func runSiteImport(ctx context.Context, env *Env) ExitCode {
+	fs := flag.NewFlagSet("site import", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	name := fs.String("name", "", "site name (defaults to the name recorded in the archive)")
+	filesDir := fs.String("files-dir", "", "directory to extract files into (defaults to ~/locorum/sites/<slug>)")
+	noAuto := fs.Bool("no-search-replace", false, "skip the automatic siteurl/home search-replace")
+	withHooks := fs.Bool("with-hooks", false, "also restore the hooks recorded in the archive, which run commands on this machine")
+	jsonOut := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(env.Args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 {
+		_, _ = fmt.Fprintln(env.Stderr, "usage: locorum site import <archive.tar.gz> [--name N] [--files-dir D] [--no-search-replace] [--with-hooks]")
+		return ExitUsage
+	}
+	archive, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return ExitUsage
+	}
+	dir := *filesDir
+	if dir != "" {
+		if dir, err = filepath.Abs(dir); err != nil {
+			_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+			return ExitUsage
+		}
+	}
+
+	cli, err := dial(ctx, env, daemon.HelloOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	params := map[string]any{
+		"path":        archive,
+		"name":        *name,
+		"filesDir":    dir,
+		"disableAuto": *noAuto,
+		"withHooks":   *withHooks,
+	}
+	var resp sites.ImportSiteResult
+	if err := cli.Call(ctx, "site.import", params, &resp); err != nil {
+		_, _ = fmt.Fprintln(env.Stderr, "locorum:", err)
+		return errToExit(err)
+	}
+	printSkippedHooks(env, resp.SkippedHooks, resp.Site.Slug)
+
+	if *jsonOut {
+		if err := printJSON(env.Stdout, resp); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Imported %s (slug=%s)\n", resp.Site.Name, resp.Site.Slug)
+	if resp.SourceSlug != "" && resp.SourceSlug != resp.Site.Slug {
+		_, _ = fmt.Fprintf(env.Stdout, "Slug %q was taken; renamed to %q\n", resp.SourceSlug, resp.Site.Slug)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "URL: https://%s\n", resp.Site.Domain)
+	_, _ = fmt.Fprintf(env.Stdout, "Files: %s\n", resp.Site.FilesDir)
+	return ExitOK
+}
+
 // runSiteDelete dispatches `locorum site delete`.
 func runSiteDelete(ctx context.Context, env *Env) ExitCode {
 	fs := flag.NewFlagSet("site delete", flag.ContinueOnError)
@@ -72,11 +72,15 @@ func allKeys() []string {
 		KeyDefaultDBEngine,
 		KeyDefaultDBVersion,
 		KeyDefaultRedisVersion,
+		KeyDefaultCacheBackend,
 		KeyDefaultWebServer,
 		KeyDefaultPublishDBPort,
 		KeyRouterHTTPPort,
 		KeyRouterHTTPSPort,
+		KeyRouterEngine,
+		KeyDBUIEngine,
 		KeyMkcertPath,
+		KeyTLSProvider,
 		KeyPerformanceMode,
 		KeyUpdateCheckEnabled,
 		KeyUpdateCheckChannel,
@@ -93,6 +97,10 @@ func allKeys() []string {
 		KeyLanDefault,
 		KeyLanDomain,
 		KeyLanIPOverride,
+		KeyHostsManage,
+		KeyDNSEnabled,
+		KeyDNSTLD,
+		KeyDNSListen,
 	}
 }
 
@@ -136,7 +144,7 @@ func (c *Config) PHPVersionDefault() string {
 	return DefaultPHPVersion
 }
 
-// DBEngineDefault is "mysql" or "mariadb".
+// DBEngineDefault is "mysql", "mariadb" or "sqlite".
 func (c *Config) DBEngineDefault() string {
 	v := c.raw(KeyDefaultDBEngine)
 	if validEnum(v, allowedDBEngines) {
@@ -162,6 +170,17 @@ func (c *Config) RedisVersionDefault() string {
 	return DefaultRedisVersion
 }
 
+// CacheBackendDefault is "redis", "valkey", "memcached" or "none". The
+// version default for non-Redis backends comes from
+// cachebackend.DefaultVersion at the call site.
+func (c *Config) CacheBackendDefault() string {
+	v := c.raw(KeyDefaultCacheBackend)
+	if validEnum(v, allowedCacheBackends) {
+		return v
+	}
+	return DefaultCacheBackend
+}
+
 // WebServerDefault returns "nginx" or "apache".
 func (c *Config) WebServerDefault() string {
 	v := c.raw(KeyDefaultWebServer)
@@ -177,6 +196,33 @@ func (c *Config) MkcertPath() string {
 	return c.raw(KeyMkcertPath)
 }
 
+// TLSProvider is "mkcert" or "builtin".
+func (c *Config) TLSProvider() string {
+	v := c.raw(KeyTLSProvider)
+	if validEnum(v, allowedTLSProviders) {
+		return v
+	}
+	return DefaultTLSProvider
+}
+
+// RouterEngine is "traefik" or "caddy".
+func (c *Config) RouterEngine() string {
+	v := c.raw(KeyRouterEngine)
+	if validEnum(v, allowedRouterEngines) {
+		return v
+	}
+	return DefaultRouterEngine
+}
+
+// DBUIEngine is "adminer" or "phpmyadmin".
+func (c *Config) DBUIEngine() string {
+	v := c.raw(KeyDBUIEngine)
+	if validEnum(v, allowedDBUIEngines) {
+		return v
+	}
+	return DefaultDBUIEngine
+}
+
 // PerformanceMode is "auto", "bind", or "mutagen".
 func (c *Config) PerformanceMode() string {
 	v := c.raw(KeyPerformanceMode)
@@ -283,6 +329,14 @@ func (c *Config) SetRedisVersionDefault(v string) error {
 	return c.Set(KeyDefaultRedisVersion, v)
 }
 
+// SetCacheBackendDefault validates the cache backend value.
+func (c *Config) SetCacheBackendDefault(v string) error {
+	if !validEnum(v, allowedCacheBackends) {
+		return fmt.Errorf("config: invalid cache backend %q (allowed: %s)", v, strings.Join(allowedCacheBackends, ", "))
+	}
+	return c.Set(KeyDefaultCacheBackend, v)
+}
+
 // SetWebServerDefault validates the web server value.
 func (c *Config) SetWebServerDefault(v string) error {
 	if !validEnum(v, allowedWebServers) {
@@ -302,6 +356,33 @@ func (c *Config) SetMkcertPath(v string) error {
 	return c.Set(KeyMkcertPath, v)
 }
 
+// SetTLSProvider validates and persists the certificate provider. Takes
+// effect on the next launch.
+func (c *Config) SetTLSProvider(v string) error {
+	if !validEnum(v, allowedTLSProviders) {
+		return fmt.Errorf("config: invalid TLS provider %q (allowed: %s)", v, strings.Join(allowedTLSProviders, ", "))
+	}
+	return c.Set(KeyTLSProvider, v)
+}
+
+// SetRouterEngine validates and persists the router backend. Takes
+// effect on the next launch.
+func (c *Config) SetRouterEngine(v string) error {
+	if !validEnum(v, allowedRouterEngines) {
+		return fmt.Errorf("config: invalid router engine %q (allowed: %s)", v, strings.Join(allowedRouterEngines, ", "))
+	}
+	return c.Set(KeyRouterEngine, v)
+}
+
+// SetDBUIEngine validates and persists the global database UI. Takes
+// effect on the next launch.
+func (c *Config) SetDBUIEngine(v string) error {
+	if !validEnum(v, allowedDBUIEngines) {
+		return fmt.Errorf("config: invalid DB UI engine %q (allowed: %s)", v, strings.Join(allowedDBUIEngines, ", "))
+	}
+	return c.Set(KeyDBUIEngine, v)
+}
+
 // SetRouterHTTPPort validates and persists the HTTP host port. The
 // caller is responsible for actually binding it — this just records
 // user intent.
@@ -537,6 +618,92 @@ func (c *Config) SetLanIPOverride(v string) error {
 	return c.Set(KeyLanIPOverride, ip.To4().String())
 }
 
+// ── Hosts file ──────────────────────────────────────────────────────
+
+// HostsFileManaged reports whether Locorum should keep site aliases in
+// the system hosts file. Default false.
+func (c *Config) HostsFileManaged() bool {
+	return parseBool(c.raw(KeyHostsManage), false)
+}
+
+// SetHostsFileManaged persists the toggle. The caller syncs (or clears)
+// the hosts file block.
+func (c *Config) SetHostsFileManaged(on bool) error {
+	return c.Set(KeyHostsManage, formatBool(on))
+}
+
+// ── Embedded DNS ────────────────────────────────────────────────────
+
+// DNSEnabled reports whether the daemon should run the embedded DNS
+// server. Default false. Read once at startup.
+func (c *Config) DNSEnabled() bool {
+	return parseBool(c.raw(KeyDNSEnabled), false)
+}
+
+// SetDNSEnabled persists the toggle. Takes effect on next launch.
+func (c *Config) SetDNSEnabled(on bool) error {
+	return c.Set(KeyDNSEnabled, formatBool(on))
+}
+
+// DNSTLD returns the dev TLD the embedded server answers for, without
+// dots. Default "test".
+func (c *Config) DNSTLD() string {
+	if v := strings.TrimSpace(c.raw(KeyDNSTLD)); v != "" {
+		return v
+	}
+	return DefaultDNSTLD
+}
+
+// SetDNSTLD validates and persists the dev TLD. Empty string clears the
+// override. The value must be a single DNS label; "localhost" is
+// refused because every OS already resolves it without help.
+func (c *Config) SetDNSTLD(v string) error {
+	v = strings.Trim(strings.ToLower(strings.TrimSpace(v)), ".")
+	if v == "" {
+		return c.Set(KeyDNSTLD, "")
+	}
+	if v == "localhost" || len(v) > 63 || v[0] == '-' || v[len(v)-1] == '-' {
+		return fmt.Errorf("config: invalid dns tld %q", v)
+	}
+	for _, r := range v {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return fmt.Errorf("config: invalid dns tld %q", v)
+		}
+	}
+	return c.Set(KeyDNSTLD, v)
+}
+
+// DNSListen returns the UDP "ip:port" the embedded server binds.
+// Default "127.0.0.1:5300".
+func (c *Config) DNSListen() string {
+	if v := strings.TrimSpace(c.raw(KeyDNSListen)); v != "" {
+		return v
+	}
+	return DefaultDNSListen
+}
+
+// SetDNSListen validates and persists the bind address. The host must
+// be a literal IP — a loopback address for this machine only, or a LAN
+// address so phones and tablets can use the server too. Empty string
+// clears the override.
+func (c *Config) SetDNSListen(v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return c.Set(KeyDNSListen, "")
+	}
+	host, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return fmt.Errorf("config: invalid dns listen address %q: %w", v, err)
+	}
+	if net.ParseIP(host) == nil {
+		return fmt.Errorf("config: dns listen host %q is not an IP address", host)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("config: invalid dns listen port %q", port)
+	}
+	return c.Set(KeyDNSListen, v)
+}
+
 // HealthLastSeen returns the persisted last-seen-finding-keys JSON blob.
 // Empty string on first run. The value is opaque to the config package;
 // the UI's toast handler parses it.
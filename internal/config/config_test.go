@@ -69,6 +69,9 @@ func TestDefaultsWhenStorageEmpty(t *testing.T) {
 	if c.RedisVersionDefault() != DefaultRedisVersion {
 		t.Errorf("RedisVersionDefault default: got %q", c.RedisVersionDefault())
 	}
+	if c.CacheBackendDefault() != DefaultCacheBackend {
+		t.Errorf("CacheBackendDefault default: got %q", c.CacheBackendDefault())
+	}
 	if c.WebServerDefault() != DefaultWebServer {
 		t.Errorf("WebServerDefault default: got %q", c.WebServerDefault())
 	}
@@ -90,6 +93,15 @@ func TestDefaultsWhenStorageEmpty(t *testing.T) {
 	if c.PerformanceMode() != DefaultPerformance {
 		t.Errorf("PerformanceMode default: got %q", c.PerformanceMode())
 	}
+	if c.TLSProvider() != DefaultTLSProvider {
+		t.Errorf("TLSProvider default: got %q", c.TLSProvider())
+	}
+	if c.RouterEngine() != DefaultRouterEngine {
+		t.Errorf("RouterEngine default: got %q", c.RouterEngine())
+	}
+	if c.DBUIEngine() != DefaultDBUIEngine {
+		t.Errorf("DBUIEngine default: got %q", c.DBUIEngine())
+	}
 }
 
 func TestSettersAndGetters(t *testing.T) {
@@ -122,6 +134,10 @@ func TestSettersAndGetters(t *testing.T) {
 	if got := c.RedisVersionDefault(); got != "7.2" {
 		t.Errorf("redis: got %q", got)
 	}
+	must("cache", c.SetCacheBackendDefault("memcached"))
+	if got := c.CacheBackendDefault(); got != "memcached" {
+		t.Errorf("cache: got %q", got)
+	}
 	must("web", c.SetWebServerDefault("apache"))
 	if got := c.WebServerDefault(); got != "apache" {
 		t.Errorf("web: got %q", got)
@@ -142,6 +158,18 @@ func TestSettersAndGetters(t *testing.T) {
 	if got := c.MkcertPath(); got != "/usr/local/bin/mkcert" {
 		t.Errorf("mkcert: got %q", got)
 	}
+	must("tls", c.SetTLSProvider("builtin"))
+	if got := c.TLSProvider(); got != "builtin" {
+		t.Errorf("tls: got %q", got)
+	}
+	must("router", c.SetRouterEngine("caddy"))
+	if got := c.RouterEngine(); got != "caddy" {
+		t.Errorf("router: got %q", got)
+	}
+	must("dbui", c.SetDBUIEngine("phpmyadmin"))
+	if got := c.DBUIEngine(); got != "phpmyadmin" {
+		t.Errorf("dbui: got %q", got)
+	}
 	must("perf", c.SetPerformanceMode("mutagen"))
 	if got := c.PerformanceMode(); got != "mutagen" {
 		t.Errorf("perf: got %q", got)
@@ -170,8 +198,12 @@ func TestEnumValidationRejectsBadValues(t *testing.T) {
 		{"theme", func() error { return c.SetThemeMode("rainbow") }},
 		{"engine", func() error { return c.SetDBEngineDefault("postgres") }},
 		{"web", func() error { return c.SetWebServerDefault("iis") }},
+		{"cache", func() error { return c.SetCacheBackendDefault("varnish") }},
 		{"perf", func() error { return c.SetPerformanceMode("fast") }},
 		{"channel", func() error { return c.SetUpdateCheckChannel("nightly") }},
+		{"tls", func() error { return c.SetTLSProvider("letsencrypt") }},
+		{"router", func() error { return c.SetRouterEngine("nginx") }},
+		{"dbui", func() error { return c.SetDBUIEngine("pgadmin") }},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -331,6 +363,76 @@ func TestLanAccessors(t *testing.T) {
 	})
 }
 
+func TestHostsFileManaged(t *testing.T) {
+	c, err := New(newFake())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HostsFileManaged() {
+		t.Error("HostsFileManaged should be false by default")
+	}
+	if err := c.SetHostsFileManaged(true); err != nil {
+		t.Fatal(err)
+	}
+	if !c.HostsFileManaged() {
+		t.Error("HostsFileManaged should be true after Set")
+	}
+
+	// The toggle must survive a restart, i.e. be part of Reload.
+	fresh, err := New(c.st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fresh.HostsFileManaged() {
+		t.Error("HostsFileManaged lost on reload")
+	}
+}
+
+func TestDNSSettings(t *testing.T) {
+	c, err := New(newFake())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DNSEnabled() || c.DNSTLD() != DefaultDNSTLD || c.DNSListen() != DefaultDNSListen {
+		t.Errorf("defaults: enabled=%v tld=%q listen=%q", c.DNSEnabled(), c.DNSTLD(), c.DNSListen())
+	}
+
+	if err := c.SetDNSTLD(" .Dev. "); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.DNSTLD(); got != "dev" {
+		t.Errorf("DNSTLD = %q, want dev", got)
+	}
+	for _, v := range []string{"localhost", "my.test", "-x", "te_st"} {
+		if err := c.SetDNSTLD(v); err == nil {
+			t.Errorf("SetDNSTLD(%q): want error", v)
+		}
+	}
+
+	if err := c.SetDNSListen("192.168.1.42:53"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.DNSListen(); got != "192.168.1.42:53" {
+		t.Errorf("DNSListen = %q", got)
+	}
+	for _, v := range []string{"127.0.0.1", "localhost:53", "127.0.0.1:0", "127.0.0.1:dns"} {
+		if err := c.SetDNSListen(v); err == nil {
+			t.Errorf("SetDNSListen(%q): want error", v)
+		}
+	}
+
+	if err := c.SetDNSEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := New(c.st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fresh.DNSEnabled() || fresh.DNSTLD() != "dev" {
+		t.Error("dns settings lost on reload")
+	}
+}
+
 func TestParseBoolCases(t *testing.T) {
 	cases := []struct {
 		in   string
@@ -35,6 +35,7 @@ const (
 	KeyDefaultDBEngine      = "defaults.db_engine"
 	KeyDefaultDBVersion     = "defaults.db_version"
 	KeyDefaultRedisVersion  = "defaults.redis_version"
+	KeyDefaultCacheBackend  = "defaults.cache_backend"
 	KeyDefaultWebServer     = "defaults.web_server"
 	KeyDefaultPublishDBPort = "defaults.publish_db_port"
 
@@ -43,9 +44,20 @@ const (
 	KeyRouterHTTPPort  = "router.http_port"
 	KeyRouterHTTPSPort = "router.https_port"
 
+	// Router engine: "traefik" or "caddy". Read once at startup.
+	KeyRouterEngine = "router.engine"
+
+	// Global database UI at db.localhost: "adminer" or "phpmyadmin".
+	// Read once at startup.
+	KeyDBUIEngine = "dbui.engine"
+
 	// TLS / mkcert. Empty means "autodetect on PATH".
 	KeyMkcertPath = "mkcert.path"
 
+	// Certificate provider: "mkcert" (external binary) or "builtin"
+	// (pure-Go CA under ~/.locorum/ca). Read once at startup.
+	KeyTLSProvider = "tls.provider"
+
 	// Performance mode. Reserved for the LEARNINGS §6.3 mutagen
 	// integration. Values: "auto", "bind", "mutagen". Default "auto".
 	KeyPerformanceMode = "performance.mode"
@@ -95,6 +107,18 @@ const (
 	KeyLanDefault    = "lan.default_enabled" // bool, default false
 	KeyLanDomain     = "lan.domain"          // default "sslip.io"
 	KeyLanIPOverride = "lan.ip_override"     // optional manual IPv4
+
+	// KeyHostsManage opts in to the Locorum block in the system hosts
+	// file, which maps site aliases outside *.localhost to loopback.
+	// Off by default because every write needs an elevation prompt.
+	KeyHostsManage = "hosts.manage" // bool, default false
+
+	// Embedded DNS server (internal/devdns). Off by default: it only
+	// helps once the OS resolver is told to use it, which is a
+	// one-off manual step per machine.
+	KeyDNSEnabled = "dns.enabled" // bool, default false
+	KeyDNSTLD     = "dns.tld"     // default "test"
+	KeyDNSListen  = "dns.listen"  // default "127.0.0.1:5300"
 )
 
 // Documented default values for every accessor. Centralising these
@@ -104,11 +128,15 @@ const (
 	DefaultPHPVersion    = "8.3"
 	DefaultDBEngine      = "mysql"
 	DefaultRedisVersion  = "7"
+	DefaultCacheBackend  = "redis"
 	DefaultWebServer     = "nginx"
 	DefaultRouterHTTP    = 80
 	DefaultRouterHTTPS   = 443
 	DefaultPerformance   = "auto"
 	DefaultUpdateChannel = "stable"
+	DefaultTLSProvider   = "mkcert"
+	DefaultRouterEngine  = "traefik"
+	DefaultDBUIEngine    = "adminer"
 
 	DefaultHealthEnabled            = true
 	DefaultHealthCadenceMinutes     = 5
@@ -120,13 +148,25 @@ const (
 	// `<anything>.<ipv4>.<domain>` back to the host's LAN IP. sslip.io
 	// is free, requires no setup, and is widely cached by ISP resolvers.
 	DefaultLanDomain = "sslip.io"
+
+	// DefaultDNSTLD is reserved for testing by RFC 2606, so it can
+	// never collide with a real public domain.
+	DefaultDNSTLD = "test"
+
+	// DefaultDNSListen sits on loopback, above 1024 so no privileges
+	// are needed to bind it, and clear of mDNS on 5353.
+	DefaultDNSListen = "127.0.0.1:5300"
 )
 
 // Allowed enum values. Used by Set* validation.
 var (
-	allowedDBEngines      = []string{"mysql", "mariadb"}
+	allowedDBEngines      = []string{"mysql", "mariadb", "sqlite"}
+	allowedCacheBackends  = []string{"redis", "valkey", "memcached", "none"}
 	allowedWebServers     = []string{"nginx", "apache"}
 	allowedThemeModes     = []string{"system", "dark", "light"}
 	allowedPerformance    = []string{"auto", "bind", "mutagen"}
 	allowedUpdateChannels = []string{"stable", "beta"}
+	allowedTLSProviders   = []string{"mkcert", "builtin"}
+	allowedRouterEngines  = []string{"traefik", "caddy"}
+	allowedDBUIEngines    = []string{"adminer", "phpmyadmin"}
 )
@@ -30,16 +30,22 @@ type Client struct {
 	pending map[int64]chan *Response
 	closed  bool
 	closeCh chan struct{}
+
+	// events receives server pushes once Events or Subscribe has been
+	// called. Closed with the client.
+	events chan Event
 }
 
 // HelloOptions configures the client.hello handshake. Most clients
 // declare PeerKind ("cli", "mcp", "gui-test") so the daemon can
 // distinguish traffic in its activity log. Profile / MCPScope are
-// MCP-only.
+// MCP-only. Token is a capability token secret; the daemon refuses the
+// hello when it is unknown, revoked or expired.
 type HelloOptions struct {
 	PeerKind string
 	Profile  string
 	MCPScope string
+	Token    string
 }
 
 // DialClient connects to the daemon socket / pipe and performs the
@@ -59,10 +65,12 @@ func DialClient(ctx context.Context, socket string, hello HelloOptions) (*Client
 		PeerKind string `json:"peerKind,omitempty"`
 		Profile  string `json:"profile,omitempty"`
 		MCPScope string `json:"mcpScope,omitempty"`
+		Token    string `json:"token,omitempty"`
 	}{
 		PeerKind: hello.PeerKind,
 		Profile:  hello.Profile,
 		MCPScope: hello.MCPScope,
+		Token:    hello.Token,
 	}
 	var info serverInfo
 	if err := cli.Call(ctx, "client.hello", helloParams, &info); err != nil {
@@ -82,6 +90,48 @@ func newClient(conn net.Conn) *Client {
 	}
 }
 
+// EventFilter narrows an events.subscribe subscription. Empty Kinds
+// means every kind the connection may receive; SiteID or Slug limits
+// site events to one site.
+type EventFilter struct {
+	SiteID string   `json:"siteId,omitempty"`
+	Slug   string   `json:"slug,omitempty"`
+	Kinds  []string `json:"kinds,omitempty"`
+}
+
+// Subscribe asks the daemon to push events matching f and returns the
+// channel they arrive on. Calls and events share the connection, so
+// Subscribe then a long Call (site.start) streams that call's progress.
+// The channel closes with the client; a reader that falls behind loses
+// events rather than stalling responses. Subscribing again replaces
+// the filter and keeps the channel.
+func (c *Client) Subscribe(ctx context.Context, f EventFilter) (<-chan Event, error) {
+	ch := c.Events()
+	if ch == nil {
+		return nil, errors.New("client is closed")
+	}
+	if err := c.Call(ctx, "events.subscribe", f, nil); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Events returns the channel server pushes arrive on, subscribed or
+// not: site.logs_stream sends its lines to the caller without a
+// subscription. Nil once the client is closed. Pushes that arrive
+// before the first call are dropped.
+func (c *Client) Events() <-chan Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	if c.events == nil {
+		c.events = make(chan Event, clientEventBuffer)
+	}
+	return c.events
+}
+
 // Call invokes method with params, blocks until the daemon responds (or
 // ctx is done), and unmarshals the result into out (a pointer to a
 // struct). Pass nil for params or out when neither is needed.
@@ -157,18 +207,37 @@ func (c *Client) writeFrame(req Request) error {
 	return c.enc.Encode(req)
 }
 
+// clientEventBuffer is how many pushes may wait for the reader of
+// Events. Larger than the server's per-subscriber buffer: a log
+// stream's history arrives as one burst.
+const clientEventBuffer = 1024
+
+// inboundFrame is anything the daemon writes: a response, or an
+// "event" notification carrying Method and Params instead.
+type inboundFrame struct {
+	Response
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
 // readLoop reads responses from the conn and routes each to its waiting
-// Call via the pending map. Exits when the connection closes; failure
-// to find a pending entry is logged-but-ignored (the caller may have
-// timed out and dropped its channel).
+// Call via the pending map, and notifications to the events channel.
+// Exits when the connection closes; failure to find a pending entry is
+// logged-but-ignored (the caller may have timed out and dropped its
+// channel).
 func (c *Client) readLoop() {
 	defer c.shutdown(nil)
 	for {
-		var resp Response
-		if err := c.dec.Decode(&resp); err != nil {
+		var frame inboundFrame
+		if err := c.dec.Decode(&frame); err != nil {
 			c.shutdown(err)
 			return
 		}
+		if frame.Method != "" {
+			c.deliverEvent(frame)
+			continue
+		}
+		resp := frame.Response
 		// id is JSON-encoded as a number — unmarshal back to int64.
 		// Defensive: malformed servers might send strings; we drop
 		// them rather than panic.
@@ -192,6 +261,28 @@ func (c *Client) readLoop() {
 	}
 }
 
+// deliverEvent hands a notification to the Subscribe channel without
+// blocking the read loop. Held under mu so shutdown cannot close the
+// channel mid-send.
+func (c *Client) deliverEvent(frame inboundFrame) {
+	if frame.Method != NotificationMethod {
+		return
+	}
+	var ev Event
+	if err := json.Unmarshal(frame.Params, &ev); err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.events == nil || c.closed {
+		return
+	}
+	select {
+	case c.events <- ev:
+	default:
+	}
+}
+
 // shutdown drains pending channels and marks the client closed.
 // Idempotent. _err is recorded for diagnostics but otherwise unused —
 // callers see a generic "connection closed" error from Call.
@@ -205,6 +296,9 @@ func (c *Client) shutdown(_ error) {
 	close(c.closeCh)
 	pending := c.pending
 	c.pending = nil
+	if c.events != nil {
+		close(c.events)
+	}
 	c.mu.Unlock()
 	for _, ch := range pending {
 		close(ch)
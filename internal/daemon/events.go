@@ -0,0 +1,308 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/captoken"
+)
+
+// Event kinds streamed by events.subscribe. Wire-stable: clients filter
+// on them.
+const (
+	// EventSiteUpdated fires whenever a site row changes, including
+	// the started flag flipping at the end of a start or stop.
+	EventSiteUpdated = "site.updated"
+	// EventSitesChanged fires when a site is added or removed.
+	EventSitesChanged = "sites.changed"
+	// EventStepStart, EventStepDone and EventPlanDone trace a
+	// lifecycle plan (start, stop, clone, …) step by step.
+	EventStepStart = "step.start"
+	EventStepDone  = "step.done"
+	EventPlanDone  = "plan.done"
+	// EventPullProgress reports image pulls during a start.
+	EventPullProgress = "pull.progress"
+	// EventHookOutput is one line printed by a running lifecycle hook.
+	EventHookOutput = "hook.output"
+	// EventHealthFindings carries the System Health findings whenever
+	// the set changes.
+	EventHealthFindings = "health.findings"
+
+	// EventLogLine is one container log line. It is not subscribable:
+	// site.logs_stream pushes it to its own caller only.
+	EventLogLine = "log.line"
+
+	// EventsDropped is pushed ahead of the next event when a
+	// subscriber fell behind and events were discarded; data.count
+	// says how many.
+	EventsDropped = "events.dropped"
+)
+
+// NotificationMethod is the JSON-RPC method of every server push. Its
+// params are one Event.
+const NotificationMethod = "event"
+
+// eventKind says who may receive a kind. fullOnly kinds are withheld
+// from the readonly profile; global kinds belong to no single site and
+// are withheld from MCP-scoped connections.
+type eventKind struct {
+	fullOnly bool
+	global   bool
+}
+
+var eventKinds = map[string]eventKind{
+	EventSiteUpdated:  {},
+	EventSitesChanged: {global: true},
+	EventStepStart:    {},
+	EventStepDone:     {},
+	EventPlanDone:     {},
+	EventPullProgress: {},
+	// Hooks print whatever the user's scripts print; the readonly
+	// profile cannot run hooks, so it does not read their output
+	// either.
+	EventHookOutput:     {fullOnly: true},
+	EventHealthFindings: {global: true},
+}
+
+// Event is one server push. Data is kind-specific and already JSON.
+type Event struct {
+	Kind   string          `json:"kind"`
+	SiteID string          `json:"siteId,omitempty"`
+	Slug   string          `json:"slug,omitempty"`
+	Time   time.Time       `json:"time"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// subscriberBuffer is how many events a subscriber may lag behind
+// before new ones are dropped for it. A start's pull progress is the
+// burstiest source and stays well under this.
+const subscriberBuffer = 256
+
+// EventHub fans events out to every events.subscribe subscription.
+// Publish never blocks the caller — the sources are SiteManager and
+// health callbacks running on lifecycle goroutines.
+type EventHub struct {
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+}
+
+// NewEventHub returns an empty hub.
+func NewEventHub() *EventHub {
+	return &EventHub{subs: make(map[*subscription]struct{})}
+}
+
+// subscription is one connection's filter and queue.
+type subscription struct {
+	kinds  map[string]bool
+	siteID string // only this site's events; empty for every site
+	scope  string // the conn's MCP scope, matched against id or slug
+
+	// sites carries the site grant of the conn's capability token, so
+	// a site-bound token sees only its own sites. The zero Token allows
+	// every site.
+	sites captoken.Token
+
+	ch      chan Event
+	dropped atomic.Int64
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func (s *subscription) wants(ev *Event) bool {
+	if !s.kinds[ev.Kind] {
+		return false
+	}
+	if eventKinds[ev.Kind].global {
+		// Kinds are filtered at subscribe time, so a scoped conn
+		// never asks for these.
+		return true
+	}
+	if s.siteID != "" && ev.SiteID != s.siteID {
+		return false
+	}
+	if s.scope != "" && ev.SiteID != s.scope && ev.Slug != s.scope {
+		return false
+	}
+	return s.sites.AllowsSite(ev.SiteID, ev.Slug)
+}
+
+// Publish sends an event to every subscription that wants it. data is
+// marshalled once; a marshal failure drops the event with a debug log.
+func (h *EventHub) Publish(kind, siteID, slug string, data any) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subs) == 0 {
+		return
+	}
+	ev := Event{Kind: kind, SiteID: siteID, Slug: slug, Time: time.Now().UTC()}
+	if data != nil {
+		body, err := json.Marshal(data)
+		if err != nil {
+			slog.Debug("events: marshal failed", "kind", kind, "err", err.Error())
+			return
+		}
+		ev.Data = body
+	}
+	for s := range h.subs {
+		if !s.wants(&ev) {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+func (h *EventHub) add(s *subscription) {
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+}
+
+func (h *EventHub) remove(s *subscription) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+// pump writes s's events to conn until ctx (the connection) ends or
+// the subscription is removed. A failed write means the peer is gone.
+func (h *EventHub) pump(ctx context.Context, conn *Conn, s *subscription) {
+	defer h.remove(s)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		case ev := <-s.ch:
+			if n := s.dropped.Swap(0); n > 0 {
+				body, _ := json.Marshal(map[string]int64{"count": n})
+				lost := Event{Kind: EventsDropped, Time: time.Now().UTC(), Data: body}
+				if err := conn.push(NotificationMethod, lost); err != nil {
+					return
+				}
+			}
+			if err := conn.push(NotificationMethod, ev); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ─── events.subscribe ──────────────────────────────────────────────────
+
+// makeEventsSubscribe starts streaming events to the calling connection
+// as "event" notifications. Without kinds it subscribes to every kind
+// the connection may receive; a site narrows site events to that site.
+// The readonly profile cannot ask for full-only kinds, and an MCP-scoped
+// connection only ever sees its own site, as does a connection whose
+// token is limited to some sites. A second call replaces the first
+// subscription.
+func makeEventsSubscribe(svc SiteService, hub *EventHub) Handler {
+	type p struct {
+		siteRef
+		Kinds []string `json:"kinds,omitempty"`
+	}
+	return func(ctx context.Context, conn *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+
+		siteLimited := conn.siteLimited()
+		kinds := args.Kinds
+		if len(kinds) == 0 {
+			for k, meta := range eventKinds {
+				if meta.fullOnly && conn.Profile == ProfileReadOnly {
+					continue
+				}
+				if meta.global && siteLimited {
+					continue
+				}
+				kinds = append(kinds, k)
+			}
+		}
+		set := make(map[string]bool, len(kinds))
+		for _, k := range kinds {
+			meta, ok := eventKinds[k]
+			switch {
+			case !ok:
+				return nil, NewMethodError(codeInvalidParams, "unknown event kind: "+k, nil)
+			case meta.fullOnly && conn.Profile == ProfileReadOnly:
+				return nil, NewMethodError(CodeForbidden, "event kind not permitted in readonly profile: "+k, nil)
+			case meta.global && siteLimited:
+				return nil, NewMethodError(CodeForbidden, "event kind not permitted on a site-scoped connection: "+k, nil)
+			}
+			set[k] = true
+		}
+
+		sub := &subscription{
+			kinds: set,
+			scope: conn.MCPScope,
+			sites: captoken.Token{Sites: conn.tokenSites},
+			ch:    make(chan Event, subscriberBuffer),
+			done:  make(chan struct{}),
+		}
+		if args.SiteID != "" || args.Slug != "" {
+			// Check the site the subscription will follow, not
+			// whichever of siteId and slug happens to match.
+			siteID, slug, err := canonicalSiteRef(svc, params)
+			if err != nil {
+				return nil, NewMethodError(CodeForbidden, err.Error(), nil)
+			}
+			if conn.MCPScope != "" && !scopeMatches(conn.MCPScope, siteID, slug) {
+				return nil, NewMethodError(CodeForbidden, fmt.Sprintf("mcp scope: requested %q does not match conn scope %q",
+					firstNonEmpty(slug, siteID), conn.MCPScope), nil)
+			}
+			if !sub.sites.AllowsSite(siteID, slug) {
+				return nil, NewMethodError(CodeForbidden, "token does not allow this site", nil)
+			}
+			id, err := resolveSite(svc, args.siteRef)
+			if err != nil {
+				return nil, err
+			}
+			sub.siteID = id
+		}
+
+		if conn.sub != nil {
+			hub.remove(conn.sub)
+		}
+		conn.sub = sub
+		hub.add(sub)
+		go hub.pump(ctx, conn, sub)
+
+		out := make([]string, 0, len(set))
+		for k := range set {
+			out = append(out, k)
+		}
+		slices.Sort(out)
+		return map[string]any{"kinds": out, "siteId": sub.siteID}, nil
+	}
+}
+
+// makeEventsUnsubscribe stops the connection's subscription. Events
+// already on the wire may still arrive after the response.
+func makeEventsUnsubscribe(hub *EventHub) Handler {
+	return func(_ context.Context, conn *Conn, _ json.RawMessage) (any, error) {
+		if conn.sub == nil {
+			return map[string]any{"unsubscribed": false}, nil
+		}
+		hub.remove(conn.sub)
+		conn.sub = nil
+		return map[string]any{"unsubscribed": true}, nil
+	}
+}
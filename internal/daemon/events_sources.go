@@ -0,0 +1,168 @@
+package daemon
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/health"
+	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// AttachSites publishes sm's lifecycle callbacks into the hub. Callbacks
+// already set (the GUI's, from ui.New) keep firing first, so call this
+// after the UI is wired.
+func (h *EventHub) AttachSites(sm *sites.SiteManager) {
+	// Slugs ride along on every event so scoped connections can match
+	// either form. Cached because pull progress fires per layer tick.
+	var slugs sync.Map // siteID → slug
+	slugOf := func(siteID string) string {
+		if v, ok := slugs.Load(siteID); ok {
+			return v.(string)
+		}
+		site, err := sm.GetSite(siteID)
+		if err != nil || site == nil {
+			return ""
+		}
+		slugs.Store(siteID, site.Slug)
+		return site.Slug
+	}
+
+	prevSite := sm.OnSiteUpdated
+	sm.OnSiteUpdated = func(site *types.Site) {
+		if prevSite != nil {
+			prevSite(site)
+		}
+		if site == nil {
+			return
+		}
+		slugs.Store(site.ID, site.Slug)
+		h.Publish(EventSiteUpdated, site.ID, site.Slug, map[string]any{"started": site.Started})
+	}
+
+	prevSites := sm.OnSitesUpdated
+	sm.OnSitesUpdated = func(all []types.Site) {
+		if prevSites != nil {
+			prevSites(all)
+		}
+		for i := range all {
+			slugs.Store(all[i].ID, all[i].Slug)
+		}
+		h.Publish(EventSitesChanged, "", "", map[string]any{"count": len(all)})
+	}
+
+	prevStart := sm.OnStepStart
+	sm.OnStepStart = func(siteID string, s orch.StepResult) {
+		if prevStart != nil {
+			prevStart(siteID, s)
+		}
+		h.Publish(EventStepStart, siteID, slugOf(siteID), stepData(s))
+	}
+
+	prevDone := sm.OnStepDone
+	sm.OnStepDone = func(siteID string, s orch.StepResult) {
+		if prevDone != nil {
+			prevDone(siteID, s)
+		}
+		h.Publish(EventStepDone, siteID, slugOf(siteID), stepData(s))
+	}
+
+	prevPlan := sm.OnPlanDone
+	sm.OnPlanDone = func(siteID string, r orch.Result) {
+		if prevPlan != nil {
+			prevPlan(siteID, r)
+		}
+		h.Publish(EventPlanDone, siteID, slugOf(siteID), map[string]any{
+			"plan":       r.PlanName,
+			"durationMs": r.Duration.Milliseconds(),
+			"rolledBack": r.RolledBack,
+			"error":      errString(r.FinalError),
+		})
+	}
+
+	prevPull := sm.OnPullProgress
+	sm.OnPullProgress = func(siteID string, p docker.PullProgress) {
+		if prevPull != nil {
+			prevPull(siteID, p)
+		}
+		h.Publish(EventPullProgress, siteID, slugOf(siteID), map[string]any{
+			"image":   p.Image,
+			"status":  p.Status,
+			"current": p.Current,
+			"total":   p.Total,
+			"layers":  p.LayerCount,
+		})
+	}
+
+	prevHook := sm.OnHookOutput
+	sm.OnHookOutput = func(siteID, line string, stderr bool) {
+		if prevHook != nil {
+			prevHook(siteID, line, stderr)
+		}
+		h.Publish(EventHookOutput, siteID, slugOf(siteID), map[string]any{
+			"line":   secrets.RedactString(line),
+			"stderr": stderr,
+		})
+	}
+}
+
+// AttachHealth publishes r's findings whenever the set changes. The
+// runner re-publishes an unchanged set every cadence tick and around
+// each RunNow; those are not events.
+func (h *EventHub) AttachHealth(r *health.Runner) {
+	var (
+		mu   sync.Mutex
+		last string
+		seen bool
+	)
+	r.Subscribe(func(snap health.Snapshot) {
+		if snap.Running {
+			return
+		}
+		keys := make([]string, 0, len(snap.Findings))
+		findings := make([]map[string]any, 0, len(snap.Findings))
+		for _, f := range snap.Findings {
+			keys = append(keys, f.ID+"|"+f.DedupKey+"|"+f.Severity.String())
+			findings = append(findings, map[string]any{
+				"id":          f.ID,
+				"severity":    f.Severity.String(),
+				"title":       f.Title,
+				"detail":      f.Detail,
+				"remediation": f.Remediation,
+				"dedupKey":    f.DedupKey,
+			})
+		}
+		slices.Sort(keys)
+		key := strings.Join(keys, "\n")
+
+		mu.Lock()
+		changed := !seen || key != last
+		last, seen = key, true
+		mu.Unlock()
+		if changed {
+			h.Publish(EventHealthFindings, "", "", map[string]any{"findings": findings})
+		}
+	})
+}
+
+func stepData(s orch.StepResult) map[string]any {
+	return map[string]any{
+		"step":       s.Name,
+		"status":     string(s.Status),
+		"durationMs": s.Duration.Milliseconds(),
+		"error":      errString(s.Error),
+	}
+}
+
+// errString renders a lifecycle error for the wire, redacted like RPC
+// error messages are.
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return secrets.RedactString(err.Error())
+}
@@ -4,11 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/PeterBooker/locorum/internal/captoken"
+	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/mail"
+	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/reqlog"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/storage"
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
 	"github.com/PeterBooker/locorum/internal/types"
 )
 
@@ -26,20 +36,79 @@ type SiteService interface {
 	StopSite(ctx context.Context, siteID string) error
 
 	CreateWorktreeSite(ctx context.Context, opts sites.CreateWorktreeOptions) (*sites.CreateWorktreeResult, error)
+	ImportSite(ctx context.Context, archivePath string, opts sites.ImportSiteOptions) (*sites.ImportSiteResult, error)
+	CreateSiteFromConfig(ctx context.Context, dir string, opts sites.CreateFromConfigOptions) (*sites.CreateFromConfigResult, error)
 	DeleteSiteWithOptions(ctx context.Context, siteID string, opts sites.DeleteOptions) error
 
 	RecentActivity(siteID string) ([]storage.ActivityEvent, error)
 	GetActivity(siteID string, limit int) ([]storage.ActivityEvent, error)
 
 	GetContainerLogs(ctx context.Context, siteID, service string, lines int) (string, error)
+	StreamSiteLogs(ctx context.Context, siteID string, q sites.LogQuery) (<-chan sites.SiteLogLine, error)
+	SiteRequests(siteID string, f reqlog.Filter) ([]reqlog.Entry, error)
+	ListMail(ctx context.Context, siteID string, q mail.Query) (*mail.Page, error)
+	GetMail(ctx context.Context, siteID, id string) (*mail.Message, error)
+	PurgeMail(ctx context.Context, siteID, search string) error
 	ExecWPCLI(ctx context.Context, siteID string, args []string) (string, error)
 
+	SetXdebugMode(siteID, mode string) error
+	SetMailMode(siteID string, s sites.MailSettings) error
+	DBUILoginURL(siteID string) (string, error)
+
+	SiteAccess(siteID string) (*sites.SiteAccess, error)
+	SetBasicAuth(ctx context.Context, siteID string, enabled bool) error
+	RotateAuthPassword(ctx context.Context, siteID string) error
+	SetIPAllowlist(ctx context.Context, siteID string, entries []string) error
+	LANAllowlist() string
+	AlwaysAllowed() []string
+	ClientsNATed() bool
+
+	ConfigDrift(siteID string) (*sites.ConfigDrift, error)
+	ConfigYAML(siteID string) ([]byte, error)
+	ApplyConfigYAML(ctx context.Context, siteID string) (*sites.ConfigDrift, error)
+	DiscardConfigYAML(siteID string) error
+
+	Pull(ctx context.Context, siteID string, opts sites.PullOptions) (*sites.PullResult, error)
+	Push(ctx context.Context, siteID string, opts sites.PushOptions) (*sites.PushResult, error)
+	ListRemotes(siteID string) ([]remote.Remote, error)
+	AddRemote(r *remote.Remote) error
+	RemoveRemote(siteID, name string) error
+
 	Snapshot(ctx context.Context, siteID, label string) (string, error)
 	ListSnapshots(slug string) ([]sites.SnapshotInfo, error)
 	RestoreSnapshot(ctx context.Context, siteID, snapshotPath string, opts sites.RestoreSnapshotOptions) error
 
 	RunHookNow(ctx context.Context, h hooks.Hook) (hooks.Result, error)
 	ListSiteHooks(siteID string) ([]hooks.Hook, error)
+	HookRunLogs(siteID string, limit int) ([]sites.HookRunLog, error)
+
+	CreateSite(site types.Site) (*types.Site, error)
+	PreviewCreateSite(ctx context.Context, site types.Site) (orch.DryResult, error)
+	ImportDB(ctx context.Context, siteID, hostPath string, opts sites.ImportDBOptions) error
+	PreviewImportDB(ctx context.Context, siteID, hostPath string, opts sites.ImportDBOptions) (orch.DryResult, error)
+	SearchReplace(ctx context.Context, siteID string, pairs []sites.SearchReplacePair) (string, error)
+	PreviewSearchReplace(ctx context.Context, siteID string, pairs []sites.SearchReplacePair) (orch.DryResult, error)
+	ExportSite(ctx context.Context, siteID, destPath string) error
+	ConfineSitePath(siteID, path string, write bool) (string, error)
+	PreviewExport(ctx context.Context, siteID, destPath string) (orch.DryResult, error)
+	UpdateSiteVersionsWithEngine(ctx context.Context, siteID string, change sites.VersionsChange) error
+	PreviewVersionsChange(ctx context.Context, siteID string, change sites.VersionsChange) (orch.DryResult, error)
+	MigrateEngine(ctx context.Context, siteID string, opts sites.MigrateEngineOptions) error
+	PreviewMigrateEngine(ctx context.Context, siteID string, opts sites.MigrateEngineOptions) (orch.DryResult, error)
+	EnableLAN(ctx context.Context, siteID string) error
+	DisableLAN(ctx context.Context, siteID string) error
+	PreviewLAN(ctx context.Context, siteID string, on bool) (orch.DryResult, error)
+	SetSPXEnabled(siteID string, enabled bool) error
+	PreviewSPX(ctx context.Context, siteID string, enabled bool) (orch.DryResult, error)
+
+	CertInventory(ctx context.Context) (tlspkg.Inventory, error)
+	RenewCerts(ctx context.Context, all bool) (*sites.CertRenewResult, error)
+
+	CreateToken(opts sites.TokenOptions) (string, *captoken.Token, error)
+	ListTokens() ([]captoken.Token, error)
+	RevokeToken(label string) error
+	// TokenAuthority makes the service the dispatcher's token lookup.
+	TokenAuthority
 
 	// Used to resolve slug → site for slug-addressed methods so MCP
 	// tools can pass a slug without first asking for an id.
@@ -52,24 +121,78 @@ type SiteService interface {
 // Site-scoped MCP enforcement applies to every method whose Params have
 // a "siteId" or "slug" field — see SiteScoped() option in server.go.
 func RegisterMethods(s *Server, svc SiteService) {
+	s.SetSiteDirectory(svc)
+
 	// ─── Read-only methods ──────────────────────────────────────────
 	s.Register("site.list", makeSiteList(svc), ReadOnly())
 	s.Register("site.describe", makeSiteDescribe(svc), ReadOnly(), SiteScoped())
 	s.Register("site.recentActivity", makeRecentActivity(svc), ReadOnly(), SiteScoped())
 	s.Register("site.activity", makeGetActivity(svc), ReadOnly(), SiteScoped())
 	s.Register("site.logs", makeContainerLogs(svc), ReadOnly(), SiteScoped())
+	s.Register("site.logs_stream", makeLogsStream(svc), ReadOnly(), SiteScoped())
+	s.Register("site.requests", makeSiteRequests(svc), ReadOnly(), SiteScoped())
+	s.Register("mail.list", makeMailList(svc, false), ReadOnly(), SiteScoped())
+	s.Register("mail.search", makeMailList(svc, true), ReadOnly(), SiteScoped())
+	s.Register("mail.get", makeMailGet(svc), ReadOnly(), SiteScoped())
 	s.Register("snapshot.list", makeSnapshotList(svc), ReadOnly(), SiteScoped())
 	s.Register("hook.list", makeHookList(svc), ReadOnly(), SiteScoped())
+	s.Register("site.config_diff", makeConfigDiff(svc), ReadOnly(), SiteScoped())
+	s.Register("site.config_yaml", makeConfigYAML(svc), ReadOnly(), SiteScoped())
+	s.Register("remote.list", makeRemoteList(svc), ReadOnly(), SiteScoped())
+	s.Register("cert.list", makeCertList(svc), ReadOnly())
+	// Profile and scope are checked per event kind inside the handler.
+	s.Register("events.subscribe", makeEventsSubscribe(svc, s.events), ReadOnly())
+	s.Register("events.unsubscribe", makeEventsUnsubscribe(s.events), ReadOnly())
 
 	// ─── Mutating methods (Full only) ───────────────────────────────
 	s.Register("site.start", makeSiteStart(svc), SiteScoped())
 	s.Register("site.stop", makeSiteStop(svc), SiteScoped())
 	s.Register("site.wp", makeWPCLI(svc), SiteScoped())
+	s.Register("site.xdebug", makeSiteXdebug(svc), SiteScoped())
+	s.Register("site.mail", makeSiteMail(svc), SiteScoped())
+	// site.db_ui mints a link that logs into the site's database, so it
+	// is Full-only even though it changes nothing.
+	s.Register("site.db_ui", makeSiteDBUI(svc), SiteScoped())
+	// site.access returns the basic-auth password even when it changes
+	// nothing, so it is Full-only like the mutating methods.
+	s.Register("site.access", makeSiteAccess(svc), SiteScoped())
+	s.Register("site.sync_config", makeSyncConfig(svc), SiteScoped())
+	s.Register("site.pull", makeSitePull(svc), SiteScoped())
+	s.Register("site.push", makeSitePush(svc), SiteScoped())
+	s.Register("remote.add", makeRemoteAdd(svc), SiteScoped())
+	s.Register("remote.remove", makeRemoteRemove(svc), SiteScoped())
 	s.Register("site.delete", makeSiteDelete(svc), SiteScoped())
-	s.Register("site.create_worktree", makeWorktreeCreate(svc))
+	s.Register("mail.purge", makeMailPurge(svc), SiteScoped())
+	// Site-creating methods carry no site reference; Unscoped keeps
+	// a conn confined to one site from adding others, as checkToken
+	// does for site-bound tokens.
+	s.Register("site.create_worktree", makeWorktreeCreate(svc), Unscoped())
+	s.Register("site.import", makeSiteImport(svc), Unscoped())
+	s.Register("site.create_from_config", makeSiteFromConfig(svc), Unscoped())
+	// The methods below take dryRun, which previews through orch.Dry
+	// and changes nothing; they stay Full-only even then, since the
+	// preview is of an operation the readonly profile may not run.
+	s.Register("site.create", makeSiteCreate(svc), Unscoped())
+	s.Register("site.import_db", makeImportDB(svc), SiteScoped())
+	s.Register("site.search_replace", makeSearchReplace(svc), SiteScoped())
+	s.Register("site.export", makeSiteExport(svc), SiteScoped())
+	s.Register("site.versions", makeSiteVersions(svc), SiteScoped())
+	s.Register("site.migrate_engine", makeMigrateEngine(svc), SiteScoped())
+	s.Register("site.lan", makeSiteLAN(svc), SiteScoped())
+	s.Register("site.spx", makeSiteSPX(svc), SiteScoped())
 	s.Register("snapshot.create", makeSnapshotCreate(svc), SiteScoped())
 	s.Register("snapshot.restore", makeSnapshotRestore(svc), SiteScoped())
 	s.Register("hook.run", makeHookRun(svc), SiteScoped())
+	// Run logs capture hook output verbatim, redacted only for the
+	// secrets Locorum knows about; like hook.output events, Full only.
+	s.Register("hook.logs", makeHookLogs(svc), SiteScoped())
+	s.Register("cert.renew", makeCertRenew(svc))
+
+	// ─── Token management (no capability token) ─────────────────────
+	s.SetTokenAuthority(svc)
+	s.Register("token.create", makeTokenCreate(svc), OwnerOnly())
+	s.Register("token.list", makeTokenList(svc), OwnerOnly())
+	s.Register("token.revoke", makeTokenRevoke(svc), OwnerOnly())
 }
 
 // ─── Param shapes ──────────────────────────────────────────────────────
@@ -104,6 +227,15 @@ func resolveSite(svc SiteService, ref siteRef) (string, error) {
 	return "", NotFound("site")
 }
 
+// resolveOptionalSite is resolveSite for methods where the site is a
+// filter: an empty ref resolves to "" (all sites) instead of an error.
+func resolveOptionalSite(svc SiteService, ref siteRef) (string, error) {
+	if ref.SiteID == "" && ref.Slug == "" {
+		return "", nil
+	}
+	return resolveSite(svc, ref)
+}
+
 // listOptions toggles the optional sections on site.list. The defaults
 // are cheap (no Docker / disk lookups). Clients pass true on the
 // fields they want.
@@ -281,7 +413,7 @@ func makeContainerLogs(svc SiteService) Handler {
 			return nil, NewMethodError(codeInvalidParams, "service is required", nil)
 		}
 		switch args.Service {
-		case "web", "php", "database", "redis":
+		case "web", "php", "database", "redis", "valkey", "memcached":
 		default:
 			return nil, NewMethodError(codeInvalidParams, "unknown service: "+args.Service, nil)
 		}
@@ -297,14 +429,24 @@ func makeContainerLogs(svc SiteService) Handler {
 	}
 }
 
-// ─── site.wp (wp-cli) ──────────────────────────────────────────────────
+// ─── site.logs_stream ──────────────────────────────────────────────────
 
-func makeWPCLI(svc SiteService) Handler {
+// makeLogsStream pushes a site's container logs to the caller as
+// "log.line" event notifications, then returns how many it sent. With
+// follow set it returns only when until passes, the containers stop or
+// the client goes away — the connection's context is the cancel
+// signal, so a CLI exiting on Ctrl-C ends the stream.
+func makeLogsStream(svc SiteService) Handler {
 	type p struct {
 		siteRef
-		Args []string `json:"args"`
+		Services []string  `json:"services,omitempty"`
+		Since    time.Time `json:"since"`
+		Until    time.Time `json:"until"`
+		Lines    int       `json:"lines,omitempty"`
+		Grep     string    `json:"grep,omitempty"`
+		Follow   bool      `json:"follow,omitempty"`
 	}
-	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+	return func(ctx context.Context, conn *Conn, params json.RawMessage) (any, error) {
 		var args p
 		if err := unmarshalParams(params, &args); err != nil {
 			return nil, err
@@ -313,34 +455,70 @@ func makeWPCLI(svc SiteService) Handler {
 		if err != nil {
 			return nil, err
 		}
-		if len(args.Args) == 0 {
-			return nil, NewMethodError(codeInvalidParams, "args must contain at least one wp-cli argument", nil)
+		for _, s := range args.Services {
+			switch s {
+			case "web", "php", "database", "redis", "valkey", "memcached":
+			default:
+				return nil, NewMethodError(codeInvalidParams, "unknown service: "+s, nil)
+			}
 		}
-		// Defence in depth: forbid raw shell metacharacters in args.
-		// ExecInContainer passes argv directly, so this is informational
-		// only — but better to reject early than ship the surprise to
-		// the user.
-		for _, a := range args.Args {
-			if strings.ContainsAny(a, "\x00") {
-				return nil, NewMethodError(codeInvalidParams, "wp-cli args must not contain NUL bytes", nil)
+		if !args.Since.IsZero() && !args.Until.IsZero() && args.Until.Before(args.Since) {
+			return nil, NewMethodError(codeInvalidParams, "until is before since", nil)
+		}
+		q := sites.LogQuery{
+			Services: args.Services,
+			Since:    args.Since,
+			Until:    args.Until,
+			Tail:     args.Lines,
+			Follow:   args.Follow,
+		}
+		if args.Grep != "" {
+			re, err := regexp.Compile(args.Grep)
+			if err != nil {
+				return nil, NewMethodError(codeInvalidParams, "grep: "+err.Error(), nil)
 			}
+			q.Grep = re
 		}
-		out, err := svc.ExecWPCLI(ctx, id, args.Args)
-		if err != nil {
+
+		ch, err := svc.StreamSiteLogs(ctx, id, q)
+		switch {
+		case errors.Is(err, sites.ErrSiteNotRunning):
+			return nil, NewMethodError(CodeConflict, err.Error(), err)
+		case errors.Is(err, sites.ErrUnknownService):
+			return nil, NewMethodError(codeInvalidParams, err.Error(), err)
+		case err != nil:
 			return nil, mapNotFoundError(err)
 		}
-		return map[string]any{"output": out}, nil
+
+		sent := 0
+		for l := range ch {
+			stream := "stdout"
+			if l.Stream == docker.LogStreamStderr {
+				stream = "stderr"
+			}
+			body, _ := json.Marshal(map[string]string{"service": l.Service, "stream": stream, "text": l.Text})
+			ev := Event{Kind: EventLogLine, SiteID: id, Time: l.Time.UTC(), Data: body}
+			if err := conn.push(NotificationMethod, ev); err != nil {
+				// The peer is gone and so is anyone to answer. The
+				// reader's EOF cancels ctx, which stops the streams.
+				return nil, err
+			}
+			sent++
+		}
+		return map[string]any{"siteId": id, "lines": sent}, nil
 	}
 }
 
-// ─── snapshot.{create,list,restore} ────────────────────────────────────
+// ─── site.requests ─────────────────────────────────────────────────────
 
-func makeSnapshotCreate(svc SiteService) Handler {
+func makeSiteRequests(svc SiteService) Handler {
 	type p struct {
 		siteRef
-		Label string `json:"label,omitempty"`
+		Status string `json:"status,omitempty"`
+		Path   string `json:"path,omitempty"`
+		Limit  int    `json:"limit,omitempty"`
 	}
-	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
 		var args p
 		if err := unmarshalParams(params, &args); err != nil {
 			return nil, err
@@ -349,97 +527,122 @@ func makeSnapshotCreate(svc SiteService) Handler {
 		if err != nil {
 			return nil, err
 		}
-		path, err := svc.Snapshot(ctx, id, args.Label)
+		if err := reqlog.ValidateStatus(args.Status); err != nil {
+			return nil, NewMethodError(codeInvalidParams, err.Error(), nil)
+		}
+		limit := args.Limit
+		if limit <= 0 {
+			limit = 100
+		}
+		entries, err := svc.SiteRequests(id, reqlog.Filter{Status: args.Status, Path: args.Path, Limit: limit})
 		if err != nil {
 			return nil, mapNotFoundError(err)
 		}
-		return map[string]any{"path": path}, nil
+		return map[string]any{"requests": entries, "count": len(entries)}, nil
 	}
 }
 
-func makeSnapshotList(svc SiteService) Handler {
-	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
-		var ref siteRef
-		if err := unmarshalParams(params, &ref); err != nil {
+// ─── mail.{list,search,get,purge} ──────────────────────────────────────
+
+// defaultMailLimit caps a mail page when the caller sets no limit.
+const defaultMailLimit = 50
+
+// makeMailList lists captured mail, optionally for one site. Query is
+// in the capture server's search syntax; mail.search requires it so a
+// typo'd empty search never silently returns the whole mailbox.
+func makeMailList(svc SiteService, requireQuery bool) Handler {
+	type p struct {
+		siteRef
+		Query string `json:"query,omitempty"`
+		Start int    `json:"start,omitempty"`
+		Limit int    `json:"limit,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
 			return nil, err
 		}
-		// snapshot.list is keyed by slug on disk, not site id, so we
-		// need to resolve to a slug specifically. Look up the row.
-		rows, err := svc.GetSites()
+		if requireQuery && strings.TrimSpace(args.Query) == "" {
+			return nil, NewMethodError(codeInvalidParams, "query is required", nil)
+		}
+		if args.Start < 0 || args.Limit < 0 {
+			return nil, NewMethodError(codeInvalidParams, "start and limit must not be negative", nil)
+		}
+		id, err := resolveOptionalSite(svc, args.siteRef)
 		if err != nil {
 			return nil, err
 		}
-		var slug string
-		for _, s := range rows {
-			if (ref.SiteID != "" && s.ID == ref.SiteID) || (ref.Slug != "" && s.Slug == ref.Slug) {
-				slug = s.Slug
-				break
-			}
-		}
-		if slug == "" {
-			return nil, NotFound("site")
+		limit := args.Limit
+		if limit == 0 {
+			limit = defaultMailLimit
 		}
-		snaps, err := svc.ListSnapshots(slug)
+		page, err := svc.ListMail(ctx, id, mail.Query{Search: args.Query, Start: args.Start, Limit: limit})
 		if err != nil {
-			return nil, err
+			return nil, mapNotFoundError(err)
 		}
-		return map[string]any{"snapshots": snaps}, nil
+		return page, nil
 	}
 }
 
-func makeSnapshotRestore(svc SiteService) Handler {
+func makeMailGet(svc SiteService) Handler {
 	type p struct {
 		siteRef
-		Path     string `json:"path"`
-		Force    bool   `json:"force,omitempty"`
-		SkipHook bool   `json:"skipHook,omitempty"`
+		ID string `json:"id"`
 	}
 	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
 		var args p
 		if err := unmarshalParams(params, &args); err != nil {
 			return nil, err
 		}
-		if args.Path == "" {
-			return nil, NewMethodError(codeInvalidParams, "snapshot path is required", nil)
+		if args.ID == "" {
+			return nil, NewMethodError(codeInvalidParams, "id is required", nil)
 		}
-		id, err := resolveSite(svc, args.siteRef)
+		id, err := resolveOptionalSite(svc, args.siteRef)
 		if err != nil {
 			return nil, err
 		}
-		opts := sites.RestoreSnapshotOptions{
-			AllowEngineMismatch: args.Force,
-		}
-		if err := svc.RestoreSnapshot(ctx, id, args.Path, opts); err != nil {
+		msg, err := svc.GetMail(ctx, id, args.ID)
+		if err != nil {
 			return nil, mapNotFoundError(err)
 		}
-		return map[string]any{"restored": true}, nil
+		return msg, nil
 	}
 }
 
-// ─── hook.list / hook.run ──────────────────────────────────────────────
-
-func makeHookList(svc SiteService) Handler {
-	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
-		var ref siteRef
-		if err := unmarshalParams(params, &ref); err != nil {
+// makeMailPurge deletes captured mail. Emptying the whole mailbox needs
+// all: true, so a call that lost its site filter fails instead.
+func makeMailPurge(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Query string `json:"query,omitempty"`
+		All   bool   `json:"all,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
 			return nil, err
 		}
-		id, err := resolveSite(svc, ref)
+		id, err := resolveOptionalSite(svc, args.siteRef)
 		if err != nil {
 			return nil, err
 		}
-		rows, err := svc.ListSiteHooks(id)
-		if err != nil {
-			return nil, err
+		filtered := id != "" || strings.TrimSpace(args.Query) != ""
+		if filtered == args.All {
+			return nil, NewMethodError(codeInvalidParams, "pass a site or query to purge, or all: true for the whole mailbox (not both)", nil)
 		}
-		return map[string]any{"hooks": rows}, nil
+		if err := svc.PurgeMail(ctx, id, args.Query); err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"purged": true, "siteId": id, "query": args.Query}, nil
 	}
 }
 
-func makeHookRun(svc SiteService) Handler {
+// ─── site.wp (wp-cli) ──────────────────────────────────────────────────
+
+func makeWPCLI(svc SiteService) Handler {
 	type p struct {
 		siteRef
-		HookID int64 `json:"hookId"`
+		Args []string `json:"args"`
 	}
 	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
 		var args p
@@ -450,81 +653,123 @@ func makeHookRun(svc SiteService) Handler {
 		if err != nil {
 			return nil, err
 		}
-		// Find the hook by id within this site so the caller can't
-		// run a hook that belongs to a different site.
-		hookList, err := svc.ListSiteHooks(id)
-		if err != nil {
-			return nil, err
+		if len(args.Args) == 0 {
+			return nil, NewMethodError(codeInvalidParams, "args must contain at least one wp-cli argument", nil)
 		}
-		var target *hooks.Hook
-		for i := range hookList {
-			if hookList[i].ID == args.HookID {
-				target = &hookList[i]
-				break
+		// Defence in depth: forbid raw shell metacharacters in args.
+		// ExecInContainer passes argv directly, so this is informational
+		// only — but better to reject early than ship the surprise to
+		// the user.
+		for _, a := range args.Args {
+			if strings.ContainsAny(a, "\x00") {
+				return nil, NewMethodError(codeInvalidParams, "wp-cli args must not contain NUL bytes", nil)
 			}
 		}
-		if target == nil {
-			return nil, NotFound("hook")
+		out, err := svc.ExecWPCLI(ctx, id, args.Args)
+		if err != nil {
+			return nil, mapNotFoundError(err)
 		}
-		res, err := svc.RunHookNow(ctx, *target)
+		return map[string]any{"output": out}, nil
+	}
+}
+
+// ─── site.xdebug ───────────────────────────────────────────────────────
+
+func makeSiteXdebug(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Mode string `json:"mode"`
+	}
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
 		if err != nil {
 			return nil, err
 		}
-		return map[string]any{"result": res}, nil
+		if !sites.ValidXdebugMode(args.Mode) {
+			return nil, NewMethodError(codeInvalidParams,
+				"mode must be one of: "+strings.Join(sites.XdebugModes, ", "), nil)
+		}
+		if err := svc.SetXdebugMode(id, args.Mode); err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"siteId": id, "mode": args.Mode}, nil
 	}
 }
 
-// ─── site.create_worktree ──────────────────────────────────────────────
+// ─── site.mail ─────────────────────────────────────────────────────────
 
-func makeWorktreeCreate(svc SiteService) Handler {
+// makeSiteMail sets where a site's outgoing mail goes. The relay
+// password is accepted but never echoed back.
+func makeSiteMail(svc SiteService) Handler {
 	type p struct {
-		Name         string `json:"name"`
-		GitRemote    string `json:"gitRemote"`
-		Branch       string `json:"branch"`
-		ParentSlug   string `json:"parentSlug,omitempty"`
-		CloneDB      bool   `json:"cloneDb,omitempty"`
-		PHPVersion   string `json:"phpVersion,omitempty"`
-		DBEngine     string `json:"dbEngine,omitempty"`
-		DBVersion    string `json:"dbVersion,omitempty"`
-		RedisVersion string `json:"redisVersion,omitempty"`
-		WorktreeRoot string `json:"worktreeRoot,omitempty"`
-		DryRun       bool   `json:"dryRun,omitempty"`
+		siteRef
+		sites.MailSettings
 	}
-	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
 		var args p
 		if err := unmarshalParams(params, &args); err != nil {
 			return nil, err
 		}
-		opts := sites.CreateWorktreeOptions{
-			Name:         args.Name,
-			GitRemote:    args.GitRemote,
-			Branch:       args.Branch,
-			ParentSlug:   args.ParentSlug,
-			CloneDB:      args.CloneDB,
-			PHPVersion:   args.PHPVersion,
-			DBEngine:     args.DBEngine,
-			DBVersion:    args.DBVersion,
-			RedisVersion: args.RedisVersion,
-			WorktreeRoot: args.WorktreeRoot,
-			DryRun:       args.DryRun,
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
 		}
-		res, err := svc.CreateWorktreeSite(ctx, opts)
+		norm, err := sites.NormaliseMailSettings(args.MailSettings)
+		if err != nil {
+			return nil, NewMethodError(codeInvalidParams, err.Error(), nil)
+		}
+		if err := svc.SetMailMode(id, norm); err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		norm.RelayPassword = ""
+		return map[string]any{"siteId": id, "mail": norm}, nil
+	}
+}
+
+// ─── site.db_ui ────────────────────────────────────────────────────────
+
+// makeSiteDBUI returns a short-lived auto-login link for the global DB
+// UI, scoped to the site's database.
+func makeSiteDBUI(svc SiteService) Handler {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args siteRef
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args)
 		if err != nil {
 			return nil, err
 		}
-		return res, nil
+		u, err := svc.DBUILoginURL(id)
+		if err != nil {
+			if errors.Is(err, sites.ErrSiteNotRunning) {
+				return nil, NewMethodError(CodeConflict, err.Error(), err)
+			}
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"siteId": id, "url": u}, nil
 	}
 }
 
-// ─── site.delete ───────────────────────────────────────────────────────
+// ─── site.access ───────────────────────────────────────────────────────
 
-func makeSiteDelete(svc SiteService) Handler {
+// makeSiteAccess reads and updates a site's router access controls.
+// With no change params it only reports the current state. Allow
+// replaces the allowlist (allowLan adds the detected LAN subnet to
+// it); clearAllowlist removes it. clientsNATed in the result says the
+// allowlist cannot tell LAN clients from the host (Docker Desktop).
+func makeSiteAccess(svc SiteService) Handler {
 	type p struct {
 		siteRef
-		PurgeVolume   bool `json:"purgeVolume,omitempty"`
-		SkipSnapshot  bool `json:"skipSnapshot,omitempty"`
-		ForceWorktree bool `json:"forceWorktree,omitempty"`
-		DryRun        bool `json:"dryRun,omitempty"`
+		Auth           string   `json:"auth,omitempty"`
+		RotatePassword bool     `json:"rotatePassword,omitempty"`
+		Allow          []string `json:"allow,omitempty"`
+		AllowLAN       bool     `json:"allowLan,omitempty"`
+		ClearAllowlist bool     `json:"clearAllowlist,omitempty"`
 	}
 	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
 		var args p
@@ -535,36 +780,1165 @@ func makeSiteDelete(svc SiteService) Handler {
 		if err != nil {
 			return nil, err
 		}
-		opts := sites.DeleteOptions{
-			PurgeVolume:   args.PurgeVolume,
-			SkipSnapshot:  args.SkipSnapshot,
-			ForceWorktree: args.ForceWorktree,
-			DryRun:        args.DryRun,
+		if args.Auth != "" && args.Auth != "on" && args.Auth != "off" {
+			return nil, NewMethodError(codeInvalidParams, `auth must be "on" or "off"`, nil)
 		}
-		if err := svc.DeleteSiteWithOptions(ctx, id, opts); err != nil {
-			// Map worktree-dirty into a typed code so the CLI can
-			// prompt for --force without parsing strings.
-			if errors.Is(err, sites.ErrWorktreeDirty) {
-				return nil, NewMethodError(CodeConflict, err.Error(), err)
+		setAllowlist := len(args.Allow) > 0 || args.AllowLAN
+		if args.ClearAllowlist && setAllowlist {
+			return nil, NewMethodError(codeInvalidParams, "clearAllowlist cannot be combined with allow or allowLan", nil)
+		}
+		allow := args.Allow
+		if args.AllowLAN {
+			lan := svc.LANAllowlist()
+			if lan == "" {
+				return nil, NewMethodError(codeInvalidParams, "no LAN address detected; pass the allowed networks explicitly", nil)
 			}
+			allow = append(allow, lan)
+		}
+		if _, err := sites.NormaliseIPAllowlist(allow); err != nil {
+			return nil, NewMethodError(codeInvalidParams, err.Error(), nil)
+		}
+
+		if args.Auth != "" {
+			if err := svc.SetBasicAuth(ctx, id, args.Auth == "on"); err != nil {
+				return nil, mapNotFoundError(err)
+			}
+		}
+		if args.RotatePassword {
+			if err := svc.RotateAuthPassword(ctx, id); err != nil {
+				return nil, mapNotFoundError(err)
+			}
+		}
+		if setAllowlist || args.ClearAllowlist {
+			if err := svc.SetIPAllowlist(ctx, id, allow); err != nil {
+				return nil, mapNotFoundError(err)
+			}
+		}
+		access, err := svc.SiteAccess(id)
+		if err != nil {
 			return nil, mapNotFoundError(err)
 		}
-		return map[string]any{"deleted": !args.DryRun, "siteId": id, "dryRun": args.DryRun}, nil
+		return map[string]any{
+			"siteId":        id,
+			"access":        access,
+			"alwaysAllowed": svc.AlwaysAllowed(),
+			"clientsNATed":  svc.ClientsNATed(),
+		}, nil
 	}
 }
 
-// ─── helpers ───────────────────────────────────────────────────────────
+// ─── site.{config_diff,config_yaml,sync_config} ────────────────────────
 
-// unmarshalParams decodes params into v. Empty params is fine (v keeps
-// its zero values); malformed JSON is mapped to CodeInvalidParams.
-func unmarshalParams(params json.RawMessage, v any) error {
-	if len(params) == 0 {
-		return nil
-	}
-	if err := json.Unmarshal(params, v); err != nil {
-		return NewMethodError(codeInvalidParams, "invalid params: "+err.Error(), nil)
+// configDiffResult is the shape both config methods return. Changes is
+// never null so clients can range over it without a nil check.
+func configDiffResult(id string, d *sites.ConfigDrift) map[string]any {
+	out := map[string]any{"siteId": id, "changes": []sites.ConfigFieldChange{}}
+	if d != nil {
+		out["path"] = d.Path
+		out["changes"] = d.Changes
+		if len(d.Warnings) > 0 {
+			out["warnings"] = d.Warnings
+		}
 	}
-	return nil
+	return out
+}
+
+func makeConfigDiff(svc SiteService) Handler {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var ref siteRef
+		if err := unmarshalParams(params, &ref); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, ref)
+		if err != nil {
+			return nil, err
+		}
+		d, err := svc.ConfigDrift(id)
+		if err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return configDiffResult(id, d), nil
+	}
+}
+
+// makeConfigYAML returns the config.yaml Locorum would write for the
+// site now — the row's projection, not the file on disk.
+func makeConfigYAML(svc SiteService) Handler {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var ref siteRef
+		if err := unmarshalParams(params, &ref); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, ref)
+		if err != nil {
+			return nil, err
+		}
+		body, err := svc.ConfigYAML(id)
+		if err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"siteId": id, "yaml": string(body)}, nil
+	}
+}
+
+func makeSyncConfig(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		// Mode is "apply" (copy config.yaml onto the site) or
+		// "discard" (rewrite config.yaml from the site).
+		Mode string `json:"mode"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		switch args.Mode {
+		case "apply":
+			d, err := svc.ApplyConfigYAML(ctx, id)
+			if err != nil {
+				return nil, mapNotFoundError(err)
+			}
+			out := configDiffResult(id, d)
+			out["applied"] = d != nil
+			return out, nil
+		case "discard":
+			if err := svc.DiscardConfigYAML(id); err != nil {
+				return nil, mapNotFoundError(err)
+			}
+			return map[string]any{"siteId": id, "discarded": true}, nil
+		}
+		return nil, NewMethodError(codeInvalidParams, `mode must be "apply" or "discard"`, nil)
+	}
+}
+
+// ─── site.{pull,push} / remote.{list,add,remove} ───────────────────────
+
+func makeSitePull(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Remote        string                    `json:"remote"`
+		SkipDB        bool                      `json:"skipDb,omitempty"`
+		SkipUploads   bool                      `json:"skipUploads,omitempty"`
+		SearchReplace []sites.SearchReplacePair `json:"searchReplace,omitempty"`
+		DisableAuto   bool                      `json:"disableAuto,omitempty"`
+		SkipSnapshot  bool                      `json:"skipSnapshot,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Remote == "" {
+			return nil, NewMethodError(codeInvalidParams, "remote is required", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		res, err := svc.Pull(ctx, id, sites.PullOptions{
+			Remote:        args.Remote,
+			SkipDB:        args.SkipDB,
+			SkipUploads:   args.SkipUploads,
+			SearchReplace: args.SearchReplace,
+			DisableAuto:   args.DisableAuto,
+			SkipSnapshot:  args.SkipSnapshot,
+		})
+		if err != nil {
+			if errors.Is(err, sites.ErrSiteNotRunning) {
+				return nil, NewMethodError(CodeConflict, err.Error(), err)
+			}
+			return nil, mapNotFoundError(err)
+		}
+		return res, nil
+	}
+}
+
+func makeSitePush(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Remote        string                    `json:"remote"`
+		ConfirmHost   string                    `json:"confirmHost"`
+		AcceptHostKey string                    `json:"acceptHostKey,omitempty"`
+		SearchReplace []sites.SearchReplacePair `json:"searchReplace,omitempty"`
+		DisableAuto   bool                      `json:"disableAuto,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Remote == "" {
+			return nil, NewMethodError(codeInvalidParams, "remote is required", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		res, err := svc.Push(ctx, id, sites.PushOptions{
+			Remote:        args.Remote,
+			ConfirmHost:   args.ConfirmHost,
+			AcceptHostKey: args.AcceptHostKey,
+			SearchReplace: args.SearchReplace,
+			DisableAuto:   args.DisableAuto,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, sites.ErrPushNotConfirmed), errors.Is(err, sites.ErrHostKeyNotPinned):
+				return nil, NewMethodError(codeInvalidParams, err.Error(), err)
+			case errors.Is(err, sites.ErrSiteNotRunning), errors.Is(err, sites.ErrPushToProduction):
+				return nil, NewMethodError(CodeConflict, err.Error(), err)
+			}
+			return nil, mapNotFoundError(err)
+		}
+		return res, nil
+	}
+}
+
+func makeRemoteList(svc SiteService) Handler {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var ref siteRef
+		if err := unmarshalParams(params, &ref); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, ref)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := svc.ListRemotes(id)
+		if err != nil {
+			return nil, err
+		}
+		if rows == nil {
+			rows = []remote.Remote{}
+		}
+		return map[string]any{"remotes": rows}, nil
+	}
+}
+
+func makeRemoteAdd(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Name       string `json:"name"`
+		Host       string `json:"host"`
+		Port       int    `json:"port,omitempty"`
+		User       string `json:"user"`
+		Path       string `json:"path"`
+		KeyPath    string `json:"keyPath,omitempty"`
+		Password   string `json:"password,omitempty"`
+		DBCommand  string `json:"dbCommand,omitempty"`
+		Production bool   `json:"production,omitempty"`
+	}
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		r := &remote.Remote{
+			SiteID: id, Name: args.Name, Host: args.Host, Port: args.Port,
+			User: args.User, Path: args.Path, KeyPath: args.KeyPath,
+			Password: args.Password, DBCommand: args.DBCommand,
+			Production: args.Production,
+		}
+		if err := svc.AddRemote(r); err != nil {
+			switch {
+			case errors.Is(err, remote.ErrRemoteInvalid):
+				return nil, NewMethodError(codeInvalidParams, err.Error(), err)
+			case errors.Is(err, storage.ErrRemoteExists):
+				return nil, NewMethodError(CodeConflict, err.Error(), err)
+			}
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"remote": r}, nil
+	}
+}
+
+func makeRemoteRemove(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Name string `json:"name"`
+	}
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		if err := svc.RemoveRemote(id, args.Name); err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"removed": true}, nil
+	}
+}
+
+// ─── snapshot.{create,list,restore} ────────────────────────────────────
+
+func makeSnapshotCreate(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Label string `json:"label,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		path, err := svc.Snapshot(ctx, id, args.Label)
+		if err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"path": path}, nil
+	}
+}
+
+func makeSnapshotList(svc SiteService) Handler {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var ref siteRef
+		if err := unmarshalParams(params, &ref); err != nil {
+			return nil, err
+		}
+		// snapshot.list is keyed by slug on disk, not site id, so we
+		// need to resolve to a slug specifically. Look up the row.
+		rows, err := svc.GetSites()
+		if err != nil {
+			return nil, err
+		}
+		var slug string
+		for _, s := range rows {
+			if (ref.SiteID != "" && s.ID == ref.SiteID) || (ref.Slug != "" && s.Slug == ref.Slug) {
+				slug = s.Slug
+				break
+			}
+		}
+		if slug == "" {
+			return nil, NotFound("site")
+		}
+		snaps, err := svc.ListSnapshots(slug)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"snapshots": snaps}, nil
+	}
+}
+
+func makeSnapshotRestore(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Path     string `json:"path"`
+		Force    bool   `json:"force,omitempty"`
+		SkipHook bool   `json:"skipHook,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Path == "" {
+			return nil, NewMethodError(codeInvalidParams, "snapshot path is required", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		opts := sites.RestoreSnapshotOptions{
+			AllowEngineMismatch: args.Force,
+		}
+		if err := svc.RestoreSnapshot(ctx, id, args.Path, opts); err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"restored": true}, nil
+	}
+}
+
+// ─── hook.list / hook.run / hook.logs ──────────────────────────────────
+
+func makeHookList(svc SiteService) Handler {
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var ref siteRef
+		if err := unmarshalParams(params, &ref); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, ref)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := svc.ListSiteHooks(id)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"hooks": rows}, nil
+	}
+}
+
+func makeHookRun(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		HookID int64 `json:"hookId"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		// Find the hook by id within this site so the caller can't
+		// run a hook that belongs to a different site.
+		hookList, err := svc.ListSiteHooks(id)
+		if err != nil {
+			return nil, err
+		}
+		var target *hooks.Hook
+		for i := range hookList {
+			if hookList[i].ID == args.HookID {
+				target = &hookList[i]
+				break
+			}
+		}
+		if target == nil {
+			return nil, NotFound("hook")
+		}
+		res, err := svc.RunHookNow(ctx, *target)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"result": res}, nil
+	}
+}
+
+// hookLogsMax caps hook.logs' limit; each run carries up to 64 KiB.
+const hookLogsMax = 10
+
+func makeHookLogs(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		// Limit is how many runs to return, newest first. Default 1.
+		Limit int `json:"limit,omitempty"`
+	}
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Limit < 0 || args.Limit > hookLogsMax {
+			return nil, NewMethodError(codeInvalidParams, "limit must be between 1 and 10", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		runs, err := svc.HookRunLogs(id, args.Limit)
+		if err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"siteId": id, "runs": runs}, nil
+	}
+}
+
+// ─── site.create_worktree ──────────────────────────────────────────────
+
+func makeWorktreeCreate(svc SiteService) Handler {
+	type p struct {
+		Name         string `json:"name"`
+		GitRemote    string `json:"gitRemote"`
+		Branch       string `json:"branch"`
+		ParentSlug   string `json:"parentSlug,omitempty"`
+		CloneDB      bool   `json:"cloneDb,omitempty"`
+		PHPVersion   string `json:"phpVersion,omitempty"`
+		DBEngine     string `json:"dbEngine,omitempty"`
+		DBVersion    string `json:"dbVersion,omitempty"`
+		CacheBackend string `json:"cacheBackend,omitempty"`
+		CacheVersion string `json:"cacheVersion,omitempty"`
+		// RedisVersion is the pre-cache-backend spelling of CacheVersion,
+		// still accepted from older clients.
+		RedisVersion string `json:"redisVersion,omitempty"`
+		WorktreeRoot string `json:"worktreeRoot,omitempty"`
+		DryRun       bool   `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.CacheVersion == "" {
+			args.CacheVersion = args.RedisVersion
+		}
+		opts := sites.CreateWorktreeOptions{
+			Name:         args.Name,
+			GitRemote:    args.GitRemote,
+			Branch:       args.Branch,
+			ParentSlug:   args.ParentSlug,
+			CloneDB:      args.CloneDB,
+			PHPVersion:   args.PHPVersion,
+			DBEngine:     args.DBEngine,
+			DBVersion:    args.DBVersion,
+			CacheBackend: args.CacheBackend,
+			CacheVersion: args.CacheVersion,
+			WorktreeRoot: args.WorktreeRoot,
+			DryRun:       args.DryRun,
+		}
+		res, err := svc.CreateWorktreeSite(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// ─── site.import ───────────────────────────────────────────────────────
+
+func makeSiteImport(svc SiteService) Handler {
+	type p struct {
+		Path          string      `json:"path"`
+		Name          string      `json:"name,omitempty"`
+		FilesDir      string      `json:"filesDir,omitempty"`
+		SearchReplace []pairParam `json:"searchReplace,omitempty"`
+		DisableAuto   bool        `json:"disableAuto,omitempty"`
+		WithHooks     bool        `json:"withHooks,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		// The daemon's working directory is not the caller's, so a
+		// relative path would resolve somewhere surprising.
+		if args.Path == "" || !filepath.IsAbs(args.Path) {
+			return nil, NewMethodError(codeInvalidParams, "path must be an absolute path to an export archive", nil)
+		}
+		if args.FilesDir != "" && !filepath.IsAbs(args.FilesDir) {
+			return nil, NewMethodError(codeInvalidParams, "filesDir must be an absolute path", nil)
+		}
+		pairs, err := toPairs("searchReplace", args.SearchReplace)
+		if err != nil {
+			return nil, err
+		}
+		opts := sites.ImportSiteOptions{
+			Name:          args.Name,
+			FilesDir:      args.FilesDir,
+			SearchReplace: pairs,
+			DisableAuto:   args.DisableAuto,
+			WithHooks:     args.WithHooks,
+		}
+		res, err := svc.ImportSite(ctx, args.Path, opts)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// ─── site.create_from_config ───────────────────────────────────────────
+
+func makeSiteFromConfig(svc SiteService) Handler {
+	type p struct {
+		Dir       string `json:"dir"`
+		WithHooks bool   `json:"withHooks,omitempty"`
+		Start     bool   `json:"start,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Dir == "" || !filepath.IsAbs(args.Dir) {
+			return nil, NewMethodError(codeInvalidParams, "dir must be an absolute path to a project directory", nil)
+		}
+		res, err := svc.CreateSiteFromConfig(ctx, args.Dir, sites.CreateFromConfigOptions{
+			WithHooks: args.WithHooks,
+			Start:     args.Start,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+}
+
+// ─── site.delete ───────────────────────────────────────────────────────
+
+func makeSiteDelete(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		PurgeVolume   bool `json:"purgeVolume,omitempty"`
+		SkipSnapshot  bool `json:"skipSnapshot,omitempty"`
+		ForceWorktree bool `json:"forceWorktree,omitempty"`
+		DryRun        bool `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		opts := sites.DeleteOptions{
+			PurgeVolume:   args.PurgeVolume,
+			SkipSnapshot:  args.SkipSnapshot,
+			ForceWorktree: args.ForceWorktree,
+			DryRun:        args.DryRun,
+		}
+		if err := svc.DeleteSiteWithOptions(ctx, id, opts); err != nil {
+			// Map worktree-dirty into a typed code so the CLI can
+			// prompt for --force without parsing strings.
+			if errors.Is(err, sites.ErrWorktreeDirty) {
+				return nil, NewMethodError(CodeConflict, err.Error(), err)
+			}
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"deleted": !args.DryRun, "siteId": id, "dryRun": args.DryRun}, nil
+	}
+}
+
+// ─── site.create ───────────────────────────────────────────────────────
+
+func makeSiteCreate(svc SiteService) Handler {
+	type p struct {
+		Name         string `json:"name"`
+		FilesDir     string `json:"filesDir,omitempty"`
+		PublicDir    string `json:"publicDir,omitempty"`
+		PHPVersion   string `json:"phpVersion,omitempty"`
+		DBEngine     string `json:"dbEngine,omitempty"`
+		DBVersion    string `json:"dbVersion,omitempty"`
+		CacheBackend string `json:"cacheBackend,omitempty"`
+		CacheVersion string `json:"cacheVersion,omitempty"`
+		WebServer    string `json:"webServer,omitempty"`
+		Multisite    string `json:"multisite,omitempty"`
+		DryRun       bool   `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		switch {
+		case strings.TrimSpace(args.Name) == "":
+			return nil, NewMethodError(codeInvalidParams, "name is required", nil)
+		case args.FilesDir != "" && !filepath.IsAbs(args.FilesDir):
+			return nil, NewMethodError(codeInvalidParams, "filesDir must be an absolute path", nil)
+		case args.WebServer != "" && args.WebServer != "nginx" && args.WebServer != "apache":
+			return nil, NewMethodError(codeInvalidParams, `webServer must be "nginx" or "apache"`, nil)
+		case args.Multisite != "" && args.Multisite != "subdirectory" && args.Multisite != "subdomain":
+			return nil, NewMethodError(codeInvalidParams, `multisite must be "subdirectory" or "subdomain"`, nil)
+		}
+		site := types.Site{
+			Name:         args.Name,
+			FilesDir:     args.FilesDir,
+			PublicDir:    args.PublicDir,
+			PHPVersion:   args.PHPVersion,
+			DBEngine:     args.DBEngine,
+			DBVersion:    args.DBVersion,
+			CacheBackend: args.CacheBackend,
+			CacheVersion: args.CacheVersion,
+			WebServer:    args.WebServer,
+			Multisite:    args.Multisite,
+		}
+		if args.DryRun {
+			dr, err := svc.PreviewCreateSite(ctx, site)
+			if err != nil {
+				return nil, err
+			}
+			return dryRunResult("", dr), nil
+		}
+		created, err := svc.CreateSite(site)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"siteId":       created.ID,
+			"slug":         created.Slug,
+			"url":          "https://" + created.Domain,
+			"filesDir":     created.FilesDir,
+			"phpVersion":   created.PHPVersion,
+			"dbEngine":     created.DBEngine,
+			"dbVersion":    created.DBVersion,
+			"cacheBackend": created.CacheBackend,
+			"cacheVersion": created.CacheVersion,
+			"webServer":    created.WebServer,
+		}, nil
+	}
+}
+
+// ─── site.import_db / site.search_replace ──────────────────────────────
+
+func makeImportDB(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Path          string      `json:"path"`
+		SearchReplace []pairParam `json:"searchReplace,omitempty"`
+		DisableAuto   bool        `json:"disableAuto,omitempty"`
+		SkipSnapshot  bool        `json:"skipSnapshot,omitempty"`
+		DryRun        bool        `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, conn *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Path == "" || !filepath.IsAbs(args.Path) {
+			return nil, NewMethodError(codeInvalidParams, "path must be an absolute path to a SQL dump", nil)
+		}
+		pairs, err := toPairs("searchReplace", args.SearchReplace)
+		if err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		if args.Path, err = confinePath(svc, conn, id, args.Path, false); err != nil {
+			return nil, err
+		}
+		opts := sites.ImportDBOptions{
+			SearchReplace: pairs,
+			DisableAuto:   args.DisableAuto,
+			SkipSnapshot:  args.SkipSnapshot,
+		}
+		if args.DryRun {
+			dr, err := svc.PreviewImportDB(ctx, id, args.Path, opts)
+			if err != nil {
+				return nil, mapSiteOpError(err)
+			}
+			return dryRunResult(id, dr), nil
+		}
+		if err := svc.ImportDB(ctx, id, args.Path, opts); err != nil {
+			return nil, mapSiteOpError(err)
+		}
+		return map[string]any{"imported": true, "siteId": id}, nil
+	}
+}
+
+func makeSearchReplace(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Pairs  []pairParam `json:"pairs"`
+		DryRun bool        `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if len(args.Pairs) == 0 {
+			return nil, NewMethodError(codeInvalidParams, "pairs must list at least one {from, to}", nil)
+		}
+		pairs, err := toPairs("pairs", args.Pairs)
+		if err != nil {
+			return nil, err
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		if args.DryRun {
+			dr, err := svc.PreviewSearchReplace(ctx, id, pairs)
+			if err != nil {
+				return nil, mapSiteOpError(err)
+			}
+			return dryRunResult(id, dr), nil
+		}
+		out, err := svc.SearchReplace(ctx, id, pairs)
+		if err != nil {
+			return nil, mapSiteOpError(err)
+		}
+		return map[string]any{"siteId": id, "output": out}, nil
+	}
+}
+
+// ─── site.export ───────────────────────────────────────────────────────
+
+func makeSiteExport(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Path   string `json:"path"`
+		DryRun bool   `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, conn *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Path == "" || !filepath.IsAbs(args.Path) {
+			return nil, NewMethodError(codeInvalidParams, "path must be an absolute path for the archive", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		if args.Path, err = confinePath(svc, conn, id, args.Path, true); err != nil {
+			return nil, err
+		}
+		if args.DryRun {
+			dr, err := svc.PreviewExport(ctx, id, args.Path)
+			if err != nil {
+				return nil, mapSiteOpError(err)
+			}
+			return dryRunResult(id, dr), nil
+		}
+		if err := svc.ExportSite(ctx, id, args.Path); err != nil {
+			return nil, mapSiteOpError(err)
+		}
+		return map[string]any{"siteId": id, "path": args.Path}, nil
+	}
+}
+
+// confinePath bounds a host path to the site's own directories when the
+// conn is limited to particular sites, so an agent scoped to one site
+// cannot read or write arbitrary host files through it. Unlimited conns
+// get the path back unchanged.
+func confinePath(svc SiteService, conn *Conn, siteID, path string, write bool) (string, error) {
+	if !conn.siteLimited() {
+		return path, nil
+	}
+	confined, err := svc.ConfineSitePath(siteID, path, write)
+	if errors.Is(err, sites.ErrPathOutsideSite) {
+		return "", NewMethodError(CodeForbidden, err.Error(), err)
+	}
+	if err != nil {
+		return "", mapNotFoundError(err)
+	}
+	return confined, nil
+}
+
+// ─── site.versions / site.migrate_engine ───────────────────────────────
+
+func makeSiteVersions(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		PHPVersion   string `json:"phpVersion,omitempty"`
+		DBEngine     string `json:"dbEngine,omitempty"`
+		DBVersion    string `json:"dbVersion,omitempty"`
+		CacheBackend string `json:"cacheBackend,omitempty"`
+		CacheVersion string `json:"cacheVersion,omitempty"`
+		DryRun       bool   `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		change := sites.VersionsChange{
+			PHPVersion:   args.PHPVersion,
+			DBEngine:     args.DBEngine,
+			DBVersion:    args.DBVersion,
+			CacheBackend: args.CacheBackend,
+			CacheVersion: args.CacheVersion,
+		}
+		if change == (sites.VersionsChange{}) {
+			return nil, NewMethodError(codeInvalidParams, "pass at least one of phpVersion, dbEngine, dbVersion, cacheBackend, cacheVersion", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		if args.DryRun {
+			dr, err := svc.PreviewVersionsChange(ctx, id, change)
+			if err != nil {
+				return nil, mapSiteOpError(err)
+			}
+			return dryRunResult(id, dr), nil
+		}
+		if err := svc.UpdateSiteVersionsWithEngine(ctx, id, change); err != nil {
+			return nil, mapSiteOpError(err)
+		}
+		return map[string]any{"siteId": id, "updated": true}, nil
+	}
+}
+
+func makeMigrateEngine(svc SiteService) Handler {
+	type p struct {
+		siteRef
+		Engine       string `json:"engine,omitempty"`
+		Version      string `json:"version"`
+		SkipSnapshot bool   `json:"skipSnapshot,omitempty"`
+		DryRun       bool   `json:"dryRun,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Version == "" {
+			return nil, NewMethodError(codeInvalidParams, "version is required", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		opts := sites.MigrateEngineOptions{
+			TargetEngine:  args.Engine,
+			TargetVersion: args.Version,
+			SkipSnapshot:  args.SkipSnapshot,
+		}
+		if args.DryRun {
+			dr, err := svc.PreviewMigrateEngine(ctx, id, opts)
+			if err != nil {
+				return nil, mapSiteOpError(err)
+			}
+			return dryRunResult(id, dr), nil
+		}
+		if err := svc.MigrateEngine(ctx, id, opts); err != nil {
+			return nil, mapSiteOpError(err)
+		}
+		return map[string]any{"siteId": id, "migrated": true}, nil
+	}
+}
+
+// ─── site.lan / site.spx ───────────────────────────────────────────────
+
+// toggleParams is the shape of the on/off methods. Enabled is a pointer
+// so a missing value is an error rather than a silent "off".
+type toggleParams struct {
+	siteRef
+	Enabled *bool `json:"enabled"`
+	DryRun  bool  `json:"dryRun,omitempty"`
+}
+
+func makeSiteLAN(svc SiteService) Handler {
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args toggleParams
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Enabled == nil {
+			return nil, NewMethodError(codeInvalidParams, "enabled is required", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		on := *args.Enabled
+		if args.DryRun {
+			dr, err := svc.PreviewLAN(ctx, id, on)
+			if err != nil {
+				return nil, mapSiteOpError(err)
+			}
+			return dryRunResult(id, dr), nil
+		}
+		if on {
+			err = svc.EnableLAN(ctx, id)
+		} else {
+			err = svc.DisableLAN(ctx, id)
+		}
+		if err != nil {
+			return nil, mapSiteOpError(err)
+		}
+		return map[string]any{"siteId": id, "lanEnabled": on}, nil
+	}
+}
+
+func makeSiteSPX(svc SiteService) Handler {
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args toggleParams
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Enabled == nil {
+			return nil, NewMethodError(codeInvalidParams, "enabled is required", nil)
+		}
+		id, err := resolveSite(svc, args.siteRef)
+		if err != nil {
+			return nil, err
+		}
+		if args.DryRun {
+			dr, err := svc.PreviewSPX(ctx, id, *args.Enabled)
+			if err != nil {
+				return nil, mapSiteOpError(err)
+			}
+			return dryRunResult(id, dr), nil
+		}
+		if err := svc.SetSPXEnabled(id, *args.Enabled); err != nil {
+			return nil, mapSiteOpError(err)
+		}
+		return map[string]any{"siteId": id, "spxEnabled": *args.Enabled}, nil
+	}
+}
+
+// ─── cert.{list,renew} ─────────────────────────────────────────────────
+
+func makeCertList(svc SiteService) Handler {
+	return func(ctx context.Context, _ *Conn, _ json.RawMessage) (any, error) {
+		return svc.CertInventory(ctx)
+	}
+}
+
+func makeCertRenew(svc SiteService) Handler {
+	type p struct {
+		// All re-issues every cert, not only those due for renewal.
+		All bool `json:"all,omitempty"`
+	}
+	return func(ctx context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		return svc.RenewCerts(ctx, args.All)
+	}
+}
+
+// ─── token.{create,list,revoke} ────────────────────────────────────────
+
+func makeTokenCreate(svc SiteService) Handler {
+	type p struct {
+		Label     string   `json:"label"`
+		Profile   string   `json:"profile,omitempty"`
+		Allow     []string `json:"allow,omitempty"`
+		Deny      []string `json:"deny,omitempty"`
+		Sites     []string `json:"sites,omitempty"`
+		ExpiresIn string   `json:"expiresIn,omitempty"`
+	}
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		opts := sites.TokenOptions{
+			Label:   args.Label,
+			Profile: args.Profile,
+			Allow:   args.Allow,
+			Deny:    args.Deny,
+			Sites:   args.Sites,
+		}
+		if args.ExpiresIn != "" {
+			d, err := time.ParseDuration(args.ExpiresIn)
+			if err != nil || d <= 0 {
+				return nil, NewMethodError(codeInvalidParams, "expiresIn must be a positive duration like 720h", nil)
+			}
+			opts.TTL = d
+		}
+		secret, tok, err := svc.CreateToken(opts)
+		if err != nil {
+			switch {
+			case errors.Is(err, captoken.ErrTokenInvalid):
+				return nil, NewMethodError(codeInvalidParams, err.Error(), err)
+			case errors.Is(err, storage.ErrTokenExists):
+				return nil, NewMethodError(CodeConflict, err.Error(), err)
+			}
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"token": tok, "secret": secret}, nil
+	}
+}
+
+func makeTokenList(svc SiteService) Handler {
+	return func(_ context.Context, _ *Conn, _ json.RawMessage) (any, error) {
+		tokens, err := svc.ListTokens()
+		if err != nil {
+			return nil, err
+		}
+		if tokens == nil {
+			tokens = []captoken.Token{}
+		}
+		return map[string]any{"tokens": tokens}, nil
+	}
+}
+
+func makeTokenRevoke(svc SiteService) Handler {
+	type p struct {
+		Label string `json:"label"`
+	}
+	return func(_ context.Context, _ *Conn, params json.RawMessage) (any, error) {
+		var args p
+		if err := unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if args.Label == "" {
+			return nil, NewMethodError(codeInvalidParams, "label is required", nil)
+		}
+		if err := svc.RevokeToken(args.Label); err != nil {
+			return nil, mapNotFoundError(err)
+		}
+		return map[string]any{"revoked": args.Label}, nil
+	}
+}
+
+// ─── helpers ───────────────────────────────────────────────────────────
+
+// unmarshalParams decodes params into v. Empty params is fine (v keeps
+// its zero values); malformed JSON is mapped to CodeInvalidParams.
+func unmarshalParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return NewMethodError(codeInvalidParams, "invalid params: "+err.Error(), nil)
+	}
+	return nil
+}
+
+// pairParam is one search-replace pair on the wire.
+type pairParam struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// toPairs converts wire pairs, rejecting any missing a side. field
+// names the param in the error.
+func toPairs(field string, in []pairParam) ([]sites.SearchReplacePair, error) {
+	var out []sites.SearchReplacePair
+	for _, sr := range in {
+		if sr.From == "" || sr.To == "" {
+			return nil, NewMethodError(codeInvalidParams, field+" entries require both from and to", nil)
+		}
+		out = append(out, sites.SearchReplacePair{From: sr.From, To: sr.To})
+	}
+	return out, nil
+}
+
+// dryRunResult is the response of a method called with dryRun: the
+// orch.Dry steps, plus the same preview rendered for display.
+func dryRunResult(siteID string, dr orch.DryResult) map[string]any {
+	steps := make([]map[string]any, 0, len(dr.Steps))
+	for _, st := range dr.Steps {
+		step := map[string]any{"name": st.Name, "description": st.Description}
+		if st.Error != nil {
+			step["error"] = st.Error.Error()
+		}
+		steps = append(steps, step)
+	}
+	out := map[string]any{
+		"dryRun":  true,
+		"plan":    dr.PlanName,
+		"steps":   steps,
+		"preview": dr.Format(),
+	}
+	if siteID != "" {
+		out["siteId"] = siteID
+	}
+	return out
+}
+
+// mapSiteOpError maps the refusals of a site operation that the caller
+// can fix by changing the site's state to CodeConflict.
+func mapSiteOpError(err error) error {
+	switch {
+	case errors.Is(err, sites.ErrSiteNotRunning):
+		return NewMethodError(CodeConflict, err.Error(), err)
+	case errors.Is(err, sites.ErrUnsafeVersionTransition):
+		return NewMethodError(CodeConflict, "unsafe version transition; use site.migrate_engine", err)
+	case errors.Is(err, sites.ErrExportExists):
+		return NewMethodError(CodeConflict, err.Error(), err)
+	}
+	return mapNotFoundError(err)
 }
 
 // mapNotFoundError translates well-known SiteManager error strings into
@@ -8,8 +8,9 @@ import (
 // Wire-format constants. Locorum's IPC speaks JSON-RPC 2.0 framed by
 // newlines (no Content-Length headers — that's an LSP convention, not
 // a JSON-RPC requirement). One request per line, one response per line.
-// Notifications (method calls without "id") are not used today; the
-// dispatcher accepts them silently for forward-compat.
+// Client notifications (method calls without "id") are accepted silently
+// for forward-compat. After events.subscribe the server pushes "event"
+// notifications on the same connection, between responses.
 const (
 	jsonRPCVersion = "2.0"
 
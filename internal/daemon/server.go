@@ -13,7 +13,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/PeterBooker/locorum/internal/captoken"
 	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/types"
 )
 
 // Handler is the per-method implementation. Receives the connection
@@ -42,10 +44,47 @@ type Conn struct {
 	// the default; "readonly" restricts to a curated method allowlist.
 	// Sandbox is reserved for a future tier (Part 6).
 	Profile string
+	// TokenLabel names the capability token the client presented in
+	// client.hello; empty when it presented none.
+	TokenLabel string
+
+	// tokenHash identifies that token. The dispatcher looks it up again
+	// on every call so revocation and expiry apply mid-connection.
+	tokenHash string
+	// tokenSites is the token's site grant as of client.hello, for the
+	// event stream, which filters per event rather than per call.
+	tokenSites []captoken.Site
 
 	// remote is the underlying connection. Handlers don't read or
 	// write it directly; the server owns the framing.
 	remote net.Conn
+
+	// writeMu and enc are the conn's response writer, shared with
+	// server pushes so frames never interleave.
+	writeMu *sync.Mutex
+	enc     *json.Encoder
+
+	// sub is the conn's events.subscribe subscription, if any. Only
+	// touched from the conn's dispatch loop, which is serial.
+	sub *subscription
+}
+
+// siteLimited reports whether the conn may act on only some sites,
+// through an MCP scope or a site-bound token.
+func (c *Conn) siteLimited() bool {
+	return c.MCPScope != "" || len(c.tokenSites) > 0
+}
+
+// push writes a JSON-RPC notification (a request frame without an id)
+// to the peer.
+func (c *Conn) push(method string, params any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.enc.Encode(Request{JSONRPC: jsonRPCVersion, Method: method, Params: body})
 }
 
 // Server is the JSON-RPC server side of the daemon. Constructed once
@@ -55,6 +94,14 @@ type Server struct {
 	ln       Listener
 	handlers map[string]methodEntry
 	logger   *slog.Logger
+	events   *EventHub
+	// tokens resolves capability tokens; nil refuses any hello that
+	// presents one.
+	tokens TokenAuthority
+	// sites resolves a call's siteId / slug to one site before the
+	// token and scope checks; nil matches on the field resolveSite
+	// acts on and nothing else.
+	sites SiteDirectory
 
 	// activeConns counts in-flight connections so Shutdown can wait
 	// for them to drain. atomic to avoid a mutex on every Accept.
@@ -75,11 +122,16 @@ type Server struct {
 // methods are reachable from the readonly profile; everything else is
 // full-only. SiteScoped methods carry a "siteId" or "slug" string field
 // in their Params; the dispatcher pulls that field and rejects the call
-// if it doesn't match Conn.MCPScope (when scope is set).
+// if it doesn't match Conn.MCPScope (when scope is set). Unscoped
+// methods act on no existing site and are refused outright when scope
+// is set. OwnerOnly methods refuse connections holding a capability
+// token.
 type methodEntry struct {
 	handler    Handler
 	readOnly   bool
 	siteScoped bool
+	unscoped   bool
+	ownerOnly  bool
 }
 
 // NewServer constructs a Server bound to ln. Methods are registered via
@@ -92,10 +144,15 @@ func NewServer(ln Listener, logger *slog.Logger) *Server {
 		ln:         ln,
 		handlers:   make(map[string]methodEntry),
 		logger:     logger.With("subsys", "ipc"),
+		events:     NewEventHub(),
 		shutdownCh: make(chan struct{}),
 	}
 }
 
+// Events returns the hub events.subscribe streams from. Event sources
+// publish into it; see AttachSites and AttachHealth.
+func (s *Server) Events() *EventHub { return s.events }
+
 // Register installs a handler for a method name. ReadOnly methods are
 // reachable from the readonly profile. SiteScoped methods accept Params
 // containing either a "siteId" or "slug" string; when MCP-scoped, the
@@ -120,6 +177,35 @@ func ReadOnly() MethodOption { return func(m *methodEntry) { m.readOnly = true }
 // with CodeForbidden.
 func SiteScoped() MethodOption { return func(m *methodEntry) { m.siteScoped = true } }
 
+// Unscoped marks a method that creates a site rather than acting on
+// one. A connection confined to one site by Conn.MCPScope may not call
+// it: the dispatcher rejects the call with CodeForbidden.
+func Unscoped() MethodOption { return func(m *methodEntry) { m.unscoped = true } }
+
+// OwnerOnly marks a method as unreachable with a capability token.
+// Token management carries it, so no token can mint a wider one.
+func OwnerOnly() MethodOption { return func(m *methodEntry) { m.ownerOnly = true } }
+
+// TokenAuthority looks up capability tokens and audits their calls.
+// RegisterMethods installs the SiteService as the authority.
+type TokenAuthority interface {
+	TokenByHash(hash string) (*captoken.Token, error)
+	RecordTokenCall(call captoken.Call)
+}
+
+// SetTokenAuthority enables capability tokens in client.hello.
+func (s *Server) SetTokenAuthority(a TokenAuthority) { s.tokens = a }
+
+// SiteDirectory lists the known sites. RegisterMethods installs the
+// SiteService as the directory.
+type SiteDirectory interface {
+	GetSites() ([]types.Site, error)
+}
+
+// SetSiteDirectory lets the dispatcher resolve site references before
+// it checks them against a token's sites or the MCP scope.
+func (s *Server) SetSiteDirectory(d SiteDirectory) { s.sites = d }
+
 // Serve runs the accept loop until the listener is closed or Shutdown
 // is called. Blocks; callers run it in a goroutine.
 func (s *Server) Serve(ctx context.Context) error {
@@ -196,9 +282,17 @@ func (s *Server) Shutdown(timeout time.Duration) {
 func (s *Server) handleConn(ctx context.Context, raw net.Conn) {
 	defer func() { _ = raw.Close() }()
 
+	enc := json.NewEncoder(raw)
+
+	// Connection write mutex: response writes from the dispatcher and
+	// server pushes (events.subscribe) share the connection.
+	var writeMu sync.Mutex
+
 	conn := &Conn{
 		Profile: ProfileFull,
 		remote:  raw,
+		writeMu: &writeMu,
+		enc:     enc,
 	}
 
 	// One reader per conn. bufio.Scanner is bounded by MaxMessageBytes
@@ -210,28 +304,43 @@ func (s *Server) handleConn(ctx context.Context, raw net.Conn) {
 	// proactively for any cross-platform clients that send CRLF.
 	scanner.Split(bufio.ScanLines)
 
-	enc := json.NewEncoder(raw)
-
-	// Connection write mutex: response writes from the dispatcher and
-	// future server-pushes (activity stream) share the connection.
-	var writeMu sync.Mutex
-
 	// Per-connection ctx so a malformed peer disconnect cancels its
 	// own in-flight handlers without affecting others.
 	connCtx, cancelConn := context.WithCancel(ctx)
 	defer cancelConn()
 
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
+	// The reader runs apart from dispatch so a disconnect is noticed
+	// while a long handler (site.logs_stream with follow) is still
+	// running: EOF cancels connCtx, which that handler is watching.
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		defer cancelConn()
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+			// Copy the line — Scanner reuses its buffer, and the
+			// bytes must outlive the next call to Scan.
+			buf := make([]byte, len(line))
+			copy(buf, line)
+			select {
+			case lines <- buf:
+			case <-connCtx.Done():
+				return
+			}
 		}
-		// Copy the line — Scanner reuses its buffer. We hand the
-		// bytes to a goroutine for concurrent dispatch, so the bytes
-		// must outlive the next call to Scan.
-		buf := make([]byte, len(line))
-		copy(buf, line)
+		// scanner.Err returns nil on EOF (the normal client-disconnect
+		// path). Any other error is logged at debug because a hostile
+		// peer that overruns MaxMessageBytes shouldn't make noise in
+		// production logs.
+		if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+			s.logger.Debug("conn read err", "err", err.Error())
+		}
+	}()
 
+	for buf := range lines {
 		var req Request
 		if err := json.Unmarshal(buf, &req); err != nil {
 			writeMu.Lock()
@@ -258,14 +367,6 @@ func (s *Server) handleConn(ctx context.Context, raw net.Conn) {
 			return
 		}
 	}
-
-	// scanner.Err returns nil on EOF (the normal client-disconnect
-	// path). Any other error is logged at debug because a hostile
-	// peer that overruns MaxMessageBytes shouldn't make noise in
-	// production logs.
-	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
-		s.logger.Debug("conn read err", "err", err.Error())
-	}
 }
 
 // dispatch resolves the method, enforces profile + scope gating, calls
@@ -299,18 +400,14 @@ func (s *Server) dispatch(ctx context.Context, conn *Conn, req Request, mu *sync
 		writeResponse(mu, enc, resp)
 		return
 	}
-	if conn.Profile == ProfileReadOnly && !entry.readOnly {
-		resp.Error = &RPCError{Code: CodeForbidden, Message: "method not permitted in readonly profile: " + req.Method}
+	if refusal := s.gate(conn, req.Method, entry, req.Params); refusal != nil {
+		if conn.tokenHash != "" {
+			s.recordTokenCall(conn, req, entry, refusal)
+		}
+		resp.Error = refusal
 		writeResponse(mu, enc, resp)
 		return
 	}
-	if entry.siteScoped && conn.MCPScope != "" {
-		if err := enforceScope(conn.MCPScope, req.Params); err != nil {
-			resp.Error = &RPCError{Code: CodeForbidden, Message: err.Error()}
-			writeResponse(mu, enc, resp)
-			return
-		}
-	}
 
 	// Handler invocation. We catch panics so a bug in one method
 	// doesn't bring down the daemon — log + return CodeInternalError.
@@ -323,6 +420,9 @@ func (s *Server) dispatch(ctx context.Context, conn *Conn, req Request, mu *sync
 		}()
 		return entry.handler(ctx, conn, req.Params)
 	}()
+	if conn.tokenHash != "" {
+		s.recordTokenCall(conn, req, entry, err)
+	}
 	if err != nil {
 		// Redact: backend errors can carry container-side strings that
 		// reflect env values or container secrets back to the client.
@@ -346,6 +446,28 @@ func (s *Server) dispatch(ctx context.Context, conn *Conn, req Request, mu *sync
 	writeResponse(mu, enc, resp)
 }
 
+// gate applies the token, profile and scope checks to a call, in that
+// order. Returns the CodeForbidden error to send, or nil.
+func (s *Server) gate(conn *Conn, method string, entry methodEntry, params json.RawMessage) *RPCError {
+	if conn.tokenHash != "" {
+		if err := s.checkToken(conn, method, entry, params); err != nil {
+			return &RPCError{Code: CodeForbidden, Message: err.Error()}
+		}
+	}
+	if conn.Profile == ProfileReadOnly && !entry.readOnly {
+		return &RPCError{Code: CodeForbidden, Message: "method not permitted in readonly profile: " + method}
+	}
+	if entry.unscoped && conn.MCPScope != "" {
+		return &RPCError{Code: CodeForbidden, Message: "mcp scope: " + method + " is not permitted on a site-scoped connection"}
+	}
+	if entry.siteScoped && conn.MCPScope != "" {
+		if err := enforceScope(s.sites, conn.MCPScope, params); err != nil {
+			return &RPCError{Code: CodeForbidden, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
 // handleHello processes the client.hello handshake. The client
 // declares its kind / profile / scope; the daemon stamps the conn
 // metadata and replies with server.info.
@@ -356,11 +478,15 @@ func (s *Server) dispatch(ctx context.Context, conn *Conn, req Request, mu *sync
 // (see transport_unix.go / transport_windows.go), so "if you can connect,
 // you are the user". A future TCP / HTTP transport MUST NOT inherit this
 // trust posture without per-call authorization. See SECURITY.md L5.
+//
+// A capability token in the hello is the exception: it can only narrow
+// what the conn may do, and checkToken re-checks it on every call.
 func (s *Server) handleHello(conn *Conn, req Request, mu *sync.Mutex, enc *json.Encoder) {
 	type helloParams struct {
 		PeerKind string `json:"peerKind"`
 		Profile  string `json:"profile,omitempty"`
 		MCPScope string `json:"mcpScope,omitempty"`
+		Token    string `json:"token,omitempty"`
 	}
 	var p helloParams
 	if len(req.Params) > 0 {
@@ -386,11 +512,108 @@ func (s *Server) handleHello(conn *Conn, req Request, mu *sync.Mutex, enc *json.
 		}
 	}
 	conn.MCPScope = p.MCPScope
+	if err := s.bindToken(conn, p.Token); err != nil {
+		writeResponse(mu, enc, Response{
+			JSONRPC: jsonRPCVersion, ID: req.ID,
+			Error: &RPCError{Code: CodeForbidden, Message: err.Error()},
+		})
+		return
+	}
 
 	body, _ := json.Marshal(serverInfo{Version: jsonRPCVersion, Profile: conn.Profile})
 	writeResponse(mu, enc, Response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: body})
 }
 
+// bindToken attaches the capability token whose secret is secret to
+// conn. A readonly token narrows the conn's profile; a full one leaves
+// the requested profile alone. A conn keeps its token for life: a later
+// hello cannot drop it or swap it for another.
+func (s *Server) bindToken(conn *Conn, secret string) error {
+	if secret == "" {
+		if conn.tokenHash != "" {
+			return errors.New("hello: connection is bound to a token")
+		}
+		return nil
+	}
+	hash := captoken.Hash(secret)
+	if conn.tokenHash != "" && conn.tokenHash != hash {
+		return errors.New("hello: connection is bound to a token")
+	}
+	if s.tokens == nil {
+		return errors.New("hello: capability tokens are not enabled")
+	}
+	tok, err := s.tokens.TokenByHash(hash)
+	if err != nil || tok.Expired(time.Now()) {
+		// One message for unknown, revoked and expired tokens alike.
+		return errors.New("hello: invalid or expired token")
+	}
+	conn.tokenHash = hash
+	conn.TokenLabel = tok.Label
+	conn.tokenSites = tok.Sites
+	if tok.Profile == ProfileReadOnly {
+		conn.Profile = ProfileReadOnly
+	}
+	return nil
+}
+
+// checkToken is the dispatcher-side gate for token conns, run before
+// the profile and scope checks. The token is looked up afresh so a
+// revocation or expiry since the last call is honoured.
+func (s *Server) checkToken(conn *Conn, method string, entry methodEntry, params json.RawMessage) error {
+	tok, err := s.tokens.TokenByHash(conn.tokenHash)
+	if err != nil || tok.Expired(time.Now()) {
+		return fmt.Errorf("token %q has been revoked or has expired", conn.TokenLabel)
+	}
+	if entry.ownerOnly || !tok.AllowsMethod(method) {
+		return fmt.Errorf("token %q does not allow %s", tok.Label, method)
+	}
+	if tok.Profile == ProfileReadOnly && !entry.readOnly {
+		// bindToken already narrowed conn.Profile; checked again here
+		// because a later hello may have asked for full.
+		return fmt.Errorf("token %q is readonly and cannot call %s", tok.Label, method)
+	}
+	if len(tok.Sites) == 0 {
+		return nil
+	}
+	if !entry.siteScoped {
+		// A site-bound token may read global state (site.list) but
+		// not change it: site.create or cert.renew would reach past
+		// the sites it was given.
+		if entry.readOnly {
+			return nil
+		}
+		return fmt.Errorf("token %q is limited to its sites and cannot call %s", tok.Label, method)
+	}
+	siteID, slug, err := canonicalSiteRef(s.sites, params)
+	if err != nil {
+		return fmt.Errorf("token %q: %w", tok.Label, err)
+	}
+	if !tok.AllowsSite(siteID, slug) {
+		return fmt.Errorf("token %q does not allow site %q", tok.Label, firstNonEmpty(siteID, slug))
+	}
+	return nil
+}
+
+// recordTokenCall reports a token conn's call, refused or completed, to
+// the authority for the audit trail.
+func (s *Server) recordTokenCall(conn *Conn, req Request, entry methodEntry, err error) {
+	tok, lookupErr := s.tokens.TokenByHash(conn.tokenHash)
+	var id int64
+	if lookupErr == nil {
+		id = tok.ID
+	}
+	siteID, slug, _ := siteRefOf(req.Params)
+	s.tokens.RecordTokenCall(captoken.Call{
+		TokenID:  id,
+		Label:    conn.TokenLabel,
+		Method:   req.Method,
+		SiteID:   siteID,
+		Slug:     slug,
+		ReadOnly: entry.readOnly,
+		Err:      err,
+	})
+}
+
 // handleServerInfo returns daemon metadata so a CLI can verify it's
 // talking to a compatible daemon version. Profile is always reported as
 // the conn's effective profile.
@@ -413,28 +636,85 @@ const (
 
 // enforceScope is the dispatcher-side gate for SiteScoped methods. We
 // tolerate either a "siteId" string (the canonical UUID) or a "slug"
-// string (human-friendly) — both are matched against the scope, which
-// itself may be either form. The MCP scope contract (D6 in the plan)
-// requires the daemon — not the client — to enforce this.
-func enforceScope(scope string, params json.RawMessage) error {
+// string (human-friendly); the reference is resolved to one site and
+// that site's id and slug are matched against the scope, which itself
+// may be either form. The MCP scope contract (D6 in the plan) requires
+// the daemon — not the client — to enforce this.
+func enforceScope(dir SiteDirectory, scope string, params json.RawMessage) error {
 	if len(params) == 0 {
 		return errors.New("mcp scope: method requires site id but params are empty")
 	}
+	siteID, slug, err := canonicalSiteRef(dir, params)
+	if err != nil {
+		return fmt.Errorf("mcp scope: %w", err)
+	}
+	if !scopeMatches(scope, siteID, slug) {
+		return fmt.Errorf("mcp scope: requested %q does not match conn scope %q",
+			firstNonEmpty(slug, siteID), scope)
+	}
+	return nil
+}
+
+// scopeMatches reports whether a resolved site falls inside scope.
+func scopeMatches(scope, siteID, slug string) bool {
+	return (siteID != "" && siteID == scope) || (slug != "" && slug == scope)
+}
+
+// canonicalSiteRef resolves a call's site reference to the id and slug
+// of the one site its handler will act on. resolveSite acts on siteId
+// when both fields are sent, so a slug naming another site is refused
+// rather than matched: otherwise {"siteId": <other>, "slug": <allowed>}
+// would pass the token and scope checks and act on <other>. With a nil
+// dir, or a reference to no known site, only the field resolveSite acts
+// on is returned.
+func canonicalSiteRef(dir SiteDirectory, params json.RawMessage) (id, slug string, err error) {
+	siteID, slug, err := siteRefOf(params)
+	if err != nil {
+		return "", "", err
+	}
+	if siteID != "" && dir == nil {
+		return siteID, "", nil
+	}
+	if dir == nil {
+		return "", slug, nil
+	}
+	rows, err := dir.GetSites()
+	if err != nil {
+		return "", "", fmt.Errorf("resolve site: %w", err)
+	}
+	for _, r := range rows {
+		switch {
+		case siteID != "" && r.ID == siteID:
+			if slug != "" && slug != r.Slug {
+				return "", "", fmt.Errorf("siteId %q and slug %q name different sites", siteID, slug)
+			}
+			return r.ID, r.Slug, nil
+		case siteID == "" && r.Slug == slug:
+			return r.ID, r.Slug, nil
+		}
+	}
+	if siteID != "" {
+		return siteID, "", nil
+	}
+	return "", slug, nil
+}
+
+// siteRefOf pulls the "siteId" and "slug" fields out of a call's
+// params. Errors when params name neither.
+func siteRefOf(params json.RawMessage) (siteID, slug string, err error) {
 	var holder struct {
 		SiteID string `json:"siteId"`
 		Slug   string `json:"slug"`
 	}
-	if err := json.Unmarshal(params, &holder); err != nil {
-		return errors.New("mcp scope: params must include siteId or slug")
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &holder); err != nil {
+			return "", "", errors.New("params must include siteId or slug")
+		}
 	}
-	switch {
-	case holder.SiteID != "" && holder.SiteID == scope:
-		return nil
-	case holder.Slug != "" && holder.Slug == scope:
-		return nil
+	if holder.SiteID == "" && holder.Slug == "" {
+		return "", "", errors.New("params must include siteId or slug")
 	}
-	return fmt.Errorf("mcp scope: requested %q does not match conn scope %q",
-		firstNonEmpty(holder.SiteID, holder.Slug), scope)
+	return holder.SiteID, holder.Slug, nil
 }
 
 func firstNonEmpty(s ...string) string {
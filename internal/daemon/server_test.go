@@ -3,15 +3,26 @@ package daemon
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/PeterBooker/locorum/internal/captoken"
+	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/mail"
+	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/reqlog"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/storage"
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
 	"github.com/PeterBooker/locorum/internal/types"
 )
 
@@ -40,6 +51,39 @@ type fakeService struct {
 
 	startedID string
 	stoppedID string
+
+	xdebugID   string
+	xdebugMode string
+
+	mailModeID string
+	mailMode   sites.MailSettings
+
+	importPath string
+	importOpts sites.ImportSiteOptions
+
+	requestsID     string
+	requestsFilter reqlog.Filter
+
+	access sites.SiteAccess
+
+	mailSiteID string
+	mailQuery  mail.Query
+	purgedSite string
+
+	logQuery sites.LogQuery
+	logLines []sites.SiteLogLine
+	// logsDone is closed when a streamed log read ends, if set.
+	logsDone chan struct{}
+
+	versionsErr error
+	// applied records the mutating calls made, as "method:siteID".
+	applied []string
+
+	// tokens maps a secret's hash to its token; tokenCalls records the
+	// audit calls. tokenMu guards both: the server goroutine writes them.
+	tokenMu    sync.Mutex
+	tokens     map[string]*captoken.Token
+	tokenCalls []captoken.Call
 }
 
 func (f *fakeService) DescribeAll(_ context.Context, _ sites.DescribeOptions) ([]sites.SiteDescription, error) {
@@ -79,9 +123,96 @@ func (f *fakeService) GetActivity(_ string, _ int) ([]storage.ActivityEvent, err
 func (f *fakeService) GetContainerLogs(_ context.Context, _, _ string, _ int) (string, error) {
 	return "", nil
 }
+func (f *fakeService) StreamSiteLogs(ctx context.Context, _ string, q sites.LogQuery) (<-chan sites.SiteLogLine, error) {
+	f.logQuery = q
+	ch := make(chan sites.SiteLogLine, len(f.logLines))
+	for _, l := range f.logLines {
+		ch <- l
+	}
+	if !q.Follow {
+		close(ch)
+		return ch, nil
+	}
+	go func() {
+		<-ctx.Done()
+		close(ch)
+		if f.logsDone != nil {
+			close(f.logsDone)
+		}
+	}()
+	return ch, nil
+}
+func (f *fakeService) SiteRequests(id string, fl reqlog.Filter) ([]reqlog.Entry, error) {
+	f.requestsID, f.requestsFilter = id, fl
+	return []reqlog.Entry{{Method: "GET", Path: "/missing", Status: 404}}, nil
+}
+func (f *fakeService) ListMail(_ context.Context, id string, q mail.Query) (*mail.Page, error) {
+	f.mailSiteID, f.mailQuery = id, q
+	return &mail.Page{Messages: []mail.Summary{{ID: "m1", Subject: "Password Reset"}}, Total: 1}, nil
+}
+func (f *fakeService) GetMail(_ context.Context, _, id string) (*mail.Message, error) {
+	if id != "m1" {
+		return nil, errors.New(`message "` + id + `" not found`)
+	}
+	return &mail.Message{Summary: mail.Summary{ID: id}, Text: "reset link"}, nil
+}
+func (f *fakeService) PurgeMail(_ context.Context, id, _ string) error {
+	f.purgedSite = id
+	return nil
+}
 func (f *fakeService) ExecWPCLI(_ context.Context, _ string, _ []string) (string, error) {
 	return "", nil
 }
+func (f *fakeService) SetXdebugMode(id, mode string) error {
+	f.xdebugID, f.xdebugMode = id, mode
+	return nil
+}
+func (f *fakeService) SetMailMode(id string, s sites.MailSettings) error {
+	f.mailModeID, f.mailMode = id, s
+	return nil
+}
+func (f *fakeService) DBUILoginURL(id string) (string, error) {
+	if id != "id1" {
+		return "", sites.ErrSiteNotRunning
+	}
+	return "https://db.localhost/?locorum_login=tok", nil
+}
+func (f *fakeService) SiteAccess(_ string) (*sites.SiteAccess, error) {
+	a := f.access
+	return &a, nil
+}
+func (f *fakeService) SetBasicAuth(_ context.Context, _ string, enabled bool) error {
+	f.access.AuthEnabled = enabled
+	return nil
+}
+func (f *fakeService) RotateAuthPassword(_ context.Context, _ string) error {
+	f.access.AuthPassword = "rotated"
+	return nil
+}
+func (f *fakeService) SetIPAllowlist(_ context.Context, _ string, entries []string) error {
+	f.access.IPAllowlist = entries
+	return nil
+}
+func (f *fakeService) LANAllowlist() string                             { return "192.168.1.0/24" }
+func (f *fakeService) AlwaysAllowed() []string                          { return []string{"127.0.0.0/8", "172.18.0.1/32"} }
+func (f *fakeService) ClientsNATed() bool                               { return true }
+func (f *fakeService) ConfigDrift(_ string) (*sites.ConfigDrift, error) { return nil, nil }
+func (f *fakeService) ApplyConfigYAML(_ context.Context, _ string) (*sites.ConfigDrift, error) {
+	return nil, nil
+}
+func (f *fakeService) DiscardConfigYAML(_ string) error { return nil }
+func (f *fakeService) ConfigYAML(_ string) ([]byte, error) {
+	return []byte("schema_version: 1\n"), nil
+}
+func (f *fakeService) Pull(_ context.Context, _ string, _ sites.PullOptions) (*sites.PullResult, error) {
+	return &sites.PullResult{}, nil
+}
+func (f *fakeService) Push(_ context.Context, _ string, _ sites.PushOptions) (*sites.PushResult, error) {
+	return &sites.PushResult{}, nil
+}
+func (f *fakeService) ListRemotes(_ string) ([]remote.Remote, error) { return nil, nil }
+func (f *fakeService) AddRemote(_ *remote.Remote) error              { return nil }
+func (f *fakeService) RemoveRemote(_, _ string) error                { return nil }
 func (f *fakeService) Snapshot(_ context.Context, _ string, _ string) (string, error) {
 	return "", nil
 }
@@ -92,6 +223,13 @@ func (f *fakeService) RestoreSnapshot(_ context.Context, _, _ string, _ sites.Re
 func (f *fakeService) CreateWorktreeSite(_ context.Context, _ sites.CreateWorktreeOptions) (*sites.CreateWorktreeResult, error) {
 	return nil, nil
 }
+func (f *fakeService) ImportSite(_ context.Context, path string, opts sites.ImportSiteOptions) (*sites.ImportSiteResult, error) {
+	f.importPath, f.importOpts = path, opts
+	return &sites.ImportSiteResult{Site: types.Site{Slug: "imported"}}, nil
+}
+func (f *fakeService) CreateSiteFromConfig(_ context.Context, _ string, _ sites.CreateFromConfigOptions) (*sites.CreateFromConfigResult, error) {
+	return nil, nil
+}
 func (f *fakeService) DeleteSiteWithOptions(_ context.Context, _ string, _ sites.DeleteOptions) error {
 	return nil
 }
@@ -99,7 +237,139 @@ func (f *fakeService) RunHookNow(_ context.Context, _ hooks.Hook) (hooks.Result,
 	return hooks.Result{}, nil
 }
 func (f *fakeService) ListSiteHooks(_ string) ([]hooks.Hook, error) { return nil, nil }
-func (f *fakeService) GetSites() ([]types.Site, error)              { return f.sites, nil }
+func (f *fakeService) HookRunLogs(_ string, _ int) ([]sites.HookRunLog, error) {
+	return []sites.HookRunLog{{Event: "post-start", Text: "ok\n"}}, nil
+}
+func (f *fakeService) GetSites() ([]types.Site, error) { return f.sites, nil }
+func (f *fakeService) CreateSite(site types.Site) (*types.Site, error) {
+	f.applied = append(f.applied, "CreateSite:"+site.Name)
+	site.ID, site.DBPassword = "new", "secret"
+	return &site, nil
+}
+func (f *fakeService) PreviewCreateSite(_ context.Context, site types.Site) (orch.DryResult, error) {
+	return fakeDry("add-site:" + site.Name), nil
+}
+func (f *fakeService) ImportDB(_ context.Context, id, _ string, _ sites.ImportDBOptions) error {
+	f.applied = append(f.applied, "ImportDB:"+id)
+	return nil
+}
+func (f *fakeService) PreviewImportDB(_ context.Context, id, _ string, _ sites.ImportDBOptions) (orch.DryResult, error) {
+	return fakeDry("import-db:" + id), nil
+}
+func (f *fakeService) SearchReplace(_ context.Context, id string, _ []sites.SearchReplacePair) (string, error) {
+	f.applied = append(f.applied, "SearchReplace:"+id)
+	return "Success: 3 replacements.\n", nil
+}
+func (f *fakeService) PreviewSearchReplace(_ context.Context, id string, _ []sites.SearchReplacePair) (orch.DryResult, error) {
+	return fakeDry("search-replace:" + id), nil
+}
+func (f *fakeService) ExportSite(_ context.Context, id, _ string) error {
+	f.applied = append(f.applied, "ExportSite:"+id)
+	return nil
+}
+func (f *fakeService) PreviewExport(_ context.Context, id, _ string) (orch.DryResult, error) {
+	return fakeDry("export:" + id), nil
+}
+func (f *fakeService) ConfineSitePath(_, path string, _ bool) (string, error) {
+	if !strings.HasPrefix(path, "/exports/") {
+		return "", fmt.Errorf("%w: %s", sites.ErrPathOutsideSite, path)
+	}
+	return path, nil
+}
+func (f *fakeService) UpdateSiteVersionsWithEngine(_ context.Context, id string, _ sites.VersionsChange) error {
+	f.applied = append(f.applied, "UpdateSiteVersionsWithEngine:"+id)
+	return f.versionsErr
+}
+func (f *fakeService) PreviewVersionsChange(_ context.Context, id string, _ sites.VersionsChange) (orch.DryResult, error) {
+	return fakeDry("versions-change:" + id), f.versionsErr
+}
+func (f *fakeService) MigrateEngine(_ context.Context, id string, _ sites.MigrateEngineOptions) error {
+	f.applied = append(f.applied, "MigrateEngine:"+id)
+	return nil
+}
+func (f *fakeService) PreviewMigrateEngine(_ context.Context, id string, _ sites.MigrateEngineOptions) (orch.DryResult, error) {
+	return fakeDry("migrate-engine:" + id), nil
+}
+func (f *fakeService) EnableLAN(_ context.Context, id string) error {
+	f.applied = append(f.applied, "EnableLAN:"+id)
+	return nil
+}
+func (f *fakeService) DisableLAN(_ context.Context, id string) error {
+	f.applied = append(f.applied, "DisableLAN:"+id)
+	return nil
+}
+func (f *fakeService) PreviewLAN(_ context.Context, id string, _ bool) (orch.DryResult, error) {
+	return fakeDry("lan:" + id), nil
+}
+func (f *fakeService) SetSPXEnabled(id string, _ bool) error {
+	f.applied = append(f.applied, "SetSPXEnabled:"+id)
+	return nil
+}
+func (f *fakeService) PreviewSPX(_ context.Context, id string, _ bool) (orch.DryResult, error) {
+	return fakeDry("spx:" + id), nil
+}
+
+// fakeDry is a one-step dry run named plan.
+func fakeDry(plan string) orch.DryResult {
+	return orch.DryResult{PlanName: plan, Steps: []orch.DryStepResult{{Name: "step", Description: "would run", Implemented: true}}}
+}
+func (f *fakeService) CertInventory(_ context.Context) (tlspkg.Inventory, error) {
+	return tlspkg.Inventory{Certs: []tlspkg.CertInfo{}}, nil
+}
+func (f *fakeService) RenewCerts(_ context.Context, _ bool) (*sites.CertRenewResult, error) {
+	return &sites.CertRenewResult{Renewed: []string{}}, nil
+}
+
+// addToken stores a token with tok's grants and returns its secret.
+func (f *fakeService) addToken(tok captoken.Token) string {
+	secret, hash, _ := captoken.Generate()
+	tok.Hash = hash
+	f.tokenMu.Lock()
+	defer f.tokenMu.Unlock()
+	if f.tokens == nil {
+		f.tokens = map[string]*captoken.Token{}
+	}
+	f.tokens[hash] = &tok
+	return secret
+}
+func (f *fakeService) CreateToken(opts sites.TokenOptions) (string, *captoken.Token, error) {
+	tok := captoken.Token{Label: opts.Label, Profile: captoken.ProfileFull, Allow: []string{"*"}}
+	return f.addToken(tok), &tok, nil
+}
+func (f *fakeService) ListTokens() ([]captoken.Token, error) {
+	f.tokenMu.Lock()
+	defer f.tokenMu.Unlock()
+	out := []captoken.Token{}
+	for _, t := range f.tokens {
+		out = append(out, *t)
+	}
+	return out, nil
+}
+func (f *fakeService) RevokeToken(label string) error {
+	f.tokenMu.Lock()
+	defer f.tokenMu.Unlock()
+	for hash, t := range f.tokens {
+		if t.Label == label {
+			delete(f.tokens, hash)
+			return nil
+		}
+	}
+	return fmt.Errorf("token %q not found", label)
+}
+func (f *fakeService) TokenByHash(hash string) (*captoken.Token, error) {
+	f.tokenMu.Lock()
+	defer f.tokenMu.Unlock()
+	if t, ok := f.tokens[hash]; ok {
+		cp := *t
+		return &cp, nil
+	}
+	return nil, storage.ErrTokenNotFound
+}
+func (f *fakeService) RecordTokenCall(call captoken.Call) {
+	f.tokenMu.Lock()
+	defer f.tokenMu.Unlock()
+	f.tokenCalls = append(f.tokenCalls, call)
+}
 
 // startTestServer wires a Server + Listener and returns a connected
 // client. Both are torn down at t.Cleanup.
@@ -174,6 +444,334 @@ func TestServer_RoundTrip_SiteStart_BySlug(t *testing.T) {
 	}
 }
 
+func TestServer_SiteXdebug_ValidatesMode(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "shop", Name: "Shop"}},
+	}
+	cli := startTestServer(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var out map[string]any
+	if err := cli.Call(ctx, "site.xdebug", map[string]any{"slug": "shop", "mode": "profile"}, &out); err != nil {
+		t.Fatalf("Call site.xdebug: %v", err)
+	}
+	if svc.xdebugID != "id1" || svc.xdebugMode != "profile" {
+		t.Fatalf("SetXdebugMode got (%q, %q), want (id1, profile)", svc.xdebugID, svc.xdebugMode)
+	}
+
+	err := cli.Call(ctx, "site.xdebug", map[string]any{"slug": "shop", "mode": "develop"}, &out)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+		t.Fatalf("expected codeInvalidParams for unknown mode, got %v", err)
+	}
+}
+
+func TestServer_SiteMail(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "shop", Name: "Shop"}},
+	}
+	cli := startTestServer(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var out struct {
+		Mail sites.MailSettings `json:"mail"`
+	}
+	params := map[string]any{
+		"slug": "shop", "mode": "relay", "relayHost": "smtp.example.com",
+		"relayUser": "qa", "relayPassword": "hunter22", "relayDomains": []string{"Example.com"},
+	}
+	if err := cli.Call(ctx, "site.mail", params, &out); err != nil {
+		t.Fatalf("Call site.mail: %v", err)
+	}
+	if svc.mailModeID != "id1" || svc.mailMode.RelayHost != "smtp.example.com:587" || svc.mailMode.RelayPassword != "hunter22" {
+		t.Errorf("SetMailMode got (%q, %+v)", svc.mailModeID, svc.mailMode)
+	}
+	if out.Mail.RelayPassword != "" || out.Mail.RelayDomains[0] != "example.com" {
+		t.Errorf("result = %+v, want normalised settings without the password", out.Mail)
+	}
+
+	err := cli.Call(ctx, "site.mail", map[string]any{"slug": "shop", "mode": "relay"}, &out)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+		t.Fatalf("expected codeInvalidParams for relay without a smarthost, got %v", err)
+	}
+}
+
+func TestServer_SiteDBUI(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "shop"}, {ID: "id2", Slug: "blog"}},
+	}
+	cli := startTestServer(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var out struct {
+		SiteID string `json:"siteId"`
+		URL    string `json:"url"`
+	}
+	if err := cli.Call(ctx, "site.db_ui", map[string]any{"slug": "shop"}, &out); err != nil {
+		t.Fatalf("Call site.db_ui: %v", err)
+	}
+	if out.SiteID != "id1" || out.URL != "https://db.localhost/?locorum_login=tok" {
+		t.Errorf("result = %+v", out)
+	}
+
+	err := cli.Call(ctx, "site.db_ui", map[string]any{"slug": "blog"}, &out)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeConflict {
+		t.Fatalf("expected CodeConflict for a stopped site, got %v", err)
+	}
+}
+
+func TestServer_SiteRequests(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "shop", Name: "Shop"}},
+	}
+	cli := startTestServer(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var out struct {
+		Requests []reqlog.Entry `json:"requests"`
+		Count    int            `json:"count"`
+	}
+	params := map[string]any{"slug": "shop", "status": "4xx", "path": "/wp-"}
+	if err := cli.Call(ctx, "site.requests", params, &out); err != nil {
+		t.Fatalf("Call site.requests: %v", err)
+	}
+	if out.Count != 1 || out.Requests[0].Status != 404 {
+		t.Fatalf("response = %+v", out)
+	}
+	want := reqlog.Filter{Status: "4xx", Path: "/wp-", Limit: 100}
+	if svc.requestsID != "id1" || svc.requestsFilter != want {
+		t.Fatalf("SiteRequests got (%q, %+v), want (id1, %+v)", svc.requestsID, svc.requestsFilter, want)
+	}
+
+	err := cli.Call(ctx, "site.requests", map[string]any{"slug": "shop", "status": "bad"}, &out)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+		t.Fatalf("expected codeInvalidParams for bad status, got %v", err)
+	}
+}
+
+func TestServer_SiteAccess(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "shop", Name: "Shop"}},
+	}
+	cli := startTestServer(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var out struct {
+		Access        sites.SiteAccess `json:"access"`
+		AlwaysAllowed []string         `json:"alwaysAllowed"`
+		ClientsNATed  bool             `json:"clientsNATed"`
+	}
+	params := map[string]any{"slug": "shop", "auth": "on", "rotatePassword": true, "allow": []string{"10.0.0.0/8"}, "allowLan": true}
+	if err := cli.Call(ctx, "site.access", params, &out); err != nil {
+		t.Fatalf("Call site.access: %v", err)
+	}
+	want := []string{"10.0.0.0/8", "192.168.1.0/24"}
+	if !out.Access.AuthEnabled || out.Access.AuthPassword != "rotated" || len(out.Access.IPAllowlist) != 2 || out.Access.IPAllowlist[1] != want[1] {
+		t.Fatalf("access = %+v, want auth on, rotated, allowlist %v", out.Access, want)
+	}
+	if !slices.Equal(out.AlwaysAllowed, []string{"127.0.0.0/8", "172.18.0.1/32"}) || !out.ClientsNATed {
+		t.Errorf("alwaysAllowed = %v, clientsNATed = %v", out.AlwaysAllowed, out.ClientsNATed)
+	}
+
+	for _, bad := range []map[string]any{
+		{"slug": "shop", "auth": "maybe"},
+		{"slug": "shop", "allow": []string{"not-an-ip"}},
+		{"slug": "shop", "allowLan": true, "clearAllowlist": true},
+	} {
+		err := cli.Call(ctx, "site.access", bad, &out)
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+			t.Errorf("%v: expected codeInvalidParams, got %v", bad, err)
+		}
+	}
+}
+
+func TestServer_Mail(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "shop", Name: "Shop"}},
+	}
+	cli := startTestServer(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var page mail.Page
+	if err := cli.Call(ctx, "mail.search", map[string]any{"slug": "shop", "query": "subject:reset"}, &page); err != nil {
+		t.Fatalf("Call mail.search: %v", err)
+	}
+	if svc.mailSiteID != "id1" || svc.mailQuery.Search != "subject:reset" || svc.mailQuery.Limit != defaultMailLimit {
+		t.Errorf("ListMail got (%q, %+v)", svc.mailSiteID, svc.mailQuery)
+	}
+	if err := cli.Call(ctx, "mail.list", nil, &page); err != nil || svc.mailSiteID != "" {
+		t.Errorf("unfiltered mail.list: site %q, err %v", svc.mailSiteID, err)
+	}
+
+	var msg mail.Message
+	if err := cli.Call(ctx, "mail.get", map[string]any{"id": "m1"}, &msg); err != nil || msg.Text != "reset link" {
+		t.Errorf("mail.get = %+v, %v", msg, err)
+	}
+	var rpcErr *RPCError
+	if err := cli.Call(ctx, "mail.get", map[string]any{"id": "nope"}, &msg); !errors.As(err, &rpcErr) || rpcErr.Code != CodeNotFound {
+		t.Errorf("unknown message: expected CodeNotFound, got %v", err)
+	}
+
+	var out map[string]any
+	if err := cli.Call(ctx, "mail.purge", map[string]any{"slug": "shop"}, &out); err != nil || svc.purgedSite != "id1" {
+		t.Errorf("mail.purge by site: site %q, err %v", svc.purgedSite, err)
+	}
+	for method, bad := range map[string]map[string]any{
+		"mail.search": {"slug": "shop"},
+		"mail.get":    {"slug": "shop"},
+		"mail.purge":  {},
+		"mail.list":   {"limit": -1},
+	} {
+		if err := cli.Call(ctx, method, bad, &out); !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+			t.Errorf("%s %v: expected codeInvalidParams, got %v", method, bad, err)
+		}
+	}
+}
+
+func TestServer_SiteImport_RequiresAbsolutePath(t *testing.T) {
+	svc := &fakeService{}
+	cli := startTestServer(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var out map[string]any
+	err := cli.Call(ctx, "site.import", map[string]any{"path": "shop.tar.gz"}, &out)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+		t.Fatalf("expected codeInvalidParams for relative path, got %v", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "shop.tar.gz")
+	params := map[string]any{
+		"path":          archive,
+		"name":          "Shop Copy",
+		"searchReplace": []map[string]string{{"from": "https://shop.example", "to": "https://shop-copy.localhost"}},
+	}
+	if err := cli.Call(ctx, "site.import", params, &out); err != nil {
+		t.Fatalf("Call site.import: %v", err)
+	}
+	if svc.importPath != archive || svc.importOpts.Name != "Shop Copy" || len(svc.importOpts.SearchReplace) != 1 {
+		t.Fatalf("ImportSite got (%q, %+v)", svc.importPath, svc.importOpts)
+	}
+}
+
+// TestServer_DryRun: every dryRun method previews without calling the
+// mutating service method, and the real call goes through.
+func TestServer_DryRun(t *testing.T) {
+	svc := &fakeService{sites: []types.Site{{ID: "id1", Slug: "shop"}}}
+	cli := startTestServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	dump := filepath.Join(t.TempDir(), "prod.sql")
+	calls := []struct {
+		method string
+		params map[string]any
+		plan   string
+	}{
+		{"site.create", map[string]any{"name": "Blog"}, "add-site:Blog"},
+		{"site.import_db", map[string]any{"slug": "shop", "path": dump}, "import-db:id1"},
+		{"site.search_replace", map[string]any{"slug": "shop", "pairs": []map[string]string{{"from": "a", "to": "b"}}}, "search-replace:id1"},
+		{"site.export", map[string]any{"slug": "shop", "path": dump + ".tar.gz"}, "export:id1"},
+		{"site.versions", map[string]any{"slug": "shop", "phpVersion": "8.4"}, "versions-change:id1"},
+		{"site.migrate_engine", map[string]any{"slug": "shop", "version": "8.4"}, "migrate-engine:id1"},
+		{"site.lan", map[string]any{"slug": "shop", "enabled": true}, "lan:id1"},
+		{"site.spx", map[string]any{"slug": "shop", "enabled": false}, "spx:id1"},
+	}
+	for _, c := range calls {
+		params := map[string]any{"dryRun": true}
+		for k, v := range c.params {
+			params[k] = v
+		}
+		var out struct {
+			DryRun bool   `json:"dryRun"`
+			Plan   string `json:"plan"`
+			Steps  []struct {
+				Name string `json:"name"`
+			} `json:"steps"`
+			Preview string `json:"preview"`
+		}
+		if err := cli.Call(ctx, c.method, params, &out); err != nil {
+			t.Fatalf("%s dryRun: %v", c.method, err)
+		}
+		if !out.DryRun || out.Plan != c.plan || len(out.Steps) != 1 || out.Preview == "" {
+			t.Errorf("%s dryRun = %+v", c.method, out)
+		}
+	}
+	if len(svc.applied) != 0 {
+		t.Fatalf("dry runs applied %v", svc.applied)
+	}
+
+	for _, c := range calls {
+		var out map[string]any
+		if err := cli.Call(ctx, c.method, c.params, &out); err != nil {
+			t.Fatalf("%s: %v", c.method, err)
+		}
+		if c.method == "site.create" {
+			if _, leaked := out["dbPassword"]; leaked || out["siteId"] != "new" {
+				t.Errorf("site.create = %v", out)
+			}
+		}
+	}
+	want := "CreateSite:Blog ImportDB:id1 SearchReplace:id1 ExportSite:id1 UpdateSiteVersionsWithEngine:id1 MigrateEngine:id1 EnableLAN:id1 SetSPXEnabled:id1"
+	if got := strings.Join(svc.applied, " "); got != want {
+		t.Errorf("applied %q\nwant %q", got, want)
+	}
+}
+
+func TestServer_SiteOps_Errors(t *testing.T) {
+	svc := &fakeService{
+		sites:       []types.Site{{ID: "id1", Slug: "shop"}},
+		versionsErr: sites.ErrUnsafeVersionTransition,
+	}
+	cli := startTestServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	invalid := []struct {
+		method string
+		params map[string]any
+	}{
+		{"site.create", map[string]any{"name": " "}},
+		{"site.create", map[string]any{"name": "Blog", "filesDir": "blog"}},
+		{"site.import_db", map[string]any{"slug": "shop", "path": "prod.sql"}},
+		{"site.search_replace", map[string]any{"slug": "shop", "pairs": []map[string]string{{"from": "a"}}}},
+		{"site.export", map[string]any{"slug": "shop"}},
+		{"site.versions", map[string]any{"slug": "shop"}},
+		{"site.migrate_engine", map[string]any{"slug": "shop"}},
+		{"site.lan", map[string]any{"slug": "shop"}},
+	}
+	var rpcErr *RPCError
+	for _, c := range invalid {
+		var out map[string]any
+		if err := cli.Call(ctx, c.method, c.params, &out); !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+			t.Errorf("%s %v: expected codeInvalidParams, got %v", c.method, c.params, err)
+		}
+	}
+
+	var out map[string]any
+	err := cli.Call(ctx, "site.versions", map[string]any{"slug": "shop", "dbEngine": "mariadb", "dryRun": true}, &out)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeConflict || !strings.Contains(rpcErr.Message, "site.migrate_engine") {
+		t.Errorf("unsafe versions change: got %v, want CodeConflict naming site.migrate_engine", err)
+	}
+
+	_, roCli := startEventServer(t, svc, HelloOptions{PeerKind: "test", Profile: ProfileReadOnly})
+	err = roCli.Call(ctx, "site.spx", map[string]any{"slug": "shop", "enabled": true, "dryRun": true}, &out)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+		t.Errorf("readonly dryRun: got %v, want CodeForbidden", err)
+	}
+}
+
 func TestServer_NotFound_BySlug(t *testing.T) {
 	svc := &fakeService{}
 	cli := startTestServer(t, svc)
@@ -296,6 +894,52 @@ func TestServer_MCPScope_RejectsWrongSite(t *testing.T) {
 	if svc.startedID != "" {
 		t.Fatalf("StartSite was reached despite scope rejection")
 	}
+	// The scoped slug cannot smuggle in another site's id.
+	err = cli.Call(ctx, "site.start", map[string]any{"siteId": "id2", "slug": "scoped"}, &out)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+		t.Fatalf("siteId of another site with scoped slug: got %v, want forbidden", err)
+	}
+	if svc.startedID != "" {
+		t.Fatalf("StartSite was reached with a mismatched siteId")
+	}
+	// Site-creating methods carry no site reference, but a scoped
+	// conn is still confined to its site.
+	for _, c := range []struct {
+		method string
+		params map[string]any
+	}{
+		{"site.create", map[string]any{"name": "Extra"}},
+		{"site.create_worktree", map[string]any{"name": "Extra", "gitRemote": "git@example.com:a/b.git", "branch": "main", "parentSlug": "scoped"}},
+		{"site.import", map[string]any{"path": "/tmp/other.tar.gz"}},
+		{"site.create_from_config", map[string]any{"dir": "/tmp/other"}},
+	} {
+		err = cli.Call(ctx, c.method, c.params, &out)
+		if !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+			t.Errorf("scoped %s: got %v, want forbidden", c.method, err)
+		}
+	}
+	if svc.importPath != "" {
+		t.Fatalf("ImportSite was reached on a scoped conn")
+	}
+	// Host paths are confined to the site's own directories.
+	for _, c := range []struct {
+		method string
+		params map[string]any
+	}{
+		{"site.export", map[string]any{"slug": "scoped", "path": "/home/user/.bashrc"}},
+		{"site.import_db", map[string]any{"slug": "scoped", "path": "/etc/shadow"}},
+	} {
+		err = cli.Call(ctx, c.method, c.params, &out)
+		if !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+			t.Errorf("scoped %s outside the site: got %v, want forbidden", c.method, err)
+		}
+	}
+	if len(svc.applied) != 0 {
+		t.Fatalf("applied = %v on paths outside the site", svc.applied)
+	}
+	if err := cli.Call(ctx, "site.export", map[string]any{"slug": "scoped", "path": "/exports/scoped/a.tar.gz"}, &out); err != nil {
+		t.Fatalf("export into the exports dir: %v", err)
+	}
 
 	// Sanity: scoped slug is allowed.
 	if err := cli.Call(ctx, "site.start", map[string]any{"slug": "scoped"}, &out); err != nil {
@@ -305,3 +949,363 @@ func TestServer_MCPScope_RejectsWrongSite(t *testing.T) {
 		t.Fatalf("expected startedID=id1, got %q", svc.startedID)
 	}
 }
+
+// startEventServer is startTestServer for events tests: it hands back
+// the Server so the test can publish, and dials with hello.
+func startEventServer(t *testing.T, svc SiteService, hello HelloOptions) (*Server, *Client) {
+	t.Helper()
+
+	sock := tempSockPath(t)
+	ln, err := Listen(sock)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	srv := NewServer(ln, nil)
+	RegisterMethods(srv, svc)
+
+	srvCtx, cancel := context.WithCancel(context.Background())
+	go func() { _ = srv.Serve(srvCtx) }()
+	t.Cleanup(func() {
+		cancel()
+		srv.Shutdown(time.Second)
+	})
+
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), time.Second)
+	defer dialCancel()
+	cli, err := DialClient(dialCtx, sock, hello)
+	if err != nil {
+		t.Fatalf("DialClient: %v", err)
+	}
+	t.Cleanup(func() { _ = cli.Close() })
+	return srv, cli
+}
+
+func nextEvent(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatal("events channel closed")
+		}
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return Event{}
+}
+
+func TestServer_Events_Subscribe(t *testing.T) {
+	svc := &fakeService{sites: []types.Site{{ID: "id1", Slug: "first"}, {ID: "id2", Slug: "second"}}}
+	srv, cli := startEventServer(t, svc, HelloOptions{PeerKind: "test"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ch, err := cli.Subscribe(ctx, EventFilter{Slug: "first"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	hub := srv.Events()
+	hub.Publish(EventStepDone, "id2", "second", map[string]any{"step": "skipped"})
+	hub.Publish(EventStepDone, "id1", "first", map[string]any{"step": "pull-images"})
+	hub.Publish(EventHookOutput, "id1", "first", map[string]any{"line": "hello"})
+
+	ev := nextEvent(t, ch)
+	if ev.Kind != EventStepDone || ev.SiteID != "id1" || !strings.Contains(string(ev.Data), "pull-images") {
+		t.Fatalf("first event = %+v, want id1's step.done", ev)
+	}
+	if ev := nextEvent(t, ch); ev.Kind != EventHookOutput {
+		t.Fatalf("second event = %+v, want hook.output", ev)
+	}
+
+	// Calls still work while events stream on the same connection.
+	var out []sites.SiteDescription
+	if err := cli.Call(ctx, "site.list", nil, &out); err != nil {
+		t.Fatalf("site.list after subscribe: %v", err)
+	}
+
+	err = cli.Call(ctx, "events.subscribe", map[string]any{"kinds": []string{"nope"}}, nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+		t.Fatalf("unknown kind: got %v, want invalid params", err)
+	}
+}
+
+func TestServer_Events_ReadOnly(t *testing.T) {
+	svc := &fakeService{sites: []types.Site{{ID: "id1", Slug: "first"}}}
+	srv, cli := startEventServer(t, svc, HelloOptions{PeerKind: "test", Profile: ProfileReadOnly})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := cli.Subscribe(ctx, EventFilter{Kinds: []string{EventHookOutput}})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+		t.Fatalf("readonly hook.output: got %v, want forbidden", err)
+	}
+
+	ch, err := cli.Subscribe(ctx, EventFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	srv.Events().Publish(EventHookOutput, "id1", "first", map[string]any{"line": "secret-ish"})
+	srv.Events().Publish(EventSiteUpdated, "id1", "first", map[string]any{"started": true})
+	if ev := nextEvent(t, ch); ev.Kind != EventSiteUpdated {
+		t.Fatalf("readonly received %+v, want hook output withheld", ev)
+	}
+}
+
+func TestServer_Events_MCPScope(t *testing.T) {
+	svc := &fakeService{sites: []types.Site{{ID: "id1", Slug: "scoped"}, {ID: "id2", Slug: "other"}}}
+	srv, cli := startEventServer(t, svc, HelloOptions{PeerKind: "mcp", MCPScope: "scoped"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, f := range []EventFilter{
+		{Kinds: []string{EventHealthFindings}},
+		{Slug: "other"},
+		{SiteID: "id2", Slug: "scoped"},
+	} {
+		_, err := cli.Subscribe(ctx, f)
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+			t.Fatalf("scoped Subscribe(%+v): got %v, want forbidden", f, err)
+		}
+	}
+
+	ch, err := cli.Subscribe(ctx, EventFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	hub := srv.Events()
+	hub.Publish(EventSitesChanged, "", "", map[string]any{"count": 2})
+	hub.Publish(EventStepStart, "id2", "other", nil)
+	hub.Publish(EventStepStart, "id1", "scoped", nil)
+	if ev := nextEvent(t, ch); ev.SiteID != "id1" {
+		t.Fatalf("scoped conn received %+v, want only its own site", ev)
+	}
+}
+
+func TestServer_LogsStream(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "first"}},
+		logLines: []sites.SiteLogLine{
+			{Service: "web", LogLine: docker.LogLine{Text: "GET / 200"}},
+			{Service: "php", LogLine: docker.LogLine{Stream: docker.LogStreamStderr, Text: "PHP Warning"}},
+		},
+		logsDone: make(chan struct{}),
+	}
+	cli := startTestServer(t, svc)
+	events := cli.Events()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var out struct {
+		Lines int `json:"lines"`
+	}
+	err := cli.Call(ctx, "site.logs_stream", map[string]any{
+		"slug": "first", "services": []string{"web", "php"}, "grep": "GET|Warn", "since": "2026-05-01T12:00:00Z",
+	}, &out)
+	if err != nil {
+		t.Fatalf("site.logs_stream: %v", err)
+	}
+	if out.Lines != 2 || svc.logQuery.Grep == nil || svc.logQuery.Since.IsZero() || len(svc.logQuery.Services) != 2 {
+		t.Fatalf("result %+v, query %+v", out, svc.logQuery)
+	}
+	ev := nextEvent(t, events)
+	if ev.Kind != EventLogLine || !strings.Contains(string(ev.Data), `"service":"web"`) {
+		t.Fatalf("first push = %+v", ev)
+	}
+	if ev := nextEvent(t, events); !strings.Contains(string(ev.Data), `"stream":"stderr"`) {
+		t.Fatalf("second push = %+v", ev)
+	}
+
+	for _, bad := range []map[string]any{
+		{"slug": "first", "services": []string{"mongo"}},
+		{"slug": "first", "grep": "("},
+	} {
+		var rpcErr *RPCError
+		if err := cli.Call(ctx, "site.logs_stream", bad, nil); !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+			t.Errorf("site.logs_stream(%v): got %v, want invalid params", bad, err)
+		}
+	}
+
+	// A follow runs until the client goes away.
+	go func() {
+		_ = cli.Call(context.Background(), "site.logs_stream", map[string]any{"slug": "first", "follow": true}, nil)
+	}()
+	nextEvent(t, events)
+	nextEvent(t, events)
+	_ = cli.Close()
+	select {
+	case <-svc.logsDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("follow stream not cancelled after the client disconnected")
+	}
+}
+
+func TestServer_ConfigYAMLAndHookLogs(t *testing.T) {
+	svc := &fakeService{sites: []types.Site{{ID: "id1", Slug: "shop"}}}
+	_, ro := startEventServer(t, svc, HelloOptions{PeerKind: "test", Profile: ProfileReadOnly})
+	_, full := startEventServer(t, svc, HelloOptions{PeerKind: "test"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var cfg struct {
+		SiteID string `json:"siteId"`
+		YAML   string `json:"yaml"`
+	}
+	if err := ro.Call(ctx, "site.config_yaml", map[string]any{"slug": "shop"}, &cfg); err != nil {
+		t.Fatalf("readonly site.config_yaml: %v", err)
+	}
+	if cfg.SiteID != "id1" || !strings.Contains(cfg.YAML, "schema_version") {
+		t.Errorf("config_yaml = %+v", cfg)
+	}
+
+	var rpcErr *RPCError
+	if err := ro.Call(ctx, "hook.logs", map[string]any{"slug": "shop"}, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+		t.Fatalf("readonly hook.logs: got %v, want forbidden", err)
+	}
+	var logs struct {
+		Runs []sites.HookRunLog `json:"runs"`
+	}
+	if err := full.Call(ctx, "hook.logs", map[string]any{"slug": "shop"}, &logs); err != nil {
+		t.Fatalf("hook.logs: %v", err)
+	}
+	if len(logs.Runs) != 1 || logs.Runs[0].Event != "post-start" {
+		t.Errorf("runs = %+v", logs.Runs)
+	}
+	if err := full.Call(ctx, "hook.logs", map[string]any{"slug": "shop", "limit": 50}, nil); !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+		t.Errorf("limit 50: got %v, want invalid params", err)
+	}
+}
+
+func TestServer_CapabilityTokens(t *testing.T) {
+	svc := &fakeService{
+		sites: []types.Site{{ID: "id1", Slug: "shop"}, {ID: "id2", Slug: "blog"}},
+	}
+	secret := svc.addToken(captoken.Token{
+		ID:      7,
+		Label:   "ci-agent",
+		Profile: captoken.ProfileFull,
+		Allow:   []string{"site.*"},
+		Deny:    []string{"site.delete"},
+		Sites:   []captoken.Site{{ID: "id1", Slug: "shop"}},
+	})
+
+	sock := tempSockPath(t)
+	ln, err := Listen(sock)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	srv := NewServer(ln, nil)
+	RegisterMethods(srv, svc)
+	srvCtx, cancel := context.WithCancel(context.Background())
+	go func() { _ = srv.Serve(srvCtx) }()
+	t.Cleanup(func() {
+		cancel()
+		srv.Shutdown(time.Second)
+	})
+
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer ctxCancel()
+	var rpcErr *RPCError
+	if _, err := DialClient(ctx, sock, HelloOptions{PeerKind: "mcp", Token: captoken.Prefix + "bogus"}); !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+		t.Fatalf("unknown token: got %v, want forbidden", err)
+	}
+	cli, err := DialClient(ctx, sock, HelloOptions{PeerKind: "mcp", Token: secret})
+	if err != nil {
+		t.Fatalf("DialClient: %v", err)
+	}
+	defer cli.Close()
+
+	if err := cli.Call(ctx, "site.start", map[string]any{"slug": "shop"}, nil); err != nil {
+		t.Fatalf("allowed site.start: %v", err)
+	}
+	if err := cli.Call(ctx, "site.list", nil, nil); err != nil {
+		t.Fatalf("site.list: %v", err)
+	}
+	for _, c := range []struct {
+		method string
+		params map[string]any
+	}{
+		{"site.delete", map[string]any{"slug": "shop"}},
+		{"site.start", map[string]any{"slug": "blog"}},
+		{"site.start", map[string]any{"siteId": "id2"}},
+		// resolveSite acts on siteId, so an allowed slug alongside
+		// must not carry the call.
+		{"site.start", map[string]any{"siteId": "id2", "slug": "shop"}},
+		{"site.create", map[string]any{"name": "new"}},
+		{"snapshot.list", map[string]any{"slug": "shop"}},
+		{"token.list", nil},
+		{"events.subscribe", map[string]any{"kinds": []string{"sites.changed"}}},
+	} {
+		if err := cli.Call(ctx, c.method, c.params, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+			t.Errorf("%s %v: got %v, want forbidden", c.method, c.params, err)
+		}
+	}
+	if svc.startedID != "id1" {
+		t.Errorf("startedID = %q, want id1", svc.startedID)
+	}
+
+	svc.tokenMu.Lock()
+	calls := append([]captoken.Call(nil), svc.tokenCalls...)
+	svc.tokenMu.Unlock()
+	if len(calls) != 10 {
+		t.Fatalf("recorded %d calls, want 10", len(calls))
+	}
+	if c := calls[0]; c.TokenID != 7 || c.Label != "ci-agent" || c.Method != "site.start" || c.Slug != "shop" || c.ReadOnly || c.Err != nil {
+		t.Errorf("first call = %+v", c)
+	}
+	if c := calls[2]; c.Method != "site.delete" || c.Err == nil {
+		t.Errorf("refused call = %+v", c)
+	}
+
+	if err := svc.RevokeToken("ci-agent"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Call(ctx, "site.list", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+		t.Errorf("after revoke: got %v, want forbidden", err)
+	}
+	if _, err := DialClient(ctx, sock, HelloOptions{PeerKind: "mcp", Token: secret}); err == nil {
+		t.Error("revoked token accepted at hello")
+	}
+
+	// The owner, with no token, manages tokens.
+	owner, err := DialClient(ctx, sock, HelloOptions{PeerKind: "test"})
+	if err != nil {
+		t.Fatalf("DialClient: %v", err)
+	}
+	defer owner.Close()
+	var created struct {
+		Secret string         `json:"secret"`
+		Token  captoken.Token `json:"token"`
+	}
+	if err := owner.Call(ctx, "token.create", map[string]any{"label": "reader", "expiresIn": "24h"}, &created); err != nil {
+		t.Fatalf("token.create: %v", err)
+	}
+	if !captoken.LooksLikeSecret(created.Secret) || created.Token.Label != "reader" {
+		t.Errorf("created = %+v", created)
+	}
+	if err := owner.Call(ctx, "token.create", map[string]any{"label": "x", "expiresIn": "soon"}, nil); !errors.As(err, &rpcErr) || rpcErr.Code != codeInvalidParams {
+		t.Errorf("bad expiresIn: got %v, want invalid params", err)
+	}
+	// A readonly token stays readonly whatever profile a later hello
+	// asks for.
+	roSecret := svc.addToken(captoken.Token{Label: "viewer", Profile: captoken.ProfileReadOnly, Allow: []string{"*"}})
+	ro, err := DialClient(ctx, sock, HelloOptions{PeerKind: "mcp", Token: roSecret})
+	if err != nil {
+		t.Fatalf("DialClient: %v", err)
+	}
+	defer ro.Close()
+	if err := ro.Call(ctx, "client.hello", map[string]any{"peerKind": "mcp", "profile": ProfileFull, "token": roSecret}, nil); err != nil {
+		t.Fatalf("re-hello: %v", err)
+	}
+	if err := ro.Call(ctx, "site.stop", map[string]any{"slug": "shop"}, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeForbidden {
+		t.Errorf("readonly token site.stop: got %v, want forbidden", err)
+	}
+
+	if err := owner.Call(ctx, "token.revoke", map[string]any{"label": "nobody"}, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeNotFound {
+		t.Errorf("revoke unknown: got %v, want not found", err)
+	}
+}
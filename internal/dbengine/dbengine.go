@@ -4,9 +4,11 @@
 // transitions — flows through dbengine.For(site.DBEngine) so the rest of
 // the codebase stays engine-agnostic.
 //
-// Two engines are supported today: MySQL and MariaDB. A third can be
-// added by writing one new file (mysql.go is the canonical reference) and
-// extending AllKinds.
+// Three engines are supported today: MySQL, MariaDB and SQLite. MySQL
+// and MariaDB run in a dedicated database container; SQLite has none and
+// lives in a file reached through the PHP container (see sqlite.go). A
+// new engine can be added by writing one new file (mysql.go is the
+// canonical reference) and extending AllKinds.
 package dbengine
 
 import (
@@ -28,6 +30,7 @@ type Kind string
 const (
 	MySQL   Kind = "mysql"
 	MariaDB Kind = "mariadb"
+	SQLite  Kind = "sqlite"
 )
 
 // Default is the engine new sites land on when the user accepts the
@@ -36,7 +39,7 @@ const Default = MySQL
 
 // AllKinds lists every supported engine in stable display order. The UI
 // dropdown reads this so adding a new engine touches one file.
-func AllKinds() []Kind { return []Kind{MySQL, MariaDB} }
+func AllKinds() []Kind { return []Kind{MySQL, MariaDB, SQLite} }
 
 // IsValid reports whether k is a known engine kind. Used at the storage
 // boundary to fail fast on a corrupted DB row.
@@ -89,11 +92,17 @@ type VolumeMarker struct {
 const MarkerFilename = ".locorum-marker.json"
 
 // Engine is the per-engine plug-in surface. Implementations live in
-// mysql.go and mariadb.go; tests substitute fake.Engine.
+// mysql.go, mariadb.go and sqlite.go; tests substitute fake.Engine.
 type Engine interface {
 	// Kind returns the stable engine identifier.
 	Kind() Kind
 
+	// Service is the site service whose container holds the database:
+	// "database" for the server engines, "php" for SQLite. Snapshot,
+	// Restore and the volume marker exec into
+	// docker.SiteContainerName(slug, Service()).
+	Service() string
+
 	// DefaultPort is the engine's TCP port inside its container. Both
 	// MySQL and MariaDB use 3306; declared per-engine so a future engine
 	// drops in cleanly.
@@ -165,6 +174,8 @@ func For(kind Kind) (Engine, error) {
 		return mysqlEngine{}, nil
 	case MariaDB:
 		return mariadbEngine{}, nil
+	case SQLite:
+		return sqliteEngine{}, nil
 	}
 	return nil, fmt.Errorf("dbengine: unknown engine %q", kind)
 }
@@ -3,6 +3,7 @@ package dbengine
 import (
 	"bytes"
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -33,6 +34,9 @@ func TestIsValid(t *testing.T) {
 	if !IsValid(MariaDB) {
 		t.Error("MariaDB should be valid")
 	}
+	if !IsValid(SQLite) {
+		t.Error("SQLite should be valid")
+	}
 	if IsValid(Kind("nosql")) {
 		t.Error("nosql should be invalid")
 	}
@@ -199,6 +203,58 @@ func TestMySQLEngine_Restore_PipesIntoExec(t *testing.T) {
 	}
 }
 
+func TestEngineService(t *testing.T) {
+	for k, want := range map[Kind]string{MySQL: "database", MariaDB: "database", SQLite: "php"} {
+		if got := MustFor(k).Service(); got != want {
+			t.Errorf("%s.Service() = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestSQLiteEngine_Snapshot_ExecsInPHPContainer(t *testing.T) {
+	site := &types.Site{Slug: "demo", DBEngine: string(SQLite), DBVersion: "3"}
+	ex := fake.New()
+	ex.StdoutScript = []string{"SQLite format 3\x00rest-of-file"}
+	ex.ExitScript = []int{0}
+
+	var buf bytes.Buffer
+	if _, err := MustFor(SQLite).Snapshot(context.Background(), ex, site, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "SQLite format 3") {
+		t.Errorf("buffer missing database body: %q", buf.String())
+	}
+	if len(ex.Calls) != 1 || ex.Calls[0].Container != "locorum-demo-php" {
+		t.Fatalf("calls = %+v, want one exec in locorum-demo-php", ex.Calls)
+	}
+	if !strings.Contains(strings.Join(ex.Calls[0].Cmd, " "), "VACUUM INTO") {
+		t.Errorf("snapshot should copy via VACUUM INTO: %v", ex.Calls[0].Cmd)
+	}
+}
+
+func TestSQLiteEngine_Restore_RejectsSQLDump(t *testing.T) {
+	site := &types.Site{Slug: "demo", DBEngine: string(SQLite), DBVersion: "3"}
+	eng := MustFor(SQLite)
+
+	ex := fake.New()
+	err := eng.Restore(context.Background(), ex, site, strings.NewReader("INSERT INTO x VALUES (1);\n"))
+	if !errors.Is(err, ErrNotSQLite) {
+		t.Fatalf("Restore(sql dump) = %v, want ErrNotSQLite", err)
+	}
+	if len(ex.Calls) != 0 {
+		t.Errorf("rejected restore should not exec, got %d calls", len(ex.Calls))
+	}
+
+	ex.ExitScript = []int{0}
+	body := "SQLite format 3\x00pages"
+	if err := eng.Restore(context.Background(), ex, site, strings.NewReader(body)); err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.CapturedStdin) != 1 || string(ex.CapturedStdin[0]) != body {
+		t.Errorf("stdin = %q, want the full database file", ex.CapturedStdin)
+	}
+}
+
 func TestUpgradeAllowed(t *testing.T) {
 	mysqlEng := MustFor(MySQL)
 	mariaEng := MustFor(MariaDB)
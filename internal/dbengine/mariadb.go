@@ -22,6 +22,7 @@ import (
 type mariadbEngine struct{}
 
 func (mariadbEngine) Kind() Kind             { return MariaDB }
+func (mariadbEngine) Service() string        { return "database" }
 func (mariadbEngine) DefaultPort() int       { return 3306 }
 func (mariadbEngine) DataDir() string        { return "/var/lib/mysql" }
 func (mariadbEngine) DefaultVersion() string { return "11.4" }
@@ -42,7 +43,7 @@ func (mariadbEngine) ConfMountTarget() string {
 }
 
 func (e mariadbEngine) ContainerSpec(site *types.Site, homeDir string) docker.ContainerSpec {
-	name := docker.SiteContainerName(site.Slug, "database")
+	name := docker.SiteContainerName(site.Slug, e.Service())
 	netName := docker.SiteNetworkName(site.Slug)
 	dbConfPath := filepath.Join(homeDir, ".locorum", "config", "dbengine", "mariadb", "locorum.cnf")
 	return docker.ContainerSpec{
@@ -91,21 +92,17 @@ func (e mariadbEngine) ContainerSpec(site *types.Site, homeDir string) docker.Co
 			CapAdd:          []string{"CHOWN", "SETGID", "SETUID", "DAC_OVERRIDE", "FOWNER", "FSETID"},
 			NoNewPrivileges: true,
 		},
-		Resources: docker.Resources{
-			LogMaxSize:  "10m",
-			LogMaxFiles: 3,
-			PidsLimit:   1024,
-			// 1 GiB: matches MySQL — both engines share the same WP-shaped
-			// workload and the same default InnoDB buffer footprint.
-			MemoryLimit: 1024 << 20,
-		},
+		// 1 GiB default, matching MySQL — both engines share the same
+		// WP-shaped workload and the same default InnoDB buffer footprint.
+		Resources: docker.SiteResources(site, docker.RoleDatabase),
+
 		Init:    true,
 		Restart: docker.RestartNo,
 	}
 }
 
 func (e mariadbEngine) Snapshot(ctx context.Context, ex Execer, site *types.Site, w io.Writer) (int64, error) {
-	cn := docker.SiteContainerName(site.Slug, "database")
+	cn := docker.SiteContainerName(site.Slug, e.Service())
 	cmd := []string{
 		"sh", "-c",
 		// `mariadb-dump` is canonical in MariaDB 11; it accepts the
@@ -130,7 +127,7 @@ func (e mariadbEngine) Snapshot(ctx context.Context, ex Execer, site *types.Site
 }
 
 func (e mariadbEngine) Restore(ctx context.Context, ex Execer, site *types.Site, r io.Reader) error {
-	cn := docker.SiteContainerName(site.Slug, "database")
+	cn := docker.SiteContainerName(site.Slug, e.Service())
 	cmd := []string{
 		"sh", "-c",
 		"MYSQL_PWD=\"$MARIADB_ROOT_PASSWORD\" mariadb -uroot --default-character-set=utf8mb4 wordpress",
@@ -22,6 +22,7 @@ import (
 type mysqlEngine struct{}
 
 func (mysqlEngine) Kind() Kind             { return MySQL }
+func (mysqlEngine) Service() string        { return "database" }
 func (mysqlEngine) DefaultPort() int       { return 3306 }
 func (mysqlEngine) DataDir() string        { return "/var/lib/mysql" }
 func (mysqlEngine) DefaultVersion() string { return "8.4" }
@@ -46,7 +47,7 @@ func (mysqlEngine) ConfMountTarget() string {
 // Security defaults match the rest of internal/docker: hardened caps,
 // no-new-privileges, log size capped at 10m × 3.
 func (e mysqlEngine) ContainerSpec(site *types.Site, homeDir string) docker.ContainerSpec {
-	name := docker.SiteContainerName(site.Slug, "database")
+	name := docker.SiteContainerName(site.Slug, e.Service())
 	netName := docker.SiteNetworkName(site.Slug)
 	dbConfPath := filepath.Join(homeDir, ".locorum", "config", "dbengine", "mysql", "locorum.cnf")
 	return docker.ContainerSpec{
@@ -87,15 +88,11 @@ func (e mysqlEngine) ContainerSpec(site *types.Site, homeDir string) docker.Cont
 			CapAdd:          []string{"CHOWN", "SETGID", "SETUID", "DAC_OVERRIDE", "FOWNER", "FSETID"},
 			NoNewPrivileges: true,
 		},
-		Resources: docker.Resources{
-			LogMaxSize:  "10m",
-			LogMaxFiles: 3,
-			PidsLimit:   1024,
-			// 1 GiB: enough for typical WP databases + InnoDB buffer pool
-			// at default sizes, while bounding the blast radius of a
-			// runaway query that allocates per-row buffers.
-			MemoryLimit: 1024 << 20,
-		},
+		// 1 GiB default (roleResources): enough for typical WP databases
+		// + InnoDB buffer pool at default sizes, while bounding the blast
+		// radius of a runaway query that allocates per-row buffers.
+		Resources: docker.SiteResources(site, docker.RoleDatabase),
+
 		Init:    true,
 		Restart: docker.RestartNo,
 	}
@@ -119,7 +116,7 @@ func (e mysqlEngine) ContainerSpec(site *types.Site, homeDir string) docker.Cont
 //   - --no-tablespaces: avoids requiring PROCESS privilege which the
 //     wordpress user does not hold.
 func (e mysqlEngine) Snapshot(ctx context.Context, ex Execer, site *types.Site, w io.Writer) (int64, error) {
-	cn := docker.SiteContainerName(site.Slug, "database")
+	cn := docker.SiteContainerName(site.Slug, e.Service())
 	cmd := []string{
 		"sh", "-c",
 		// MYSQL_PWD is read by mysqldump from the env, keeping the
@@ -148,7 +145,7 @@ func (e mysqlEngine) Snapshot(ctx context.Context, ex Execer, site *types.Site,
 // already been preprocessed via FilterImportStream(e.Filters(), ...) by
 // the caller — engine-internal restores apply no extra filtering.
 func (e mysqlEngine) Restore(ctx context.Context, ex Execer, site *types.Site, r io.Reader) error {
-	cn := docker.SiteContainerName(site.Slug, "database")
+	cn := docker.SiteContainerName(site.Slug, e.Service())
 	cmd := []string{
 		"sh", "-c",
 		"MYSQL_PWD=\"$MYSQL_ROOT_PASSWORD\" mysql -uroot --default-character-set=utf8mb4 wordpress",
@@ -0,0 +1,121 @@
+package dbengine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// SQLiteFile is the database filename inside docker.SQLiteDataDir. The
+// .ht prefix matches the SQLite Database Integration plugin's default,
+// which web servers refuse to serve.
+const SQLiteFile = ".ht.sqlite"
+
+// sqliteHeader is the 16-byte magic every SQLite 3 database starts with.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// ErrNotSQLite is returned by the SQLite engine's Restore when the stream
+// is not a SQLite database — typically a MySQL dump handed over by a
+// restore that skipped the engine check.
+var ErrNotSQLite = errors.New("snapshot is not a SQLite database")
+
+// sqliteEngine runs WordPress on the SQLite Database Integration drop-in.
+// There is no database container: the site's data volume is mounted
+// into the PHP container at docker.SQLiteDataDir, and Snapshot / Restore
+// copy the database file through that container.
+//
+// Snapshots are therefore raw SQLite files, not SQL. Moving data to or
+// from MySQL goes through the SQL bridge in internal/sites (MigrateEngine,
+// ImportDB), which runs statements through the drop-in's translator.
+type sqliteEngine struct{}
+
+func (sqliteEngine) Kind() Kind             { return SQLite }
+func (sqliteEngine) Service() string        { return "php" }
+func (sqliteEngine) DefaultPort() int       { return 0 }
+func (sqliteEngine) DataDir() string        { return docker.SQLiteDataDir }
+func (sqliteEngine) DefaultVersion() string { return "3" }
+
+// KnownVersions has a single entry: the on-disk format is SQLite 3 and
+// the library version comes with the PHP image, not from Locorum.
+func (sqliteEngine) KnownVersions() []string { return []string{"3"} }
+
+func (sqliteEngine) Image(string) string             { return "" }
+func (sqliteEngine) ConfMountTarget() string         { return "" }
+func (sqliteEngine) UpgradeAllowed(_, _ string) bool { return true }
+
+// ContainerSpec returns the zero spec. Callers check Service() before
+// adding a database container to a site.
+func (sqliteEngine) ContainerSpec(*types.Site, string) docker.ContainerSpec {
+	return docker.ContainerSpec{}
+}
+
+// Filters is the MySQL chain: everything imported into a SQLite site is
+// a MySQL dump on its way through the translator.
+func (sqliteEngine) Filters() []ImportFilter { return mysqlBaseFilters }
+
+// ConnectionURL points at the file inside the PHP container. There is
+// no port to publish.
+func (sqliteEngine) ConnectionURL(_, _ string, _ *types.Site) string {
+	return "sqlite://" + SQLitePath()
+}
+
+// SQLitePath is the in-container path of a SQLite site's database.
+func SQLitePath() string {
+	return docker.SQLiteDataDir + "/" + SQLiteFile
+}
+
+// Snapshot writes a consistent copy of the database file to w. VACUUM
+// INTO takes the copy under SQLite's own locking, so a request mid-write
+// can't leave a torn file the way a plain cat could.
+func (e sqliteEngine) Snapshot(ctx context.Context, ex Execer, site *types.Site, w io.Writer) (int64, error) {
+	cn := docker.SiteContainerName(site.Slug, e.Service())
+	script := `set -e
+tmp="` + docker.SQLiteDataDir + `/.locorum-snapshot.$$"
+trap 'rm -f "$tmp"' EXIT
+php -r '$db = new PDO("sqlite:" . $argv[1]); $db->exec("VACUUM INTO " . $db->quote($argv[2]));' "` + SQLitePath() + `" "$tmp"
+cat "$tmp"`
+	cw := &countWriter{w: w}
+	var stderr bytes.Buffer
+	exit, err := ex.ExecInContainerWriter(ctx, cn, docker.ExecOptions{Cmd: []string{"sh", "-c", script}}, cw, &stderr)
+	if err != nil {
+		return cw.n, fmt.Errorf("sqlite snapshot exec: %w", err)
+	}
+	if exit != 0 {
+		return cw.n, fmt.Errorf("sqlite snapshot exited %d: %s", exit, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if cw.n == 0 {
+		return 0, errors.New("sqlite snapshot produced no output — database missing")
+	}
+	return cw.n, nil
+}
+
+// Restore replaces the database file with r. The new file is written
+// beside the old one and renamed into place, and stale WAL / shared
+// memory files are dropped so SQLite doesn't replay them onto it.
+func (e sqliteEngine) Restore(ctx context.Context, ex Execer, site *types.Site, r io.Reader) error {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(sqliteHeader))
+	if err != nil || !bytes.Equal(head, sqliteHeader) {
+		return ErrNotSQLite
+	}
+	cn := docker.SiteContainerName(site.Slug, e.Service())
+	f := SQLitePath()
+	script := `set -e
+cat > "` + f + `.restore"
+rm -f "` + f + `-wal" "` + f + `-shm"
+mv "` + f + `.restore" "` + f + `"`
+	exit, err := ex.ExecInContainerWriterStdin(ctx, cn, docker.ExecOptions{Cmd: []string{"sh", "-c", script}}, br, nil, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite restore: %w", err)
+	}
+	if exit != 0 {
+		return fmt.Errorf("sqlite restore exited %d", exit)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package dbui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/utils"
+)
+
+// openTokenPHP defines locorum_dbui_login(), shared by every shim: it
+// returns the Login fields of a valid token from the query string, or
+// null. Keep the field names and token layout in step with Seal.
+const openTokenPHP = `
+if (!function_exists('locorum_dbui_login')) {
+    function locorum_dbui_login() {
+        if (!isset($_GET['locorum_login']) || !is_string($_GET['locorum_login'])) { return null; }
+        $key = base64_decode((string) getenv('LOCORUM_DBUI_KEY'), true);
+        if ($key === false || strlen($key) !== 32) { return null; }
+        $b64 = strtr($_GET['locorum_login'], '-_', '+/');
+        $raw = base64_decode(str_pad($b64, strlen($b64) + (4 - strlen($b64) % 4) % 4, '='), true);
+        if ($raw === false || strlen($raw) < 12 + 16) { return null; }
+        $plain = openssl_decrypt(
+            substr($raw, 12, -16), 'aes-256-gcm', $key, OPENSSL_RAW_DATA,
+            substr($raw, 0, 12), substr($raw, -16)
+        );
+        if ($plain === false) { return null; }
+        $login = json_decode($plain, true);
+        if (!is_array($login) || !isset($login['exp']) || time() > (int) $login['exp']) { return null; }
+        return $login;
+    }
+}
+`
+
+// adminerPluginPHP runs from Adminer's plugins-enabled/ directory before
+// the request is dispatched. A valid token is turned into the POST the
+// login form would have sent; Adminer then stores the credentials in
+// its session and redirects to the database as for a typed login.
+const adminerPluginPHP = `<?php
+// Locorum DB UI auto-login. Generated — changes are overwritten.
+` + openTokenPHP + `
+$login = locorum_dbui_login();
+if ($login !== null) {
+    $_POST['auth'] = [
+        'driver'    => 'server',
+        'server'    => $login['server'],
+        'username'  => $login['user'],
+        'password'  => $login['pass'],
+        'db'        => $login['db'],
+        'permanent' => '',
+    ];
+}
+
+// plugins-enabled/ files must return a plugin object; this one only
+// needs its side effect.
+return new stdClass();
+`
+
+// phpMyAdminConfigPHP is loaded by the image's config.inc.php on every
+// request. Server 1 is the signon server the auto-login lands on;
+// server 2 keeps the usual login form for any site's database.
+const phpMyAdminConfigPHP = `<?php
+// Locorum DB UI configuration. Generated — changes are overwritten.
+
+$cfg['Servers'] = [
+    1 => [
+        'verbose'       => 'Locorum auto-login',
+        'auth_type'     => 'signon',
+        'host'          => '',
+        'SignonSession' => 'LocorumSignon',
+        'SignonURL'     => '/locorum-signon.php',
+    ],
+    2 => [
+        'verbose'   => 'Any site',
+        'auth_type' => 'cookie',
+        'host'      => '',
+    ],
+];
+$cfg['AllowArbitraryServer'] = true;
+$cfg['ServerDefault'] = 2;
+
+if (isset($_GET['locorum_login']) && basename($_SERVER['SCRIPT_NAME'] ?? '') === 'index.php') {
+    header('Location: /locorum-signon.php?locorum_login=' . rawurlencode((string) $_GET['locorum_login']));
+    exit;
+}
+`
+
+// phpMyAdminSignonPHP hands a valid token's credentials to phpMyAdmin's
+// signon auth. Without one (an expired link, or phpMyAdmin bouncing a
+// logged-out signon session here) it falls back to the login form.
+const phpMyAdminSignonPHP = `<?php
+// Locorum DB UI auto-login. Generated — changes are overwritten.
+` + openTokenPHP + `
+$login = locorum_dbui_login();
+if ($login === null) {
+    header('Location: /index.php?server=2');
+    exit;
+}
+
+session_name('LocorumSignon');
+session_start();
+session_regenerate_id(true);
+$_SESSION['PMA_single_signon_user'] = $login['user'];
+$_SESSION['PMA_single_signon_password'] = $login['pass'];
+$_SESSION['PMA_single_signon_host'] = $login['server'];
+session_write_close();
+
+header('Location: /index.php?server=1&db=' . rawurlencode($login['db']));
+exit;
+`
+
+// assetFiles maps the file names docker.DBUISpec mounts to their body.
+var assetFiles = map[string]string{
+	"adminer-login.php":     adminerPluginPHP,
+	"phpmyadmin-config.php": phpMyAdminConfigPHP,
+	"phpmyadmin-signon.php": phpMyAdminSignonPHP,
+}
+
+// WriteAssets writes the PHP shims for both engines, so switching the
+// engine needs no other step. They hold no secret: the key reaches the
+// container through its environment.
+func WriteAssets(homeDir string) error {
+	dir := docker.DBUIAssetDir(homeDir)
+	if err := utils.EnsureDir(dir); err != nil {
+		return fmt.Errorf("creating DB UI asset dir: %w", err)
+	}
+	for name, body := range assetFiles {
+		//nolint:gosec // G306: world-readable so the container's web user can load it.
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			return fmt.Errorf("writing DB UI asset %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package dbui backs the one-click database login of the global DB UI
+// container (Adminer or phpMyAdmin at db.localhost).
+//
+// A login link carries the site's database credentials sealed with
+// AES-256-GCM under a key only Locorum and the DB UI container know.
+// The PHP shims from WriteAssets open the token inside the container,
+// check its expiry and hand the credentials to the engine's own login,
+// so the password never appears in the URL, the browser history or a
+// log line in readable form. The key lives in the Locorum home
+// directory and is passed to the container as an environment secret.
+package dbui
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Host is the hostname the router serves the DB UI on.
+const Host = "db.localhost"
+
+// TokenParam is the query parameter that carries a sealed login.
+const TokenParam = "locorum_login"
+
+// TTL is how long a login link stays usable. Long enough to survive a
+// slow browser launch, short enough that a link left in scrollback or
+// shell history is dead by the time anyone reads it.
+const TTL = 2 * time.Minute
+
+// keySize is the AES-256 key length in bytes.
+const keySize = 32
+
+// ErrInvalidToken reports a token that does not open under the key:
+// truncated, tampered with, sealed under another key or expired.
+var ErrInvalidToken = errors.New("invalid or expired DB UI login token")
+
+// Login is the payload of a sealed link. Field names are short because
+// the token rides in a URL; the PHP shims read the same names.
+type Login struct {
+	Server   string `json:"server"`
+	User     string `json:"user"`
+	Password string `json:"pass"`
+	DB       string `json:"db"`
+	// Expires is a Unix timestamp; Seal fills it in.
+	Expires int64 `json:"exp"`
+}
+
+// KeyPath is where the login-link key is kept: outside the config
+// tree, whose files get mounted into containers.
+func KeyPath(homeDir string) string {
+	return filepath.Join(homeDir, ".locorum", "dbui.key")
+}
+
+// LoadKey returns the login-link key, generating and persisting it on
+// first use. The file holds the key base64-encoded, which is also the
+// form the container receives it in.
+func LoadKey(homeDir string) ([]byte, error) {
+	path := KeyPath(homeDir)
+	key, err := readKey(path)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating DB UI key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating DB UI key dir: %w", err)
+	}
+	// O_EXCL: two processes starting together must not each write a
+	// key and end up signing with different ones. The loser re-reads.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return readKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("writing DB UI key: %w", err)
+	}
+	_, werr := f.WriteString(EncodeKey(key))
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("writing DB UI key: %w", werr)
+	}
+	return key, nil
+}
+
+func readKey(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("DB UI key at %s is malformed; delete it to generate a new one", path)
+	}
+	return key, nil
+}
+
+// EncodeKey returns key in the form the container expects it.
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// Seal returns a URL-safe token carrying l, valid until now+TTL. The
+// layout is base64url(nonce | ciphertext | tag), which is what PHP's
+// openssl_decrypt wants taken apart.
+func Seal(key []byte, l Login, now time.Time) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	l.Expires = now.Add(TTL).Unix()
+	plain, err := json.Marshal(l)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// Open is Seal's inverse, as the PHP shims implement it.
+func Open(key []byte, token string, now time.Time) (Login, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return Login{}, err
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) < gcm.NonceSize()+gcm.Overhead() {
+		return Login{}, ErrInvalidToken
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return Login{}, ErrInvalidToken
+	}
+	var l Login
+	if err := json.Unmarshal(plain, &l); err != nil || now.Unix() > l.Expires {
+		return Login{}, ErrInvalidToken
+	}
+	return l, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("DB UI key must be %d bytes, got %d", keySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// URL is the link that logs the browser in with token. Both engines
+// take it at the root; the phpMyAdmin config forwards it on.
+func URL(token string) string {
+	return "https://" + Host + "/?" + TokenParam + "=" + url.QueryEscape(token)
+}
@@ -0,0 +1,97 @@
+package dbui
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+)
+
+func TestSealOpen(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, keySize)
+	now := time.Unix(1_800_000_000, 0)
+	in := Login{Server: "locorum-shop-database", User: "wordpress", Password: "s3cret+/=", DB: "wordpress"}
+
+	token, err := Seal(key, in, now)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if strings.Contains(token, "s3cret") || strings.ContainsAny(token, "+/=") {
+		t.Errorf("token %q leaks the password or is not URL-safe", token)
+	}
+
+	got, err := Open(key, token, now.Add(TTL))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	in.Expires = now.Add(TTL).Unix()
+	if got != in {
+		t.Errorf("Open = %+v, want %+v", got, in)
+	}
+
+	if _, err := Open(key, token, now.Add(TTL+time.Second)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expired token: err = %v", err)
+	}
+	other := bytes.Repeat([]byte{8}, keySize)
+	if _, err := Open(other, token, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("foreign key: err = %v", err)
+	}
+	if _, err := Open(key, token[:len(token)-2]+"AA", now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered token: err = %v", err)
+	}
+	if _, err := Seal(key[:16], in, now); err == nil {
+		t.Error("Seal accepted a short key")
+	}
+
+	if u := URL(token); !strings.HasPrefix(u, "https://db.localhost/?locorum_login=") {
+		t.Errorf("URL = %q", u)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	home := t.TempDir()
+	key, err := LoadKey(home)
+	if err != nil {
+		t.Fatalf("LoadKey: %v", err)
+	}
+	if len(key) != keySize {
+		t.Fatalf("key is %d bytes", len(key))
+	}
+	again, err := LoadKey(home)
+	if err != nil || !bytes.Equal(again, key) {
+		t.Errorf("second LoadKey = %x, %v; want the stored key", again, err)
+	}
+
+	if err := os.WriteFile(KeyPath(home), []byte("short"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKey(home); err == nil {
+		t.Error("LoadKey accepted a malformed key file")
+	}
+}
+
+func TestWriteAssets(t *testing.T) {
+	home := t.TempDir()
+	if err := WriteAssets(home); err != nil {
+		t.Fatalf("WriteAssets: %v", err)
+	}
+	// Every file the specs mount must exist, or Docker creates a
+	// directory in its place.
+	for _, engine := range []string{docker.DBUIAdminer, docker.DBUIPHPMyAdmin} {
+		for _, m := range docker.DBUISpec(engine, home, "k").Mounts {
+			b, err := os.ReadFile(m.Bind.Source)
+			if err != nil {
+				t.Errorf("%s mount %s: %v", engine, filepath.Base(m.Bind.Source), err)
+				continue
+			}
+			if !bytes.HasPrefix(b, []byte("<?php")) {
+				t.Errorf("%s is not a PHP file", m.Bind.Source)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package devdns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testZone() Zone {
+	return Zone{
+		TLD:       "test",
+		Hosts:     []string{"shop.test", "www.shop.test"},
+		Wildcards: []string{"*.net.test"},
+		LanDomain: "sslip.io",
+		LanIP:     net.IPv4(192, 168, 1, 42),
+		LanSites:  map[string]bool{"shop": false, "net": true},
+	}
+}
+
+func TestZoneResolve(t *testing.T) {
+	z := testZone()
+	cases := []struct {
+		name   string
+		local  bool
+		want   string
+		result result
+	}{
+		{name: "shop.test", local: true, want: "127.0.0.1", result: resultAddr},
+		{name: "SHOP.test.", local: true, want: "127.0.0.1", result: resultAddr},
+		{name: "shop.test", local: false, want: "192.168.1.42", result: resultAddr},
+		{name: "a.net.test", local: true, want: "127.0.0.1", result: resultAddr},
+		{name: "a.b.net.test", local: true, result: resultNXDomain},
+		{name: "net.test", local: true, result: resultNXDomain},
+		{name: "other.test", local: true, result: resultNXDomain},
+		{name: "locorum-probe-abc.test", local: false, want: ProbeIP.String(), result: resultAddr},
+		{name: "shop.10-0-0-7.sslip.io", want: "10.0.0.7", result: resultAddr},
+		{name: "sub.net.10-0-0-7.sslip.io", want: "10.0.0.7", result: resultAddr},
+		{name: "sub.shop.10-0-0-7.sslip.io", result: resultNXDomain},
+		{name: "blog.10-0-0-7.sslip.io", result: resultNXDomain},
+		{name: "shop.10-0-0.sslip.io", result: resultNXDomain},
+		{name: "example.com", result: resultRefused},
+		{name: "shop.localhost", result: resultRefused},
+	}
+	for _, tc := range cases {
+		ip, res := z.resolve(tc.name, tc.local)
+		if res != tc.result {
+			t.Errorf("%s: result = %d, want %d", tc.name, res, tc.result)
+			continue
+		}
+		if tc.want != "" && ip.String() != tc.want {
+			t.Errorf("%s: ip = %s, want %s", tc.name, ip, tc.want)
+		}
+	}
+
+	noLan := testZone()
+	noLan.LanIP = nil
+	if _, res := noLan.resolve("shop.test", false); res != resultNXDomain {
+		t.Errorf("remote client without a LAN IP: result = %d", res)
+	}
+}
+
+type staticSource struct{ zone Zone }
+
+func (s staticSource) DNSZone() Zone { return s.zone }
+
+func TestServerAnswersOverUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp listen: %v", err)
+	}
+	srv := NewServer(conn, staticSource{testZone()}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+
+	addr := srv.Addr().String()
+	ips, err := Lookup(ctx, addr, "shop.test")
+	if err != nil || len(ips) != 1 || !ips[0].Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("shop.test: %v, %v", ips, err)
+	}
+	ips, err = Lookup(ctx, addr, NewProbeName("test"))
+	if err != nil || len(ips) != 1 || !ips[0].Equal(ProbeIP) {
+		t.Fatalf("probe: %v, %v", ips, err)
+	}
+	if _, err := Lookup(ctx, addr, "missing.test"); err == nil {
+		t.Error("missing.test: want NXDOMAIN error")
+	}
+	if _, err := Lookup(ctx, addr, "example.com"); err == nil {
+		t.Error("example.com: want refused error")
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Serve after Close: %v", err)
+	}
+}
+
+func TestHandleAAAAIsNoData(t *testing.T) {
+	srv := &Server{source: staticSource{testZone()}}
+	// Query header (ID 0x1234, RD) + one AAAA question for shop.test.
+	query := []byte{
+		0x12, 0x34, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		4, 's', 'h', 'o', 'p', 4, 't', 'e', 's', 't', 0,
+		0x00, 0x1c, 0x00, 0x01,
+	}
+	resp, ok := srv.handle(query, true)
+	if !ok {
+		t.Fatal("no reply")
+	}
+	// RCODE is the low nibble of byte 3; ANCOUNT is bytes 6-7.
+	if rcode := resp[3] & 0x0f; rcode != 0 {
+		t.Errorf("rcode = %d, want NOERROR", rcode)
+	}
+	if resp[6] != 0 || resp[7] != 0 {
+		t.Errorf("answer count = %d, want 0", int(resp[6])<<8|int(resp[7]))
+	}
+}
+
+func TestSetupHint(t *testing.T) {
+	if h := SetupHint("darwin", "test", "127.0.0.1:5300"); !strings.Contains(h, "/etc/resolver/test") || !strings.Contains(h, "port 5300") {
+		t.Errorf("darwin: %s", h)
+	}
+	if h := SetupHint("linux", "test", "127.0.0.1:5300"); !strings.Contains(h, "DNS=127.0.0.1:5300") || !strings.Contains(h, "Domains=~test") {
+		t.Errorf("linux: %s", h)
+	}
+	if h := SetupHint("windows", "test", "127.0.0.1:5300"); !strings.Contains(h, "port 53,") {
+		t.Errorf("windows off :53 should ask for port 53: %s", h)
+	}
+	if h := SetupHint("windows", "test", "127.0.0.1:53"); strings.Contains(h, "port 53,") || !strings.Contains(h, "'.test'") {
+		t.Errorf("windows on :53: %s", h)
+	}
+}
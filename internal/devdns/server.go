@@ -0,0 +1,177 @@
+package devdns
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// answerTTL is the TTL on every answer, in seconds. Short, because the
+// site list behind it changes whenever the user adds an alias or turns
+// LAN access on, and OS resolvers cache for the full TTL.
+const answerTTL = 10
+
+// zoneTTL bounds how long the server reuses a Zone before asking the
+// Source again. Browsers fire a burst of lookups per page load; this
+// keeps that burst from becoming a burst of database reads.
+const zoneTTL = 2 * time.Second
+
+// Source supplies the live zone. SiteManager satisfies it.
+type Source interface {
+	DNSZone() Zone
+}
+
+// Server answers DNS queries over UDP from a Source. TCP is not served:
+// every answer is a single A record, far below the 512-byte UDP limit,
+// so a resolver never has a reason to retry over TCP.
+type Server struct {
+	conn   net.PacketConn
+	source Source
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	zone     Zone
+	zoneAt   time.Time
+	closeErr error
+	closed   bool
+}
+
+// NewServer wraps an already-bound packet conn. Pass the result of
+// net.ListenPacket("udp", addr).
+func NewServer(conn net.PacketConn, source Source, logger *slog.Logger) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Server{conn: conn, source: source, logger: logger}
+}
+
+// Addr returns the bound address.
+func (s *Server) Addr() net.Addr { return s.conn.LocalAddr() }
+
+// Serve reads and answers queries until ctx is cancelled or Close is
+// called. Returns nil on a clean shutdown.
+func (s *Server) Serve(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() { _ = s.Close() })
+	defer stop()
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		resp, ok := s.handle(buf[:n], isLocal(from))
+		if !ok {
+			continue
+		}
+		if _, err := s.conn.WriteTo(resp, from); err != nil {
+			s.logger.Debug("devdns: write reply failed", "to", from.String(), "err", err.Error())
+		}
+	}
+}
+
+// Close stops Serve. Safe to call more than once.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		s.closeErr = s.conn.Close()
+	}
+	return s.closeErr
+}
+
+// handle builds the reply for one query packet. ok is false for packets
+// that don't merit a reply at all (unparseable, or already a response).
+func (s *Server) handle(packet []byte, local bool) ([]byte, bool) {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(packet)
+	if err != nil || hdr.Response {
+		return nil, false
+	}
+	q, err := p.Question()
+	if err != nil {
+		return reply(hdr, nil, dnsmessage.RCodeFormatError, nil), true
+	}
+	if hdr.OpCode != 0 {
+		return reply(hdr, &q, dnsmessage.RCodeNotImplemented, nil), true
+	}
+	if q.Class != dnsmessage.ClassINET {
+		return reply(hdr, &q, dnsmessage.RCodeRefused, nil), true
+	}
+
+	zone := s.currentZone()
+	ip, res := zone.resolve(q.Name.String(), local)
+	switch res {
+	case resultRefused:
+		return reply(hdr, &q, dnsmessage.RCodeRefused, nil), true
+	case resultNXDomain:
+		return reply(hdr, &q, dnsmessage.RCodeNameError, nil), true
+	}
+	// The name exists. Only A carries data; AAAA and everything else
+	// get an empty NOERROR so clients fall back to IPv4 instead of
+	// treating the name as missing.
+	if q.Type != dnsmessage.TypeA {
+		return reply(hdr, &q, dnsmessage.RCodeSuccess, nil), true
+	}
+	return reply(hdr, &q, dnsmessage.RCodeSuccess, ip), true
+}
+
+func (s *Server) currentZone() Zone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.zoneAt.IsZero() || time.Since(s.zoneAt) > zoneTTL {
+		s.zone = s.source.DNSZone()
+		s.zoneAt = time.Now()
+	}
+	return s.zone
+}
+
+// reply encodes a response echoing q. ip, when non-nil, becomes the
+// single A record in the answer section.
+func reply(req dnsmessage.Header, q *dnsmessage.Question, rcode dnsmessage.RCode, ip net.IP) []byte {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:               req.ID,
+		Response:         true,
+		OpCode:           req.OpCode,
+		Authoritative:    rcode == dnsmessage.RCodeSuccess || rcode == dnsmessage.RCodeNameError,
+		RecursionDesired: req.RecursionDesired,
+		RCode:            rcode,
+	})
+	b.EnableCompression()
+	// Builder errors only arise from misuse (sections out of order) or
+	// names over 255 bytes, which the parser already rejected; a nil
+	// packet here would be a bug, not a runtime condition.
+	_ = b.StartQuestions()
+	if q != nil {
+		_ = b.Question(*q)
+	}
+	if q != nil && ip != nil {
+		_ = b.StartAnswers()
+		var a dnsmessage.AResource
+		copy(a.A[:], ip.To4())
+		_ = b.AResource(dnsmessage.ResourceHeader{
+			Name:  q.Name,
+			Class: dnsmessage.ClassINET,
+			TTL:   answerTTL,
+		}, a)
+	}
+	out, _ := b.Finish()
+	return out
+}
+
+// isLocal reports whether a query came from this machine.
+func isLocal(addr net.Addr) bool {
+	if u, ok := addr.(*net.UDPAddr); ok {
+		return u.IP.IsLoopback()
+	}
+	return false
+}
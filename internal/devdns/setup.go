@@ -0,0 +1,56 @@
+package devdns
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+)
+
+// NewProbeName returns a fresh probe hostname under tld.
+func NewProbeName(tld string) string {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return ProbePrefix + hex.EncodeToString(b[:]) + "." + tld
+}
+
+// Lookup resolves name's IPv4 addresses by asking the server at addr
+// directly, bypassing the OS resolver configuration. The health check
+// uses it to tell "server down" apart from "OS not pointed at it".
+func Lookup(ctx context.Context, addr, name string) ([]net.IP, error) {
+	r := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", addr)
+		},
+	}
+	// The trailing dot makes the name absolute so resolv.conf search
+	// domains never get appended.
+	return r.LookupIP(ctx, "ip4", name+".")
+}
+
+// SetupHint returns the one-off command that routes tld to the server
+// at listen on goos. The OS resolver has to opt in per domain; nothing
+// here changes system configuration on its own.
+func SetupHint(goos, tld, listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return ""
+	}
+	switch goos {
+	case "darwin":
+		return fmt.Sprintf("sudo mkdir -p /etc/resolver && printf 'nameserver %s\\nport %s\\n' | sudo tee /etc/resolver/%s", host, port, tld)
+	case "windows":
+		// NRPT rules carry no port, so Windows can only use a server
+		// bound to :53.
+		hint := fmt.Sprintf("Add-DnsClientNrptRule -Namespace '.%s' -NameServers '%s' (in an elevated PowerShell)", tld, host)
+		if port != "53" {
+			hint = "Set the DNS listen address to port 53, then run " + hint
+		}
+		return hint
+	default:
+		return fmt.Sprintf("sudo mkdir -p /etc/systemd/resolved.conf.d && printf '[Resolve]\\nDNS=%s\\nDomains=~%s\\n' | sudo tee /etc/systemd/resolved.conf.d/locorum.conf && sudo systemctl restart systemd-resolved", listen, tld)
+	}
+}
@@ -0,0 +1,135 @@
+// Package devdns is Locorum's optional embedded DNS server. It answers A
+// queries for a dev TLD (".test" by default) and for the LAN wildcard
+// domain straight from the live site list, so custom site domains
+// resolve without hand-edited hosts files and LAN access keeps working
+// on networks with no route to sslip.io.
+//
+// The server is authoritative for those two zones only. Anything else is
+// REFUSED rather than forwarded: the OS is expected to send just the dev
+// zones here (an /etc/resolver file, a systemd-resolved routing domain,
+// an NRPT rule), and a recursive resolver on a LAN address would be an
+// open relay.
+package devdns
+
+import (
+	"net"
+	"strings"
+)
+
+// ProbePrefix starts the throwaway names the health check resolves
+// through the system resolver. Any name of the form
+// "<ProbePrefix><anything>.<tld>" answers ProbeIP, so a fresh nonce per
+// check sidesteps negative caching in the OS resolver.
+const ProbePrefix = "locorum-probe-"
+
+// ProbeIP is the sentinel answer for probe names. It is a loopback
+// address nothing else hands out, so seeing it proves the answer came
+// from this server and not a hosts file or an upstream wildcard.
+var ProbeIP = net.IPv4(127, 76, 79, 67).To4()
+
+// Zone is a snapshot of everything the server answers for. A zero Zone
+// answers nothing.
+type Zone struct {
+	// TLD is the dev top-level domain without dots, e.g. "test".
+	TLD string
+
+	// Hosts are the exact names under TLD that belong to a site.
+	Hosts []string
+
+	// Wildcards are "*.<name>" patterns under TLD. Like a TLS wildcard
+	// they match exactly one extra label.
+	Wildcards []string
+
+	// LanDomain is the wildcard-DNS suffix LAN hostnames use
+	// ("sslip.io" by default). Empty disables the LAN zone.
+	LanDomain string
+
+	// LanIP answers TLD names for clients that are not on this
+	// machine. Nil means such clients get NXDOMAIN.
+	LanIP net.IP
+
+	// LanSites maps the slug of every LAN-enabled site to whether it
+	// is a subdomain multisite (and so owns one label below its LAN
+	// hostname too).
+	LanSites map[string]bool
+}
+
+// result is the outcome of a single name lookup.
+type result int
+
+const (
+	resultRefused  result = iota // not one of our zones
+	resultNXDomain               // in our zone, no such name
+	resultAddr                   // name exists; ip holds the answer
+)
+
+// resolve looks up name (case-insensitive, with or without the trailing
+// dot). local reports whether the query came from this machine: those
+// clients get loopback for TLD names, everyone else gets LanIP.
+func (z *Zone) resolve(name string, local bool) (net.IP, result) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+	if z.TLD != "" && strings.HasSuffix(name, "."+z.TLD) {
+		if strings.HasPrefix(name, ProbePrefix) && strings.Count(name, ".") == 1 {
+			return ProbeIP, resultAddr
+		}
+		if !z.hasHost(name) {
+			return nil, resultNXDomain
+		}
+		if local {
+			return net.IPv4(127, 0, 0, 1).To4(), resultAddr
+		}
+		if z.LanIP == nil {
+			return nil, resultNXDomain
+		}
+		return z.LanIP, resultAddr
+	}
+
+	if z.LanDomain != "" && strings.HasSuffix(name, "."+z.LanDomain) {
+		if ip := z.lanAddr(strings.TrimSuffix(name, "."+z.LanDomain)); ip != nil {
+			return ip, resultAddr
+		}
+		return nil, resultNXDomain
+	}
+
+	return nil, resultRefused
+}
+
+func (z *Zone) hasHost(name string) bool {
+	for _, h := range z.Hosts {
+		if h == name {
+			return true
+		}
+	}
+	_, parent, ok := strings.Cut(name, ".")
+	if !ok {
+		return false
+	}
+	for _, w := range z.Wildcards {
+		if w == "*."+parent {
+			return true
+		}
+	}
+	return false
+}
+
+// lanAddr decodes "<slug>.<a-b-c-d>" (or "<sub>.<slug>.<a-b-c-d>" for a
+// subdomain multisite) into the embedded address, provided slug is a
+// LAN-enabled site. The answer is the address in the name, exactly as
+// sslip.io would give it, so a LAN URL resolves the same whether or not
+// the network can reach public DNS.
+func (z *Zone) lanAddr(rest string) net.IP {
+	labels := strings.Split(rest, ".")
+	if len(labels) < 2 || len(labels) > 3 {
+		return nil
+	}
+	ip := net.ParseIP(strings.ReplaceAll(labels[len(labels)-1], "-", "."))
+	if ip == nil || ip.To4() == nil {
+		return nil
+	}
+	subdomain, ok := z.LanSites[labels[len(labels)-2]]
+	if !ok || (len(labels) == 3 && !subdomain) {
+		return nil
+	}
+	return ip.To4()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
@@ -97,3 +98,26 @@ func (d *Docker) ContainerIsRunning(ctx context.Context, name string) (bool, err
 	}
 	return info.State != nil && info.State.Running, nil
 }
+
+// ContainerExit reports the container's last exit state. FinishedAt is
+// zero while the container has never stopped.
+func (d *Docker) ContainerExit(ctx context.Context, name string) (ExitState, error) {
+	info, err := d.cli.ContainerInspect(ctx, name)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return ExitState{}, nil
+		}
+		return ExitState{}, err
+	}
+	if info.State == nil {
+		return ExitState{}, nil
+	}
+	st := ExitState{
+		OOMKilled: info.State.OOMKilled,
+		ExitCode:  info.State.ExitCode,
+	}
+	if t, err := time.Parse(time.RFC3339Nano, info.State.FinishedAt); err == nil && t.Year() > 1 {
+		st.FinishedAt = t
+	}
+	return st, nil
+}
@@ -47,7 +47,8 @@ func TestSpecBuildersBindMountsAreSlashSafe(t *testing.T) {
 		PHPSpec(site, homeDir),
 		RedisSpec(site),
 		MailSpec(),
-		AdminerSpec(),
+		DBUISpec(DBUIAdminer, homeDir, "key"),
+		DBUISpec(DBUIPHPMyAdmin, homeDir, "key"),
 	}
 	for _, s := range specs {
 		for i, m := range s.Mounts {
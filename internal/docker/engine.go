@@ -100,6 +100,10 @@ type Engine interface {
 	// "running" state.
 	ContainerIsRunning(ctx context.Context, name string) (bool, error)
 
+	// ContainerExit reports how the container last stopped. A missing or
+	// never-stopped container returns the zero ExitState.
+	ContainerExit(ctx context.Context, name string) (ExitState, error)
+
 	// ProviderInfo returns Docker daemon identification, cached after first
 	// call. Use RefreshProviderInfo to force a re-fetch.
 	ProviderInfo(ctx context.Context) (ProviderInfo, error)
@@ -132,6 +136,15 @@ type PullProgress struct {
 	LayerCount int
 }
 
+// ExitState is the subset of a container's inspect State that describes
+// its last exit. OOMKilled is set when the kernel's OOM killer ended the
+// main process because the container hit its memory limit.
+type ExitState struct {
+	OOMKilled  bool
+	ExitCode   int
+	FinishedAt time.Time
+}
+
 // ContainerInfo is the package-level view of a container. Keeps callers
 // from importing docker SDK types directly.
 type ContainerInfo struct {
@@ -419,6 +419,7 @@ func buildResources(r Resources) container.Resources {
 	res := container.Resources{
 		Memory:    r.MemoryLimit,
 		CPUShares: r.CPUShares,
+		NanoCPUs:  r.NanoCPUs,
 	}
 	pids := r.PidsLimit
 	if pids == 0 {
@@ -54,6 +54,33 @@ const streamChannelBuf = 1024
 // the container does not exist at start; transient mid-stream read
 // failures are logged via slog and result in the channel closing.
 func (d *Docker) StreamContainerLogs(ctx context.Context, name string, since time.Time) (<-chan LogLine, error) {
+	opts := LogOptions{Follow: true, Tail: 100}
+	if !since.IsZero() {
+		// Don't replay the historical tail when resuming — the caller
+		// already has it.
+		opts.Since = since
+		opts.Tail = 0
+	}
+	return d.ContainerLogStream(ctx, name, opts)
+}
+
+// LogOptions selects the window ContainerLogStream reads.
+type LogOptions struct {
+	// Since and Until bound the lines by container timestamp. Docker
+	// takes them at one-second resolution. Zero means unbounded.
+	Since time.Time
+	Until time.Time
+	// Tail is how many lines of history to start with; negative means
+	// all of it (subject to Since).
+	Tail int
+	// Follow keeps the stream open for new lines. Without it the
+	// channel closes once the history is read.
+	Follow bool
+}
+
+// ContainerLogStream is StreamContainerLogs with the window spelled
+// out. Channel and error semantics are the same.
+func (d *Docker) ContainerLogStream(ctx context.Context, name string, o LogOptions) (<-chan LogLine, error) {
 	if d.cli == nil {
 		return nil, fmt.Errorf("%w: docker client not initialised", ErrDaemonUnreachable)
 	}
@@ -70,17 +97,19 @@ func (d *Docker) StreamContainerLogs(ctx context.Context, name string, since tim
 	opts := container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-		Follow:     true,
+		Follow:     o.Follow,
 		Timestamps: true,
-		Tail:       "100",
+		Tail:       "all",
 	}
-	if !since.IsZero() {
-		// Docker's API expects unix-seconds with optional nanos as a
-		// string; UnixNano keeps the resolution we receive back.
-		opts.Since = strconv.FormatInt(since.Unix(), 10)
-		// Don't replay the historical tail when resuming — the caller
-		// already has it.
-		opts.Tail = "0"
+	if o.Tail >= 0 {
+		opts.Tail = strconv.Itoa(o.Tail)
+	}
+	// Docker's API expects unix-seconds with optional nanos as a string.
+	if !o.Since.IsZero() {
+		opts.Since = strconv.FormatInt(o.Since.Unix(), 10)
+	}
+	if !o.Until.IsZero() {
+		opts.Until = strconv.FormatInt(o.Until.Unix(), 10)
 	}
 
 	rc, err := d.cli.ContainerLogs(ctx, name, opts)
@@ -63,6 +63,7 @@ type Container struct {
 	Healthy     bool
 	Logs        string
 	StreamLines []docker.LogLine
+	Exit        docker.ExitState
 }
 
 // Network is the fake's record of a created network.
@@ -333,6 +334,16 @@ func (e *Engine) ContainerIsRunning(_ context.Context, name string) (bool, error
 	return c.Running, nil
 }
 
+func (e *Engine) ContainerExit(_ context.Context, name string) (docker.ExitState, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.Containers[name]
+	if !ok {
+		return docker.ExitState{}, nil
+	}
+	return c.Exit, nil
+}
+
 func (e *Engine) ProviderInfo(_ context.Context) (docker.ProviderInfo, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -27,13 +27,15 @@ type Role = string
 // labels and used for filtering during cleanup, so changing a value is a
 // breaking change.
 const (
-	RoleRouter   Role = "router"
-	RoleWeb      Role = "web"
-	RolePHP      Role = "php"
-	RoleDatabase Role = "database"
-	RoleRedis    Role = "redis"
-	RoleMail     Role = "mail"
-	RoleAdminer  Role = "adminer"
+	RoleRouter    Role = "router"
+	RoleWeb       Role = "web"
+	RolePHP       Role = "php"
+	RoleDatabase  Role = "database"
+	RoleRedis     Role = "redis"
+	RoleValkey    Role = "valkey"
+	RoleMemcached Role = "memcached"
+	RoleMail      Role = "mail"
+	RoleDBUI      Role = "dbui"
 
 	RoleGlobalNetwork Role = "global-network"
 	RoleSiteNetwork   Role = "site-network"
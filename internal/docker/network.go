@@ -37,6 +37,27 @@ func (d *Docker) NetworksByLabel(ctx context.Context, match map[string]string) (
 	return out, nil
 }
 
+// NetworkGateways returns the gateway of each address pool on the named
+// network ("172.18.0.1"). A pool with no gateway set contributes its
+// subnet instead, so the result always covers the address the host
+// shows up as when it talks to a container on that network.
+func (d *Docker) NetworkGateways(ctx context.Context, name string) ([]string, error) {
+	res, err := d.cli.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("inspecting network %q: %w", name, err)
+	}
+	var out []string
+	for _, c := range res.IPAM.Config {
+		switch {
+		case c.Gateway != "":
+			out = append(out, c.Gateway)
+		case c.Subnet != "":
+			out = append(out, c.Subnet)
+		}
+	}
+	return out, nil
+}
+
 // RemoveNetworksByLabel removes every network matching the given label set.
 // NotFound errors during removal are tolerated.
 func (d *Docker) RemoveNetworksByLabel(ctx context.Context, match map[string]string) error {
@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// Resource keys accepted in types.Site.Resources. Stable strings — they
+// are persisted in the sites table and written to config.yaml. The cache
+// key covers whichever object cache backend the site runs.
+const (
+	ResourceKeyAll      = "all"
+	ResourceKeyPHP      = "php"
+	ResourceKeyWeb      = "web"
+	ResourceKeyDatabase = "database"
+	ResourceKeyCache    = "cache"
+)
+
+// Bounds on user overrides. The floors stop a typo (e.g. "memory_mb: 5")
+// from producing a container that cannot boot; there is no memory
+// ceiling because Docker Desktop's VM size is the real limit.
+const (
+	minResourceMemoryMB = 64
+	minResourcePids     = 64
+	minResourceCPUs     = 0.1
+)
+
+// ResourceKeys lists every accepted key in display order.
+func ResourceKeys() []string {
+	return []string{ResourceKeyAll, ResourceKeyPHP, ResourceKeyWeb, ResourceKeyDatabase, ResourceKeyCache}
+}
+
+// resourceKeyForRole maps a container role to the key its override is
+// stored under. Roles without a per-site key (router, mail, dbui)
+// return "" and keep their defaults.
+func resourceKeyForRole(role Role) string {
+	switch role {
+	case RolePHP:
+		return ResourceKeyPHP
+	case RoleWeb:
+		return ResourceKeyWeb
+	case RoleDatabase:
+		return ResourceKeyDatabase
+	case RoleRedis, RoleValkey, RoleMemcached:
+		return ResourceKeyCache
+	}
+	return ""
+}
+
+// SiteResources returns the caps for one of site's containers: the role
+// default from roleResources, overlaid with the site's "all" entry and
+// then its role entry. Engine packages building their own specs (see
+// internal/dbengine) call this so overrides reach every container.
+func SiteResources(site *types.Site, role Role) Resources {
+	r := roleResources(role)
+	key := resourceKeyForRole(role)
+	if site == nil || key == "" {
+		return r
+	}
+	r = applyResourceLimit(r, site.Resources[ResourceKeyAll])
+	return applyResourceLimit(r, site.Resources[key])
+}
+
+func applyResourceLimit(r Resources, l types.ResourceLimit) Resources {
+	if l.MemoryMB > 0 {
+		r.MemoryLimit = l.MemoryMB << 20
+	}
+	if l.CPUs > 0 {
+		r.NanoCPUs = int64(math.Round(l.CPUs * 1e9))
+	}
+	if l.Pids > 0 {
+		r.PidsLimit = l.Pids
+	}
+	return r
+}
+
+// ValidateResourceLimits rejects unknown keys and out-of-range values.
+// The error names the offending key so config.yaml and the GUI can point
+// the user at it.
+func ValidateResourceLimits(limits types.ResourceLimits) error {
+	keys := make([]string, 0, len(limits))
+	for k := range limits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !isResourceKey(k) {
+			return fmt.Errorf("resources: unknown key %q (want one of %v)", k, ResourceKeys())
+		}
+		l := limits[k]
+		if l.MemoryMB < 0 || (l.MemoryMB > 0 && l.MemoryMB < minResourceMemoryMB) {
+			return fmt.Errorf("resources.%s.memory_mb: must be at least %d", k, minResourceMemoryMB)
+		}
+		if l.Pids < 0 || (l.Pids > 0 && l.Pids < minResourcePids) {
+			return fmt.Errorf("resources.%s.pids: must be at least %d", k, minResourcePids)
+		}
+		if l.CPUs < 0 || (l.CPUs > 0 && l.CPUs < minResourceCPUs) {
+			return fmt.Errorf("resources.%s.cpus: must be at least %.1f", k, minResourceCPUs)
+		}
+		if n := float64(runtime.NumCPU()); l.CPUs > n {
+			return fmt.Errorf("resources.%s.cpus: %.2f exceeds the %d CPUs on this machine", k, l.CPUs, runtime.NumCPU())
+		}
+	}
+	return nil
+}
+
+func isResourceKey(k string) bool {
+	for _, v := range ResourceKeys() {
+		if v == k {
+			return true
+		}
+	}
+	return false
+}
+
+// CompactResourceLimits drops entries that override nothing, returning
+// nil when no entry is left. Callers normalise with it before persisting
+// so "all defaults" has exactly one representation.
+func CompactResourceLimits(limits types.ResourceLimits) types.ResourceLimits {
+	var out types.ResourceLimits
+	for k, l := range limits {
+		if l.IsZero() {
+			continue
+		}
+		if out == nil {
+			out = types.ResourceLimits{}
+		}
+		out[k] = l
+	}
+	return out
+}
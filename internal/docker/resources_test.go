@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func TestSiteResources_Overlay(t *testing.T) {
+	site := &types.Site{Slug: "demo", Resources: types.ResourceLimits{
+		ResourceKeyAll: {CPUs: 2, Pids: 512},
+		ResourceKeyPHP: {MemoryMB: 1536, Pids: 2048},
+	}}
+
+	php := SiteResources(site, RolePHP)
+	if php.MemoryLimit != 1536<<20 || php.PidsLimit != 2048 || php.NanoCPUs != 2e9 {
+		t.Errorf("php = %+v, want role entry over the all entry", php)
+	}
+	db := SiteResources(site, RoleDatabase)
+	if db.MemoryLimit != roleResources(RoleDatabase).MemoryLimit || db.PidsLimit != 512 || db.NanoCPUs != 2e9 {
+		t.Errorf("database = %+v, want default memory with the all entry", db)
+	}
+	if mail := SiteResources(site, RoleMail); !reflect.DeepEqual(mail, roleResources(RoleMail)) {
+		t.Errorf("mail = %+v, want untouched defaults", mail)
+	}
+	if r := SiteResources(&types.Site{}, RoleValkey); !reflect.DeepEqual(r, roleResources(RoleValkey)) {
+		t.Errorf("no overrides = %+v, want defaults", r)
+	}
+}
+
+func TestSiteResources_ChangesConfigHash(t *testing.T) {
+	site := &types.Site{Slug: "demo", PHPVersion: "8.3"}
+	before := PHPSpec(site, "/home/u").ConfigHash()
+	site.Resources = types.ResourceLimits{ResourceKeyPHP: {MemoryMB: 1024}}
+	if after := PHPSpec(site, "/home/u").ConfigHash(); after == before {
+		t.Error("a memory override must change the PHP spec hash")
+	}
+}
+
+func TestValidateResourceLimits(t *testing.T) {
+	cases := []struct {
+		name   string
+		limits types.ResourceLimits
+		ok     bool
+	}{
+		{"empty", nil, true},
+		{"valid", types.ResourceLimits{ResourceKeyCache: {MemoryMB: 512, CPUs: 0.5}}, true},
+		{"unknown key", types.ResourceLimits{"mail": {MemoryMB: 512}}, false},
+		{"memory below floor", types.ResourceLimits{ResourceKeyPHP: {MemoryMB: 16}}, false},
+		{"pids below floor", types.ResourceLimits{ResourceKeyPHP: {Pids: 8}}, false},
+		{"negative cpus", types.ResourceLimits{ResourceKeyWeb: {CPUs: -1}}, false},
+		{"too many cpus", types.ResourceLimits{ResourceKeyWeb: {CPUs: 1 << 16}}, false},
+	}
+	for _, tc := range cases {
+		if err := ValidateResourceLimits(tc.limits); (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
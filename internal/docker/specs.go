@@ -69,9 +69,14 @@ type Healthcheck struct {
 //	LogMaxFiles: 3
 //	PidsLimit:   1024
 //	MemoryLimit: 0  (unlimited; revisited per role in a follow-up)
+//	NanoCPUs:    0  (no CPU quota)
+//
+// NanoCPUs is omitted from ConfigHash when zero so specs built before the
+// field existed keep their hash and are not recreated on upgrade.
 type Resources struct {
 	MemoryLimit int64
 	CPUShares   int64
+	NanoCPUs    int64 `json:",omitempty"`
 	LogMaxSize  string
 	LogMaxFiles int
 	PidsLimit   int64
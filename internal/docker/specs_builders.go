@@ -3,8 +3,12 @@ package docker
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/PeterBooker/locorum/internal/mail"
 	"github.com/PeterBooker/locorum/internal/types"
 	"github.com/PeterBooker/locorum/internal/version"
 )
@@ -27,6 +31,56 @@ func SPXKeyINIPath(homeDir, slug string) string {
 	return filepath.Join(homeDir, ".locorum", "config", "php", "spx-keys", slug+".ini")
 }
 
+// XdebugINIPath is the on-disk location of the per-site Xdebug INI
+// fragment (xdebug.mode, client host/port, output dir). Shared by
+// EnsureXdebugStep, which writes it, and PHPSpec, which mounts it —
+// same producer/consumer split as SPXKeyINIPath.
+func XdebugINIPath(homeDir, slug string) string {
+	return filepath.Join(homeDir, ".locorum", "config", "php", "xdebug", slug+".ini")
+}
+
+// SitePHPINIPath is the on-disk location of the per-site php.ini
+// overrides (site.PHPIni). Written by EnsurePHPIniStep, mounted by
+// PHPSpec.
+func SitePHPINIPath(homeDir, slug string) string {
+	return filepath.Join(homeDir, ".locorum", "config", "php", "sites", slug+".ini")
+}
+
+// blackholeSendmailPath swallows whatever mail() pipes into it, so a
+// blackhole-mode site sees every send succeed and nothing is kept.
+const blackholeSendmailPath = "cat > /dev/null"
+
+// SitePHPINISettings returns the directives rendered into the site's
+// zzzz-site.ini: site.PHPIni plus what its mail mode needs. A
+// blackhole site's sendmail_path wins over a user-set one. Empty when
+// there is nothing to write, in which case the file is not mounted.
+func SitePHPINISettings(site *types.Site) map[string]string {
+	if site.MailMode != mail.ModeBlackhole {
+		return site.PHPIni
+	}
+	out := make(map[string]string, len(site.PHPIni)+1)
+	for k, v := range site.PHPIni {
+		out[k] = v
+	}
+	out["sendmail_path"] = blackholeSendmailPath
+	return out
+}
+
+// SQLiteDataDir is where a SQLite site's data volume is mounted inside
+// the PHP container. SQLite sites have no database container; the
+// dbengine sqlite engine points WordPress's DB_DIR here and reads and
+// writes the database file through the PHP container.
+const SQLiteDataDir = "/var/lib/sqlite"
+
+// XdebugClientPort is the port Xdebug dials on host.docker.internal
+// when step debugging. 9003 is the Xdebug 3 default and what PhpStorm
+// and VS Code listen on out of the box.
+const XdebugClientPort = 9003
+
+// XdebugClientHost is the hostname Xdebug dials to reach the IDE. On
+// Linux it only resolves because PHPSpec maps it to host-gateway.
+const XdebugClientHost = "host.docker.internal"
+
 // SiteNetworkName is the canonical name for a site's internal bridge network.
 func SiteNetworkName(slug string) string {
 	return "locorum-" + slug
@@ -103,12 +157,13 @@ func permissiveSecurity() SecurityOptions {
 // trip-wiring on a typical 100-page site, but tight enough that a fork-bomb
 // in a hook or a hostile import is contained inside the container's cgroup.
 //
-// Defaults can be lifted on big-memory boxes via a future global setting
-// (see internal/config); the current values are the safe-by-default cap.
+// Users can raise or lower them per site through Site.Resources; builders
+// for site containers go through SiteResources, which overlays those
+// overrides on the values here.
 //
 // roleResources returns per-role resource caps. New container builders
-// should call this with their RoleX constant to pick up the right
-// memory ceiling.
+// should call this (or SiteResources) with their RoleX constant to pick
+// up the right memory ceiling.
 func roleResources(role Role) Resources {
 	r := Resources{
 		LogMaxSize:  "10m",
@@ -120,9 +175,13 @@ func roleResources(role Role) Resources {
 		r.MemoryLimit = 512 << 20
 	case RoleDatabase:
 		r.MemoryLimit = 1024 << 20
-	case RoleRedis:
+	case RoleRedis, RoleValkey:
 		r.MemoryLimit = 256 << 20
-	case RoleWeb, RoleMail, RoleAdminer, RoleRouter:
+	case RoleMemcached:
+		// Headroom over memcached's -m 64 item memory for connection
+		// buffers and the hash table.
+		r.MemoryLimit = 128 << 20
+	case RoleWeb, RoleMail, RoleDBUI, RoleRouter:
 		r.MemoryLimit = 128 << 20
 	}
 	return r
@@ -157,7 +216,7 @@ func NginxWebSpec(site *types.Site, homeDir string) ContainerSpec {
 			StartPeriod: 1 * time.Second,
 		},
 		Security:  hardenedSecurity("CHOWN", "SETGID", "SETUID", "NET_BIND_SERVICE", "DAC_OVERRIDE"),
-		Resources: roleResources(RoleWeb),
+		Resources: SiteResources(site, RoleWeb),
 		Init:      true,
 		Restart:   RestartNo,
 	}
@@ -192,7 +251,7 @@ func ApacheWebSpec(site *types.Site, homeDir string) ContainerSpec {
 			StartPeriod: 1 * time.Second,
 		},
 		Security:  hardenedSecurity("CHOWN", "SETGID", "SETUID", "NET_BIND_SERVICE", "DAC_OVERRIDE"),
-		Resources: roleResources(RoleWeb),
+		Resources: SiteResources(site, RoleWeb),
 		Init:      true,
 		Restart:   RestartNo,
 	}
@@ -219,6 +278,16 @@ func WebSpec(site *types.Site, homeDir string) ContainerSpec {
 // mount stable avoids hash churn when only the toggle flips. Activating
 // SPX adds env vars + a per-site profile-data bind, both of which DO
 // participate in the hash so the toggle correctly forces a recreate.
+//
+// Xdebug follows the same shape, gated by site.XdebugEnabled: the
+// per-site INI carrying xdebug.mode is only mounted while enabled, so
+// toggling it on or off forces a recreate. Switching between modes
+// does not: the mount stays put and only the INI's content changes,
+// which the next start rewrites.
+//
+// Per-site php.ini overrides (SitePHPINISettings) mount as zzzz-site.ini:
+// after the shared zzz-php.ini so they win over it, and before the SPX key
+// and Xdebug fragments so Locorum-managed settings still win over them.
 func PHPSpec(site *types.Site, homeDir string) ContainerSpec {
 	name := SiteContainerName(site.Slug, "php")
 	netName := SiteNetworkName(site.Slug)
@@ -265,15 +334,60 @@ func PHPSpec(site *types.Site, homeDir string) ContainerSpec {
 		{Bind: &BindMount{Source: wpcliPharHost, Target: "/usr/local/bin/wp", ReadOnly: true}},
 	}
 
-	if site.SPXEnabled {
-		// PHP_EXTENSIONS_ENABLE flips the wodby image's default disable
-		// list off for spx; the explicit DISABLE keeps xdebug/xhprof
-		// off so a Locorum upgrade to an image that re-orders the
-		// defaults can't accidentally bring them back.
+	// PHP_EXTENSIONS_ENABLE flips the wodby image's default disable
+	// list off for the opted-in extensions; the explicit DISABLE keeps
+	// the rest off so a Locorum upgrade to an image that re-orders the
+	// defaults can't accidentally bring them back. Neither var is set
+	// when nothing is opted in, so the common case hashes identically
+	// to a container created before these toggles existed.
+	//
+	// The site's own enable/disable lists are merged in after the
+	// managed set. xhprof is off by default but may be opted in there;
+	// xdebug and spx only ever follow their toggles.
+	var extEnable, extDisable []string
+	for _, ext := range []struct {
+		name string
+		on   bool
+	}{
+		{"xdebug", site.XdebugEnabled},
+		{"spx", site.SPXEnabled},
+		{"xhprof", slices.Contains(site.PHPExtensionsEnable, "xhprof")},
+	} {
+		if ext.on {
+			extEnable = append(extEnable, ext.name)
+		} else {
+			extDisable = append(extDisable, ext.name)
+		}
+	}
+	for _, ext := range site.PHPExtensionsEnable {
+		if !slices.Contains(extEnable, ext) {
+			extEnable = append(extEnable, ext)
+		}
+	}
+	for _, ext := range site.PHPExtensionsDisable {
+		if !slices.Contains(extDisable, ext) {
+			extDisable = append(extDisable, ext)
+		}
+	}
+	if len(extEnable) > 0 || len(site.PHPExtensionsDisable) > 0 {
 		env = append(env,
-			"PHP_EXTENSIONS_ENABLE=spx",
-			"PHP_EXTENSIONS_DISABLE=xdebug,xhprof",
+			"PHP_EXTENSIONS_ENABLE="+strings.Join(extEnable, ","),
+			"PHP_EXTENSIONS_DISABLE="+strings.Join(extDisable, ","),
 		)
+	}
+
+	if len(SitePHPINISettings(site)) > 0 {
+		// Regenerated by EnsurePHPIniStep before each start, so a value
+		// change needs only a restart; adding the first directive (or
+		// clearing the last) changes the mount list and recreates.
+		mounts = append(mounts, Mount{Bind: &BindMount{
+			Source:   SitePHPINIPath(homeDir, site.Slug),
+			Target:   "/usr/local/etc/php/conf.d/zzzz-site.ini",
+			ReadOnly: true,
+		}})
+	}
+
+	if site.SPXEnabled {
 		// Per-site INI override providing spx.http_key. Read-only mount
 		// — the file is regenerated by the EnsureSPXStep before each
 		// start so PHP-FPM never needs to write to it.
@@ -290,6 +404,34 @@ func PHPSpec(site *types.Site, homeDir string) ContainerSpec {
 		)
 	}
 
+	if site.DBEngine == "sqlite" {
+		// The database volume holds .ht.sqlite and the engine marker.
+		// Same volume name as a MySQL site so purge and migrate need
+		// no special case.
+		mounts = append(mounts, Mount{Volume: &VolumeMount{Name: SiteVolumeName(site.Slug), Target: SQLiteDataDir}})
+	}
+
+	extraHosts := []string{site.Domain + ":host-gateway"}
+	if site.XdebugEnabled {
+		// The INI is regenerated by EnsureXdebugStep on every start,
+		// so a mode change only needs a restart, never a hand edit.
+		// Profiler and trace output land in the data bind.
+		mounts = append(mounts,
+			Mount{Bind: &BindMount{
+				Source:   XdebugINIPath(homeDir, site.Slug),
+				Target:   "/usr/local/etc/php/conf.d/zzzz-xdebug.ini",
+				ReadOnly: true,
+			}},
+			Mount{Bind: &BindMount{
+				Source: filepath.Join(site.FilesDir, ".locorum", "xdebug"),
+				Target: "/var/xdebug/data",
+			}},
+		)
+		// Docker Desktop resolves host.docker.internal on its own;
+		// native Linux engines need the explicit mapping.
+		extraHosts = append(extraHosts, XdebugClientHost+":host-gateway")
+	}
+
 	return ContainerSpec{
 		Name:       name,
 		Image:      version.WodbyPHPImagePrefix + site.PHPVersion,
@@ -304,7 +446,7 @@ func PHPSpec(site *types.Site, homeDir string) ContainerSpec {
 			{Network: netName, Aliases: []string{"php"}},
 			{Network: GlobalNetwork},
 		},
-		ExtraHosts: []string{site.Domain + ":host-gateway"},
+		ExtraHosts: extraHosts,
 		Healthcheck: &Healthcheck{
 			// `pgrep php-fpm` ships in procps which the wodby base image
 			// includes; CMD-SHELL keeps us shell-portable across alpine /
@@ -318,28 +460,33 @@ func PHPSpec(site *types.Site, homeDir string) ContainerSpec {
 		// wodby/php uses sudo in its entrypoint; see permissiveSecurity
 		// for why we can't apply our hardened defaults here.
 		Security:  permissiveSecurity(),
-		Resources: roleResources(RolePHP),
+		Resources: SiteResources(site, RolePHP),
 		Init:      true,
 		Restart:   RestartNo,
 	}
 }
 
 // Database container specs live in the dbengine package — see
-// internal/dbengine/{mysql,mariadb}.go. The site-spec assembler in
+// internal/dbengine/{mysql,mariadb}.go. SQLite sites have none. The site-spec assembler in
 // internal/sites/sites.go:serviceSpecs routes through dbengine.Resolve(site).
 
+// Object cache specs. internal/cachebackend picks one of these per site
+// from Site.CacheBackend; a site on the "none" backend gets no container.
+// Each joins the site network under its own service name plus a neutral
+// "cache" alias.
+
 // RedisSpec builds the per-site Redis container spec.
 func RedisSpec(site *types.Site) ContainerSpec {
 	name := SiteContainerName(site.Slug, "redis")
 	netName := SiteNetworkName(site.Slug)
 	return ContainerSpec{
 		Name:   name,
-		Image:  version.RedisImagePrefix + site.RedisVersion + version.RedisImageSuffix,
+		Image:  version.RedisImagePrefix + site.CacheVersion + version.RedisImageSuffix,
 		Tty:    true,
 		Cmd:    []string{"redis-server", "--appendonly", "yes"},
 		Labels: PlatformLabels(RoleRedis, site.Slug, version.Version),
 		Networks: []NetworkAttachment{
-			{Network: netName, Aliases: []string{"redis"}},
+			{Network: netName, Aliases: []string{"redis", "cache"}},
 		},
 		Healthcheck: &Healthcheck{
 			Test:        []string{"CMD-SHELL", "redis-cli ping | grep -q PONG"},
@@ -352,29 +499,104 @@ func RedisSpec(site *types.Site) ContainerSpec {
 		// to the redis user. CHOWN + SETUID + SETGID are the minimum it
 		// needs; DAC_OVERRIDE covers the post-chown read paths.
 		Security:  hardenedSecurity("CHOWN", "SETGID", "SETUID", "DAC_OVERRIDE"),
-		Resources: roleResources(RoleRedis),
+		Resources: SiteResources(site, RoleRedis),
+		Init:      true,
+		Restart:   RestartNo,
+	}
+}
+
+// ValkeySpec builds the per-site Valkey container spec. Valkey is a
+// protocol-compatible Redis fork, so Redis object-cache drop-ins work
+// against it unchanged.
+func ValkeySpec(site *types.Site) ContainerSpec {
+	name := SiteContainerName(site.Slug, "valkey")
+	netName := SiteNetworkName(site.Slug)
+	return ContainerSpec{
+		Name:   name,
+		Image:  version.ValkeyImagePrefix + site.CacheVersion + version.ValkeyImageSuffix,
+		Tty:    true,
+		Cmd:    []string{"valkey-server", "--appendonly", "yes"},
+		Labels: PlatformLabels(RoleValkey, site.Slug, version.Version),
+		Networks: []NetworkAttachment{
+			{Network: netName, Aliases: []string{"valkey", "cache"}},
+		},
+		Healthcheck: &Healthcheck{
+			Test:        []string{"CMD-SHELL", "valkey-cli ping | grep -q PONG"},
+			Interval:    1 * time.Second,
+			Timeout:     3 * time.Second,
+			Retries:     20,
+			StartPeriod: 1 * time.Second,
+		},
+		// Same entrypoint shape as redis-alpine: chown /data as root,
+		// then drop to the valkey user.
+		Security:  hardenedSecurity("CHOWN", "SETGID", "SETUID", "DAC_OVERRIDE"),
+		Resources: SiteResources(site, RoleValkey),
 		Init:      true,
 		Restart:   RestartNo,
 	}
 }
 
-// MailSpec builds the global mailhog container spec. Joined to the global
-// network only — the router routes mail.localhost here.
+// MemcachedSpec builds the per-site Memcached container spec. Memcached
+// keeps nothing on disk, so there is no volume to persist.
+func MemcachedSpec(site *types.Site) ContainerSpec {
+	name := SiteContainerName(site.Slug, "memcached")
+	netName := SiteNetworkName(site.Slug)
+	return ContainerSpec{
+		Name:   name,
+		Image:  version.MemcachedImagePrefix + site.CacheVersion + version.MemcachedImageSuffix,
+		Tty:    true,
+		Cmd:    []string{"memcached", "-m", "64"},
+		Labels: PlatformLabels(RoleMemcached, site.Slug, version.Version),
+		Networks: []NetworkAttachment{
+			{Network: netName, Aliases: []string{"memcached", "cache"}},
+		},
+		Healthcheck: &Healthcheck{
+			// busybox nc ships in the alpine base.
+			Test:        []string{"CMD-SHELL", "echo stats | nc -w 1 127.0.0.1 11211 | grep -q uptime"},
+			Interval:    1 * time.Second,
+			Timeout:     3 * time.Second,
+			Retries:     20,
+			StartPeriod: 1 * time.Second,
+		},
+		// The image runs as the memcache user from the start; no
+		// capabilities needed.
+		Security:  hardenedSecurity(),
+		Resources: SiteResources(site, RoleMemcached),
+		Init:      true,
+		Restart:   RestartNo,
+	}
+}
+
+// MailContainerName is the global mail capture container. PHP
+// containers deliver to it on MailSMTPPort; MailAPIPort serves both the
+// web UI (via the router at mail.localhost) and the REST API.
+const (
+	MailContainerName = "locorum-global-mail"
+	MailSMTPPort      = 1025
+	MailAPIPort       = 8025
+)
+
+// MailSpec builds the global Mailpit container spec. Joined to the
+// global network — the router routes mail.localhost here — with the
+// API port also published on host loopback at an ephemeral port so the
+// daemon can query captured mail (see PublishedHostPort).
 func MailSpec() ContainerSpec {
 	return ContainerSpec{
-		Name:   "locorum-global-mail",
-		Image:  version.MailhogImage,
+		Name:   MailContainerName,
+		Image:  version.MailpitImage,
 		Tty:    true,
 		Labels: PlatformLabels(RoleMail, "", version.Version),
 		Ports: []PortMap{
-			{ContainerPort: "1025", Proto: "tcp"},
-			{ContainerPort: "8025", Proto: "tcp"},
+			{ContainerPort: strconv.Itoa(MailSMTPPort), Proto: "tcp"},
+			{HostIP: "127.0.0.1", HostPort: "0", ContainerPort: strconv.Itoa(MailAPIPort), Proto: "tcp"},
 		},
 		Networks: []NetworkAttachment{
 			{Network: GlobalNetwork, Aliases: []string{"mail"}},
 		},
 		Healthcheck: &Healthcheck{
-			Test:        []string{"CMD-SHELL", "wget -qO- http://127.0.0.1:8025/ >/dev/null 2>&1"},
+			// Mailpit's built-in probe, the same one its image's own
+			// HEALTHCHECK uses.
+			Test:        []string{"CMD", "/mailpit", "readyz"},
 			Interval:    1 * time.Second,
 			Timeout:     3 * time.Second,
 			Retries:     20,
@@ -387,21 +609,65 @@ func MailSpec() ContainerSpec {
 	}
 }
 
-// AdminerSpec builds the global adminer container spec.
-func AdminerSpec() ContainerSpec {
+// The global database UI. One engine runs at a time under
+// DBUIContainerName, picked in settings; the router serves it at
+// db.localhost. The PHP shims in DBUIAssetDir (written by internal/dbui)
+// are mounted read-only and let a sealed link from `locorum site db-ui`
+// log the browser straight into one site's database.
+const (
+	DBUIContainerName = "locorum-global-dbui"
+	DBUIAdminer       = "adminer"
+	DBUIPHPMyAdmin    = "phpmyadmin"
+	// DBUIKeyEnv carries the base64 login-link key into the container.
+	DBUIKeyEnv = "LOCORUM_DBUI_KEY"
+)
+
+// DBUIAssetDir holds the PHP files Locorum mounts into the database UI
+// container. Written by dbui.WriteAssets, mounted by DBUISpec.
+func DBUIAssetDir(homeDir string) string {
+	return filepath.Join(homeDir, ".locorum", "config", "dbui")
+}
+
+// DBUIPort is the port the engine's web server listens on inside the
+// container; the router's backend for db.localhost.
+func DBUIPort(engine string) int {
+	if engine == DBUIPHPMyAdmin {
+		return 80
+	}
+	return 8080
+}
+
+// DBUISpec builds the global database UI container for engine; anything
+// other than DBUIPHPMyAdmin gets Adminer. key is the base64 login-link
+// key, passed as a secret so it never reaches a log line.
+func DBUISpec(engine, homeDir, key string) ContainerSpec {
+	if engine == DBUIPHPMyAdmin {
+		return phpMyAdminSpec(homeDir, key)
+	}
+	return adminerSpec(homeDir, key)
+}
+
+func adminerSpec(homeDir, key string) ContainerSpec {
 	return ContainerSpec{
-		Name:   "locorum-global-adminer",
+		Name:   DBUIContainerName,
 		Image:  version.AdminerImage,
 		Tty:    true,
-		Labels: PlatformLabels(RoleAdminer, "", version.Version),
+		Labels: PlatformLabels(RoleDBUI, "", version.Version),
 		Env: []string{
 			"ADMINER_DEFAULT_SERVER=database",
 		},
+		EnvSecrets: []EnvSecret{{Key: DBUIKeyEnv, Value: key}},
 		Ports: []PortMap{
 			{ContainerPort: "8080", Proto: "tcp"},
 		},
+		Mounts: []Mount{{Bind: &BindMount{
+			// The image loads every file in plugins-enabled/.
+			Source:   filepath.Join(DBUIAssetDir(homeDir), "adminer-login.php"),
+			Target:   "/var/www/html/plugins-enabled/locorum-login.php",
+			ReadOnly: true,
+		}}},
 		Networks: []NetworkAttachment{
-			{Network: GlobalNetwork, Aliases: []string{"adminer"}},
+			{Network: GlobalNetwork, Aliases: []string{"dbui", "adminer"}},
 		},
 		Healthcheck: &Healthcheck{
 			Test:        []string{"CMD-SHELL", "wget -qO- http://127.0.0.1:8080/ >/dev/null 2>&1"},
@@ -411,7 +677,55 @@ func AdminerSpec() ContainerSpec {
 			StartPeriod: 1 * time.Second,
 		},
 		Security:  hardenedSecurity(),
-		Resources: roleResources(RoleAdminer),
+		Resources: roleResources(RoleDBUI),
+		Init:      true,
+		Restart:   RestartNo,
+	}
+}
+
+// phpMyAdminSpec runs the official Apache image. Its entrypoint
+// generates config.inc.php as root and Apache drops to www-data, hence
+// the same capability set as the Apache web container.
+func phpMyAdminSpec(homeDir, key string) ContainerSpec {
+	assets := DBUIAssetDir(homeDir)
+	return ContainerSpec{
+		Name:   DBUIContainerName,
+		Image:  version.PHPMyAdminImage,
+		Tty:    true,
+		Labels: PlatformLabels(RoleDBUI, "", version.Version),
+		Env: []string{
+			// Lets the manual login form reach any site's database
+			// container, like Adminer's server field.
+			"PMA_ARBITRARY=1",
+		},
+		EnvSecrets: []EnvSecret{{Key: DBUIKeyEnv, Value: key}},
+		Ports: []PortMap{
+			{ContainerPort: "80", Proto: "tcp"},
+		},
+		Mounts: []Mount{
+			{Bind: &BindMount{
+				Source:   filepath.Join(assets, "phpmyadmin-config.php"),
+				Target:   "/etc/phpmyadmin/config.user.inc.php",
+				ReadOnly: true,
+			}},
+			{Bind: &BindMount{
+				Source:   filepath.Join(assets, "phpmyadmin-signon.php"),
+				Target:   "/var/www/html/locorum-signon.php",
+				ReadOnly: true,
+			}},
+		},
+		Networks: []NetworkAttachment{
+			{Network: GlobalNetwork, Aliases: []string{"dbui", "phpmyadmin"}},
+		},
+		Healthcheck: &Healthcheck{
+			Test:        []string{"CMD-SHELL", "curl -fsS -o /dev/null http://127.0.0.1/ || exit 1"},
+			Interval:    1 * time.Second,
+			Timeout:     3 * time.Second,
+			Retries:     30,
+			StartPeriod: 2 * time.Second,
+		},
+		Security:  hardenedSecurity("CHOWN", "SETGID", "SETUID", "NET_BIND_SERVICE", "DAC_OVERRIDE"),
+		Resources: roleResources(RoleDBUI),
 		Init:      true,
 		Restart:   RestartNo,
 	}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/PeterBooker/locorum/internal/mail"
 	"github.com/PeterBooker/locorum/internal/types"
 )
 
@@ -19,7 +20,8 @@ func builderTestSite() *types.Site {
 		DBEngine:     "mysql",
 		DBVersion:    "8.0",
 		MySQLVersion: "8.0", // legacy mirror retained for one minor
-		RedisVersion: "7",
+		CacheBackend: "redis",
+		CacheVersion: "7",
 		WebServer:    "nginx",
 		DBPassword:   "supersecret",
 	}
@@ -40,8 +42,11 @@ func TestBuilders_AllProduceHardenedDefaults(t *testing.T) {
 		// DatabaseSpec moved to internal/dbengine/{mysql,mariadb}.go;
 		// hardened-defaults coverage there.
 		RedisSpec(site),
+		ValkeySpec(site),
+		MemcachedSpec(site),
 		MailSpec(),
-		AdminerSpec(),
+		DBUISpec(DBUIAdminer, "/home/x", "key"),
+		DBUISpec(DBUIPHPMyAdmin, "/home/x", "key"),
 	}
 	for _, s := range specs {
 		t.Run(s.Name, func(t *testing.T) {
@@ -132,6 +137,27 @@ func TestPHPSpec_BindsWPCliPhar(t *testing.T) {
 	t.Errorf("PHPSpec.Mounts missing the /usr/local/bin/wp bind — wp-cli will not be available in the container")
 }
 
+// TestPHPSpec_SQLiteVolume checks that only SQLite sites get the
+// database volume mounted into the PHP container.
+func TestPHPSpec_SQLiteVolume(t *testing.T) {
+	hasVolume := func(spec ContainerSpec) bool {
+		for _, m := range spec.Mounts {
+			if m.Volume != nil && m.Volume.Target == SQLiteDataDir {
+				return m.Volume.Name == SiteVolumeName("demo")
+			}
+		}
+		return false
+	}
+	site := builderTestSite()
+	if hasVolume(PHPSpec(site, "/home/x")) {
+		t.Errorf("MySQL site should not mount the database volume into PHP")
+	}
+	site.DBEngine = "sqlite"
+	if !hasVolume(PHPSpec(site, "/home/x")) {
+		t.Errorf("SQLite site missing the %s volume mount", SQLiteDataDir)
+	}
+}
+
 // TestPHPSpec_FPMUserOverride locks in the workaround for wodby/php's
 // FPM pool defaulting to www-data (UID 82). Without these env vars, FPM
 // workers can't read the 0600 wp-config files owned by 1000:1000 and
@@ -255,6 +281,128 @@ func TestPHPSpec_SPXOn(t *testing.T) {
 	}
 }
 
+// TestPHPSpec_XdebugOn asserts enabling Xdebug mounts the per-site INI
+// and output dir, maps host.docker.internal, flips the extension on
+// while keeping SPX explicitly disabled, and changes the config hash.
+func TestPHPSpec_XdebugOn(t *testing.T) {
+	site := builderTestSite()
+	site.XdebugEnabled = true
+	site.XdebugMode = "debug"
+
+	off := PHPSpec(builderTestSite(), "/home/x")
+	on := PHPSpec(site, "/home/x")
+
+	if off.ConfigHash() == on.ConfigHash() {
+		t.Errorf("toggling Xdebug did not change ConfigHash; container would not be recreated")
+	}
+	if hasBindTarget(off.Mounts, "/usr/local/etc/php/conf.d/zzzz-xdebug.ini") {
+		t.Errorf("xdebug INI mounted while Xdebug disabled")
+	}
+	if !hasBindTarget(on.Mounts, "/usr/local/etc/php/conf.d/zzzz-xdebug.ini") {
+		t.Errorf("xdebug INI bind missing when Xdebug enabled")
+	}
+	if !hasBindTarget(on.Mounts, "/var/xdebug/data") {
+		t.Errorf("/var/xdebug/data bind missing when Xdebug enabled")
+	}
+
+	env := strings.Join(on.Env, "\n")
+	if !strings.Contains(env, "PHP_EXTENSIONS_ENABLE=xdebug\n") {
+		t.Errorf("PHP_EXTENSIONS_ENABLE=xdebug missing on Env: %v", on.Env)
+	}
+	if !strings.Contains(env, "PHP_EXTENSIONS_DISABLE=spx,xhprof") {
+		t.Errorf("PHP_EXTENSIONS_DISABLE=spx,xhprof missing on Env: %v", on.Env)
+	}
+
+	foundHost := false
+	for _, h := range on.ExtraHosts {
+		if h == XdebugClientHost+":host-gateway" {
+			foundHost = true
+		}
+	}
+	if !foundHost {
+		t.Errorf("ExtraHosts missing %s mapping: %v", XdebugClientHost, on.ExtraHosts)
+	}
+}
+
+// TestPHPSpec_XdebugAndSPX asserts both profilers can be enabled at
+// once without either landing on the disable list.
+func TestPHPSpec_XdebugAndSPX(t *testing.T) {
+	site := builderTestSite()
+	site.XdebugEnabled = true
+	site.XdebugMode = "profile"
+	site.SPXEnabled = true
+	site.SPXKey = "k"
+
+	spec := PHPSpec(site, "/home/x")
+	env := strings.Join(spec.Env, "\n")
+	if !strings.Contains(env, "PHP_EXTENSIONS_ENABLE=xdebug,spx") {
+		t.Errorf("PHP_EXTENSIONS_ENABLE should list xdebug and spx: %v", spec.Env)
+	}
+	if !strings.Contains(env, "PHP_EXTENSIONS_DISABLE=xhprof") {
+		t.Errorf("PHP_EXTENSIONS_DISABLE should list only xhprof: %v", spec.Env)
+	}
+}
+
+// TestPHPSpec_SiteOverrides covers the per-site php.ini mount and the
+// extension lists: nothing changes for a site without overrides, the
+// INI mounts after the shared php.ini, and the site's lists merge with
+// the managed Xdebug/SPX set.
+func TestPHPSpec_SiteOverrides(t *testing.T) {
+	plain := PHPSpec(builderTestSite(), "/home/x")
+	for _, e := range plain.Env {
+		if strings.HasPrefix(e, "PHP_EXTENSIONS_") {
+			t.Errorf("site without overrides sets %s", e)
+		}
+	}
+	if hasBindTarget(plain.Mounts, "/usr/local/etc/php/conf.d/zzzz-site.ini") {
+		t.Errorf("site INI mounted without any overrides")
+	}
+
+	site := builderTestSite()
+	site.PHPIni = map[string]string{"max_input_vars": "5000"}
+	site.PHPExtensionsEnable = []string{"xhprof", "pcov"}
+	site.PHPExtensionsDisable = []string{"newrelic"}
+	spec := PHPSpec(site, "/home/x")
+
+	if !hasBindTarget(spec.Mounts, "/usr/local/etc/php/conf.d/zzzz-site.ini") {
+		t.Errorf("site INI bind missing when PHPIni is set")
+	}
+	if spec.ConfigHash() == plain.ConfigHash() {
+		t.Errorf("overrides did not change ConfigHash; container would not be recreated")
+	}
+	env := strings.Join(spec.Env, "\n")
+	if !strings.Contains(env, "PHP_EXTENSIONS_ENABLE=xhprof,pcov\n") {
+		t.Errorf("PHP_EXTENSIONS_ENABLE should list xhprof and pcov: %v", spec.Env)
+	}
+	if !strings.Contains(env, "PHP_EXTENSIONS_DISABLE=xdebug,spx,newrelic") {
+		t.Errorf("PHP_EXTENSIONS_DISABLE should add newrelic to the managed set: %v", spec.Env)
+	}
+}
+
+// TestPHPSpec_BlackholeMail: a blackhole site mounts the per-site INI
+// even without user overrides, and its sendmail_path beats a user one.
+func TestPHPSpec_BlackholeMail(t *testing.T) {
+	site := builderTestSite()
+	site.MailMode = mail.ModeBlackhole
+	if !hasBindTarget(PHPSpec(site, "/home/x").Mounts, "/usr/local/etc/php/conf.d/zzzz-site.ini") {
+		t.Errorf("site INI not mounted for a blackhole site")
+	}
+
+	site.PHPIni = map[string]string{"sendmail_path": "/usr/sbin/sendmail -t", "max_input_vars": "5000"}
+	got := SitePHPINISettings(site)
+	if got["sendmail_path"] != blackholeSendmailPath || got["max_input_vars"] != "5000" {
+		t.Errorf("SitePHPINISettings = %v", got)
+	}
+	if site.PHPIni["sendmail_path"] != "/usr/sbin/sendmail -t" {
+		t.Errorf("SitePHPINISettings modified site.PHPIni")
+	}
+
+	site.MailMode = mail.ModeRelay
+	if got := SitePHPINISettings(site); got["sendmail_path"] != "/usr/sbin/sendmail -t" {
+		t.Errorf("relay site sendmail_path = %q, want the user's", got["sendmail_path"])
+	}
+}
+
 // hasBindTarget reports whether mounts contains a BindMount targeting
 // the given container path.
 func hasBindTarget(mounts []Mount, target string) bool {
@@ -273,7 +421,8 @@ func TestSpec_HealthcheckRequired(t *testing.T) {
 		PHPSpec(site, "/home/x"),
 		RedisSpec(site),
 		MailSpec(),
-		AdminerSpec(),
+		DBUISpec(DBUIAdminer, "/home/x", "key"),
+		DBUISpec(DBUIPHPMyAdmin, "/home/x", "key"),
 	} {
 		if spec.Healthcheck == nil || len(spec.Healthcheck.Test) == 0 {
 			t.Errorf("%s: missing healthcheck", spec.Name)
@@ -281,6 +430,27 @@ func TestSpec_HealthcheckRequired(t *testing.T) {
 	}
 }
 
+// TestMailSpec_APIOnLoopbackOnly guards the capture API's exposure:
+// the daemon reads it through an ephemeral host port, which must never
+// bind beyond loopback. SMTP stays internal to the global network.
+func TestMailSpec_APIOnLoopbackOnly(t *testing.T) {
+	spec := MailSpec()
+	for _, p := range spec.Ports {
+		switch p.ContainerPort {
+		case "8025":
+			if p.HostIP != "127.0.0.1" || p.HostPort != "0" {
+				t.Errorf("API port binding = %+v, want ephemeral on 127.0.0.1", p)
+			}
+		case "1025":
+			if p.HostPort != "" {
+				t.Errorf("SMTP port published to the host: %+v", p)
+			}
+		default:
+			t.Errorf("unexpected port %+v", p)
+		}
+	}
+}
+
 func TestPHPSpec_UID_GID_Pair(t *testing.T) {
 	site := builderTestSite()
 	spec := PHPSpec(site, "/home/x")
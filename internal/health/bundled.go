@@ -33,6 +33,32 @@ type BundledOpts struct {
 	// path-shape checks (mnt-c, longpath) are omitted.
 	Sites SiteRootLister
 
+	// XdebugSites lists running sites in Xdebug step-debug mode.
+	// Optional — when nil the IDE-port check is omitted.
+	XdebugSites XdebugSiteLister
+
+	// XdebugPort is the IDE port the Xdebug check probes. Pass
+	// docker.XdebugClientPort.
+	XdebugPort int
+
+	// ConfigDrift lists sites with unapplied config.yaml edits.
+	// Optional — when nil the drift check is omitted.
+	ConfigDrift ConfigDriftLister
+
+	// OOMKills lists running sites with an OOM-killed container.
+	// Optional — when nil the out-of-memory check is omitted.
+	OOMKills OOMKillLister
+
+	// Certs reads the issued-cert inventory. Optional — when nil the
+	// cert-expiry check is omitted.
+	Certs CertInventoryReader
+
+	// DNSTLD and DNSListen describe the embedded DNS server. Leave
+	// DNSTLD empty when the server is off and the resolver check is
+	// omitted.
+	DNSTLD    string
+	DNSListen string
+
 	// HostStatfsPath is the directory passed to platform.HostFreeBytes
 	// for the disk-low check. Typically platform.Get().HomeDir; on
 	// Windows native callers may want to pass the drive root.
@@ -106,9 +132,29 @@ func Bundled(opts BundledOpts) []Check {
 		}
 	}
 
+	if opts.XdebugSites != nil && opts.XdebugPort > 0 {
+		out = append(out, NewXdebugIDEPortCheck(opts.XdebugSites, opts.XdebugPort))
+	}
+
+	if opts.ConfigDrift != nil {
+		out = append(out, NewConfigDriftCheck(opts.ConfigDrift))
+	}
+
+	if opts.OOMKills != nil {
+		out = append(out, NewOOMKillCheck(opts.OOMKills))
+	}
+
 	if opts.Mkcert != nil {
 		out = append(out, NewMkcertCheck(opts.Mkcert, opts.MkcertInstaller))
 	}
 
+	if opts.Certs != nil {
+		out = append(out, NewCertExpiryCheck(opts.Certs))
+	}
+
+	if opts.DNSTLD != "" && opts.DNSListen != "" {
+		out = append(out, NewDNSResolverCheck(opts.DNSTLD, opts.DNSListen))
+	}
+
 	return out
 }
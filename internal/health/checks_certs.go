@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
+)
+
+// CertInventoryReader is the interface the cert-expiry check needs.
+// SiteManager satisfies it: CertInventory parses every issued cert and
+// RenewDueCerts re-issues the ones that need it.
+type CertInventoryReader interface {
+	CertInventory(ctx context.Context) (tlspkg.Inventory, error)
+	RenewDueCerts(ctx context.Context) error
+}
+
+// CertExpiryCheck warns before issued certs expire and after the root CA
+// changes underneath them. Issue only re-signs on a site start, so a
+// site left running for months — or a CAROOT regenerated by hand — would
+// otherwise surface as a browser error with no hint of the cause.
+type CertExpiryCheck struct {
+	certs CertInventoryReader
+	now   func() time.Time
+}
+
+// NewCertExpiryCheck builds the check.
+func NewCertExpiryCheck(certs CertInventoryReader) *CertExpiryCheck {
+	return &CertExpiryCheck{certs: certs, now: time.Now}
+}
+
+func (*CertExpiryCheck) ID() string             { return "cert-expiry" }
+func (*CertExpiryCheck) Cadence() time.Duration { return time.Hour }
+func (*CertExpiryCheck) Budget() time.Duration  { return 2 * time.Second }
+
+func (c *CertExpiryCheck) Run(ctx context.Context) ([]Finding, error) {
+	if c.certs == nil {
+		return nil, nil
+	}
+	inv, err := c.certs.CertInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+
+	var out []Finding
+	if root := inv.Root; root != nil && root.NotAfter.Sub(now) < tlspkg.RootWarnBefore {
+		out = append(out, Finding{
+			ID:       c.ID(),
+			Severity: SeverityWarn,
+			DedupKey: "root",
+			Title:    "Local CA " + expiryPhrase(root.NotAfter, now),
+			Detail: "Every Locorum certificate chains to the root CA at " + root.Path +
+				"; once it expires, browsers reject all of them.",
+			Remediation: "Create a new root with your certificate provider, trust it with ‘Set up trusted HTTPS’, " +
+				"then run `locorum certs renew --all`.",
+		})
+	}
+
+	due := inv.Due(now)
+	if len(due) == 0 {
+		return out, nil
+	}
+	reasons := make([]string, 0, len(due))
+	for _, cert := range due {
+		reasons = append(reasons, cert.Name+" ("+renewalReason(cert, now)+")")
+	}
+	f := Finding{
+		ID:       c.ID(),
+		Severity: SeverityWarn,
+		DedupKey: "leaf",
+		Title:    fmt.Sprintf("%d certificate(s) need renewal", len(due)),
+		Detail:   "Due: " + strings.Join(reasons, ", ") + ".",
+		Remediation: "Renew them below or run `locorum certs renew`; running sites pick up the new " +
+			"certificates without a restart.",
+		Action: &Action{
+			Label:   "Renew certificates",
+			Run:     c.certs.RenewDueCerts,
+			Timeout: 2 * time.Minute,
+		},
+	}
+	return append(out, f), nil
+}
+
+// renewalReason is the short parenthetical for one due cert.
+func renewalReason(cert tlspkg.CertInfo, now time.Time) string {
+	switch {
+	case cert.Error != "":
+		return "unreadable"
+	case !cert.CurrentRoot:
+		return "signed by a previous CA"
+	default:
+		return expiryPhrase(cert.NotAfter, now)
+	}
+}
+
+func expiryPhrase(notAfter, now time.Time) string {
+	if !notAfter.After(now) {
+		return "expired on " + notAfter.Format("2 Jan 2006")
+	}
+	return "expires on " + notAfter.Format("2 Jan 2006")
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigDriftLister is the interface the config.yaml drift check needs.
+// SiteManager satisfies it: ConfigDriftFields maps each slug with
+// unapplied config.yaml edits to the fields that differ, and
+// ApplyConfigYAMLForSlug copies those edits onto the site.
+type ConfigDriftLister interface {
+	ConfigDriftFields(ctx context.Context) map[string][]string
+	ApplyConfigYAMLForSlug(ctx context.Context, slug string) error
+}
+
+// ConfigDriftCheck reports sites whose .locorum/config.yaml was edited
+// after Locorum last wrote it. Until the edits are applied or discarded
+// Locorum stops regenerating the file, so the finding stays up rather
+// than letting the two copies quietly diverge.
+type ConfigDriftCheck struct {
+	sites ConfigDriftLister
+}
+
+// NewConfigDriftCheck builds the check.
+func NewConfigDriftCheck(sites ConfigDriftLister) *ConfigDriftCheck {
+	return &ConfigDriftCheck{sites: sites}
+}
+
+func (*ConfigDriftCheck) ID() string             { return "config-yaml-drift" }
+func (*ConfigDriftCheck) Cadence() time.Duration { return time.Minute }
+func (*ConfigDriftCheck) Budget() time.Duration  { return 2 * time.Second }
+
+func (c *ConfigDriftCheck) Run(ctx context.Context) ([]Finding, error) {
+	if c.sites == nil {
+		return nil, nil
+	}
+	drifts := c.sites.ConfigDriftFields(ctx)
+	if len(drifts) == 0 {
+		return nil, nil
+	}
+	slugs := make([]string, 0, len(drifts))
+	for slug := range drifts {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	out := make([]Finding, 0, len(slugs))
+	for _, slug := range slugs {
+		out = append(out, Finding{
+			ID:       c.ID(),
+			Severity: SeverityWarn,
+			DedupKey: slug,
+			Title:    "config.yaml for " + slug + " has unapplied changes",
+			Detail: "The file was edited after Locorum last wrote it. Changed: " +
+				strings.Join(drifts[slug], ", ") + ".",
+			Remediation: "Apply the changes (stop the site first if they include versions or the web server), " +
+				"or run `locorum site sync-config " + slug + " --discard` to keep the current settings.",
+			Action: &Action{
+				Label: "Apply changes",
+				Run: func(ctx context.Context) error {
+					return c.sites.ApplyConfigYAMLForSlug(ctx, slug)
+				},
+			},
+		})
+	}
+	return out, nil
+}
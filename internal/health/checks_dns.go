@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/devdns"
+)
+
+// lookupFunc resolves a hostname to its IPv4 addresses.
+type lookupFunc func(ctx context.Context, name string) ([]net.IP, error)
+
+// DNSResolverCheck verifies the embedded DNS server end to end. It
+// resolves a fresh probe name twice: straight at the server, then
+// through the OS resolver. The first failing tells the user the server
+// itself is down; only the second failing means the OS hasn't been
+// pointed at it — which is the usual state right after turning it on.
+type DNSResolverCheck struct {
+	tld    string
+	listen string
+	goos   string
+	direct lookupFunc
+	system lookupFunc
+}
+
+// NewDNSResolverCheck builds the check for the server bound to listen,
+// answering for tld.
+func NewDNSResolverCheck(tld, listen string) *DNSResolverCheck {
+	return &DNSResolverCheck{
+		tld:    tld,
+		listen: listen,
+		goos:   runtime.GOOS,
+		direct: func(ctx context.Context, name string) ([]net.IP, error) {
+			return devdns.Lookup(ctx, listen, name)
+		},
+		system: func(ctx context.Context, name string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip4", name)
+		},
+	}
+}
+
+func (*DNSResolverCheck) ID() string             { return "dns-resolver" }
+func (*DNSResolverCheck) Cadence() time.Duration { return 5 * time.Minute }
+func (*DNSResolverCheck) Budget() time.Duration  { return 3 * time.Second }
+
+func (c *DNSResolverCheck) Run(ctx context.Context) ([]Finding, error) {
+	name := devdns.NewProbeName(c.tld)
+	if !answersProbe(ctx, c.direct, name) {
+		return []Finding{{
+			ID:       c.ID(),
+			Severity: SeverityWarn,
+			DedupKey: "server",
+			Title:    "Locorum DNS server is not answering",
+			Detail:   "The embedded DNS server on " + c.listen + " did not answer a test query. Names under ." + c.tld + " won't resolve.",
+			Remediation: "Check the log for a bind error — another process may hold " + c.listen +
+				". Pick a different DNS listen address in Settings → Network & TLS and restart Locorum.",
+			HelpURL: "https://docs.locorum.dev/dns",
+		}}, nil
+	}
+	if answersProbe(ctx, c.system, name) {
+		return nil, nil
+	}
+	return []Finding{{
+		ID:       c.ID(),
+		Severity: SeverityWarn,
+		DedupKey: "resolver",
+		Title:    "System DNS isn't using Locorum for ." + c.tld,
+		Detail: "The embedded DNS server is running, but lookups for ." + c.tld +
+			" names go elsewhere, so browsers can't reach sites on those names.",
+		Remediation: "Route ." + c.tld + " to Locorum once on this machine: " + devdns.SetupHint(c.goos, c.tld, c.listen),
+		HelpURL:     "https://docs.locorum.dev/dns",
+	}}, nil
+}
+
+func answersProbe(ctx context.Context, lookup lookupFunc, name string) bool {
+	ips, err := lookup(ctx, name)
+	if err != nil {
+		return false
+	}
+	for _, ip := range ips {
+		if ip.Equal(devdns.ProbeIP) {
+			return true
+		}
+	}
+	return false
+}
@@ -59,6 +59,13 @@ func (c *MkcertCheck) Run(ctx context.Context) ([]Finding, error) {
 			Remediation: "Install mkcert and run `mkcert -install` so browsers trust Locorum-issued certificates.",
 			HelpURL:     "https://github.com/FiloSottile/mkcert#installation",
 		}
+		if status.Installed {
+			// The provider is usable; only the trust step is missing.
+			// Worded generically because the built-in CA has no binary
+			// to install and no `mkcert -install` to run.
+			f.Remediation = "Use ‘Set up trusted HTTPS’ to add the local certificate authority to your system trust store, then restart your browser."
+			f.HelpURL = ""
+		}
 		if c.installer != nil {
 			f.Action = &Action{
 				Label:   "Set up trusted HTTPS",
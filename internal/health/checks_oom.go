@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OOMKillLister is the interface the out-of-memory check needs.
+// SiteManager satisfies it: OOMKilledServices inspects the containers of
+// running sites and maps each slug to the services the kernel killed for
+// exceeding their memory limit.
+type OOMKillLister interface {
+	OOMKilledServices(ctx context.Context) map[string][]string
+}
+
+// OOMKillCheck reports running sites with a container that was
+// OOM-killed. Containers run with restart "no", so a killed PHP or
+// database container stays down while the site still shows as started —
+// the symptom the user sees is a 502, not a memory error.
+type OOMKillCheck struct {
+	sites OOMKillLister
+}
+
+// NewOOMKillCheck builds the check.
+func NewOOMKillCheck(sites OOMKillLister) *OOMKillCheck {
+	return &OOMKillCheck{sites: sites}
+}
+
+func (*OOMKillCheck) ID() string             { return "container-oom-killed" }
+func (*OOMKillCheck) Cadence() time.Duration { return time.Minute }
+func (*OOMKillCheck) Budget() time.Duration  { return 5 * time.Second }
+
+func (c *OOMKillCheck) Run(ctx context.Context) ([]Finding, error) {
+	if c.sites == nil {
+		return nil, nil
+	}
+	killed := c.sites.OOMKilledServices(ctx)
+	if len(killed) == 0 {
+		return nil, nil
+	}
+	slugs := make([]string, 0, len(killed))
+	for slug := range killed {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	out := make([]Finding, 0, len(slugs))
+	for _, slug := range slugs {
+		services := strings.Join(killed[slug], ", ")
+		out = append(out, Finding{
+			ID:       c.ID(),
+			Severity: SeverityWarn,
+			DedupKey: slug,
+			Title:    slug + " ran out of memory",
+			Detail: "The kernel stopped " + services + " after it reached its memory limit. " +
+				"The container stays down until the site is restarted.",
+			Remediation: "Raise the memory limit in the site's Resources settings (or `resources:` in " +
+				".locorum/config.yaml) and restart the site. If several heavy sites run at once, " +
+				"stopping one frees memory for the others.",
+		})
+	}
+	return out, nil
+}
@@ -5,11 +5,15 @@ import (
 	"errors"
 	"net"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/PeterBooker/locorum/internal/devdns"
 	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/docker/fake"
 	"github.com/PeterBooker/locorum/internal/platform"
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
 	"github.com/PeterBooker/locorum/internal/version"
 )
 
@@ -265,3 +269,180 @@ func TestWSLMntCCheckSilentOutsideWSL(t *testing.T) {
 		t.Errorf("expected no findings when not in WSL; got %+v", out)
 	}
 }
+
+// fakeXdebugSites satisfies XdebugSiteLister.
+type fakeXdebugSites struct{ slugs []string }
+
+func (f *fakeXdebugSites) XdebugDebugSites(_ context.Context) []string { return f.slugs }
+
+func TestXdebugIDEPortCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	c := NewXdebugIDEPortCheck(&fakeXdebugSites{slugs: []string{"shop"}}, port)
+	if out, _ := c.Run(context.Background()); len(out) != 0 {
+		t.Errorf("expected no finding while the IDE listens; got %+v", out)
+	}
+
+	ln.Close()
+	out, _ := c.Run(context.Background())
+	if len(out) != 1 || out[0].Severity != SeverityWarn {
+		t.Fatalf("expected one warn finding with no listener; got %+v", out)
+	}
+
+	c = NewXdebugIDEPortCheck(&fakeXdebugSites{}, port)
+	if out, _ := c.Run(context.Background()); len(out) != 0 {
+		t.Errorf("expected no finding without debug-mode sites; got %+v", out)
+	}
+}
+
+// fakeConfigDrift satisfies ConfigDriftLister.
+type fakeConfigDrift struct {
+	fields  map[string][]string
+	applied []string
+}
+
+func (f *fakeConfigDrift) ConfigDriftFields(_ context.Context) map[string][]string { return f.fields }
+
+func (f *fakeConfigDrift) ApplyConfigYAMLForSlug(_ context.Context, slug string) error {
+	f.applied = append(f.applied, slug)
+	return nil
+}
+
+func TestConfigDriftCheck(t *testing.T) {
+	sites := &fakeConfigDrift{fields: map[string][]string{
+		"shop": {"php_version", "hooks"},
+		"blog": {"web_server"},
+	}}
+	out, err := NewConfigDriftCheck(sites).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || out[0].DedupKey != "blog" || out[1].DedupKey != "shop" {
+		t.Fatalf("expected one finding per site in slug order; got %+v", out)
+	}
+	if !strings.Contains(out[1].Detail, "php_version, hooks") {
+		t.Errorf("detail missing field list: %q", out[1].Detail)
+	}
+	if out[1].Action == nil {
+		t.Fatal("expected an apply action")
+	}
+	if err := out[1].Action.Run(context.Background()); err != nil || len(sites.applied) != 1 || sites.applied[0] != "shop" {
+		t.Errorf("action applied %v, err %v", sites.applied, err)
+	}
+
+	sites.fields = nil
+	if out, _ := NewConfigDriftCheck(sites).Run(context.Background()); len(out) != 0 {
+		t.Errorf("expected no findings without drift; got %+v", out)
+	}
+}
+
+// fakeOOMKills satisfies OOMKillLister.
+type fakeOOMKills struct{ killed map[string][]string }
+
+func (f *fakeOOMKills) OOMKilledServices(_ context.Context) map[string][]string { return f.killed }
+
+func TestOOMKillCheck(t *testing.T) {
+	sites := &fakeOOMKills{killed: map[string][]string{
+		"shop": {"database", "php"},
+	}}
+	out, err := NewOOMKillCheck(sites).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].DedupKey != "shop" || out[0].Severity != SeverityWarn {
+		t.Fatalf("expected one warn finding for shop; got %+v", out)
+	}
+	if !strings.Contains(out[0].Detail, "database, php") {
+		t.Errorf("detail missing service list: %q", out[0].Detail)
+	}
+
+	sites.killed = nil
+	if out, _ := NewOOMKillCheck(sites).Run(context.Background()); len(out) != 0 {
+		t.Errorf("expected no findings without kills; got %+v", out)
+	}
+}
+
+// fakeCertInventory satisfies CertInventoryReader.
+type fakeCertInventory struct {
+	inv     tlspkg.Inventory
+	renewed int
+}
+
+func (f *fakeCertInventory) CertInventory(_ context.Context) (tlspkg.Inventory, error) {
+	return f.inv, nil
+}
+
+func (f *fakeCertInventory) RenewDueCerts(_ context.Context) error {
+	f.renewed++
+	return nil
+}
+
+func TestCertExpiryCheck(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	certs := &fakeCertInventory{inv: tlspkg.Inventory{
+		Root: &tlspkg.RootInfo{Path: "/ca/rootCA.pem", NotAfter: now.AddDate(5, 0, 0)},
+		Certs: []tlspkg.CertInfo{
+			{Name: "site-blog", NotAfter: now.AddDate(1, 0, 0), CurrentRoot: true},
+			{Name: "site-shop", NotAfter: now.AddDate(0, 0, 10), CurrentRoot: true},
+			{Name: "svc-mail", NotAfter: now.AddDate(1, 0, 0), CurrentRoot: false},
+		},
+	}}
+	c := NewCertExpiryCheck(certs)
+	c.now = func() time.Time { return now }
+
+	out, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].DedupKey != "leaf" {
+		t.Fatalf("expected one leaf finding; got %+v", out)
+	}
+	if !strings.Contains(out[0].Detail, "site-shop (expires on 11 Jun 2026)") ||
+		!strings.Contains(out[0].Detail, "svc-mail (signed by a previous CA)") ||
+		strings.Contains(out[0].Detail, "site-blog") {
+		t.Errorf("unexpected detail: %q", out[0].Detail)
+	}
+	if out[0].Action == nil {
+		t.Fatal("expected a renew action")
+	}
+	if err := out[0].Action.Run(context.Background()); err != nil || certs.renewed != 1 {
+		t.Errorf("renew action ran %d times, err %v", certs.renewed, err)
+	}
+
+	certs.inv.Root.NotAfter = now.AddDate(0, 1, 0)
+	certs.inv.Certs = certs.inv.Certs[:1]
+	out, _ = c.Run(context.Background())
+	if len(out) != 1 || out[0].DedupKey != "root" {
+		t.Errorf("expected only the root finding; got %+v", out)
+	}
+}
+
+func TestDNSResolverCheck(t *testing.T) {
+	probe := func(ctx context.Context, name string) ([]net.IP, error) { return []net.IP{devdns.ProbeIP}, nil }
+	wrong := func(ctx context.Context, name string) ([]net.IP, error) { return []net.IP{net.IPv4(10, 0, 0, 1)}, nil }
+	fail := func(ctx context.Context, name string) ([]net.IP, error) { return nil, errors.New("no such host") }
+
+	c := NewDNSResolverCheck("test", "127.0.0.1:5300")
+	c.goos = "darwin"
+
+	c.direct, c.system = probe, probe
+	if out, _ := c.Run(context.Background()); len(out) != 0 {
+		t.Errorf("expected no finding when both resolve; got %+v", out)
+	}
+
+	c.direct, c.system = fail, fail
+	out, _ := c.Run(context.Background())
+	if len(out) != 1 || out[0].DedupKey != "server" {
+		t.Fatalf("expected a server finding; got %+v", out)
+	}
+
+	c.direct, c.system = probe, wrong
+	out, _ = c.Run(context.Background())
+	if len(out) != 1 || out[0].DedupKey != "resolver" || !strings.Contains(out[0].Remediation, "/etc/resolver/test") {
+		t.Fatalf("expected a resolver finding with the setup hint; got %+v", out)
+	}
+}
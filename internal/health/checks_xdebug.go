@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XdebugSiteLister is the read-only interface the Xdebug IDE-port check
+// needs. SiteManager satisfies it by returning the slugs of running
+// sites with Xdebug in step-debug mode.
+type XdebugSiteLister interface {
+	XdebugDebugSites(ctx context.Context) []string
+}
+
+// XdebugIDEPortCheck warns when a site has step debugging on but nothing
+// is listening on the IDE port. Xdebug dials host.docker.internal, which
+// lands on the host, so a local dial is a faithful probe. Without a
+// listener every triggered request stalls for xdebug.connect_timeout_ms
+// and then runs undebugged — easy to mistake for a broken breakpoint.
+type XdebugIDEPortCheck struct {
+	sites XdebugSiteLister
+	port  int
+}
+
+// NewXdebugIDEPortCheck builds the check for the given IDE port.
+func NewXdebugIDEPortCheck(sites XdebugSiteLister, port int) *XdebugIDEPortCheck {
+	return &XdebugIDEPortCheck{sites: sites, port: port}
+}
+
+func (*XdebugIDEPortCheck) ID() string             { return "xdebug-ide-port" }
+func (*XdebugIDEPortCheck) Cadence() time.Duration { return 5 * time.Minute }
+func (*XdebugIDEPortCheck) Budget() time.Duration  { return time.Second }
+
+func (c *XdebugIDEPortCheck) Run(ctx context.Context) ([]Finding, error) {
+	if c.sites == nil {
+		return nil, nil
+	}
+	slugs := c.sites.XdebugDebugSites(ctx)
+	if len(slugs) == 0 {
+		return nil, nil
+	}
+	if portInUse(ctx, c.port) {
+		return nil, nil
+	}
+	port := strconv.Itoa(c.port)
+	return []Finding{{
+		ID:       c.ID(),
+		Severity: SeverityWarn,
+		Title:    "No debugger listening on port " + port,
+		Detail: "Xdebug step debugging is enabled for " + strings.Join(slugs, ", ") +
+			" but nothing accepts connections on host.docker.internal:" + port + ".",
+		Remediation: "Start listening for Xdebug connections in your IDE on port " + port +
+			", or switch the site's Xdebug mode off.",
+		HelpURL: "https://docs.locorum.dev/xdebug",
+	}}, nil
+}
@@ -35,7 +35,7 @@ func (c EnvContext) String() string {
 
 // HostDBPort is the host port published by the site database container in
 // host-context tasks. Locorum currently does not publish per-site DB ports
-// to the host; users typically connect via Adminer at db.localhost. We
+// to the host; users typically connect via the DB UI at db.localhost. We
 // expose the in-container port (3306) here as a sensible default. If/when
 // the platform starts publishing per-site DB ports, swap this for a lookup
 // against the docker port mapping.
@@ -86,7 +86,9 @@ func BuildEnv(site *types.Site, ctx EnvContext) []string {
 		"LOCORUM_PRIMARY_URL":   primaryURL(domain),
 		"LOCORUM_PHP_VERSION":   site.PHPVersion,
 		"LOCORUM_MYSQL_VERSION": site.MySQLVersion, //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
-		"LOCORUM_REDIS_VERSION": site.RedisVersion,
+		"LOCORUM_REDIS_VERSION": site.RedisVersion, //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+		"LOCORUM_CACHE_BACKEND": site.CacheBackend,
+		"LOCORUM_CACHE_VERSION": site.CacheVersion,
 		"LOCORUM_WEBSERVER":     webServer,
 		"LOCORUM_MULTISITE":     site.Multisite,
 		"LOCORUM_FILES_DIR":     site.FilesDir,
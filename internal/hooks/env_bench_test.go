@@ -16,7 +16,7 @@ func BenchmarkBuildEnv(b *testing.B) {
 		PublicDir:    "/",
 		PHPVersion:   "8.3",
 		MySQLVersion: "8.4",
-		RedisVersion: "7",
+		CacheVersion: "7",
 		WebServer:    "nginx",
 		Multisite:    "subdomain",
 		DBPassword:   "p",
@@ -28,7 +28,7 @@ func FuzzBuildEnv(f *testing.F) {
 			Domain:       dom,
 			PHPVersion:   php,
 			MySQLVersion: "8.4",
-			RedisVersion: "7",
+			CacheVersion: "7",
 			DBPassword:   "p",
 		}
 		env := BuildEnv(s, ContextContainer)
@@ -44,6 +44,8 @@ func TestBuildEnv_FullSite_Container(t *testing.T) {
 		PHPVersion:   "8.3",
 		MySQLVersion: "8.0",
 		RedisVersion: "7.4",
+		CacheBackend: "redis",
+		CacheVersion: "7.4",
 		DBPassword:   "topsecret",
 		WebServer:    "apache",
 		Multisite:    "subdomain",
@@ -61,6 +63,8 @@ func TestBuildEnv_FullSite_Container(t *testing.T) {
 		"LOCORUM_PHP_VERSION":   "8.3",
 		"LOCORUM_MYSQL_VERSION": "8.0",
 		"LOCORUM_REDIS_VERSION": "7.4",
+		"LOCORUM_CACHE_BACKEND": "redis",
+		"LOCORUM_CACHE_VERSION": "7.4",
 		"LOCORUM_WEBSERVER":     "apache",
 		"LOCORUM_MULTISITE":     "subdomain",
 		"LOCORUM_FILES_DIR":     "/home/u/locorum/sites/demo",
@@ -36,6 +36,13 @@ const (
 	PreSnapshot  Event = "pre-snapshot"
 	PostSnapshot Event = "post-snapshot"
 
+	// Whole-site import from an ExportSite archive. pre-import-site
+	// fires once the row exists but before the first start;
+	// post-import-site fires with the site running and the database
+	// restored.
+	PreImportSite  Event = "pre-import-site"
+	PostImportSite Event = "post-import-site"
+
 	// LAN access toggles (ACCESS.md). Fire around the EnableLAN /
 	// DisableLAN lifecycle methods. The site's containers are
 	// untouched, so AllowsContainerTasks returns true (containers may
@@ -44,6 +51,17 @@ const (
 	PostLanEnable  Event = "post-lan-enable"
 	PreLanDisable  Event = "pre-lan-disable"
 	PostLanDisable Event = "post-lan-disable"
+
+	// Pull from an SSH remote. Both fire with the site running:
+	// pre-pull before the connection opens, post-pull once the
+	// database and uploads have landed and URLs are rewritten.
+	PrePull  Event = "pre-pull"
+	PostPull Event = "post-pull"
+
+	// Push to an SSH remote. pre-push fires before the connection
+	// opens; post-push after the remote import and URL rewrite.
+	PrePush  Event = "pre-push"
+	PostPush Event = "post-push"
 )
 
 // Reserved events — declared but not yet fired by any lifecycle method.
@@ -55,9 +73,6 @@ const (
 
 	PreRestoreSnapshot  Event = "pre-restore-snapshot"
 	PostRestoreSnapshot Event = "post-restore-snapshot"
-
-	PreImportSite  Event = "pre-import-site"
-	PostImportSite Event = "post-import-site"
 )
 
 // allEvents is the canonical registry. Build the lookup map once at package
@@ -78,6 +93,8 @@ var allEvents = []Event{
 	PreImportSite, PostImportSite,
 	PreLanEnable, PostLanEnable,
 	PreLanDisable, PostLanDisable,
+	PrePull, PostPull,
+	PrePush, PostPush,
 }
 
 // activeEvents lists the events that the SiteManager fires today. Used by
@@ -93,8 +110,11 @@ var activeEvents = []Event{
 	PreExport, PostExport,
 	PreImportDB, PostImportDB,
 	PreSnapshot, PostSnapshot,
+	PreImportSite, PostImportSite,
 	PreLanEnable, PostLanEnable,
 	PreLanDisable, PostLanDisable,
+	PrePull, PostPull,
+	PrePush, PostPush,
 }
 
 var eventSet = func() map[Event]struct{} {
@@ -154,8 +174,8 @@ func (e Event) AllowsContainerTasks() bool {
 		PostSnapshot,
 		PreRestoreSnapshot,
 		PostRestoreSnapshot,
-		PreImportSite,
-		PostImportSite:
+		// pre-import-site runs before the imported site's first start.
+		PreImportSite:
 		return false
 	}
 	return true
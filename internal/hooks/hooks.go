@@ -112,7 +112,7 @@ func (h Hook) Validate() error {
 // validService reports whether s is one of the per-site service aliases.
 func validService(s string) bool {
 	switch s {
-	case "php", "web", "database", "redis":
+	case "php", "web", "database", "redis", "valkey", "memcached":
 		return true
 	}
 	return false
@@ -391,7 +391,7 @@ func (r *runner) openRunLog(slug string, ev Event) (*os.File, string, error) {
 		return nil, "", err
 	}
 	// Replace ":" so Windows accepts the filename.
-	ts := strings.ReplaceAll(time.Now().UTC().Format("20060102T150405.000000000Z"), ":", "-")
+	ts := strings.ReplaceAll(time.Now().UTC().Format(runLogTimeLayout), ":", "-")
 	name := string(ev)
 	if name == "" {
 		name = "ad-hoc"
@@ -455,6 +455,68 @@ func truncate(s string, limit int) string {
 	return s[:limit] + "…"
 }
 
+// RunLogsDir is the run-log base directory under homeDir: the
+// LogsBaseDir production hands to NewRunner.
+func RunLogsDir(homeDir string) string {
+	return filepath.Join(homeDir, ".locorum", "hooks", "runs")
+}
+
+// runLogTimeLayout is the timestamp openRunLog puts in a run-log name.
+const runLogTimeLayout = "20060102T150405.000000000Z"
+
+// RunLog is one run-log file written by openRunLog.
+type RunLog struct {
+	Path    string
+	Event   Event // "ad-hoc" for runs with no lifecycle event
+	Started time.Time
+	Size    int64
+}
+
+// ListRunLogs returns slug's run logs under baseDir, newest first. A
+// site that never ran a hook has none, which is not an error. Files
+// not named the way openRunLog names them are skipped.
+func ListRunLogs(baseDir, slug string) ([]RunLog, error) {
+	if baseDir == "" || slug == "" || slug != filepath.Base(slug) {
+		return nil, fmt.Errorf("invalid run-log slug %q", slug)
+	}
+	dir := filepath.Join(baseDir, slug)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []RunLog
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".log")
+		if e.IsDir() || !ok {
+			continue
+		}
+		// Events contain dashes; timestamps do not.
+		i := strings.LastIndexByte(name, '-')
+		if i <= 0 {
+			continue
+		}
+		started, err := time.Parse(runLogTimeLayout, name[i+1:])
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, RunLog{
+			Path:    filepath.Join(dir, e.Name()),
+			Event:   Event(name[:i]),
+			Started: started,
+			Size:    info.Size(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.After(out[j].Started) })
+	return out, nil
+}
+
 // SweepLogs prunes old run-log files. Safe to call from startup. Errors
 // from individual files are logged at warn level and do not abort the
 // sweep.
@@ -40,7 +40,7 @@ func testSite() *types.Site {
 	return &types.Site{
 		ID: "s", Slug: "demo", Name: "Demo", Domain: "demo.localhost",
 		FilesDir: "/tmp/sites/demo", PublicDir: "/",
-		PHPVersion: "8.3", MySQLVersion: "8.0", RedisVersion: "7.4",
+		PHPVersion: "8.3", MySQLVersion: "8.0", CacheVersion: "7.4",
 		DBPassword: "pw", WebServer: "nginx",
 	}
 }
@@ -447,3 +447,42 @@ func TestSweepLogs_CapsPerSiteCount(t *testing.T) {
 		t.Errorf("entries = %d, want 2", len(entries))
 	}
 }
+
+func TestListRunLogs(t *testing.T) {
+	dir := t.TempDir()
+	siteDir := filepath.Join(dir, "demo")
+	if err := os.MkdirAll(siteDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"pre-start-20260501T120000.000000000Z.log",
+		"post-start-20260501T120005.000000000Z.log",
+		"ad-hoc-20260430T090000.000000000Z.log",
+		"notes.txt",
+		"post-start-yesterday.log",
+	} {
+		if err := os.WriteFile(filepath.Join(siteDir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logs, err := hooks.ListRunLogs(dir, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, l := range logs {
+		got = append(got, string(l.Event)+"@"+l.Started.Format("15:04:05"))
+	}
+	want := "post-start@12:00:05 pre-start@12:00:00 ad-hoc@09:00:00"
+	if strings.Join(got, " ") != want {
+		t.Errorf("logs = %v, want %s", got, want)
+	}
+
+	if logs, err := hooks.ListRunLogs(dir, "never-ran"); err != nil || len(logs) != 0 {
+		t.Errorf("site with no runs: %v, %v", logs, err)
+	}
+	if _, err := hooks.ListRunLogs(dir, "../demo"); err == nil {
+		t.Error("slug escaping the base dir accepted")
+	}
+}
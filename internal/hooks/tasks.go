@@ -63,7 +63,7 @@ type HostExecOptions struct {
 // execTask runs a command inside one of the site's containers.
 type execTask struct {
 	containerName string
-	service       string // "php" / "web" / "database" / "redis"
+	service       string // "php" / "web" / "database" / cache service
 	cmd           []string
 	user          string
 	env           []string
@@ -168,6 +168,12 @@ func TestEvent_AllowsContainerTasks(t *testing.T) {
 		{PostDelete, false},
 		{PreClone, true},
 		{PostClone, true},
+		{PreImportSite, false},
+		{PostImportSite, true},
+		{PrePull, true},
+		{PostPull, true},
+		{PrePush, true},
+		{PostPush, true},
 	}
 	for _, tc := range cases {
 		got := tc.ev.AllowsContainerTasks()
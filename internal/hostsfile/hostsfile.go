@@ -0,0 +1,184 @@
+// Package hostsfile maintains a Locorum-owned block in the system hosts
+// file so site aliases outside *.localhost (which every OS already
+// resolves to loopback) reach the router.
+//
+// Only the lines between BeginMarker and EndMarker are ever touched; the
+// rest of the file is preserved byte-for-byte. Writing the file needs
+// elevated rights on every supported OS, so Apply first tries a direct
+// write (Locorum running as root / an elevated Windows shell) and falls
+// back to a one-shot privileged copy through the platform's own prompt:
+// pkexec on Linux, an administrator-privileges AppleScript on macOS and a
+// UAC-elevated PowerShell on Windows.
+package hostsfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/utils"
+)
+
+const (
+	BeginMarker = "# BEGIN locorum — managed by Locorum, do not edit"
+	EndMarker   = "# END locorum"
+)
+
+// Path returns the hosts file location for the running OS.
+func Path() string {
+	if runtime.GOOS == "windows" {
+		root := os.Getenv("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		return filepath.Join(root, "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// Render returns existing with the Locorum block replaced by one mapping
+// each hostname to 127.0.0.1 and ::1. Hostnames are de-duplicated and
+// sorted so the output is stable. An empty list removes the block, and
+// leaves a file without one untouched. The line ending of the existing
+// file (LF or CRLF) is kept.
+func Render(existing []byte, hostnames []string) []byte {
+	eol := "\n"
+	if bytes.Contains(existing, []byte("\r\n")) {
+		eol = "\r\n"
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(existing), "\r\n", "\n"), "\n")
+	var kept []string
+	inBlock, hadBlock := false, false
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "# BEGIN locorum"):
+			inBlock, hadBlock = true, true
+		case inBlock && strings.HasPrefix(line, EndMarker):
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+	if !hadBlock && len(hostnames) == 0 {
+		return existing
+	}
+	// Drop trailing blank lines so repeated renders don't grow the file.
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+
+	hosts := slices.Clone(hostnames)
+	slices.Sort(hosts)
+	hosts = slices.Compact(hosts)
+	if len(hosts) > 0 {
+		if len(kept) > 0 {
+			kept = append(kept, "")
+		}
+		kept = append(kept, BeginMarker)
+		for _, h := range hosts {
+			kept = append(kept, "127.0.0.1 "+h, "::1 "+h)
+		}
+		kept = append(kept, EndMarker)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(kept, eol) + eol)
+}
+
+// Apply rewrites the hosts file at path so the Locorum block lists
+// exactly hostnames. A no-op when the file already matches; otherwise
+// the platform's elevation prompt may be shown.
+func Apply(ctx context.Context, path string, hostnames []string) error {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		if len(hostnames) == 0 && os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read hosts file: %w", err)
+	}
+	next := Render(current, hostnames)
+	if bytes.Equal(current, next) {
+		return nil
+	}
+
+	// Keep the file's mode; hosts must stay world-readable for the
+	// system resolver.
+	if err := os.WriteFile(path, next, 0o644); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("write hosts file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "locorum-hosts-*")
+	if err != nil {
+		return fmt.Errorf("stage hosts file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(next); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("stage hosts file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("stage hosts file: %w", err)
+	}
+	// The elevated copy runs as another user; it must be able to read
+	// the staged file.
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("stage hosts file: %w", err)
+	}
+
+	name, args, err := elevatedCopyCommand(runtime.GOOS, tmp.Name(), path)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	utils.HideConsole(cmd)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("update hosts file (elevated): %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// elevatedCopyCommand returns the command that copies src over dst with
+// administrator rights, prompting the user through the OS's own dialog.
+func elevatedCopyCommand(goos, src, dst string) (string, []string, error) {
+	switch goos {
+	case "linux":
+		return "pkexec", []string{"cp", src, dst}, nil
+	case "darwin":
+		script := fmt.Sprintf("do shell script \"cp %s %s\" with administrator privileges",
+			appleScriptEscape(shellQuote(src)), appleScriptEscape(shellQuote(dst)))
+		return "osascript", []string{"-e", script}, nil
+	case "windows":
+		inner := fmt.Sprintf("Copy-Item -LiteralPath %s -Destination %s -Force", psQuote(src), psQuote(dst))
+		outer := fmt.Sprintf("Start-Process -FilePath powershell -Verb RunAs -Wait -WindowStyle Hidden -ArgumentList '-NoProfile','-Command',%s",
+			psQuote(inner))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", outer}, nil
+	default:
+		return "", nil, fmt.Errorf("updating the hosts file is not supported on %s", goos)
+	}
+}
+
+// shellQuote single-quotes s for /bin/sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// appleScriptEscape escapes s for use inside an AppleScript string literal.
+func appleScriptEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// psQuote single-quotes s for PowerShell.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
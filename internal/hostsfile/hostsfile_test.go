@@ -0,0 +1,91 @@
+package hostsfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	base := "127.0.0.1 localhost\n::1 localhost\n"
+
+	got := string(Render([]byte(base), []string{"shop.test", "blog.test", "shop.test"}))
+	want := base + "\n" + BeginMarker + "\n" +
+		"127.0.0.1 blog.test\n::1 blog.test\n" +
+		"127.0.0.1 shop.test\n::1 shop.test\n" +
+		EndMarker + "\n"
+	if got != want {
+		t.Fatalf("insert:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Re-rendering is stable and replaces the block in place.
+	if again := string(Render([]byte(got), []string{"blog.test", "shop.test"})); again != got {
+		t.Errorf("render not idempotent:\n%s", again)
+	}
+	replaced := string(Render([]byte(got), []string{"new.test"}))
+	if strings.Contains(replaced, "shop.test") || !strings.Contains(replaced, "127.0.0.1 new.test") {
+		t.Errorf("replace:\n%s", replaced)
+	}
+
+	// An empty list removes the block and leaves the rest untouched.
+	if removed := string(Render([]byte(got), nil)); removed != base {
+		t.Errorf("remove:\n%q\nwant %q", removed, base)
+	}
+}
+
+func TestRenderKeepsCRLFAndSurroundingLines(t *testing.T) {
+	existing := "127.0.0.1 localhost\r\n" + BeginMarker + "\r\n127.0.0.1 old.test\r\n" + EndMarker + "\r\n10.0.0.5 nas\r\n"
+	got := string(Render([]byte(existing), []string{"a.test"}))
+	if !strings.Contains(got, "10.0.0.5 nas\r\n") {
+		t.Errorf("lines after the block were lost:\n%q", got)
+	}
+	if strings.Contains(got, "old.test") {
+		t.Errorf("stale entry kept:\n%q", got)
+	}
+	if strings.Count(got, "\n") != strings.Count(got, "\r\n") {
+		t.Errorf("mixed line endings:\n%q", got)
+	}
+}
+
+func TestApplyWritable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(context.Background(), path, []string{"shop.test"}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "127.0.0.1 shop.test") {
+		t.Errorf("hosts = %q", data)
+	}
+}
+
+func TestElevatedCopyCommand(t *testing.T) {
+	name, args, err := elevatedCopyCommand("linux", "/tmp/x", "/etc/hosts")
+	if err != nil || name != "pkexec" || strings.Join(args, " ") != "cp /tmp/x /etc/hosts" {
+		t.Errorf("linux = %s %v, %v", name, args, err)
+	}
+
+	name, args, err = elevatedCopyCommand("darwin", "/tmp/it's", "/etc/hosts")
+	if err != nil || name != "osascript" {
+		t.Fatalf("darwin = %s, %v", name, err)
+	}
+	if want := `do shell script "cp '/tmp/it'\\''s' '/etc/hosts'" with administrator privileges`; args[1] != want {
+		t.Errorf("darwin script = %s\nwant %s", args[1], want)
+	}
+
+	name, args, err = elevatedCopyCommand("windows", `C:\Temp\h`, `C:\Windows\System32\drivers\etc\hosts`)
+	if err != nil || name != "powershell" {
+		t.Fatalf("windows = %s, %v", name, err)
+	}
+	if cmd := args[len(args)-1]; !strings.Contains(cmd, "-Verb RunAs") || !strings.Contains(cmd, "Copy-Item") {
+		t.Errorf("windows command = %s", cmd)
+	}
+
+	if _, _, err := elevatedCopyCommand("plan9", "a", "b"); err == nil {
+		t.Error("unsupported OS: want error")
+	}
+}
@@ -0,0 +1,151 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/sites"
+)
+
+const (
+	sshdImage    = "lscr.io/linuxserver/openssh-server:latest"
+	sshdUser     = "deploy"
+	sshdPassword = "pull-test-password"
+)
+
+// startSSHD runs a throwaway OpenSSH container with srcDir mounted at
+// /srv/wp and returns the published host port once sshd accepts logins.
+func startSSHD(t *testing.T, h *harness, srcDir string) int {
+	t.Helper()
+
+	pullCtx := timeoutCtx(t, h.ctx, 5*time.Minute)
+	if err := h.docker.PullImage(pullCtx, sshdImage, nil); err != nil {
+		t.Fatalf("pull %s: %v", sshdImage, err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+
+	name := "locorum-it-sshd-" + uniqueSlug("sshd")
+	spec := docker.ContainerSpec{
+		Name:  name,
+		Image: sshdImage,
+		Env: []string{
+			"PUID=" + strconv.Itoa(os.Getuid()),
+			"PGID=" + strconv.Itoa(os.Getgid()),
+			"USER_NAME=" + sshdUser,
+			"USER_PASSWORD=" + sshdPassword,
+			"PASSWORD_ACCESS=true",
+		},
+		Ports:  []docker.PortMap{{HostIP: "127.0.0.1", HostPort: strconv.Itoa(port), ContainerPort: "2222"}},
+		Mounts: []docker.Mount{{Bind: &docker.BindMount{Source: srcDir, Target: "/srv/wp", ReadOnly: true}}},
+	}
+	if _, err := h.docker.EnsureContainer(h.ctx, spec); err != nil {
+		t.Fatalf("create sshd: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = h.docker.RemoveContainer(ctx, name)
+	})
+	if err := h.docker.StartContainer(h.ctx, name); err != nil {
+		t.Fatalf("start sshd: %v", err)
+	}
+
+	probe := remote.Remote{
+		SiteID: "probe", Name: "probe", Host: "127.0.0.1", Port: port,
+		User: sshdUser, Path: "/srv/wp", Password: sshdPassword, KeyPath: "/nonexistent",
+	}
+	deadline := time.Now().Add(90 * time.Second)
+	for {
+		c, err := remote.Dial(h.ctx, probe, remote.DialOptions{KnownHostsPath: "-", Timeout: 5 * time.Second})
+		if err == nil {
+			_ = c.Close()
+			return port
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sshd never accepted a login: %v", err)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+func TestPull_FromSSHRemote(t *testing.T) {
+	t.Parallel()
+	h := newHarness(t)
+
+	src := t.TempDir()
+	const dump = `-- MySQL dump
+DROP TABLE IF EXISTS wp_pull_check;
+CREATE TABLE wp_pull_check (id int) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+INSERT INTO wp_pull_check VALUES (7);
+`
+	if err := os.WriteFile(filepath.Join(src, "dump.sql"), []byte(dump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	upload := filepath.Join(src, "wp-content", "uploads", "2026", "05", "pulled.txt")
+	if err := os.MkdirAll(filepath.Dir(upload), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(upload, []byte("from staging"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	port := startSSHD(t, h, src)
+	id := mustCreateAndStart(t, h, "pull")
+
+	if err := h.sites.AddRemote(&remote.Remote{
+		SiteID: id, Name: "staging", Host: "127.0.0.1", Port: port,
+		User: sshdUser, Path: "/srv/wp", Password: sshdPassword, KeyPath: "/nonexistent",
+		// The sshd image has no wp-cli; the override is what lets the
+		// test run without a second WordPress stack.
+		DBCommand: "cat /srv/wp/dump.sql",
+	}); err != nil {
+		t.Fatalf("AddRemote: %v", err)
+	}
+
+	pullCtx := timeoutCtx(t, h.ctx, 3*time.Minute)
+	res, err := h.sites.Pull(pullCtx, id, sites.PullOptions{Remote: "staging", SkipSnapshot: true})
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if !res.Database || res.UploadFiles != 1 || !res.HostKeyPinned {
+		t.Errorf("PullResult = %+v", res)
+	}
+
+	got, err := h.sites.ExecWPCLI(h.ctx, id, []string{"db", "query", "SELECT id FROM wp_pull_check WHERE id = 7"})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !strings.Contains(got, "7") {
+		t.Errorf("expected pulled row 7; got %q", got)
+	}
+
+	site, _ := h.storage.GetSite(id)
+	body, err := os.ReadFile(filepath.Join(site.FilesDir, "wp-content", "uploads", "2026", "05", "pulled.txt"))
+	if err != nil || string(body) != "from staging" {
+		t.Errorf("pulled upload = %q, %v", body, err)
+	}
+
+	r, _ := h.storage.GetRemote(id, "staging")
+	if r == nil || r.HostKey != res.HostKey {
+		t.Errorf("host key not pinned: %+v", r)
+	}
+
+	stop := timeoutCtx(t, h.ctx, 60*time.Second)
+	_ = h.sites.StopSite(stop, id)
+}
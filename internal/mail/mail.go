@@ -0,0 +1,129 @@
+// Package mail reads the mail captured by Locorum's global mail
+// container. Every site's PHP container delivers to it instead of the
+// internet; this package lets the daemon, CLI and MCP list, read,
+// search and purge what was caught, so a test script can assert that a
+// password-reset email was actually sent.
+//
+// Client is deliberately small so the capture server stays swappable;
+// Mailpit is the one implementation today.
+package mail
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotFound reports a message id the capture server does not hold.
+var ErrNotFound = errors.New("message not found")
+
+// Client is the capture server's API as Locorum uses it.
+type Client interface {
+	// List returns the newest messages matching q, newest first.
+	List(ctx context.Context, q Query) (*Page, error)
+	// Get returns one message with its bodies.
+	Get(ctx context.Context, id string) (*Message, error)
+	// Delete removes every message matching q. A zero Query empties
+	// the mailbox.
+	Delete(ctx context.Context, q Query) error
+	// Raw returns the message exactly as it was received, headers and
+	// all, for forwarding.
+	Raw(ctx context.Context, id string) ([]byte, error)
+	// SetTags replaces the message's tags.
+	SetTags(ctx context.Context, id string, tags []string) error
+}
+
+// Query selects messages. The zero value matches everything.
+type Query struct {
+	// Tag restricts the result to messages carrying the tag. Sites
+	// tag their outgoing mail; see sites.MailTag.
+	Tag string
+	// Search is free text in the capture server's search syntax
+	// (Mailpit: `subject:"Password Reset" to:admin@example.com`).
+	Search string
+	// Start skips that many matches, for paging.
+	Start int
+	// Limit caps the page; <= 0 uses the server default.
+	Limit int
+}
+
+// IsZero reports whether q matches every message.
+func (q Query) IsZero() bool {
+	return q.Tag == "" && strings.TrimSpace(q.Search) == ""
+}
+
+// Address is one mailbox from a message header.
+type Address struct {
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address"`
+}
+
+// String renders the address as "Name <addr>", or the bare address
+// when there is no display name.
+func (a Address) String() string {
+	if a.Name == "" {
+		return a.Address
+	}
+	return a.Name + " <" + a.Address + ">"
+}
+
+// Summary is a message as it appears in a list.
+type Summary struct {
+	ID        string    `json:"id"`
+	MessageID string    `json:"messageId,omitempty"`
+	From      Address   `json:"from"`
+	To        []Address `json:"to"`
+	Subject   string    `json:"subject"`
+	Created   time.Time `json:"created"`
+	Snippet   string    `json:"snippet,omitempty"`
+	Size      int       `json:"size"`
+	Read      bool      `json:"read"`
+	Tags      []string  `json:"tags,omitempty"`
+	// Site is the slug of the sending site, when its tag identifies
+	// one. The capture server knows nothing of sites; callers fill it.
+	Site string `json:"site,omitempty"`
+}
+
+// Attachment describes one attached file. The content itself stays on
+// the capture server.
+type Attachment struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int    `json:"size"`
+}
+
+// Message is a full message: the summary fields plus recipients and
+// bodies.
+type Message struct {
+	Summary
+	Cc          []Address    `json:"cc,omitempty"`
+	Bcc         []Address    `json:"bcc,omitempty"`
+	ReplyTo     []Address    `json:"replyTo,omitempty"`
+	Text        string       `json:"text"`
+	HTML        string       `json:"html,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Page is one page of a List.
+type Page struct {
+	Messages []Summary `json:"messages"`
+	// Total is the number of matching messages, not just this page.
+	Total int `json:"total"`
+	Start int `json:"start"`
+}
+
+// Outbound mail modes a site can run in (Site.MailMode). The empty
+// string is ModeCapture.
+const (
+	// ModeCapture keeps every message in the capture server.
+	ModeCapture = "capture"
+	// ModeRelay captures every message and also delivers those
+	// addressed to an allowed domain through the site's smarthost.
+	ModeRelay = "relay"
+	// ModeBlackhole discards mail before it leaves PHP.
+	ModeBlackhole = "blackhole"
+)
+
+// Modes lists the selectable modes in display order.
+var Modes = []string{ModeCapture, ModeRelay, ModeBlackhole}
@@ -0,0 +1,267 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mailpit is a Client over Mailpit's REST API (/api/v1).
+type Mailpit struct {
+	baseURL string
+	http    *http.Client
+}
+
+var _ Client = (*Mailpit)(nil)
+
+// NewMailpit returns a client for the Mailpit instance at baseURL
+// (e.g. "http://127.0.0.1:49153").
+func NewMailpit(baseURL string) *Mailpit {
+	return &Mailpit{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// mailpitAddress, mailpitSummary and mailpitMessage mirror the API's
+// PascalCase JSON.
+type mailpitAddress struct {
+	Name    string `json:"Name"`
+	Address string `json:"Address"`
+}
+
+type mailpitSummary struct {
+	ID        string           `json:"ID"`
+	MessageID string           `json:"MessageID"`
+	Read      bool             `json:"Read"`
+	From      *mailpitAddress  `json:"From"`
+	To        []mailpitAddress `json:"To"`
+	Subject   string           `json:"Subject"`
+	Created   time.Time        `json:"Created"`
+	Tags      []string         `json:"Tags"`
+	Size      int              `json:"Size"`
+	Snippet   string           `json:"Snippet"`
+}
+
+type mailpitMessage struct {
+	ID          string           `json:"ID"`
+	MessageID   string           `json:"MessageID"`
+	From        *mailpitAddress  `json:"From"`
+	To          []mailpitAddress `json:"To"`
+	Cc          []mailpitAddress `json:"Cc"`
+	Bcc         []mailpitAddress `json:"Bcc"`
+	ReplyTo     []mailpitAddress `json:"ReplyTo"`
+	Subject     string           `json:"Subject"`
+	Date        time.Time        `json:"Date"`
+	Tags        []string         `json:"Tags"`
+	Text        string           `json:"Text"`
+	HTML        string           `json:"HTML"`
+	Size        int              `json:"Size"`
+	Attachments []struct {
+		FileName    string `json:"FileName"`
+		ContentType string `json:"ContentType"`
+		Size        int    `json:"Size"`
+	} `json:"Attachments"`
+}
+
+type mailpitList struct {
+	// MessagesCount is the number of matches; Total counts the whole
+	// mailbox even for a search.
+	MessagesCount int              `json:"messages_count"`
+	Start         int              `json:"start"`
+	Messages      []mailpitSummary `json:"messages"`
+}
+
+// List implements Client. A filtered query goes through /search, the
+// plain mailbox through /messages.
+func (m *Mailpit) List(ctx context.Context, q Query) (*Page, error) {
+	params := url.Values{}
+	if q.Start > 0 {
+		params.Set("start", strconv.Itoa(q.Start))
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	path := "/api/v1/messages"
+	if !q.IsZero() {
+		path = "/api/v1/search"
+		params.Set("query", mailpitQuery(q))
+	}
+	var list mailpitList
+	if err := m.do(ctx, http.MethodGet, path, params, &list); err != nil {
+		return nil, err
+	}
+	page := &Page{Messages: make([]Summary, 0, len(list.Messages)), Total: list.MessagesCount, Start: list.Start}
+	for _, s := range list.Messages {
+		page.Messages = append(page.Messages, Summary{
+			ID:        s.ID,
+			MessageID: s.MessageID,
+			From:      s.From.address(),
+			To:        addresses(s.To),
+			Subject:   s.Subject,
+			Created:   s.Created,
+			Snippet:   s.Snippet,
+			Size:      s.Size,
+			Read:      s.Read,
+			Tags:      s.Tags,
+		})
+	}
+	return page, nil
+}
+
+// Get implements Client. Mailpit marks the message read as a side
+// effect, as its web UI would.
+func (m *Mailpit) Get(ctx context.Context, id string) (*Message, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+	var msg mailpitMessage
+	if err := m.do(ctx, http.MethodGet, "/api/v1/message/"+url.PathEscape(id), nil, &msg); err != nil {
+		return nil, err
+	}
+	out := &Message{
+		Summary: Summary{
+			ID:        msg.ID,
+			MessageID: msg.MessageID,
+			From:      msg.From.address(),
+			To:        addresses(msg.To),
+			Subject:   msg.Subject,
+			Created:   msg.Date,
+			Size:      msg.Size,
+			Read:      true,
+			Tags:      msg.Tags,
+		},
+		Cc:      addresses(msg.Cc),
+		Bcc:     addresses(msg.Bcc),
+		ReplyTo: addresses(msg.ReplyTo),
+		Text:    msg.Text,
+		HTML:    msg.HTML,
+	}
+	for _, a := range msg.Attachments {
+		out.Attachments = append(out.Attachments, Attachment{FileName: a.FileName, ContentType: a.ContentType, Size: a.Size})
+	}
+	return out, nil
+}
+
+// Delete implements Client.
+func (m *Mailpit) Delete(ctx context.Context, q Query) error {
+	if q.IsZero() {
+		return m.do(ctx, http.MethodDelete, "/api/v1/messages", nil, nil)
+	}
+	return m.do(ctx, http.MethodDelete, "/api/v1/search", url.Values{"query": {mailpitQuery(q)}}, nil)
+}
+
+// Raw implements Client.
+func (m *Mailpit) Raw(ctx context.Context, id string) ([]byte, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+	resp, err := m.send(ctx, http.MethodGet, "/api/v1/message/"+url.PathEscape(id)+"/raw", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("mailpit api raw: %w", err)
+	}
+	return raw, nil
+}
+
+// SetTags implements Client. Mailpit's tag endpoint overwrites the
+// whole set, so callers pass the existing tags along with new ones.
+func (m *Mailpit) SetTags(ctx context.Context, id string, tags []string) error {
+	body, err := json.Marshal(struct {
+		IDs  []string `json:"IDs"`
+		Tags []string `json:"Tags"`
+	}{IDs: []string{id}, Tags: tags})
+	if err != nil {
+		return err
+	}
+	resp, err := m.send(ctx, http.MethodPut, "/api/v1/tags", nil, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// mailpitQuery folds the tag filter into Mailpit's search syntax.
+// Slug-derived tags never need quoting.
+func mailpitQuery(q Query) string {
+	search := strings.TrimSpace(q.Search)
+	if q.Tag == "" {
+		return search
+	}
+	return strings.TrimSpace("tag:" + q.Tag + " " + search)
+}
+
+func (m *Mailpit) do(ctx context.Context, method, path string, params url.Values, dest any) error {
+	resp, err := m.send(ctx, method, path, params, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if dest == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("mailpit api %s: %w", path, err)
+	}
+	return nil
+}
+
+// send issues one request and returns the response once its status is
+// known to be 200; the caller closes the body.
+func (m *Mailpit) send(ctx context.Context, method, path string, params url.Values, body []byte) (*http.Response, error) {
+	u := m.baseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	var rd io.Reader = http.NoBody
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("mailpit api: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound && strings.HasPrefix(path, "/api/v1/message/"):
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("mailpit api %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (a *mailpitAddress) address() Address {
+	if a == nil {
+		return Address{}
+	}
+	return Address{Name: a.Name, Address: a.Address}
+}
+
+func addresses(in []mailpitAddress) []Address {
+	out := make([]Address, 0, len(in))
+	for i := range in {
+		out = append(out, in[i].address())
+	}
+	return out
+}
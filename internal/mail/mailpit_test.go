@@ -0,0 +1,138 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeMailpit answers the handful of endpoints the client uses with
+// canned Mailpit-shaped JSON and records the last request.
+func fakeMailpit(t *testing.T) (*Mailpit, *http.Request) {
+	t.Helper()
+	last := &http.Request{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = *r
+		switch {
+		case r.Method == http.MethodGet && (r.URL.Path == "/api/v1/messages" || r.URL.Path == "/api/v1/search"):
+			_, _ = w.Write([]byte(`{"total":7,"messages_count":1,"start":0,"messages":[{
+				"ID":"abc","MessageID":"m1@shop.localhost","Read":false,
+				"From":{"Name":"Shop","Address":"wordpress@shop.localhost"},
+				"To":[{"Name":"","Address":"admin@example.com"}],
+				"Subject":"[Shop] Password Reset","Created":"2026-05-01T10:00:00Z",
+				"Tags":["site-shop"],"Size":1024,"Snippet":"Someone has requested a password reset"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/message/abc":
+			_, _ = w.Write([]byte(`{"ID":"abc","From":{"Name":"Shop","Address":"wordpress@shop.localhost"},
+				"To":[{"Address":"admin@example.com"}],"Cc":[],"Subject":"[Shop] Password Reset",
+				"Date":"2026-05-01T10:00:00Z","Tags":["site-shop"],"Text":"reset link","HTML":"",
+				"Attachments":[{"FileName":"a.txt","ContentType":"text/plain","Size":3}]}`))
+		case r.Method == http.MethodDelete:
+			_, _ = w.Write([]byte("ok"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return NewMailpit(srv.URL + "/"), last
+}
+
+func TestMailpitList(t *testing.T) {
+	m, last := fakeMailpit(t)
+	ctx := context.Background()
+
+	page, err := m.List(ctx, Query{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if last.URL.Path != "/api/v1/messages" {
+		t.Errorf("unfiltered list hit %s, want /api/v1/messages", last.URL.Path)
+	}
+	if page.Total != 1 || len(page.Messages) != 1 {
+		t.Fatalf("page = %+v", page)
+	}
+	got := page.Messages[0]
+	if got.ID != "abc" || got.From.String() != "Shop <wordpress@shop.localhost>" || got.To[0].String() != "admin@example.com" {
+		t.Errorf("summary = %+v", got)
+	}
+	if got.Created.IsZero() || got.Tags[0] != "site-shop" {
+		t.Errorf("created/tags not decoded: %+v", got)
+	}
+
+	if _, err := m.List(ctx, Query{Tag: "site-shop", Search: ` subject:"Password Reset" `, Limit: 5}); err != nil {
+		t.Fatalf("List filtered: %v", err)
+	}
+	if last.URL.Path != "/api/v1/search" {
+		t.Errorf("filtered list hit %s, want /api/v1/search", last.URL.Path)
+	}
+	if q := last.URL.Query(); q.Get("query") != `tag:site-shop subject:"Password Reset"` || q.Get("limit") != "5" {
+		t.Errorf("query params = %v", q)
+	}
+}
+
+func TestMailpitGet(t *testing.T) {
+	m, _ := fakeMailpit(t)
+	msg, err := m.Get(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if msg.Text != "reset link" || msg.Subject != "[Shop] Password Reset" || len(msg.Attachments) != 1 {
+		t.Errorf("message = %+v", msg)
+	}
+	if _, err := m.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown id: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMailpitDelete(t *testing.T) {
+	m, last := fakeMailpit(t)
+	ctx := context.Background()
+
+	if err := m.Delete(ctx, Query{}); err != nil {
+		t.Fatalf("Delete all: %v", err)
+	}
+	if last.Method != http.MethodDelete || last.URL.Path != "/api/v1/messages" {
+		t.Errorf("delete all hit %s %s", last.Method, last.URL.Path)
+	}
+	if err := m.Delete(ctx, Query{Tag: "site-shop"}); err != nil {
+		t.Fatalf("Delete by tag: %v", err)
+	}
+	if last.URL.Path != "/api/v1/search" || last.URL.Query().Get("query") != "tag:site-shop" {
+		t.Errorf("delete by tag hit %s?%s", last.URL.Path, last.URL.RawQuery)
+	}
+}
+
+func TestMailpitRawAndTags(t *testing.T) {
+	var tagBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/message/abc/raw":
+			_, _ = w.Write([]byte("Subject: hi\r\n\r\nbody\r\n"))
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v1/tags":
+			b, _ := io.ReadAll(r.Body)
+			tagBody = string(b)
+			_, _ = w.Write([]byte("ok"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	m := NewMailpit(srv.URL)
+	ctx := context.Background()
+
+	raw, err := m.Raw(ctx, "abc")
+	if err != nil || string(raw) != "Subject: hi\r\n\r\nbody\r\n" {
+		t.Errorf("Raw = %q, %v", raw, err)
+	}
+	if _, err := m.Raw(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Raw unknown id: err = %v, want ErrNotFound", err)
+	}
+	if err := m.SetTags(ctx, "abc", []string{"site-shop", "relayed"}); err != nil {
+		t.Fatalf("SetTags: %v", err)
+	}
+	if tagBody != `{"IDs":["abc"],"Tags":["site-shop","relayed"]}` {
+		t.Errorf("tag body = %s", tagBody)
+	}
+}
@@ -12,6 +12,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/PeterBooker/locorum/internal/captoken"
+	"github.com/PeterBooker/locorum/internal/daemon"
 )
 
 // HTTP MCP transport. MCP defines a "Streamable HTTP" transport that
@@ -26,16 +29,22 @@ import (
 // without an explicit --bind flag, and requires a per-process bearer
 // token (token.go). Filesystem perms on the token file (0600) limit
 // the attack surface to other processes running as the same user.
+//
+// A client may instead present a named capability token (lct_…). Each
+// such request gets its own daemon connection, opened with that token,
+// so the daemon's dispatcher applies the token's grants and records
+// the call under its label; the shared token's connection is not used.
 
 // HTTPServer is the HTTP frontend for an existing daemon client. One
 // goroutine handles the listen + accept loop; per-request dispatch is
 // stateless so the http.Handler is goroutine-safe by construction.
 type HTTPServer struct {
-	addr   string
-	token  string
-	mux    *http.ServeMux
-	core   *Server // wraps the same dispatch logic as stdio mode
-	logger *slog.Logger
+	addr      string
+	token     string
+	dialToken func(ctx context.Context, secret string) (*daemon.Client, error)
+	mux       *http.ServeMux
+	core      *Server // wraps the same dispatch logic as stdio mode
+	logger    *slog.Logger
 
 	mu       sync.Mutex
 	listener net.Listener
@@ -45,12 +54,15 @@ type HTTPServer struct {
 
 // HTTPOptions configures the HTTP server. Bind is the listen address
 // (e.g. "127.0.0.1:2484"); Token is the bearer secret clients send in
-// `Authorization: Bearer <token>`.
+// `Authorization: Bearer <token>`. DialToken, when set, opens a daemon
+// connection bound to a capability token; without it only Token is
+// accepted.
 type HTTPOptions struct {
-	Bind   string
-	Token  string
-	Server *Server // typically constructed via NewServer with stdio I/O wired to nil
-	Logger *slog.Logger
+	Bind      string
+	Token     string
+	DialToken func(ctx context.Context, secret string) (*daemon.Client, error)
+	Server    *Server // typically constructed via NewServer with stdio I/O wired to nil
+	Logger    *slog.Logger
 }
 
 // NewHTTPServer wires an HTTP listener around the existing dispatch
@@ -76,11 +88,12 @@ func NewHTTPServer(opts HTTPOptions) (*HTTPServer, error) {
 		logger = slog.Default()
 	}
 	h := &HTTPServer{
-		addr:   opts.Bind,
-		token:  opts.Token,
-		mux:    http.NewServeMux(),
-		core:   opts.Server,
-		logger: logger.With("subsys", "mcp.http"),
+		addr:      opts.Bind,
+		token:     opts.Token,
+		dialToken: opts.DialToken,
+		mux:       http.NewServeMux(),
+		core:      opts.Server,
+		logger:    logger.With("subsys", "mcp.http"),
 	}
 	h.mux.HandleFunc("/mcp", h.handleMCP)
 	h.mux.HandleFunc("/healthz", h.handleHealth)
@@ -178,12 +191,26 @@ func (h *HTTPServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "POST required", http.StatusMethodNotAllowed)
 		return
 	}
+	var tokenClient *daemon.Client
 	if !h.checkAuth(r) {
-		// 401 with a generic message — no hint about why so a probing
-		// attacker can't tell whether the token was wrong or absent.
-		w.Header().Set("WWW-Authenticate", `Bearer realm="locorum-mcp"`)
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
-		return
+		secret, _ := bearer(r)
+		if h.dialToken == nil || !captoken.LooksLikeSecret(secret) {
+			unauthorized(w)
+			return
+		}
+		cli, err := h.dialToken(r.Context(), secret)
+		if err != nil {
+			var rpcErr *daemon.RPCError
+			if errors.As(err, &rpcErr) && rpcErr.Code == daemon.CodeForbidden {
+				unauthorized(w)
+				return
+			}
+			h.logger.Warn("daemon dial failed", "err", err.Error())
+			http.Error(w, "daemon unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		defer func() { _ = cli.Close() }()
+		tokenClient = cli
 	}
 
 	// 1 MiB body cap: tool calls don't exceed a few KB in practice. The
@@ -211,6 +238,9 @@ func (h *HTTPServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 		out bytes.Buffer
 	)
 	once := h.core.cloneForOneShot(in, &out)
+	if tokenClient != nil {
+		once.client = tokenClient
+	}
 	if err := once.Serve(r.Context()); err != nil {
 		h.logger.Warn("dispatch failed", "err", err.Error())
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -238,19 +268,33 @@ func (h *HTTPServer) handleHealth(w http.ResponseWriter, _ *http.Request) {
 	_, _ = io.WriteString(w, `{"status":"ok","name":"locorum"}`)
 }
 
-// checkAuth pulls the Authorization header and constant-time-compares
-// against the configured token. Header parsing is intentionally
-// strict — we accept only "Bearer <token>" with no whitespace
-// tolerance beyond a single space, so a malformed header is a hint
-// the client is misconfigured rather than an attempt to bypass us.
+// unauthorized writes a 401 with a generic message — no hint about why
+// so a probing attacker can't tell whether the token was wrong or
+// absent.
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="locorum-mcp"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// checkAuth constant-time-compares the request's bearer against the
+// configured shared token.
 func (h *HTTPServer) checkAuth(r *http.Request) bool {
+	got, ok := bearer(r)
+	return ok && CompareTokens(got, h.token)
+}
+
+// bearer pulls the token out of the Authorization header. Header
+// parsing is intentionally strict — we accept only "Bearer <token>"
+// with no whitespace tolerance beyond a single space, so a malformed
+// header is a hint the client is misconfigured rather than an attempt
+// to bypass us.
+func bearer(r *http.Request) (string, bool) {
 	auth := r.Header.Get("Authorization")
 	const prefix = "Bearer "
 	if !strings.HasPrefix(auth, prefix) {
-		return false
+		return "", false
 	}
-	got := auth[len(prefix):]
-	return CompareTokens(got, h.token)
+	return auth[len(prefix):], true
 }
 
 // validateBind enforces the localhost-default rule. We only allow:
@@ -308,6 +352,7 @@ func (s *Server) cloneForOneShot(in io.Reader, out io.Writer) *Server {
 		scope:   s.scope,
 		profile: s.profile,
 		version: s.version,
+		oneShot: true,
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -200,3 +201,70 @@ func TestHTTP_TokenFile_RoundTrip(t *testing.T) {
 		t.Fatalf("rotate didn't change token")
 	}
 }
+
+func TestHTTP_CapabilityToken(t *testing.T) {
+	tokenCli := startStubDaemon(t, map[string]daemon.Handler{
+		"site.list": reply([]map[string]any{{"slug": "from-token-conn"}}),
+	})
+	var (
+		mu     sync.Mutex
+		dialed []string
+	)
+	core := NewServer(Options{In: strings.NewReader(""), Out: io.Discard, Profile: daemon.ProfileFull, Version: "test"})
+	srv, err := NewHTTPServer(HTTPOptions{
+		Bind:   "127.0.0.1:0",
+		Token:  "secret-token-123",
+		Server: core,
+		DialToken: func(_ context.Context, secret string) (*daemon.Client, error) {
+			mu.Lock()
+			dialed = append(dialed, secret)
+			mu.Unlock()
+			if secret != "lct_good" {
+				return nil, &daemon.RPCError{Code: daemon.CodeForbidden, Message: "hello: invalid or expired token"}
+			}
+			return tokenCli, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPServer: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = srv.Serve(ctx) }()
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+	var addr string
+	for i := 0; i < 50 && (addr == "" || strings.HasSuffix(addr, ":0")); i++ {
+		time.Sleep(10 * time.Millisecond)
+		addr = srv.Addr()
+	}
+
+	post := func(token string) *http.Response {
+		t.Helper()
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_sites","arguments":{}}}`
+		req, _ := http.NewRequest("POST", "http://"+addr+"/mcp", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	if resp := post("lct_revoked"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("refused token: status %d, want 401", resp.StatusCode)
+	}
+	if resp := post("not-a-capability-token"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong shared token: status %d, want 401", resp.StatusCode)
+	}
+	resp := post("lct_good")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || !bytes.Contains(body, []byte("from-token-conn")) {
+		t.Errorf("token call: status %d body %s", resp.StatusCode, body)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) != 2 || dialed[0] != "lct_revoked" || dialed[1] != "lct_good" {
+		t.Errorf("dialed = %v", dialed)
+	}
+}
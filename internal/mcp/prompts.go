@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/PeterBooker/locorum/internal/daemon"
+)
+
+// promptDef pairs a prompts/list descriptor with the function that
+// renders it. requireFull hides prompts whose workflow needs mutating
+// tools from the readonly profile.
+type promptDef struct {
+	descriptor  promptDescriptor
+	render      func(ctx context.Context, s *Server, args map[string]string) (promptGetResult, error)
+	requireFull bool
+}
+
+// errPromptArgs marks a prompts/get failure caused by the caller's
+// arguments rather than the daemon.
+var errPromptArgs = errors.New("invalid prompt arguments")
+
+// argSlug is shared by prompts about one site. Optional under an MCP
+// scope, which stands in for it.
+var argSlug = promptArgument{
+	Name:        "slug",
+	Description: "Site slug. Defaults to the MCP scope when one is set.",
+}
+
+// allPrompts is the prompt catalogue, in listing order.
+var allPrompts = []promptDef{
+	{
+		descriptor: promptDescriptor{
+			Name:        "diagnose_start_failure",
+			Title:       "Diagnose a failing start",
+			Description: "Work out why a site failed to start, with its description, recent activity, hook runs and container log tails attached.",
+			Arguments:   []promptArgument{argSlug},
+		},
+		render: renderDiagnoseStart,
+	},
+	{
+		descriptor: promptDescriptor{
+			Name:        "prepare_worktree_pr",
+			Title:       "Prepare a worktree for a PR",
+			Description: "Create and start a worktree-bound site for reviewing a branch, alongside an existing parent site. The parent's description and config.yaml are attached.",
+			Arguments: []promptArgument{
+				{Name: "branch", Description: "Branch the pull request proposes.", Required: true},
+				{Name: "parentSlug", Description: "Existing site the worktree hangs off. Defaults to the MCP scope when one is set."},
+				{Name: "gitRemote", Description: "Upstream git URL. Looked up from the parent's files directory when omitted."},
+			},
+		},
+		render:      renderPrepareWorktree,
+		requireFull: true,
+	},
+}
+
+// promptList returns the descriptors visible in the current profile.
+func (s *Server) promptList() []promptDescriptor {
+	out := make([]promptDescriptor, 0, len(allPrompts))
+	for _, p := range allPrompts {
+		if p.requireFull && s.profile == daemon.ProfileReadOnly {
+			continue
+		}
+		out = append(out, p.descriptor)
+	}
+	return out
+}
+
+func (s *Server) handlePromptGet(ctx context.Context, req request) error {
+	var p struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments,omitempty"`
+	}
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		s.writeError(req.ID, codeInvalidParams, "invalid prompts/get params: "+err.Error())
+		return nil
+	}
+	for _, def := range allPrompts {
+		if def.descriptor.Name != p.Name || (def.requireFull && s.profile == daemon.ProfileReadOnly) {
+			continue
+		}
+		for _, a := range def.descriptor.Arguments {
+			if a.Required && p.Arguments[a.Name] == "" {
+				s.writeError(req.ID, codeInvalidParams, "missing required argument: "+a.Name)
+				return nil
+			}
+		}
+		out, err := def.render(ctx, s, p.Arguments)
+		switch {
+		case errors.Is(err, errPromptArgs):
+			s.writeError(req.ID, codeInvalidParams, err.Error())
+		case err != nil:
+			s.writeError(req.ID, resourceErrCode(err), mapDaemonErr(err).Error())
+		default:
+			s.writeResult(req.ID, out)
+		}
+		return nil
+	}
+	s.writeError(req.ID, codeInvalidParams, "unknown prompt: "+p.Name)
+	return nil
+}
+
+// promptSite resolves a prompt's site argument (or the scope) and
+// returns the site's slug and its description resource. Reading the
+// description first turns a bad slug into a prompts/get error rather
+// than a prompt full of failed resources.
+func (s *Server) promptSite(ctx context.Context, ref string) (string, resourceContents, error) {
+	params := siteRefMap(s, "", ref)
+	if len(params) == 0 {
+		return "", resourceContents{}, fmt.Errorf("%w: a site slug is required without an MCP scope", errPromptArgs)
+	}
+	var desc json.RawMessage
+	if err := s.callDaemon(ctx, "site.describe", params, &desc); err != nil {
+		return "", resourceContents{}, err
+	}
+	var head struct {
+		Slug string `json:"slug"`
+	}
+	if err := json.Unmarshal(desc, &head); err != nil {
+		return "", resourceContents{}, fmt.Errorf("decode site description: %w", err)
+	}
+	return head.Slug, resourceContents{
+		URI:      siteResourceURI(head.Slug, "description", ""),
+		MimeType: "application/json",
+		Text:     indentJSON(desc),
+	}, nil
+}
+
+// embedResource reads uri for a prompt. A resource that cannot be read
+// — logs of a container that never came up, say — becomes a note, since
+// its absence is itself a clue.
+func (s *Server) embedResource(ctx context.Context, uri string) promptMessage {
+	r, err := s.parseResource(uri)
+	if err == nil {
+		var contents resourceContents
+		if contents, err = s.readResource(ctx, r); err == nil {
+			return promptMessage{Role: "user", Content: promptContent{Type: "resource", Resource: &contents}}
+		}
+	}
+	return userText(fmt.Sprintf("(%s could not be read: %s)", uri, mapDaemonErr(err)))
+}
+
+func userText(text string) promptMessage {
+	return promptMessage{Role: "user", Content: promptContent{Type: "text", Text: text}}
+}
+
+func renderDiagnoseStart(ctx context.Context, s *Server, args map[string]string) (promptGetResult, error) {
+	slug, desc, err := s.promptSite(ctx, args["slug"])
+	if err != nil {
+		return promptGetResult{}, err
+	}
+	msgs := []promptMessage{
+		userText(fmt.Sprintf(`The Locorum site %q failed to start. Its description, recent lifecycle activity, latest hook runs and container log tails follow.
+
+Work out why:
+1. Find the failed plan in the activity; its step error names the step that broke.
+2. Look for the matching error in the hook output and the container logs.
+3. Explain the cause in a sentence or two and propose the smallest fix.
+
+Confirm theories with read-only tools (read_log, list_requests, describe_site). Ask before starting, stopping, restoring or deleting anything.`, slug)),
+		{Role: "user", Content: promptContent{Type: "resource", Resource: &desc}},
+		s.embedResource(ctx, siteResourceURI(slug, "activity", "")),
+	}
+	if s.profile != daemon.ProfileReadOnly {
+		msgs = append(msgs, s.embedResource(ctx, siteResourceURI(slug, "hooks", "")))
+	}
+	for _, svc := range []string{"php", "web", "database"} {
+		msgs = append(msgs, s.embedResource(ctx, siteResourceURI(slug, resourceKindLogs, svc)))
+	}
+	return promptGetResult{
+		Description: "Diagnose why " + slug + " failed to start",
+		Messages:    msgs,
+	}, nil
+}
+
+func renderPrepareWorktree(ctx context.Context, s *Server, args map[string]string) (promptGetResult, error) {
+	parent, desc, err := s.promptSite(ctx, args["parentSlug"])
+	if err != nil {
+		return promptGetResult{}, err
+	}
+	branch := args["branch"]
+	remote := "the parent's upstream (`git -C <filesDir> remote get-url origin`, filesDir from the attached description)"
+	if r := args["gitRemote"]; r != "" {
+		remote = "`" + r + "`"
+	}
+	text := fmt.Sprintf(`Prepare a Locorum site for reviewing branch %q, as a worktree of the site %q.
+
+1. Call worktree_create with dryRun=true: name "%s %s", gitRemote %s, branch %q, parentSlug %q. Set cloneDb=true if the change needs the parent's content to review.
+2. Check the plan, then call worktree_create again without dryRun.
+3. Call start_site on the new slug. If it fails, read its recent_activity and the php/web logs before retrying.
+4. Reply with the new site's URL and slug.
+
+The parent's config.yaml is attached. The worktree starts from the same runtime unless you override it; mention anything the branch looks likely to need changed (PHP version, extensions, database engine).`,
+		branch, parent, parent, branch, remote, branch, parent)
+	return promptGetResult{
+		Description: "Prepare a worktree of " + parent + " for branch " + branch,
+		Messages: []promptMessage{
+			userText(text),
+			{Role: "user", Content: promptContent{Type: "resource", Resource: &desc}},
+			s.embedResource(ctx, siteResourceURI(parent, "config", "")),
+		},
+	}, nil
+}
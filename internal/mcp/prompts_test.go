@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/daemon"
+)
+
+func TestPrompts_ListByProfile(t *testing.T) {
+	ro := NewServer(Options{Profile: daemon.ProfileReadOnly})
+	for _, p := range ro.promptList() {
+		if p.Name == "prepare_worktree_pr" {
+			t.Error("readonly profile lists prepare_worktree_pr, whose workflow needs mutating tools")
+		}
+	}
+	full := NewServer(Options{Profile: daemon.ProfileFull})
+	if got := len(full.promptList()); got != len(allPrompts) {
+		t.Errorf("full profile lists %d prompts, want %d", got, len(allPrompts))
+	}
+}
+
+func TestPrompts_DiagnoseStartFailure(t *testing.T) {
+	cli := startStubDaemon(t, map[string]daemon.Handler{
+		"site.describe":       reply(map[string]any{"id": "id1", "slug": "shop", "started": false}),
+		"site.recentActivity": reply([]map[string]any{{"kind": "start", "message": "php: exited 255"}}),
+		"hook.logs":           reply(map[string]any{"runs": []any{}}),
+		"site.logs": func(_ context.Context, _ *daemon.Conn, params json.RawMessage) (any, error) {
+			var p struct {
+				Service string `json:"service"`
+			}
+			_ = json.Unmarshal(params, &p)
+			if p.Service == "database" {
+				return nil, daemon.NotFound("container")
+			}
+			return map[string]any{"output": p.Service + " log\n"}, nil
+		},
+	})
+
+	_, frames := serveFrames(t, Options{Client: cli, Profile: daemon.ProfileFull, Scope: "id1"},
+		`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"diagnose_start_failure"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"prepare_worktree_pr"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"nope"}}`,
+	)
+
+	var res promptGetResult
+	if err := json.Unmarshal(frames[0].Result, &res); err != nil {
+		t.Fatalf("diagnose: %v (%+v)", err, frames[0].Error)
+	}
+	var parts []string
+	for _, m := range res.Messages {
+		if m.Content.Resource != nil {
+			parts = append(parts, m.Content.Resource.URI)
+		} else {
+			parts = append(parts, m.Content.Text)
+		}
+	}
+	if len(parts) != 7 || !strings.Contains(parts[0], `"shop" failed to start`) {
+		t.Fatalf("messages = %q", parts)
+	}
+	wantURIs := []string{
+		"locorum://site/shop/description",
+		"locorum://site/shop/activity",
+		"locorum://site/shop/hooks",
+		"locorum://site/shop/logs/php",
+		"locorum://site/shop/logs/web",
+	}
+	if strings.Join(parts[1:6], " ") != strings.Join(wantURIs, " ") {
+		t.Errorf("embedded = %q\nwant %q", parts[1:6], wantURIs)
+	}
+	// The scope was an id; resources are still addressed by slug, and
+	// the unreadable database log becomes a note, not a failure.
+	if !strings.Contains(parts[6], "locorum://site/shop/logs/database could not be read") {
+		t.Errorf("database note = %q", parts[6])
+	}
+
+	if frames[1].Error == nil || frames[1].Error.Code != codeInvalidParams || !strings.Contains(frames[1].Error.Message, "branch") {
+		t.Errorf("missing branch: %+v", frames[1].Error)
+	}
+	if frames[2].Error == nil || frames[2].Error.Code != codeInvalidParams {
+		t.Errorf("unknown prompt: %+v", frames[2].Error)
+	}
+}
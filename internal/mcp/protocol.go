@@ -1,10 +1,12 @@
 // Package mcp implements a Model Context Protocol server that exposes
-// Locorum's daemon as a curated set of tools to local AI agents.
+// Locorum's daemon as a curated set of tools, resources and prompts to
+// local AI agents.
 //
 // Locorum's MCP server is a thin shim: every tool call is forwarded to
 // the daemon over the IPC socket. The daemon enforces the security
 // boundary (per-site mutex, profile gating, scope checks). This package
-// owns the protocol surface and the tool catalogue.
+// owns the protocol surface and the tool, resource and prompt
+// catalogues.
 //
 // MCP (https://spec.modelcontextprotocol.io) is JSON-RPC 2.0 over
 // either stdio (one frame per line) or Streamable HTTP. Locorum ships
@@ -36,6 +38,10 @@ const (
 	codeMethodNotFound = -32601
 	codeInvalidParams  = -32602
 	codeInternalError  = -32603
+
+	// codeResourceNotFound is MCP's code for resources/read of a URI
+	// that names nothing.
+	codeResourceNotFound = -32002
 )
 
 // request is one inbound MCP frame. Notifications (no id) and
@@ -48,6 +54,13 @@ type request struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// notification is a server-initiated frame: no id, no reply expected.
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 // response is one outbound MCP frame. Exactly one of Result or Error
 // is set on a reply; notifications never produce a response.
 type response struct {
@@ -63,8 +76,8 @@ type rpcError struct {
 }
 
 // initializeResult is the fixed response shape MCP clients expect from
-// initialize. Capabilities advertise which features we support — only
-// "tools" today; resources/prompts can land in a follow-on.
+// initialize. Capabilities advertise which features we support: tools,
+// resources and prompts.
 type initializeResult struct {
 	ProtocolVersion string             `json:"protocolVersion"`
 	ServerInfo      serverInfo         `json:"serverInfo"`
@@ -78,7 +91,9 @@ type serverInfo struct {
 }
 
 type serverCapabilities struct {
-	Tools *toolsCapability `json:"tools,omitempty"`
+	Tools     *toolsCapability     `json:"tools,omitempty"`
+	Resources *resourcesCapability `json:"resources,omitempty"`
+	Prompts   *promptsCapability   `json:"prompts,omitempty"`
 }
 
 type toolsCapability struct {
@@ -88,6 +103,20 @@ type toolsCapability struct {
 	ListChanged bool `json:"listChanged"`
 }
 
+// resourcesCapability advertises resources/subscribe and
+// `notifications/resources/list_changed`. Both need a connection the
+// server can push on, so they are off over HTTP.
+type resourcesCapability struct {
+	Subscribe   bool `json:"subscribe"`
+	ListChanged bool `json:"listChanged"`
+}
+
+// promptsCapability mirrors toolsCapability: the prompt catalogue is
+// fixed at process start.
+type promptsCapability struct {
+	ListChanged bool `json:"listChanged"`
+}
+
 // toolListResult is the response shape for `tools/list`.
 type toolListResult struct {
 	Tools []toolDescriptor `json:"tools"`
@@ -115,6 +144,85 @@ type contentPart struct {
 	Text string `json:"text"`
 }
 
+// resourceDescriptor is one entry of `resources/list`.
+type resourceDescriptor struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// resourceTemplate is one entry of `resources/templates/list`: an RFC
+// 6570 URI template the client fills in itself.
+type resourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type resourceListResult struct {
+	Resources []resourceDescriptor `json:"resources"`
+}
+
+type resourceTemplateListResult struct {
+	ResourceTemplates []resourceTemplate `json:"resourceTemplates"`
+}
+
+// resourceReadResult is the response shape for `resources/read`. We
+// always return exactly one text entry.
+type resourceReadResult struct {
+	Contents []resourceContents `json:"contents"`
+}
+
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// promptDescriptor is one entry of `prompts/list`.
+type promptDescriptor struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description"`
+	Arguments   []promptArgument `json:"arguments,omitempty"`
+}
+
+type promptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type promptListResult struct {
+	Prompts []promptDescriptor `json:"prompts"`
+}
+
+// promptGetResult is the response shape for `prompts/get`.
+type promptGetResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []promptMessage `json:"messages"`
+}
+
+// promptMessage carries one content item. Resources are embedded
+// rather than linked so the agent gets the context without a
+// resources/read round trip.
+type promptMessage struct {
+	Role    string        `json:"role"`
+	Content promptContent `json:"content"`
+}
+
+// promptContent is a text part (Text) or an embedded resource
+// (Resource), per Type.
+type promptContent struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Resource *resourceContents `json:"resource,omitempty"`
+}
+
 // errStop is the sentinel returned by the read loop when stdin is
 // closed (the controlling MCP client has gone away). Caught at the
 // top level to terminate cleanly.
@@ -0,0 +1,428 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/daemon"
+)
+
+// Resource URIs name one piece of one site's context:
+//
+//	locorum://site/<slug>/description
+//	locorum://site/<slug>/config
+//	locorum://site/<slug>/activity
+//	locorum://site/<slug>/hooks
+//	locorum://site/<slug>/logs/<service>
+//
+// Keyed by slug rather than id so the URIs an agent sees say which site
+// they belong to.
+const resourcePrefix = "locorum://site/"
+
+// resourceLogLines is how much of a container log a logs resource
+// carries: the same default read_log uses.
+const resourceLogLines = 200
+
+// resourceHookRuns is how many recent hook runs the hooks resource
+// carries.
+const resourceHookRuns = 3
+
+// resourceKind describes one per-site resource. requireFull hides it
+// from the readonly profile, as for tools.
+type resourceKind struct {
+	name        string
+	title       string
+	description string
+	mimeType    string
+	requireFull bool
+}
+
+const resourceKindLogs = "logs"
+
+// resourceKinds is the per-site resource catalogue, in listing order.
+var resourceKinds = []resourceKind{
+	{
+		name:        "description",
+		title:       "Site description",
+		description: "The site's SiteDescription: status, URL, runtime versions, containers, hook counts. Same shape as describe_site.",
+		mimeType:    "application/json",
+	},
+	{
+		name:        "config",
+		title:       "config.yaml",
+		description: "The site's settings as the config.yaml Locorum writes to its files directory. Reflects the stored site, not unapplied hand edits.",
+		mimeType:    "application/yaml",
+	},
+	{
+		name:        "activity",
+		title:       "Recent activity",
+		description: "The most recent lifecycle events (start, stop, clone, snapshot…) with their step errors.",
+		mimeType:    "application/json",
+	},
+	{
+		name:        "hooks",
+		title:       "Hook runs",
+		description: fmt.Sprintf("Output of the site's %d most recent lifecycle hook runs, newest first, redacted.", resourceHookRuns),
+		mimeType:    "text/plain",
+		requireFull: true,
+	},
+	{
+		name:        resourceKindLogs,
+		title:       "Container log",
+		description: fmt.Sprintf("The last %d lines of one service container's log (web, php, database or the cache service).", resourceLogLines),
+		mimeType:    "text/plain",
+	},
+}
+
+// siteResource is a parsed resource URI.
+type siteResource struct {
+	slug    string
+	kind    resourceKind
+	service string // logs only
+}
+
+func (r siteResource) uri() string {
+	return siteResourceURI(r.slug, r.kind.name, r.service)
+}
+
+func siteResourceURI(slug, kind, service string) string {
+	uri := resourcePrefix + slug + "/" + kind
+	if service != "" {
+		uri += "/" + service
+	}
+	return uri
+}
+
+// parseResource resolves uri against the catalogue visible in the
+// current profile.
+func (s *Server) parseResource(uri string) (siteResource, error) {
+	rest, ok := strings.CutPrefix(uri, resourcePrefix)
+	if !ok {
+		return siteResource{}, fmt.Errorf("unknown resource: %s", uri)
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || parts[0] == "" {
+		return siteResource{}, fmt.Errorf("unknown resource: %s", uri)
+	}
+	for _, k := range resourceKinds {
+		if k.name != parts[1] || (k.requireFull && s.profile == daemon.ProfileReadOnly) {
+			continue
+		}
+		want := 2
+		if k.name == resourceKindLogs {
+			want = 3
+		}
+		if len(parts) != want || parts[len(parts)-1] == "" {
+			break
+		}
+		r := siteResource{slug: parts[0], kind: k}
+		if want == 3 {
+			r.service = parts[2]
+		}
+		return r, nil
+	}
+	return siteResource{}, fmt.Errorf("unknown resource: %s", uri)
+}
+
+// visibleKinds is resourceKinds minus the ones the profile hides.
+func (s *Server) visibleKinds() []resourceKind {
+	out := make([]resourceKind, 0, len(resourceKinds))
+	for _, k := range resourceKinds {
+		if k.requireFull && s.profile == daemon.ProfileReadOnly {
+			continue
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+func (s *Server) resourceTemplates() []resourceTemplate {
+	out := make([]resourceTemplate, 0, len(resourceKinds))
+	for _, k := range s.visibleKinds() {
+		service := ""
+		if k.name == resourceKindLogs {
+			service = "{service}"
+		}
+		out = append(out, resourceTemplate{
+			URITemplate: siteResourceURI("{slug}", k.name, service),
+			Name:        k.name,
+			Title:       k.title,
+			Description: k.description,
+			MimeType:    k.mimeType,
+		})
+	}
+	return out
+}
+
+// handleResourceList lists every visible resource of every site, or of
+// the scoped site only. Log resources are listed per container.
+func (s *Server) handleResourceList(ctx context.Context, req request) error {
+	var list []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Slug       string `json:"slug"`
+		Containers []struct {
+			Service string `json:"service"`
+		} `json:"containers"`
+	}
+	if err := s.callDaemon(ctx, "site.list", map[string]any{}, &list); err != nil {
+		s.writeError(req.ID, codeInternalError, mapDaemonErr(err).Error())
+		return nil
+	}
+
+	out := resourceListResult{Resources: []resourceDescriptor{}}
+	for _, site := range list {
+		if s.scope != "" && s.scope != site.ID && s.scope != site.Slug {
+			continue
+		}
+		for _, k := range s.visibleKinds() {
+			services := []string{""}
+			if k.name == resourceKindLogs {
+				services = services[:0]
+				for _, c := range site.Containers {
+					services = append(services, c.Service)
+				}
+			}
+			for _, svc := range services {
+				title := site.Name + ": " + k.title
+				if svc != "" {
+					title += " (" + svc + ")"
+				}
+				out.Resources = append(out.Resources, resourceDescriptor{
+					URI:         siteResourceURI(site.Slug, k.name, svc),
+					Name:        site.Slug + "/" + strings.TrimSuffix(k.name+"/"+svc, "/"),
+					Title:       title,
+					Description: k.description,
+					MimeType:    k.mimeType,
+				})
+			}
+		}
+	}
+	s.writeResult(req.ID, out)
+	return nil
+}
+
+func (s *Server) handleResourceRead(ctx context.Context, req request) error {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		s.writeError(req.ID, codeInvalidParams, "invalid resources/read params: "+err.Error())
+		return nil
+	}
+	r, err := s.parseResource(p.URI)
+	if err != nil {
+		s.writeError(req.ID, codeResourceNotFound, err.Error())
+		return nil
+	}
+	contents, err := s.readResource(ctx, r)
+	if err != nil {
+		s.writeError(req.ID, resourceErrCode(err), mapDaemonErr(err).Error())
+		return nil
+	}
+	s.writeResult(req.ID, resourceReadResult{Contents: []resourceContents{contents}})
+	return nil
+}
+
+// readResource fetches r from the daemon. The daemon enforces the MCP
+// scope, so a URI naming another site fails there like a tool call.
+func (s *Server) readResource(ctx context.Context, r siteResource) (resourceContents, error) {
+	out := resourceContents{URI: r.uri(), MimeType: r.kind.mimeType}
+	ref := map[string]any{"slug": r.slug}
+	switch r.kind.name {
+	case "description":
+		var desc json.RawMessage
+		if err := s.callDaemon(ctx, "site.describe", ref, &desc); err != nil {
+			return out, err
+		}
+		out.Text = indentJSON(desc)
+	case "config":
+		var cfg struct {
+			YAML string `json:"yaml"`
+		}
+		if err := s.callDaemon(ctx, "site.config_yaml", ref, &cfg); err != nil {
+			return out, err
+		}
+		out.Text = cfg.YAML
+	case "activity":
+		var activity json.RawMessage
+		if err := s.callDaemon(ctx, "site.recentActivity", ref, &activity); err != nil {
+			return out, err
+		}
+		out.Text = indentJSON(activity)
+	case "hooks":
+		ref["limit"] = resourceHookRuns
+		var logs struct {
+			Runs []struct {
+				Event     string    `json:"event"`
+				Started   time.Time `json:"started"`
+				Text      string    `json:"text"`
+				Truncated bool      `json:"truncated"`
+			} `json:"runs"`
+		}
+		if err := s.callDaemon(ctx, "hook.logs", ref, &logs); err != nil {
+			return out, err
+		}
+		if len(logs.Runs) == 0 {
+			out.Text = "No hook runs recorded for this site.\n"
+			break
+		}
+		var b strings.Builder
+		for i, run := range logs.Runs {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "=== %s at %s ===\n", run.Event, run.Started.UTC().Format(time.RFC3339))
+			if run.Truncated {
+				b.WriteString("[earlier output truncated]\n")
+			}
+			b.WriteString(run.Text)
+		}
+		out.Text = b.String()
+	case resourceKindLogs:
+		ref["service"] = r.service
+		ref["lines"] = resourceLogLines
+		var logs struct {
+			Output string `json:"output"`
+		}
+		if err := s.callDaemon(ctx, "site.logs", ref, &logs); err != nil {
+			return out, err
+		}
+		out.Text = logs.Output
+	}
+	return out, nil
+}
+
+// resourceErrCode picks the JSON-RPC code for a failed resources/read.
+// An unknown site is an unknown resource to the client.
+func resourceErrCode(err error) int {
+	var rpc *daemon.RPCError
+	if !errors.As(err, &rpc) {
+		return codeInternalError
+	}
+	switch rpc.Code {
+	case daemon.CodeNotFound:
+		return codeResourceNotFound
+	case daemon.CodeForbidden:
+		return codeInvalidRequest
+	case codeInvalidParams:
+		return codeInvalidParams
+	default:
+		return codeInternalError
+	}
+}
+
+func indentJSON(raw json.RawMessage) string {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return string(raw)
+	}
+	return string(body)
+}
+
+// ─── subscriptions ──────────────────────────────────────────────────
+
+func (s *Server) handleResourceSubscribe(req request, subscribe bool) error {
+	if s.oneShot {
+		s.writeError(req.ID, codeInvalidRequest, "resource subscriptions need the stdio transport")
+		return nil
+	}
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		s.writeError(req.ID, codeInvalidParams, "invalid params: "+err.Error())
+		return nil
+	}
+	r, err := s.parseResource(p.URI)
+	if err != nil {
+		s.writeError(req.ID, codeResourceNotFound, err.Error())
+		return nil
+	}
+	// No scope check: the daemon only pushes the scoped site's events,
+	// so a URI naming another site never fires.
+	s.subMu.Lock()
+	if s.subs == nil {
+		s.subs = make(map[string]struct{})
+	}
+	if subscribe {
+		s.subs[r.uri()] = struct{}{}
+	} else {
+		delete(s.subs, r.uri())
+	}
+	s.subMu.Unlock()
+	s.writeResult(req.ID, struct{}{})
+	return nil
+}
+
+// watchEvents subscribes to the daemon events that change resources,
+// once per session. Site events mark that site's subscribed URIs
+// updated; sites.changed (withheld from scoped sessions by the daemon)
+// changes the resource list. Failure only costs the notifications.
+func (s *Server) watchEvents(ctx context.Context) {
+	s.subMu.Lock()
+	if s.watching || s.client == nil || s.oneShot {
+		s.subMu.Unlock()
+		return
+	}
+	s.watching = true
+	s.subMu.Unlock()
+
+	kinds := []string{daemon.EventSiteUpdated, daemon.EventPlanDone}
+	if s.scope == "" {
+		kinds = append(kinds, daemon.EventSitesChanged)
+	}
+	ch, err := s.client.Subscribe(ctx, daemon.EventFilter{Kinds: kinds})
+	if err != nil {
+		s.logger.Warn("resource notifications unavailable", "err", err.Error())
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				s.resourceEvent(ev)
+			}
+		}
+	}()
+}
+
+// resourceEvent turns one daemon event into resource notifications.
+// Any event about a site may change any of its resources, so every
+// subscribed URI of that site is reported.
+func (s *Server) resourceEvent(ev daemon.Event) {
+	if ev.Kind == daemon.EventSitesChanged {
+		s.notify("notifications/resources/list_changed", nil)
+		return
+	}
+	if ev.Slug == "" {
+		return
+	}
+	prefix := resourcePrefix + ev.Slug + "/"
+	s.subMu.Lock()
+	var uris []string
+	for uri := range s.subs {
+		if strings.HasPrefix(uri, prefix) {
+			uris = append(uris, uri)
+		}
+	}
+	s.subMu.Unlock()
+	slices.Sort(uris)
+	for _, uri := range uris {
+		s.notify("notifications/resources/updated", map[string]string{"uri": uri})
+	}
+}
@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/daemon"
+)
+
+// startStubDaemon serves handlers on a real daemon socket and returns a
+// full-profile client for it. Only the methods a test touches need
+// stubbing.
+func startStubDaemon(t *testing.T, handlers map[string]daemon.Handler) *daemon.Client {
+	t.Helper()
+	dir := t.TempDir()
+	if runtime.GOOS == "darwin" {
+		// sun_path is short on macOS; t.TempDir() can overflow it.
+		var err error
+		if dir, err = os.MkdirTemp("/tmp", "lcr"); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	}
+	ln, err := daemon.Listen(filepath.Join(dir, "s"))
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	srv := daemon.NewServer(ln, nil)
+	for name, h := range handlers {
+		srv.Register(name, h)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _ = srv.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		srv.Shutdown(time.Second)
+	})
+
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), time.Second)
+	defer dialCancel()
+	cli, err := daemon.DialClient(dialCtx, filepath.Join(dir, "s"), daemon.HelloOptions{PeerKind: "mcp"})
+	if err != nil {
+		t.Fatalf("DialClient: %v", err)
+	}
+	t.Cleanup(func() { _ = cli.Close() })
+	return cli
+}
+
+// reply returns a handler answering every call with v.
+func reply(v any) daemon.Handler {
+	return func(context.Context, *daemon.Conn, json.RawMessage) (any, error) { return v, nil }
+}
+
+// frame is one decoded output line: a response or a notification.
+type frame struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// serveFrames runs srv over the given request lines and returns what
+// it wrote, one frame per line.
+func serveFrames(t *testing.T, opts Options, lines ...string) (*Server, []frame) {
+	t.Helper()
+	var out bytes.Buffer
+	opts.In = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	opts.Out = &out
+	srv := NewServer(opts)
+	if err := srv.Serve(context.Background()); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	return srv, decodeFrames(t, out.Bytes())
+}
+
+func decodeFrames(t *testing.T, body []byte) []frame {
+	t.Helper()
+	var frames []frame
+	for _, line := range bytes.Split(bytes.TrimSpace(body), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var f frame
+		if err := json.Unmarshal(line, &f); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		frames = append(frames, f)
+	}
+	return frames
+}
+
+func TestResources_ListAndRead(t *testing.T) {
+	var logParams map[string]any
+	cli := startStubDaemon(t, map[string]daemon.Handler{
+		"site.list": reply([]map[string]any{
+			{"id": "id1", "name": "Shop", "slug": "shop", "containers": []map[string]string{{"service": "web"}, {"service": "php"}}},
+			{"id": "id2", "name": "Blog", "slug": "blog"},
+		}),
+		"site.config_yaml": reply(map[string]any{"siteId": "id1", "yaml": "name: Shop\n"}),
+		"site.logs": func(_ context.Context, _ *daemon.Conn, params json.RawMessage) (any, error) {
+			_ = json.Unmarshal(params, &logParams)
+			return map[string]any{"output": "PHP Warning: boom\n"}, nil
+		},
+	})
+
+	_, frames := serveFrames(t, Options{Client: cli, Profile: daemon.ProfileFull, Scope: "shop"},
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"locorum://site/shop/config"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"locorum://site/shop/logs/php"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"locorum://site/shop/secrets"}}`,
+	)
+	if len(frames) != 4 {
+		t.Fatalf("got %d frames, want 4", len(frames))
+	}
+
+	var list resourceListResult
+	if err := json.Unmarshal(frames[0].Result, &list); err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range list.Resources {
+		uris = append(uris, r.URI)
+	}
+	want := []string{
+		"locorum://site/shop/description",
+		"locorum://site/shop/config",
+		"locorum://site/shop/activity",
+		"locorum://site/shop/hooks",
+		"locorum://site/shop/logs/web",
+		"locorum://site/shop/logs/php",
+	}
+	if strings.Join(uris, " ") != strings.Join(want, " ") {
+		t.Errorf("scoped list = %v\nwant %v", uris, want)
+	}
+
+	var read resourceReadResult
+	if err := json.Unmarshal(frames[1].Result, &read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Contents) != 1 || read.Contents[0].Text != "name: Shop\n" || read.Contents[0].MimeType != "application/yaml" {
+		t.Errorf("config read = %+v", read.Contents)
+	}
+
+	if err := json.Unmarshal(frames[2].Result, &read); err != nil {
+		t.Fatal(err)
+	}
+	if read.Contents[0].Text != "PHP Warning: boom\n" || logParams["service"] != "php" || logParams["slug"] != "shop" {
+		t.Errorf("log read = %+v, params %v", read.Contents, logParams)
+	}
+
+	if frames[3].Error == nil || frames[3].Error.Code != codeResourceNotFound {
+		t.Errorf("unknown resource: %+v, want resource-not-found", frames[3].Error)
+	}
+}
+
+// TestResources_ReadOnlyHidesHooks: hook output is Full-only on the
+// daemon, so the readonly profile must not even list it.
+func TestResources_ReadOnlyHidesHooks(t *testing.T) {
+	srv, frames := serveFrames(t, Options{Profile: daemon.ProfileReadOnly},
+		`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"locorum://site/shop/hooks"}}`,
+	)
+	if frames[0].Error == nil || frames[0].Error.Code != codeResourceNotFound {
+		t.Errorf("readonly hooks read: %+v, want resource-not-found", frames[0].Error)
+	}
+	for _, tmpl := range srv.resourceTemplates() {
+		if strings.Contains(tmpl.URITemplate, "/hooks") {
+			t.Errorf("readonly templates include %s", tmpl.URITemplate)
+		}
+	}
+}
+
+func TestResources_SubscribeNotifies(t *testing.T) {
+	srv, frames := serveFrames(t, Options{Profile: daemon.ProfileFull},
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"locorum://site/shop/description"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"locorum://site/shop/logs/php"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"locorum://site/blog/config"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/unsubscribe","params":{"uri":"locorum://site/blog/config"}}`,
+	)
+	for _, f := range frames {
+		if f.Error != nil {
+			t.Fatalf("frame %d: %+v", f.ID, f.Error)
+		}
+	}
+
+	var out bytes.Buffer
+	srv.out = &out
+	srv.resourceEvent(daemon.Event{Kind: daemon.EventPlanDone, SiteID: "id1", Slug: "shop"})
+	srv.resourceEvent(daemon.Event{Kind: daemon.EventSiteUpdated, SiteID: "id2", Slug: "blog"})
+	srv.resourceEvent(daemon.Event{Kind: daemon.EventSitesChanged})
+
+	var got []string
+	for _, f := range decodeFrames(t, out.Bytes()) {
+		var p struct {
+			URI string `json:"uri"`
+		}
+		_ = json.Unmarshal(f.Params, &p)
+		got = append(got, strings.TrimSpace(f.Method+" "+p.URI))
+	}
+	want := []string{
+		"notifications/resources/updated locorum://site/shop/description",
+		"notifications/resources/updated locorum://site/shop/logs/php",
+		"notifications/resources/list_changed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("notifications:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestResources_OneShotRefusesSubscribe: an HTTP request has nowhere
+// to deliver notifications, so it neither advertises nor accepts them.
+func TestResources_OneShotRefusesSubscribe(t *testing.T) {
+	core := NewServer(Options{Profile: daemon.ProfileFull})
+	var out bytes.Buffer
+	once := core.cloneForOneShot(strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`+"\n"+
+			`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"locorum://site/shop/config"}}`+"\n"), &out)
+	if err := once.Serve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	frames := decodeFrames(t, out.Bytes())
+	if !strings.Contains(string(frames[0].Result), `"resources":{"subscribe":false,"listChanged":false}`) {
+		t.Errorf("one-shot capabilities: %s", frames[0].Result)
+	}
+	if frames[1].Error == nil {
+		t.Error("one-shot resources/subscribe accepted")
+	}
+}
@@ -18,8 +18,9 @@ import (
 // Server is the MCP stdio server. Constructed once per process; the
 // caller wires stdin/stdout and a daemon client, then calls Serve.
 //
-// Server is safe to use from a single goroutine only. MCP stdio is
-// one-frame-at-a-time, no pipelining required by the spec.
+// Dispatch runs on a single goroutine: MCP stdio is one-frame-at-a-time,
+// no pipelining required by the spec. The resource watcher (see
+// watchEvents) is the only other writer; it goes through notify.
 type Server struct {
 	in     io.Reader
 	out    io.Writer
@@ -47,6 +48,17 @@ type Server struct {
 	// notifications until the handshake completes.
 	mu          sync.Mutex
 	initialized bool
+
+	// oneShot marks an HTTP per-request clone. It has no connection
+	// to push on, so it refuses resources/subscribe.
+	oneShot bool
+
+	// subs is the set of resource URIs the client subscribed to;
+	// watching is set once the daemon event subscription behind them
+	// is up.
+	subMu    sync.Mutex
+	subs     map[string]struct{}
+	watching bool
 }
 
 // Options configures a new MCP server.
@@ -141,6 +153,7 @@ func (s *Server) dispatch(ctx context.Context, req request) error {
 		s.mu.Lock()
 		s.initialized = true
 		s.mu.Unlock()
+		s.watchEvents(ctx)
 		return nil
 	case "ping":
 		s.writeResult(req.ID, struct{}{})
@@ -150,6 +163,22 @@ func (s *Server) dispatch(ctx context.Context, req request) error {
 		return nil
 	case "tools/call":
 		return s.handleToolCall(ctx, req)
+	case "resources/list":
+		return s.handleResourceList(ctx, req)
+	case "resources/templates/list":
+		s.writeResult(req.ID, resourceTemplateListResult{ResourceTemplates: s.resourceTemplates()})
+		return nil
+	case "resources/read":
+		return s.handleResourceRead(ctx, req)
+	case "resources/subscribe":
+		return s.handleResourceSubscribe(req, true)
+	case "resources/unsubscribe":
+		return s.handleResourceSubscribe(req, false)
+	case "prompts/list":
+		s.writeResult(req.ID, promptListResult{Prompts: s.promptList()})
+		return nil
+	case "prompts/get":
+		return s.handlePromptGet(ctx, req)
 	default:
 		// Notifications other than the ones we handle: silently
 		// drop. Requests: respond with method-not-found.
@@ -165,12 +194,17 @@ func (s *Server) dispatch(ctx context.Context, req request) error {
 func (s *Server) handleInitialize(req request) error {
 	instructions := "Locorum exposes site-management tools backed by a local daemon. " +
 		"Mutating tools require Profile=full; readonly returns only inspection tools. " +
-		"When MCP scope is set, every site-targeted tool is forced to that site by the daemon."
+		"When MCP scope is set, every site-targeted tool is forced to that site by the daemon. " +
+		"Site context (description, config.yaml, activity, hook runs, container logs) is also readable as locorum://site/<slug>/... resources."
 	s.writeResult(req.ID, initializeResult{
 		ProtocolVersion: MCPProtocolVersion,
 		ServerInfo:      serverInfo{Name: ServerName, Version: s.version},
-		Capabilities:    serverCapabilities{Tools: &toolsCapability{ListChanged: false}},
-		Instructions:    instructions,
+		Capabilities: serverCapabilities{
+			Tools:     &toolsCapability{ListChanged: false},
+			Resources: &resourcesCapability{Subscribe: !s.oneShot, ListChanged: !s.oneShot},
+			Prompts:   &promptsCapability{ListChanged: false},
+		},
+		Instructions: instructions,
 	})
 	return nil
 }
@@ -255,13 +289,25 @@ func (s *Server) writeToolError(id json.RawMessage, msg string) {
 	s.write(resp)
 }
 
+// notify sends a server-initiated notification. Dropped until the
+// client completes the handshake.
+func (s *Server) notify(method string, params any) {
+	s.mu.Lock()
+	ready := s.initialized
+	s.mu.Unlock()
+	if !ready {
+		return
+	}
+	s.write(notification{JSONRPC: jsonRPCVersion, Method: method, Params: params})
+}
+
 // write encodes a frame on stdout, holding a mutex so a slow producer
 // goroutine cannot interleave bytes with a notification.
-func (s *Server) write(resp response) {
+func (s *Server) write(frame any) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	enc := json.NewEncoder(s.out)
-	if err := enc.Encode(resp); err != nil {
+	if err := enc.Encode(frame); err != nil {
 		s.logger.Warn("write frame failed", "err", err.Error())
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"slices"
 	"strings"
 	"testing"
 
@@ -23,7 +24,9 @@ func TestServer_ToolListReadOnly(t *testing.T) {
 	for _, t := range tools {
 		switch t.Name {
 		case "start_site", "stop_site", "wp_cli",
-			"create_snapshot", "restore_snapshot", "run_hook":
+			"create_snapshot", "restore_snapshot", "run_hook", "purge_mail", "db_ui_login",
+			"create_site", "import_db", "search_replace", "export_site", "change_versions",
+			"migrate_engine", "set_lan", "set_spx":
 			panic("readonly profile leaked mutating tool: " + t.Name)
 		}
 	}
@@ -51,6 +54,30 @@ func TestServer_ToolListFull(t *testing.T) {
 	}
 }
 
+// TestServer_ToolListScoped covers a site-scoped server: tools that
+// reach past the one site are neither listed nor callable.
+func TestServer_ToolListScoped(t *testing.T) {
+	srv := NewServer(Options{
+		Profile: daemon.ProfileFull,
+		Scope:   "shop",
+		Version: "test",
+	})
+	hidden := []string{"create_site", "worktree_create", "import_site"}
+	for _, tool := range srv.toolList() {
+		if slices.Contains(hidden, tool.Name) {
+			t.Errorf("scoped server lists %s", tool.Name)
+		}
+	}
+	for _, name := range hidden {
+		if _, ok := srv.findTool(name); ok {
+			t.Errorf("scoped server can call %s", name)
+		}
+	}
+	if _, ok := srv.findTool("start_site"); !ok {
+		t.Fatalf("scoped server lost start_site")
+	}
+}
+
 // TestServer_InitializeHandshake exercises the JSON-RPC handshake using
 // in-memory pipes. Confirms the initialize response shape MCP clients
 // rely on.
@@ -131,3 +158,29 @@ func TestServer_ToolsListShape(t *testing.T) {
 		}
 	}
 }
+
+// TestServer_ForwardSiteArgs: the forwarding tools pass their arguments
+// through to the daemon, filling the site ref from the MCP scope.
+func TestServer_ForwardSiteArgs(t *testing.T) {
+	var got map[string]any
+	cli := startStubDaemon(t, map[string]daemon.Handler{
+		"site.lan": func(_ context.Context, _ *daemon.Conn, params json.RawMessage) (any, error) {
+			_ = json.Unmarshal(params, &got)
+			return map[string]any{"dryRun": true, "plan": "enable-lan:shop"}, nil
+		},
+	})
+
+	_, frames := serveFrames(t, Options{Client: cli, Profile: daemon.ProfileFull, Scope: "shop"},
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"set_lan","arguments":{"enabled":true,"dryRun":true}}}`,
+	)
+	var res toolCallResult
+	if err := json.Unmarshal(frames[0].Result, &res); err != nil || res.IsError {
+		t.Fatalf("set_lan: %s (%+v)", frames[0].Result, frames[0].Error)
+	}
+	if got["slug"] != "shop" || got["enabled"] != true || got["dryRun"] != true {
+		t.Errorf("daemon params = %v", got)
+	}
+	if !strings.Contains(res.Content[0].Text, "enable-lan:shop") {
+		t.Errorf("result = %q", res.Content[0].Text)
+	}
+}
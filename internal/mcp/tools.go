@@ -10,21 +10,33 @@ import (
 )
 
 // toolDef pairs a wire descriptor with its implementation. requireFull
-// gates the tool to the full profile; readonly tools omit it.
+// gates the tool to the full profile; readonly tools omit it. unscoped
+// hides the tool from a server with an MCP scope, for tools that reach
+// past any one site.
 type toolDef struct {
 	descriptor  toolDescriptor
 	impl        func(ctx context.Context, s *Server, args json.RawMessage) (any, error)
 	requireFull bool
+	unscoped    bool
 }
 
-// findTool looks up a tool by name, honouring the profile gate. Returns
-// (toolDef, true) when the tool is reachable in the current profile.
+// visible reports whether t is reachable in the server's profile and
+// scope.
+func (s *Server) visible(t toolDef) bool {
+	if s.profile == daemon.ProfileReadOnly && t.requireFull {
+		return false
+	}
+	return !(s.scope != "" && t.unscoped)
+}
+
+// findTool looks up a tool by name, honouring the profile and scope
+// gates. Returns (toolDef, true) when the tool is reachable.
 func (s *Server) findTool(name string) (toolDef, bool) {
 	for _, t := range allTools {
 		if t.descriptor.Name != name {
 			continue
 		}
-		if s.profile == daemon.ProfileReadOnly && t.requireFull {
+		if !s.visible(t) {
 			return toolDef{}, false
 		}
 		return t, true
@@ -32,12 +44,12 @@ func (s *Server) findTool(name string) (toolDef, bool) {
 	return toolDef{}, false
 }
 
-// toolList returns the descriptors visible in the current profile. The
-// readonly profile sees a strict subset.
+// toolList returns the descriptors visible in the current profile and
+// scope. The readonly profile sees a strict subset.
 func (s *Server) toolList() []toolDescriptor {
 	out := make([]toolDescriptor, 0, len(allTools))
 	for _, t := range allTools {
-		if s.profile == daemon.ProfileReadOnly && t.requireFull {
+		if !s.visible(t) {
 			continue
 		}
 		out = append(out, t.descriptor)
@@ -60,6 +72,29 @@ const schemaSiteRef = `{
   ]
 }`
 
+// schemaMailList is list_mail's input. The site is optional: without
+// one (and without an MCP scope) the whole mailbox is listed.
+const schemaMailList = `{
+  "type": "object",
+  "properties": {
+    "siteId": {"type": "string"},
+    "slug":   {"type": "string"},
+    "limit":  {"type": "integer", "minimum": 1, "maximum": 500, "default": 50}
+  }
+}`
+
+// schemaSiteToggle is the input of the on/off tools.
+const schemaSiteToggle = `{
+  "type": "object",
+  "properties": {
+    "siteId":  {"type": "string"},
+    "slug":    {"type": "string"},
+    "enabled": {"type": "boolean"},
+    "dryRun":  {"type": "boolean", "default": false}
+  },
+  "required": ["enabled"]
+}`
+
 // allTools is the registered tool catalogue. Order is presentation-
 // stable: list_sites first, then describe_site, then mutating actions.
 var allTools = []toolDef{
@@ -94,14 +129,14 @@ var allTools = []toolDef{
 		descriptor: toolDescriptor{
 			Name:  "read_log",
 			Title: "Read container log",
-			Description: "Return the trailing N lines of one of a site's service container logs. Service must be one of web/php/database/redis. " +
+			Description: "Return the trailing N lines of one of a site's service container logs. Service must be one of web/php/database or the site's cache service (redis/valkey/memcached). " +
 				"Use this to debug a failing site without granting RCE-equivalent exec access.",
 			InputSchema: json.RawMessage(`{
   "type": "object",
   "properties": {
     "siteId":  {"type": "string"},
     "slug":    {"type": "string"},
-    "service": {"type": "string", "enum": ["web", "php", "database", "redis"]},
+    "service": {"type": "string", "enum": ["web", "php", "database", "redis", "valkey", "memcached"]},
     "lines":   {"type": "integer", "minimum": 1, "maximum": 5000, "default": 200}
   },
   "required": ["service"]
@@ -109,6 +144,72 @@ var allTools = []toolDef{
 		},
 		impl: callReadLog,
 	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "list_requests",
+			Title: "Inspect site requests",
+			Description: "Return the most recent HTTP requests the router served for a site, newest first: method, host, path, status, duration. " +
+				"Filter by status (exact code like 404 or a class like 5xx) and a path substring. Useful for chasing redirect loops and 404s. " +
+				"Only requests since Locorum started are kept.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId": {"type": "string"},
+    "slug":   {"type": "string"},
+    "status": {"type": "string", "description": "status code (\"404\") or class (\"5xx\")"},
+    "path":   {"type": "string", "description": "substring the request path must contain"},
+    "limit":  {"type": "integer", "minimum": 1, "maximum": 1000, "default": 100}
+  }
+}`),
+		},
+		impl: callListRequests,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "list_mail",
+			Title: "List captured mail",
+			Description: "Return mail captured from the sites, newest first: id, sending site, from, to, subject, snippet. Nothing a site sends leaves the machine. " +
+				"Pass a site to see only its mail. Pair with read_mail to check a message's body.",
+			InputSchema: json.RawMessage(schemaMailList),
+		},
+		impl: callListMail,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "search_mail",
+			Title: "Search captured mail",
+			Description: "Search captured mail with Mailpit's search syntax, e.g. `subject:\"Password Reset\" to:admin@example.com`. " +
+				"Use it to assert that a site actually sent an email. Returns the same shape as list_mail.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId": {"type": "string"},
+    "slug":   {"type": "string"},
+    "query":  {"type": "string", "description": "search terms, e.g. subject:\"Password Reset\""},
+    "limit":  {"type": "integer", "minimum": 1, "maximum": 500, "default": 50}
+  },
+  "required": ["query"]
+}`),
+		},
+		impl: callSearchMail,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:        "read_mail",
+			Title:       "Read a captured message",
+			Description: "Return one captured message by id, with its text and HTML bodies, recipients and attachment names.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId": {"type": "string"},
+    "slug":   {"type": "string"},
+    "id":     {"type": "string", "description": "message id from list_mail or search_mail"}
+  },
+  "required": ["id"]
+}`),
+		},
+		impl: callReadMail,
+	},
 	{
 		descriptor: toolDescriptor{
 			Name:        "list_snapshots",
@@ -259,6 +360,28 @@ var allTools = []toolDef{
 		},
 		impl:        callWorktreeCreate,
 		requireFull: true,
+		unscoped:    true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "import_site",
+			Title: "Import a site from an export archive",
+			Description: "Create a new Locorum site from a .tar.gz written by Locorum's export. Files are extracted, the site is started, " +
+				"the database restored, and URLs rewritten to the new local domain. A taken slug gets a -2, -3, … suffix; the result reports the final slug.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "path":        {"type": "string", "description": "absolute host path to the export archive"},
+    "name":        {"type": "string", "description": "site name; defaults to the name in the archive"},
+    "filesDir":    {"type": "string", "description": "absolute directory to extract into; default ~/locorum/sites/<slug>"},
+    "disableAuto": {"type": "boolean", "default": false, "description": "skip the automatic siteurl/home search-replace"}
+  },
+  "required": ["path"]
+}`),
+		},
+		impl:        callImportSite,
+		requireFull: true,
+		unscoped:    true,
 	},
 	{
 		descriptor: toolDescriptor{
@@ -280,6 +403,191 @@ var allTools = []toolDef{
 		impl:        callWorktreeDestroy,
 		requireFull: true,
 	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "purge_mail",
+			Title: "Purge captured mail",
+			Description: "Delete captured mail: a site's, or whatever matches query. Emptying the whole mailbox needs all: true. " +
+				"Purge before triggering an email to make a later search_mail unambiguous.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId": {"type": "string"},
+    "slug":   {"type": "string"},
+    "query":  {"type": "string"},
+    "all":    {"type": "boolean", "default": false}
+  }
+}`),
+		},
+		impl:        callPurgeMail,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "db_ui_login",
+			Title: "Get a database UI login link",
+			Description: "Return a link that opens the global DB UI (Adminer or phpMyAdmin at db.localhost) logged in to the site's database. " +
+				"The site must be running; the link expires after two minutes, so hand it to the user or a browser straight away.",
+			InputSchema: json.RawMessage(schemaSiteRef),
+		},
+		impl:        callDBUILogin,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "create_site",
+			Title: "Create a site",
+			Description: "Create a new, stopped Locorum site with a fresh WordPress database. Unset versions take the configured defaults. " +
+				"Call start_site afterwards to bring it up. dryRun lists what would be created without touching anything.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "name":         {"type": "string", "description": "site name; the slug and <slug>.localhost domain derive from it"},
+    "filesDir":     {"type": "string", "description": "absolute directory for the site files; default ~/locorum/sites/<slug>"},
+    "publicDir":    {"type": "string", "description": "web root relative to filesDir, e.g. \"web\" for Bedrock"},
+    "phpVersion":   {"type": "string"},
+    "dbEngine":     {"type": "string", "enum": ["mysql", "mariadb", "sqlite"]},
+    "dbVersion":    {"type": "string"},
+    "cacheBackend": {"type": "string"},
+    "cacheVersion": {"type": "string"},
+    "webServer":    {"type": "string", "enum": ["nginx", "apache"]},
+    "multisite":    {"type": "string", "enum": ["subdirectory", "subdomain"]},
+    "dryRun":       {"type": "boolean", "default": false}
+  },
+  "required": ["name"]
+}`),
+		},
+		impl:        callCreateSite,
+		requireFull: true,
+		unscoped:    true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "import_db",
+			Title: "Import a SQL dump into a site",
+			Description: "Replace a running site's database with a .sql, .sql.gz or .zip dump. The siteurl/home recorded in the dump is rewritten " +
+				"to the local URL unless disableAuto is set; searchReplace adds further pairs. A pre-import snapshot is taken unless skipSnapshot is set.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId":        {"type": "string"},
+    "slug":          {"type": "string"},
+    "path":          {"type": "string", "description": "absolute host path to the dump; a site-scoped server only reads from the site's files or exports directory"},
+    "searchReplace": {"type": "array", "items": {"type": "object", "properties": {"from": {"type": "string"}, "to": {"type": "string"}}, "required": ["from", "to"]}},
+    "disableAuto":   {"type": "boolean", "default": false},
+    "skipSnapshot":  {"type": "boolean", "default": false},
+    "dryRun":        {"type": "boolean", "default": false}
+  },
+  "required": ["path"]
+}`),
+		},
+		impl:        callImportDB,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "search_replace",
+			Title: "Search-replace across a site's database",
+			Description: "Run wp search-replace for each pair, in order, on a running site. Serialized PHP values are rewritten safely, " +
+				"so use this rather than SQL REPLACE for URLs and paths. Returns wp-cli's per-table report.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId": {"type": "string"},
+    "slug":   {"type": "string"},
+    "pairs":  {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"from": {"type": "string"}, "to": {"type": "string"}}, "required": ["from", "to"]}},
+    "dryRun": {"type": "boolean", "default": false}
+  },
+  "required": ["pairs"]
+}`),
+		},
+		impl:        callSearchReplace,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:        "export_site",
+			Title:       "Export a site to an archive",
+			Description: "Write a running site's files and database to a .tar.gz that import_site can restore. An existing file is never overwritten.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId": {"type": "string"},
+    "slug":   {"type": "string"},
+    "path":   {"type": "string", "description": "absolute host path for the archive; a site-scoped server only writes under ~/locorum/exports/<slug>"},
+    "dryRun": {"type": "boolean", "default": false}
+  },
+  "required": ["path"]
+}`),
+		},
+		impl:        callExportSite,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "change_versions",
+			Title: "Change a site's PHP, database or cache versions",
+			Description: "Change a stopped site's service versions; containers are recreated on the next start. " +
+				"Changes the data volume cannot survive (an engine swap or a database downgrade) are refused — use migrate_engine for those.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId":       {"type": "string"},
+    "slug":         {"type": "string"},
+    "phpVersion":   {"type": "string"},
+    "dbEngine":     {"type": "string", "enum": ["mysql", "mariadb", "sqlite"]},
+    "dbVersion":    {"type": "string"},
+    "cacheBackend": {"type": "string"},
+    "cacheVersion": {"type": "string"},
+    "dryRun":       {"type": "boolean", "default": false}
+  }
+}`),
+		},
+		impl:        callChangeVersions,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:  "migrate_engine",
+			Title: "Migrate a site's database engine or version",
+			Description: "Dump the database, rebuild the volume on the target engine and version, and load the dump back. " +
+				"Slow, and the site is restarted; a snapshot is taken first unless skipSnapshot is set. Leave engine empty to keep the current one.",
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "siteId":       {"type": "string"},
+    "slug":         {"type": "string"},
+    "engine":       {"type": "string", "enum": ["mysql", "mariadb", "sqlite"]},
+    "version":      {"type": "string"},
+    "skipSnapshot": {"type": "boolean", "default": false},
+    "dryRun":       {"type": "boolean", "default": false}
+  },
+  "required": ["version"]
+}`),
+		},
+		impl:        callMigrateEngine,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:        "set_lan",
+			Title:       "Expose a site on the LAN",
+			Description: "Make a site reachable from other devices on the local network via a <slug>.<ip>.sslip.io hostname, or withdraw it.",
+			InputSchema: json.RawMessage(schemaSiteToggle),
+		},
+		impl:        callSetLAN,
+		requireFull: true,
+	},
+	{
+		descriptor: toolDescriptor{
+			Name:        "set_spx",
+			Title:       "Toggle SPX profiling",
+			Description: "Enable or disable the SPX PHP profiler for a stopped site; it takes effect on the next start.",
+			InputSchema: json.RawMessage(schemaSiteToggle),
+		},
+		impl:        callSetSPX,
+		requireFull: true,
+	},
 }
 
 // ─── Tool implementations ────────────────────────────────────────────
@@ -343,6 +651,92 @@ func callReadLog(ctx context.Context, s *Server, args json.RawMessage) (any, err
 	return out, nil
 }
 
+func callListRequests(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	type p struct {
+		SiteID string `json:"siteId"`
+		Slug   string `json:"slug"`
+		Status string `json:"status"`
+		Path   string `json:"path"`
+		Limit  int    `json:"limit"`
+	}
+	var parsed p
+	if err := json.Unmarshal(args, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	params := siteRefMap(s, parsed.SiteID, parsed.Slug)
+	if parsed.Status != "" {
+		params["status"] = parsed.Status
+	}
+	if parsed.Path != "" {
+		params["path"] = parsed.Path
+	}
+	if parsed.Limit > 0 {
+		params["limit"] = parsed.Limit
+	}
+	var out any
+	if err := s.callDaemon(ctx, "site.requests", params, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
+func callListMail(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return listMail(ctx, s, "mail.list", args)
+}
+
+func callSearchMail(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return listMail(ctx, s, "mail.search", args)
+}
+
+func listMail(ctx context.Context, s *Server, method string, args json.RawMessage) (any, error) {
+	type p struct {
+		SiteID string `json:"siteId"`
+		Slug   string `json:"slug"`
+		Query  string `json:"query"`
+		Limit  int    `json:"limit"`
+	}
+	var parsed p
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &parsed); err != nil {
+			return nil, fmt.Errorf("invalid args: %w", err)
+		}
+	}
+	params := siteRefMap(s, parsed.SiteID, parsed.Slug)
+	if parsed.Query != "" {
+		params["query"] = parsed.Query
+	}
+	if parsed.Limit > 0 {
+		params["limit"] = parsed.Limit
+	}
+	var out any
+	if err := s.callDaemon(ctx, method, params, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
+func callReadMail(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	type p struct {
+		SiteID string `json:"siteId"`
+		Slug   string `json:"slug"`
+		ID     string `json:"id"`
+	}
+	var parsed p
+	if err := json.Unmarshal(args, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	if parsed.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	params := siteRefMap(s, parsed.SiteID, parsed.Slug)
+	params["id"] = parsed.ID
+	var out any
+	if err := s.callDaemon(ctx, "mail.get", params, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
 func callListSnapshots(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
 	params, err := siteRefArgs(s, args)
 	if err != nil {
@@ -476,6 +870,33 @@ func callWorktreeCreate(ctx context.Context, s *Server, args json.RawMessage) (a
 	return out, nil
 }
 
+func callImportSite(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	type p struct {
+		Path        string `json:"path"`
+		Name        string `json:"name"`
+		FilesDir    string `json:"filesDir"`
+		DisableAuto bool   `json:"disableAuto"`
+	}
+	var parsed p
+	if err := json.Unmarshal(args, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	if parsed.Path == "" {
+		return nil, errors.New("path is required")
+	}
+	params := map[string]any{
+		"path":        parsed.Path,
+		"name":        parsed.Name,
+		"filesDir":    parsed.FilesDir,
+		"disableAuto": parsed.DisableAuto,
+	}
+	var out any
+	if err := s.callDaemon(ctx, "site.import", params, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
 func callWorktreeDestroy(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
 	type p struct {
 		SiteID       string `json:"siteId"`
@@ -503,6 +924,45 @@ func callWorktreeDestroy(ctx context.Context, s *Server, args json.RawMessage) (
 	return out, nil
 }
 
+func callPurgeMail(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	type p struct {
+		SiteID string `json:"siteId"`
+		Slug   string `json:"slug"`
+		Query  string `json:"query"`
+		All    bool   `json:"all"`
+	}
+	var parsed p
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &parsed); err != nil {
+			return nil, fmt.Errorf("invalid args: %w", err)
+		}
+	}
+	params := siteRefMap(s, parsed.SiteID, parsed.Slug)
+	if parsed.Query != "" {
+		params["query"] = parsed.Query
+	}
+	if parsed.All {
+		params["all"] = true
+	}
+	var out any
+	if err := s.callDaemon(ctx, "mail.purge", params, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
+func callDBUILogin(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	params, err := siteRefArgs(s, args)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := s.callDaemon(ctx, "site.db_ui", params, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
 func callRunHook(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
 	type p struct {
 		SiteID string `json:"siteId"`
@@ -525,8 +985,75 @@ func callRunHook(ctx context.Context, s *Server, args json.RawMessage) (any, err
 	return out, nil
 }
 
+func callCreateSite(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	raw := map[string]any{}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &raw); err != nil {
+			return nil, fmt.Errorf("invalid args: %w", err)
+		}
+	}
+	var out any
+	if err := s.callDaemon(ctx, "site.create", raw, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
+func callImportDB(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return forwardSiteArgs(ctx, s, "site.import_db", args)
+}
+
+func callSearchReplace(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return forwardSiteArgs(ctx, s, "site.search_replace", args)
+}
+
+func callExportSite(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return forwardSiteArgs(ctx, s, "site.export", args)
+}
+
+func callChangeVersions(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return forwardSiteArgs(ctx, s, "site.versions", args)
+}
+
+func callMigrateEngine(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return forwardSiteArgs(ctx, s, "site.migrate_engine", args)
+}
+
+func callSetLAN(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return forwardSiteArgs(ctx, s, "site.lan", args)
+}
+
+func callSetSPX(ctx context.Context, s *Server, args json.RawMessage) (any, error) {
+	return forwardSiteArgs(ctx, s, "site.spx", args)
+}
+
 // ─── helpers ────────────────────────────────────────────────────────
 
+// forwardSiteArgs passes a tool's arguments to a site-targeted daemon
+// method unchanged apart from the site ref, which falls back to the
+// MCP scope as in siteRefMap. The daemon owns validation, so the
+// tool's schema and the method's params stay one shape.
+func forwardSiteArgs(ctx context.Context, s *Server, method string, args json.RawMessage) (any, error) {
+	params := map[string]any{}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &params); err != nil {
+			return nil, fmt.Errorf("invalid args: %w", err)
+		}
+	}
+	siteID, _ := params["siteId"].(string)
+	slug, _ := params["slug"].(string)
+	delete(params, "siteId")
+	delete(params, "slug")
+	for k, v := range siteRefMap(s, siteID, slug) {
+		params[k] = v
+	}
+	var out any
+	if err := s.callDaemon(ctx, method, params, &out); err != nil {
+		return nil, mapDaemonErr(err)
+	}
+	return out, nil
+}
+
 // siteRefArgs decodes a tools/call argument blob and returns the
 // site-ref params map ready to forward to the daemon. Honours the
 // MCP scope: when no explicit siteId/slug is set, fall back to the
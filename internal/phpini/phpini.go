@@ -0,0 +1,169 @@
+// Package phpini validates and renders a site's PHP overrides: the
+// php.ini directives layered over the shared ~/.locorum/config/php/php.ini,
+// and the wodby/php extensions switched on or off for that site alone.
+//
+// It is a leaf package — storage, config.yaml parsing, the GUI and the
+// container builders all call into it so the rules live in one place.
+package phpini
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// directiveRe matches a php.ini directive name: "memory_limit",
+// "opcache.validate_timestamps", "session.save_path".
+var directiveRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]*$`)
+
+// extensionRe matches a wodby/php extension name as it appears in
+// PHP_EXTENSIONS_ENABLE / PHP_EXTENSIONS_DISABLE.
+var extensionRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// plainValueRe matches values that render unquoted. Anything else is
+// written as a double-quoted string so ';', '=' and spaces survive.
+var plainValueRe = regexp.MustCompile(`^[A-Za-z0-9_.,:/+\-]*$`)
+
+// blockedDirectives cannot be set per site. Extensions load through
+// the image's PHP_EXTENSIONS_* switches, and the managed scan dir is
+// what makes the override file load in the first place.
+var blockedDirectives = map[string]string{
+	"extension":      "use the extension enable list instead",
+	"zend_extension": "use the extension enable list instead",
+	"extension_dir":  "the image's extension directory is fixed",
+}
+
+// ManagedExtensions are toggled by their own site settings (Xdebug,
+// SPX) and rejected in the enable/disable lists so the two controls
+// can't disagree.
+var ManagedExtensions = []string{"xdebug", "spx"}
+
+// ValidateSettings rejects directive names that are not php.ini shaped,
+// directives Locorum controls, and values that cannot be expressed on
+// one INI line.
+func ValidateSettings(settings map[string]string) error {
+	for _, k := range SortedKeys(settings) {
+		if !directiveRe.MatchString(k) {
+			return fmt.Errorf("php ini: %q is not a valid directive name", k)
+		}
+		if why, blocked := blockedDirectives[strings.ToLower(k)]; blocked {
+			return fmt.Errorf("php ini: %s cannot be set per site (%s)", k, why)
+		}
+		v := settings[k]
+		if strings.ContainsAny(v, "\"\r\n\x00") {
+			return fmt.Errorf("php ini: %s: value must be a single line without double quotes", k)
+		}
+	}
+	return nil
+}
+
+// ValidateExtensions checks the per-site enable and disable lists: each
+// name must look like an extension, appear once, not be in both lists,
+// and not be one of ManagedExtensions.
+func ValidateExtensions(enable, disable []string) error {
+	seen := map[string]string{}
+	for _, list := range []struct {
+		name  string
+		names []string
+	}{{"enable", enable}, {"disable", disable}} {
+		for _, ext := range list.names {
+			if !extensionRe.MatchString(ext) {
+				return fmt.Errorf("php extensions: %q is not a valid extension name", ext)
+			}
+			for _, m := range ManagedExtensions {
+				if ext == m {
+					return fmt.Errorf("php extensions: %s is controlled by its own site setting", ext)
+				}
+			}
+			if prev, dup := seen[ext]; dup {
+				if prev == list.name {
+					return fmt.Errorf("php extensions: %s listed twice", ext)
+				}
+				return fmt.Errorf("php extensions: %s is both enabled and disabled", ext)
+			}
+			seen[ext] = list.name
+		}
+	}
+	return nil
+}
+
+// ParseExtensionList splits a comma- or space-separated list, trimming
+// and lower-casing each name and dropping empties. Used by the GUI and
+// CLI inputs; config.yaml carries proper lists.
+func ParseExtensionList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	var out []string
+	for _, f := range fields {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// ParseDirectives reads "directive = value" lines as typed into the
+// GUI editor. Blank lines and ';' comments are skipped, surrounding
+// double quotes on a value are dropped, and a later line wins over an
+// earlier one. The result is not validated; pass it to ValidateSettings.
+func ParseDirectives(s string) (map[string]string, error) {
+	out := map[string]string{}
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"directive = value\"", i+1)
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if len(v) >= 2 && strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) {
+			v = v[1 : len(v)-1]
+		}
+		out[k] = v
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// FormatDirectives is the inverse of ParseDirectives: one unquoted
+// "directive = value" line per entry, sorted.
+func FormatDirectives(settings map[string]string) string {
+	var b strings.Builder
+	for _, k := range SortedKeys(settings) {
+		b.WriteString(k + " = " + settings[k] + "\n")
+	}
+	return b.String()
+}
+
+// Render produces the INI file body for settings, one directive per
+// line in sorted order so regenerating it is byte-identical.
+func Render(settings map[string]string) string {
+	var b strings.Builder
+	b.WriteString("; locorum-generated — DO NOT EDIT.\n")
+	b.WriteString("; Per-site php.ini overrides. Edit them in Locorum or .locorum/config.yaml.\n")
+	for _, k := range SortedKeys(settings) {
+		b.WriteString(k + " = " + renderValue(settings[k]) + "\n")
+	}
+	return b.String()
+}
+
+func renderValue(v string) string {
+	if v != "" && plainValueRe.MatchString(v) {
+		return v
+	}
+	return `"` + v + `"`
+}
+
+// SortedKeys returns the directive names of settings in sorted order.
+func SortedKeys(settings map[string]string) []string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
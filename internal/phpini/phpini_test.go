@@ -0,0 +1,93 @@
+package phpini
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateSettings(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings map[string]string
+		ok       bool
+	}{
+		{"empty", nil, true},
+		{"typical", map[string]string{"max_input_vars": "5000", "opcache.validate_timestamps": "0"}, true},
+		{"bad name", map[string]string{"max input vars": "1"}, false},
+		{"extension", map[string]string{"extension": "redis.so"}, false},
+		{"newline", map[string]string{"error_log": "/tmp/x\nauto_prepend_file=/evil"}, false},
+		{"quote", map[string]string{"date.timezone": `Europe/"London`}, false},
+	}
+	for _, tc := range cases {
+		if err := ValidateSettings(tc.settings); (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestValidateExtensions(t *testing.T) {
+	cases := []struct {
+		name            string
+		enable, disable []string
+		ok              bool
+	}{
+		{"empty", nil, nil, true},
+		{"typical", []string{"xhprof", "pcov"}, []string{"newrelic"}, true},
+		{"managed", []string{"xdebug"}, nil, false},
+		{"both lists", []string{"pcov"}, []string{"pcov"}, false},
+		{"duplicate", []string{"pcov", "pcov"}, nil, false},
+		{"bad name", []string{"pcov.so"}, nil, false},
+	}
+	for _, tc := range cases {
+		if err := ValidateExtensions(tc.enable, tc.disable); (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestParseExtensionList(t *testing.T) {
+	got := ParseExtensionList(" PCOV, xhprof  newrelic,,")
+	if want := []string{"pcov", "xhprof", "newrelic"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseExtensionList = %v, want %v", got, want)
+	}
+}
+
+func TestParseDirectives(t *testing.T) {
+	got, err := ParseDirectives("; comment\n\nmax_input_vars = 5000\nsendmail_path=\"/usr/sbin/sendmail -t\"\nmax_input_vars = 6000\n")
+	if err != nil {
+		t.Fatalf("ParseDirectives: %v", err)
+	}
+	want := map[string]string{"max_input_vars": "6000", "sendmail_path": "/usr/sbin/sendmail -t"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDirectives = %v, want %v", got, want)
+	}
+	if back, _ := ParseDirectives(FormatDirectives(want)); !reflect.DeepEqual(back, want) {
+		t.Errorf("FormatDirectives did not round-trip: %v", back)
+	}
+	if _, err := ParseDirectives("memory_limit 512M"); err == nil {
+		t.Error("expected a line without '=' to be rejected")
+	}
+	if got, _ := ParseDirectives("  \n; only a comment\n"); got != nil {
+		t.Errorf("ParseDirectives of nothing = %v, want nil", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	out := Render(map[string]string{
+		"opcache.validate_timestamps": "0",
+		"max_input_vars":              "5000",
+		"error_log":                   "",
+		"sendmail_path":               "/usr/sbin/sendmail -t -i",
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	want := []string{
+		`error_log = ""`,
+		`max_input_vars = 5000`,
+		`opcache.validate_timestamps = 0`,
+		`sendmail_path = "/usr/sbin/sendmail -t -i"`,
+	}
+	if got := lines[len(lines)-len(want):]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Render directives = %q, want %q", got, want)
+	}
+}
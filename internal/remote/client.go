@@ -0,0 +1,346 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ErrHostKeyMismatch is returned when the server presents a key that
+// differs from the pinned fingerprint or from ~/.ssh/known_hosts.
+var ErrHostKeyMismatch = errors.New("remote host key does not match")
+
+// stderrCap bounds how much remote stderr is kept for error messages.
+const stderrCap = 8 << 10
+
+// DialOptions tunes Dial. The zero value uses ~/.ssh/known_hosts, the
+// user's default keys and SSH_AUTH_SOCK.
+type DialOptions struct {
+	// KnownHostsPath overrides ~/.ssh/known_hosts. Set to "-" to skip
+	// known_hosts entirely (tests).
+	KnownHostsPath string
+
+	// Timeout bounds the TCP connect and handshake. Default 15s.
+	Timeout time.Duration
+}
+
+// Client is an open SSH connection to a Remote.
+type Client struct {
+	conn *ssh.Client
+
+	// HostKey is the SHA256 fingerprint the server presented. Callers
+	// persist it onto Remote.HostKey after a first successful connect.
+	HostKey string
+}
+
+// Dial connects and authenticates to r. Host keys are checked against
+// r.HostKey when pinned, otherwise against known_hosts; a host known to
+// neither is accepted (trust on first use) and its fingerprint returned
+// in Client.HostKey for the caller to pin.
+func Dial(ctx context.Context, r Remote, opts DialOptions) (*Client, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+
+	auth, closeAgent := authMethods(r)
+	defer closeAgent()
+	if len(auth) == 0 {
+		return nil, errors.New("no SSH credentials: set a key path or password, or run an ssh-agent")
+	}
+
+	var seen string
+	cfg := &ssh.ClientConfig{
+		User:            r.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback(r.HostKey, knownHostsPath(opts.KnownHostsPath), &seen),
+		Timeout:         timeout,
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var d net.Dialer
+	nc, err := d.DialContext(dctx, "tcp", r.Addr())
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", r.Addr(), err)
+	}
+	// NewClientConn has no context; a deadline keeps a stalled
+	// handshake from hanging the pull.
+	_ = nc.SetDeadline(time.Now().Add(timeout))
+	c, chans, reqs, err := ssh.NewClientConn(nc, r.Addr(), cfg)
+	if err != nil {
+		_ = nc.Close()
+		return nil, fmt.Errorf("ssh handshake with %s: %w", r.Addr(), err)
+	}
+	_ = nc.SetDeadline(time.Time{})
+	return &Client{conn: ssh.NewClient(c, chans, reqs), HostKey: seen}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Run executes cmd and returns its stdout. Use Stream for anything
+// larger than a few kilobytes.
+func (c *Client) Run(ctx context.Context, cmd string) (string, error) {
+	rc, err := c.Stream(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+	out, readErr := io.ReadAll(rc)
+	if err := rc.Close(); err != nil {
+		return string(out), err
+	}
+	return string(out), readErr
+}
+
+// RunStdin executes cmd with stdin attached and returns its (capped)
+// stdout. Used to push a dump into a remote import.
+func (c *Client) RunStdin(ctx context.Context, cmd string, stdin io.Reader) (string, error) {
+	sess, err := c.conn.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("ssh session: %w", err)
+	}
+	defer sess.Close()
+	stdout := &cappedBuffer{max: stderrCap}
+	stderr := &cappedBuffer{max: stderrCap}
+	sess.Stdin, sess.Stdout, sess.Stderr = stdin, stdout, stderr
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = sess.Close()
+		case <-done:
+		}
+	}()
+
+	if err := sess.Run(cmd); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return stdout.String(), ctxErr
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("remote command failed: %w: %s", err, msg)
+		}
+		return stdout.String(), fmt.Errorf("remote command failed: %w", err)
+	}
+	return stdout.String(), nil
+}
+
+// Stream starts cmd and returns its stdout. Close waits for the command
+// to exit and reports a non-zero status together with the tail of its
+// stderr, so a reader that hit EOF early still learns why. Cancelling
+// ctx tears the session down.
+func (c *Client) Stream(ctx context.Context, cmd string) (io.ReadCloser, error) {
+	sess, err := c.conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("ssh session: %w", err)
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		_ = sess.Close()
+		return nil, fmt.Errorf("ssh stdout: %w", err)
+	}
+	stderr := &cappedBuffer{max: stderrCap}
+	sess.Stderr = stderr
+	if err := sess.Start(cmd); err != nil {
+		_ = sess.Close()
+		return nil, fmt.Errorf("starting remote command: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = sess.Close()
+		case <-done:
+		}
+	}()
+	return &stream{sess: sess, stdout: stdout, stderr: stderr, done: done, ctx: ctx}, nil
+}
+
+type stream struct {
+	sess   *ssh.Session
+	stdout io.Reader
+	stderr *cappedBuffer
+	done   chan struct{}
+	ctx    context.Context
+	once   sync.Once
+	err    error
+}
+
+func (s *stream) Read(p []byte) (int, error) { return s.stdout.Read(p) }
+
+func (s *stream) Close() error {
+	s.once.Do(func() {
+		// Drain so Wait sees the exit status rather than blocking on a
+		// full channel window.
+		_, _ = io.Copy(io.Discard, s.stdout)
+		err := s.sess.Wait()
+		close(s.done)
+		_ = s.sess.Close()
+		if ctxErr := s.ctx.Err(); ctxErr != nil {
+			s.err = ctxErr
+			return
+		}
+		if err != nil {
+			if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+				s.err = fmt.Errorf("remote command failed: %w: %s", err, msg)
+			} else {
+				s.err = fmt.Errorf("remote command failed: %w", err)
+			}
+		}
+	})
+	return s.err
+}
+
+// authMethods assembles agent, key-file and password auth in that
+// order. The returned func closes the agent socket once the handshake
+// is done.
+func authMethods(r Remote) ([]ssh.AuthMethod, func()) {
+	var (
+		methods []ssh.AuthMethod
+		closer  = func() {}
+	)
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" && r.KeyPath == "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			closer = func() { _ = conn.Close() }
+		}
+	}
+
+	var signers []ssh.Signer
+	if r.KeyPath != "" {
+		if s, err := loadSigner(r.KeyPath, r.Password); err == nil {
+			signers = append(signers, s)
+		}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			if s, err := loadSigner(filepath.Join(home, ".ssh", name), r.Password); err == nil {
+				signers = append(signers, s)
+			}
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	if r.Password != "" {
+		pw := r.Password
+		methods = append(methods,
+			ssh.Password(pw),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = pw
+				}
+				return answers, nil
+			}),
+		)
+	}
+	return methods, closer
+}
+
+// loadSigner reads a private key, using passphrase when the key is
+// encrypted.
+func loadSigner(keyPath, passphrase string) (ssh.Signer, error) {
+	pem, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ssh.ParsePrivateKey(pem)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) && passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+	}
+	return s, err
+}
+
+func knownHostsPath(override string) string {
+	if override == "-" {
+		return ""
+	}
+	if override != "" {
+		return override
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// hostKeyCallback verifies the server key and records its fingerprint
+// in *seen. A pinned fingerprint wins; otherwise known_hosts is
+// consulted and only a conflicting entry is fatal.
+func hostKeyCallback(pinned, khPath string, seen *string) ssh.HostKeyCallback {
+	var kh ssh.HostKeyCallback
+	if khPath != "" {
+		if cb, err := knownhosts.New(khPath); err == nil {
+			kh = cb
+		}
+	}
+	return func(hostname string, addr net.Addr, key ssh.PublicKey) error {
+		fp := ssh.FingerprintSHA256(key)
+		*seen = fp
+		if pinned != "" {
+			if fp != pinned {
+				return fmt.Errorf("%w: got %s, pinned %s", ErrHostKeyMismatch, fp, pinned)
+			}
+			return nil
+		}
+		if kh == nil {
+			return nil
+		}
+		err := kh(hostname, addr, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return nil
+			}
+			return fmt.Errorf("%w: %s conflicts with known_hosts", ErrHostKeyMismatch, fp)
+		}
+		return err
+	}
+}
+
+// cappedBuffer keeps the last max bytes written to it.
+type cappedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Write(p)
+	if over := b.buf.Len() - b.max; over > 0 {
+		b.buf.Next(over)
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
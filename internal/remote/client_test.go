@@ -0,0 +1,164 @@
+package remote
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeSSHD is a minimal in-process SSH server that answers exec
+// requests from a fixed table: stdout, stderr and exit status.
+type fakeSSHD struct {
+	addr   string
+	hostFP string
+}
+
+type fakeCmd struct {
+	stdout, stderr string
+	status         uint32
+}
+
+func startFakeSSHD(t *testing.T, password string, cmds map[string]fakeCmd) fakeSSHD {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, pw []byte) (*ssh.Permissions, error) {
+			if string(pw) == password {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeConn(nc, cfg, cmds)
+		}
+	}()
+	return fakeSSHD{addr: ln.Addr().String(), hostFP: ssh.FingerprintSHA256(signer.PublicKey())}
+}
+
+func serveFakeConn(nc net.Conn, cfg *ssh.ServerConfig, cmds map[string]fakeCmd) {
+	_, chans, reqs, err := ssh.NewServerConn(nc, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nch := range chans {
+		ch, chReqs, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer ch.Close()
+			for req := range chReqs {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				_ = req.Reply(true, nil)
+				cmd := string(req.Payload[4:])
+				c, ok := cmds[cmd]
+				if !ok {
+					c = fakeCmd{stderr: "command not found", status: 127}
+				}
+				if c.stdout == "<stdin>" {
+					// Echo stdin back, as `cat` would.
+					_, _ = io.Copy(ch, ch)
+				} else {
+					_, _ = ch.Write([]byte(c.stdout))
+				}
+				_, _ = ch.Stderr().Write([]byte(c.stderr))
+				status := make([]byte, 4)
+				binary.BigEndian.PutUint32(status, c.status)
+				_, _ = ch.SendRequest("exit-status", false, status)
+				return
+			}
+		}()
+	}
+}
+
+func fakeRemote(t *testing.T, d fakeSSHD, password string) Remote {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(d.addr)
+	p, _ := strconv.Atoi(port)
+	r := validRemote()
+	r.Host, r.Port, r.Password = host, p, password
+	r.KeyPath = "/nonexistent" // keep the test away from the user's real keys and agent
+	return r
+}
+
+func TestClient_RunAndPin(t *testing.T) {
+	d := startFakeSSHD(t, "hunter22", map[string]fakeCmd{
+		"echo hi": {stdout: "hi\n"},
+		"false":   {stderr: "it broke", status: 1},
+		"cat":     {stdout: "<stdin>"},
+	})
+	r := fakeRemote(t, d, "hunter22")
+	ctx := context.Background()
+
+	c, err := Dial(ctx, r, DialOptions{KnownHostsPath: "-"})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer c.Close()
+	if c.HostKey != d.hostFP {
+		t.Errorf("HostKey = %q, want %q", c.HostKey, d.hostFP)
+	}
+
+	out, err := c.Run(ctx, "echo hi")
+	if err != nil || out != "hi\n" {
+		t.Errorf("Run = %q, %v", out, err)
+	}
+	if out, err := c.RunStdin(ctx, "cat", strings.NewReader("dump")); err != nil || out != "dump" {
+		t.Errorf("RunStdin = %q, %v", out, err)
+	}
+	if _, err := c.Run(ctx, "false"); err == nil || !strings.Contains(err.Error(), "it broke") {
+		t.Errorf("failing command error = %v, want stderr in message", err)
+	}
+}
+
+func TestDial_PinnedHostKeyMismatch(t *testing.T) {
+	d := startFakeSSHD(t, "hunter22", nil)
+	r := fakeRemote(t, d, "hunter22")
+	r.HostKey = "SHA256:not-the-key"
+	_, err := Dial(context.Background(), r, DialOptions{KnownHostsPath: "-"})
+	if !errors.Is(err, ErrHostKeyMismatch) {
+		t.Errorf("Dial = %v, want ErrHostKeyMismatch", err)
+	}
+}
+
+func TestDial_BadPassword(t *testing.T) {
+	d := startFakeSSHD(t, "hunter22", nil)
+	r := fakeRemote(t, d, "wrong-password")
+	if _, err := Dial(context.Background(), r, DialOptions{KnownHostsPath: "-"}); err == nil {
+		t.Error("Dial with wrong password succeeded")
+	}
+}
@@ -0,0 +1,166 @@
+// Package remote holds the per-site SSH remotes used by `site pull` and
+// `site push`, and the client that talks to them. A remote names a
+// WordPress install on another host ("staging", "production"); pulling
+// from it streams a database dump and the uploads directory over a
+// single SSH connection, pushing pipes a local dump into its database.
+//
+// Nothing here touches Docker or the local site — the SiteManager feeds
+// the streams into the same import pipeline ImportDB uses.
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrRemoteInvalid is wrapped by every Validate failure so callers can
+// map it to a user-facing "bad input" error.
+var ErrRemoteInvalid = errors.New("invalid remote")
+
+// DefaultPort is used when Remote.Port is zero.
+const DefaultPort = 22
+
+// Remote is one SSH-reachable WordPress install attached to a site.
+//
+// Password doubles as the passphrase for KeyPath when the key is
+// encrypted. It is stored alongside the site's DB password and
+// registered with the secrets registry so it never reaches a log line.
+// HostKey is the server's SHA256 fingerprint, pinned on first connect.
+// Production remotes can be pulled from but never pushed to.
+type Remote struct {
+	ID         int64  `json:"id"`
+	SiteID     string `json:"siteId"`
+	Name       string `json:"name"`
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	User       string `json:"user"`
+	Path       string `json:"path"`
+	KeyPath    string `json:"keyPath,omitempty"`
+	Password   string `json:"-"`
+	HostKey    string `json:"hostKey,omitempty"`
+	DBCommand  string `json:"dbCommand,omitempty"`
+	Production bool   `json:"production"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Validate checks the fields a pull can't work without. Host reachability
+// is not checked — that happens on connect.
+func (r Remote) Validate() error {
+	if r.SiteID == "" {
+		return fmt.Errorf("%w: site id is required", ErrRemoteInvalid)
+	}
+	if !nameRe.MatchString(r.Name) {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits, '-' or '_'", ErrRemoteInvalid, r.Name)
+	}
+	if r.Host == "" || strings.ContainsAny(r.Host, " /@") {
+		return fmt.Errorf("%w: host %q is not a hostname or IP", ErrRemoteInvalid, r.Host)
+	}
+	if r.Port < 0 || r.Port > 65535 {
+		return fmt.Errorf("%w: port %d out of range", ErrRemoteInvalid, r.Port)
+	}
+	if r.User == "" {
+		return fmt.Errorf("%w: user is required", ErrRemoteInvalid)
+	}
+	if !path.IsAbs(r.Path) {
+		return fmt.Errorf("%w: path %q must be the absolute WordPress root on the remote", ErrRemoteInvalid, r.Path)
+	}
+	return nil
+}
+
+// Addr returns host:port, defaulting the port.
+func (r Remote) Addr() string {
+	port := r.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	return net.JoinHostPort(r.Host, strconv.Itoa(port))
+}
+
+// String renders user@host:path for logs and CLI output.
+func (r Remote) String() string {
+	return r.User + "@" + r.Addr() + ":" + r.Path
+}
+
+// DumpCommand is the remote command whose stdout is the database dump.
+// The default pipes wp-cli through gzip so a large database crosses the
+// wire compressed; DBCommand overrides it for hosts without wp-cli
+// (e.g. a mysqldump invocation). Either plain or gzipped output is fine —
+// the reader sniffs the stream.
+func (r Remote) DumpCommand() string {
+	if r.DBCommand != "" {
+		return r.DBCommand
+	}
+	return r.wp("db", "export", "-", "--add-drop-table") + " | gzip -c"
+}
+
+// UploadsCommand streams wp-content/uploads as a gzipped tar whose
+// entries are rooted at "uploads/".
+func (r Remote) UploadsCommand() string {
+	return "tar -C " + ShellQuote(path.Join(r.Path, "wp-content")) + " -czf - uploads"
+}
+
+// BackupDir is where push leaves its pre-push database backups, relative
+// to the remote user's home directory.
+const BackupDir = ".locorum-backups"
+
+// BackupCommand dumps the remote database into BackupDir under name and
+// returns the command plus the path of the file it leaves behind. The
+// default writes through `wp db export` so a failed export fails the
+// command instead of leaving a truncated gzip; a DBCommand override runs
+// under bash with pipefail, so a failing stage of a pipeline such as
+// `mysqldump | gzip` fails it too. Either way the command fails unless
+// the backup file is non-empty.
+func (r Remote) BackupCommand(name string) (cmd, file string) {
+	mkdir := "mkdir -p " + BackupDir + " && chmod 700 " + BackupDir + " && "
+	if r.DBCommand != "" {
+		file = path.Join(BackupDir, name+".dump")
+		dump := "set -o pipefail; (" + r.DBCommand + ") > " + ShellQuote(file)
+		return mkdir + "bash -c " + ShellQuote(dump) + " && test -s " + ShellQuote(file), file
+	}
+	sqlFile := path.Join(BackupDir, name+".sql")
+	file = sqlFile + ".gz"
+	return mkdir + r.wp("db", "export", ShellQuote(sqlFile), "--add-drop-table") +
+		" && gzip -f " + ShellQuote(sqlFile) + " && test -s " + ShellQuote(file), file
+}
+
+// ImportCommand reads a gzipped SQL dump on stdin into the remote
+// database. Push requires wp-cli on the remote; there is no override.
+func (r Remote) ImportCommand() string {
+	return "gunzip -c | " + r.wp("db", "import", "-")
+}
+
+// OptionCommand prints a single WordPress option.
+func (r Remote) OptionCommand(key string) string {
+	return r.wp("option", "get", ShellQuote(key))
+}
+
+// TablePrefixCommand prints $table_prefix from the remote wp-config.php.
+func (r Remote) TablePrefixCommand() string {
+	return r.wp("config", "get", "table_prefix")
+}
+
+// SearchReplaceCommand rewrites from → to across every table, with the
+// same flags the local search-replace uses.
+func (r Remote) SearchReplaceCommand(from, to string) string {
+	return r.wp("search-replace", ShellQuote(from), ShellQuote(to), "--all-tables", "--skip-columns=guid")
+}
+
+// wp builds a wp-cli invocation pinned to the remote WordPress root.
+// args must already be shell-safe.
+func (r Remote) wp(args ...string) string {
+	return "wp " + strings.Join(args, " ") + " --path=" + ShellQuote(r.Path)
+}
+
+// ShellQuote wraps s in single quotes for a POSIX shell, escaping any
+// embedded single quotes.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
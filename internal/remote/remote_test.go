@@ -0,0 +1,79 @@
+package remote
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func validRemote() Remote {
+	return Remote{SiteID: "s1", Name: "staging", Host: "example.com", User: "deploy", Path: "/srv/www/site"}
+}
+
+func TestValidate(t *testing.T) {
+	if err := validRemote().Validate(); err != nil {
+		t.Fatalf("valid remote rejected: %v", err)
+	}
+	cases := []struct {
+		name string
+		mut  func(*Remote)
+	}{
+		{"empty name", func(r *Remote) { r.Name = "" }},
+		{"uppercase name", func(r *Remote) { r.Name = "Staging" }},
+		{"host with user", func(r *Remote) { r.Host = "deploy@example.com" }},
+		{"bad port", func(r *Remote) { r.Port = 70000 }},
+		{"no user", func(r *Remote) { r.User = "" }},
+		{"relative path", func(r *Remote) { r.Path = "www" }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := validRemote()
+			tc.mut(&r)
+			if err := r.Validate(); !errors.Is(err, ErrRemoteInvalid) {
+				t.Errorf("Validate = %v, want ErrRemoteInvalid", err)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	r := validRemote()
+	r.Path = "/srv/it's here"
+	if got, want := r.DumpCommand(), `wp db export - --add-drop-table --path='/srv/it'\''s here' | gzip -c`; got != want {
+		t.Errorf("DumpCommand = %q, want %q", got, want)
+	}
+	if got, want := r.UploadsCommand(), `tar -C '/srv/it'\''s here/wp-content' -czf - uploads`; got != want {
+		t.Errorf("UploadsCommand = %q, want %q", got, want)
+	}
+	r.DBCommand = "mysqldump wp"
+	if got := r.DumpCommand(); got != "mysqldump wp" {
+		t.Errorf("DBCommand override ignored: %q", got)
+	}
+	if got := r.Addr(); got != "example.com:22" {
+		t.Errorf("Addr = %q", got)
+	}
+}
+
+func TestPushCommands(t *testing.T) {
+	r := validRemote()
+	cmd, file := r.BackupCommand("shop-20260513")
+	if file != ".locorum-backups/shop-20260513.sql.gz" {
+		t.Errorf("backup file = %q", file)
+	}
+	if want := "wp db export '.locorum-backups/shop-20260513.sql' --add-drop-table --path='/srv/www/site' && gzip -f '.locorum-backups/shop-20260513.sql'" +
+		" && test -s '.locorum-backups/shop-20260513.sql.gz'"; !strings.HasSuffix(cmd, want) {
+		t.Errorf("backup cmd = %q, want suffix %q", cmd, want)
+	}
+
+	r.DBCommand = "mysqldump wp | gzip"
+	cmd, file = r.BackupCommand("shop")
+	want := `bash -c 'set -o pipefail; (mysqldump wp | gzip) > '\''.locorum-backups/shop.dump'\''' && test -s '.locorum-backups/shop.dump'`
+	if file != ".locorum-backups/shop.dump" || !strings.HasSuffix(cmd, want) {
+		t.Errorf("override backup = %q, %q; want suffix %q", cmd, file, want)
+	}
+
+	if got, want := r.SearchReplaceCommand("https://shop.localhost", "https://it's.example"),
+		`wp search-replace 'https://shop.localhost' 'https://it'\''s.example' --all-tables --skip-columns=guid --path='/srv/www/site'`; got != want {
+		t.Errorf("SearchReplaceCommand = %q, want %q", got, want)
+	}
+}
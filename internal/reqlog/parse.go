@@ -0,0 +1,141 @@
+// Package reqlog turns the global router's JSON access log into
+// per-site request records and keeps the most recent ones in memory for
+// the request inspector (GUI Requests tab, site.requests, MCP).
+//
+// Both router backends are understood. Traefik names each entry's
+// router ("locorum-<slug>@file"); Caddy routes every site host to a
+// logger named "locorum-<slug>". Either way the slug is recovered from
+// the name, so no hostname lookup is needed.
+package reqlog
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+)
+
+// Entry is one request the router handled for a site.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Host       string    `json:"host"`
+	Path       string    `json:"path"` // request URI, query string included
+	Status     int       `json:"status"`
+	DurationMS float64   `json:"durationMs"`
+	Size       int64     `json:"size"`
+	ClientIP   string    `json:"clientIp,omitempty"`
+}
+
+// traefikLine is the subset of Traefik's JSON access log fields we read.
+type traefikLine struct {
+	RouterName            string `json:"RouterName"`
+	StartUTC              string `json:"StartUTC"`
+	RequestMethod         string `json:"RequestMethod"`
+	RequestHost           string `json:"RequestHost"`
+	RequestPath           string `json:"RequestPath"`
+	DownstreamStatus      int    `json:"DownstreamStatus"`
+	DownstreamContentSize int64  `json:"DownstreamContentSize"`
+	Duration              int64  `json:"Duration"` // nanoseconds
+	ClientHost            string `json:"ClientHost"`
+}
+
+// caddyLine is the subset of a Caddy "handled request" log entry we read.
+type caddyLine struct {
+	Logger   string  `json:"logger"`
+	TS       float64 `json:"ts"`
+	Duration float64 `json:"duration"` // seconds
+	Size     int64   `json:"size"`
+	Status   int     `json:"status"`
+	Request  struct {
+		RemoteIP string `json:"remote_ip"`
+		Method   string `json:"method"`
+		Host     string `json:"host"`
+		URI      string `json:"uri"`
+	} `json:"request"`
+}
+
+// caddyAccessLogger prefixes the logger name of every Caddy access log
+// entry; the remainder is the name assigned in the server's logger_names.
+const caddyAccessLogger = "http.log.access."
+
+// Parse decodes one router log line. ok is false for lines that are not
+// site access entries: the router's own logs, service routes (mail,
+// adminer), the dashboard, and requests no site route matched.
+func Parse(line string) (slug string, e Entry, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return "", Entry{}, false
+	}
+	var probe struct {
+		RouterName *string `json:"RouterName"`
+		Logger     string  `json:"logger"`
+	}
+	if err := json.Unmarshal([]byte(line), &probe); err != nil {
+		return "", Entry{}, false
+	}
+	switch {
+	case probe.RouterName != nil:
+		return parseTraefik(line)
+	case strings.HasPrefix(probe.Logger, caddyAccessLogger):
+		return parseCaddy(line)
+	}
+	return "", Entry{}, false
+}
+
+func parseTraefik(line string) (string, Entry, bool) {
+	var l traefikLine
+	if err := json.Unmarshal([]byte(line), &l); err != nil {
+		return "", Entry{}, false
+	}
+	// Router names carry the provider: "locorum-shop@file".
+	name, _, _ := strings.Cut(l.RouterName, "@")
+	slug, ok := slugFromRouter(name)
+	if !ok {
+		return "", Entry{}, false
+	}
+	t, _ := time.Parse(time.RFC3339Nano, l.StartUTC)
+	return slug, Entry{
+		Time:       t,
+		Method:     l.RequestMethod,
+		Host:       l.RequestHost,
+		Path:       l.RequestPath,
+		Status:     l.DownstreamStatus,
+		DurationMS: float64(l.Duration) / float64(time.Millisecond),
+		Size:       l.DownstreamContentSize,
+		ClientIP:   l.ClientHost,
+	}, true
+}
+
+func parseCaddy(line string) (string, Entry, bool) {
+	var l caddyLine
+	if err := json.Unmarshal([]byte(line), &l); err != nil {
+		return "", Entry{}, false
+	}
+	slug, ok := slugFromRouter(strings.TrimPrefix(l.Logger, caddyAccessLogger))
+	if !ok {
+		return "", Entry{}, false
+	}
+	sec, frac := math.Modf(l.TS)
+	return slug, Entry{
+		Time:       time.Unix(int64(sec), int64(frac*1e9)).UTC(),
+		Method:     l.Request.Method,
+		Host:       l.Request.Host,
+		Path:       l.Request.URI,
+		Status:     l.Status,
+		DurationMS: l.Duration * 1000,
+		Size:       l.Size,
+		ClientIP:   l.Request.RemoteIP,
+	}, true
+}
+
+// slugFromRouter maps a site route name ("locorum-<slug>") to its slug.
+// Service routes ("locorum-svc-<name>") and the dashboard route
+// ("locorum-api") are not sites.
+func slugFromRouter(name string) (string, bool) {
+	slug, ok := strings.CutPrefix(name, "locorum-")
+	if !ok || slug == "" || slug == "api" || strings.HasPrefix(slug, "svc-") {
+		return "", false
+	}
+	return slug, true
+}
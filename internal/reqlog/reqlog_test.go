@@ -0,0 +1,104 @@
+package reqlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTraefik(t *testing.T) {
+	line := `{"ClientHost":"172.18.0.1","DownstreamContentSize":512,"DownstreamStatus":404,"Duration":2500000,` +
+		`"RequestHost":"shop.localhost","RequestMethod":"GET","RequestPath":"/missing?x=1","RouterName":"locorum-shop@file",` +
+		`"StartUTC":"2026-10-17T09:30:00.123456789Z","level":"info","msg":"","time":"2026-10-17T09:30:00Z"}`
+	slug, e, ok := Parse(line)
+	if !ok || slug != "shop" {
+		t.Fatalf("Parse = (%q, %v), want shop", slug, ok)
+	}
+	want := Entry{
+		Time:       time.Date(2026, 10, 17, 9, 30, 0, 123456789, time.UTC),
+		Method:     "GET",
+		Host:       "shop.localhost",
+		Path:       "/missing?x=1",
+		Status:     404,
+		DurationMS: 2.5,
+		Size:       512,
+		ClientIP:   "172.18.0.1",
+	}
+	if e != want {
+		t.Errorf("entry = %+v\nwant    %+v", e, want)
+	}
+}
+
+func TestParseCaddy(t *testing.T) {
+	line := `{"level":"info","ts":1791711000.5,"logger":"http.log.access.locorum-my-blog","msg":"handled request",` +
+		`"request":{"remote_ip":"172.18.0.1","proto":"HTTP/2.0","method":"POST","host":"my-blog.localhost","uri":"/wp-login.php"},` +
+		`"duration":0.0125,"size":10,"status":302}`
+	slug, e, ok := Parse(line)
+	if !ok || slug != "my-blog" {
+		t.Fatalf("Parse = (%q, %v), want my-blog", slug, ok)
+	}
+	if e.Method != "POST" || e.Path != "/wp-login.php" || e.Status != 302 || e.DurationMS != 12.5 {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Time.UnixMilli() != 1791711000500 {
+		t.Errorf("time = %v", e.Time)
+	}
+}
+
+func TestParseSkipsNonSiteLines(t *testing.T) {
+	for _, line := range []string{
+		"",
+		`time="2026-10-17T09:30:00Z" level=info msg="Configuration loaded"`,
+		`{"RouterName":"locorum-svc-mail@file","DownstreamStatus":200}`,
+		`{"RouterName":"locorum-api@file","DownstreamStatus":200}`,
+		`{"RouterName":"web-to-websecure@internal","DownstreamStatus":301}`,
+		`{"RouterName":"","DownstreamStatus":404}`,
+		`{"level":"info","logger":"tls","msg":"certificate loaded"}`,
+		`{"broken json`,
+	} {
+		if slug, _, ok := Parse(line); ok {
+			t.Errorf("Parse(%q) = %q, want skipped", line, slug)
+		}
+	}
+}
+
+func TestStoreQuery(t *testing.T) {
+	s := NewStore(3)
+	for i, st := range []int{200, 404, 301, 500, 404} {
+		s.Add("shop", Entry{Status: st, Path: "/p" + string(rune('a'+i))})
+	}
+	s.Add("blog", Entry{Status: 200})
+
+	all, _ := s.Query("shop", Filter{})
+	if len(all) != 3 || all[0].Path != "/pe" || all[2].Path != "/pc" {
+		t.Fatalf("ring should keep the newest 3, newest first: %+v", all)
+	}
+
+	cases := []struct {
+		f    Filter
+		want int
+	}{
+		{Filter{Status: "404"}, 1},
+		{Filter{Status: "5xx"}, 1},
+		{Filter{Status: "3XX"}, 1},
+		{Filter{Path: "/pd"}, 1},
+		{Filter{Limit: 2}, 2},
+	}
+	for _, tc := range cases {
+		got, err := s.Query("shop", tc.f)
+		if err != nil || len(got) != tc.want {
+			t.Errorf("Query(%+v) = %d entries, %v; want %d", tc.f, len(got), err, tc.want)
+		}
+	}
+
+	if got, _ := s.Query("nope", Filter{}); got == nil || len(got) != 0 {
+		t.Errorf("unknown site should give an empty, non-nil slice")
+	}
+	if _, err := s.Query("shop", Filter{Status: "teapot"}); err == nil {
+		t.Error("bad status filter: want error")
+	}
+
+	s.Clear("shop")
+	if got, _ := s.Query("shop", Filter{}); len(got) != 0 {
+		t.Errorf("Clear left %d entries", len(got))
+	}
+}
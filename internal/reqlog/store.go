@@ -0,0 +1,132 @@
+package reqlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultPerSite is how many requests the Store keeps per site. Enough
+// to spot a redirect loop or a 404 storm; old entries are still in the
+// router container's log.
+const DefaultPerSite = 1000
+
+// Filter narrows a Query. The zero value matches everything.
+type Filter struct {
+	// Status is an exact code ("404") or a class ("4xx").
+	Status string
+	// Path matches entries whose path contains it.
+	Path string
+	// Limit caps the result; 0 means no cap.
+	Limit int
+}
+
+// ValidateStatus reports whether s is usable as Filter.Status.
+func ValidateStatus(s string) error {
+	_, _, err := statusRange(s)
+	return err
+}
+
+// statusRange turns a status filter into an inclusive code range.
+func statusRange(s string) (lo, hi int, err error) {
+	if s == "" {
+		return 0, 999, nil
+	}
+	if len(s) == 3 && strings.HasSuffix(strings.ToLower(s), "xx") && s[0] >= '1' && s[0] <= '5' {
+		lo = int(s[0]-'0') * 100
+		return lo, lo + 99, nil
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil || code < 100 || code > 599 {
+		return 0, 0, fmt.Errorf("invalid status filter %q (want a code like 404 or a class like 5xx)", s)
+	}
+	return code, code, nil
+}
+
+// Store holds the most recent requests per site in fixed-size rings.
+// Safe for concurrent use.
+type Store struct {
+	perSite int
+
+	mu    sync.Mutex
+	rings map[string]*ring
+}
+
+// NewStore returns a Store keeping perSite entries per site; perSite
+// <= 0 selects DefaultPerSite.
+func NewStore(perSite int) *Store {
+	if perSite <= 0 {
+		perSite = DefaultPerSite
+	}
+	return &Store{perSite: perSite, rings: map[string]*ring{}}
+}
+
+// Add records e for slug, evicting the site's oldest entry when full.
+func (s *Store) Add(slug string, e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.rings[slug]
+	if r == nil {
+		r = &ring{buf: make([]Entry, 0, s.perSite)}
+		s.rings[slug] = r
+	}
+	r.add(e)
+}
+
+// Query returns slug's entries matching f, newest first.
+func (s *Store) Query(slug string, f Filter) ([]Entry, error) {
+	lo, hi, err := statusRange(f.Status)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []Entry{}
+	r := s.rings[slug]
+	if r == nil {
+		return out, nil
+	}
+	for i := len(r.buf) - 1; i >= 0; i-- {
+		e := r.at(i)
+		if e.Status < lo || e.Status > hi {
+			continue
+		}
+		if f.Path != "" && !strings.Contains(e.Path, f.Path) {
+			continue
+		}
+		out = append(out, e)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Clear drops everything recorded for slug.
+func (s *Store) Clear(slug string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rings, slug)
+}
+
+// ring is a fixed-capacity FIFO. buf grows to cap, after which next
+// marks the oldest slot.
+type ring struct {
+	buf  []Entry
+	next int
+}
+
+func (r *ring) add(e Entry) {
+	if len(r.buf) < cap(r.buf) {
+		r.buf = append(r.buf, e)
+		return
+	}
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+}
+
+// at returns the i-th oldest entry.
+func (r *ring) at(i int) Entry {
+	return r.buf[(r.next+i)%len(r.buf)]
+}
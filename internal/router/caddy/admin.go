@@ -0,0 +1,62 @@
+package caddy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// AdminAddr is where Caddy's admin API listens inside the router
+// container. It is never published to the host.
+const AdminAddr = "localhost:2019"
+
+// execer runs a command in a container and returns its combined output.
+// *docker.Docker satisfies it.
+type execer interface {
+	ExecInContainer(ctx context.Context, containerName string, cmd []string) (string, error)
+}
+
+// adminClient drives Caddy's admin API from inside the router container
+// with the image's busybox wget. Publishing the API instead would expose
+// an unauthenticated config endpoint to every container on the shared
+// network — Caddy's admin API has no built-in auth, and proxying it
+// through Caddy itself behind basic auth (the Traefik backend's
+// approach) makes every reload wait on the very request that
+// triggered it.
+type adminClient struct {
+	exec      execer
+	container string
+}
+
+// load replaces Caddy's running config with the JSON file at
+// containerPath. Caddy validates and provisions the whole config before
+// swapping it in, so a rejected config leaves the old one serving.
+func (c *adminClient) load(ctx context.Context, containerPath string) error {
+	out, err := c.exec.ExecInContainer(ctx, c.container, []string{
+		"wget", "-q", "-O", "-",
+		"--header", "Content-Type: application/json",
+		"--post-file", containerPath,
+		"http://" + AdminAddr + "/load",
+	})
+	if err != nil {
+		return fmt.Errorf("caddy admin load: %w: %s", err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// routeCount returns how many routes the HTTPS server has loaded.
+func (c *adminClient) routeCount(ctx context.Context) (int, error) {
+	out, err := c.exec.ExecInContainer(ctx, c.container, []string{
+		"wget", "-q", "-O", "-",
+		"http://" + AdminAddr + "/config/apps/http/servers/" + serverName + "/routes",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("caddy admin config: %w: %s", err, strings.TrimSpace(out))
+	}
+	var routes []json.RawMessage
+	if err := json.Unmarshal([]byte(out), &routes); err != nil {
+		return 0, fmt.Errorf("caddy admin config: %w", err)
+	}
+	return len(routes), nil
+}
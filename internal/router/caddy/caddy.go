@@ -0,0 +1,398 @@
+// Package caddy implements router.Router with a single Caddy v2
+// container. Unlike the Traefik backend, which hot-reloads YAML files
+// through a file watcher, the whole routing table is rendered to one
+// JSON document and pushed through Caddy's admin API; Caddy swaps it in
+// atomically or rejects it whole.
+//
+// When the tls.Provider cannot issue a certificate (mkcert missing, CA
+// not yet created) the affected hostnames are handed to Caddy's own
+// internal CA instead, so sites stay on HTTPS rather than degrading to
+// the router's default certificate.
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	dcontainer "github.com/docker/docker/api/types/container"
+	dnetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/genmark"
+	"github.com/PeterBooker/locorum/internal/platform"
+	"github.com/PeterBooker/locorum/internal/router"
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
+	"github.com/PeterBooker/locorum/internal/version"
+)
+
+const (
+	// ContainerName matches the Traefik backend's so switching engines
+	// replaces the old router container, and the port-conflict health
+	// check recognises either as "ours".
+	ContainerName = "locorum-global-router"
+
+	// NetworkName / NetworkAlias mirror the Traefik backend: backends
+	// are resolved by container name on the shared bridge network.
+	NetworkName  = "locorum-global"
+	NetworkAlias = "router"
+
+	// Container-side mount points. The config dir is read-only; Caddy
+	// reads the JSON at startup and on every admin load.
+	ContainerConfigDir  = "/etc/locorum"
+	ContainerConfigPath = ContainerConfigDir + "/caddy.json"
+	ContainerCertsDir   = "/etc/locorum-certs"
+
+	// ContainerDataDir is Caddy's storage root in the official image.
+	// It holds the internal CA, so it is persisted on the host:
+	// otherwise every recreate would mint a new root for the user to
+	// trust again.
+	ContainerDataDir = "/data"
+
+	tickInterval         = 250 * time.Millisecond
+	routeAddTimeout      = 5 * time.Second
+	startupReadyDeadline = 30 * time.Second
+)
+
+// Config controls the Caddy orchestrator. Fields match traefik.Config.
+type Config struct {
+	HomeDir    string
+	AppVersion string
+	LogLevel   string // DEBUG, INFO, WARN, ERROR; defaults to INFO
+	HTTPPort   int    // host port for :80; 0 → 80
+	HTTPSPort  int    // host port for :443; 0 → 443
+}
+
+// engine is the docker surface the router uses. *docker.Docker
+// satisfies it.
+type engine interface {
+	execer
+	ContainerIsRunning(ctx context.Context, name string) (bool, error)
+	ContainerExists(ctx context.Context, name string) (bool, error)
+	RemoveContainer(ctx context.Context, name string) error
+	CreateContainer(ctx context.Context, name, ref string, cfg *dcontainer.Config, hostCfg *dcontainer.HostConfig, netCfg *dnetwork.NetworkingConfig) error
+}
+
+// Router is the Caddy-backed router.Router implementation.
+type Router struct {
+	cfg    Config
+	docker engine
+	tls    tlspkg.Provider
+	admin  *adminClient
+
+	hostConfigDir  string
+	hostConfigPath string
+	hostCertsDir   string
+	hostDataDir    string
+
+	mu       sync.Mutex
+	sites    map[string]siteEntry
+	services map[string]serviceEntry
+	// loadErr is the last config Caddy rejected, surfaced through
+	// Health until a later load succeeds.
+	loadErr string
+}
+
+// New constructs a Caddy-backed router. prov may be nil, in which case
+// every hostname is certified by Caddy's internal CA.
+func New(cfg Config, d *docker.Docker, prov tlspkg.Provider) (*Router, error) {
+	return newRouter(cfg, d, prov), nil
+}
+
+func newRouter(cfg Config, d engine, prov tlspkg.Provider) *Router {
+	if cfg.HTTPPort == 0 {
+		cfg.HTTPPort = 80
+	}
+	if cfg.HTTPSPort == 0 {
+		cfg.HTTPSPort = 443
+	}
+	routerDir := filepath.Join(cfg.HomeDir, ".locorum", "router")
+	return &Router{
+		cfg:            cfg,
+		docker:         d,
+		tls:            prov,
+		admin:          &adminClient{exec: d, container: ContainerName},
+		hostConfigDir:  filepath.Join(routerDir, "caddy"),
+		hostConfigPath: filepath.Join(routerDir, "caddy", "caddy.json"),
+		hostCertsDir:   filepath.Join(cfg.HomeDir, ".locorum", "certs"),
+		hostDataDir:    filepath.Join(routerDir, "caddy-data"),
+		sites:          map[string]siteEntry{},
+		services:       map[string]serviceEntry{},
+	}
+}
+
+func (r *Router) EnsureRunning(ctx context.Context) error {
+	for _, dir := range []string{r.hostConfigDir, r.hostCertsDir, r.hostDataDir} {
+		// 0o700: the data dir holds Caddy's CA key and the certs dir
+		// the per-site keys.
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("preparing router dirs: create %q: %w", dir, err)
+		}
+	}
+
+	running, err := r.docker.ContainerIsRunning(ctx, ContainerName)
+	if err != nil {
+		return fmt.Errorf("inspecting router: %w", err)
+	}
+	if running {
+		if _, err := r.admin.routeCount(ctx); err == nil {
+			return nil
+		}
+		// Not answering Caddy's admin API — most likely the Traefik
+		// container from before an engine switch.
+		slog.Info("router stale, recreating")
+		if err := r.docker.RemoveContainer(ctx, ContainerName); err != nil {
+			return fmt.Errorf("remove stale router: %w", err)
+		}
+	} else if exists, _ := r.docker.ContainerExists(ctx, ContainerName); exists {
+		if err := r.docker.RemoveContainer(ctx, ContainerName); err != nil {
+			return fmt.Errorf("remove stopped router: %w", err)
+		}
+	}
+
+	// Starting fresh: site routes from a previous session would point
+	// at backends that no longer exist. Services are re-upserted by the
+	// caller, so any already registered this process are kept.
+	r.mu.Lock()
+	r.sites = map[string]siteEntry{}
+	r.mu.Unlock()
+	if err := r.writeConfig(); err != nil {
+		return fmt.Errorf("writing router config: %w", err)
+	}
+	if err := r.createContainer(ctx); err != nil {
+		return fmt.Errorf("create router container: %w", router.ClassifyStartError(err))
+	}
+	if err := r.waitReady(ctx); err != nil {
+		return fmt.Errorf("router did not become ready: %w", err)
+	}
+	return nil
+}
+
+func (r *Router) Stop(ctx context.Context) error {
+	return r.docker.RemoveContainer(ctx, ContainerName)
+}
+
+func (r *Router) UpsertSite(ctx context.Context, route router.SiteRoute) error {
+	cert := r.ensureCert(ctx, "site-"+route.Slug, siteHosts(route))
+
+	r.mu.Lock()
+	_, known := r.sites[route.Slug]
+	r.sites[route.Slug] = siteEntry{route: route, cert: cert}
+	r.mu.Unlock()
+
+	return r.apply(ctx, !known)
+}
+
+func (r *Router) RemoveSite(ctx context.Context, slug string) error {
+	r.mu.Lock()
+	delete(r.sites, slug)
+	r.mu.Unlock()
+	if r.tls != nil {
+		if err := r.tls.Remove(ctx, "site-"+slug); err != nil {
+			slog.Warn("remove cert failed", "slug", slug, "err", err)
+		}
+	}
+	return r.apply(ctx, false)
+}
+
+func (r *Router) UpsertService(ctx context.Context, route router.ServiceRoute) error {
+	if len(route.Hostnames) == 0 {
+		return fmt.Errorf("service %q: at least one hostname required", route.Name)
+	}
+	cert := r.ensureCert(ctx, "svc-"+route.Name, route.Hostnames)
+
+	r.mu.Lock()
+	_, known := r.services[route.Name]
+	r.services[route.Name] = serviceEntry{route: route, cert: cert}
+	r.mu.Unlock()
+
+	return r.apply(ctx, !known)
+}
+
+func (r *Router) RemoveService(ctx context.Context, name string) error {
+	r.mu.Lock()
+	delete(r.services, name)
+	r.mu.Unlock()
+	if r.tls != nil {
+		if err := r.tls.Remove(ctx, "svc-"+name); err != nil {
+			slog.Warn("remove cert failed", "name", name, "err", err)
+		}
+	}
+	return r.apply(ctx, false)
+}
+
+func (r *Router) Health(ctx context.Context) (router.Health, error) {
+	loaded, err := r.admin.routeCount(ctx)
+	if err != nil {
+		return router.Health{Reachable: false}, err
+	}
+	r.mu.Lock()
+	h := router.Health{
+		Reachable:     true,
+		LoadedRouters: loaded,
+		Expected:      r.expectedRouteCountLocked(),
+	}
+	if r.loadErr != "" {
+		h.Errors = []string{r.loadErr}
+	}
+	r.mu.Unlock()
+	return h, nil
+}
+
+// expectedRouteCountLocked is the number of routes the HTTPS server
+// should hold: one per site and one per service. Caller must hold r.mu.
+func (r *Router) expectedRouteCountLocked() int {
+	return len(r.sites) + len(r.services)
+}
+
+// apply renders the current state, writes it to the bind-mounted config
+// file (which is also what Caddy boots from after a restart) and loads
+// it through the admin API. When a route was added it then waits for
+// the admin API to report it, giving callers the same "routable on
+// return" guarantee as the Traefik backend.
+func (r *Router) apply(ctx context.Context, added bool) error {
+	if err := r.writeConfig(); err != nil {
+		return fmt.Errorf("write router config: %w", err)
+	}
+	if !added {
+		// Removals and in-place updates must not fail just because the
+		// router is down (a site deleted while Docker is stopped); Caddy
+		// boots from the file written above.
+		if running, err := r.docker.ContainerIsRunning(ctx, ContainerName); err == nil && !running {
+			return nil
+		}
+	}
+	loadErr := r.admin.load(ctx, ContainerConfigPath)
+	r.mu.Lock()
+	r.loadErr = ""
+	if loadErr != nil {
+		r.loadErr = loadErr.Error()
+	}
+	expected := r.expectedRouteCountLocked()
+	r.mu.Unlock()
+	if loadErr != nil {
+		return loadErr
+	}
+	if added {
+		return r.waitForRouteCount(ctx, expected)
+	}
+	return nil
+}
+
+func (r *Router) writeConfig() error {
+	r.mu.Lock()
+	st := configState{
+		logLevel:  r.cfg.LogLevel,
+		httpsPort: r.cfg.HTTPSPort,
+		sites:     make(map[string]siteEntry, len(r.sites)),
+		services:  make(map[string]serviceEntry, len(r.services)),
+	}
+	for k, v := range r.sites {
+		st.sites[k] = v
+	}
+	for k, v := range r.services {
+		st.services[k] = v
+	}
+	r.mu.Unlock()
+
+	payload, err := renderConfig(st)
+	if err != nil {
+		return err
+	}
+	return genmark.WriteAtomic(r.hostConfigPath, payload, 0o600)
+}
+
+// ensureCert issues a cert through the tls.Provider and returns it in
+// container paths. A zero result hands the hostnames to Caddy's
+// internal CA.
+func (r *Router) ensureCert(ctx context.Context, name string, hostnames []string) tlspkg.CertPath {
+	if r.tls == nil {
+		return tlspkg.CertPath{}
+	}
+	status, err := r.tls.Available(ctx)
+	if err == nil && !status.Installed {
+		err = fmt.Errorf("tls provider unavailable: %s", status.Message)
+	}
+	var cert tlspkg.CertPath
+	if err == nil {
+		cert, err = r.tls.Issue(ctx, tlspkg.CertSpec{Name: name, Hostnames: hostnames})
+	}
+	if err != nil {
+		slog.Info("issuing cert failed; using Caddy's internal CA", "name", name, "err", err)
+		return tlspkg.CertPath{}
+	}
+	return tlspkg.CertPath{
+		CertFile: containerCertPath(cert.CertFile, r.hostCertsDir, ContainerCertsDir),
+		KeyFile:  containerCertPath(cert.KeyFile, r.hostCertsDir, ContainerCertsDir),
+	}
+}
+
+func (r *Router) createContainer(ctx context.Context) error {
+	cfg := &dcontainer.Config{
+		Image:  version.CaddyImage,
+		Cmd:    []string{"caddy", "run", "--config", ContainerConfigPath},
+		Labels: docker.PlatformLabels(docker.RoleRouter, "", r.cfg.AppVersion),
+		ExposedPorts: nat.PortSet{
+			"80/tcp":  {},
+			"443/tcp": {},
+		},
+	}
+	hostCfg := &dcontainer.HostConfig{
+		Binds: []string{
+			platform.DockerPath(r.hostConfigDir) + ":" + ContainerConfigDir + ":ro",
+			platform.DockerPath(r.hostCertsDir) + ":" + ContainerCertsDir + ":ro",
+			platform.DockerPath(r.hostDataDir) + ":" + ContainerDataDir,
+		},
+		PortBindings: nat.PortMap{
+			"80/tcp":  {{HostIP: "0.0.0.0", HostPort: strconv.Itoa(r.cfg.HTTPPort)}},
+			"443/tcp": {{HostIP: "0.0.0.0", HostPort: strconv.Itoa(r.cfg.HTTPSPort)}},
+		},
+		NetworkMode:   dcontainer.NetworkMode(NetworkName),
+		RestartPolicy: dcontainer.RestartPolicy{Name: dcontainer.RestartPolicyUnlessStopped},
+	}
+	netCfg := &dnetwork.NetworkingConfig{
+		EndpointsConfig: map[string]*dnetwork.EndpointSettings{
+			NetworkName: {Aliases: []string{NetworkAlias}},
+		},
+	}
+	return r.docker.CreateContainer(ctx, ContainerName, version.CaddyImage, cfg, hostCfg, netCfg)
+}
+
+func (r *Router) waitReady(ctx context.Context) error {
+	deadline := time.Now().Add(startupReadyDeadline)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if _, err := r.admin.routeCount(ctx); err == nil {
+			return nil
+		}
+		time.Sleep(tickInterval)
+	}
+	return fmt.Errorf("caddy admin API unreachable after %v", startupReadyDeadline)
+}
+
+func (r *Router) waitForRouteCount(ctx context.Context, expected int) error {
+	deadline := time.Now().Add(routeAddTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		n, err := r.admin.routeCount(ctx)
+		if err == nil && n >= expected {
+			return nil
+		}
+		time.Sleep(tickInterval)
+	}
+	return fmt.Errorf("route did not appear in router after %v (expected %d)", routeAddTimeout, expected)
+}
@@ -0,0 +1,181 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	dcontainer "github.com/docker/docker/api/types/container"
+	dnetwork "github.com/docker/docker/api/types/network"
+
+	"github.com/PeterBooker/locorum/internal/router"
+)
+
+// fakeEngine stands in for docker. loadedRoutes is what the admin API
+// reports after the most recent successful load.
+type fakeEngine struct {
+	mu           sync.Mutex
+	running      bool
+	loads        int
+	loadErr      error
+	loadedRoutes int
+	pending      int // route count the next load installs
+	execs        [][]string
+	created      *dcontainer.Config
+	removed      int
+}
+
+func (f *fakeEngine) ExecInContainer(_ context.Context, _ string, cmd []string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, cmd)
+	if !f.running {
+		return "", errors.New("container not running")
+	}
+	last := cmd[len(cmd)-1]
+	switch {
+	case strings.HasSuffix(last, "/load"):
+		if f.loadErr != nil {
+			return "wget: server returned error: HTTP/1.1 400 Bad Request", f.loadErr
+		}
+		f.loads++
+		f.loadedRoutes = f.pending
+		return "", nil
+	case strings.HasSuffix(last, "/routes"):
+		if f.loadedRoutes == 0 {
+			return "null\n", nil
+		}
+		return "[" + strings.Repeat("{},", f.loadedRoutes-1) + "{}]\n", nil
+	}
+	return "", errors.New("unexpected command")
+}
+
+func (f *fakeEngine) ContainerIsRunning(context.Context, string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.running, nil
+}
+
+func (f *fakeEngine) ContainerExists(context.Context, string) (bool, error) { return false, nil }
+
+func (f *fakeEngine) RemoveContainer(context.Context, string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = false
+	f.removed++
+	return nil
+}
+
+func (f *fakeEngine) CreateContainer(_ context.Context, _, _ string, cfg *dcontainer.Config, _ *dcontainer.HostConfig, _ *dnetwork.NetworkingConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = cfg
+	f.running = true
+	return nil
+}
+
+func newTestRouter(t *testing.T) (*Router, *fakeEngine) {
+	t.Helper()
+	eng := &fakeEngine{}
+	return newRouter(Config{HomeDir: t.TempDir()}, eng, nil), eng
+}
+
+func shopRoute() router.SiteRoute {
+	return router.SiteRoute{Slug: "shop", PrimaryHost: "shop.localhost", Backend: "http://locorum-shop-web:80"}
+}
+
+func TestEnsureRunningCreatesContainerAndWritesConfig(t *testing.T) {
+	r, eng := newTestRouter(t)
+	if err := r.EnsureRunning(context.Background()); err != nil {
+		t.Fatalf("EnsureRunning: %v", err)
+	}
+	if eng.created == nil || eng.created.Image == "" || eng.created.Cmd[len(eng.created.Cmd)-1] != ContainerConfigPath {
+		t.Fatalf("container config = %+v", eng.created)
+	}
+	if _, err := os.Stat(r.hostConfigPath); err != nil {
+		t.Errorf("config not written: %v", err)
+	}
+
+	// A running container whose admin API answers is left alone.
+	if err := r.EnsureRunning(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if eng.removed != 0 {
+		t.Errorf("healthy router was recreated")
+	}
+}
+
+func TestUpsertSiteLoadsAndWaitsForRoute(t *testing.T) {
+	r, eng := newTestRouter(t)
+	if err := r.EnsureRunning(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	eng.pending = 1
+	if err := r.UpsertSite(context.Background(), shopRoute()); err != nil {
+		t.Fatalf("UpsertSite: %v", err)
+	}
+	if eng.loads != 1 {
+		t.Errorf("loads = %d, want 1", eng.loads)
+	}
+	body, _ := os.ReadFile(r.hostConfigPath)
+	if !strings.Contains(string(body), "shop.localhost") {
+		t.Errorf("site missing from config file:\n%s", body)
+	}
+
+	h, err := r.Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.Reachable || h.LoadedRouters != 1 || h.Expected != 1 {
+		t.Errorf("health = %+v", h)
+	}
+}
+
+func TestUpsertSiteSurfacesRejectedConfig(t *testing.T) {
+	r, eng := newTestRouter(t)
+	if err := r.EnsureRunning(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	eng.loadErr = errors.New("command exited with code 1")
+	if err := r.UpsertSite(context.Background(), shopRoute()); err == nil {
+		t.Fatal("want load error")
+	}
+	h, _ := r.Health(context.Background())
+	if len(h.Errors) != 1 {
+		t.Errorf("health errors = %v, want the load failure", h.Errors)
+	}
+
+	eng.loadErr = nil
+	eng.pending = 1
+	if err := r.UpsertSite(context.Background(), shopRoute()); err != nil {
+		t.Fatal(err)
+	}
+	if h, _ := r.Health(context.Background()); len(h.Errors) != 0 {
+		t.Errorf("errors not cleared after a good load: %v", h.Errors)
+	}
+}
+
+func TestRemoveSiteWhileRouterDown(t *testing.T) {
+	r, eng := newTestRouter(t)
+	if err := r.EnsureRunning(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	eng.pending = 1
+	if err := r.UpsertSite(context.Background(), shopRoute()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RemoveSite(context.Background(), "shop"); err != nil {
+		t.Fatalf("RemoveSite with the router down: %v", err)
+	}
+	body, _ := os.ReadFile(r.hostConfigPath)
+	if strings.Contains(string(body), "shop.localhost") {
+		t.Errorf("removed site still in config file")
+	}
+}
@@ -0,0 +1,378 @@
+package caddy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/router"
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
+)
+
+// serverName is the key of the HTTPS server in apps.http.servers. The
+// admin client counts routes under it for Health.
+const serverName = "locorum"
+
+// siteEntry / serviceEntry pair a route with its cert, already
+// translated to container paths. A zero cert means "let Caddy's internal
+// CA issue one".
+type siteEntry struct {
+	route router.SiteRoute
+	cert  tlspkg.CertPath
+}
+
+type serviceEntry struct {
+	route router.ServiceRoute
+	cert  tlspkg.CertPath
+}
+
+// configState is everything renderConfig needs. The Router snapshots it
+// under its mutex and renders outside.
+type configState struct {
+	logLevel  string
+	httpsPort int
+	sites     map[string]siteEntry
+	services  map[string]serviceEntry
+}
+
+// The types below model the subset of Caddy's JSON config Locorum
+// writes. Field names follow https://caddyserver.com/docs/json/.
+type caddyConfig struct {
+	Admin   adminConfig   `json:"admin"`
+	Logging loggingConfig `json:"logging"`
+	Apps    appsConfig    `json:"apps"`
+}
+
+type adminConfig struct {
+	Listen string `json:"listen"`
+}
+
+type loggingConfig struct {
+	Logs map[string]logConfig `json:"logs"`
+}
+
+type logConfig struct {
+	Level string `json:"level"`
+}
+
+type appsConfig struct {
+	HTTP httpApp `json:"http"`
+	TLS  tlsApp  `json:"tls"`
+}
+
+type httpApp struct {
+	Servers map[string]httpServer `json:"servers"`
+}
+
+type httpServer struct {
+	Listen                []string       `json:"listen"`
+	Routes                []route        `json:"routes"`
+	TLSConnectionPolicies []struct{}     `json:"tls_connection_policies,omitempty"`
+	AutomaticHTTPS        automaticHTTPS `json:"automatic_https"`
+	Logs                  *serverLogs    `json:"logs,omitempty"`
+}
+
+// serverLogs turns on access logging. Each site host logs under
+// "locorum-<slug>", the name the request inspector (internal/reqlog)
+// attributes entries by; service hosts are left unlogged.
+type serverLogs struct {
+	LoggerNames       map[string][]string `json:"logger_names"`
+	SkipUnmappedHosts bool                `json:"skip_unmapped_hosts"`
+}
+
+// automaticHTTPS is always disabled: left on, Caddy would try ACME for
+// every non-local hostname (aliases, sslip.io LAN names) and add its own
+// redirect server. Certificates are managed explicitly in tlsApp instead.
+type automaticHTTPS struct {
+	Disable bool `json:"disable"`
+}
+
+type route struct {
+	ID       string    `json:"@id,omitempty"`
+	Match    []matcher `json:"match,omitempty"`
+	Handle   []handler `json:"handle"`
+	Terminal bool      `json:"terminal,omitempty"`
+}
+
+type matcher struct {
+	Host     []string       `json:"host,omitempty"`
+	RemoteIP *remoteIPMatch `json:"remote_ip,omitempty"`
+	Not      []matcher      `json:"not,omitempty"`
+}
+
+type remoteIPMatch struct {
+	Ranges []string `json:"ranges"`
+}
+
+type handler struct {
+	Handler    string              `json:"handler"`
+	Upstreams  []upstream          `json:"upstreams,omitempty"`
+	StatusCode int                 `json:"status_code,omitempty"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Routes     []route             `json:"routes,omitempty"`
+	Providers  *authProviders      `json:"providers,omitempty"`
+}
+
+type authProviders struct {
+	HTTPBasic httpBasicAuth `json:"http_basic"`
+}
+
+type httpBasicAuth struct {
+	Hash     hashConfig    `json:"hash"`
+	Accounts []authAccount `json:"accounts"`
+}
+
+type hashConfig struct {
+	Algorithm string `json:"algorithm"`
+}
+
+// authAccount.Password is the base64-encoded bcrypt hash, the form
+// every Caddy 2 release accepts.
+type authAccount struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type upstream struct {
+	Dial string `json:"dial"`
+}
+
+type tlsApp struct {
+	Certificates tlsCertificates `json:"certificates"`
+	Automation   *tlsAutomation  `json:"automation,omitempty"`
+}
+
+type tlsCertificates struct {
+	LoadFiles []loadFile `json:"load_files,omitempty"`
+	Automate  []string   `json:"automate,omitempty"`
+}
+
+type loadFile struct {
+	Certificate string   `json:"certificate"`
+	Key         string   `json:"key"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+type tlsAutomation struct {
+	Policies []tlsPolicy `json:"policies"`
+}
+
+type tlsPolicy struct {
+	Subjects []string    `json:"subjects"`
+	Issuers  []tlsIssuer `json:"issuers"`
+}
+
+type tlsIssuer struct {
+	Module string `json:"module"`
+}
+
+// renderConfig builds the full Caddy config. Routes are sorted by name
+// so identical state renders byte-identical output.
+func renderConfig(st configState) ([]byte, error) {
+	https := httpServer{
+		Listen:                []string{":443"},
+		Routes:                []route{},
+		TLSConnectionPolicies: []struct{}{{}},
+		AutomaticHTTPS:        automaticHTTPS{Disable: true},
+		Logs:                  &serverLogs{LoggerNames: map[string][]string{}, SkipUnmappedHosts: true},
+	}
+	var certs tlsCertificates
+	var internal []string
+
+	addCert := func(tag string, cert tlspkg.CertPath, hosts []string) {
+		if cert.IsZero() {
+			internal = append(internal, hosts...)
+			return
+		}
+		certs.LoadFiles = append(certs.LoadFiles, loadFile{
+			Certificate: cert.CertFile,
+			Key:         cert.KeyFile,
+			Tags:        []string{tag},
+		})
+	}
+
+	for _, name := range sortedKeys(st.services) {
+		e := st.services[name]
+		if len(e.route.Hostnames) == 0 {
+			return nil, fmt.Errorf("service %q: at least one hostname required", name)
+		}
+		r, err := proxyRoute("locorum-svc-"+name, e.route.Hostnames, e.route.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", name, err)
+		}
+		https.Routes = append(https.Routes, r)
+		addCert("svc-"+name, e.cert, e.route.Hostnames)
+	}
+	for _, slug := range sortedKeys(st.sites) {
+		e := st.sites[slug]
+		hosts := siteHosts(e.route)
+		r, err := proxyRoute("locorum-"+slug, hosts, e.route.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("site %q: %w", slug, err)
+		}
+		guards, err := accessHandlers(e.route)
+		if err != nil {
+			return nil, fmt.Errorf("site %q: %w", slug, err)
+		}
+		r.Handle = append(guards, r.Handle...)
+		https.Routes = append(https.Routes, r)
+		addCert("site-"+slug, e.cert, hosts)
+		for _, h := range hosts {
+			https.Logs.LoggerNames[h] = []string{"locorum-" + slug}
+		}
+	}
+
+	cfg := caddyConfig{
+		// Admin stays on the container's own loopback; Locorum reaches
+		// it through docker exec (see adminClient), so no other
+		// container on the shared network can rewrite routes.
+		Admin:   adminConfig{Listen: AdminAddr},
+		Logging: loggingConfig{Logs: map[string]logConfig{"default": {Level: caddyLogLevel(st.logLevel)}}},
+		Apps: appsConfig{
+			HTTP: httpApp{Servers: map[string]httpServer{
+				serverName: https,
+				"redirect": redirectServer(st.httpsPort),
+			}},
+			TLS: tlsApp{Certificates: certs},
+		},
+	}
+	if len(internal) > 0 {
+		cfg.Apps.TLS.Certificates.Automate = internal
+		cfg.Apps.TLS.Automation = &tlsAutomation{Policies: []tlsPolicy{{
+			Subjects: internal,
+			Issuers:  []tlsIssuer{{Module: "internal"}},
+		}}}
+	}
+	return json.MarshalIndent(cfg, "", "  ")
+}
+
+// redirectServer answers plain HTTP with a permanent redirect to HTTPS,
+// like Traefik's web → websecure entrypoint redirection.
+func redirectServer(httpsPort int) httpServer {
+	target := "https://{http.request.host}"
+	if httpsPort != 0 && httpsPort != 443 {
+		target += ":" + strconv.Itoa(httpsPort)
+	}
+	return httpServer{
+		Listen: []string{":80"},
+		Routes: []route{{Handle: []handler{{
+			Handler:    "static_response",
+			StatusCode: 301,
+			Headers:    map[string][]string{"Location": {target + "{http.request.uri}"}},
+		}}}},
+		AutomaticHTTPS: automaticHTTPS{Disable: true},
+	}
+}
+
+func proxyRoute(id string, hosts []string, backend string) (route, error) {
+	dial, err := dialAddress(backend)
+	if err != nil {
+		return route{}, err
+	}
+	return route{
+		ID:       id,
+		Match:    []matcher{{Host: hosts}},
+		Handle:   []handler{{Handler: "reverse_proxy", Upstreams: []upstream{{Dial: dial}}}},
+		Terminal: true,
+	}, nil
+}
+
+// accessHandlers returns the handlers that enforce a site's access
+// controls, to run ahead of its reverse_proxy: a subroute answering 403
+// to clients outside the allowlist (mirroring Traefik's ipAllowList),
+// then basic auth.
+func accessHandlers(r router.SiteRoute) ([]handler, error) {
+	var out []handler
+	if len(r.IPAllowList) > 0 {
+		out = append(out, handler{
+			Handler: "subroute",
+			Routes: []route{{
+				Match:  []matcher{{Not: []matcher{{RemoteIP: &remoteIPMatch{Ranges: r.IPAllowList}}}}},
+				Handle: []handler{{Handler: "static_response", StatusCode: 403}},
+			}},
+		})
+	}
+	if len(r.BasicAuth) > 0 {
+		auth := httpBasicAuth{Hash: hashConfig{Algorithm: "bcrypt"}}
+		for _, u := range r.BasicAuth {
+			user, hash, ok := strings.Cut(u, ":")
+			if !ok || user == "" || hash == "" {
+				return nil, fmt.Errorf("invalid basic auth entry for %q", user)
+			}
+			auth.Accounts = append(auth.Accounts, authAccount{
+				Username: user,
+				Password: base64.StdEncoding.EncodeToString([]byte(hash)),
+			})
+		}
+		out = append(out, handler{Handler: "authentication", Providers: &authProviders{HTTPBasic: auth}})
+	}
+	return out, nil
+}
+
+// siteHosts lists every hostname a site answers on. Caddy's host
+// matcher treats "*.x" as exactly one label, matching the TLS wildcard
+// rule Traefik's HostRegexp encodes by hand.
+func siteHosts(r router.SiteRoute) []string {
+	out := []string{r.PrimaryHost}
+	out = append(out, r.ExtraHosts...)
+	if r.WildcardHost != "" {
+		out = append(out, r.WildcardHost)
+	}
+	for _, w := range r.ExtraWildcardHosts {
+		if w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// dialAddress turns a backend URL ("http://locorum-shop-web:80") into
+// the host:port reverse_proxy dials.
+func dialAddress(backend string) (string, error) {
+	u, err := url.Parse(backend)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid backend %q", backend)
+	}
+	if u.Port() != "" {
+		return u.Host, nil
+	}
+	return net.JoinHostPort(u.Hostname(), "80"), nil
+}
+
+// caddyLogLevel maps Locorum's Traefik-style level names onto Caddy's.
+func caddyLogLevel(level string) string {
+	switch strings.ToUpper(level) {
+	case "DEBUG", "WARN", "ERROR":
+		return strings.ToUpper(level)
+	}
+	return "INFO"
+}
+
+// containerCertPath translates a host-side cert path to its path inside
+// the router container. Paths outside hostRoot pass through unchanged.
+func containerCertPath(hostPath, hostRoot, containerRoot string) string {
+	if hostPath == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(hostRoot, hostPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return hostPath
+	}
+	return path.Join(containerRoot, filepath.ToSlash(rel))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
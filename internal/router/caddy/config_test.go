@@ -0,0 +1,185 @@
+package caddy
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/router"
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
+)
+
+func testState() configState {
+	return configState{
+		httpsPort: 443,
+		sites: map[string]siteEntry{
+			"shop": {
+				route: router.SiteRoute{
+					Slug:               "shop",
+					PrimaryHost:        "shop.localhost",
+					ExtraHosts:         []string{"shop.test"},
+					WildcardHost:       "*.shop.localhost",
+					ExtraWildcardHosts: []string{"", "*.shop.192-168-1-42.sslip.io"},
+					Backend:            "http://locorum-shop-web:80",
+				},
+				cert: tlspkg.CertPath{CertFile: "/etc/locorum-certs/site-shop.pem", KeyFile: "/etc/locorum-certs/site-shop-key.pem"},
+			},
+			"blog": {
+				route: router.SiteRoute{Slug: "blog", PrimaryHost: "blog.localhost", Backend: "http://locorum-blog-web"},
+			},
+		},
+		services: map[string]serviceEntry{
+			"mail": {route: router.ServiceRoute{Name: "mail", Hostnames: []string{"mail.localhost"}, Backend: "http://locorum-global-mail:8025"}},
+		},
+	}
+}
+
+func renderForTest(t *testing.T, st configState) caddyConfig {
+	t.Helper()
+	payload, err := renderConfig(st)
+	if err != nil {
+		t.Fatalf("renderConfig: %v", err)
+	}
+	var cfg caddyConfig
+	if err := json.Unmarshal(payload, &cfg); err != nil {
+		t.Fatalf("rendered config is not valid JSON: %v\n%s", err, payload)
+	}
+	return cfg
+}
+
+func TestRenderConfigRoutes(t *testing.T) {
+	cfg := renderForTest(t, testState())
+
+	if cfg.Admin.Listen != AdminAddr {
+		t.Errorf("admin listen = %q, want container loopback", cfg.Admin.Listen)
+	}
+	srv := cfg.Apps.HTTP.Servers[serverName]
+	if !srv.AutomaticHTTPS.Disable || len(srv.TLSConnectionPolicies) != 1 {
+		t.Errorf("https server must terminate TLS with automatic HTTPS off: %+v", srv)
+	}
+
+	var ids []string
+	for _, r := range srv.Routes {
+		ids = append(ids, r.ID)
+	}
+	if want := []string{"locorum-svc-mail", "locorum-blog", "locorum-shop"}; !slices.Equal(ids, want) {
+		t.Fatalf("route order = %v, want %v", ids, want)
+	}
+
+	if got := srv.Logs.LoggerNames["*.shop.localhost"]; len(got) != 1 || got[0] != "locorum-shop" {
+		t.Errorf("access log name for shop wildcard = %v", got)
+	}
+	if _, ok := srv.Logs.LoggerNames["mail.localhost"]; ok || !srv.Logs.SkipUnmappedHosts {
+		t.Error("service hosts should not be access-logged")
+	}
+
+	shop := srv.Routes[2]
+	wantHosts := []string{"shop.localhost", "shop.test", "*.shop.localhost", "*.shop.192-168-1-42.sslip.io"}
+	if !slices.Equal(shop.Match[0].Host, wantHosts) {
+		t.Errorf("shop hosts = %v, want %v", shop.Match[0].Host, wantHosts)
+	}
+	if got := shop.Handle[0].Upstreams[0].Dial; got != "locorum-shop-web:80" {
+		t.Errorf("shop dial = %q", got)
+	}
+	if got := srv.Routes[1].Handle[0].Upstreams[0].Dial; got != "locorum-blog-web:80" {
+		t.Errorf("portless backend dial = %q, want :80 added", got)
+	}
+}
+
+func TestRenderConfigAccessControls(t *testing.T) {
+	st := testState()
+	shop := st.sites["shop"]
+	shop.route.BasicAuth = []string{"locorum:$2a$04$abc"}
+	shop.route.IPAllowList = []string{"192.168.1.0/24", "127.0.0.0/8"}
+	st.sites["shop"] = shop
+
+	h := renderForTest(t, st).Apps.HTTP.Servers[serverName].Routes[2].Handle
+	if len(h) != 3 || h[0].Handler != "subroute" || h[1].Handler != "authentication" || h[2].Handler != "reverse_proxy" {
+		t.Fatalf("handler chain = %+v, want allowlist, auth, proxy", h)
+	}
+	deny := h[0].Routes[0]
+	if got := deny.Match[0].Not[0].RemoteIP.Ranges; !slices.Equal(got, shop.route.IPAllowList) || deny.Handle[0].StatusCode != 403 {
+		t.Errorf("allowlist subroute = %+v", deny)
+	}
+	acct := h[1].Providers.HTTPBasic.Accounts[0]
+	if acct.Username != "locorum" || acct.Password != "JDJhJDA0JGFiYw==" {
+		t.Errorf("account = %+v, want base64 of the bcrypt hash", acct)
+	}
+
+	shop.route.BasicAuth = []string{"no-hash"}
+	st.sites["shop"] = shop
+	if _, err := renderConfig(st); err == nil {
+		t.Error("malformed basic auth entry: want error")
+	}
+}
+
+func TestRenderConfigTLS(t *testing.T) {
+	cfg := renderForTest(t, testState())
+	tlsApp := cfg.Apps.TLS
+
+	if len(tlsApp.Certificates.LoadFiles) != 1 || tlsApp.Certificates.LoadFiles[0].Tags[0] != "site-shop" {
+		t.Errorf("load_files = %+v", tlsApp.Certificates.LoadFiles)
+	}
+	// Routes without a provider cert fall back to the internal CA.
+	want := []string{"mail.localhost", "blog.localhost"}
+	if !slices.Equal(tlsApp.Certificates.Automate, want) {
+		t.Errorf("automate = %v, want %v", tlsApp.Certificates.Automate, want)
+	}
+	if tlsApp.Automation == nil || tlsApp.Automation.Policies[0].Issuers[0].Module != "internal" {
+		t.Errorf("automation = %+v, want internal issuer", tlsApp.Automation)
+	}
+
+	st := testState()
+	delete(st.sites, "blog")
+	delete(st.services, "mail")
+	if cfg := renderForTest(t, st); cfg.Apps.TLS.Automation != nil {
+		t.Error("no internal-CA policy expected when every route has a cert")
+	}
+}
+
+func TestRenderConfigRedirect(t *testing.T) {
+	loc := func(st configState) string {
+		return renderForTest(t, st).Apps.HTTP.Servers["redirect"].Routes[0].Handle[0].Headers["Location"][0]
+	}
+	st := testState()
+	if got := loc(st); got != "https://{http.request.host}{http.request.uri}" {
+		t.Errorf("redirect on 443 = %q", got)
+	}
+	st.httpsPort = 8443
+	if got := loc(st); got != "https://{http.request.host}:8443{http.request.uri}" {
+		t.Errorf("redirect on 8443 = %q", got)
+	}
+}
+
+func TestRenderConfigStable(t *testing.T) {
+	a, _ := renderConfig(testState())
+	b, _ := renderConfig(testState())
+	if string(a) != string(b) {
+		t.Error("identical state rendered different output")
+	}
+}
+
+func TestRenderConfigRejectsBadInput(t *testing.T) {
+	st := testState()
+	st.services["bad"] = serviceEntry{route: router.ServiceRoute{Name: "bad", Backend: "http://x"}}
+	if _, err := renderConfig(st); err == nil {
+		t.Error("service without hostnames: want error")
+	}
+	st = testState()
+	st.sites["bad"] = siteEntry{route: router.SiteRoute{Slug: "bad", PrimaryHost: "bad.localhost", Backend: "not a url"}}
+	if _, err := renderConfig(st); err == nil {
+		t.Error("invalid backend: want error")
+	}
+}
+
+func TestContainerCertPath(t *testing.T) {
+	root := filepath.Join("home", "u", ".locorum", "certs")
+	if got := containerCertPath(filepath.Join(root, "site-a.pem"), root, ContainerCertsDir); got != ContainerCertsDir+"/site-a.pem" {
+		t.Errorf("got %q", got)
+	}
+	outside := filepath.Join("elsewhere", "x.pem")
+	if got := containerCertPath(outside, root, ContainerCertsDir); got != outside {
+		t.Errorf("outside root: got %q", got)
+	}
+}
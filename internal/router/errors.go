@@ -1,6 +1,9 @@
 package router
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 // ErrPortInUse is returned by Router.EnsureRunning implementations when
 // the configured HTTP or HTTPS host port is already bound by something
@@ -8,3 +11,33 @@ import "errors"
 // code can branch with errors.Is without importing a specific routing
 // engine implementation.
 var ErrPortInUse = errors.New("router host port already in use")
+
+// ClassifyStartError translates the opaque Docker CreateContainer /
+// ContainerStart error string into one of our sentinels when the pattern
+// is recognisable. Returns the original error untouched when nothing
+// matches. Shared by every backend, since they all publish 80/443 the
+// same way.
+//
+// Daemon error strings vary across Docker SDK versions and platforms; we
+// match on the substrings the SDK consistently surfaces:
+//
+//   - "address already in use"  (Linux userland-proxy)
+//   - "port is already allocated" (Docker Engine API)
+//   - "bind: An attempt was made..." (Windows Docker Desktop)
+//
+// Update this list when a new variant shows up rather than spraying string
+// matches across the codebase.
+func ClassifyStartError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "address already in use"),
+		strings.Contains(msg, "port is already allocated"),
+		strings.Contains(msg, "only one usage of each socket address"),
+		strings.Contains(msg, "bind: an attempt was made"):
+		return errors.Join(ErrPortInUse, err)
+	}
+	return err
+}
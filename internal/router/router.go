@@ -3,7 +3,8 @@
 // per-site web containers and global services (mail, adminer).
 //
 // The interface is the seam between sites/app code and the routing engine.
-// The production implementation lives in router/traefik; router/fake is
+// The default implementation lives in router/traefik, with router/caddy
+// as an alternative chosen by the router.engine setting; router/fake is
 // available for tests.
 package router
 
@@ -32,7 +33,7 @@ type Router interface {
 	// RemoveSite removes routing for a site. Hot-reloaded.
 	RemoveSite(ctx context.Context, slug string) error
 
-	// UpsertService registers a global service (mail, adminer, future addons).
+	// UpsertService registers a global service (mail, the DB UI, future addons).
 	UpsertService(ctx context.Context, route ServiceRoute) error
 
 	// RemoveService removes a global service by name.
@@ -53,6 +54,11 @@ type SiteRoute struct {
 	ExtraWildcardHosts []string // additional wildcard hosts (e.g. "*.myslug.<lan>.sslip.io")
 	Backend            string   // e.g. "http://locorum-myslug-web:80"
 	Cert               tls.CertPath
+
+	// Access controls. Both empty means anyone who can reach the router
+	// gets through; when both are set the allowlist is checked first.
+	BasicAuth   []string // htpasswd-style "user:bcrypt-hash" entries
+	IPAllowList []string // client CIDRs let through, e.g. "192.168.1.0/24"
 }
 
 // ServiceRoute describes a global service exposed under a fixed hostname.
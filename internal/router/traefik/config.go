@@ -81,19 +81,27 @@ func (r *Renderer) Static(logLevel string) ([]byte, error) {
 // be translated to container paths (see Router.containerCertPath). If cert
 // is zero, the site is rendered without TLS — useful for HTTP-only fallback
 // when mkcert is unavailable.
+//
+// Access controls become ipAllowList and basicAuth middlewares on the
+// site's router, allowlist first so a blocked client never sees the
+// password prompt.
 func (r *Renderer) Site(route router.SiteRoute, cert tlspkg.CertPath) ([]byte, error) {
 	data := struct {
-		Slug     string
-		Rule     string
-		Backend  string
-		CertFile string
-		KeyFile  string
+		Slug      string
+		Rule      string
+		Backend   string
+		CertFile  string
+		KeyFile   string
+		AllowList []string
+		Users     []string
 	}{
-		Slug:     route.Slug,
-		Rule:     BuildSiteRule(route),
-		Backend:  route.Backend,
-		CertFile: cert.CertFile,
-		KeyFile:  cert.KeyFile,
+		Slug:      route.Slug,
+		Rule:      BuildSiteRule(route),
+		Backend:   route.Backend,
+		CertFile:  cert.CertFile,
+		KeyFile:   cert.KeyFile,
+		AllowList: route.IPAllowList,
+		Users:     route.BasicAuth,
 	}
 	var buf bytes.Buffer
 	if err := r.siteTpl.Execute(&buf, data); err != nil {
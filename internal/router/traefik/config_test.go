@@ -359,6 +359,44 @@ func TestRendererSite(t *testing.T) {
 				"url: 'http://locorum-my-store-web:80'",
 			},
 		},
+		{
+			name: "no access controls",
+			route: router.SiteRoute{
+				Slug:        "open",
+				PrimaryHost: "open.localhost",
+				Backend:     "http://locorum-open-web:80",
+			},
+			cert:        cert,
+			mustNotHave: []string{"middlewares:", "basicAuth:", "ipAllowList:"},
+		},
+		{
+			name: "basic auth and allowlist",
+			route: router.SiteRoute{
+				Slug:        "guarded",
+				PrimaryHost: "guarded.localhost",
+				Backend:     "http://locorum-guarded-web:80",
+				BasicAuth:   []string{"locorum:$2a$04$abc"},
+				IPAllowList: []string{"192.168.1.0/24", "127.0.0.0/8"},
+			},
+			cert: cert,
+			mustHave: []string{
+				"        - locorum-guarded-allowlist\n        - locorum-guarded-auth\n",
+				"locorum-guarded-allowlist:\n      ipAllowList:\n        sourceRange:\n          - '192.168.1.0/24'\n          - '127.0.0.0/8'",
+				"locorum-guarded-auth:\n      basicAuth:\n        removeHeader: true\n        users:\n          - 'locorum:$2a$04$abc'",
+			},
+		},
+		{
+			name: "allowlist only",
+			route: router.SiteRoute{
+				Slug:        "lan",
+				PrimaryHost: "lan.localhost",
+				Backend:     "http://locorum-lan-web:80",
+				IPAllowList: []string{"10.0.0.0/8"},
+			},
+			cert:        cert,
+			mustHave:    []string{"- locorum-lan-allowlist", "sourceRange:"},
+			mustNotHave: []string{"basicAuth:", "locorum-lan-auth"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,348 @@
+package sites
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/router"
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/types"
+	"github.com/PeterBooker/locorum/internal/utils"
+)
+
+// DefaultAuthUser is the basic-auth username generated for every site.
+const DefaultAuthUser = "locorum"
+
+// MaxIPAllowlist caps the entries on one site's allowlist.
+const MaxIPAllowlist = 32
+
+// authPasswordByteLen is the random source for a basic-auth password:
+// 16 bytes → 22 URL-safe chars, short enough to type on a phone.
+const authPasswordByteLen = 16
+
+// AlwaysAllowed are appended to every non-empty allowlist so the host
+// never locks itself out. Requests the host makes through a published
+// port can also arrive from the router network's gateway;
+// SiteManager.AlwaysAllowed adds that once Docker reports it.
+var AlwaysAllowed = []string{"127.0.0.0/8", "::1/128"}
+
+// DockerDesktopVMNet is the network of Docker Desktop's VM. Desktop
+// forwards published ports from inside the VM, so the host and every
+// LAN client reach the router from it: there the allowlist cannot tell
+// devices apart — basic auth still can.
+const DockerDesktopVMNet = "192.168.65.0/24"
+
+// routerNetCacheTTL bounds how long the router network's gateway is
+// reused. The network is only recreated by an infrastructure reset,
+// which invalidates the cache itself. A failed lookup is retried
+// sooner, after routerNetRetryTTL.
+const (
+	routerNetCacheTTL = 5 * time.Minute
+	routerNetRetryTTL = 30 * time.Second
+)
+
+// RouterNetwork is where the host's own requests reach the router from,
+// beyond loopback.
+type RouterNetwork struct {
+	// Gateways are the router network's bridge gateways, as CIDRs.
+	Gateways []string
+	// DockerDesktop is set when Docker runs in Docker Desktop's VM.
+	DockerDesktop bool
+}
+
+// SiteAccess is a site's router-level access controls, as shown in the
+// Access tab and returned by `locorum site access`.
+type SiteAccess struct {
+	AuthEnabled  bool     `json:"authEnabled"`
+	AuthUser     string   `json:"authUser,omitempty"`
+	AuthPassword string   `json:"authPassword,omitempty"`
+	IPAllowlist  []string `json:"ipAllowlist"`
+}
+
+// NormaliseIPAllowlist parses each entry as a CIDR or a bare address
+// (taken as a single host) and returns them in canonical network form,
+// de-duplicated, in input order.
+func NormaliseIPAllowlist(entries []string) ([]string, error) {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !strings.Contains(e, "/") {
+			ip := net.ParseIP(e)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid allowlist entry %q: want an IP address or CIDR", e)
+			}
+			if ip.To4() != nil {
+				e += "/32"
+			} else {
+				e += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q: want an IP address or CIDR", e)
+		}
+		if c := n.String(); !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	if len(out) > MaxIPAllowlist {
+		return nil, fmt.Errorf("too many allowlist entries (%d); the limit is %d", len(out), MaxIPAllowlist)
+	}
+	return out, nil
+}
+
+// LANAllowlist returns the detected LAN subnet ("192.168.1.0/24"), the
+// suggested allowlist for a LAN-exposed site, or "" when no LAN address
+// is available.
+func (sm *SiteManager) LANAllowlist() string {
+	return utils.LANSubnet(sm.lanIP())
+}
+
+// AlwaysAllowed returns the networks appended to every non-empty
+// allowlist: loopback, the router network's gateway and, on Docker
+// Desktop, its VM network.
+func (sm *SiteManager) AlwaysAllowed() []string {
+	rn := sm.routerNetwork()
+	out := append(slices.Clone(AlwaysAllowed), rn.Gateways...)
+	if rn.DockerDesktop {
+		out = append(out, DockerDesktopVMNet)
+	}
+	return out
+}
+
+// ClientsNATed reports whether LAN clients reach the router from the
+// same network as the host (Docker Desktop), so the allowlist cannot
+// tell them apart.
+func (sm *SiteManager) ClientsNATed() bool {
+	return sm.routerNetwork().DockerDesktop
+}
+
+// routerNetwork returns the cached RouterNetwork, detecting it when the
+// cache is empty or stale. After a failed detection the allowlist
+// falls back to loopback until Docker answers.
+func (sm *SiteManager) routerNetwork() RouterNetwork {
+	sm.routerNetMu.Lock()
+	defer sm.routerNetMu.Unlock()
+	if sm.routerNetCached != nil && time.Now().Before(sm.routerNetUntil) {
+		return *sm.routerNetCached
+	}
+	detect := sm.routerNetDetect
+	if detect == nil {
+		detect = sm.detectRouterNetwork
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rn, err := detect(ctx)
+	ttl := routerNetCacheTTL
+	if err != nil {
+		slog.Debug("access: router network not detected", "err", err.Error())
+		rn, ttl = RouterNetwork{}, routerNetRetryTTL
+	}
+	sm.routerNetCached, sm.routerNetUntil = &rn, time.Now().Add(ttl)
+	return rn
+}
+
+// detectRouterNetwork reads the global network's gateways and the
+// Docker provider from the engine.
+func (sm *SiteManager) detectRouterNetwork(ctx context.Context) (RouterNetwork, error) {
+	if sm.d == nil {
+		return RouterNetwork{}, errors.New("docker engine not available")
+	}
+	pi, err := sm.d.ProviderInfo(ctx)
+	if err != nil {
+		return RouterNetwork{}, err
+	}
+	gateways, err := sm.d.NetworkGateways(ctx, docker.GlobalNetwork)
+	if err != nil {
+		return RouterNetwork{}, err
+	}
+	cidrs, err := NormaliseIPAllowlist(gateways)
+	if err != nil {
+		return RouterNetwork{}, err
+	}
+	return RouterNetwork{Gateways: cidrs, DockerDesktop: pi.IsDockerDesktop}, nil
+}
+
+// SetRouterNetworkDetector is a test seam: replace the engine lookup
+// behind AlwaysAllowed with a deterministic stub. Pass nil to revert.
+// Mirrors SetLANDetector.
+func (sm *SiteManager) SetRouterNetworkDetector(fn func(context.Context) (RouterNetwork, error)) {
+	sm.routerNetMu.Lock()
+	sm.routerNetDetect = fn
+	sm.routerNetCached = nil
+	sm.routerNetMu.Unlock()
+}
+
+// InvalidateRouterNetwork drops the cached router network so the next
+// route re-reads it. Called after an infrastructure reset, which
+// recreates the network.
+func (sm *SiteManager) InvalidateRouterNetwork() {
+	sm.routerNetMu.Lock()
+	sm.routerNetCached = nil
+	sm.routerNetMu.Unlock()
+}
+
+// SiteAccess returns the site's access controls, credentials included.
+func (sm *SiteManager) SiteAccess(siteID string) (*SiteAccess, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	return siteAccessOf(site), nil
+}
+
+func siteAccessOf(site *types.Site) *SiteAccess {
+	return &SiteAccess{
+		AuthEnabled:  site.AuthEnabled,
+		AuthUser:     site.AuthUser,
+		AuthPassword: site.AuthPassword,
+		IPAllowlist:  append([]string{}, site.IPAllowlist...),
+	}
+}
+
+// SetBasicAuth turns HTTP basic auth on or off for the site. The first
+// enable generates the credentials; later toggles keep them, so a
+// password already shared with a colleague keeps working. Like
+// SetAliases it applies to a running site in place.
+func (sm *SiteManager) SetBasicAuth(ctx context.Context, siteID string, enabled bool) error {
+	return sm.updateAccess(ctx, siteID, func(site *types.Site) (bool, error) {
+		if site.AuthEnabled == enabled && (!enabled || site.AuthHash != "") {
+			return false, nil
+		}
+		site.AuthEnabled = enabled
+		if enabled && site.AuthHash == "" {
+			return true, setAuthCredentials(site)
+		}
+		return true, nil
+	})
+}
+
+// RotateAuthPassword replaces the site's basic-auth password. Browsers
+// that cached the old one are prompted again on their next request.
+func (sm *SiteManager) RotateAuthPassword(ctx context.Context, siteID string) error {
+	return sm.updateAccess(ctx, siteID, func(site *types.Site) (bool, error) {
+		return true, setAuthCredentials(site)
+	})
+}
+
+// SetIPAllowlist replaces the site's allowlist; empty removes it.
+// Entries are normalised with NormaliseIPAllowlist.
+func (sm *SiteManager) SetIPAllowlist(ctx context.Context, siteID string, entries []string) error {
+	cidrs, err := NormaliseIPAllowlist(entries)
+	if err != nil {
+		return err
+	}
+	if len(cidrs) == 0 {
+		cidrs = nil
+	}
+	return sm.updateAccess(ctx, siteID, func(site *types.Site) (bool, error) {
+		if slices.Equal(site.IPAllowlist, cidrs) {
+			return false, nil
+		}
+		site.IPAllowlist = cidrs
+		return true, nil
+	})
+}
+
+// updateAccess applies mutate to the site under its lock, persists the
+// result and, for a running site, re-upserts the route so the router
+// picks up the new middlewares. mutate reports whether it changed
+// anything; an unchanged site is left alone.
+func (sm *SiteManager) updateAccess(ctx context.Context, siteID string, mutate func(*types.Site) (bool, error)) error {
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+	changed, err := mutate(site)
+	if err != nil || !changed {
+		return err
+	}
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+	if site.Started {
+		if err := sm.rtr.UpsertSite(ctx, sm.routeFor(site)); err != nil {
+			return fmt.Errorf("upsert route: %w", err)
+		}
+	}
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	return nil
+}
+
+// setAuthCredentials generates a fresh password for the site and its
+// bcrypt hash, and registers the password for redaction in place of the
+// old one. The hash uses the minimum cost: the router checks it on
+// every request, and a random 128-bit password gains nothing from a
+// slower hash.
+func setAuthCredentials(site *types.Site) error {
+	b := make([]byte, authPasswordByteLen)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("generate auth password: %w", err)
+	}
+	password := base64.RawURLEncoding.EncodeToString(b)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		return fmt.Errorf("hash auth password: %w", err)
+	}
+	if site.AuthUser == "" {
+		site.AuthUser = DefaultAuthUser
+	}
+	secrets.Remove(site.AuthPassword)
+	secrets.Add(password)
+	site.AuthPassword, site.AuthHash = password, string(hash)
+	return nil
+}
+
+// applyAccess copies the site's access controls onto its route, with
+// AlwaysAllowed appended to a non-empty allowlist. Auth without a
+// stored hash (e.g. a row imported from an export, which never carries
+// credentials) is skipped rather than rendered broken.
+func (sm *SiteManager) applyAccess(route *router.SiteRoute, site *types.Site) {
+	if site.AuthEnabled && site.AuthUser != "" && site.AuthHash != "" {
+		route.BasicAuth = []string{site.AuthUser + ":" + site.AuthHash}
+	}
+	if len(site.IPAllowlist) > 0 {
+		route.IPAllowList = append(slices.Clone(site.IPAllowlist), sm.AlwaysAllowed()...)
+	}
+}
+
+// adminLoginURL is the auto-login URL for the site. Basic-auth
+// credentials are deliberately left out: a URL's userinfo lands in
+// browser history and process arguments, so with auth on the browser
+// prompts and the user pastes the password from Access controls.
+func adminLoginURL(site *types.Site, token string) string {
+	u := url.URL{
+		Scheme:   "https",
+		Host:     site.Domain,
+		Path:     "/wp-admin/",
+		RawQuery: url.Values{"locorum_token": {token}}.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,160 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func TestNormaliseIPAllowlist(t *testing.T) {
+	got, err := NormaliseIPAllowlist([]string{" 192.168.1.17/24", "10.0.0.5", "", "192.168.1.0/24", "fd00::1"})
+	if err != nil {
+		t.Fatalf("NormaliseIPAllowlist: %v", err)
+	}
+	want := []string{"192.168.1.0/24", "10.0.0.5/32", "fd00::1/128"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, bad := range []string{"192.168.1.300", "lan", "10.0.0.0/33"} {
+		if _, err := NormaliseIPAllowlist([]string{bad}); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+}
+
+func TestBasicAuthLifecycle(t *testing.T) {
+	sm, rtr, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	site.Started = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := sm.SetBasicAuth(ctx, site.ID, true); err != nil {
+		t.Fatalf("SetBasicAuth: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if got.AuthUser != DefaultAuthUser || got.AuthPassword == "" {
+		t.Fatalf("credentials not generated: %q / %q", got.AuthUser, got.AuthPassword)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(got.AuthHash), []byte(got.AuthPassword)); err != nil {
+		t.Errorf("hash does not match password: %v", err)
+	}
+	route := rtr.Sites()["lansite"]
+	if len(route.BasicAuth) != 1 || route.BasicAuth[0] != DefaultAuthUser+":"+got.AuthHash {
+		t.Errorf("route basic auth = %v", route.BasicAuth)
+	}
+
+	// Off and on again keeps the password; rotating replaces it.
+	password := got.AuthPassword
+	_ = sm.SetBasicAuth(ctx, site.ID, false)
+	if len(rtr.Sites()["lansite"].BasicAuth) != 0 {
+		t.Error("disabled auth still on the route")
+	}
+	_ = sm.SetBasicAuth(ctx, site.ID, true)
+	if got, _ := sm.st.GetSite(site.ID); got.AuthPassword != password {
+		t.Error("re-enable changed the password")
+	}
+	if err := sm.RotateAuthPassword(ctx, site.ID); err != nil {
+		t.Fatalf("RotateAuthPassword: %v", err)
+	}
+	got, _ = sm.st.GetSite(site.ID)
+	if got.AuthPassword == password {
+		t.Error("rotate kept the old password")
+	}
+	if red := secrets.RedactString("pw=" + got.AuthPassword); strings.Contains(red, got.AuthPassword) {
+		t.Errorf("auth password not registered for redaction: %q", red)
+	}
+}
+
+func TestSetIPAllowlist(t *testing.T) {
+	sm, rtr, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	site.Started = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+	sm.SetRouterNetworkDetector(func(context.Context) (RouterNetwork, error) {
+		return RouterNetwork{Gateways: []string{"172.18.0.1/32"}}, nil
+	})
+	ctx := context.Background()
+
+	lan := sm.LANAllowlist()
+	if lan != "192.168.1.0/24" {
+		t.Fatalf("LANAllowlist = %q", lan)
+	}
+	if err := sm.SetIPAllowlist(ctx, site.ID, []string{lan}); err != nil {
+		t.Fatalf("SetIPAllowlist: %v", err)
+	}
+	want := []string{lan, "127.0.0.0/8", "::1/128", "172.18.0.1/32"}
+	if got := rtr.Sites()["lansite"].IPAllowList; !slices.Equal(got, want) {
+		t.Errorf("route allowlist = %v, want %v", got, want)
+	}
+
+	if err := sm.SetIPAllowlist(ctx, site.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := sm.st.GetSite(site.ID); got.IPAllowlist != nil {
+		t.Errorf("allowlist = %v, want cleared", got.IPAllowlist)
+	}
+	if len(rtr.Sites()["lansite"].IPAllowList) != 0 {
+		t.Error("cleared allowlist still on the route")
+	}
+	if err := sm.SetIPAllowlist(ctx, site.ID, []string{"nope"}); err == nil {
+		t.Error("invalid entry: want error")
+	}
+}
+
+func TestAlwaysAllowed(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+
+	sm.SetRouterNetworkDetector(func(context.Context) (RouterNetwork, error) {
+		return RouterNetwork{}, errors.New("docker down")
+	})
+	if got := sm.AlwaysAllowed(); !slices.Equal(got, AlwaysAllowed) {
+		t.Errorf("detection failed: AlwaysAllowed = %v, want loopback only", got)
+	}
+
+	sm.SetRouterNetworkDetector(func(context.Context) (RouterNetwork, error) {
+		return RouterNetwork{Gateways: []string{"172.18.0.1/32"}, DockerDesktop: true}, nil
+	})
+	want := []string{"127.0.0.0/8", "::1/128", "172.18.0.1/32", DockerDesktopVMNet}
+	if got := sm.AlwaysAllowed(); !slices.Equal(got, want) {
+		t.Errorf("Docker Desktop: AlwaysAllowed = %v, want %v", got, want)
+	}
+	if !sm.ClientsNATed() {
+		t.Error("Docker Desktop: ClientsNATed = false")
+	}
+
+	sm.SetRouterNetworkDetector(func(context.Context) (RouterNetwork, error) {
+		return RouterNetwork{Gateways: []string{"172.18.0.1/32"}}, nil
+	})
+	for _, cidr := range sm.AlwaysAllowed() {
+		if cidr == "172.16.0.0/12" || cidr == DockerDesktopVMNet {
+			t.Errorf("native Docker: %s always allowed", cidr)
+		}
+	}
+	if sm.ClientsNATed() {
+		t.Error("native Docker: ClientsNATed = true")
+	}
+}
+
+func TestAdminLoginURLOmitsAuth(t *testing.T) {
+	site := &types.Site{Domain: "shop.localhost"}
+	want := "https://shop.localhost/wp-admin/?locorum_token=tok"
+	if got := adminLoginURL(site, "tok"); got != want {
+		t.Errorf("without auth: %s", got)
+	}
+	site.AuthEnabled, site.AuthUser, site.AuthPassword = true, "locorum", "s3cret"
+	if got := adminLoginURL(site, "tok"); got != want {
+		t.Errorf("with auth: %s, want %s", got, want)
+	}
+}
@@ -31,6 +31,8 @@ const activityErrorMaxBytes = 1024
 type activityDetails struct {
 	Steps []activityStepDetail `json:"steps,omitempty"`
 	Error string               `json:"error,omitempty"`
+	// Token is the capability token label on "call" rows.
+	Token string `json:"token,omitempty"`
 }
 
 type activityStepDetail struct {
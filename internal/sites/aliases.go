@@ -0,0 +1,261 @@
+package sites
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/hostsfile"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// MaxAliases caps the aliases on one site. Every alias is a SAN on the
+// site cert and a clause in its router rule; a few dozen is plenty for
+// any real project.
+const MaxAliases = 20
+
+// NormaliseAliases lowercases and trims each alias, drops a trailing
+// dot and blanks, and collapses an empty result to nil.
+func NormaliseAliases(aliases []string) []string {
+	var out []string
+	for _, a := range aliases {
+		a = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(a)), ".")
+		if a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// ValidateAliases checks a site's (normalised) aliases for hostname
+// syntax, duplicates, and collisions with the hostnames every other site
+// answers on. others may include site itself; it is skipped by ID.
+func ValidateAliases(site *types.Site, others []types.Site) error {
+	if len(site.Aliases) > MaxAliases {
+		return fmt.Errorf("too many aliases (%d); the limit is %d", len(site.Aliases), MaxAliases)
+	}
+	seen := make(map[string]bool, len(site.Aliases))
+	for _, a := range site.Aliases {
+		if err := validAliasHost(a); err != nil {
+			return err
+		}
+		if seen[a] {
+			return fmt.Errorf("alias %q is listed twice", a)
+		}
+		seen[a] = true
+		if strings.EqualFold(a, site.Domain) {
+			return fmt.Errorf("alias %q is the site's own domain", a)
+		}
+		for i := range others {
+			other := &others[i]
+			if other.ID == site.ID {
+				continue
+			}
+			for _, h := range siteHostnames(other) {
+				if hostsOverlap(a, h) {
+					return fmt.Errorf("alias %q conflicts with %q, already used by site %q", a, h, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validAliasHost checks one alias: a DNS name of at least two labels,
+// optionally prefixed with a single-label wildcard ("*.shop.test").
+func validAliasHost(a string) error {
+	host := strings.TrimPrefix(a, "*.")
+	if net.ParseIP(host) != nil {
+		return fmt.Errorf("alias %q is an IP address; use a hostname", a)
+	}
+	if len(host) > 253 {
+		return fmt.Errorf("alias %q is too long", a)
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("alias %q needs a domain suffix, e.g. %q", a, host+".test")
+	}
+	for _, l := range labels {
+		if l == "" || len(l) > 63 || l[0] == '-' || l[len(l)-1] == '-' {
+			return fmt.Errorf("alias %q is not a valid hostname", a)
+		}
+		for _, r := range l {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return fmt.Errorf("alias %q is not a valid hostname", a)
+			}
+		}
+	}
+	return nil
+}
+
+// siteHostnames lists the hostnames the router sends to site: the
+// primary domain, the subdomain-multisite wildcard and the aliases.
+func siteHostnames(site *types.Site) []string {
+	out := []string{strings.ToLower(site.Domain)}
+	if site.Multisite == "subdomain" {
+		out = append(out, "*."+strings.ToLower(site.Domain))
+	}
+	return append(out, site.Aliases...)
+}
+
+// hostsOverlap reports whether a request for some hostname could match
+// both a and b. Wildcards cover exactly one label, as in the router.
+func hostsOverlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	aw, bw := strings.HasPrefix(a, "*."), strings.HasPrefix(b, "*.")
+	switch {
+	case aw && !bw:
+		return wildcardCovers(a, b)
+	case bw && !aw:
+		return wildcardCovers(b, a)
+	default:
+		return false
+	}
+}
+
+func wildcardCovers(wildcard, host string) bool {
+	label, ok := strings.CutSuffix(host, wildcard[1:])
+	return ok && label != "" && !strings.Contains(label, ".")
+}
+
+// splitAliases separates exact aliases from wildcard ones, the shape
+// router.SiteRoute wants.
+func splitAliases(aliases []string) (hosts, wildcards []string) {
+	for _, a := range aliases {
+		if strings.HasPrefix(a, "*.") {
+			wildcards = append(wildcards, a)
+		} else {
+			hosts = append(hosts, a)
+		}
+	}
+	return hosts, wildcards
+}
+
+// SetAliases replaces the site's extra hostnames. Unlike most settings
+// it works on a running site: the wp-config host whitelist is rewritten
+// and the route re-upserted, which re-issues the cert with the new SANs.
+// When hosts-file management is on, the Locorum block is synced last; a
+// failure there is reported but leaves the aliases saved.
+//
+// Emits OnSiteUpdated on success so the GUI redraws.
+func (sm *SiteManager) SetAliases(ctx context.Context, siteID string, aliases []string) error {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+
+	candidate := *site
+	candidate.Aliases = NormaliseAliases(aliases)
+	all, err := sm.st.GetSites()
+	if err != nil {
+		return fmt.Errorf("listing sites: %w", err)
+	}
+	if err := ValidateAliases(&candidate, all); err != nil {
+		return err
+	}
+
+	if err := sm.applyAliases(ctx, siteID, candidate.Aliases); err != nil {
+		return err
+	}
+	if err := sm.SyncHostsFile(ctx); err != nil {
+		return fmt.Errorf("aliases saved, but updating the hosts file failed: %w", err)
+	}
+	return nil
+}
+
+func (sm *SiteManager) applyAliases(ctx context.Context, siteID string, aliases []string) error {
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Re-read under the lock so a start that landed since the
+	// validation pass is seen.
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+	if slices.Equal(site.Aliases, aliases) {
+		return nil
+	}
+	site.Aliases = aliases
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+
+	if site.Started {
+		if err := sm.EnsureWPConfig(site); err != nil {
+			return fmt.Errorf("regenerate wp-config: %w", err)
+		}
+		if err := sm.rtr.UpsertSite(ctx, sm.routeFor(site)); err != nil {
+			return fmt.Errorf("upsert route: %w", err)
+		}
+	}
+
+	sm.writeConfigYAML(site)
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	return nil
+}
+
+// hostsFileAliases returns every alias that needs a hosts-file entry.
+// Wildcards can't be expressed in a hosts file and *.localhost already
+// resolves to loopback, so both are left out.
+func hostsFileAliases(all []types.Site) []string {
+	var out []string
+	for _, s := range all {
+		for _, a := range s.Aliases {
+			if strings.HasPrefix(a, "*.") || strings.HasSuffix(a, ".localhost") {
+				continue
+			}
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// SyncHostsFile rewrites the Locorum block in the system hosts file to
+// list every site's aliases, or removes it when hosts-file management
+// is off. A no-op (no prompt) when the block is already current, so it
+// is cheap to call after any alias change.
+func (sm *SiteManager) SyncHostsFile(ctx context.Context) error {
+	if sm.cfg == nil {
+		return nil
+	}
+	var hosts []string
+	if sm.cfg.HostsFileManaged() {
+		all, err := sm.st.GetSites()
+		if err != nil {
+			return fmt.Errorf("listing sites: %w", err)
+		}
+		hosts = hostsFileAliases(all)
+	}
+	path := sm.hostsPath
+	if path == "" {
+		path = hostsfile.Path()
+	}
+	return hostsfile.Apply(ctx, path, hosts)
+}
+
+// syncHostsFileAfterDelete drops a deleted site's aliases from the hosts
+// file. Best-effort: the row is already gone, so a declined prompt only
+// leaves a stale loopback entry behind.
+func (sm *SiteManager) syncHostsFileAfterDelete(ctx context.Context, site *types.Site) {
+	if len(site.Aliases) == 0 || sm.cfg == nil || !sm.cfg.HostsFileManaged() {
+		return
+	}
+	if err := sm.SyncHostsFile(ctx); err != nil {
+		slog.Warn("hosts file sync after delete failed", "site", site.Slug, "err", err.Error())
+	}
+}
@@ -0,0 +1,171 @@
+package sites
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/hostsfile"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func TestValidateAliases(t *testing.T) {
+	others := []types.Site{
+		{ID: "b", Name: "Blog", Domain: "blog.localhost", Aliases: []string{"blog.test"}},
+		{ID: "n", Name: "Network", Domain: "net.localhost", Multisite: "subdomain"},
+		{ID: "w", Name: "Wild", Domain: "wild.localhost", Aliases: []string{"*.wild.test"}},
+	}
+	cases := []struct {
+		aliases []string
+		wantErr string
+	}{
+		{aliases: nil},
+		{aliases: []string{"shop.test", "*.shop.test", "www.shop.test"}},
+		{aliases: []string{"a.net.localhost.test"}},
+		{aliases: []string{"shop"}, wantErr: "domain suffix"},
+		{aliases: []string{"127.0.0.1"}, wantErr: "IP address"},
+		{aliases: []string{"bad_host.test"}, wantErr: "not a valid hostname"},
+		{aliases: []string{"-x.test"}, wantErr: "not a valid hostname"},
+		{aliases: []string{"a.*.test"}, wantErr: "not a valid hostname"},
+		{aliases: []string{"shop.test", "shop.test"}, wantErr: "listed twice"},
+		{aliases: []string{"shop.localhost"}, wantErr: "own domain"},
+		{aliases: []string{"blog.localhost"}, wantErr: `site "Blog"`},
+		{aliases: []string{"blog.test"}, wantErr: `site "Blog"`},
+		{aliases: []string{"sub.net.localhost"}, wantErr: `site "Network"`},
+		{aliases: []string{"api.wild.test"}, wantErr: `site "Wild"`},
+		{aliases: []string{"*.blog.test"}, wantErr: ""},
+	}
+	for _, tc := range cases {
+		site := &types.Site{ID: "s", Domain: "shop.localhost", Aliases: tc.aliases}
+		err := ValidateAliases(site, others)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%v: unexpected error %v", tc.aliases, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%v: err = %v, want containing %q", tc.aliases, err, tc.wantErr)
+		}
+	}
+
+	many := make([]string, MaxAliases+1)
+	for i := range many {
+		many[i] = "h" + string(rune('a'+i)) + ".test"
+	}
+	if err := ValidateAliases(&types.Site{ID: "s", Aliases: many}, nil); err == nil {
+		t.Error("over the limit: want error")
+	}
+}
+
+func TestNormaliseAliases(t *testing.T) {
+	got := NormaliseAliases([]string{" Shop.Test. ", "", "  "})
+	if !slices.Equal(got, []string{"shop.test"}) {
+		t.Errorf("got %v", got)
+	}
+	if NormaliseAliases([]string{""}) != nil {
+		t.Error("blank list should normalise to nil")
+	}
+}
+
+func TestRouteForIncludesAliases(t *testing.T) {
+	sm := &SiteManager{}
+	route := sm.routeFor(&types.Site{Slug: "shop", Domain: "shop.localhost", Aliases: []string{"shop.test", "*.shop.test"}})
+	if !slices.Equal(route.ExtraHosts, []string{"shop.test"}) {
+		t.Errorf("ExtraHosts = %v", route.ExtraHosts)
+	}
+	if !slices.Equal(route.ExtraWildcardHosts, []string{"*.shop.test"}) {
+		t.Errorf("ExtraWildcardHosts = %v", route.ExtraWildcardHosts)
+	}
+}
+
+func TestSetAliases_RunningSiteReroutesAndRewritesWPConfig(t *testing.T) {
+	sm, rtr, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	site.Started = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sm.SetAliases(context.Background(), site.ID, []string{"Shop.Test", "*.shop.test"}); err != nil {
+		t.Fatalf("SetAliases: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if !slices.Equal(got.Aliases, []string{"shop.test", "*.shop.test"}) {
+		t.Errorf("stored aliases = %v", got.Aliases)
+	}
+	route := rtr.Sites()[site.Slug]
+	if !slices.Contains(route.ExtraHosts, "shop.test") || !slices.Contains(route.ExtraWildcardHosts, "*.shop.test") {
+		t.Errorf("route = %+v", route)
+	}
+	body, err := os.ReadFile(filepath.Join(site.FilesDir, "wp-config-locorum.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `$locorum_alias_regex  = '/^(?:shop\\.test|[a-z0-9-]+\\.shop\\.test)$/'`; !strings.Contains(string(body), want) {
+		t.Errorf("alias regex missing, want %s\n%s", want, body)
+	}
+}
+
+func TestSetAliases_StoppedSiteSkipsRouter(t *testing.T) {
+	sm, rtr, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	if err := sm.SetAliases(context.Background(), site.ID, []string{"shop.test"}); err != nil {
+		t.Fatalf("SetAliases: %v", err)
+	}
+	if calls := rtr.Calls(); len(calls) != 0 {
+		t.Errorf("router calls on a stopped site: %v", calls)
+	}
+}
+
+func TestSetAliases_RejectsConflict(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	other := &types.Site{
+		ID: "other", Name: "Other", Slug: "other", Domain: "other.localhost",
+		FilesDir: t.TempDir(), PublicDir: "/", PHPVersion: "8.3",
+		DBEngine: "mysql", DBVersion: "8.0", Aliases: []string{"taken.test"},
+	}
+	if err := sm.st.AddSite(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetAliases(context.Background(), site.ID, []string{"taken.test"}); err == nil {
+		t.Fatal("want conflict error")
+	}
+}
+
+func TestSyncHostsFile(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	sm.hostsPath = filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(sm.hostsPath, []byte("127.0.0.1 localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.cfg.SetHostsFileManaged(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sm.SetAliases(context.Background(), site.ID, []string{"shop.test", "*.shop.test", "x.lansite.localhost"}); err != nil {
+		t.Fatalf("SetAliases: %v", err)
+	}
+	body, _ := os.ReadFile(sm.hostsPath)
+	s := string(body)
+	if !strings.Contains(s, "127.0.0.1 shop.test") {
+		t.Errorf("alias missing from hosts file:\n%s", s)
+	}
+	if strings.Contains(s, "*.shop.test") || strings.Contains(s, "lansite.localhost") {
+		t.Errorf("wildcard or .localhost alias written to hosts file:\n%s", s)
+	}
+
+	// Turning management off removes the block on the next sync.
+	if err := sm.cfg.SetHostsFileManaged(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SyncHostsFile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	body, _ = os.ReadFile(sm.hostsPath)
+	if strings.Contains(string(body), hostsfile.BeginMarker) {
+		t.Errorf("block not removed:\n%s", body)
+	}
+}
@@ -0,0 +1,164 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tlspkg "github.com/PeterBooker/locorum/internal/tls"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// CertRenewResult reports what RenewCerts did with each cert it touched.
+type CertRenewResult struct {
+	Renewed []string `json:"renewed"`
+
+	// Removed lists site certs with no running site behind them. They
+	// would be re-issued on the next start anyway, so renewing them
+	// only keeps a stale file around.
+	Removed []string `json:"removed,omitempty"`
+
+	// Failed maps cert name to the reason it could not be renewed.
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// certsDir is where both TLS providers write issued certs.
+func (sm *SiteManager) certsDir() string {
+	return filepath.Join(sm.homeDir, ".locorum", "certs")
+}
+
+// CertInventory reads every issued cert and compares it with the TLS
+// provider's current root.
+func (sm *SiteManager) CertInventory(ctx context.Context) (tlspkg.Inventory, error) {
+	if sm.tls == nil {
+		return tlspkg.Inventory{}, errors.New("no TLS provider configured")
+	}
+	return tlspkg.ReadInventory(ctx, sm.tls, sm.certsDir())
+}
+
+// RenewCerts re-issues the certs that need renewal, or every cert when
+// all is true. Site certs go through the router's UpsertSite so the new
+// files are hot-reloaded along with the route; service certs are
+// re-issued in place with the SANs they already carry, and the router
+// picks them up on its next dynamic-config reload (service certs are
+// renewed first, so any site renewal in the same call triggers it).
+//
+// Per-cert failures are collected in the result rather than aborting
+// the batch; the returned error covers only the inventory read.
+func (sm *SiteManager) RenewCerts(ctx context.Context, all bool) (*CertRenewResult, error) {
+	inv, err := sm.CertInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := inv.Certs
+	if !all {
+		targets = inv.Due(time.Now())
+	}
+
+	res := &CertRenewResult{Renewed: []string{}}
+	fail := func(name string, err error) {
+		if res.Failed == nil {
+			res.Failed = map[string]string{}
+		}
+		res.Failed[name] = err.Error()
+	}
+
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		return nil, fmt.Errorf("listing sites: %w", err)
+	}
+	bySlug := make(map[string]types.Site, len(rows))
+	for _, s := range rows {
+		bySlug[s.Slug] = s
+	}
+
+	var siteCerts []tlspkg.CertInfo
+	for _, c := range targets {
+		if strings.HasPrefix(c.Name, "site-") {
+			siteCerts = append(siteCerts, c)
+			continue
+		}
+		if err := sm.reissueCert(ctx, c); err != nil {
+			fail(c.Name, err)
+			continue
+		}
+		res.Renewed = append(res.Renewed, c.Name)
+	}
+
+	for _, c := range siteCerts {
+		slug := strings.TrimPrefix(c.Name, "site-")
+		site, ok := bySlug[slug]
+		if !ok || !site.Started {
+			if err := sm.tls.Remove(ctx, c.Name); err != nil {
+				fail(c.Name, err)
+				continue
+			}
+			res.Removed = append(res.Removed, c.Name)
+			continue
+		}
+		if err := sm.renewSiteCert(ctx, site.ID, c); err != nil {
+			fail(c.Name, err)
+			continue
+		}
+		res.Renewed = append(res.Renewed, c.Name)
+	}
+	return res, nil
+}
+
+// RenewDueCerts renews only the certs that need it. Backs the System
+// Health action, which wants a single error rather than a report.
+func (sm *SiteManager) RenewDueCerts(ctx context.Context) error {
+	res, err := sm.RenewCerts(ctx, false)
+	if err != nil {
+		return err
+	}
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("%d certificate(s) could not be renewed", len(res.Failed))
+	}
+	return nil
+}
+
+// renewSiteCert drops the site's cert and re-runs UpsertSite under the
+// site lock, so a concurrent start or stop can't interleave. UpsertSite
+// only logs an issue failure (the site falls back to HTTP), so the new
+// file's presence is checked explicitly.
+func (sm *SiteManager) renewSiteCert(ctx context.Context, siteID string, c tlspkg.CertInfo) error {
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return err
+	}
+	if site == nil || !site.Started {
+		return errors.New("site stopped during renewal")
+	}
+	if err := sm.tls.Remove(ctx, c.Name); err != nil {
+		return err
+	}
+	if err := sm.rtr.UpsertSite(ctx, sm.routeFor(site)); err != nil {
+		return fmt.Errorf("upsert route: %w", err)
+	}
+	if _, err := os.Stat(c.CertFile); err != nil {
+		return errors.New("certificate was not re-issued; check the TLS provider in System Health")
+	}
+	return nil
+}
+
+// reissueCert replaces a service cert with a fresh one for the same SANs.
+// The old files stay in place if they can't be read back for their SANs.
+func (sm *SiteManager) reissueCert(ctx context.Context, c tlspkg.CertInfo) error {
+	if len(c.Hostnames) == 0 {
+		return errors.New("cannot read the existing hostnames; it is re-issued when Locorum next starts")
+	}
+	if err := sm.tls.Remove(ctx, c.Name); err != nil {
+		return err
+	}
+	_, err := sm.tls.Issue(ctx, tlspkg.CertSpec{Name: c.Name, Hostnames: c.Hostnames})
+	return err
+}
@@ -0,0 +1,89 @@
+package sites
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	routerfake "github.com/PeterBooker/locorum/internal/router/fake"
+	tlsfake "github.com/PeterBooker/locorum/internal/tls/fake"
+)
+
+// writeSelfSignedCert drops a cert.pem for hosts under
+// <home>/.locorum/certs/<name>/, expiring after validity.
+func writeSelfSignedCert(t *testing.T, home, name string, hosts []string, validity time.Duration) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(home, ".locorum", "certs", name)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenewCerts(t *testing.T) {
+	home := t.TempDir()
+	prov := tlsfake.New()
+	t.Cleanup(prov.Cleanup)
+	rtr := routerfake.New()
+	sm := newSPXSiteManager(t)
+	sm.tls, sm.rtr, sm.homeDir = prov, rtr, home
+
+	site := spxTestSite(t.TempDir())
+	site.Started = true
+	if err := sm.st.AddSite(&site); err != nil {
+		t.Fatal(err)
+	}
+	year := 365 * 24 * time.Hour
+	writeSelfSignedCert(t, home, "site-"+site.Slug, []string{site.Domain}, 5*24*time.Hour)
+	writeSelfSignedCert(t, home, "site-gone", []string{"gone.localhost"}, 5*24*time.Hour)
+	writeSelfSignedCert(t, home, "svc-mail", []string{"mail.localhost"}, year)
+
+	res, err := sm.RenewCerts(context.Background(), false)
+	if err != nil {
+		t.Fatalf("RenewCerts: %v", err)
+	}
+	if !slices.Equal(res.Renewed, []string{"site-" + site.Slug}) || !slices.Equal(res.Removed, []string{"site-gone"}) || len(res.Failed) != 0 {
+		t.Fatalf("due-only renewal = %+v", res)
+	}
+	if !slices.Contains(rtr.Calls(), "UpsertSite:"+site.Slug) {
+		t.Errorf("router calls %v, want the site route re-upserted", rtr.Calls())
+	}
+
+	res, err = sm.RenewCerts(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(res.Renewed, "svc-mail") {
+		t.Errorf("--all renewal = %+v, want svc-mail included", res)
+	}
+	if got := prov.Issued["svc-mail"]; !slices.Equal(got, []string{"mail.localhost"}) {
+		t.Errorf("svc-mail re-issued for %v, want its existing SANs", got)
+	}
+}
@@ -0,0 +1,530 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/dbengine"
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/sites/configyaml"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// ConfigFieldChange is one projected field where a hand-edited
+// config.yaml disagrees with the site row.
+type ConfigFieldChange struct {
+	Field    string `json:"field"`
+	Current  string `json:"current"`
+	Proposed string `json:"proposed"`
+
+	// Blocked explains why the YAML value can't be applied in place.
+	// Empty when ApplyConfigYAML will apply it.
+	Blocked string `json:"blocked,omitempty"`
+}
+
+// ConfigDrift describes unapplied edits in a site's config.yaml.
+type ConfigDrift struct {
+	SiteID   string              `json:"siteId"`
+	Slug     string              `json:"slug"`
+	Path     string              `json:"path"`
+	Changes  []ConfigFieldChange `json:"changes"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+// Applicable reports whether at least one change can be applied.
+func (d *ConfigDrift) Applicable() bool {
+	for _, c := range d.Changes {
+		if c.Blocked == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsStop reports whether applying the drift touches anything other
+// than hooks and aliases, which is everything baked into container specs.
+func (d *ConfigDrift) NeedsStop() bool {
+	for _, c := range d.Changes {
+		if c.Blocked == "" && c.Field != "hooks" && c.Field != "aliases" {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigDrift returns the unapplied config.yaml edits for siteID, or
+// nil when the file matches the row, is missing, or is older than the
+// row (the normal case: writeConfigYAML will refresh it).
+//
+// A site whose edits have been seen but not yet applied or discarded
+// stays drifted even if a later lifecycle write bumps updated_at past
+// the file's mtime — see configPending.
+func (sm *SiteManager) ConfigDrift(siteID string) (*ConfigDrift, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	drift, _, err := sm.configDriftFor(site)
+	return drift, err
+}
+
+// ConfigYAML renders siteID's row as config.yaml, the projection
+// writeConfigYAML keeps on disk. Unlike the file it never reflects
+// unapplied hand edits; ConfigDrift reports those.
+func (sm *SiteManager) ConfigYAML(siteID string) ([]byte, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	hookList, err := sm.st.ListHooks(site.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing hooks: %w", err)
+	}
+	return configyaml.Render(configyaml.FromSite(*site, hookList))
+}
+
+// holdConfigYAML marks site's config.yaml as carrying unapplied edits
+// instead of re-projecting it. Used when a site is created from a file
+// whose hooks were left out: the file keeps them, and the drift flow
+// lists them for the user to apply once reviewed.
+func (sm *SiteManager) holdConfigYAML(site *types.Site) {
+	sm.configPending.Store(site.ID, true)
+	drift, _, err := sm.configDriftFor(site)
+	if err != nil {
+		slog.Warn("config.yaml: reconcile", "site", site.Slug, "err", err.Error())
+		return
+	}
+	if drift != nil && sm.OnConfigDrift != nil {
+		sm.OnConfigDrift(site.ID, drift)
+	}
+}
+
+// ConfigDrifts returns every site with unapplied config.yaml edits.
+// Sites whose file fails to parse are logged and skipped.
+func (sm *SiteManager) ConfigDrifts(_ context.Context) []ConfigDrift {
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		slog.Debug("sites: config drifts: GetSites failed", "err", err.Error())
+		return nil
+	}
+	var out []ConfigDrift
+	for i := range rows {
+		drift, _, err := sm.configDriftFor(&rows[i])
+		if err != nil {
+			slog.Warn("config.yaml: reconcile", "site", rows[i].Slug, "err", err.Error())
+			continue
+		}
+		if drift != nil {
+			out = append(out, *drift)
+		}
+	}
+	return out
+}
+
+// ConfigDriftFields maps slug → drifted field names. Implements
+// health.ConfigDriftLister.
+func (sm *SiteManager) ConfigDriftFields(ctx context.Context) map[string][]string {
+	if sm == nil || sm.st == nil {
+		return nil
+	}
+	drifts := sm.ConfigDrifts(ctx)
+	if len(drifts) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(drifts))
+	for _, d := range drifts {
+		fields := make([]string, 0, len(d.Changes))
+		for _, c := range d.Changes {
+			fields = append(fields, c.Field)
+		}
+		out[d.Slug] = fields
+	}
+	return out
+}
+
+// ApplyConfigYAMLForSlug is ApplyConfigYAML keyed by slug, for the
+// health panel's one-click action.
+func (sm *SiteManager) ApplyConfigYAMLForSlug(ctx context.Context, slug string) error {
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		return err
+	}
+	for _, s := range rows {
+		if s.Slug == slug {
+			_, err := sm.ApplyConfigYAML(ctx, s.ID)
+			return err
+		}
+	}
+	return fmt.Errorf("site %q not found", slug)
+}
+
+// ApplyConfigYAML copies the applicable config.yaml edits onto the
+// site row through the same setters the GUI uses, then re-projects the
+// file so the blocked fields fall back to the row's values. Returns
+// the drift that was acted on, or nil when there was nothing to apply.
+//
+// Version, web server, public dir, port publish, Xdebug and resource
+// changes need a stopped site; a hooks-only change applies to a running one.
+func (sm *SiteManager) ApplyConfigYAML(ctx context.Context, siteID string) (*ConfigDrift, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	drift, f, err := sm.configDriftFor(site)
+	if err != nil || drift == nil {
+		return nil, err
+	}
+	if !drift.Applicable() {
+		return nil, errors.New("none of the config.yaml changes can be applied in place")
+	}
+	if drift.NeedsStop() && site.Started {
+		return nil, errors.New("site must be stopped to apply config.yaml changes")
+	}
+
+	if err := sm.validateConfigChanges(site, f, drift.Changes); err != nil {
+		return nil, err
+	}
+	// The pending mark stays set while the setters run, so none of
+	// them re-projects config.yaml from a half-updated row: a failure
+	// part-way leaves the file, and the drift, as the user wrote it.
+	sm.configPending.Store(siteID, true)
+	if err := sm.applyConfigChanges(ctx, site, f, drift.Changes); err != nil {
+		return nil, err
+	}
+
+	sm.configPending.Delete(siteID)
+	sm.refreshConfigYAMLFor(siteID)
+	if sm.OnConfigDrift != nil {
+		sm.OnConfigDrift(siteID, nil)
+	}
+	return drift, nil
+}
+
+// configVersionsChange gathers the version fields among changes into
+// the one VersionsChange they are applied as.
+func configVersionsChange(f *configyaml.File, changes []ConfigFieldChange) VersionsChange {
+	var versions VersionsChange
+	for _, c := range changes {
+		if c.Blocked != "" {
+			continue
+		}
+		switch c.Field {
+		case "php_version":
+			versions.PHPVersion = f.PHPVersion
+		case "cache.backend":
+			versions.CacheBackend = f.Cache.Backend
+		case "cache.version":
+			versions.CacheVersion = f.Cache.Version
+		case "db.version":
+			versions.DBVersion = f.DB.Version
+		}
+	}
+	return versions
+}
+
+// validateConfigChanges runs every check the setters in
+// applyConfigChanges would, against a copy of site, so a bad value
+// is refused before anything is written.
+func (sm *SiteManager) validateConfigChanges(site *types.Site, f *configyaml.File, changes []ConfigFieldChange) error {
+	fs := f.ToSite()
+	candidate := *site
+	if _, err := applyVersionsChange(&candidate, configVersionsChange(f, changes)); err != nil {
+		return fmt.Errorf("applying versions: %w", err)
+	}
+	for _, c := range changes {
+		if c.Blocked != "" {
+			continue
+		}
+		var err error
+		switch c.Field {
+		case "web_server":
+			if f.WebServer != "nginx" && f.WebServer != "apache" {
+				err = fmt.Errorf("invalid web server %q (allowed: nginx, apache)", f.WebServer)
+			}
+		case "xdebug":
+			if mode := strings.ToLower(strings.TrimSpace(f.FieldValue("xdebug"))); !ValidXdebugMode(mode) {
+				err = fmt.Errorf("invalid xdebug mode %q (allowed: %s)", mode, strings.Join(XdebugModes, ", "))
+			}
+		case "resources":
+			err = docker.ValidateResourceLimits(docker.CompactResourceLimits(fs.Resources))
+		case "php_ini":
+			candidate.PHPIni = fs.PHPIni
+		case "php_extensions":
+			candidate.PHPExtensionsEnable, candidate.PHPExtensionsDisable = fs.PHPExtensionsEnable, fs.PHPExtensionsDisable
+		case "aliases":
+			var all []types.Site
+			if all, err = sm.st.GetSites(); err == nil {
+				candidate.Aliases = NormaliseAliases(f.Aliases)
+				err = ValidateAliases(&candidate, all)
+			}
+		case "hooks":
+			for _, h := range f.ToHooks(site.ID) {
+				if err = h.Validate(); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("applying %s: %w", c.Field, err)
+		}
+	}
+	normalisePHPOverrides(&candidate)
+	if err := ValidatePHPOverrides(&candidate); err != nil {
+		return fmt.Errorf("applying PHP settings: %w", err)
+	}
+	return nil
+}
+
+// applyConfigChanges writes validated changes through the GUI's
+// setters. Versions go first: the swap removes containers and runs
+// hooks, so it is the step most likely to fail, and failing before
+// anything else is written leaves the row as it was.
+func (sm *SiteManager) applyConfigChanges(ctx context.Context, site *types.Site, f *configyaml.File, changes []ConfigFieldChange) error {
+	if versions := configVersionsChange(f, changes); versions != (VersionsChange{}) {
+		if err := sm.UpdateSiteVersionsWithEngine(ctx, site.ID, versions); err != nil {
+			return fmt.Errorf("applying versions: %w", err)
+		}
+	}
+	// php_ini and php_extensions share one setter; gather both and
+	// apply once so neither reverts the other.
+	fs := f.ToSite()
+	php, phpChanged := *site, false
+	for _, c := range changes {
+		if c.Blocked != "" {
+			continue
+		}
+		var err error
+		switch c.Field {
+		case "public_dir":
+			err = sm.UpdatePublicDir(ctx, site.ID, f.PublicDir)
+		case "db.publish_port":
+			err = sm.SetPublishDBPort(site.ID, f.DB.PublishPort)
+		case "web_server":
+			err = sm.SetWebServer(site.ID, f.WebServer)
+		case "xdebug":
+			err = sm.SetXdebugMode(site.ID, f.FieldValue("xdebug"))
+		case "resources":
+			err = sm.SetResourceLimits(site.ID, fs.Resources)
+		case "php_ini":
+			php.PHPIni, phpChanged = fs.PHPIni, true
+		case "php_extensions":
+			php.PHPExtensionsEnable, php.PHPExtensionsDisable, phpChanged = fs.PHPExtensionsEnable, fs.PHPExtensionsDisable, true
+		case "aliases":
+			err = sm.SetAliases(ctx, site.ID, f.Aliases)
+		case "hooks":
+			err = sm.st.ReplaceHooks(site.ID, f.ToHooks(site.ID))
+		}
+		if err != nil {
+			return fmt.Errorf("applying %s: %w", c.Field, err)
+		}
+	}
+	if phpChanged {
+		if err := sm.SetPHPOverrides(site.ID, php.PHPIni, php.PHPExtensionsEnable, php.PHPExtensionsDisable); err != nil {
+			return fmt.Errorf("applying PHP settings: %w", err)
+		}
+	}
+	return nil
+}
+
+// DiscardConfigYAML drops the unapplied edits by re-projecting the row
+// over config.yaml. Fails when the file has no generated-file marker:
+// it is then the user's to edit, and rewriting it is not ours to do.
+func (sm *SiteManager) DiscardConfigYAML(siteID string) error {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+	sm.configPending.Delete(siteID)
+	sm.writeConfigYAML(site)
+
+	if drift, _, _ := sm.configDriftFor(site); drift != nil {
+		sm.configPending.Store(siteID, true)
+		return fmt.Errorf("%s has no locorum-generated marker; edit it by hand", drift.Path)
+	}
+	if sm.OnConfigDrift != nil {
+		sm.OnConfigDrift(siteID, nil)
+	}
+	return nil
+}
+
+// WatchConfigYAML polls every site's config.yaml and reports new or
+// resolved drift through OnConfigDrift. The first pass runs
+// immediately, so edits made while Locorum was closed surface at
+// startup. Only files whose mtime moved are re-parsed. Returns when
+// ctx is done.
+func (sm *SiteManager) WatchConfigYAML(ctx context.Context, every time.Duration) {
+	seen := make(map[string]time.Time)
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		sm.scanConfigYAML(seen)
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+func (sm *SiteManager) scanConfigYAML(seen map[string]time.Time) {
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		slog.Debug("config.yaml watch: GetSites failed", "err", err.Error())
+		return
+	}
+	for i := range rows {
+		site := &rows[i]
+		if site.FilesDir == "" {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(site.FilesDir, configyaml.Filename))
+		if err != nil {
+			delete(seen, site.ID)
+			continue
+		}
+		if last, ok := seen[site.ID]; ok && last.Equal(info.ModTime()) {
+			continue
+		}
+		seen[site.ID] = info.ModTime()
+
+		drift, _, err := sm.configDriftFor(site)
+		if err != nil {
+			slog.Warn("config.yaml: reconcile", "site", site.Slug, "err", err.Error())
+			continue
+		}
+		if drift != nil {
+			sm.configPending.Store(site.ID, true)
+		} else if _, was := sm.configPending.LoadAndDelete(site.ID); !was {
+			continue
+		}
+		if sm.OnConfigDrift != nil {
+			sm.OnConfigDrift(site.ID, drift)
+		}
+	}
+}
+
+// configDriftFor reads and reconciles site's config.yaml. Returns the
+// parsed file alongside the drift so ApplyConfigYAML works from the
+// exact bytes the user was shown, not a re-read.
+func (sm *SiteManager) configDriftFor(site *types.Site) (*ConfigDrift, *configyaml.File, error) {
+	if site.FilesDir == "" {
+		return nil, nil, nil
+	}
+	path := filepath.Join(site.FilesDir, configyaml.Filename)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	parsed, err := configyaml.Parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	hookList, err := sm.st.ListHooks(site.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing hooks: %w", err)
+	}
+	dbF := configyaml.FromSite(*site, hookList)
+	mtime := info.ModTime().UTC().Format(time.RFC3339)
+	rep := configyaml.Reconcile(parsed.File, dbF, mtime, site.UpdatedAt)
+	switch rep.Verdict {
+	case configyaml.VerdictEqual:
+		return nil, nil, nil
+	case configyaml.VerdictDBNewer:
+		if _, pending := sm.configPending.Load(site.ID); !pending {
+			return nil, nil, nil
+		}
+	}
+
+	f := parsed.File
+	drift := &ConfigDrift{SiteID: site.ID, Slug: site.Slug, Path: path, Warnings: parsed.Warnings}
+	for _, field := range rep.Differences {
+		drift.Changes = append(drift.Changes, ConfigFieldChange{
+			Field:    field,
+			Current:  dbF.FieldValue(field),
+			Proposed: f.FieldValue(field),
+			Blocked:  configFieldBlocked(field, site, &f),
+		})
+	}
+	return drift, &f, nil
+}
+
+// configFieldBlocked returns why field can't be applied from f, or ""
+// when ApplyConfigYAML handles it.
+func configFieldBlocked(field string, site *types.Site, f *configyaml.File) string {
+	switch field {
+	case "name", "domain":
+		return "renaming a site is not supported from config.yaml"
+	case "multisite":
+		return "converting to or from multisite is not supported in place"
+	case "db.engine":
+		return "switching database engine needs a migration; use Migrate engine"
+	case "db.version":
+		if f.DB.Engine != site.DBEngine {
+			return "depends on the db.engine change"
+		}
+		if f.DB.Version == "" {
+			return "not set in config.yaml"
+		}
+		if !dbengine.Resolve(site).UpgradeAllowed(site.DBVersion, f.DB.Version) {
+			return "unsafe version transition; use Migrate engine"
+		}
+	case "cache.version":
+		// "none" has no version; the backend change clears it.
+		if f.Cache.Version == "" && f.Cache.Backend != "none" {
+			return "not set in config.yaml"
+		}
+	case "php_version", "cache.backend", "web_server":
+		if f.FieldValue(field) == "" {
+			return "not set in config.yaml"
+		}
+	case "resources":
+		if err := docker.ValidateResourceLimits(f.ToSite().Resources); err != nil {
+			return err.Error()
+		}
+	case "aliases":
+		// Conflicts with other sites are checked again by SetAliases.
+		fs := f.ToSite()
+		fs.ID, fs.Aliases = site.ID, NormaliseAliases(fs.Aliases)
+		if err := ValidateAliases(&fs, nil); err != nil {
+			return err.Error()
+		}
+	case "php_ini", "php_extensions":
+		fs := f.ToSite()
+		if err := ValidatePHPOverrides(&fs); err != nil {
+			return err.Error()
+		}
+	case "hooks":
+		for _, h := range f.ToHooks(site.ID) {
+			if err := h.Validate(); err != nil {
+				return fmt.Sprintf("hook %s %q: %v", h.Event, h.Command, err)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,320 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	hooksfake "github.com/PeterBooker/locorum/internal/hooks/fake"
+	"github.com/PeterBooker/locorum/internal/sites/configyaml"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// editConfigYAML writes a mutated projection of site over its
+// config.yaml and pushes the mtime past the row's second-resolution
+// updated_at, as a hand edit a moment later would.
+func editConfigYAML(t *testing.T, site types.Site, mut func(f *configyaml.File)) string {
+	t.Helper()
+	f := configyaml.FromSite(site, nil)
+	mut(&f)
+	body, err := configyaml.Render(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(site.FilesDir, configyaml.Filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func addConfigSyncSite(t *testing.T, sm *SiteManager) types.Site {
+	t.Helper()
+	site := spxTestSite(t.TempDir())
+	site.WebServer = "nginx"
+	site.CacheBackend, site.CacheVersion = "redis", "7.4"
+	if err := sm.st.AddSite(&site); err != nil {
+		t.Fatalf("AddSite: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	return *got
+}
+
+func TestApplyConfigYAML(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	editConfigYAML(t, site, func(f *configyaml.File) {
+		f.Name = "Renamed"
+		f.WebServer = "apache"
+		f.Xdebug = "debug"
+		f.Hooks = []configyaml.HookYAML{{Event: "post-start", TaskType: "wp-cli", Command: "cache flush", Enabled: true}}
+	})
+
+	drift, err := sm.ConfigDrift(site.ID)
+	if err != nil || drift == nil {
+		t.Fatalf("ConfigDrift = %v, %v", drift, err)
+	}
+	byField := map[string]ConfigFieldChange{}
+	for _, c := range drift.Changes {
+		byField[c.Field] = c
+	}
+	if c := byField["web_server"]; c.Current != "nginx" || c.Proposed != "apache" || c.Blocked != "" {
+		t.Errorf("web_server change = %+v", c)
+	}
+	if byField["name"].Blocked == "" {
+		t.Error("name change should be blocked")
+	}
+	if _, ok := byField["hooks"]; !ok {
+		t.Errorf("hooks change missing: %+v", drift.Changes)
+	}
+
+	if _, err := sm.ApplyConfigYAML(context.Background(), site.ID); err != nil {
+		t.Fatalf("ApplyConfigYAML: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if got.WebServer != "apache" || !got.XdebugEnabled || got.XdebugMode != "debug" {
+		t.Errorf("row after apply = %+v", got)
+	}
+
+	if got.Name != site.Name {
+		t.Errorf("blocked name change applied: %q", got.Name)
+	}
+	hs, _ := sm.st.ListHooks(site.ID)
+	if len(hs) != 1 || hs[0].Event != hooks.PostStart || hs[0].Command != "cache flush" {
+		t.Errorf("hooks after apply = %+v", hs)
+	}
+	if drift, err := sm.ConfigDrift(site.ID); err != nil || drift != nil {
+		t.Errorf("drift after apply = %+v, %v", drift, err)
+	}
+}
+
+func TestApplyConfigYAML_Resources(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	editConfigYAML(t, site, func(f *configyaml.File) {
+		f.Resources = configyaml.Resources{"database": {MemoryMB: 2048, Pids: 4096}}
+	})
+
+	drift, err := sm.ConfigDrift(site.ID)
+	if err != nil || drift == nil || len(drift.Changes) != 1 {
+		t.Fatalf("ConfigDrift = %+v, %v", drift, err)
+	}
+	if c := drift.Changes[0]; c.Field != "resources" || c.Current != "defaults" || c.Blocked != "" {
+		t.Errorf("resources change = %+v", c)
+	}
+	if _, err := sm.ApplyConfigYAML(context.Background(), site.ID); err != nil {
+		t.Fatalf("ApplyConfigYAML: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if db := got.Resources["database"]; db.MemoryMB != 2048 || db.Pids != 4096 {
+		t.Errorf("database limits after apply = %+v", db)
+	}
+
+	editConfigYAML(t, *got, func(f *configyaml.File) {
+		f.Resources = configyaml.Resources{"php": {MemoryMB: 8}}
+	})
+	drift, _ = sm.ConfigDrift(site.ID)
+	if drift == nil || drift.Changes[0].Blocked == "" {
+		t.Errorf("a memory limit below the floor should be blocked: %+v", drift)
+	}
+}
+
+func TestApplyConfigYAML_Aliases(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	editConfigYAML(t, site, func(f *configyaml.File) {
+		f.Aliases = []string{"spx.test"}
+	})
+
+	drift, err := sm.ConfigDrift(site.ID)
+	if err != nil || drift == nil || len(drift.Changes) != 1 {
+		t.Fatalf("ConfigDrift = %+v, %v", drift, err)
+	}
+	if c := drift.Changes[0]; c.Field != "aliases" || c.Current != "none" || c.Proposed != "spx.test" || c.Blocked != "" {
+		t.Errorf("aliases change = %+v", c)
+	}
+	if drift.NeedsStop() {
+		t.Error("an alias change should apply to a running site")
+	}
+	if _, err := sm.ApplyConfigYAML(context.Background(), site.ID); err != nil {
+		t.Fatalf("ApplyConfigYAML: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if len(got.Aliases) != 1 || got.Aliases[0] != "spx.test" {
+		t.Errorf("aliases after apply = %v", got.Aliases)
+	}
+
+	editConfigYAML(t, *got, func(f *configyaml.File) {
+		f.Aliases = []string{"not a host"}
+	})
+	drift, _ = sm.ConfigDrift(site.ID)
+	if drift == nil || drift.Changes[0].Blocked == "" {
+		t.Errorf("an invalid alias should be blocked: %+v", drift)
+	}
+}
+
+// TestApplyConfigYAML_PHPOverrides applies php_ini and php_extensions
+// edits together; they share one setter, so neither may revert the other.
+func TestApplyConfigYAML_PHPOverrides(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	editConfigYAML(t, site, func(f *configyaml.File) {
+		f.PHPIni = configyaml.PHPIni{"max_input_vars": "5000"}
+		f.PHPExtensions = configyaml.PHPExtensions{Enable: []string{"pcov"}}
+	})
+
+	drift, err := sm.ConfigDrift(site.ID)
+	if err != nil || drift == nil || len(drift.Changes) != 2 {
+		t.Fatalf("ConfigDrift = %+v, %v", drift, err)
+	}
+	if c := drift.Changes[0]; c.Field != "php_ini" || c.Current != "none" || c.Proposed != "max_input_vars=5000" {
+		t.Errorf("php_ini change = %+v", c)
+	}
+	if _, err := sm.ApplyConfigYAML(context.Background(), site.ID); err != nil {
+		t.Fatalf("ApplyConfigYAML: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if got.PHPIni["max_input_vars"] != "5000" || len(got.PHPExtensionsEnable) != 1 || got.PHPExtensionsEnable[0] != "pcov" {
+		t.Errorf("after apply: ini=%v enable=%v", got.PHPIni, got.PHPExtensionsEnable)
+	}
+}
+
+func TestConfigDrift_CacheBackend(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	editConfigYAML(t, site, func(f *configyaml.File) {
+		f.Cache = configyaml.CacheSection{Backend: "none"}
+	})
+
+	drift, err := sm.ConfigDrift(site.ID)
+	if err != nil || drift == nil {
+		t.Fatalf("ConfigDrift = %v, %v", drift, err)
+	}
+	byField := map[string]ConfigFieldChange{}
+	for _, c := range drift.Changes {
+		byField[c.Field] = c
+	}
+	if c := byField["cache.backend"]; c.Current != "redis" || c.Proposed != "none" || c.Blocked != "" {
+		t.Errorf("cache.backend change = %+v", c)
+	}
+	// "none" carries no version, and that must not block the switch.
+	if c := byField["cache.version"]; c.Current != "7.4" || c.Proposed != "" || c.Blocked != "" {
+		t.Errorf("cache.version change = %+v", c)
+	}
+}
+
+func TestApplyConfigYAML_RunningSite(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	site.Started = true
+	if _, err := sm.st.UpdateSite(&site); err != nil {
+		t.Fatal(err)
+	}
+
+	editConfigYAML(t, site, func(f *configyaml.File) { f.WebServer = "apache" })
+	if _, err := sm.ApplyConfigYAML(context.Background(), site.ID); err == nil {
+		t.Error("expected an error applying a web server change to a running site")
+	}
+
+	// Hooks don't touch container specs, so they apply while running.
+	editConfigYAML(t, site, func(f *configyaml.File) {
+		f.Hooks = []configyaml.HookYAML{{Event: "post-start", TaskType: "exec-host", Command: "true", Enabled: true}}
+	})
+	if _, err := sm.ApplyConfigYAML(context.Background(), site.ID); err != nil {
+		t.Fatalf("hooks-only apply on a running site: %v", err)
+	}
+}
+
+func TestScanConfigYAML_PendingEditsSurviveWrites(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	path := editConfigYAML(t, site, func(f *configyaml.File) { f.PHPVersion = "8.4" })
+
+	var reported []*ConfigDrift
+	sm.OnConfigDrift = func(_ string, d *ConfigDrift) { reported = append(reported, d) }
+
+	seen := map[string]time.Time{}
+	sm.scanConfigYAML(seen)
+	if len(reported) != 1 || reported[0] == nil {
+		t.Fatalf("first scan reported %v", reported)
+	}
+	sm.scanConfigYAML(seen)
+	if len(reported) != 1 {
+		t.Errorf("unchanged file re-reported: %d callbacks", len(reported))
+	}
+
+	before, _ := os.ReadFile(path)
+	sm.writeConfigYAML(&site)
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Error("writeConfigYAML overwrote unapplied edits")
+	}
+
+	if err := sm.DiscardConfigYAML(site.ID); err != nil {
+		t.Fatalf("DiscardConfigYAML: %v", err)
+	}
+	if last := reported[len(reported)-1]; last != nil {
+		t.Errorf("discard reported drift %+v, want nil", last)
+	}
+	if drift, _ := sm.ConfigDrift(site.ID); drift != nil {
+		t.Errorf("drift after discard = %+v", drift)
+	}
+}
+
+func TestApplyConfigYAML_FailureKeepsEdits(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	runner := hooksfake.New()
+	sm.hooks = runner
+	site := addConfigSyncSite(t, sm)
+	path := editConfigYAML(t, site, func(f *configyaml.File) {
+		f.WebServer = "apache"
+		f.PHPVersion = "8.4"
+	})
+	before, _ := os.ReadFile(path)
+
+	// pre-versions-change fails: the versions step goes first, so
+	// nothing else may land either.
+	runner.RunErr = errors.New("hook failed")
+	if _, err := sm.ApplyConfigYAML(context.Background(), site.ID); err == nil {
+		t.Fatal("apply succeeded despite the failing hook")
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if got.WebServer != "nginx" || got.PHPVersion != site.PHPVersion {
+		t.Errorf("row changed by a failed apply: web %q php %q", got.WebServer, got.PHPVersion)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("failed apply rewrote config.yaml")
+	}
+	if drift, _ := sm.ConfigDrift(site.ID); drift == nil || len(drift.Changes) != 2 {
+		t.Errorf("drift after failed apply = %+v", drift)
+	}
+}
+
+func TestConfigYAML_IgnoresHandEdits(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := addConfigSyncSite(t, sm)
+	editConfigYAML(t, site, func(f *configyaml.File) { f.Name = "Renamed" })
+
+	body, err := sm.ConfigYAML(site.ID)
+	if err != nil {
+		t.Fatalf("ConfigYAML: %v", err)
+	}
+	res, err := configyaml.Parse(body)
+	if err != nil {
+		t.Fatalf("parse projection: %v", err)
+	}
+	if f := res.File; f.Name != site.Name || f.Slug != site.Slug {
+		t.Errorf("projection name/slug = %q/%q, want the row's %q/%q", f.Name, f.Slug, site.Name, site.Slug)
+	}
+}
@@ -26,6 +26,7 @@ package configyaml
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -33,6 +34,7 @@ import (
 
 	"github.com/PeterBooker/locorum/internal/genmark"
 	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/phpini"
 	"github.com/PeterBooker/locorum/internal/types"
 )
 
@@ -50,9 +52,12 @@ const Filename = ".locorum/config.yaml"
 // or sites packages) so configyaml stays a leaf and can be parsed
 // without booting any other subsystem.
 var (
-	allowedEngines    = []string{"mysql", "mariadb"}
-	allowedWebServers = []string{"nginx", "apache"}
-	allowedMultisite  = []string{"", "subdirectory", "subdomain"}
+	allowedEngines       = []string{"mysql", "mariadb", "sqlite"}
+	allowedCacheBackends = []string{"redis", "valkey", "memcached", "none"}
+	allowedWebServers    = []string{"nginx", "apache"}
+	allowedMultisite     = []string{"", "subdirectory", "subdomain"}
+	allowedXdebug        = []string{"", "off", "debug", "profile", "trace", "coverage"}
+	allowedResourceKeys  = []string{"all", "php", "web", "database", "cache"}
 )
 
 // File is the on-disk YAML projection.
@@ -63,17 +68,62 @@ var (
 //   - deprecated names appear in their own field with a `,inline` or
 //     dedicated handler (see Normalize).
 type File struct {
-	SchemaVersion int        `yaml:"schema_version"`
-	Name          string     `yaml:"name"`
-	Slug          string     `yaml:"slug"`
-	Domain        string     `yaml:"domain"`
-	PublicDir     string     `yaml:"public_dir"`
-	PHPVersion    string     `yaml:"php_version"`
-	DB            DBSection  `yaml:"db"`
-	RedisVersion  string     `yaml:"redis_version"`
-	WebServer     string     `yaml:"web_server"`
-	Multisite     string     `yaml:"multisite,omitempty"`
-	Hooks         []HookYAML `yaml:"hooks,omitempty"`
+	SchemaVersion int           `yaml:"schema_version"`
+	Name          string        `yaml:"name"`
+	Slug          string        `yaml:"slug"`
+	Domain        string        `yaml:"domain"`
+	Aliases       []string      `yaml:"aliases,omitempty"`
+	PublicDir     string        `yaml:"public_dir"`
+	PHPVersion    string        `yaml:"php_version"`
+	DB            DBSection     `yaml:"db"`
+	Cache         CacheSection  `yaml:"cache"`
+	WebServer     string        `yaml:"web_server"`
+	Multisite     string        `yaml:"multisite,omitempty"`
+	Xdebug        string        `yaml:"xdebug,omitempty"`
+	Resources     Resources     `yaml:"resources,omitempty"`
+	PHPIni        PHPIni        `yaml:"php_ini,omitempty"`
+	PHPExtensions PHPExtensions `yaml:"php_extensions,omitempty"`
+	Hooks         []HookYAML    `yaml:"hooks,omitempty"`
+
+	// RedisVersion is the pre-cache-section spelling of cache.version
+	// for a Redis site. Read-only; Normalize moves it into Cache.
+	//
+	// Deprecated: use Cache.
+	RedisVersion string `yaml:"redis_version,omitempty"`
+}
+
+// CacheSection holds the object cache settings. Backend is one of
+// "redis", "valkey", "memcached" or "none"; Version is empty for "none".
+type CacheSection struct {
+	Backend string `yaml:"backend"`
+	Version string `yaml:"version,omitempty"`
+}
+
+// Resources maps a resource key ("all", "php", "web", "database",
+// "cache") to its container caps. Absent keys keep Locorum's built-in
+// defaults; "all" applies to every container and the role keys win over
+// it field by field.
+type Resources map[string]ResourceLimitYAML
+
+// PHPIni holds php.ini directives layered over the shared php.ini for
+// this site only. Values are strings; YAML numbers are accepted and
+// read as their text.
+type PHPIni map[string]string
+
+// PHPExtensions lists wodby/php extensions to switch on or off for
+// this site. Xdebug and SPX have their own settings and are rejected
+// here.
+type PHPExtensions struct {
+	Enable  []string `yaml:"enable,omitempty"`
+	Disable []string `yaml:"disable,omitempty"`
+}
+
+// ResourceLimitYAML projects one types.ResourceLimit. Zero fields are
+// omitted and mean "use the default".
+type ResourceLimitYAML struct {
+	MemoryMB int64   `yaml:"memory_mb,omitempty"`
+	CPUs     float64 `yaml:"cpus,omitempty"`
+	Pids     int64   `yaml:"pids,omitempty"`
 }
 
 // DBSection holds the database engine settings. The deprecated
@@ -121,9 +171,18 @@ func FromSite(s types.Site, hs []hooks.Hook) File {
 			Version:     s.DBVersion,
 			PublishPort: s.PublishDBPort,
 		},
-		RedisVersion: s.RedisVersion,
-		WebServer:    s.WebServer,
-		Multisite:    s.Multisite,
+		Cache: CacheSection{
+			Backend: s.CacheBackend,
+			Version: s.CacheVersion,
+		},
+		WebServer: s.WebServer,
+		Multisite: s.Multisite,
+	}
+
+	// Only the active mode is projected; the remembered mode of a
+	// disabled site is UI state, not configuration.
+	if s.XdebugEnabled {
+		f.Xdebug = s.XdebugMode
 	}
 
 	// Default to MySQL for legacy rows missing DBEngine — same
@@ -134,6 +193,35 @@ func FromSite(s types.Site, hs []hooks.Hook) File {
 	if f.DB.Version == "" {
 		f.DB.Version = s.MySQLVersion //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
 	}
+	// Same fallback for the cache: rows without a backend are Redis.
+	if f.Cache.Backend == "" {
+		f.Cache.Backend = "redis"
+	}
+	if f.Cache.Version == "" && f.Cache.Backend == "redis" {
+		f.Cache.Version = s.RedisVersion //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+	}
+
+	if len(s.Aliases) > 0 {
+		f.Aliases = append([]string(nil), s.Aliases...)
+	}
+
+	if len(s.Resources) > 0 {
+		f.Resources = make(Resources, len(s.Resources))
+		for k, l := range s.Resources {
+			f.Resources[k] = ResourceLimitYAML{MemoryMB: l.MemoryMB, CPUs: l.CPUs, Pids: l.Pids}
+		}
+	}
+
+	if len(s.PHPIni) > 0 {
+		f.PHPIni = make(PHPIni, len(s.PHPIni))
+		for k, v := range s.PHPIni {
+			f.PHPIni[k] = v
+		}
+	}
+	f.PHPExtensions = PHPExtensions{
+		Enable:  append([]string(nil), s.PHPExtensionsEnable...),
+		Disable: append([]string(nil), s.PHPExtensionsDisable...),
+	}
 
 	if len(hs) > 0 {
 		f.Hooks = make([]HookYAML, 0, len(hs))
@@ -162,6 +250,82 @@ func FromSite(s types.Site, hs []hooks.Hook) File {
 	return f
 }
 
+// ToSite is the inverse of FromSite for the projected fields. The
+// fields FromSite leaves out (id, files_dir, db_password, salts) stay
+// zero — the caller fills them for the machine it is importing onto.
+func (f File) ToSite() types.Site {
+	s := types.Site{
+		Name:          f.Name,
+		Slug:          f.Slug,
+		Domain:        f.Domain,
+		PublicDir:     f.PublicDir,
+		PHPVersion:    f.PHPVersion,
+		DBEngine:      f.DB.Engine,
+		DBVersion:     f.DB.Version,
+		PublishDBPort: f.DB.PublishPort,
+		CacheBackend:  f.Cache.Backend,
+		CacheVersion:  f.Cache.Version,
+		WebServer:     f.WebServer,
+		Multisite:     f.Multisite,
+	}
+	if f.Xdebug != "" && f.Xdebug != "off" {
+		s.XdebugEnabled = true
+		s.XdebugMode = f.Xdebug
+	}
+	if len(f.Aliases) > 0 {
+		s.Aliases = append([]string(nil), f.Aliases...)
+	}
+	if len(f.Resources) > 0 {
+		s.Resources = make(types.ResourceLimits, len(f.Resources))
+		for k, l := range f.Resources {
+			s.Resources[k] = types.ResourceLimit{MemoryMB: l.MemoryMB, CPUs: l.CPUs, Pids: l.Pids}
+		}
+	}
+	if len(f.PHPIni) > 0 {
+		s.PHPIni = make(map[string]string, len(f.PHPIni))
+		for k, v := range f.PHPIni {
+			s.PHPIni[k] = v
+		}
+	}
+	if len(f.PHPExtensions.Enable) > 0 {
+		s.PHPExtensionsEnable = append([]string(nil), f.PHPExtensions.Enable...)
+	}
+	if len(f.PHPExtensions.Disable) > 0 {
+		s.PHPExtensionsDisable = append([]string(nil), f.PHPExtensions.Disable...)
+	}
+	return s
+}
+
+// ToHooks converts the projected hooks back into rows for siteID, in
+// (event, position) order so a storage layer that assigns the next
+// free position on insert reproduces the original ordering.
+func (f File) ToHooks(siteID string) []hooks.Hook {
+	if len(f.Hooks) == 0 {
+		return nil
+	}
+	sortable := append([]HookYAML(nil), f.Hooks...)
+	sort.SliceStable(sortable, func(i, j int) bool {
+		if sortable[i].Event != sortable[j].Event {
+			return sortable[i].Event < sortable[j].Event
+		}
+		return sortable[i].Position < sortable[j].Position
+	})
+	out := make([]hooks.Hook, 0, len(sortable))
+	for _, h := range sortable {
+		out = append(out, hooks.Hook{
+			SiteID:    siteID,
+			Event:     hooks.Event(h.Event),
+			Position:  h.Position,
+			TaskType:  hooks.TaskType(h.TaskType),
+			Command:   h.Command,
+			Service:   h.Service,
+			RunAsUser: h.RunAsUser,
+			Enabled:   h.Enabled,
+		})
+	}
+	return out
+}
+
 // Render serialises a File with the canonical genmark header. The
 // caller writes the bytes via genmark.WriteIfManaged (or its file-
 // owned equivalent).
@@ -235,6 +399,10 @@ func Parse(data []byte) (ParseResult, error) {
 		return ParseResult{}, fmt.Errorf("%w: db.engine=%q (allowed: %s)",
 			ErrInvalidEnum, f.DB.Engine, strings.Join(allowedEngines, ", "))
 	}
+	if f.Cache.Backend != "" && !validEnum(f.Cache.Backend, allowedCacheBackends) {
+		return ParseResult{}, fmt.Errorf("%w: cache.backend=%q (allowed: %s)",
+			ErrInvalidEnum, f.Cache.Backend, strings.Join(allowedCacheBackends, ", "))
+	}
 	if f.WebServer != "" && !validEnum(f.WebServer, allowedWebServers) {
 		return ParseResult{}, fmt.Errorf("%w: web_server=%q (allowed: %s)",
 			ErrInvalidEnum, f.WebServer, strings.Join(allowedWebServers, ", "))
@@ -243,6 +411,30 @@ func Parse(data []byte) (ParseResult, error) {
 		return ParseResult{}, fmt.Errorf("%w: multisite=%q (allowed: %s)",
 			ErrInvalidEnum, f.Multisite, "subdirectory, subdomain, or empty")
 	}
+	if !validEnum(f.Xdebug, allowedXdebug) {
+		return ParseResult{}, fmt.Errorf("%w: xdebug=%q (allowed: %s)",
+			ErrInvalidEnum, f.Xdebug, strings.Join(allowedXdebug[1:], ", "))
+	}
+	// "off" is accepted for readability but means the same as an
+	// absent key, which is what FromSite renders for a disabled site.
+	if f.Xdebug == "off" {
+		f.Xdebug = ""
+	}
+	for _, k := range f.Resources.keys() {
+		if !validEnum(k, allowedResourceKeys) {
+			return ParseResult{}, fmt.Errorf("%w: resources.%s (allowed: %s)",
+				ErrInvalidEnum, k, strings.Join(allowedResourceKeys, ", "))
+		}
+		if l := f.Resources[k]; l.MemoryMB < 0 || l.CPUs < 0 || l.Pids < 0 {
+			return ParseResult{}, fmt.Errorf("configyaml: resources.%s: limits must not be negative", k)
+		}
+	}
+	if err := phpini.ValidateSettings(f.PHPIni); err != nil {
+		return ParseResult{}, fmt.Errorf("configyaml: php_ini: %w", err)
+	}
+	if err := phpini.ValidateExtensions(f.PHPExtensions.Enable, f.PHPExtensions.Disable); err != nil {
+		return ParseResult{}, fmt.Errorf("configyaml: php_extensions: %w", err)
+	}
 
 	return ParseResult{File: f, Warnings: warnings}, nil
 }
@@ -257,7 +449,18 @@ func (f *File) Normalize() []string {
 		warnings = append(warnings,
 			"db.mysql_version is deprecated; use db.version (will be removed in a future schema)")
 	}
-	f.DB.MySQLVersion = "" // never write the alias back out
+	f.DB.MySQLVersion = ""    // never write the alias back out
+	if f.RedisVersion != "" { //nolint:staticcheck // SA1019: alias migration
+		if f.Cache.Backend == "" {
+			f.Cache.Backend = "redis"
+		}
+		if f.Cache.Version == "" && f.Cache.Backend == "redis" {
+			f.Cache.Version = f.RedisVersion //nolint:staticcheck // SA1019: alias migration
+		}
+		warnings = append(warnings,
+			"redis_version is deprecated; use cache.backend + cache.version (will be removed in a future schema)")
+	}
+	f.RedisVersion = "" //nolint:staticcheck // SA1019: never write the alias back out
 	return warnings
 }
 
@@ -326,6 +529,9 @@ func diffFields(a, b File) []string {
 	if a.Domain != b.Domain {
 		diffs = append(diffs, "domain")
 	}
+	if !slices.Equal(a.Aliases, b.Aliases) {
+		diffs = append(diffs, "aliases")
+	}
 	if a.PublicDir != b.PublicDir {
 		diffs = append(diffs, "public_dir")
 	}
@@ -341,8 +547,11 @@ func diffFields(a, b File) []string {
 	if a.DB.PublishPort != b.DB.PublishPort {
 		diffs = append(diffs, "db.publish_port")
 	}
-	if a.RedisVersion != b.RedisVersion {
-		diffs = append(diffs, "redis_version")
+	if a.Cache.Backend != b.Cache.Backend {
+		diffs = append(diffs, "cache.backend")
+	}
+	if a.Cache.Version != b.Cache.Version {
+		diffs = append(diffs, "cache.version")
 	}
 	if a.WebServer != b.WebServer {
 		diffs = append(diffs, "web_server")
@@ -350,12 +559,80 @@ func diffFields(a, b File) []string {
 	if a.Multisite != b.Multisite {
 		diffs = append(diffs, "multisite")
 	}
+	if a.Xdebug != b.Xdebug {
+		diffs = append(diffs, "xdebug")
+	}
+	if !resourcesEqual(a.Resources, b.Resources) {
+		diffs = append(diffs, "resources")
+	}
+	if !phpIniEqual(a.PHPIni, b.PHPIni) {
+		diffs = append(diffs, "php_ini")
+	}
+	if !slices.Equal(a.PHPExtensions.Enable, b.PHPExtensions.Enable) ||
+		!slices.Equal(a.PHPExtensions.Disable, b.PHPExtensions.Disable) {
+		diffs = append(diffs, "php_extensions")
+	}
 	if !hooksEqual(a.Hooks, b.Hooks) {
 		diffs = append(diffs, "hooks")
 	}
 	return diffs
 }
 
+// FieldValue renders one of the canonical field names diffFields
+// reports as a short display string. Hooks collapse to a count — the
+// per-hook detail is too long for a diff line. Unknown names return "".
+func (f File) FieldValue(field string) string {
+	switch field {
+	case "name":
+		return f.Name
+	case "domain":
+		return f.Domain
+	case "aliases":
+		if len(f.Aliases) == 0 {
+			return "none"
+		}
+		return strings.Join(f.Aliases, ", ")
+	case "public_dir":
+		return f.PublicDir
+	case "php_version":
+		return f.PHPVersion
+	case "db.engine":
+		return f.DB.Engine
+	case "db.version":
+		return f.DB.Version
+	case "db.publish_port":
+		if f.DB.PublishPort {
+			return "true"
+		}
+		return "false"
+	case "cache.backend":
+		return f.Cache.Backend
+	case "cache.version":
+		return f.Cache.Version
+	case "web_server":
+		return f.WebServer
+	case "multisite":
+		return f.Multisite
+	case "xdebug":
+		if f.Xdebug == "" {
+			return "off"
+		}
+		return f.Xdebug
+	case "resources":
+		return f.Resources.String()
+	case "php_ini":
+		return f.PHPIni.String()
+	case "php_extensions":
+		return f.PHPExtensions.String()
+	case "hooks":
+		if len(f.Hooks) == 1 {
+			return "1 hook"
+		}
+		return fmt.Sprintf("%d hooks", len(f.Hooks))
+	}
+	return ""
+}
+
 func hooksEqual(a, b []HookYAML) bool {
 	if len(a) != len(b) {
 		return false
@@ -367,3 +644,97 @@ func hooksEqual(a, b []HookYAML) bool {
 	}
 	return true
 }
+
+// keys returns the resource keys in sorted order so validation errors
+// and FieldValue output are deterministic.
+func (r Resources) keys() []string {
+	out := make([]string, 0, len(r))
+	for k := range r {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// String renders the overrides on one line, e.g.
+// "php: 1024MB 2cpu; database: 2048MB". "defaults" when empty.
+func (r Resources) String() string {
+	parts := make([]string, 0, len(r))
+	for _, k := range r.keys() {
+		l := r[k]
+		var vals []string
+		if l.MemoryMB > 0 {
+			vals = append(vals, fmt.Sprintf("%dMB", l.MemoryMB))
+		}
+		if l.CPUs > 0 {
+			vals = append(vals, fmt.Sprintf("%gcpu", l.CPUs))
+		}
+		if l.Pids > 0 {
+			vals = append(vals, fmt.Sprintf("%dpids", l.Pids))
+		}
+		if len(vals) == 0 {
+			continue
+		}
+		parts = append(parts, k+": "+strings.Join(vals, " "))
+	}
+	if len(parts) == 0 {
+		return "defaults"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// resourcesEqual compares two override maps, treating an entry that
+// overrides nothing the same as an absent one.
+func resourcesEqual(a, b Resources) bool {
+	for k, l := range a {
+		if b[k] != l {
+			return false
+		}
+	}
+	for k, l := range b {
+		if a[k] != l {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the directives on one line, e.g.
+// "max_input_vars=5000, memory_limit=512M". "none" when empty.
+func (p PHPIni) String() string {
+	if len(p) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(p))
+	for _, k := range phpini.SortedKeys(p) {
+		parts = append(parts, k+"="+p[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String renders the switches as "+pcov -newrelic". "none" when empty.
+func (e PHPExtensions) String() string {
+	parts := make([]string, 0, len(e.Enable)+len(e.Disable))
+	for _, x := range e.Enable {
+		parts = append(parts, "+"+x)
+	}
+	for _, x := range e.Disable {
+		parts = append(parts, "-"+x)
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " ")
+}
+
+func phpIniEqual(a, b PHPIni) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package configyaml
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"testing"
 
@@ -22,7 +23,8 @@ func sampleSite() types.Site {
 		DBEngine:      "mysql",
 		DBVersion:     "8.4",
 		MySQLVersion:  "8.4",
-		RedisVersion:  "7",
+		CacheBackend:  "redis",
+		CacheVersion:  "7",
 		WebServer:     "nginx",
 		Multisite:     "",
 		PublishDBPort: false,
@@ -195,6 +197,7 @@ func TestParse_RejectsInvalidEnums(t *testing.T) {
 		"engine":    []byte("schema_version: 1\nname: x\nslug: x\ndomain: x\ndb: {engine: postgres, version: \"1\"}\nweb_server: nginx\n"),
 		"web":       []byte("schema_version: 1\nname: x\nslug: x\ndomain: x\ndb: {engine: mysql, version: \"1\"}\nweb_server: iis\n"),
 		"multisite": []byte("schema_version: 1\nname: x\nslug: x\ndomain: x\ndb: {engine: mysql, version: \"1\"}\nweb_server: nginx\nmultisite: hyperdrive\n"),
+		"xdebug":    []byte("schema_version: 1\nname: x\nslug: x\ndomain: x\ndb: {engine: mysql, version: \"1\"}\nweb_server: nginx\nxdebug: develop\n"),
 	}
 	for name, body := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -232,6 +235,202 @@ web_server: nginx
 	}
 }
 
+func TestNormalize_DeprecatedRedisVersion(t *testing.T) {
+	body := []byte(`schema_version: 1
+name: legacy
+slug: legacy
+domain: legacy.localhost
+php_version: "8.3"
+db:
+  engine: mysql
+  version: "8.0"
+redis_version: "7.2"
+web_server: nginx
+`)
+	res, err := Parse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.File.Cache.Backend != "redis" || res.File.Cache.Version != "7.2" {
+		t.Errorf("cache not migrated from redis_version: %+v", res.File.Cache)
+	}
+	if res.File.RedisVersion != "" { //nolint:staticcheck // SA1019: asserting the alias is cleared
+		t.Errorf("redis_version should have been cleared after migration")
+	}
+	if len(res.Warnings) == 0 {
+		t.Errorf("expected a deprecation warning")
+	}
+}
+
+func TestParse_RejectsUnknownCacheBackend(t *testing.T) {
+	body := []byte(`schema_version: 1
+name: x
+slug: x
+domain: x.localhost
+db:
+  engine: mysql
+cache:
+  backend: varnish
+`)
+	if _, err := Parse(body); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Parse = %v, want ErrInvalidEnum", err)
+	}
+}
+
+func TestFromSite_XdebugOnlyWhenEnabled(t *testing.T) {
+	s := sampleSite()
+	s.XdebugMode = "profile"
+	if f := FromSite(s, nil); f.Xdebug != "" {
+		t.Errorf("disabled site projected xdebug=%q", f.Xdebug)
+	}
+	s.XdebugEnabled = true
+	if f := FromSite(s, nil); f.Xdebug != "profile" {
+		t.Errorf("xdebug = %q, want profile", f.Xdebug)
+	}
+}
+
+func TestParse_XdebugOffMeansAbsent(t *testing.T) {
+	body := []byte("schema_version: 1\nname: x\nslug: x\ndomain: x\ndb: {engine: mysql, version: \"1\"}\nweb_server: nginx\nxdebug: off\n")
+	res, err := Parse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.File.Xdebug != "" {
+		t.Errorf("xdebug: off should normalise to empty, got %q", res.File.Xdebug)
+	}
+}
+
+func TestResources_RoundTripAndDiff(t *testing.T) {
+	src := sampleSite()
+	src.Resources = types.ResourceLimits{"php": {MemoryMB: 1024, CPUs: 1.5}}
+	f := FromSite(src, nil)
+	out, err := Render(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "memory_mb: 1024") {
+		t.Errorf("rendered file missing resources:\n%s", out)
+	}
+	res, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.File.ToSite().Resources["php"]; got != src.Resources["php"] {
+		t.Errorf("php limits after round trip = %+v", got)
+	}
+
+	edited := res.File
+	edited.Resources = Resources{"php": {MemoryMB: 2048}}
+	if diffs := diffFields(edited, f); len(diffs) != 1 || diffs[0] != "resources" {
+		t.Errorf("diffFields = %v, want [resources]", diffs)
+	}
+	if v := edited.FieldValue("resources"); v != "php: 2048MB" {
+		t.Errorf("FieldValue(resources) = %q", v)
+	}
+	if v := FromSite(sampleSite(), nil).FieldValue("resources"); v != "defaults" {
+		t.Errorf("FieldValue(resources) without overrides = %q", v)
+	}
+}
+
+func TestParse_RejectsUnknownResourceKey(t *testing.T) {
+	body := []byte("schema_version: 1\nname: x\nslug: x\ndomain: x\ndb: {engine: mysql, version: \"1\"}\nresources:\n  mail: {memory_mb: 256}\n")
+	if _, err := Parse(body); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Parse = %v, want ErrInvalidEnum", err)
+	}
+}
+
+func TestPHPOverrides_RoundTripAndDiff(t *testing.T) {
+	src := sampleSite()
+	src.PHPIni = map[string]string{"max_input_vars": "5000"}
+	src.PHPExtensionsEnable = []string{"pcov"}
+	f := FromSite(src, nil)
+	out, err := Render(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := res.File.ToSite()
+	if got.PHPIni["max_input_vars"] != "5000" || len(got.PHPExtensionsEnable) != 1 {
+		t.Errorf("after round trip: ini=%v enable=%v", got.PHPIni, got.PHPExtensionsEnable)
+	}
+	if diffs := diffFields(res.File, f); len(diffs) != 0 {
+		t.Errorf("diffFields after round trip = %v", diffs)
+	}
+	if v := f.FieldValue("php_extensions"); v != "+pcov" {
+		t.Errorf("FieldValue(php_extensions) = %q", v)
+	}
+}
+
+func TestAliases_RoundTripAndDiff(t *testing.T) {
+	src := sampleSite()
+	src.Aliases = []string{"shop.test", "*.shop.test"}
+	f := FromSite(src, nil)
+	out, err := Render(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.File.ToSite().Aliases; !slices.Equal(got, src.Aliases) {
+		t.Errorf("aliases after round trip = %v", got)
+	}
+	if diffs := diffFields(FromSite(sampleSite(), nil), f); !slices.Equal(diffs, []string{"aliases"}) {
+		t.Errorf("diffFields = %v", diffs)
+	}
+	if v := f.FieldValue("aliases"); v != "shop.test, *.shop.test" {
+		t.Errorf("FieldValue(aliases) = %q", v)
+	}
+}
+
+func TestParse_PHPOverrides(t *testing.T) {
+	head := "schema_version: 1\nname: x\nslug: x\ndomain: x\ndb: {engine: mysql, version: \"1\"}\n"
+	// Unquoted YAML numbers are read as their text.
+	res, err := Parse([]byte(head + "php_ini:\n  max_input_vars: 5000\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if v := res.File.PHPIni["max_input_vars"]; v != "5000" {
+		t.Errorf("max_input_vars = %q, want 5000", v)
+	}
+	if _, err := Parse([]byte(head + "php_ini:\n  extension: evil.so\n")); err == nil {
+		t.Error("Parse accepted a blocked directive")
+	}
+	if _, err := Parse([]byte(head + "php_extensions:\n  enable: [xdebug]\n")); err == nil {
+		t.Error("Parse accepted xdebug in php_extensions")
+	}
+}
+
+func TestToSite_InvertsFromSite(t *testing.T) {
+	src := sampleSite()
+	src.XdebugEnabled, src.XdebugMode = true, "debug"
+	f := FromSite(src, sampleHooks())
+
+	got := f.ToSite()
+	if got.ID != "" || got.FilesDir != "" || got.DBPassword != "" || got.Salts != "" {
+		t.Errorf("ToSite leaked machine-local fields: %+v", got)
+	}
+	if got.Slug != src.Slug || got.PHPVersion != src.PHPVersion || got.DBVersion != src.DBVersion ||
+		got.WebServer != src.WebServer || !got.XdebugEnabled || got.XdebugMode != "debug" {
+		t.Errorf("ToSite = %+v", got)
+	}
+	if again := FromSite(got, nil); len(diffFields(again, FromSite(src, nil))) != 0 {
+		t.Errorf("FromSite(ToSite(f)) drifted: %v", diffFields(again, FromSite(src, nil)))
+	}
+
+	hs := f.ToHooks("new-id")
+	if len(hs) != 2 {
+		t.Fatalf("ToHooks len = %d, want 2", len(hs))
+	}
+	if hs[0].Event != "post-start" || hs[0].SiteID != "new-id" || hs[1].TaskType != hooks.TaskExecHost {
+		t.Errorf("ToHooks = %+v", hs)
+	}
+}
+
 func TestReconcile_Equal(t *testing.T) {
 	a := FromSite(sampleSite(), nil)
 	b := FromSite(sampleSite(), nil)
@@ -283,9 +482,11 @@ func TestDiffFields_DetectsAllProjectedChanges(t *testing.T) {
 		"db.engine":       func(f *File) { f.DB.Engine = "mariadb" },
 		"db.version":      func(f *File) { f.DB.Version = "x" },
 		"db.publish_port": func(f *File) { f.DB.PublishPort = !f.DB.PublishPort },
-		"redis_version":   func(f *File) { f.RedisVersion = "x" },
+		"cache.backend":   func(f *File) { f.Cache.Backend = "memcached" },
+		"cache.version":   func(f *File) { f.Cache.Version = "x" },
 		"web_server":      func(f *File) { f.WebServer = "apache" },
 		"multisite":       func(f *File) { f.Multisite = "subdomain" },
+		"xdebug":          func(f *File) { f.Xdebug = "debug" },
 	}
 	for name, mut := range mutations {
 		t.Run(name, func(t *testing.T) {
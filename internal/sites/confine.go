@@ -0,0 +1,73 @@
+package sites
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathOutsideSite is returned by ConfineSitePath for a host path a
+// site-limited caller may not touch.
+var ErrPathOutsideSite = errors.New("path is outside the site's directories")
+
+// ExportsDir is where archives of the site with the given slug belong:
+// ~/locorum/exports/<slug>. It is kept apart from the files directory,
+// which the web server publishes and every export archives.
+func (sm *SiteManager) ExportsDir(slug string) string {
+	return filepath.Join(sm.homeDir, "locorum", "exports", slug)
+}
+
+// ConfineSitePath bounds a host path supplied by a caller limited to one
+// site. A write target must lie in the site's exports directory, which is
+// created on demand; a read may also come from the site's files
+// directory. Symlinks are resolved before the check, and the resolved
+// path is returned for the caller to use in place of path.
+func (sm *SiteManager) ConfineSitePath(siteID, path string, write bool) (string, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return "", fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return "", fmt.Errorf("site %q not found", siteID)
+	}
+
+	exportsDir := sm.ExportsDir(site.Slug)
+	roots := []string{exportsDir}
+	if !write {
+		roots = append(roots, site.FilesDir)
+	}
+
+	path = filepath.Clean(path)
+	var resolved string
+	if write {
+		if err := os.MkdirAll(exportsDir, 0o755); err != nil {
+			return "", fmt.Errorf("creating exports directory: %w", err)
+		}
+		// The file need not exist yet; resolve its directory instead.
+		dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrPathOutsideSite, path)
+		}
+		resolved = filepath.Join(dir, filepath.Base(path))
+	} else {
+		resolved, err = filepath.EvalSymlinks(path)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrPathOutsideSite, path)
+		}
+	}
+
+	for _, root := range roots {
+		root, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, resolved)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return resolved, nil
+	}
+	return "", fmt.Errorf("%w: %s (use %s)", ErrPathOutsideSite, path, exportsDir)
+}
@@ -0,0 +1,69 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfineSitePath(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	sm.homeDir = t.TempDir()
+	site := newLanSite(t, sm)
+	exports := sm.ExportsDir(site.Slug)
+
+	dump := filepath.Join(site.FilesDir, "dump.sql")
+	if err := os.WriteFile(dump, []byte("SELECT 1;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.sql")
+	if err := os.WriteFile(outside, []byte("SELECT 1;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(site.FilesDir, "link.sql")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name  string
+		path  string
+		write bool
+		ok    bool
+	}{
+		{"export into exports dir", filepath.Join(exports, "a.tar.gz"), true, true},
+		{"export into files dir", filepath.Join(site.FilesDir, "a.tar.gz"), true, false},
+		{"export elsewhere", filepath.Join(sm.homeDir, ".bashrc"), true, false},
+		{"export escaping with ..", filepath.Join(exports, "..", "other", "a.tar.gz"), true, false},
+		{"read from files dir", dump, false, true},
+		{"read from elsewhere", outside, false, false},
+		{"read through a symlink", link, false, false},
+	} {
+		_, err := sm.ConfineSitePath(site.ID, c.path, c.write)
+		if c.ok && err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+		if !c.ok && !errors.Is(err, ErrPathOutsideSite) {
+			t.Errorf("%s: err = %v, want ErrPathOutsideSite", c.name, err)
+		}
+	}
+}
+
+func TestExportSite_RefusesExistingDestination(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+
+	dest := filepath.Join(t.TempDir(), "keep.txt")
+	if err := os.WriteFile(dest, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := sm.ExportSite(context.Background(), site.ID, dest)
+	if !errors.Is(err, ErrExportExists) {
+		t.Fatalf("err = %v, want ErrExportExists", err)
+	}
+	if b, _ := os.ReadFile(dest); string(b) != "keep" {
+		t.Errorf("destination overwritten: %q", b)
+	}
+}
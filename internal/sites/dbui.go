@@ -0,0 +1,46 @@
+package sites
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/dbui"
+	"github.com/PeterBooker/locorum/internal/docker"
+)
+
+// DBUILoginURL returns a short-lived link that logs the global DB UI
+// (Adminer or phpMyAdmin at db.localhost) straight into the site's
+// database. The credentials travel sealed in the link; see package
+// dbui. Returns ErrSiteNotRunning when the site is stopped, since the
+// database container the link points at only exists while it runs.
+func (sm *SiteManager) DBUILoginURL(siteID string) (string, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return "", fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return "", fmt.Errorf("site %q not found", siteID)
+	}
+	if isSQLite(site) {
+		return "", errors.New("site uses SQLite; it has no database server for the DB UI to connect to")
+	}
+	if !site.Started {
+		return "", ErrSiteNotRunning
+	}
+
+	key, err := dbui.LoadKey(sm.homeDir)
+	if err != nil {
+		return "", err
+	}
+	token, err := dbui.Seal(key, dbui.Login{
+		Server:   docker.SiteContainerName(site.Slug, "database"),
+		User:     "wordpress",
+		Password: site.DBPassword,
+		DB:       "wordpress",
+	}, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("sealing DB UI login: %w", err)
+	}
+	return dbui.URL(token), nil
+}
@@ -0,0 +1,47 @@
+package sites
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/dbui"
+)
+
+func TestDBUILoginURL(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	sm.homeDir = t.TempDir()
+	site := newLanSite(t, sm)
+
+	if _, err := sm.DBUILoginURL(site.ID); !errors.Is(err, ErrSiteNotRunning) {
+		t.Fatalf("stopped site: err = %v, want ErrSiteNotRunning", err)
+	}
+	if _, err := sm.DBUILoginURL("missing"); err == nil {
+		t.Error("unknown site: no error")
+	}
+
+	site.Started = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+	link, err := sm.DBUILoginURL(site.ID)
+	if err != nil {
+		t.Fatalf("DBUILoginURL: %v", err)
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Host != dbui.Host {
+		t.Fatalf("link = %q", link)
+	}
+	key, err := dbui.LoadKey(sm.homeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := dbui.Open(key, u.Query().Get(dbui.TokenParam), time.Now())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got.Server != "locorum-lansite-database" || got.User != "wordpress" || got.Password != "pw" || got.DB != "wordpress" {
+		t.Errorf("login = %+v", got)
+	}
+}
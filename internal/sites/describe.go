@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/PeterBooker/locorum/internal/cachebackend"
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/mail"
 	"github.com/PeterBooker/locorum/internal/storage"
 	"github.com/PeterBooker/locorum/internal/types"
 )
@@ -18,22 +20,23 @@ import (
 // server's describe_site tool. JSON tags pin the wire format for IPC and
 // MCP — rename fields here only with a coordinated client bump.
 type SiteDescription struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Slug      string `json:"slug"`
-	Domain    string `json:"domain"`
-	URL       string `json:"url"`
-	FilesDir  string `json:"filesDir"`
-	PublicDir string `json:"publicDir"`
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Slug      string   `json:"slug"`
+	Domain    string   `json:"domain"`
+	URL       string   `json:"url"`
+	Aliases   []string `json:"aliases,omitempty"` // extra hostnames besides Domain
+	FilesDir  string   `json:"filesDir"`
+	PublicDir string   `json:"publicDir"`
 
 	Started bool `json:"started"`
 
 	WebServer string `json:"webServer"`
 	Multisite string `json:"multisite,omitempty"`
 
-	PHP      VersionInfo `json:"php"`
-	Database DBInfo      `json:"database"`
-	Redis    VersionInfo `json:"redis"`
+	PHP      PHPInfo   `json:"php"`
+	Database DBInfo    `json:"database"`
+	Cache    CacheInfo `json:"cache"`
 
 	Containers []ContainerInfo `json:"containers,omitempty"`
 
@@ -42,15 +45,35 @@ type SiteDescription struct {
 
 	SnapshotsCount int `json:"snapshotsCount"`
 
-	Profiling SPXInfo `json:"profiling,omitempty"`
+	Profiling SPXInfo    `json:"profiling,omitempty"`
+	Xdebug    XdebugInfo `json:"xdebug"`
+	Mail      MailInfo   `json:"mail"`
+
+	// Resources is the site's per-role container limit overrides;
+	// absent when every container runs with the built-in defaults.
+	Resources types.ResourceLimits `json:"resources,omitempty"`
 
 	CreatedAt string `json:"createdAt,omitempty"`
 	UpdatedAt string `json:"updatedAt,omitempty"`
 }
 
-// VersionInfo captures the runtime version of one of the site's services.
-type VersionInfo struct {
-	Version string `json:"version"`
+// PHPInfo describes the PHP runtime: its version plus any per-site
+// php.ini directives and extension switches layered over the shared
+// configuration.
+type PHPInfo struct {
+	Version            string            `json:"version"`
+	INI                map[string]string `json:"ini,omitempty"`
+	ExtensionsEnabled  []string          `json:"extensionsEnabled,omitempty"`
+	ExtensionsDisabled []string          `json:"extensionsDisabled,omitempty"`
+}
+
+// CacheInfo describes the object cache service. Backend is "none" and
+// the rest empty when the site runs without one.
+type CacheInfo struct {
+	Backend string `json:"backend"`
+	Version string `json:"version,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Port    int    `json:"port,omitempty"`
 }
 
 // DBInfo describes the database service. Credentials are user-static
@@ -105,6 +128,24 @@ type SPXInfo struct {
 	Enabled bool `json:"enabled"`
 }
 
+// XdebugInfo reports the Xdebug toggle and active mode. Mode is empty
+// while disabled so clients don't mistake the remembered mode for the
+// running one.
+type XdebugInfo struct {
+	Enabled bool   `json:"enabled"`
+	Mode    string `json:"mode,omitempty"`
+}
+
+// MailInfo reports where the site's outgoing mail goes. The relay
+// fields are set only in relay mode; the relay password is never
+// included.
+type MailInfo struct {
+	Mode         string   `json:"mode"`
+	RelayHost    string   `json:"relayHost,omitempty"`
+	RelayUser    string   `json:"relayUser,omitempty"`
+	RelayDomains []string `json:"relayDomains,omitempty"`
+}
+
 // DescribeOptions controls which optional sections require live Docker /
 // disk lookups. The plain Describe() takes the cheap path; richer clients
 // (CLI / MCP) can opt in.
@@ -178,6 +219,7 @@ func (sm *SiteManager) DescribeAll(ctx context.Context, opts DescribeOptions) ([
 // guarantee site is non-nil.
 func (sm *SiteManager) describeFromSite(ctx context.Context, site *types.Site, opts DescribeOptions) (*SiteDescription, error) {
 	eng := dbengine.Resolve(site)
+	cache := cachebackend.Resolve(site)
 
 	desc := &SiteDescription{
 		ID:        site.ID,
@@ -185,36 +227,63 @@ func (sm *SiteManager) describeFromSite(ctx context.Context, site *types.Site, o
 		Slug:      site.Slug,
 		Domain:    site.Domain,
 		URL:       "https://" + site.Domain,
+		Aliases:   site.Aliases,
 		FilesDir:  site.FilesDir,
 		PublicDir: site.PublicDir,
 		Started:   site.Started,
 		WebServer: site.WebServer,
 		Multisite: site.Multisite,
-		PHP:       VersionInfo{Version: site.PHPVersion},
+		PHP: PHPInfo{
+			Version:            site.PHPVersion,
+			INI:                site.PHPIni,
+			ExtensionsEnabled:  site.PHPExtensionsEnable,
+			ExtensionsDisabled: site.PHPExtensionsDisable,
+		},
 		Database: DBInfo{
 			Engine:    site.DBEngine,
 			Version:   site.DBVersion,
 			Username:  "wordpress",
 			Database:  "wordpress",
-			Container: docker.SiteContainerName(site.Slug, "database"),
+			Container: docker.SiteContainerName(site.Slug, eng.Service()),
+		},
+		Cache: CacheInfo{
+			Backend: string(cache),
+			Version: site.CacheVersion,
+			Host:    cachebackend.Service(cache),
+			Port:    cachebackend.Port(cache),
 		},
-		Redis: VersionInfo{Version: site.RedisVersion},
 		Containers: []ContainerInfo{
 			{Service: "web", Name: docker.SiteContainerName(site.Slug, "web")},
 			{Service: "php", Name: docker.SiteContainerName(site.Slug, "php")},
-			{Service: "database", Name: docker.SiteContainerName(site.Slug, "database")},
-			{Service: "redis", Name: docker.SiteContainerName(site.Slug, "redis")},
 		},
 		Profiling: SPXInfo{Enabled: site.SPXEnabled},
+		Xdebug:    XdebugInfo{Enabled: site.XdebugEnabled},
+		Mail:      MailInfo{Mode: MailMode(site)},
+		Resources: site.Resources,
 		CreatedAt: site.CreatedAt,
 		UpdatedAt: site.UpdatedAt,
 	}
+	// SQLite sites have no database container; their file lives in php.
+	if eng.Service() == "database" {
+		desc.Containers = append(desc.Containers, ContainerInfo{Service: "database", Name: docker.SiteContainerName(site.Slug, "database")})
+	}
+	if svc := cachebackend.Service(cache); svc != "" {
+		desc.Containers = append(desc.Containers, ContainerInfo{Service: svc, Name: docker.SiteContainerName(site.Slug, svc)})
+	}
 	// Engine resolver fills in DBEngine when the column was NULL on
 	// legacy rows; mirror its choice into the description so clients
 	// see the same value the rest of the system uses.
 	if desc.Database.Engine == "" {
 		desc.Database.Engine = string(eng.Kind())
 	}
+	if site.XdebugEnabled {
+		desc.Xdebug.Mode = site.XdebugMode
+	}
+	if desc.Mail.Mode == mail.ModeRelay {
+		desc.Mail.RelayHost = site.MailRelayHost
+		desc.Mail.RelayUser = site.MailRelayUser
+		desc.Mail.RelayDomains = site.MailRelayDomains
+	}
 
 	desc.Hooks = sm.summariseHooks(site.ID)
 
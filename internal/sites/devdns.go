@@ -0,0 +1,45 @@
+package sites
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/devdns"
+)
+
+// DNSZone snapshots the names the embedded DNS server answers for:
+// every site hostname under the configured dev TLD, plus the LAN
+// hostname of each LAN-enabled site. Satisfies devdns.Source.
+func (sm *SiteManager) DNSZone() devdns.Zone {
+	if sm.cfg == nil {
+		return devdns.Zone{}
+	}
+	zone := devdns.Zone{
+		TLD:       sm.cfg.DNSTLD(),
+		LanDomain: effectiveLanDomain(sm.cfg),
+		LanIP:     sm.lanIP(),
+		LanSites:  map[string]bool{},
+	}
+	all, err := sm.st.GetSites()
+	if err != nil {
+		slog.Warn("devdns: list sites failed", "err", err.Error())
+		return zone
+	}
+	suffix := "." + zone.TLD
+	for i := range all {
+		site := &all[i]
+		for _, h := range siteHostnames(site) {
+			switch {
+			case !strings.HasSuffix(h, suffix):
+			case strings.HasPrefix(h, "*."):
+				zone.Wildcards = append(zone.Wildcards, h)
+			default:
+				zone.Hosts = append(zone.Hosts, h)
+			}
+		}
+		if site.LanEnabled {
+			zone.LanSites[site.Slug] = site.Multisite == "subdomain"
+		}
+	}
+	return zone
+}
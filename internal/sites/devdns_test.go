@@ -0,0 +1,37 @@
+package sites
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDNSZone(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	site.Aliases = []string{"shop.test", "*.shop.test", "shop.example"}
+	site.LanEnabled = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+
+	zone := sm.DNSZone()
+	if zone.TLD != "test" || zone.LanDomain != "sslip.io" || !zone.LanIP.Equal(sm.lanIP()) {
+		t.Errorf("zone settings = %+v", zone)
+	}
+	if !slices.Equal(zone.Hosts, []string{"shop.test"}) {
+		t.Errorf("Hosts = %v", zone.Hosts)
+	}
+	if !slices.Equal(zone.Wildcards, []string{"*.shop.test"}) {
+		t.Errorf("Wildcards = %v", zone.Wildcards)
+	}
+	if sub, ok := zone.LanSites["lansite"]; !ok || sub {
+		t.Errorf("LanSites = %v", zone.LanSites)
+	}
+
+	if err := sm.cfg.SetDNSTLD("example"); err != nil {
+		t.Fatal(err)
+	}
+	if zone := sm.DNSZone(); !slices.Equal(zone.Hosts, []string{"shop.example"}) {
+		t.Errorf("after TLD change, Hosts = %v", zone.Hosts)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/types"
 )
 
 type exportMeta struct {
@@ -26,14 +28,53 @@ type exportMeta struct {
 	DBEngine     string `json:"dbEngine"`
 	DBVersion    string `json:"dbVersion"`
 	MySQLVersion string `json:"mysqlVersion,omitempty"` // legacy mirror
-	RedisVersion string `json:"redisVersion"`
+	CacheBackend string `json:"cacheBackend"`
+	CacheVersion string `json:"cacheVersion"`
+	RedisVersion string `json:"redisVersion,omitempty"` // legacy mirror
 	PublicDir    string `json:"publicDir"`
 	ExportedAt   string `json:"exportedAt"`
+
+	// WebServer, Multisite and the Xdebug pair are absent from archives
+	// written before they were exported; import then falls back to the
+	// saved web server default, a single site and Xdebug off.
+	WebServer     string `json:"webServer,omitempty"`
+	Multisite     string `json:"multisite,omitempty"`
+	XdebugEnabled bool   `json:"xdebugEnabled,omitempty"`
+	XdebugMode    string `json:"xdebugMode,omitempty"`
+
+	Resources types.ResourceLimits `json:"resources,omitempty"`
+
+	PHPIni               map[string]string `json:"phpIni,omitempty"`
+	PHPExtensionsEnable  []string          `json:"phpExtensionsEnable,omitempty"`
+	PHPExtensionsDisable []string          `json:"phpExtensionsDisable,omitempty"`
 }
 
+// ErrExportExists is returned by ExportSite when the destination is
+// already taken. An export never overwrites a file it did not create.
+var ErrExportExists = errors.New("export destination already exists")
+
 // ExportSite creates a .tar.gz archive containing the site's database dump,
-// files directory, and metadata.
+// files directory, and metadata. The archive is written to a temporary
+// file beside destPath and only appears under destPath once complete, so
+// a failed export leaves nothing behind; an existing destPath is refused
+// with ErrExportExists.
 func (sm *SiteManager) ExportSite(ctx context.Context, id, destPath string) error {
+	return sm.exportSite(ctx, id, destPath, false)
+}
+
+// ExportSiteReplacing is ExportSite for a destination the user already
+// agreed to overwrite, e.g. in a save dialog. The old file is replaced
+// only once the new archive is complete.
+func (sm *SiteManager) ExportSiteReplacing(ctx context.Context, id, destPath string) error {
+	return sm.exportSite(ctx, id, destPath, true)
+}
+
+func (sm *SiteManager) exportSite(ctx context.Context, id, destPath string, replace bool) error {
+	if !replace {
+		if _, err := os.Lstat(destPath); err == nil {
+			return fmt.Errorf("%w: %s", ErrExportExists, destPath)
+		}
+	}
 	site, err := sm.st.GetSite(id)
 	if err != nil {
 		return fmt.Errorf("fetching site: %w", err)
@@ -61,18 +102,20 @@ func (sm *SiteManager) ExportSite(ctx context.Context, id, destPath string) erro
 	}
 	sqlDump := dumpBuf.String()
 
-	// Create the tar.gz file.
-	outFile, err := os.Create(destPath)
+	// Build the tar.gz under a temporary name in the destination's
+	// directory, so the final link or rename stays on one filesystem.
+	outFile, err := os.CreateTemp(filepath.Dir(destPath), "."+filepath.Base(destPath)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("creating export file: %w", err)
 	}
-	defer outFile.Close()
+	tmpPath := outFile.Name()
+	defer func() {
+		_ = outFile.Close()
+		_ = os.Remove(tmpPath)
+	}()
 
 	gw := gzip.NewWriter(outFile)
-	defer func() { _ = gw.Close() }()
-
 	tw := tar.NewWriter(gw)
-	defer func() { _ = tw.Close() }()
 
 	// Write metadata.json
 	meta := exportMeta{
@@ -83,9 +126,21 @@ func (sm *SiteManager) ExportSite(ctx context.Context, id, destPath string) erro
 		DBEngine:     site.DBEngine,
 		DBVersion:    site.DBVersion,
 		MySQLVersion: site.MySQLVersion, //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
-		RedisVersion: site.RedisVersion,
+		CacheBackend: site.CacheBackend,
+		CacheVersion: site.CacheVersion,
+		RedisVersion: site.RedisVersion, //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
 		PublicDir:    site.PublicDir,
 		ExportedAt:   time.Now().UTC().Format(time.RFC3339),
+		Resources:    site.Resources,
+
+		WebServer:     site.WebServer,
+		Multisite:     site.Multisite,
+		XdebugEnabled: site.XdebugEnabled,
+		XdebugMode:    site.XdebugMode,
+
+		PHPIni:               site.PHPIni,
+		PHPExtensionsEnable:  site.PHPExtensionsEnable,
+		PHPExtensionsDisable: site.PHPExtensionsDisable,
 	}
 	metaJSON, _ := json.MarshalIndent(meta, "", "  ")
 	if err := addToTar(tw, "metadata.json", metaJSON); err != nil {
@@ -103,6 +158,11 @@ func (sm *SiteManager) ExportSite(ctx context.Context, id, destPath string) erro
 		if err != nil {
 			return err
 		}
+		if path == tmpPath {
+			// Exporting into the files directory: never archive
+			// the archive itself.
+			return nil
+		}
 
 		rel, err := filepath.Rel(filesDir, path)
 		if err != nil {
@@ -149,6 +209,28 @@ func (sm *SiteManager) ExportSite(ctx context.Context, id, destPath string) erro
 	if err != nil {
 		return fmt.Errorf("archiving files: %w", err)
 	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+	if replace {
+		err = os.Rename(tmpPath, destPath)
+	} else {
+		// A hard link fails when destPath exists, closing the gap
+		// between the check above and now.
+		err = os.Link(tmpPath, destPath)
+		if errors.Is(err, fs.ErrExist) {
+			err = fmt.Errorf("%w: %s", ErrExportExists, destPath)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("placing export file: %w", err)
+	}
 
 	slog.Info(fmt.Sprintf("Site %q exported to %s", site.Name, destPath))
 	return sm.runHooks(ctx, hooks.PostExport, site)
@@ -0,0 +1,198 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/PeterBooker/locorum/internal/cachebackend"
+	"github.com/PeterBooker/locorum/internal/config"
+	"github.com/PeterBooker/locorum/internal/dbengine"
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/sites/configyaml"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// CreateFromConfigOptions controls CreateSiteFromConfig.
+type CreateFromConfigOptions struct {
+	// WithHooks recreates the hooks the YAML declares. Off by default:
+	// a config.yaml from someone else's repo carries commands that will
+	// run on this machine, exec-host ones as the user, so they are only
+	// added once the user has chosen to trust them. Left off, they come
+	// back in CreateFromConfigResult.SkippedHooks and the file is kept
+	// as unapplied edits, so ApplyConfigYAML adds them after review.
+	WithHooks bool
+
+	// Start brings the site up once the row exists.
+	Start bool
+}
+
+// CreateFromConfigResult reports what CreateSiteFromConfig did.
+type CreateFromConfigResult struct {
+	Site types.Site
+
+	// Hooks is the number of hooks recreated in site_hooks.
+	Hooks int
+
+	// SkippedHooks lists the hooks the YAML declares that were not
+	// added because WithHooks was off, so callers can show the user
+	// what they would run.
+	SkippedHooks []hooks.Hook
+
+	// Warnings collects the Normalize deprecation notices plus any
+	// hook that failed validation and was skipped. Each entry is
+	// ready to show to the user as-is.
+	Warnings []string
+}
+
+// CreateSiteFromConfig registers the project in dir as a site using the
+// committed .locorum/config.yaml. dir becomes the site's FilesDir; the
+// YAML supplies everything else except credentials, which are generated
+// fresh — the projection never carries them.
+//
+// Refuses when a site with the same slug, domain or directory already
+// exists: the YAML names the site, so silently renaming it would break
+// every URL the project hard-codes.
+func (sm *SiteManager) CreateSiteFromConfig(ctx context.Context, dir string, opts CreateFromConfigOptions) (*CreateFromConfigResult, error) {
+	if dir == "" {
+		return nil, errors.New("project directory is empty")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving project directory: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, configyaml.Filename))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", configyaml.Filename, err)
+	}
+	parsed, err := configyaml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.createMu.Lock()
+	unlockCreate := sync.OnceFunc(sm.createMu.Unlock)
+	defer unlockCreate()
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		return nil, fmt.Errorf("listing sites: %w", err)
+	}
+	if err := siteConflict(parsed.File.Slug, parsed.File.Domain, dir, rows); err != nil {
+		return nil, err
+	}
+	if err := sm.checkPathBlocking(dir); err != nil {
+		return nil, err
+	}
+
+	site := parsed.File.ToSite()
+	site.ID = uuid.NewString()
+	site.FilesDir = dir
+	sm.fillConfigDefaults(&site)
+	if !dbengine.IsValid(dbengine.Kind(site.DBEngine)) {
+		return nil, fmt.Errorf("unknown database engine %q", site.DBEngine)
+	}
+	if err := docker.ValidateResourceLimits(site.Resources); err != nil {
+		return nil, err
+	}
+	if err := ValidatePHPOverrides(&site); err != nil {
+		return nil, err
+	}
+	site.Aliases = NormaliseAliases(site.Aliases)
+	if err := ValidateAliases(&site, rows); err != nil {
+		return nil, err
+	}
+
+	if site.DBPassword, err = generatePassword(16); err != nil {
+		return nil, fmt.Errorf("generating db password: %w", err)
+	}
+	if site.Salts, err = newSaltsJSON(); err != nil {
+		return nil, fmt.Errorf("generating salts: %w", err)
+	}
+
+	if err := sm.st.AddSite(&site); err != nil {
+		return nil, fmt.Errorf("adding site to database: %w", err)
+	}
+	unlockCreate()
+	secrets.Add(site.DBPassword)
+
+	res := &CreateFromConfigResult{Site: site, Warnings: parsed.Warnings}
+	if !opts.WithHooks {
+		res.SkippedHooks = parsed.File.ToHooks(site.ID)
+	} else {
+		for _, h := range parsed.File.ToHooks(site.ID) {
+			if err := sm.st.AddHook(&h); err != nil {
+				res.Warnings = append(res.Warnings,
+					fmt.Sprintf("hook %s %q skipped: %v", h.Event, h.Command, err))
+				continue
+			}
+			res.Hooks++
+		}
+	}
+
+	if len(site.Aliases) > 0 {
+		if err := sm.SyncHostsFile(ctx); err != nil {
+			res.Warnings = append(res.Warnings, "hosts file not updated: "+err.Error())
+		}
+	}
+
+	if len(res.SkippedHooks) > 0 {
+		sm.holdConfigYAML(&site)
+	} else {
+		sm.writeConfigYAML(&site)
+	}
+	sm.emitSitesUpdate()
+
+	if opts.Start {
+		if err := sm.StartSite(ctx, site.ID); err != nil {
+			return res, fmt.Errorf("starting site: %w", err)
+		}
+		res.Site.Started = true
+	}
+	return res, nil
+}
+
+// siteConflict reports an existing row that would clash with a new
+// site's slug, domain or files directory.
+func siteConflict(slug, domain, dir string, rows []types.Site) error {
+	for _, r := range rows {
+		switch {
+		case r.Slug == slug:
+			return fmt.Errorf("a site with slug %q already exists", slug)
+		case r.Domain == domain:
+			return fmt.Errorf("site %q already uses domain %s", r.Slug, domain)
+		case filepath.Clean(r.FilesDir) == dir:
+			return fmt.Errorf("site %q is already registered for %s", r.Slug, dir)
+		}
+	}
+	return nil
+}
+
+// fillConfigDefaults supplies the optional YAML fields a hand-written
+// config may omit, preferring the user's saved new-site defaults.
+func (sm *SiteManager) fillConfigDefaults(site *types.Site) {
+	php, cache, redis, web := config.DefaultPHPVersion, config.DefaultCacheBackend, config.DefaultRedisVersion, config.DefaultWebServer
+	if cfg := sm.Config(); cfg != nil {
+		php, cache, redis, web = cfg.PHPVersionDefault(), cfg.CacheBackendDefault(), cfg.RedisVersionDefault(), cfg.WebServerDefault()
+	}
+	site.PHPVersion = firstNonEmpty(site.PHPVersion, php)
+	site.CacheBackend = firstNonEmpty(site.CacheBackend, cache)
+	if site.CacheVersion == "" {
+		if site.CacheBackend == string(cachebackend.Redis) {
+			site.CacheVersion = redis
+		} else {
+			site.CacheVersion = cachebackend.DefaultVersion(cachebackend.Kind(site.CacheBackend))
+		}
+	}
+	site.WebServer = firstNonEmpty(site.WebServer, web)
+	site.DBEngine = firstNonEmpty(site.DBEngine, string(dbengine.Default))
+	if site.DBVersion == "" && dbengine.IsValid(dbengine.Kind(site.DBEngine)) {
+		site.DBVersion = dbengine.MustFor(dbengine.Kind(site.DBEngine)).DefaultVersion()
+	}
+}
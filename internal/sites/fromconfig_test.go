@@ -0,0 +1,133 @@
+package sites
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/sites/configyaml"
+)
+
+const fromConfigYAML = `schema_version: 1
+name: Shop
+slug: shop
+domain: shop.localhost
+public_dir: /
+php_version: "8.2"
+db:
+  engine: mysql
+  mysql_version: "8.0"
+web_server: apache
+xdebug: debug
+hooks:
+  - event: post-start
+    task_type: wp-cli
+    position: 0
+    command: plugin activate woocommerce
+    enabled: true
+  - event: pre-start
+    task_type: exec
+    position: 0
+    command: echo containers are not up yet
+    enabled: true
+`
+
+func writeProjectConfig(t *testing.T, body string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, configyaml.Filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCreateSiteFromConfig(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	dir := writeProjectConfig(t, fromConfigYAML)
+
+	res, err := sm.CreateSiteFromConfig(context.Background(), dir, CreateFromConfigOptions{WithHooks: true})
+	if err != nil {
+		t.Fatalf("CreateSiteFromConfig: %v", err)
+	}
+
+	got, err := sm.st.GetSite(res.Site.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetSite: %v / nil=%v", err, got == nil)
+	}
+	if got.Slug != "shop" || got.FilesDir != dir || got.PHPVersion != "8.2" || got.WebServer != "apache" {
+		t.Errorf("site row = %+v", got)
+	}
+	if got.DBVersion != "8.0" {
+		t.Errorf("DBVersion = %q, want the normalised mysql_version", got.DBVersion)
+	}
+	if !got.XdebugEnabled || got.XdebugMode != "debug" {
+		t.Errorf("xdebug = %v/%q", got.XdebugEnabled, got.XdebugMode)
+	}
+	if got.DBPassword == "" {
+		t.Error("no db password generated")
+	}
+	if _, err := decodeSalts(got.Salts); err != nil {
+		t.Errorf("salts not generated: %v", err)
+	}
+
+	// The pre-start exec hook fails validation (containers are down);
+	// it must be reported, not silently dropped.
+	if res.Hooks != 1 {
+		t.Errorf("Hooks = %d, want 1", res.Hooks)
+	}
+	var sawDeprecation, sawSkipped bool
+	for _, w := range res.Warnings {
+		sawDeprecation = sawDeprecation || strings.Contains(w, "mysql_version")
+		sawSkipped = sawSkipped || strings.Contains(w, "pre-start")
+	}
+	if !sawDeprecation || !sawSkipped {
+		t.Errorf("warnings = %q", res.Warnings)
+	}
+
+	if _, err := sm.CreateSiteFromConfig(context.Background(), dir, CreateFromConfigOptions{}); err == nil {
+		t.Error("expected a conflict creating the same site twice")
+	}
+}
+
+func TestCreateSiteFromConfig_HooksOptIn(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	dir := writeProjectConfig(t, fromConfigYAML)
+
+	res, err := sm.CreateSiteFromConfig(context.Background(), dir, CreateFromConfigOptions{})
+	if err != nil {
+		t.Fatalf("CreateSiteFromConfig: %v", err)
+	}
+	hs, err := sm.st.ListHooks(res.Site.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hs) != 0 || res.Hooks != 0 {
+		t.Errorf("hooks created without WithHooks: %d", len(hs))
+	}
+	if len(res.SkippedHooks) != 2 || res.SkippedHooks[0].Command != "plugin activate woocommerce" {
+		t.Errorf("SkippedHooks = %+v, want both declared hooks for review", res.SkippedHooks)
+	}
+
+	// The file keeps its hooks, offered as an unapplied edit.
+	data, err := os.ReadFile(filepath.Join(dir, configyaml.Filename))
+	if err != nil || !strings.Contains(string(data), "plugin activate woocommerce") {
+		t.Fatalf("config.yaml lost its hooks: %v\n%s", err, data)
+	}
+	drift, err := sm.ConfigDrift(res.Site.ID)
+	if err != nil || drift == nil {
+		t.Fatalf("ConfigDrift = %+v, %v; want the skipped hooks pending", drift, err)
+	}
+	var sawHooks bool
+	for _, c := range drift.Changes {
+		sawHooks = sawHooks || c.Field == "hooks"
+	}
+	if !sawHooks {
+		t.Errorf("drift changes = %+v, want hooks", drift.Changes)
+	}
+}
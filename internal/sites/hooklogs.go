@@ -0,0 +1,92 @@
+package sites
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/secrets"
+)
+
+// hookLogMaxBytes caps how much of one run log HookRunLogs returns. The
+// tail is kept: the failing task and the summary are at the end.
+const hookLogMaxBytes = 64 << 10
+
+// HookRunLog is one recorded hook run, redacted for display.
+type HookRunLog struct {
+	Event     string    `json:"event"`
+	Started   time.Time `json:"started"`
+	Text      string    `json:"text"`
+	Truncated bool      `json:"truncated,omitempty"`
+}
+
+// HookRunLogs returns up to limit of siteID's most recent hook run logs,
+// newest first. limit <= 0 means 1.
+func (sm *SiteManager) HookRunLogs(siteID string, limit int) ([]HookRunLog, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+
+	logs, err := hooks.ListRunLogs(hooks.RunLogsDir(sm.homeDir), site.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("listing hook run logs: %w", err)
+	}
+	out := make([]HookRunLog, 0, min(limit, len(logs)))
+	for _, l := range logs {
+		if len(out) == limit {
+			break
+		}
+		text, truncated, err := readLogTail(l.Path, hookLogMaxBytes)
+		if err != nil {
+			// Swept between the listing and the read.
+			continue
+		}
+		out = append(out, HookRunLog{
+			Event:     string(l.Event),
+			Started:   l.Started,
+			Text:      secrets.RedactString(text),
+			Truncated: truncated,
+		})
+	}
+	return out, nil
+}
+
+// readLogTail returns the last limit bytes of path, starting at a line
+// boundary when it had to cut.
+func readLogTail(path string, limit int64) (string, bool, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path comes from hooks.ListRunLogs under our own base dir.
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", false, err
+	}
+	truncated := info.Size() > limit
+	if truncated {
+		if _, err := f.Seek(info.Size()-limit, io.SeekStart); err != nil {
+			return "", false, err
+		}
+	}
+	body, err := io.ReadAll(io.LimitReader(f, limit))
+	if err != nil {
+		return "", false, err
+	}
+	if truncated {
+		if i := bytes.IndexByte(body, '\n'); i >= 0 {
+			body = body[i+1:]
+		}
+	}
+	return string(body), truncated, nil
+}
@@ -0,0 +1,60 @@
+package sites
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/secrets"
+)
+
+func TestHookRunLogs(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	sm.homeDir = t.TempDir()
+	site := newLanSite(t, sm)
+
+	if logs, err := sm.HookRunLogs(site.ID, 5); err != nil || len(logs) != 0 {
+		t.Fatalf("no runs yet: %v, %v", logs, err)
+	}
+
+	dir := filepath.Join(hooks.RunLogsDir(sm.homeDir), site.Slug)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	const secret = "hunter2-but-longer"
+	secrets.Add(secret)
+	t.Cleanup(func() { secrets.Remove(secret) })
+	long := strings.Repeat("noise line\n", hookLogMaxBytes/10) + "  DB_PASSWORD=" + secret + "\n# summary: failed=1\n"
+	for name, body := range map[string]string{
+		"pre-start-20260501T120000.000000000Z.log":  "# event=pre-start\nok\n",
+		"post-start-20260501T120005.000000000Z.log": long,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logs, err := sm.HookRunLogs(site.ID, 0)
+	if err != nil {
+		t.Fatalf("HookRunLogs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Event != "post-start" {
+		t.Fatalf("logs = %+v, want only the newest run", logs)
+	}
+	newest := logs[0]
+	if !newest.Truncated || len(newest.Text) > hookLogMaxBytes || !strings.HasSuffix(newest.Text, "# summary: failed=1\n") {
+		t.Errorf("truncated=%v len=%d: want the tail of the log", newest.Truncated, len(newest.Text))
+	}
+	if !strings.HasPrefix(newest.Text, "noise line\n") {
+		t.Errorf("cut mid-line: %q", newest.Text[:20])
+	}
+	if strings.Contains(newest.Text, secret) {
+		t.Error("secret not redacted")
+	}
+
+	if logs, _ := sm.HookRunLogs(site.ID, 5); len(logs) != 2 || logs[1].Text != "# event=pre-start\nok\n" {
+		t.Errorf("logs = %+v", logs)
+	}
+}
@@ -71,29 +71,55 @@ type SearchReplacePair struct {
 // caller-supplied SearchReplace and the auto-detected pairs run between
 // them so a post-import-db hook sees the local URLs already in place.
 func (sm *SiteManager) ImportDB(ctx context.Context, siteID, hostPath string, opts ImportDBOptions) error {
+	site, err := sm.importDBTarget(siteID, hostPath, opts)
+	if err != nil {
+		return err
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	return sm.importDBLocked(ctx, site, func(dst string) error {
+		return prepareDump(hostPath, dst)
+	}, opts)
+}
+
+// importDBTarget fetches siteID and checks ImportDB's arguments.
+func (sm *SiteManager) importDBTarget(siteID, hostPath string, opts ImportDBOptions) (*types.Site, error) {
 	site, err := sm.st.GetSite(siteID)
 	if err != nil {
-		return fmt.Errorf("fetching site: %w", err)
+		return nil, fmt.Errorf("fetching site: %w", err)
 	}
 	if site == nil {
-		return fmt.Errorf("site %q not found", siteID)
+		return nil, fmt.Errorf("site %q not found", siteID)
 	}
 	if !site.Started {
-		return fmt.Errorf("%w: cannot import database", ErrSiteNotRunning)
+		return nil, fmt.Errorf("%w: cannot import database", ErrSiteNotRunning)
 	}
 	if hostPath == "" {
-		return errors.New("import path is empty")
+		return nil, errors.New("import path is empty")
+	}
+	if err := validatePairs(opts.SearchReplace); err != nil {
+		return nil, err
 	}
-	for _, p := range opts.SearchReplace {
+	return site, nil
+}
+
+// validatePairs rejects search-replace pairs missing either side.
+func validatePairs(pairs []SearchReplacePair) error {
+	for _, p := range pairs {
 		if p.From == "" || p.To == "" {
 			return errors.New("search-replace pairs require both From and To")
 		}
 	}
+	return nil
+}
 
-	mu := sm.siteMutex(siteID)
-	mu.Lock()
-	defer mu.Unlock()
-
+// importDBLocked is the body of ImportDB once the site mutex is held.
+// prepare writes the filtered SQL to the dump path it is given; ImportDB
+// reads a host file, Pull streams from an SSH remote.
+func (sm *SiteManager) importDBLocked(ctx context.Context, site *types.Site, prepare func(dst string) error, opts ImportDBOptions) error {
 	// Pre-import snapshot. Provides a one-click restore path if the
 	// imported dump turns out to be the wrong one, or if a search-replace
 	// runs over a column it shouldn't have touched.
@@ -136,7 +162,7 @@ func (sm *SiteManager) ImportDB(ctx context.Context, siteID, hostPath string, op
 			&sitesteps.FuncStep{
 				Label: "prepare-dump",
 				Do: func(_ context.Context) error {
-					return prepareDump(hostPath, hostDumpPath)
+					return prepare(hostDumpPath)
 				},
 				Undo: func(_ context.Context) error {
 					cleanup()
@@ -304,7 +330,12 @@ func prepareDump(hostPath, dst string) error {
 	if rc, ok := reader.(io.Closer); ok && rc != src {
 		defer rc.Close()
 	}
+	return writeFilteredDump(reader, dst)
+}
 
+// writeFilteredDump runs reader through the import filter into dst with
+// the permissions and atomicity described on prepareDump.
+func writeFilteredDump(reader io.Reader, dst string) error {
 	tmp, err := os.CreateTemp(filepath.Dir(dst), ".locorum-import-*.sql")
 	if err != nil {
 		return fmt.Errorf("create tmp: %w", err)
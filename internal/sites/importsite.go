@@ -0,0 +1,480 @@
+package sites
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gosimple/slug"
+
+	"github.com/PeterBooker/locorum/internal/cachebackend"
+	"github.com/PeterBooker/locorum/internal/dbengine"
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/sites/configyaml"
+	"github.com/PeterBooker/locorum/internal/types"
+	"github.com/PeterBooker/locorum/internal/utils"
+)
+
+const (
+	exportMetaEntry  = "metadata.json"
+	exportDBEntry    = "database.sql"
+	exportFilesEntry = "files/"
+
+	// exportMetaMax caps metadata.json. The real file is a few hundred
+	// bytes; anything larger is not an archive ExportSite wrote.
+	exportMetaMax = 1 << 20
+
+	// importSlugAttempts bounds the -2, -3, … suffix search. A user
+	// with a hundred copies of the same site has bigger problems.
+	importSlugAttempts = 100
+)
+
+// errExportEntryMissing is returned when a required entry is absent
+// from an export archive.
+var errExportEntryMissing = errors.New("entry missing from export archive")
+
+// ImportSiteOptions controls how ImportSite lays the archive down.
+type ImportSiteOptions struct {
+	// Name overrides the site name recorded in the archive. The slug
+	// and domain are derived from whichever name wins.
+	Name string
+
+	// FilesDir overrides where the files tree is extracted. Default:
+	// ~/locorum/sites/<slug>. An explicit directory must be empty or
+	// not yet exist.
+	FilesDir string
+
+	// SearchReplace and DisableAuto behave as in ImportDBOptions and
+	// run once the database has been restored.
+	SearchReplace []SearchReplacePair
+	DisableAuto   bool
+
+	// WithHooks restores the hooks recorded in the archive's
+	// .locorum/config.yaml before pre-import-site fires. Off by
+	// default for the reason CreateFromConfigOptions.WithHooks is: the
+	// archive may come from someone else. Left off, they come back in
+	// ImportSiteResult.SkippedHooks and wait as unapplied config.yaml
+	// edits.
+	WithHooks bool
+}
+
+// ImportSiteResult is what ImportSite returns. Site is populated as
+// soon as the row exists, so callers can point the user at a
+// half-imported site when a later step fails.
+type ImportSiteResult struct {
+	Site types.Site
+
+	// SourceSlug and SourceDomain are the values recorded in the
+	// archive, before collision resolution.
+	SourceSlug   string
+	SourceDomain string
+
+	// SkippedHooks lists the archived hooks that were not restored
+	// because WithHooks was off.
+	SkippedHooks []hooks.Hook
+}
+
+// ImportSite creates a new site from an archive written by ExportSite.
+// The flow:
+//
+//  1. Read metadata.json and resolve slug/domain collisions by
+//     appending -2, -3, … to the slug.
+//  2. Extract files/ into the new FilesDir with the same traversal
+//     and special-file guards as the WordPress download.
+//  3. Insert the site row and, with WithHooks, the hooks from the
+//     extracted .locorum/config.yaml; fire pre-import-site, start the
+//     site.
+//  4. Restore database.sql through the engine and run the URL
+//     search-replace, then fire post-import-site.
+//
+// Failures before the row is inserted remove the extracted files.
+// After that the row and files stay so the user can inspect them,
+// matching CloneSite.
+func (sm *SiteManager) ImportSite(ctx context.Context, archivePath string, opts ImportSiteOptions) (*ImportSiteResult, error) {
+	if archivePath == "" {
+		return nil, errors.New("archive path is empty")
+	}
+	for _, p := range opts.SearchReplace {
+		if p.From == "" || p.To == "" {
+			return nil, errors.New("search-replace pairs require both From and To")
+		}
+	}
+
+	meta, err := readExportMeta(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = meta.Name
+	}
+	if name == "" {
+		name = meta.Slug
+	}
+	base := slug.Make(name)
+	if base == "" {
+		return nil, fmt.Errorf("cannot derive a slug from site name %q", name)
+	}
+
+	// Hold the create lock from choosing the slug until the row is
+	// stored, so a concurrent create cannot claim the same slug.
+	sm.createMu.Lock()
+	unlockCreate := sync.OnceFunc(sm.createMu.Unlock)
+	defer unlockCreate()
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		return nil, fmt.Errorf("listing sites: %w", err)
+	}
+	sitesRoot := filepath.Join(sm.homeDir, "locorum", "sites")
+	dirFree := func(s string) bool {
+		if opts.FilesDir != "" {
+			return true
+		}
+		_, err := os.Stat(filepath.Join(sitesRoot, s))
+		return errors.Is(err, os.ErrNotExist)
+	}
+	newSlug, err := uniqueImportSlug(base, rows, dirFree)
+	if err != nil {
+		return nil, err
+	}
+
+	filesDir := opts.FilesDir
+	if filesDir == "" {
+		filesDir = filepath.Join(sitesRoot, newSlug)
+	}
+	if err := sm.checkPathBlocking(filesDir); err != nil {
+		return nil, err
+	}
+	newSite, err := sm.importedSiteSettings(meta)
+	if err != nil {
+		return nil, err
+	}
+	dbPassword, err := generatePassword(16)
+	if err != nil {
+		return nil, fmt.Errorf("generating db password: %w", err)
+	}
+	newSite.ID = uuid.NewString()
+	newSite.Name = name
+	newSite.Slug = newSlug
+	newSite.Domain = newSlug + ".localhost"
+	newSite.FilesDir = filesDir
+	newSite.DBPassword = dbPassword
+
+	createdDir, err := prepareImportDir(filesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	discard := func() {
+		if !createdDir {
+			return
+		}
+		if err := os.RemoveAll(filesDir); err != nil {
+			slog.Warn("import-site: cleanup failed", "path", filesDir, "err", err.Error())
+		}
+	}
+
+	if err := extractExportFiles(archivePath, filesDir); err != nil {
+		discard()
+		return nil, fmt.Errorf("extracting files: %w", err)
+	}
+	// Read before the row exists: writeConfigYAML would replace the
+	// archived file with a projection of a site that has no hooks.
+	archivedHooks := readArchivedHooks(filesDir, newSite.ID)
+
+	if err := sm.st.AddSite(&newSite); err != nil {
+		discard()
+		return nil, fmt.Errorf("adding imported site to database: %w", err)
+	}
+	unlockCreate()
+	secrets.Add(newSite.DBPassword)
+
+	res := &ImportSiteResult{Site: newSite, SourceSlug: meta.Slug, SourceDomain: meta.Domain}
+	switch {
+	case opts.WithHooks:
+		for _, h := range archivedHooks {
+			if err := sm.st.AddHook(&h); err != nil {
+				slog.Warn("import-site: hook skipped", "event", h.Event, "command", h.Command, "err", err.Error())
+			}
+		}
+		sm.writeConfigYAML(&newSite)
+	case len(archivedHooks) > 0:
+		res.SkippedHooks = archivedHooks
+		sm.holdConfigYAML(&newSite)
+	default:
+		sm.writeConfigYAML(&newSite)
+	}
+	sm.emitSitesUpdate()
+
+	if err := sm.runHooks(ctx, hooks.PreImportSite, &newSite); err != nil {
+		return res, err
+	}
+
+	// StartSite acquires its own per-site mutex (newSite.ID).
+	if err := sm.StartSite(ctx, newSite.ID); err != nil {
+		return res, fmt.Errorf("starting imported site: %w", err)
+	}
+	newSite.Started = true
+	res.Site.Started = true
+
+	eng := dbengine.Resolve(&newSite)
+	err = withExportEntry(archivePath, exportDBEntry, func(r io.Reader) error {
+		return eng.Restore(ctx, sm.d, &newSite, r)
+	})
+	if err != nil {
+		return res, fmt.Errorf("restoring database: %w", err)
+	}
+
+	if !opts.DisableAuto || len(opts.SearchReplace) > 0 {
+		dbOpts := ImportDBOptions{SearchReplace: opts.SearchReplace, DisableAuto: opts.DisableAuto}
+		if err := sm.applySearchReplace(ctx, &newSite, dbOpts); err != nil {
+			return res, fmt.Errorf("search-replace: %w", err)
+		}
+	}
+
+	slog.Info(fmt.Sprintf("Site %q imported from %s", newSite.Name, archivePath))
+	sm.emitSitesUpdate()
+	return res, sm.runHooks(ctx, hooks.PostImportSite, &newSite)
+}
+
+// uniqueImportSlug returns base, or base-N for the smallest N ≥ 2,
+// such that no existing row uses the slug or its .localhost domain and
+// dirFree reports the candidate's default directory unused.
+func uniqueImportSlug(base string, rows []types.Site, dirFree func(string) bool) (string, error) {
+	taken := make(map[string]struct{}, 2*len(rows))
+	for _, r := range rows {
+		taken[r.Slug] = struct{}{}
+		taken[strings.TrimSuffix(r.Domain, ".localhost")] = struct{}{}
+	}
+	for i := 1; i <= importSlugAttempts; i++ {
+		cand := base
+		if i > 1 {
+			cand = base + "-" + strconv.Itoa(i)
+		}
+		if _, ok := taken[cand]; ok {
+			continue
+		}
+		if dirFree != nil && !dirFree(cand) {
+			continue
+		}
+		return cand, nil
+	}
+	return "", fmt.Errorf("no free slug for %q after %d attempts", base, importSlugAttempts)
+}
+
+// readArchivedHooks returns the hooks declared in the .locorum/config.yaml
+// extracted into filesDir, attached to siteID. Archives from before
+// config.yaml existed, or with a file that no longer parses, yield none.
+func readArchivedHooks(filesDir, siteID string) []hooks.Hook {
+	data, err := os.ReadFile(filepath.Join(filesDir, configyaml.Filename))
+	if err != nil {
+		return nil
+	}
+	parsed, err := configyaml.Parse(data)
+	if err != nil {
+		slog.Warn("import-site: archived config.yaml ignored", "err", err.Error())
+		return nil
+	}
+	return parsed.File.ToHooks(siteID)
+}
+
+// prepareImportDir ensures dir exists and is empty. created reports
+// whether this call made the directory, which is what lets ImportSite
+// remove it on failure without touching anything the user owned.
+func prepareImportDir(dir string) (created bool, err error) {
+	entries, err := os.ReadDir(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := utils.EnsureDir(dir); err != nil {
+			return false, fmt.Errorf("creating site directory: %w", err)
+		}
+		return true, nil
+	case err != nil:
+		return false, fmt.Errorf("reading site directory: %w", err)
+	case len(entries) > 0:
+		return false, fmt.Errorf("site directory %s is not empty", dir)
+	}
+	return false, nil
+}
+
+// importedSiteSettings builds the versions, engine and overrides of an
+// imported site from its archive metadata and validates them. Whatever
+// the archive leaves out follows the user's saved defaults, as it
+// would for a new site.
+func (sm *SiteManager) importedSiteSettings(meta exportMeta) (types.Site, error) {
+	site := types.Site{
+		PublicDir:    meta.PublicDir,
+		PHPVersion:   meta.PHPVersion,
+		DBEngine:     meta.DBEngine,
+		DBVersion:    firstNonEmpty(meta.DBVersion, meta.MySQLVersion),
+		CacheBackend: meta.CacheBackend,
+		CacheVersion: meta.CacheVersion,
+		WebServer:    meta.WebServer,
+		Multisite:    meta.Multisite,
+		Resources:    docker.CompactResourceLimits(meta.Resources),
+		PHPIni:       meta.PHPIni,
+
+		XdebugEnabled: meta.XdebugEnabled,
+		XdebugMode:    meta.XdebugMode,
+
+		PHPExtensionsEnable:  meta.PHPExtensionsEnable,
+		PHPExtensionsDisable: meta.PHPExtensionsDisable,
+	}
+	if meta.CacheBackend == "" && meta.RedisVersion != "" {
+		// Archives from before the cache-backend split carry only
+		// redisVersion, and always for a Redis site.
+		site.CacheBackend = string(cachebackend.Redis)
+		site.CacheVersion = firstNonEmpty(site.CacheVersion, meta.RedisVersion)
+	}
+	sm.fillConfigDefaults(&site)
+	if !dbengine.IsValid(dbengine.Kind(site.DBEngine)) {
+		return types.Site{}, fmt.Errorf("archive has unknown database engine %q", site.DBEngine)
+	}
+	if !cachebackend.IsValid(cachebackend.Kind(site.CacheBackend)) {
+		return types.Site{}, fmt.Errorf("archive has unknown cache backend %q", site.CacheBackend)
+	}
+	if site.WebServer != "nginx" && site.WebServer != "apache" {
+		return types.Site{}, fmt.Errorf("archive has unknown web server %q", site.WebServer)
+	}
+	if site.Multisite != "" && site.Multisite != "subdirectory" && site.Multisite != "subdomain" {
+		return types.Site{}, fmt.Errorf("archive has unknown multisite mode %q", site.Multisite)
+	}
+	if site.XdebugEnabled && (site.XdebugMode == XdebugModeOff || !ValidXdebugMode(site.XdebugMode)) {
+		return types.Site{}, fmt.Errorf("archive has unknown xdebug mode %q", site.XdebugMode)
+	}
+	if err := docker.ValidateResourceLimits(site.Resources); err != nil {
+		return types.Site{}, fmt.Errorf("archive: %w", err)
+	}
+	if err := ValidatePHPOverrides(&site); err != nil {
+		return types.Site{}, fmt.Errorf("archive: %w", err)
+	}
+	return site, nil
+}
+
+// readExportMeta decodes metadata.json from an export archive.
+func readExportMeta(archivePath string) (exportMeta, error) {
+	var meta exportMeta
+	err := withExportEntry(archivePath, exportMetaEntry, func(r io.Reader) error {
+		return json.NewDecoder(io.LimitReader(r, exportMetaMax)).Decode(&meta)
+	})
+	if err != nil {
+		return exportMeta{}, fmt.Errorf("reading %s: %w", exportMetaEntry, err)
+	}
+	if meta.Name == "" && meta.Slug == "" {
+		return exportMeta{}, fmt.Errorf("%s has neither name nor slug", exportMetaEntry)
+	}
+	return meta, nil
+}
+
+// withExportEntry streams the named entry of an export archive to fn.
+// The archive is scanned from the start on every call; ExportSite
+// writes metadata.json and database.sql first, so those lookups stop
+// before the files tree.
+func withExportEntry(archivePath, name string, fn func(io.Reader) error) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("gzip reader: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s: %w", name, errExportEntryMissing)
+		}
+		if err != nil {
+			return fmt.Errorf("tar next: %w", err)
+		}
+		if hdr.Name == name && hdr.Typeflag == tar.TypeReg {
+			return fn(tr)
+		}
+	}
+}
+
+// extractExportFiles writes the files/ subtree of an export archive
+// into destDir. Guards mirror extractTarGz: every target must stay
+// under destDir, and links or device nodes are refused outright. An
+// archive written on Windows carries backslash separators, which are
+// normalised so a teammate's export lands the same on every host.
+func extractExportFiles(archivePath, destDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("gzip reader: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar next: %w", err)
+		}
+
+		name := strings.ReplaceAll(hdr.Name, `\`, "/")
+		if !strings.HasPrefix(name, exportFilesEntry) {
+			continue
+		}
+		name = strings.Trim(strings.TrimPrefix(name, exportFilesEntry), "/")
+		if name == "" || name == "." {
+			continue
+		}
+
+		target := filepath.Join(destDir, filepath.FromSlash(name)) //nolint:gosec // G305: traversal guarded by the Clean+HasPrefix check below.
+		if !strings.HasPrefix(filepath.Clean(target)+string(os.PathSeparator),
+			filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %q escapes destination", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("mkdir %q: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("mkdir parent %q: %w", target, err)
+			}
+			// No per-file cap: ExportSite archives files of any size,
+			// and the tar reader stops at the size in the header.
+			if err := writeRegularEntry(tr, target, 0); err != nil {
+				return err
+			}
+		case tar.TypeSymlink, tar.TypeLink, tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			// ExportSite only writes regular files and directories.
+			return fmt.Errorf("tar entry %q has unsupported type %c — refusing to extract",
+				hdr.Name, hdr.Typeflag)
+		default:
+			return fmt.Errorf("tar entry %q has unknown type %c", hdr.Name, hdr.Typeflag)
+		}
+	}
+}
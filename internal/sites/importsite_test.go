@@ -0,0 +1,376 @@
+package sites
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	hooksfake "github.com/PeterBooker/locorum/internal/hooks/fake"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// tarEntry is one entry in a test export archive. Empty body plus a
+// trailing slash on name makes a directory.
+type tarEntry struct {
+	name     string
+	body     string
+	typeflag byte
+}
+
+func writeTestArchive(t *testing.T, entries []tarEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create archive: %v", err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: e.typeflag}
+		switch {
+		case e.typeflag == tar.TypeSymlink:
+			hdr.Linkname, hdr.Size = "/etc/passwd", 0
+		case strings.HasSuffix(e.name, "/"):
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0o755
+		case e.typeflag == 0:
+			hdr.Typeflag = tar.TypeReg
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("write header %s: %v", e.name, err)
+		}
+		if hdr.Size > 0 {
+			if _, err := io.WriteString(tw, e.body); err != nil {
+				t.Fatalf("write body %s: %v", e.name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+	return path
+}
+
+func metaJSON(t *testing.T, m exportMeta) string {
+	t.Helper()
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal meta: %v", err)
+	}
+	return string(b)
+}
+
+func TestReadExportMeta(t *testing.T) {
+	path := writeTestArchive(t, []tarEntry{
+		{name: "metadata.json", body: metaJSON(t, exportMeta{Name: "Shop", Slug: "shop", Domain: "shop.localhost", DBEngine: "mariadb"})},
+		{name: "database.sql", body: "SELECT 1;"},
+	})
+	meta, err := readExportMeta(path)
+	if err != nil {
+		t.Fatalf("readExportMeta: %v", err)
+	}
+	if meta.Slug != "shop" || meta.DBEngine != "mariadb" {
+		t.Errorf("meta = %+v", meta)
+	}
+
+	var body string
+	err = withExportEntry(path, exportDBEntry, func(r io.Reader) error {
+		b, err := io.ReadAll(r)
+		body = string(b)
+		return err
+	})
+	if err != nil || body != "SELECT 1;" {
+		t.Errorf("database.sql = %q, err %v", body, err)
+	}
+}
+
+func TestReadExportMeta_Missing(t *testing.T) {
+	path := writeTestArchive(t, []tarEntry{{name: "database.sql", body: "SELECT 1;"}})
+	_, err := readExportMeta(path)
+	if !errors.Is(err, errExportEntryMissing) {
+		t.Fatalf("err = %v, want errExportEntryMissing", err)
+	}
+}
+
+func TestUniqueImportSlug(t *testing.T) {
+	rows := []types.Site{
+		{Slug: "shop", Domain: "shop.localhost"},
+		{Slug: "other", Domain: "shop-2.localhost"},
+	}
+	got, err := uniqueImportSlug("shop", rows, nil)
+	if err != nil {
+		t.Fatalf("uniqueImportSlug: %v", err)
+	}
+	if got != "shop-3" {
+		t.Errorf("slug = %q, want shop-3 (shop taken by slug, shop-2 by domain)", got)
+	}
+
+	got, _ = uniqueImportSlug("blog", rows, func(s string) bool { return s != "blog" })
+	if got != "blog-2" {
+		t.Errorf("slug = %q, want blog-2 when the blog directory exists", got)
+	}
+}
+
+func TestExtractExportFiles(t *testing.T) {
+	path := writeTestArchive(t, []tarEntry{
+		{name: "metadata.json", body: "{}"},
+		{name: "database.sql", body: "SELECT 1;"},
+		{name: "files/./"},
+		{name: "files/wp-content/"},
+		{name: "files/wp-content/index.php", body: "<?php"},
+		{name: `files/wp-content\uploads\a.txt`, body: "windows"},
+	})
+	dest := t.TempDir()
+	if err := extractExportFiles(path, dest); err != nil {
+		t.Fatalf("extractExportFiles: %v", err)
+	}
+	for rel, want := range map[string]string{
+		"wp-content/index.php":     "<?php",
+		"wp-content/uploads/a.txt": "windows",
+	} {
+		got, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(rel)))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, err %v", rel, got, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "metadata.json")); !os.IsNotExist(err) {
+		t.Error("metadata.json extracted into the files dir")
+	}
+}
+
+func TestExtractExportFiles_RejectsUnsafeEntries(t *testing.T) {
+	cases := map[string]tarEntry{
+		"traversal": {name: "files/../../evil.php", body: "x"},
+		"symlink":   {name: "files/link", typeflag: tar.TypeSymlink},
+	}
+	for name, e := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := writeTestArchive(t, []tarEntry{e})
+			dest := filepath.Join(t.TempDir(), "site")
+			if err := os.MkdirAll(dest, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := extractExportFiles(path, dest); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "evil.php")); !os.IsNotExist(err) {
+				t.Error("entry escaped the destination")
+			}
+		})
+	}
+}
+
+func TestPrepareImportDir(t *testing.T) {
+	root := t.TempDir()
+	created, err := prepareImportDir(filepath.Join(root, "new"))
+	if err != nil || !created {
+		t.Fatalf("new dir: created=%v err=%v", created, err)
+	}
+	created, err = prepareImportDir(filepath.Join(root, "new"))
+	if err != nil || created {
+		t.Fatalf("existing empty dir: created=%v err=%v", created, err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "new", "x"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prepareImportDir(filepath.Join(root, "new")); err == nil {
+		t.Error("expected error for non-empty dir")
+	}
+}
+
+func TestImportedSiteSettings_UsesSavedDefaults(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	if err := sm.cfg.SetPHPVersionDefault("8.2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.cfg.SetWebServerDefault("apache"); err != nil {
+		t.Fatal(err)
+	}
+
+	site, err := sm.importedSiteSettings(exportMeta{DBEngine: "mysql"})
+	if err != nil {
+		t.Fatalf("importedSiteSettings: %v", err)
+	}
+	if site.PHPVersion != "8.2" || site.WebServer != "apache" {
+		t.Errorf("php %q web %q, want the saved defaults 8.2 / apache", site.PHPVersion, site.WebServer)
+	}
+
+	site, err = sm.importedSiteSettings(exportMeta{PHPVersion: "8.1", RedisVersion: "7.2"})
+	if err != nil {
+		t.Fatalf("legacy archive: %v", err)
+	}
+	if site.PHPVersion != "8.1" || site.CacheBackend != "redis" || site.CacheVersion != "7.2" {
+		t.Errorf("legacy archive = php %q cache %s:%s", site.PHPVersion, site.CacheBackend, site.CacheVersion)
+	}
+
+	if _, err := sm.importedSiteSettings(exportMeta{DBEngine: "oracle"}); err == nil {
+		t.Error("unknown engine accepted")
+	}
+}
+
+func TestImportedSiteSettings_KeepsWebServerMultisiteXdebug(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+
+	site, err := sm.importedSiteSettings(exportMeta{
+		WebServer: "apache", Multisite: "subdomain",
+		XdebugEnabled: true, XdebugMode: "profile",
+	})
+	if err != nil {
+		t.Fatalf("importedSiteSettings: %v", err)
+	}
+	if site.WebServer != "apache" || site.Multisite != "subdomain" || !site.XdebugEnabled || site.XdebugMode != "profile" {
+		t.Errorf("site = web %q multisite %q xdebug %v/%q", site.WebServer, site.Multisite, site.XdebugEnabled, site.XdebugMode)
+	}
+
+	for _, meta := range []exportMeta{
+		{WebServer: "caddy"},
+		{Multisite: "network"},
+		{XdebugEnabled: true, XdebugMode: "bogus"},
+	} {
+		if _, err := sm.importedSiteSettings(meta); err == nil {
+			t.Errorf("meta %+v accepted", meta)
+		}
+	}
+}
+
+// importHooksYAML is the .locorum/config.yaml an exported site carries,
+// with a host hook on pre-import-site.
+const importHooksYAML = `schema_version: 1
+name: Shop
+slug: shop
+domain: shop.localhost
+public_dir: /
+php_version: "8.3"
+db:
+  engine: mysql
+  version: "8.0"
+web_server: nginx
+hooks:
+  - event: pre-import-site
+    task_type: exec-host
+    position: 0
+    command: ./scripts/prepare-import.sh
+    enabled: true
+`
+
+// newImportHooksSiteManager wires a real hooks runner over sm's store
+// with a fake host executor. The scripted hook fails and fail-strict is
+// on, so ImportSite stops straight after pre-import-site, before
+// StartSite needs Docker.
+func newImportHooksSiteManager(t *testing.T) (*SiteManager, *hooksfake.HostExecer) {
+	t.Helper()
+	sm, _, _ := newLanSiteManager(t)
+	host := hooksfake.NewHost()
+	host.Script["./scripts/prepare-import.sh"] = hooksfake.HostScript{ExitCode: 1}
+	settings := hooksfake.NewSettings()
+	settings.Set(hooks.SettingKeyFailGlobal, "true")
+	runner, err := hooks.NewRunner(hooks.Config{
+		Lister:      sm.st,
+		Container:   hooksfake.NewContainer(),
+		Host:        host,
+		Settings:    settings,
+		LogsBaseDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	sm.hooks = runner
+	return sm, host
+}
+
+func importHooksArchive(t *testing.T) string {
+	t.Helper()
+	return writeTestArchive(t, []tarEntry{
+		{name: "metadata.json", body: metaJSON(t, exportMeta{Name: "Shop", Slug: "shop", DBEngine: "mysql", DBVersion: "8.0"})},
+		{name: "database.sql", body: "SELECT 1;"},
+		{name: "files/.locorum/"},
+		{name: "files/.locorum/config.yaml", body: importHooksYAML},
+	})
+}
+
+func TestImportSite_RestoresArchivedHooks(t *testing.T) {
+	sm, host := newImportHooksSiteManager(t)
+
+	res, err := sm.ImportSite(context.Background(), importHooksArchive(t), ImportSiteOptions{
+		FilesDir:  filepath.Join(t.TempDir(), "shop"),
+		WithHooks: true,
+	})
+	if err == nil {
+		t.Fatal("expected the failing pre-import-site hook to stop the import")
+	}
+	calls := host.Calls()
+	if len(calls) != 1 || calls[0].Command != "./scripts/prepare-import.sh" {
+		t.Fatalf("host calls = %+v, want the archived pre-import-site hook", calls)
+	}
+	hs, err := sm.st.ListHooks(res.Site.ID)
+	if err != nil || len(hs) != 1 || hs[0].Event != hooks.PreImportSite {
+		t.Errorf("restored hooks = %+v, %v", hs, err)
+	}
+}
+
+func TestImportSite_ArchivedHooksOptIn(t *testing.T) {
+	sm, _, runner := newLanSiteManager(t)
+	// Stop at pre-import-site, before StartSite needs Docker.
+	runner.RunErr = errors.New("stop")
+
+	res, _ := sm.ImportSite(context.Background(), importHooksArchive(t), ImportSiteOptions{
+		FilesDir: filepath.Join(t.TempDir(), "shop"),
+	})
+	if res == nil {
+		t.Fatal("ImportSite returned no site")
+	}
+	if len(res.SkippedHooks) != 1 || res.SkippedHooks[0].Command != "./scripts/prepare-import.sh" {
+		t.Errorf("SkippedHooks = %+v", res.SkippedHooks)
+	}
+	if hs, _ := sm.st.ListHooks(res.Site.ID); len(hs) != 0 {
+		t.Errorf("hooks restored without WithHooks: %+v", hs)
+	}
+	data, err := os.ReadFile(filepath.Join(res.Site.FilesDir, ".locorum", "config.yaml"))
+	if err != nil || !strings.Contains(string(data), "prepare-import.sh") {
+		t.Errorf("archived config.yaml lost its hooks: %v", err)
+	}
+}
+
+func TestImportSite_ConcurrentImportsGetDistinctSlugs(t *testing.T) {
+	sm, _, runner := newLanSiteManager(t)
+	runner.RunErr = errors.New("stop")
+	archive := importHooksArchive(t)
+
+	const n = 4
+	slugs := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, _ := sm.ImportSite(context.Background(), archive, ImportSiteOptions{
+				FilesDir: filepath.Join(t.TempDir(), "shop"),
+			})
+			if res != nil {
+				slugs[i] = res.Site.Slug
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, s := range slugs {
+		if s == "" || seen[s] {
+			t.Fatalf("slugs = %q, want %d distinct", slugs, n)
+		}
+		seen[s] = true
+	}
+}
@@ -0,0 +1,212 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/cachebackend"
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// ErrUnknownService is returned by StreamSiteLogs for a service the
+// site does not run.
+var ErrUnknownService = errors.New("unknown service")
+
+// LogQuery selects what StreamSiteLogs reads.
+type LogQuery struct {
+	// Services to read; empty means every service the site runs.
+	Services []string
+	// Since and Until bound the lines by container timestamp. Zero
+	// means unbounded.
+	Since time.Time
+	Until time.Time
+	// Tail is how many lines of history each service starts with when
+	// Since is zero. Zero means 100.
+	Tail int
+	// Grep keeps only lines it matches. Nil keeps everything.
+	Grep *regexp.Regexp
+	// Follow keeps streaming new lines until ctx ends, Until passes or
+	// the containers stop.
+	Follow bool
+}
+
+// SiteLogLine is one log line tagged with the service that wrote it.
+type SiteLogLine struct {
+	Service string
+	docker.LogLine
+}
+
+// logStreamFunc matches Docker.ContainerLogStream.
+type logStreamFunc func(ctx context.Context, name string, o docker.LogOptions) (<-chan docker.LogLine, error)
+
+// logReorderWindow is how long a line is held so a line another
+// service wrote a moment earlier, but delivered later, can overtake it.
+// Long enough to absorb a history burst, short enough to feel live.
+const logReorderWindow = 200 * time.Millisecond
+
+// LogServices returns the services a site runs, in display order: the
+// ones StreamSiteLogs reads by default.
+func LogServices(site *types.Site) []string {
+	services := []string{"web", "php"}
+	if !isSQLite(site) {
+		services = append(services, "database")
+	}
+	if k := cachebackend.Resolve(site); k != cachebackend.None {
+		services = append(services, string(k))
+	}
+	return services
+}
+
+// StreamSiteLogs merges the logs of several of a site's service
+// containers into one channel, ordered by timestamp. History arrives
+// sorted; followed lines are ordered within a short reorder window. The
+// channel closes when every stream has ended or ctx is cancelled.
+//
+// Unlike StreamLogs it does not reattach: a follow ends when the site
+// stops. Returns ErrSiteNotRunning for a stopped site.
+func (sm *SiteManager) StreamSiteLogs(ctx context.Context, siteID string, q LogQuery) (<-chan SiteLogLine, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	if !site.Started {
+		return nil, ErrSiteNotRunning
+	}
+
+	available := LogServices(site)
+	services := q.Services
+	if len(services) == 0 {
+		services = available
+	}
+	for _, svc := range services {
+		if !slices.Contains(available, svc) {
+			return nil, fmt.Errorf("%w: site %q has no %q service (has %v)", ErrUnknownService, site.Slug, svc, available)
+		}
+	}
+
+	opts := docker.LogOptions{Since: q.Since, Until: q.Until, Tail: q.Tail, Follow: q.Follow}
+	switch {
+	case !q.Since.IsZero():
+		opts.Tail = -1
+	case opts.Tail <= 0:
+		opts.Tail = 100
+	}
+	open := sm.logStream
+	if open == nil {
+		open = sm.d.ContainerLogStream
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	sources := make(map[string]<-chan docker.LogLine, len(services))
+	for _, svc := range services {
+		ch, err := open(streamCtx, docker.SiteContainerName(site.Slug, svc), opts)
+		if err != nil {
+			cancel()
+			if errors.Is(err, docker.ErrNotFound) {
+				return nil, fmt.Errorf("%s container is not running: %w", svc, ErrSiteNotRunning)
+			}
+			return nil, fmt.Errorf("streaming %s logs: %w", svc, err)
+		}
+		sources[svc] = ch
+	}
+
+	out := make(chan SiteLogLine, 256)
+	go func() {
+		defer cancel()
+		mergeLogs(streamCtx, sources, q, out)
+	}()
+	return out, nil
+}
+
+// mergeLogs drains sources into out, filtered by q.Grep and q's time
+// bounds, and closes out when they are all done. Lines wait
+// logReorderWindow in a buffer and leave it in timestamp order.
+func mergeLogs(ctx context.Context, sources map[string]<-chan docker.LogLine, q LogQuery, out chan<- SiteLogLine) {
+	defer close(out)
+
+	type held struct {
+		line    SiteLogLine
+		arrived time.Time
+	}
+	in := make(chan SiteLogLine)
+	var wg sync.WaitGroup
+	for svc, ch := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for l := range ch {
+				// Docker bounds at whole seconds; trim to the exact window.
+				if (!q.Since.IsZero() && l.Time.Before(q.Since)) || (!q.Until.IsZero() && l.Time.After(q.Until)) {
+					continue
+				}
+				if q.Grep != nil && !q.Grep.MatchString(l.Text) {
+					continue
+				}
+				select {
+				case in <- SiteLogLine{Service: svc, LogLine: l}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(in)
+	}()
+
+	var pending []held
+	// flush sends every held line that arrived before cutoff, oldest
+	// timestamp first. A zero cutoff sends everything.
+	flush := func(cutoff time.Time) bool {
+		slices.SortStableFunc(pending, func(a, b held) int { return a.line.Time.Compare(b.line.Time) })
+		keep := pending[:0]
+		for _, h := range pending {
+			if !cutoff.IsZero() && h.arrived.After(cutoff) {
+				keep = append(keep, h)
+				continue
+			}
+			select {
+			case out <- h.line:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		pending = keep
+		return true
+	}
+
+	// Without Follow the streams end after their history, so hold
+	// everything and sort once.
+	var tickC <-chan time.Time
+	if q.Follow {
+		tick := time.NewTicker(logReorderWindow / 2)
+		defer tick.Stop()
+		tickC = tick.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case l, ok := <-in:
+			if !ok {
+				flush(time.Time{})
+				return
+			}
+			pending = append(pending, held{line: l, arrived: time.Now()})
+		case now := <-tickC:
+			if !flush(now.Add(-logReorderWindow)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+)
+
+func TestStreamSiteLogs(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+
+	if _, err := sm.StreamSiteLogs(context.Background(), site.ID, LogQuery{}); !errors.Is(err, ErrSiteNotRunning) {
+		t.Fatalf("stopped site: err = %v, want ErrSiteNotRunning", err)
+	}
+	site.Started = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return base.Add(time.Duration(s) * time.Second) }
+	logs := map[string][]docker.LogLine{
+		docker.SiteContainerName("lansite", "web"): {
+			{Time: at(1), Text: "GET / 200"},
+			{Time: at(4), Text: "GET /wp-admin 302"},
+		},
+		docker.SiteContainerName("lansite", "php"): {
+			{Time: at(0), Text: "before the window"},
+			{Time: at(2), Text: "PHP Warning: undefined index"},
+			{Time: at(3), Text: "GET /wp-admin 302 upstream"},
+		},
+	}
+	var gotOpts docker.LogOptions
+	sm.logStream = func(_ context.Context, name string, o docker.LogOptions) (<-chan docker.LogLine, error) {
+		gotOpts = o
+		lines, ok := logs[name]
+		if !ok {
+			return nil, docker.ErrNotFound
+		}
+		ch := make(chan docker.LogLine, len(lines))
+		for _, l := range lines {
+			ch <- l
+		}
+		close(ch)
+		return ch, nil
+	}
+
+	ch, err := sm.StreamSiteLogs(context.Background(), site.ID, LogQuery{
+		Services: []string{"web", "php"},
+		Since:    at(1),
+		Grep:     regexp.MustCompile(`GET|Warning`),
+	})
+	if err != nil {
+		t.Fatalf("StreamSiteLogs: %v", err)
+	}
+	var got []string
+	for l := range ch {
+		got = append(got, l.Service+": "+l.Text)
+	}
+	want := []string{
+		"web: GET / 200",
+		"php: PHP Warning: undefined index",
+		"php: GET /wp-admin 302 upstream",
+		"web: GET /wp-admin 302",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("lines = %q\nwant %q", got, want)
+	}
+	if gotOpts.Tail >= 0 || gotOpts.Follow {
+		t.Errorf("opts = %+v, want all history since the bound and no follow", gotOpts)
+	}
+
+	if _, err := sm.StreamSiteLogs(context.Background(), site.ID, LogQuery{Services: []string{"mongo"}}); err == nil {
+		t.Error("unknown service accepted")
+	}
+	// The database container was never created: reported as not running.
+	if _, err := sm.StreamSiteLogs(context.Background(), site.ID, LogQuery{}); !errors.Is(err, ErrSiteNotRunning) {
+		t.Errorf("missing container: err = %v, want ErrSiteNotRunning", err)
+	}
+}
@@ -0,0 +1,193 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/genmark"
+	"github.com/PeterBooker/locorum/internal/mail"
+	"github.com/PeterBooker/locorum/internal/types"
+	"github.com/PeterBooker/locorum/internal/utils"
+)
+
+// mailTagPrefix prefixes the slug in the tag a site's mail carries.
+const mailTagPrefix = "site-"
+
+// mailTagPluginRelPath is the mu-plugin that tags the site's outgoing
+// mail, relative to the site's app root.
+const mailTagPluginRelPath = "wp-content/mu-plugins/locorum-mail-tag.php"
+
+// mailTagPluginBody adds an X-Tags header to everything WordPress sends
+// through PHPMailer (wp_mail and every SMTP plugin built on it). Mailpit
+// turns the header into a tag, which is how captured mail is filtered
+// by site: the SMTP hop from PHP carries nothing else that survives to
+// the API. Mail sent with PHP's bare mail() stays untagged and only
+// shows up in the unfiltered mailbox. A relay-mode site's plugin adds
+// the relay tag too, queueing the mail for WatchMailRelay.
+const mailTagPluginBody = `<?php
+// #locorum-generated — DO NOT remove this line if you want Locorum to keep
+// this file in sync. Removing the signature opts out of all Locorum-
+// managed updates to this file forever.
+//
+// Tags outgoing mail with the site that sent it so Locorum's mail
+// capture can filter by site.
+
+if (!defined('ABSPATH')) { exit; }
+
+add_action('phpmailer_init', function ($phpmailer) {
+    $phpmailer->addCustomHeader('X-Tags', '%s');
+});
+`
+
+// MailTag returns the capture-server tag carried by mail the site sends.
+func MailTag(slug string) string {
+	return mailTagPrefix + slug
+}
+
+// installMailTagPlugin writes the site's mail-tag mu-plugin. Same
+// ownership rules as installAutoLoginPlugin: a user-stripped marker
+// leaves the file alone.
+func installMailTagPlugin(site *types.Site) error {
+	if site == nil || site.FilesDir == "" || site.Slug == "" {
+		return errors.New("installMailTagPlugin: empty site")
+	}
+	pluginPath := filepath.Join(autoLoginAppRoot(site), filepath.FromSlash(mailTagPluginRelPath))
+	if err := utils.EnsureDir(filepath.Dir(pluginPath)); err != nil {
+		return fmt.Errorf("creating mu-plugins dir: %w", err)
+	}
+	tags := MailTag(site.Slug)
+	if MailMode(site) == mail.ModeRelay {
+		tags += "," + relayQueueTag
+	}
+	body := fmt.Sprintf(mailTagPluginBody, tags)
+	if err := genmark.WriteIfManaged(pluginPath, []byte(body), 0o600); err != nil &&
+		!errors.Is(err, genmark.ErrUserOwned) {
+		return fmt.Errorf("writing mail-tag mu-plugin: %w", err)
+	}
+	return nil
+}
+
+// SetMailClient overrides the capture-server client. Test seam;
+// production leaves it unset and mailbox resolves the global mail
+// container's published API port on each call.
+func (sm *SiteManager) SetMailClient(c mail.Client) {
+	sm.mail = c
+}
+
+// mailbox returns a client for the global mail container. The API port
+// is ephemeral and changes whenever the container is recreated, so it
+// is looked up per call rather than cached.
+func (sm *SiteManager) mailbox(ctx context.Context) (mail.Client, error) {
+	if sm.mail != nil {
+		return sm.mail, nil
+	}
+	port, err := sm.d.PublishedHostPort(ctx, docker.MailContainerName, docker.MailAPIPort)
+	if err != nil && !errors.Is(err, docker.ErrNotFound) {
+		return nil, fmt.Errorf("locating mail capture: %w", err)
+	}
+	if port == 0 {
+		return nil, errors.New("mail capture is not running; start a site to bring it up")
+	}
+	return mail.NewMailpit("http://127.0.0.1:" + strconv.Itoa(port)), nil
+}
+
+// ListMail returns captured mail, newest first. A non-empty siteID
+// limits it to mail that site sent; q.Search narrows it further.
+func (sm *SiteManager) ListMail(ctx context.Context, siteID string, q mail.Query) (*mail.Page, error) {
+	slug, err := sm.mailSiteSlug(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if slug != "" {
+		q.Tag = MailTag(slug)
+	}
+	mb, err := sm.mailbox(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := mb.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Messages {
+		page.Messages[i].Site = mailSite(page.Messages[i].Tags)
+	}
+	return page, nil
+}
+
+// GetMail returns one captured message. With a siteID, mail another
+// site sent is reported as not found, so a site-scoped caller cannot
+// read it by guessing ids.
+func (sm *SiteManager) GetMail(ctx context.Context, siteID, id string) (*mail.Message, error) {
+	slug, err := sm.mailSiteSlug(siteID)
+	if err != nil {
+		return nil, err
+	}
+	mb, err := sm.mailbox(ctx)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := mb.Get(ctx, id)
+	if errors.Is(err, mail.ErrNotFound) {
+		return nil, fmt.Errorf("message %q not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if slug != "" && !slices.Contains(msg.Tags, MailTag(slug)) {
+		return nil, fmt.Errorf("message %q not found", id)
+	}
+	msg.Site = mailSite(msg.Tags)
+	return msg, nil
+}
+
+// PurgeMail deletes captured mail: the site's when siteID is set,
+// matching search when it is non-empty, and the whole mailbox when
+// both are empty.
+func (sm *SiteManager) PurgeMail(ctx context.Context, siteID, search string) error {
+	slug, err := sm.mailSiteSlug(siteID)
+	if err != nil {
+		return err
+	}
+	q := mail.Query{Search: search}
+	if slug != "" {
+		q.Tag = MailTag(slug)
+	}
+	mb, err := sm.mailbox(ctx)
+	if err != nil {
+		return err
+	}
+	return mb.Delete(ctx, q)
+}
+
+// mailSiteSlug resolves an optional siteID to the slug its mail is
+// tagged with; "" stays "".
+func (sm *SiteManager) mailSiteSlug(siteID string) (string, error) {
+	if siteID == "" {
+		return "", nil
+	}
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return "", fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return "", fmt.Errorf("site %q not found", siteID)
+	}
+	return site.Slug, nil
+}
+
+// mailSite returns the slug named by the first site tag, or "".
+func mailSite(tags []string) string {
+	for _, t := range tags {
+		if slug, ok := strings.CutPrefix(t, mailTagPrefix); ok && slug != "" {
+			return slug
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package sites
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/mail"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// fakeMailbox is an in-memory mail.Client that records the last query.
+type fakeMailbox struct {
+	msgs      map[string]*mail.Message
+	lastQuery mail.Query
+	deleted   []mail.Query
+}
+
+func (f *fakeMailbox) List(_ context.Context, q mail.Query) (*mail.Page, error) {
+	f.lastQuery = q
+	page := &mail.Page{}
+	for _, m := range f.msgs {
+		page.Messages = append(page.Messages, m.Summary)
+	}
+	page.Total = len(page.Messages)
+	return page, nil
+}
+
+func (f *fakeMailbox) Get(_ context.Context, id string) (*mail.Message, error) {
+	if m, ok := f.msgs[id]; ok {
+		return m, nil
+	}
+	return nil, mail.ErrNotFound
+}
+
+func (f *fakeMailbox) Delete(_ context.Context, q mail.Query) error {
+	f.deleted = append(f.deleted, q)
+	return nil
+}
+
+func (f *fakeMailbox) Raw(_ context.Context, id string) ([]byte, error) {
+	if _, ok := f.msgs[id]; ok {
+		return []byte("Subject: " + f.msgs[id].Subject + "\r\n\r\nbody\r\n"), nil
+	}
+	return nil, mail.ErrNotFound
+}
+
+func (f *fakeMailbox) SetTags(_ context.Context, id string, tags []string) error {
+	m, ok := f.msgs[id]
+	if !ok {
+		return mail.ErrNotFound
+	}
+	m.Tags = tags
+	return nil
+}
+
+func TestMailFilteredBySite(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	mb := &fakeMailbox{msgs: map[string]*mail.Message{
+		"own":   {Summary: mail.Summary{ID: "own", Tags: []string{MailTag("lansite")}}},
+		"other": {Summary: mail.Summary{ID: "other", Tags: []string{MailTag("shop")}}},
+	}}
+	sm.SetMailClient(mb)
+	ctx := context.Background()
+
+	page, err := sm.ListMail(ctx, site.ID, mail.Query{Search: "subject:reset"})
+	if err != nil {
+		t.Fatalf("ListMail: %v", err)
+	}
+	if mb.lastQuery.Tag != "site-lansite" || mb.lastQuery.Search != "subject:reset" {
+		t.Errorf("query = %+v, want the site tag plus the search", mb.lastQuery)
+	}
+	for _, m := range page.Messages {
+		if m.Site == "" {
+			t.Errorf("message %s: site not filled from its tag", m.ID)
+		}
+	}
+
+	if msg, err := sm.GetMail(ctx, site.ID, "own"); err != nil || msg.Site != "lansite" {
+		t.Errorf("GetMail own = %+v, %v", msg, err)
+	}
+	if _, err := sm.GetMail(ctx, site.ID, "other"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("another site's message: err = %v, want not found", err)
+	}
+	if _, err := sm.GetMail(ctx, "", "other"); err != nil {
+		t.Errorf("unscoped GetMail: %v", err)
+	}
+	if _, err := sm.ListMail(ctx, "nope", mail.Query{}); err == nil {
+		t.Error("unknown site: want error")
+	}
+
+	if err := sm.PurgeMail(ctx, site.ID, ""); err != nil {
+		t.Fatalf("PurgeMail: %v", err)
+	}
+	if err := sm.PurgeMail(ctx, "", ""); err != nil {
+		t.Fatalf("PurgeMail all: %v", err)
+	}
+	if len(mb.deleted) != 2 || mb.deleted[0].Tag != "site-lansite" || !mb.deleted[1].IsZero() {
+		t.Errorf("deletes = %+v", mb.deleted)
+	}
+}
+
+func TestInstallMailTagPlugin(t *testing.T) {
+	site := &types.Site{Slug: "shop", FilesDir: t.TempDir(), PublicDir: "/"}
+	if err := installMailTagPlugin(site); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	body, err := os.ReadFile(filepath.Join(site.FilesDir, "wp-content", "mu-plugins", "locorum-mail-tag.php"))
+	if err != nil {
+		t.Fatalf("read plugin: %v", err)
+	}
+	if !strings.Contains(string(body), "#locorum-generated") || !strings.Contains(string(body), "'X-Tags', 'site-shop'") {
+		t.Errorf("plugin body:\n%s", body)
+	}
+}
+
+func TestInstallMailTagPluginRelay(t *testing.T) {
+	site := &types.Site{Slug: "shop", FilesDir: t.TempDir(), PublicDir: "/", MailMode: mail.ModeRelay}
+	if err := installMailTagPlugin(site); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	body, err := os.ReadFile(filepath.Join(site.FilesDir, "wp-content", "mu-plugins", "locorum-mail-tag.php"))
+	if err != nil {
+		t.Fatalf("read plugin: %v", err)
+	}
+	if !strings.Contains(string(body), "'X-Tags', 'site-shop,relay'") {
+		t.Errorf("relay site's plugin does not queue mail for the relay:\n%s", body)
+	}
+}
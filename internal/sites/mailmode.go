@@ -0,0 +1,338 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/mail"
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// Tags the relay adds to captured mail. relayQueueTag is written by the
+// mail-tag mu-plugin of a relay-mode site; relaySendingTag claims a
+// message before it is sent, and the other three record what the relay
+// did with it, so it is handled once.
+const (
+	relayQueueTag   = "relay"
+	relaySendingTag = "relay-sending"
+	relayedTag      = "relayed"
+	relayBlockedTag = "relay-blocked"
+	relayFailedTag  = "relay-failed"
+)
+
+// relayDefaultPort is assumed when the smarthost is given without one:
+// the submission port, which every provider with STARTTLS listens on.
+const relayDefaultPort = "587"
+
+// relayBatch caps the messages forwarded per site per pass.
+const relayBatch = 50
+
+// MaxMailRelayDomains caps the entries on one site's relay allowlist.
+const MaxMailRelayDomains = 32
+
+var relayDomainRe = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// relaySendFunc delivers one message to a smarthost; smtp.SendMail's
+// shape, which upgrades to STARTTLS when the server offers it.
+type relaySendFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// MailSettings is a site's outbound mail configuration as set through
+// `locorum site mail` and the Mail tab. RelayPassword is write-only:
+// it is never filled in on the way out.
+type MailSettings struct {
+	Mode          string   `json:"mode"`
+	RelayHost     string   `json:"relayHost,omitempty"`
+	RelayUser     string   `json:"relayUser,omitempty"`
+	RelayPassword string   `json:"relayPassword,omitempty"`
+	RelayDomains  []string `json:"relayDomains,omitempty"`
+}
+
+// MailMode returns the site's effective mail mode; rows that predate the
+// setting capture.
+func MailMode(site *types.Site) string {
+	if site.MailMode == "" {
+		return mail.ModeCapture
+	}
+	return site.MailMode
+}
+
+// ValidMailMode reports whether mode is one of mail.Modes.
+func ValidMailMode(mode string) bool {
+	return slices.Contains(mail.Modes, mode)
+}
+
+// NormaliseMailSettings validates s and returns it in canonical form:
+// lower-case mode, a smarthost with an explicit port, and a
+// de-duplicated, lower-case domain list. The relay fields are only
+// checked (and only kept) in relay mode.
+func NormaliseMailSettings(s MailSettings) (MailSettings, error) {
+	mode := strings.ToLower(strings.TrimSpace(s.Mode))
+	if mode == "" {
+		mode = mail.ModeCapture
+	}
+	if !ValidMailMode(mode) {
+		return MailSettings{}, fmt.Errorf("invalid mail mode %q (allowed: %s)", s.Mode, strings.Join(mail.Modes, ", "))
+	}
+	if mode != mail.ModeRelay {
+		return MailSettings{Mode: mode}, nil
+	}
+
+	host, err := normaliseRelayHost(s.RelayHost)
+	if err != nil {
+		return MailSettings{}, err
+	}
+	domains, err := NormaliseMailRelayDomains(s.RelayDomains)
+	if err != nil {
+		return MailSettings{}, err
+	}
+	if len(domains) == 0 {
+		return MailSettings{}, errors.New("relay mode needs at least one allowed recipient domain")
+	}
+	user := strings.TrimSpace(s.RelayUser)
+	if user == "" && s.RelayPassword != "" {
+		return MailSettings{}, errors.New("relay password given without a relay user")
+	}
+	return MailSettings{
+		Mode:          mode,
+		RelayHost:     host,
+		RelayUser:     user,
+		RelayPassword: s.RelayPassword,
+		RelayDomains:  domains,
+	}, nil
+}
+
+// normaliseRelayHost checks a "host" or "host:port" smarthost and adds
+// the submission port when none is given.
+func normaliseRelayHost(h string) (string, error) {
+	h = strings.TrimSpace(h)
+	if h == "" {
+		return "", errors.New("relay mode needs a smarthost (host or host:port)")
+	}
+	host, port, err := net.SplitHostPort(h)
+	if err != nil {
+		host, port = strings.Trim(h, "[]"), relayDefaultPort
+	}
+	if host == "" || strings.ContainsAny(host, " /") {
+		return "", fmt.Errorf("invalid smarthost %q", h)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid smarthost port %q", port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// NormaliseMailRelayDomains lower-cases each entry, drops a leading "@"
+// or "*.", and returns the valid domains de-duplicated in input order.
+// An entry also allows its subdomains.
+func NormaliseMailRelayDomains(entries []string) ([]string, error) {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		d := strings.ToLower(strings.TrimSpace(e))
+		d = strings.TrimPrefix(strings.TrimPrefix(d, "@"), "*.")
+		if d == "" {
+			continue
+		}
+		if !relayDomainRe.MatchString(d) {
+			return nil, fmt.Errorf("invalid relay domain %q", e)
+		}
+		if !slices.Contains(out, d) {
+			out = append(out, d)
+		}
+	}
+	if len(out) > MaxMailRelayDomains {
+		return nil, fmt.Errorf("too many relay domains (%d); the limit is %d", len(out), MaxMailRelayDomains)
+	}
+	return out, nil
+}
+
+// SetMailMode changes where the site's outgoing mail goes. Switching
+// away from relay keeps the stored relay settings for a later switch
+// back; in relay mode they are replaced, except that an empty password
+// keeps the stored one for the same user. Site must be stopped: the
+// mode is baked into the PHP container's ini and the mail-tag
+// mu-plugin at start.
+func (sm *SiteManager) SetMailMode(siteID string, s MailSettings) error {
+	norm, err := NormaliseMailSettings(s)
+	if err != nil {
+		return err
+	}
+
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if site.Started {
+		return errors.New("site must be stopped to change mail mode")
+	}
+
+	site.MailMode = norm.Mode
+	if norm.Mode == mail.ModeCapture {
+		site.MailMode = ""
+	}
+	oldPassword := site.MailRelayPassword
+	if norm.Mode == mail.ModeRelay {
+		if norm.RelayPassword != "" || norm.RelayUser != site.MailRelayUser {
+			site.MailRelayPassword = norm.RelayPassword
+		}
+		site.MailRelayHost = norm.RelayHost
+		site.MailRelayUser = norm.RelayUser
+		site.MailRelayDomains = norm.RelayDomains
+	}
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+	if site.MailRelayPassword != oldPassword {
+		secrets.Remove(oldPassword)
+		secrets.Add(site.MailRelayPassword)
+	}
+
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	return nil
+}
+
+// WatchMailRelay forwards the captured mail of relay-mode sites to
+// their smarthost every interval until ctx is done. Only messages the
+// mail-tag mu-plugin queued while the site was in relay mode are
+// considered, and each is tagged with the outcome so it is handled
+// once: relayed, relay-blocked (no recipient in an allowed domain) or
+// relay-failed (not retried; the message stays in the capture inbox).
+// Recipients outside the allowlist are dropped from the delivery.
+//
+// Only the process holding the daemon lock runs this loop. Each message
+// is also tagged relay-sending before delivery, so a pass that finds it
+// claimed leaves it alone; one left claimed by a crash is not retried.
+func (sm *SiteManager) WatchMailRelay(ctx context.Context, every time.Duration) {
+	for sleepWithCtx(ctx, every) {
+		sm.relayMail(ctx)
+	}
+}
+
+func (sm *SiteManager) relayMail(ctx context.Context) {
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		slog.Debug("mail relay: GetSites failed", "err", err.Error())
+		return
+	}
+	rows = slices.DeleteFunc(rows, func(s types.Site) bool {
+		return MailMode(&s) != mail.ModeRelay || s.MailRelayHost == ""
+	})
+	if len(rows) == 0 {
+		return
+	}
+	mb, err := sm.mailbox(ctx)
+	if err != nil {
+		// Capture is down; whatever it held is gone or waits for it.
+		return
+	}
+	for i := range rows {
+		sm.relaySiteMail(ctx, mb, &rows[i])
+	}
+}
+
+func (sm *SiteManager) relaySiteMail(ctx context.Context, mb mail.Client, site *types.Site) {
+	page, err := mb.List(ctx, mail.Query{
+		Tag:    MailTag(site.Slug),
+		Search: "tag:" + relayQueueTag + " !tag:" + relaySendingTag + " !tag:" + relayedTag + " !tag:" + relayBlockedTag + " !tag:" + relayFailedTag,
+		Limit:  relayBatch,
+	})
+	if err != nil {
+		slog.Debug("mail relay: listing queued mail failed", "site", site.Slug, "err", err.Error())
+		return
+	}
+	for _, m := range page.Messages {
+		if !slices.Contains(m.Tags, relayQueueTag) || relayHandled(m.Tags) {
+			continue
+		}
+		// Claim first: a message that cannot be marked is not sent,
+		// since nothing would stop the next pass sending it again.
+		if err := mb.SetTags(ctx, m.ID, append(slices.Clone(m.Tags), relaySendingTag)); err != nil {
+			slog.Warn("mail relay: claiming message failed", "site", site.Slug, "id", m.ID, "err", err.Error())
+			continue
+		}
+		outcome := sm.relayMessage(ctx, mb, site, m.ID)
+		if err := mb.SetTags(ctx, m.ID, append(slices.Clone(m.Tags), outcome)); err != nil {
+			slog.Warn("mail relay: tagging message failed", "site", site.Slug, "id", m.ID, "err", err.Error())
+		}
+	}
+}
+
+// relayMessage forwards one message and returns the outcome tag.
+func (sm *SiteManager) relayMessage(ctx context.Context, mb mail.Client, site *types.Site, id string) string {
+	msg, err := mb.Get(ctx, id)
+	if err != nil {
+		slog.Warn("mail relay: reading message failed", "site", site.Slug, "id", id, "err", err.Error())
+		return relayFailedTag
+	}
+	rcpts := relayRecipients(msg, site.MailRelayDomains)
+	if len(rcpts) == 0 {
+		return relayBlockedTag
+	}
+	raw, err := mb.Raw(ctx, id)
+	if err != nil {
+		slog.Warn("mail relay: reading message failed", "site", site.Slug, "id", id, "err", err.Error())
+		return relayFailedTag
+	}
+	var auth smtp.Auth
+	if site.MailRelayUser != "" {
+		host, _, _ := net.SplitHostPort(site.MailRelayHost)
+		auth = smtp.PlainAuth("", site.MailRelayUser, site.MailRelayPassword, host)
+	}
+	send := sm.relaySend
+	if send == nil {
+		send = smtp.SendMail
+	}
+	if err := send(site.MailRelayHost, auth, msg.From.Address, rcpts, raw); err != nil {
+		slog.Warn("mail relay: delivery failed", "site", site.Slug, "id", id, "err", secrets.RedactString(err.Error()))
+		return relayFailedTag
+	}
+	slog.Info("mail relay: delivered", "site", site.Slug, "id", id, "recipients", len(rcpts))
+	return relayedTag
+}
+
+// relayRecipients returns the message's recipients whose domain is on
+// the allowlist or a subdomain of an entry.
+func relayRecipients(msg *mail.Message, domains []string) []string {
+	var out []string
+	for _, list := range [][]mail.Address{msg.To, msg.Cc, msg.Bcc} {
+		for _, a := range list {
+			addr := strings.ToLower(strings.TrimSpace(a.Address))
+			at := strings.LastIndexByte(addr, '@')
+			if at < 0 || slices.Contains(out, addr) {
+				continue
+			}
+			domain := addr[at+1:]
+			if slices.ContainsFunc(domains, func(d string) bool {
+				return domain == d || strings.HasSuffix(domain, "."+d)
+			}) {
+				out = append(out, addr)
+			}
+		}
+	}
+	return out
+}
+
+func relayHandled(tags []string) bool {
+	return slices.ContainsFunc(tags, func(t string) bool {
+		return t == relaySendingTag || t == relayedTag || t == relayBlockedTag || t == relayFailedTag
+	})
+}
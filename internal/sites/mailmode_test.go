@@ -0,0 +1,155 @@
+package sites
+
+import (
+	"context"
+	"net/smtp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/mail"
+	"github.com/PeterBooker/locorum/internal/secrets"
+)
+
+func TestNormaliseMailSettings(t *testing.T) {
+	got, err := NormaliseMailSettings(MailSettings{
+		Mode: " Relay ", RelayHost: "smtp.example.com", RelayUser: "qa",
+		RelayDomains: []string{"@Example.com", "*.qa.example.org", "example.com", ""},
+	})
+	if err != nil {
+		t.Fatalf("NormaliseMailSettings: %v", err)
+	}
+	if got.Mode != mail.ModeRelay || got.RelayHost != "smtp.example.com:587" {
+		t.Errorf("mode/host = %q/%q, want relay with the submission port", got.Mode, got.RelayHost)
+	}
+	if !slices.Equal(got.RelayDomains, []string{"example.com", "qa.example.org"}) {
+		t.Errorf("domains = %v", got.RelayDomains)
+	}
+
+	if got, err := NormaliseMailSettings(MailSettings{Mode: "blackhole", RelayHost: "ignored"}); err != nil || got.RelayHost != "" {
+		t.Errorf("blackhole = %+v, %v; want the relay fields dropped", got, err)
+	}
+	if got, _ := NormaliseMailSettings(MailSettings{}); got.Mode != mail.ModeCapture {
+		t.Errorf("empty mode = %q, want capture", got.Mode)
+	}
+
+	for _, bad := range []MailSettings{
+		{Mode: "forward"},
+		{Mode: "relay", RelayDomains: []string{"example.com"}},
+		{Mode: "relay", RelayHost: "smtp.example.com"},
+		{Mode: "relay", RelayHost: "smtp.example.com:99999", RelayDomains: []string{"example.com"}},
+		{Mode: "relay", RelayHost: "smtp.example.com", RelayDomains: []string{"not a domain"}},
+		{Mode: "relay", RelayHost: "smtp.example.com", RelayDomains: []string{"example.com"}, RelayPassword: "x"},
+	} {
+		if _, err := NormaliseMailSettings(bad); err == nil {
+			t.Errorf("NormaliseMailSettings(%+v) accepted", bad)
+		}
+	}
+}
+
+func TestSetMailMode(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+
+	relay := MailSettings{Mode: "relay", RelayHost: "smtp.example.com:2525", RelayUser: "qa", RelayPassword: "relay-secret-1", RelayDomains: []string{"example.com"}}
+	if err := sm.SetMailMode(site.ID, relay); err != nil {
+		t.Fatalf("SetMailMode relay: %v", err)
+	}
+	if got := secrets.RedactString("pw=relay-secret-1"); strings.Contains(got, "relay-secret-1") {
+		t.Errorf("relay password not registered as a secret: %q", got)
+	}
+
+	// Re-saving without a password keeps the stored one.
+	relay.RelayPassword = ""
+	relay.RelayDomains = []string{"example.com", "example.org"}
+	if err := sm.SetMailMode(site.ID, relay); err != nil {
+		t.Fatalf("SetMailMode relay again: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if got.MailRelayPassword != "relay-secret-1" || len(got.MailRelayDomains) != 2 {
+		t.Errorf("after re-save: password %q, domains %v", got.MailRelayPassword, got.MailRelayDomains)
+	}
+
+	// Leaving relay keeps the settings for next time.
+	if err := sm.SetMailMode(site.ID, MailSettings{Mode: "blackhole"}); err != nil {
+		t.Fatalf("SetMailMode blackhole: %v", err)
+	}
+	got, _ = sm.st.GetSite(site.ID)
+	if got.MailMode != mail.ModeBlackhole || got.MailRelayHost != "smtp.example.com:2525" {
+		t.Errorf("after blackhole: mode %q, host %q", got.MailMode, got.MailRelayHost)
+	}
+	if err := sm.SetMailMode(site.ID, MailSettings{Mode: "capture"}); err != nil {
+		t.Fatalf("SetMailMode capture: %v", err)
+	}
+	if got, _ := sm.st.GetSite(site.ID); got.MailMode != "" {
+		t.Errorf("capture stored as %q, want the empty default", got.MailMode)
+	}
+
+	got.Started = true
+	if _, err := sm.st.UpdateSite(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetMailMode(site.ID, MailSettings{Mode: "blackhole"}); err == nil {
+		t.Error("SetMailMode on a running site succeeded")
+	}
+}
+
+func TestRelayMail(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	if err := sm.SetMailMode(site.ID, MailSettings{
+		Mode: "relay", RelayHost: "smtp.example.com", RelayUser: "qa", RelayPassword: "pw",
+		RelayDomains: []string{"example.com"},
+	}); err != nil {
+		t.Fatalf("SetMailMode: %v", err)
+	}
+
+	queued := []string{MailTag("lansite"), relayQueueTag}
+	mb := &fakeMailbox{msgs: map[string]*mail.Message{
+		"team": {
+			Summary: mail.Summary{ID: "team", Subject: "Reset", Tags: slices.Clone(queued),
+				From: mail.Address{Address: "wordpress@lansite.localhost"},
+				To:   []mail.Address{{Address: "QA@team.example.com"}, {Address: "someone@gmail.com"}}},
+			Bcc: []mail.Address{{Address: "lead@example.com"}},
+		},
+		"outside": {Summary: mail.Summary{ID: "outside", Tags: slices.Clone(queued),
+			To: []mail.Address{{Address: "customer@gmail.com"}}}},
+		"captured": {Summary: mail.Summary{ID: "captured", Tags: []string{MailTag("lansite")},
+			To: []mail.Address{{Address: "qa@example.com"}}}},
+		// Claimed by another pass that is still sending it.
+		"claimed": {Summary: mail.Summary{ID: "claimed", Tags: append(slices.Clone(queued), relaySendingTag),
+			To: []mail.Address{{Address: "qa@example.com"}}}},
+	}}
+	sm.SetMailClient(mb)
+
+	var sent [][]string
+	sm.relaySend = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		if addr != "smtp.example.com:587" || a == nil || from != "wordpress@lansite.localhost" || len(msg) == 0 {
+			t.Errorf("send(%q, %v, %q, %v, %d bytes)", addr, a, from, to, len(msg))
+		}
+		if !slices.Contains(mb.msgs["team"].Tags, relaySendingTag) {
+			t.Errorf("team sent before it was claimed: tags %v", mb.msgs["team"].Tags)
+		}
+		sent = append(sent, to)
+		return nil
+	}
+
+	sm.relayMail(context.Background())
+	sm.relayMail(context.Background())
+
+	if len(sent) != 1 || !slices.Equal(sent[0], []string{"qa@team.example.com", "lead@example.com"}) {
+		t.Errorf("sent = %v, want one delivery to the allowed recipients", sent)
+	}
+	if tags := mb.msgs["team"].Tags; !slices.Contains(tags, relayedTag) || !slices.Contains(tags, MailTag("lansite")) {
+		t.Errorf("team tags = %v, want relayed added to the existing tags", tags)
+	}
+	if tags := mb.msgs["team"].Tags; slices.Contains(tags, relaySendingTag) {
+		t.Errorf("team tags = %v, want the claim replaced by the outcome", tags)
+	}
+	if tags := mb.msgs["outside"].Tags; !slices.Contains(tags, relayBlockedTag) {
+		t.Errorf("outside tags = %v, want relay-blocked", tags)
+	}
+	if tags := mb.msgs["captured"].Tags; len(tags) != 1 {
+		t.Errorf("mail captured before relay mode was touched: %v", tags)
+	}
+}
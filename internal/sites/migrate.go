@@ -4,17 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/sites/sitesteps"
+	"github.com/PeterBooker/locorum/internal/types"
 )
 
 // MigrateEngineOptions describes the destination engine + version of an
 // engine migration. Empty fields are interpreted as "leave unchanged".
 type MigrateEngineOptions struct {
-	// TargetEngine is the destination engine kind ("mysql" or
-	// "mariadb"). Pass empty to keep the current engine but change
+	// TargetEngine is the destination engine kind ("mysql", "mariadb"
+	// or "sqlite"). Pass empty to keep the current engine but change
 	// version (used for unsafe in-place version transitions).
 	TargetEngine string
 
@@ -38,29 +42,21 @@ type MigrateEngineOptions struct {
 //  6. Restore the snapshot with AllowEngineMismatch=true so the new
 //     engine accepts a dump originally produced by the old one.
 //
+// Moves to or from SQLite can't restore the snapshot — a SQLite snapshot
+// is the database file, not SQL — so before step 2 the data is also
+// dumped as MySQL SQL into FilesDir (dumpSQL), and step 6 imports that
+// dump instead (importSQLFile). The snapshot is still taken as the
+// backup, and the dump is kept on failure.
+//
 // Failure between steps 4 and 6 leaves the site in a known-broken state
 // — the SQL row points at the new engine, the volume is empty, the
 // snapshot is on disk. The user can re-trigger MigrateEngine from the
 // snapshot to finish the migration. We deliberately don't try to roll
 // back the SQL update: re-rolling forward is the safer recovery.
 func (sm *SiteManager) MigrateEngine(ctx context.Context, siteID string, opts MigrateEngineOptions) error {
-	if opts.TargetVersion == "" {
-		return errors.New("MigrateEngine: TargetVersion is required")
-	}
-	site, err := sm.st.GetSite(siteID)
+	site, targetEngine, err := sm.migrateTarget(siteID, opts)
 	if err != nil {
-		return fmt.Errorf("fetching site: %w", err)
-	}
-	if site == nil {
-		return fmt.Errorf("site %q not found", siteID)
-	}
-
-	targetEngine := opts.TargetEngine
-	if targetEngine == "" {
-		targetEngine = site.DBEngine
-	}
-	if !dbengine.IsValid(dbengine.Kind(targetEngine)) {
-		return fmt.Errorf("unknown target engine %q", targetEngine)
+		return err
 	}
 
 	noChange := targetEngine == site.DBEngine && opts.TargetVersion == site.DBVersion
@@ -99,6 +95,18 @@ func (sm *SiteManager) MigrateEngine(ctx context.Context, siteID string, opts Mi
 		slog.Info("migrate: pre-snapshot saved", "path", snapshotPath)
 	}
 
+	crossFormat := migrateCrossFormat(site, targetEngine)
+	var dumpName string
+	if crossFormat {
+		mu.Lock()
+		dumpName, err = sm.writeMigrateDump(ctx, site)
+		mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("migrate: sql dump: %w", err)
+		}
+		slog.Info("migrate: sql dump saved", "path", filepath.Join(site.FilesDir, dumpName))
+	}
+
 	if err := sm.StopSite(ctx, siteID); err != nil {
 		return fmt.Errorf("migrate: stop: %w", err)
 	}
@@ -134,7 +142,15 @@ func (sm *SiteManager) MigrateEngine(ctx context.Context, siteID string, opts Mi
 	if err != nil {
 		return fmt.Errorf("migrate: re-fetch: %w", err)
 	}
-	if snapshotPath != "" {
+	if crossFormat {
+		hostDump := filepath.Join(site.FilesDir, dumpName)
+		if _, err := sm.importSQLFile(ctx, site, "/var/www/html/"+dumpName); err != nil {
+			return fmt.Errorf("migrate: import: %w (dump at %s)", err, hostDump)
+		}
+		if err := os.Remove(hostDump); err != nil {
+			slog.Warn("migrate: removing sql dump", "path", hostDump, "err", err.Error())
+		}
+	} else if snapshotPath != "" {
 		if err := sm.RestoreSnapshot(ctx, siteID, snapshotPath, RestoreSnapshotOptions{AllowEngineMismatch: true}); err != nil {
 			return fmt.Errorf("migrate: restore: %w (snapshot at %s)", err, snapshotPath)
 		}
@@ -154,3 +170,65 @@ func (sm *SiteManager) MigrateEngine(ctx context.Context, siteID string, opts Mi
 	)
 	return nil
 }
+
+// migrateTarget fetches siteID and resolves the engine MigrateEngine
+// moves it to: opts.TargetEngine, or the current one when empty.
+func (sm *SiteManager) migrateTarget(siteID string, opts MigrateEngineOptions) (*types.Site, string, error) {
+	if opts.TargetVersion == "" {
+		return nil, "", errors.New("MigrateEngine: TargetVersion is required")
+	}
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, "", fmt.Errorf("site %q not found", siteID)
+	}
+
+	targetEngine := opts.TargetEngine
+	if targetEngine == "" {
+		targetEngine = site.DBEngine
+	}
+	if !dbengine.IsValid(dbengine.Kind(targetEngine)) {
+		return nil, "", fmt.Errorf("unknown target engine %q", targetEngine)
+	}
+	return site, targetEngine, nil
+}
+
+// migrateCrossFormat reports whether a move to targetEngine crosses
+// between SQLite and a SQL server, so a snapshot cannot be restored.
+func migrateCrossFormat(site *types.Site, targetEngine string) bool {
+	return (dbengine.Resolve(site).Kind() == dbengine.SQLite) != (dbengine.Kind(targetEngine) == dbengine.SQLite)
+}
+
+// writeMigrateDump writes the site's database as filtered MySQL SQL into
+// FilesDir, where the PHP container sees it under /var/www/html, and
+// returns the file name. 0o600: the dump holds password hashes and salts.
+func (sm *SiteManager) writeMigrateDump(ctx context.Context, site *types.Site) (string, error) {
+	token, err := importToken()
+	if err != nil {
+		return "", err
+	}
+	name := "locorum-migrate-" + token + ".sql"
+	path := filepath.Join(site.FilesDir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := sm.dumpSQL(ctx, site, pw)
+		_ = pw.CloseWithError(err)
+	}()
+	_, err = FilterImportStream(pr, f)
+	_ = pr.CloseWithError(err)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,76 @@
+package sites
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PeterBooker/locorum/internal/phpini"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// ValidatePHPOverrides checks a site's php.ini directives and extension
+// lists. Shared by AddSite, the config.yaml sync and imports so every
+// path into the sites table enforces the same rules.
+func ValidatePHPOverrides(site *types.Site) error {
+	if err := phpini.ValidateSettings(site.PHPIni); err != nil {
+		return err
+	}
+	return phpini.ValidateExtensions(site.PHPExtensionsEnable, site.PHPExtensionsDisable)
+}
+
+// normalisePHPOverrides collapses empty collections to nil so "no
+// overrides" is stored and compared as one value.
+func normalisePHPOverrides(site *types.Site) {
+	if len(site.PHPIni) == 0 {
+		site.PHPIni = nil
+	}
+	if len(site.PHPExtensionsEnable) == 0 {
+		site.PHPExtensionsEnable = nil
+	}
+	if len(site.PHPExtensionsDisable) == 0 {
+		site.PHPExtensionsDisable = nil
+	}
+}
+
+// SetPHPOverrides replaces the site's php.ini directives and extension
+// enable/disable lists. Nil or empty values clear them. Site must be
+// stopped — the extension lists are part of the PHP container's spec
+// hash and the INI is only rewritten on start.
+//
+// Emits OnSiteUpdated on success so the GUI redraws.
+func (sm *SiteManager) SetPHPOverrides(siteID string, ini map[string]string, enable, disable []string) error {
+	candidate := types.Site{PHPIni: ini, PHPExtensionsEnable: enable, PHPExtensionsDisable: disable}
+	normalisePHPOverrides(&candidate)
+	if err := ValidatePHPOverrides(&candidate); err != nil {
+		return err
+	}
+
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if site.Started {
+		return errors.New("site must be stopped to change PHP settings")
+	}
+
+	site.PHPIni = candidate.PHPIni
+	site.PHPExtensionsEnable = candidate.PHPExtensionsEnable
+	site.PHPExtensionsDisable = candidate.PHPExtensionsDisable
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+
+	sm.writeConfigYAML(site)
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package sites
+
+import "testing"
+
+func TestSetPHPOverrides(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := spxTestSite(t.TempDir())
+	if err := sm.st.AddSite(&site); err != nil {
+		t.Fatalf("AddSite: %v", err)
+	}
+
+	if err := sm.SetPHPOverrides(site.ID, map[string]string{"extension": "evil.so"}, nil, nil); err == nil {
+		t.Error("expected a blocked directive to be rejected")
+	}
+	if err := sm.SetPHPOverrides(site.ID, nil, []string{"xdebug"}, nil); err == nil {
+		t.Error("expected a managed extension to be rejected")
+	}
+	if err := sm.SetPHPOverrides(site.ID, map[string]string{"max_input_vars": "5000"}, []string{"pcov"}, []string{}); err != nil {
+		t.Fatalf("SetPHPOverrides: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if got.PHPIni["max_input_vars"] != "5000" || len(got.PHPExtensionsEnable) != 1 || got.PHPExtensionsDisable != nil {
+		t.Errorf("overrides = %v / %v / %v", got.PHPIni, got.PHPExtensionsEnable, got.PHPExtensionsDisable)
+	}
+
+	got.Started = true
+	if _, err := sm.st.UpdateSite(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetPHPOverrides(site.ID, nil, nil, nil); err == nil {
+		t.Error("expected running site to be rejected")
+	}
+}
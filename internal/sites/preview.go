@@ -0,0 +1,372 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/sites/configyaml"
+	"github.com/PeterBooker/locorum/internal/sites/sitesteps"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// Previews back the dry-run flag of the daemon's import, search-replace,
+// export, versions, migrate, LAN, SPX and create methods. Each Preview*
+// runs the same precondition checks as the operation it mirrors, so a
+// preview fails exactly where the real call would, then reports the
+// steps through orch.Dry. Most of these flows run hooks, snapshots and
+// row updates outside an orch.Plan; previewStep stands in for those, and
+// the real sitesteps are used wherever the flow itself uses them.
+
+// errPreviewOnly is returned by previewStep.Apply. Preview plans are
+// built for orch.Dry and never run.
+var errPreviewOnly = errors.New("preview step cannot be applied")
+
+// previewStep is one line of a preview plan. A non-nil err marks a step
+// the preview already knows would fail; orch.Dry reports it against the
+// step instead of failing the whole preview.
+type previewStep struct {
+	name string
+	desc string
+	err  error
+}
+
+func (s previewStep) Name() string                             { return s.name }
+func (s previewStep) Apply(context.Context) error              { return errPreviewOnly }
+func (s previewStep) Rollback(context.Context) error           { return nil }
+func (s previewStep) Describe(context.Context) (string, error) { return s.desc, s.err }
+
+// hookSteps previews the enabled hooks a flow fires at ev: one step, or
+// none when nothing would run. Listing hooks is a storage read.
+func (sm *SiteManager) hookSteps(site *types.Site, ev hooks.Event) []orch.Step {
+	if sm.hooks == nil {
+		return nil
+	}
+	name := "hooks:" + string(ev)
+	list, err := sm.st.ListHooksByEvent(site.ID, ev)
+	if err != nil {
+		return []orch.Step{previewStep{name: name, desc: "run " + string(ev) + " hooks", err: err}}
+	}
+	var cmds []string
+	for _, h := range list {
+		if h.Enabled {
+			cmds = append(cmds, fmt.Sprintf("%s %q", h.TaskType, h.Command))
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return []orch.Step{previewStep{
+		name: name,
+		desc: fmt.Sprintf("run %d %s hook(s): %s", len(cmds), ev, strings.Join(cmds, "; ")),
+	}}
+}
+
+// dryPlan wraps steps in a plan named after the real one and dry-runs it.
+func dryPlan(ctx context.Context, name string, steps []orch.Step) (orch.DryResult, error) {
+	return orch.Dry(ctx, orch.Plan{Name: name, Steps: steps})
+}
+
+// PreviewImportDB describes what ImportDB would do with hostPath. The
+// dump's format is checked by reading its header, as the import would.
+func (sm *SiteManager) PreviewImportDB(ctx context.Context, siteID, hostPath string, opts ImportDBOptions) (orch.DryResult, error) {
+	site, err := sm.importDBTarget(siteID, hostPath, opts)
+	if err != nil {
+		return orch.DryResult{}, err
+	}
+	if err := sniffDump(hostPath); err != nil {
+		return orch.DryResult{}, err
+	}
+
+	var steps []orch.Step
+	if !opts.SkipSnapshot {
+		steps = append(steps, previewStep{name: "snapshot", desc: "snapshot the database as pre_import"})
+	}
+	steps = append(steps, sm.hookSteps(site, hooks.PreImportDB)...)
+	steps = append(steps,
+		previewStep{
+			name: "prepare-dump",
+			desc: fmt.Sprintf("filter %s into %s", hostPath, filepath.Join(site.FilesDir, "locorum-import-<token>.sql")),
+		},
+		previewStep{
+			name: "wp-db-import",
+			desc: fmt.Sprintf("replace the site's %s %s database with the dump", site.DBEngine, site.DBVersion),
+		},
+		previewStep{name: "auto-search-replace", desc: importRewrites(site, opts)},
+	)
+	if opts.KeepDump {
+		steps = append(steps, previewStep{name: "cleanup-dump", desc: "keep the filtered dump in the files directory"})
+	} else {
+		steps = append(steps, previewStep{name: "cleanup-dump", desc: "delete the filtered dump"})
+	}
+	steps = append(steps, sm.hookSteps(site, hooks.PostImportDB)...)
+	return dryPlan(ctx, "import-db:"+site.Slug, steps)
+}
+
+// sniffDump opens hostPath the way prepareDump does, without reading
+// past the header.
+func sniffDump(hostPath string) error {
+	src, err := os.Open(hostPath)
+	if err != nil {
+		return fmt.Errorf("open dump: %w", err)
+	}
+	defer src.Close()
+	reader, err := decompressedReader(hostPath, src)
+	if err != nil {
+		return err
+	}
+	if rc, ok := reader.(io.Closer); ok && rc != src {
+		_ = rc.Close()
+	}
+	return nil
+}
+
+// importRewrites describes the auto-search-replace step of an import.
+func importRewrites(site *types.Site, opts ImportDBOptions) string {
+	var parts []string
+	if !opts.DisableAuto {
+		parts = append(parts, "rewrite the dump's siteurl and home (http and https) to https://"+site.Domain)
+	}
+	for _, p := range opts.SearchReplace {
+		parts = append(parts, "wp search-replace "+p.From+" → "+p.To)
+	}
+	if len(parts) == 0 {
+		return "no URL rewrites (auto-detection disabled)"
+	}
+	return strings.Join(parts, "; then ")
+}
+
+// PreviewSearchReplace describes what SearchReplace would do.
+func (sm *SiteManager) PreviewSearchReplace(ctx context.Context, siteID string, pairs []SearchReplacePair) (orch.DryResult, error) {
+	site, err := sm.searchReplaceTarget(siteID, pairs)
+	if err != nil {
+		return orch.DryResult{}, err
+	}
+	var steps []orch.Step
+	for i, p := range pairs {
+		name := fmt.Sprintf("search-replace-%d", i+1)
+		if p.From == p.To {
+			steps = append(steps, previewStep{name: name, desc: "skip " + p.From + ": from and to are identical"})
+			continue
+		}
+		if sm.shouldAutoSnapshot() {
+			steps = append(steps, previewStep{name: "snapshot", desc: "snapshot the database as pre_search_replace"})
+		}
+		steps = append(steps, previewStep{
+			name: name,
+			desc: fmt.Sprintf("wp search-replace %s %s --all-tables --skip-columns=guid", p.From, p.To),
+		})
+	}
+	return dryPlan(ctx, "search-replace:"+site.Slug, steps)
+}
+
+// PreviewExport describes what ExportSite would write to destPath. A
+// stopped site is reported against the dump step rather than refused:
+// ExportSite itself only fails once it reaches the dump.
+func (sm *SiteManager) PreviewExport(ctx context.Context, siteID, destPath string) (orch.DryResult, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return orch.DryResult{}, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return orch.DryResult{}, fmt.Errorf("site %q not found", siteID)
+	}
+
+	dump := previewStep{
+		name: "dump-database",
+		desc: fmt.Sprintf("dump the %s %s database", site.DBEngine, site.DBVersion),
+	}
+	if !site.Started {
+		dump.err = fmt.Errorf("%w: the database cannot be dumped", ErrSiteNotRunning)
+	}
+	archive := previewStep{
+		name: "write-archive",
+		desc: fmt.Sprintf("write %s with metadata.json, database.sql and files/ from %s", destPath, site.FilesDir),
+	}
+	if _, err := os.Lstat(destPath); err == nil {
+		archive.err = fmt.Errorf("%w: %s", ErrExportExists, destPath)
+	}
+
+	var steps []orch.Step
+	steps = append(steps, sm.hookSteps(site, hooks.PreExport)...)
+	steps = append(steps, dump, archive)
+	steps = append(steps, sm.hookSteps(site, hooks.PostExport)...)
+	return dryPlan(ctx, "export:"+site.Slug, steps)
+}
+
+// PreviewVersionsChange describes what UpdateSiteVersionsWithEngine
+// would do. A change that alters nothing previews as an empty plan.
+func (sm *SiteManager) PreviewVersionsChange(ctx context.Context, siteID string, change VersionsChange) (orch.DryResult, error) {
+	site, err := sm.versionsTarget(siteID)
+	if err != nil {
+		return orch.DryResult{}, err
+	}
+	containers := specNames(sm.serviceSpecs(site))
+	before := *site
+	changed, err := applyVersionsChange(site, change)
+	if err != nil {
+		return orch.DryResult{}, err
+	}
+
+	var steps []orch.Step
+	if changed {
+		steps = append(steps, sm.hookSteps(site, hooks.PreVersionsChange)...)
+		steps = append(steps,
+			&sitesteps.RemoveContainersStep{Engine: sm.d, Containers: containers},
+			previewStep{name: "update-site", desc: versionsDiff(&before, site) + "; new images are used on the next start"},
+		)
+		steps = append(steps, sm.hookSteps(site, hooks.PostVersionsChange)...)
+	}
+	return dryPlan(ctx, "versions-change:"+site.Slug, steps)
+}
+
+// versionsDiff lists the runtime versions that differ between before
+// and after, e.g. "PHP 8.2 → 8.3; mysql 8.0 → 8.4".
+func versionsDiff(before, after *types.Site) string {
+	var parts []string
+	if before.PHPVersion != after.PHPVersion {
+		parts = append(parts, "PHP "+before.PHPVersion+" → "+after.PHPVersion)
+	}
+	if before.DBVersion != after.DBVersion {
+		parts = append(parts, after.DBEngine+" "+before.DBVersion+" → "+after.DBVersion)
+	}
+	if before.CacheBackend != after.CacheBackend || before.CacheVersion != after.CacheVersion {
+		parts = append(parts, "cache "+before.CacheBackend+" "+before.CacheVersion+" → "+after.CacheBackend+" "+after.CacheVersion)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// PreviewMigrateEngine describes what MigrateEngine would do, including
+// the destructive volume purge. Migrating to the current engine and
+// version previews as an empty plan.
+func (sm *SiteManager) PreviewMigrateEngine(ctx context.Context, siteID string, opts MigrateEngineOptions) (orch.DryResult, error) {
+	site, targetEngine, err := sm.migrateTarget(siteID, opts)
+	if err != nil {
+		return orch.DryResult{}, err
+	}
+	name := "migrate-engine:" + site.Slug
+	if targetEngine == site.DBEngine && opts.TargetVersion == site.DBVersion {
+		return dryPlan(ctx, name, nil)
+	}
+	crossFormat := migrateCrossFormat(site, targetEngine)
+
+	var steps []orch.Step
+	if !site.Started {
+		steps = append(steps, previewStep{name: "start-site", desc: "start the site so its database can be read"})
+	}
+	if !opts.SkipSnapshot {
+		steps = append(steps, previewStep{name: "snapshot", desc: "snapshot the database as pre_migrate"})
+	}
+	if crossFormat {
+		steps = append(steps, previewStep{
+			name: "sql-dump",
+			desc: "dump the database as MySQL SQL into " + filepath.Join(site.FilesDir, "locorum-migrate-<token>.sql"),
+		})
+	}
+	steps = append(steps,
+		previewStep{name: "stop-site", desc: "stop the site"},
+		&sitesteps.PurgeVolumeStep{Engine: sm.d, Site: site},
+		previewStep{
+			name: "update-site",
+			desc: fmt.Sprintf("switch the database from %s %s to %s %s", site.DBEngine, site.DBVersion, targetEngine, opts.TargetVersion),
+		},
+		previewStep{name: "start-site", desc: "start the site on " + targetEngine + " " + opts.TargetVersion},
+	)
+	switch {
+	case crossFormat:
+		steps = append(steps, previewStep{name: "import-dump", desc: "import the SQL dump into the new database, then delete it"})
+	case !opts.SkipSnapshot:
+		steps = append(steps, previewStep{name: "restore-snapshot", desc: "restore the pre_migrate snapshot into the new database"})
+	default:
+		steps = append(steps, previewStep{name: "restore-snapshot", desc: "nothing to restore: without a snapshot the new database starts empty"})
+	}
+	if !site.Started {
+		steps = append(steps, previewStep{name: "stop-site", desc: "stop the site again"})
+	}
+	return dryPlan(ctx, name, steps)
+}
+
+// PreviewLAN describes what EnableLAN (on) or DisableLAN would do.
+func (sm *SiteManager) PreviewLAN(ctx context.Context, siteID string, on bool) (orch.DryResult, error) {
+	site, err := sm.lanTarget(siteID)
+	if err != nil {
+		return orch.DryResult{}, err
+	}
+	name, state := "enable-lan:"+site.Slug, "on"
+	preEvent, postEvent := hooks.PreLanEnable, hooks.PostLanEnable
+	detect, route := "detect the host's LAN IPv4 address", "with its LAN hostname"
+	if !on {
+		name, state = "disable-lan:"+site.Slug, "off"
+		preEvent, postEvent = hooks.PreLanDisable, hooks.PostLanDisable
+		detect, route = "forget the cached LAN IPv4 address", "without a LAN hostname"
+	}
+
+	var steps []orch.Step
+	steps = append(steps, sm.hookSteps(site, preEvent)...)
+	steps = append(steps,
+		previewStep{name: "detect-lan-ip", desc: detect},
+		previewStep{name: "apply-lan-state", desc: "set LAN access " + state + " and regenerate wp-config-locorum.php"},
+		previewStep{name: "upsert-route", desc: "re-issue the router route for " + site.Domain + " " + route},
+	)
+	steps = append(steps, sm.hookSteps(site, postEvent)...)
+	return dryPlan(ctx, name, steps)
+}
+
+// PreviewSPX describes what SetSPXEnabled would do. A toggle that
+// changes nothing previews as an empty plan.
+func (sm *SiteManager) PreviewSPX(ctx context.Context, siteID string, enabled bool) (orch.DryResult, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return orch.DryResult{}, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return orch.DryResult{}, fmt.Errorf("site %q not found", siteID)
+	}
+	if site.Started {
+		return orch.DryResult{}, errSPXSiteRunning
+	}
+
+	var steps []orch.Step
+	switch {
+	case site.SPXEnabled == enabled && (!enabled || site.SPXKey != ""):
+	case enabled && site.SPXKey == "":
+		steps = append(steps, previewStep{name: "update-site", desc: "enable SPX profiling and generate an SPX_KEY"})
+	case enabled:
+		steps = append(steps, previewStep{name: "update-site", desc: "enable SPX profiling with the existing SPX_KEY"})
+	default:
+		steps = append(steps, previewStep{name: "update-site", desc: "disable SPX profiling; the SPX_KEY is kept"})
+	}
+	return dryPlan(ctx, "spx:"+site.Slug, steps)
+}
+
+// PreviewCreateSite describes what CreateSite would store for site,
+// with the same defaults and conflict checks.
+func (sm *SiteManager) PreviewCreateSite(ctx context.Context, site types.Site) (orch.DryResult, error) {
+	if err := sm.defaultNewSite(&site); err != nil {
+		return orch.DryResult{}, err
+	}
+	if err := sm.prepareNewSite(&site); err != nil {
+		return orch.DryResult{}, err
+	}
+
+	dir := "create " + site.FilesDir
+	if _, err := os.Stat(site.FilesDir); err == nil {
+		dir = "use the existing directory " + site.FilesDir
+	}
+	row := fmt.Sprintf("add site %q at https://%s: PHP %s, %s %s, %s %s cache, %s",
+		site.Name, site.Domain, site.PHPVersion, site.DBEngine, site.DBVersion, site.CacheBackend, site.CacheVersion, site.WebServer)
+	if site.Multisite != "" {
+		row += ", " + site.Multisite + " multisite"
+	}
+	return dryPlan(ctx, "add-site:"+site.Slug, []orch.Step{
+		previewStep{name: "ensure-files-dir", desc: dir},
+		previewStep{name: "insert-site", desc: row},
+		previewStep{name: "write-config-yaml", desc: "write " + filepath.Join(site.FilesDir, configyaml.Filename)},
+	})
+}
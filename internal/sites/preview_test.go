@@ -0,0 +1,147 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// stepNames lists a dry-run's step names in order.
+func stepNames(dr orch.DryResult) []string {
+	out := make([]string, len(dr.Steps))
+	for i, s := range dr.Steps {
+		out[i] = s.Name
+	}
+	return out
+}
+
+func TestPreviewVersionsChange(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	ctx := context.Background()
+
+	dr, err := sm.PreviewVersionsChange(ctx, site.ID, VersionsChange{PHPVersion: "8.4"})
+	if err != nil {
+		t.Fatalf("PreviewVersionsChange: %v", err)
+	}
+	if got := strings.Join(stepNames(dr), " "); got != "remove-containers update-site" {
+		t.Fatalf("steps = %q", got)
+	}
+	if d := dr.Steps[1].Description; !strings.HasPrefix(d, "PHP 8.3 → 8.4") {
+		t.Errorf("update-site = %q", d)
+	}
+	if got, _ := sm.st.GetSite(site.ID); got.PHPVersion != "8.3" {
+		t.Errorf("preview changed the row: PHP %s", got.PHPVersion)
+	}
+
+	if dr, err := sm.PreviewVersionsChange(ctx, site.ID, VersionsChange{PHPVersion: "8.3"}); err != nil || len(dr.Steps) != 0 {
+		t.Errorf("no-op change: %d steps, %v", len(dr.Steps), err)
+	}
+	if _, err := sm.PreviewVersionsChange(ctx, site.ID, VersionsChange{DBEngine: "mariadb"}); !errors.Is(err, ErrUnsafeVersionTransition) {
+		t.Errorf("engine swap: got %v, want ErrUnsafeVersionTransition", err)
+	}
+}
+
+func TestPreviewImportDB(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	ctx := context.Background()
+	dump := filepath.Join(t.TempDir(), "prod.sql")
+	if err := os.WriteFile(dump, []byte("-- MySQL dump\nINSERT INTO wp_options VALUES (1);\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.PreviewImportDB(ctx, site.ID, dump, ImportDBOptions{}); !errors.Is(err, ErrSiteNotRunning) {
+		t.Fatalf("stopped site: got %v, want ErrSiteNotRunning", err)
+	}
+	site.Started = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := ImportDBOptions{SearchReplace: []SearchReplacePair{{From: "https://cdn.example", To: "https://lansite.localhost"}}}
+	dr, err := sm.PreviewImportDB(ctx, site.ID, dump, opts)
+	if err != nil {
+		t.Fatalf("PreviewImportDB: %v", err)
+	}
+	want := "snapshot prepare-dump wp-db-import auto-search-replace cleanup-dump"
+	if got := strings.Join(stepNames(dr), " "); got != want {
+		t.Errorf("steps = %q, want %q", got, want)
+	}
+	if d := dr.Steps[3].Description; !strings.Contains(d, "https://lansite.localhost") || !strings.Contains(d, "https://cdn.example") {
+		t.Errorf("auto-search-replace = %q", d)
+	}
+
+	bogus := filepath.Join(t.TempDir(), "photo.bin")
+	if err := os.WriteFile(bogus, []byte{0x89, 'P', 'N', 'G'}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.PreviewImportDB(ctx, site.ID, bogus, ImportDBOptions{}); err == nil {
+		t.Error("non-SQL file previewed without error")
+	}
+}
+
+func TestPreviewLAN_ListsHooks(t *testing.T) {
+	sm, rtr, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	if err := sm.st.AddHook(&hooks.Hook{SiteID: site.ID, Event: hooks.PreLanEnable, TaskType: hooks.TaskExecHost, Command: "echo lan", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	dr, err := sm.PreviewLAN(context.Background(), site.ID, true)
+	if err != nil {
+		t.Fatalf("PreviewLAN: %v", err)
+	}
+	if dr.PlanName != "enable-lan:lansite" {
+		t.Errorf("plan = %q", dr.PlanName)
+	}
+	want := "hooks:pre-lan-enable detect-lan-ip apply-lan-state upsert-route"
+	if got := strings.Join(stepNames(dr), " "); got != want {
+		t.Errorf("steps = %q, want %q", got, want)
+	}
+	if !strings.Contains(dr.Steps[0].Description, `"echo lan"`) {
+		t.Errorf("hook step = %q", dr.Steps[0].Description)
+	}
+	if got, _ := sm.st.GetSite(site.ID); got.LanEnabled || len(rtr.Calls()) != 0 {
+		t.Errorf("preview applied: lanEnabled=%v router calls=%v", got.LanEnabled, rtr.Calls())
+	}
+}
+
+func TestCreateSite(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	sm.homeDir = t.TempDir()
+	ctx := context.Background()
+
+	dr, err := sm.PreviewCreateSite(ctx, types.Site{Name: "My Shop"})
+	if err != nil {
+		t.Fatalf("PreviewCreateSite: %v", err)
+	}
+	dir := filepath.Join(sm.homeDir, "locorum", "sites", "my-shop")
+	if dr.PlanName != "add-site:my-shop" || dr.Steps[0].Description != "create "+dir {
+		t.Errorf("preview = %s", dr.Format())
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("preview created %s", dir)
+	}
+
+	site, err := sm.CreateSite(types.Site{Name: "My Shop"})
+	if err != nil {
+		t.Fatalf("CreateSite: %v", err)
+	}
+	if site.Slug != "my-shop" || site.FilesDir != dir || site.DBEngine == "" || site.DBVersion == "" {
+		t.Errorf("created %+v", site)
+	}
+	if _, err := sm.CreateSite(types.Site{Name: "My Shop"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("duplicate name: got %v", err)
+	}
+	if _, err := sm.CreateSite(types.Site{Name: "!!!"}); err == nil {
+		t.Error("name without a slug accepted")
+	}
+}
@@ -0,0 +1,327 @@
+package sites
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// PullOptions controls Pull. The URL-rewrite and snapshot fields mean
+// the same as on ImportDBOptions.
+type PullOptions struct {
+	// Remote is the name of the site's remote to pull from.
+	Remote string
+
+	// SkipDB / SkipUploads pull only one half.
+	SkipDB      bool
+	SkipUploads bool
+
+	SearchReplace []SearchReplacePair
+	DisableAuto   bool
+	SkipSnapshot  bool
+}
+
+// PullResult summarises a finished pull.
+type PullResult struct {
+	Remote        string `json:"remote"`
+	Database      bool   `json:"database"`
+	UploadFiles   int    `json:"uploadFiles"`
+	UploadBytes   int64  `json:"uploadBytes"`
+	HostKey       string `json:"hostKey"`
+	HostKeyPinned bool   `json:"hostKeyPinned"`
+}
+
+// Pull copies the database and wp-content/uploads from one of the
+// site's SSH remotes. The dump is streamed straight into the ImportDB
+// pipeline (snapshot, filter, import, auto search-replace), so the
+// remote's URLs are rewritten to the local domain the same way a file
+// import would be. Uploads arrive as a tar stream and overwrite local
+// files of the same name; nothing local is deleted.
+//
+// The site must be running and its mutex is held throughout. pre-pull
+// and post-pull hooks bracket the whole flow; the import-db hooks also
+// fire around the database half.
+func (sm *SiteManager) Pull(ctx context.Context, siteID string, opts PullOptions) (*PullResult, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	if !site.Started {
+		return nil, fmt.Errorf("%w: cannot pull", ErrSiteNotRunning)
+	}
+	if opts.SkipDB && opts.SkipUploads {
+		return nil, errors.New("nothing to pull: both database and uploads skipped")
+	}
+	for _, p := range opts.SearchReplace {
+		if p.From == "" || p.To == "" {
+			return nil, errors.New("search-replace pairs require both From and To")
+		}
+	}
+	r, err := sm.st.GetRemote(siteID, opts.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("remote %q: %w", opts.Remote, err)
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := sm.runHooks(ctx, hooks.PrePull, site); err != nil {
+		return nil, err
+	}
+
+	client, err := remote.Dial(ctx, *r, remote.DialOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	res := &PullResult{Remote: r.Name, HostKey: client.HostKey}
+	if r.HostKey == "" {
+		r.HostKey = client.HostKey
+		if err := sm.st.UpdateRemote(r); err != nil {
+			return nil, fmt.Errorf("pinning host key: %w", err)
+		}
+		res.HostKeyPinned = true
+		slog.Info("pull: pinned remote host key", "remote", r.String(), "fingerprint", client.HostKey)
+	}
+
+	if !opts.SkipDB {
+		prepare := func(dst string) error {
+			return pullDump(ctx, client, r.DumpCommand(), dst)
+		}
+		importOpts := ImportDBOptions{
+			SearchReplace: opts.SearchReplace,
+			DisableAuto:   opts.DisableAuto,
+			SkipSnapshot:  opts.SkipSnapshot,
+		}
+		if err := sm.importDBLocked(ctx, site, prepare, importOpts); err != nil {
+			return res, fmt.Errorf("pulling database: %w", err)
+		}
+		res.Database = true
+	}
+
+	if !opts.SkipUploads {
+		stream, err := client.Stream(ctx, r.UploadsCommand())
+		if err != nil {
+			return res, fmt.Errorf("pulling uploads: %w", err)
+		}
+		dest := filepath.Join(wpDocrootDir(site), "wp-content")
+		files, n, extractErr := extractUploads(stream, dest)
+		// A failed remote tar usually surfaces first as a truncated
+		// gzip stream; the command's own stderr says why.
+		if err := stream.Close(); err != nil {
+			return res, fmt.Errorf("pulling uploads: %w", err)
+		}
+		if extractErr != nil {
+			return res, fmt.Errorf("pulling uploads: %w", extractErr)
+		}
+		res.UploadFiles, res.UploadBytes = files, n
+		slog.Info("pull: uploads extracted", "files", files, "bytes", n, "dest", dest)
+	}
+
+	if err := sm.runHooks(ctx, hooks.PostPull, site); err != nil {
+		return res, err
+	}
+	sm.emitSitesUpdate()
+	return res, nil
+}
+
+// pullDump runs the remote dump command and writes the filtered SQL to
+// dst. The command may emit plain or gzipped SQL.
+func pullDump(ctx context.Context, client *remote.Client, cmd, dst string) error {
+	stream, err := client.Stream(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	reader, err := sniffGzip(stream)
+	writeErr := err
+	if err == nil {
+		writeErr = writeFilteredDump(reader, dst)
+	}
+	if err := stream.Close(); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	return writeErr
+}
+
+// sniffGzip returns a decompressing reader when r starts with the gzip
+// magic bytes and r itself otherwise.
+func sniffGzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(head) == 2 && head[0] == 0x1f && head[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// extractUploads unpacks a gzipped tar rooted at "uploads/" into
+// wpContentDir. Entries outside uploads/ or escaping the directory are
+// refused; links and device nodes are skipped, since a remote uploads
+// tree is not ours to vouch for.
+func extractUploads(r io.Reader, wpContentDir string) (files int, size int64, err error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("gzip reader: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	uploadsDir := filepath.Join(wpContentDir, "uploads")
+	if err := os.MkdirAll(uploadsDir, 0o755); err != nil {
+		return 0, 0, fmt.Errorf("mkdir %q: %w", uploadsDir, err)
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, size, nil
+		}
+		if err != nil {
+			return files, size, fmt.Errorf("tar next: %w", err)
+		}
+
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if name != "uploads" && !strings.HasPrefix(name, "uploads/") {
+			return files, size, fmt.Errorf("tar entry %q is outside uploads/", hdr.Name)
+		}
+		target := filepath.Join(wpContentDir, filepath.FromSlash(name)) //nolint:gosec // G305: traversal guarded by the Clean+HasPrefix check below.
+		if filepath.Clean(target) != uploadsDir && !strings.HasPrefix(filepath.Clean(target)+string(os.PathSeparator),
+			uploadsDir+string(os.PathSeparator)) {
+			return files, size, fmt.Errorf("tar entry %q escapes destination", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return files, size, fmt.Errorf("mkdir %q: %w", target, err)
+			}
+		case tar.TypeReg:
+			if hdr.Size > wordpressMaxArchiveSize {
+				return files, size, fmt.Errorf("tar entry %q is %d bytes, over the %d byte limit",
+					hdr.Name, hdr.Size, wordpressMaxArchiveSize)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return files, size, fmt.Errorf("mkdir parent %q: %w", target, err)
+			}
+			if err := writeRegularEntry(tr, target, wordpressMaxArchiveSize); err != nil {
+				return files, size, err
+			}
+			files++
+			size += hdr.Size
+		default:
+			slog.Warn("pull: skipping non-regular uploads entry", "name", hdr.Name, "type", string(hdr.Typeflag))
+		}
+	}
+}
+
+// ListRemotes returns the site's SSH remotes with their passwords
+// blanked. Pull and Push read the password from storage themselves, so
+// it never needs to leave the manager.
+func (sm *SiteManager) ListRemotes(siteID string) ([]remote.Remote, error) {
+	rs, err := sm.st.ListRemotes(siteID)
+	for i := range rs {
+		rs[i].Password = ""
+	}
+	return rs, err
+}
+
+// AddRemote stores a new SSH remote for r.SiteID and registers its
+// password for redaction.
+func (sm *SiteManager) AddRemote(r *remote.Remote) error {
+	site, err := sm.st.GetSite(r.SiteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", r.SiteID)
+	}
+	if err := sm.st.AddRemote(r); err != nil {
+		return err
+	}
+	if r.Password != "" {
+		secrets.Add(r.Password)
+	}
+	return nil
+}
+
+// RemoveRemote deletes the site's remote called name.
+func (sm *SiteManager) RemoveRemote(siteID, name string) error {
+	r, err := sm.st.GetRemote(siteID, name)
+	if err != nil {
+		return fmt.Errorf("remote %q: %w", name, err)
+	}
+	if err := sm.st.DeleteRemote(siteID, name); err != nil {
+		return err
+	}
+	sm.forgetRemotePassword(r.Password, "")
+	return nil
+}
+
+// forgetRemotePassword drops password from the redaction registry
+// unless another remote or a site still uses it, in which case it must
+// stay redacted. Rows of skipSite are ignored, for a site whose rows are
+// about to be deleted.
+func (sm *SiteManager) forgetRemotePassword(password, skipSite string) {
+	if password == "" {
+		return
+	}
+	remotes, err := sm.st.ListAllRemotes()
+	if err != nil {
+		slog.Warn("listing remotes to forget a password", "err", err.Error())
+		return
+	}
+	for _, r := range remotes {
+		if r.SiteID != skipSite && r.Password == password {
+			return
+		}
+	}
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		slog.Warn("listing sites to forget a password", "err", err.Error())
+		return
+	}
+	for _, site := range rows {
+		if site.ID == skipSite {
+			continue
+		}
+		if site.DBPassword == password || site.MailRelayPassword == password || site.AuthPassword == password {
+			return
+		}
+	}
+	secrets.Remove(password)
+}
+
+// forgetRemoteSecrets drops every remote password of site from the
+// redaction registry. Called on delete, before the rows cascade away.
+func (sm *SiteManager) forgetRemoteSecrets(site *types.Site) {
+	rs, err := sm.st.ListRemotes(site.ID)
+	if err != nil {
+		slog.Warn("listing remotes on delete", "err", err.Error())
+		return
+	}
+	for _, r := range rs {
+		sm.forgetRemotePassword(r.Password, site.ID)
+	}
+}
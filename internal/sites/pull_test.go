@@ -0,0 +1,133 @@
+package sites
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/secrets"
+)
+
+// uploadsTarGz opens a test archive built by writeTestArchive, standing
+// in for the remote tar stream.
+func uploadsTarGz(t *testing.T, entries []tarEntry) io.Reader {
+	t.Helper()
+	f, err := os.Open(writeTestArchive(t, entries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func TestExtractUploads(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "uploads", "keep.txt")
+	if err := os.MkdirAll(filepath.Dir(existing), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("local"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := uploadsTarGz(t, []tarEntry{
+		{name: "uploads/"},
+		{name: "uploads/2026/05/"},
+		{name: "uploads/2026/05/photo.jpg", body: "jpeg"},
+		{name: "uploads/link", typeflag: tar.TypeSymlink},
+	})
+	files, n, err := extractUploads(buf, dir)
+	if err != nil {
+		t.Fatalf("extractUploads: %v", err)
+	}
+	if files != 1 || n != 4 {
+		t.Errorf("files, bytes = %d, %d; want 1, 4", files, n)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "uploads", "2026", "05", "photo.jpg")); string(got) != "jpeg" {
+		t.Errorf("photo.jpg = %q", got)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "uploads", "link")); !os.IsNotExist(err) {
+		t.Errorf("symlink entry was extracted: %v", err)
+	}
+	if got, _ := os.ReadFile(existing); string(got) != "local" {
+		t.Errorf("unrelated local upload changed: %q", got)
+	}
+}
+
+func TestExtractUploads_RejectsEscapes(t *testing.T) {
+	for _, name := range []string{"wp-config.php", "uploads/../../evil.php", "../uploads/x"} {
+		t.Run(name, func(t *testing.T) {
+			buf := uploadsTarGz(t, []tarEntry{{name: name, body: "x"}})
+			if _, _, err := extractUploads(buf, t.TempDir()); err == nil {
+				t.Errorf("entry %q extracted, want error", name)
+			}
+		})
+	}
+}
+
+func TestSniffGzip(t *testing.T) {
+	const sql = "-- MySQL dump\nCREATE TABLE t (id int);\n"
+
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	_, _ = gz.Write([]byte(sql))
+	_ = gz.Close()
+
+	for name, in := range map[string]io.Reader{
+		"plain": strings.NewReader(sql),
+		"gzip":  &gzBuf,
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := sniffGzip(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || string(got) != sql {
+				t.Errorf("read %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestRemoveRemote_KeepsSharedPasswordRedacted(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	const pw = "shared-remote-secret-1"
+	for _, name := range []string{"staging", "live"} {
+		r := &remote.Remote{SiteID: site.ID, Name: name, Host: name + ".example.com", User: "deploy", Path: "/srv/www", Password: pw}
+		if err := sm.AddRemote(r); err != nil {
+			t.Fatalf("AddRemote %s: %v", name, err)
+		}
+	}
+	redacted := func() bool { return !strings.Contains(secrets.RedactString("pw="+pw), pw) }
+
+	list, err := sm.ListRemotes(site.ID)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListRemotes = %v, %v", list, err)
+	}
+	for _, r := range list {
+		if r.Password != "" {
+			t.Errorf("ListRemotes returned the password of %s", r.Name)
+		}
+	}
+
+	if err := sm.RemoveRemote(site.ID, "staging"); err != nil {
+		t.Fatal(err)
+	}
+	if !redacted() {
+		t.Error("password un-redacted while live still uses it")
+	}
+	if err := sm.RemoveRemote(site.ID, "live"); err != nil {
+		t.Fatal(err)
+	}
+	if redacted() {
+		t.Error("password still redacted after its last remote was removed")
+	}
+}
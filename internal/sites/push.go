@@ -0,0 +1,301 @@
+package sites
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// ErrPushToProduction is returned when the target remote is marked
+// production. There is no override: unmark the remote first.
+var ErrPushToProduction = errors.New("refusing to push to a production remote")
+
+// ErrPushNotConfirmed is returned when PushOptions.ConfirmHost does not
+// name the remote's host.
+var ErrPushNotConfirmed = errors.New("push not confirmed")
+
+// ErrHostKeyNotPinned is returned when the remote has no pinned host key
+// and PushOptions.AcceptHostKey does not name the key it presented. A
+// push never trusts a server on first contact.
+var ErrHostKeyNotPinned = errors.New("remote host key not pinned")
+
+// PushOptions controls Push.
+type PushOptions struct {
+	// Remote is the name of the site's remote to push to.
+	Remote string
+
+	// ConfirmHost must equal the remote's Host (case-insensitive). The
+	// CLI and GUI make the user type or click through the hostname so a
+	// push never lands on the wrong server by accident.
+	ConfirmHost string
+
+	// AcceptHostKey pins the remote's host key when none is pinned yet.
+	// It must equal the fingerprint the server presents; without it an
+	// unpinned remote is refused, so the key is pinned by a pull or by
+	// the user checking the fingerprint, never silently by a push.
+	AcceptHostKey string
+
+	// SearchReplace pairs run on the remote after the import, after the
+	// automatic local → remote URL pairs.
+	SearchReplace []SearchReplacePair
+
+	// DisableAuto skips the automatic local → remote URL pairs.
+	DisableAuto bool
+}
+
+// pushRemote is the part of *remote.Client Push drives.
+type pushRemote interface {
+	Run(ctx context.Context, cmd string) (string, error)
+	RunStdin(ctx context.Context, cmd string, stdin io.Reader) (string, error)
+	Close() error
+}
+
+// pushDialFunc opens a pushRemote to r and returns it with the host key
+// fingerprint the server presented.
+type pushDialFunc func(ctx context.Context, r remote.Remote) (pushRemote, string, error)
+
+// dialPushRemote is the production pushDialFunc.
+func dialPushRemote(ctx context.Context, r remote.Remote) (pushRemote, string, error) {
+	c, err := remote.Dial(ctx, r, remote.DialOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	return c, c.HostKey, nil
+}
+
+// PushResult summarises a finished push.
+type PushResult struct {
+	Remote        string              `json:"remote"`
+	Host          string              `json:"host"`
+	BackupPath    string              `json:"backupPath"`
+	Bytes         int64               `json:"bytes"`
+	SearchReplace []SearchReplacePair `json:"searchReplace"`
+	HostKey       string              `json:"hostKey"`
+	HostKeyPinned bool                `json:"hostKeyPinned"`
+}
+
+// Push replaces the remote's database with the local one. The dump comes
+// from dumpSQL (MySQL-format SQL even for SQLite sites), runs through the
+// import filters (DEFINER and collation clean-up), and is piped gzipped
+// into `wp db import` on the remote. URLs are then rewritten from the local domain
+// to the remote's home with `wp search-replace` on the remote, so the
+// local database is never modified.
+//
+// Safety rails, in order: production remotes are refused outright,
+// ConfirmHost must match, the host key must already be pinned or match
+// AcceptHostKey, the table prefixes must agree, and the remote database
+// is exported to remote.BackupDir before anything is imported.
+// Uploads are not pushed.
+//
+// The site must be running and its mutex is held throughout. pre-push
+// and post-push hooks bracket the flow.
+func (sm *SiteManager) Push(ctx context.Context, siteID string, opts PushOptions) (*PushResult, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	if !site.Started {
+		return nil, fmt.Errorf("%w: cannot push", ErrSiteNotRunning)
+	}
+	for _, p := range opts.SearchReplace {
+		if p.From == "" || p.To == "" {
+			return nil, errors.New("search-replace pairs require both From and To")
+		}
+	}
+	r, err := sm.st.GetRemote(siteID, opts.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("remote %q: %w", opts.Remote, err)
+	}
+	if r.Production {
+		return nil, fmt.Errorf("%w: %s", ErrPushToProduction, r.String())
+	}
+	if !strings.EqualFold(strings.TrimSpace(opts.ConfirmHost), r.Host) {
+		return nil, fmt.Errorf("%w: confirm by passing the remote host %q", ErrPushNotConfirmed, r.Host)
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := sm.runHooks(ctx, hooks.PrePush, site); err != nil {
+		return nil, err
+	}
+
+	dial := sm.pushDial
+	if dial == nil {
+		dial = dialPushRemote
+	}
+	client, hostKey, err := dial(ctx, *r)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	res := &PushResult{Remote: r.Name, Host: r.Host, HostKey: hostKey}
+	if r.HostKey == "" {
+		if accept := strings.TrimSpace(opts.AcceptHostKey); accept != hostKey {
+			if accept == "" {
+				return res, fmt.Errorf("%w: %s presented %s; pull from it first or accept that key explicitly", ErrHostKeyNotPinned, r.String(), hostKey)
+			}
+			return res, fmt.Errorf("%w: %s presented %s, not the accepted %s", ErrHostKeyNotPinned, r.String(), hostKey, accept)
+		}
+		r.HostKey = hostKey
+		if err := sm.st.UpdateRemote(r); err != nil {
+			return nil, fmt.Errorf("pinning host key: %w", err)
+		}
+		res.HostKeyPinned = true
+		slog.Info("push: pinned remote host key", "remote", r.String(), "fingerprint", hostKey)
+	}
+
+	// Both checks run before the backup so a misconfigured remote is
+	// rejected without touching it.
+	localPrefix, err := sm.wpcli(ctx, site, "config", "get", "table_prefix")
+	if err != nil {
+		return res, fmt.Errorf("reading local table prefix: %w", err)
+	}
+	remotePrefix, err := client.Run(ctx, r.TablePrefixCommand())
+	if err != nil {
+		return res, fmt.Errorf("reading remote table prefix: %w", err)
+	}
+	if lp, rp := strings.TrimSpace(localPrefix), strings.TrimSpace(remotePrefix); lp != rp {
+		return res, fmt.Errorf("table prefix mismatch: local %q, remote %q", lp, rp)
+	}
+	remoteHome, err := client.Run(ctx, r.OptionCommand("home"))
+	if err != nil {
+		return res, fmt.Errorf("reading remote home URL: %w", err)
+	}
+	remoteHome = strings.TrimSpace(remoteHome)
+	if remoteHome == "" {
+		return res, errors.New("remote home URL is empty — is WordPress installed at the remote path?")
+	}
+
+	backupCmd, backupPath := r.BackupCommand(site.Slug + "-" + time.Now().UTC().Format("20060102-150405"))
+	if _, err := client.Run(ctx, backupCmd); err != nil {
+		return res, fmt.Errorf("remote backup: %w", err)
+	}
+	res.BackupPath = backupPath
+	slog.Info("push: remote database backed up", "remote", r.String(), "file", backupPath)
+
+	n, err := sm.pushDump(ctx, site, client, r.ImportCommand())
+	if err != nil {
+		return res, fmt.Errorf("pushing database (remote backup at %s): %w", backupPath, err)
+	}
+	res.Bytes = n
+
+	var localURLs []string
+	if !opts.DisableAuto {
+		localURLs = sm.localSiteURLs(ctx, site)
+	}
+	for _, p := range pushSearchReplacePairs(localURLs, remoteHome, opts.SearchReplace) {
+		if _, err := client.Run(ctx, r.SearchReplaceCommand(p.From, p.To)); err != nil {
+			return res, fmt.Errorf("remote search-replace %s → %s: %w", p.From, p.To, err)
+		}
+		res.SearchReplace = append(res.SearchReplace, p)
+		slog.Info("push: search-replace applied", "from", p.From, "to", p.To)
+	}
+
+	if err := sm.runHooks(ctx, hooks.PostPush, site); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// pushDump streams the site's SQL dump through the import filters and
+// gzip into cmd's stdin. Returns the uncompressed SQL byte count.
+func (sm *SiteManager) pushDump(ctx context.Context, site *types.Site, client pushRemote, cmd string) (int64, error) {
+	pr, pw := io.Pipe()
+
+	type result struct {
+		n   int64
+		err error
+	}
+	dumped := make(chan result, 1)
+	go func() {
+		sqlR, sqlW := io.Pipe()
+		filtered := make(chan result, 1)
+		gz := gzip.NewWriter(pw)
+		go func() {
+			n, err := FilterImportStream(sqlR, gz)
+			_ = sqlR.CloseWithError(err)
+			filtered <- result{n, err}
+		}()
+		_, snapErr := sm.dumpSQL(ctx, site, sqlW)
+		_ = sqlW.CloseWithError(snapErr)
+		f := <-filtered
+		err := snapErr
+		if err == nil {
+			err = f.err
+		}
+		if err == nil {
+			err = gz.Close()
+		}
+		_ = pw.CloseWithError(err)
+		dumped <- result{f.n, err}
+	}()
+
+	_, runErr := client.RunStdin(ctx, cmd, pr)
+	// Unblock the dump if the remote gave up before reading everything.
+	_ = pr.CloseWithError(errors.New("remote import exited"))
+	d := <-dumped
+	if d.err != nil {
+		return d.n, fmt.Errorf("local dump: %w", d.err)
+	}
+	if runErr != nil {
+		return d.n, fmt.Errorf("remote import: %w", runErr)
+	}
+	return d.n, nil
+}
+
+// localSiteURLs returns the local home and siteurl, falling back to the
+// site's https URL when wp-cli can't read them.
+func (sm *SiteManager) localSiteURLs(ctx context.Context, site *types.Site) []string {
+	var urls []string
+	for _, key := range []string{"home", "siteurl"} {
+		v, err := sm.wpOptionGet(ctx, site, key)
+		if err != nil {
+			slog.Warn("push: reading local URL", "key", key, "err", err.Error())
+			continue
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			urls = append(urls, v)
+		}
+	}
+	if len(urls) == 0 {
+		urls = append(urls, "https://"+site.Domain)
+	}
+	return urls
+}
+
+// pushSearchReplacePairs is the inverse of autoSearchReplacePairs: every
+// local URL, plus its scheme-flipped variant, maps to remoteHome. User
+// pairs follow; duplicates and no-op pairs are dropped.
+func pushSearchReplacePairs(localURLs []string, remoteHome string, user []SearchReplacePair) []SearchReplacePair {
+	pairs := make([]SearchReplacePair, 0, 2*len(localURLs)+len(user))
+	for _, u := range localURLs {
+		pairs = append(pairs, SearchReplacePair{From: u, To: remoteHome})
+		if flipped := flipScheme(u); flipped != "" {
+			pairs = append(pairs, SearchReplacePair{From: flipped, To: remoteHome})
+		}
+	}
+	pairs = append(pairs, user...)
+
+	out := pairs[:0]
+	for _, p := range dedupePairs(pairs) {
+		if p.From != p.To {
+			out = append(out, p)
+		}
+	}
+	return out
+}
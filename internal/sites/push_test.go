@@ -0,0 +1,290 @@
+package sites
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func TestPushSearchReplacePairs(t *testing.T) {
+	got := pushSearchReplacePairs(
+		[]string{"https://shop.localhost", "https://shop.localhost"},
+		"https://staging.example.com",
+		[]SearchReplacePair{
+			{"shop.localhost", "staging.example.com"},
+			{"same", "same"},
+		},
+	)
+	want := []SearchReplacePair{
+		{"https://shop.localhost", "https://staging.example.com"},
+		{"http://shop.localhost", "https://staging.example.com"},
+		{"shop.localhost", "staging.example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pairs = %v, want %v", got, want)
+	}
+
+	if got := pushSearchReplacePairs(nil, "https://staging.example.com", nil); len(got) != 0 {
+		t.Errorf("no local URLs and no user pairs = %v, want none", got)
+	}
+}
+
+// fakePushRemote answers Push's remote commands from a table keyed by
+// command prefix and records every command in order. RunStdin commands
+// are recorded with their decompressed stdin.
+type fakePushRemote struct {
+	mu      sync.Mutex
+	replies map[string]fakePushReply
+	cmds    []string
+	stdin   string
+	closed  bool
+}
+
+type fakePushReply struct {
+	out string
+	err error
+}
+
+func (f *fakePushRemote) reply(cmd string) (string, error) {
+	for prefix, r := range f.replies {
+		if strings.HasPrefix(cmd, prefix) {
+			return r.out, r.err
+		}
+	}
+	return "", nil
+}
+
+func (f *fakePushRemote) Run(_ context.Context, cmd string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cmds = append(f.cmds, cmd)
+	return f.reply(cmd)
+}
+
+func (f *fakePushRemote) RunStdin(_ context.Context, cmd string, stdin io.Reader) (string, error) {
+	gz, err := gzip.NewReader(stdin)
+	if err != nil {
+		return "", err
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cmds = append(f.cmds, cmd)
+	f.stdin = string(body)
+	return f.reply(cmd)
+}
+
+func (f *fakePushRemote) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	return nil
+}
+
+// index returns the position of the first recorded command starting
+// with prefix, or -1.
+func (f *fakePushRemote) index(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, c := range f.cmds {
+		if strings.HasPrefix(c, prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// newPushSiteManager returns a SiteManager with a started site, a
+// staging remote pinned to the fake's host key, and fakes in place of SSH, the PHP container and the
+// database dump. Every dial is counted in *dials.
+func newPushSiteManager(t *testing.T, fr *fakePushRemote) (*SiteManager, *types.Site, *remote.Remote, *int) {
+	t.Helper()
+	sm, _, _ := newLanSiteManager(t)
+	site := newLanSite(t, sm)
+	site.Started = true
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		t.Fatal(err)
+	}
+	r := &remote.Remote{
+		SiteID: site.ID, Name: "staging", Host: "staging.example.com",
+		User: "deploy", Path: "/srv/www", HostKey: "SHA256:fake",
+	}
+	if err := sm.st.AddRemote(r); err != nil {
+		t.Fatalf("AddRemote: %v", err)
+	}
+
+	dials := new(int)
+	sm.pushDial = func(context.Context, remote.Remote) (pushRemote, string, error) {
+		*dials++
+		return fr, "SHA256:fake", nil
+	}
+	sm.wpExec = func(_ context.Context, _ string, cmd []string) (string, error) {
+		switch {
+		case reflect.DeepEqual(cmd[1:4], []string{"config", "get", "table_prefix"}):
+			return "wp_\n", nil
+		case cmd[1] == "option" && cmd[3] == "home":
+			return "https://lansite.localhost\n", nil
+		}
+		return "", errors.New("unexpected wp call")
+	}
+	sm.sqlDump = func(_ context.Context, _ *types.Site, w io.Writer) (int64, error) {
+		n, err := io.WriteString(w, "INSERT INTO wp_options VALUES (1);\n")
+		return int64(n), err
+	}
+	return sm, site, r, dials
+}
+
+func TestPush_RefusesProduction(t *testing.T) {
+	fr := &fakePushRemote{}
+	sm, site, r, dials := newPushSiteManager(t, fr)
+	r.Production = true
+	if err := sm.st.UpdateRemote(r); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := sm.Push(context.Background(), site.ID, PushOptions{Remote: "staging", ConfirmHost: r.Host})
+	if !errors.Is(err, ErrPushToProduction) {
+		t.Fatalf("err = %v, want ErrPushToProduction", err)
+	}
+	if *dials != 0 {
+		t.Errorf("dialled a production remote %d times", *dials)
+	}
+}
+
+func TestPush_ConfirmHostMismatch(t *testing.T) {
+	fr := &fakePushRemote{}
+	sm, site, _, dials := newPushSiteManager(t, fr)
+
+	for _, confirm := range []string{"", "prod.example.com", "staging"} {
+		_, err := sm.Push(context.Background(), site.ID, PushOptions{Remote: "staging", ConfirmHost: confirm})
+		if !errors.Is(err, ErrPushNotConfirmed) {
+			t.Errorf("ConfirmHost %q: err = %v, want ErrPushNotConfirmed", confirm, err)
+		}
+	}
+	if *dials != 0 {
+		t.Errorf("dialled an unconfirmed remote %d times", *dials)
+	}
+}
+
+func TestPush_TablePrefixMismatch(t *testing.T) {
+	r := remote.Remote{Path: "/srv/www"}
+	fr := &fakePushRemote{replies: map[string]fakePushReply{
+		r.TablePrefixCommand(): {out: "live_\n"},
+	}}
+	sm, site, _, _ := newPushSiteManager(t, fr)
+
+	_, err := sm.Push(context.Background(), site.ID, PushOptions{Remote: "staging", ConfirmHost: "staging.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "table prefix mismatch") {
+		t.Fatalf("err = %v, want table prefix mismatch", err)
+	}
+	if i := fr.index("mkdir -p " + remote.BackupDir); i >= 0 {
+		t.Error("remote backed up despite the prefix mismatch")
+	}
+	if i := fr.index(r.ImportCommand()); i >= 0 {
+		t.Error("remote imported despite the prefix mismatch")
+	}
+}
+
+func TestPush_BacksUpBeforeImport(t *testing.T) {
+	r := remote.Remote{Path: "/srv/www"}
+	fr := &fakePushRemote{replies: map[string]fakePushReply{
+		r.TablePrefixCommand():  {out: "wp_\n"},
+		r.OptionCommand("home"): {out: "https://staging.example.com\n"},
+	}}
+	sm, site, _, _ := newPushSiteManager(t, fr)
+
+	res, err := sm.Push(context.Background(), site.ID, PushOptions{Remote: "staging", ConfirmHost: "STAGING.example.com"})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	backup, imp, sr := fr.index("mkdir -p "+remote.BackupDir), fr.index(r.ImportCommand()), fr.index("wp search-replace")
+	if backup < 0 || imp < 0 || sr < 0 {
+		t.Fatalf("commands = %q, want backup, import and search-replace", fr.cmds)
+	}
+	if backup >= imp || imp >= sr {
+		t.Errorf("order: backup %d, import %d, search-replace %d; want backup < import < search-replace", backup, imp, sr)
+	}
+	if !strings.HasPrefix(res.BackupPath, remote.BackupDir+"/lansite-") {
+		t.Errorf("BackupPath = %q", res.BackupPath)
+	}
+	if !strings.Contains(fr.stdin, "INSERT INTO wp_options") {
+		t.Errorf("import stdin = %q, want the local dump", fr.stdin)
+	}
+	if res.HostKeyPinned || res.HostKey != "SHA256:fake" {
+		t.Errorf("host key = %q pinned %v, want SHA256:fake already pinned", res.HostKey, res.HostKeyPinned)
+	}
+	if !fr.closed {
+		t.Error("remote connection not closed")
+	}
+}
+
+func TestPush_FailedBackupStopsImport(t *testing.T) {
+	r := remote.Remote{Path: "/srv/www"}
+	fr := &fakePushRemote{replies: map[string]fakePushReply{
+		r.TablePrefixCommand():         {out: "wp_\n"},
+		r.OptionCommand("home"):        {out: "https://staging.example.com\n"},
+		"mkdir -p " + remote.BackupDir: {err: errors.New("disk full")},
+	}}
+	sm, site, _, _ := newPushSiteManager(t, fr)
+
+	_, err := sm.Push(context.Background(), site.ID, PushOptions{Remote: "staging", ConfirmHost: "staging.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "remote backup") {
+		t.Fatalf("err = %v, want remote backup failure", err)
+	}
+	if i := fr.index(r.ImportCommand()); i >= 0 {
+		t.Error("remote imported after the backup failed")
+	}
+}
+
+func TestPush_UnpinnedHostKey(t *testing.T) {
+	r := remote.Remote{Path: "/srv/www"}
+	fr := &fakePushRemote{replies: map[string]fakePushReply{
+		r.TablePrefixCommand():  {out: "wp_\n"},
+		r.OptionCommand("home"): {out: "https://staging.example.com\n"},
+	}}
+	sm, site, pinned, _ := newPushSiteManager(t, fr)
+	pinned.HostKey = ""
+	if err := sm.st.UpdateRemote(pinned); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := PushOptions{Remote: "staging", ConfirmHost: "staging.example.com"}
+	for _, accept := range []string{"", "SHA256:other"} {
+		opts.AcceptHostKey = accept
+		_, err := sm.Push(context.Background(), site.ID, opts)
+		if !errors.Is(err, ErrHostKeyNotPinned) {
+			t.Fatalf("AcceptHostKey %q: err = %v, want ErrHostKeyNotPinned", accept, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "SHA256:fake") {
+			t.Errorf("AcceptHostKey %q: err = %v, want the presented fingerprint", accept, err)
+		}
+	}
+	if len(fr.cmds) != 0 {
+		t.Fatalf("ran %q on an unpinned remote", fr.cmds)
+	}
+
+	opts.AcceptHostKey = "SHA256:fake"
+	res, err := sm.Push(context.Background(), site.ID, opts)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if !res.HostKeyPinned {
+		t.Error("accepted host key not reported as pinned")
+	}
+	got, err := sm.st.GetRemote(site.ID, "staging")
+	if err != nil || got.HostKey != "SHA256:fake" {
+		t.Errorf("stored host key = %v (err %v), want SHA256:fake", got, err)
+	}
+}
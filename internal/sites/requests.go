@@ -0,0 +1,72 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/reqlog"
+)
+
+// requestsRetryDelay is how long WatchRequests waits before reattaching
+// after the router container's log stream ends or cannot be opened.
+const requestsRetryDelay = 5 * time.Second
+
+// WatchRequests follows the router container's log and records each
+// site access entry for SiteRequests. The router is recreated on every
+// launch and on engine or port changes, so a closed stream or a missing
+// container just means "retry shortly". Returns when ctx is done.
+func (sm *SiteManager) WatchRequests(ctx context.Context, routerContainer string) {
+	var since time.Time
+	for {
+		ch, err := sm.d.StreamContainerLogs(ctx, routerContainer, since)
+		if err != nil {
+			if !errors.Is(err, docker.ErrNotFound) {
+				slog.Debug("request log: attach failed", "err", err.Error())
+			}
+		} else {
+			for line := range ch {
+				// Reattaching resumes at a whole second, so lines
+				// already recorded can be replayed.
+				if !line.Time.After(since) {
+					continue
+				}
+				since = line.Time
+				sm.recordRequestLine(line.Text)
+			}
+		}
+		if !sleepWithCtx(ctx, requestsRetryDelay) {
+			return
+		}
+	}
+}
+
+// recordRequestLine stores line if it is a site access-log entry.
+func (sm *SiteManager) recordRequestLine(line string) {
+	if slug, e, ok := reqlog.Parse(line); ok {
+		sm.requests.Add(slug, e)
+	}
+}
+
+// SiteRequests returns the site's recent requests matching f, newest
+// first. Only requests seen since Locorum started are kept.
+func (sm *SiteManager) SiteRequests(siteID string, f reqlog.Filter) ([]reqlog.Entry, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	return sm.requests.Query(site.Slug, f)
+}
+
+// ClearSiteRequests forgets the site's recorded requests. Used by the
+// Requests tab's Clear button and on delete, so a new site reusing the
+// slug starts empty.
+func (sm *SiteManager) ClearSiteRequests(slug string) {
+	sm.requests.Clear(slug)
+}
@@ -0,0 +1,41 @@
+package sites
+
+import (
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/reqlog"
+)
+
+func TestSiteRequestsFromRouterLog(t *testing.T) {
+	sm, _, _ := newLanSiteManager(t)
+	sm.requests = reqlog.NewStore(0)
+	site := newLanSite(t, sm)
+
+	for _, line := range []string{
+		`{"RouterName":"locorum-lansite@file","RequestMethod":"GET","RequestPath":"/","DownstreamStatus":200}`,
+		`{"RouterName":"locorum-lansite@file","RequestMethod":"GET","RequestPath":"/gone","DownstreamStatus":404}`,
+		`{"RouterName":"locorum-other@file","RequestMethod":"GET","RequestPath":"/","DownstreamStatus":500}`,
+		`time="2026-10-17T09:30:00Z" level=info msg="Configuration loaded"`,
+	} {
+		sm.recordRequestLine(line)
+	}
+
+	got, err := sm.SiteRequests(site.ID, reqlog.Filter{})
+	if err != nil {
+		t.Fatalf("SiteRequests: %v", err)
+	}
+	if len(got) != 2 || got[0].Path != "/gone" {
+		t.Fatalf("got %+v, want this site's two requests newest first", got)
+	}
+	if got, _ := sm.SiteRequests(site.ID, reqlog.Filter{Status: "4xx"}); len(got) != 1 {
+		t.Errorf("4xx filter: got %d entries", len(got))
+	}
+
+	sm.ClearSiteRequests(site.Slug)
+	if got, _ := sm.SiteRequests(site.ID, reqlog.Filter{}); len(got) != 0 {
+		t.Errorf("after clear: got %d entries", len(got))
+	}
+	if _, err := sm.SiteRequests("missing", reqlog.Filter{}); err == nil {
+		t.Error("unknown site: want error")
+	}
+}
@@ -0,0 +1,148 @@
+package sites
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/storage"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// oomActivityLookback bounds how many recent activity rows are searched
+// when deciding whether an OOM kill was already recorded by a previous
+// Locorum process.
+const oomActivityLookback = 50
+
+// SetResourceLimits replaces the site's per-role resource overrides.
+// Entries that override nothing are dropped; an empty map restores the
+// built-in defaults. Site must be stopped — the caps are part of every
+// container's spec hash, so the next start recreates what changed.
+//
+// Emits OnSiteUpdated on success so the GUI redraws.
+func (sm *SiteManager) SetResourceLimits(siteID string, limits types.ResourceLimits) error {
+	limits = docker.CompactResourceLimits(limits)
+	if err := docker.ValidateResourceLimits(limits); err != nil {
+		return err
+	}
+
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if site.Started {
+		return errors.New("site must be stopped to change resource limits")
+	}
+
+	site.Resources = limits
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+
+	sm.writeConfigYAML(site)
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	return nil
+}
+
+// OOMKilledServices inspects the containers of every running site and
+// returns slug → services whose last exit was an out-of-memory kill.
+// Each kill is recorded once as an activity row the first time it is
+// seen. Implements health.OOMKillLister; errors are logged and swallowed
+// for the same reason as Roots.
+func (sm *SiteManager) OOMKilledServices(ctx context.Context) map[string][]string {
+	if sm == nil || sm.st == nil || sm.d == nil {
+		return nil
+	}
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		slog.Debug("sites: oom scan: GetSites failed", "err", err.Error())
+		return nil
+	}
+	out := map[string][]string{}
+	for i := range rows {
+		site := &rows[i]
+		if !site.Started {
+			continue
+		}
+		for _, spec := range sm.serviceSpecs(site) {
+			st, err := sm.d.ContainerExit(ctx, spec.Name)
+			if err != nil {
+				slog.Debug("sites: oom scan: inspect failed", "container", spec.Name, "err", err.Error())
+				continue
+			}
+			if !st.OOMKilled {
+				continue
+			}
+			service := spec.Labels[docker.LabelRole]
+			out[site.Slug] = append(out[site.Slug], service)
+			sm.recordOOMKill(site, service, spec.Resources.MemoryLimit, st.FinishedAt)
+		}
+	}
+	for slug := range out {
+		sort.Strings(out[slug])
+	}
+	return out
+}
+
+// recordOOMKill appends an "oom" activity row for one kill. The same
+// kill is reported by every scan until the site restarts, so rows are
+// keyed on (service, FinishedAt): the in-memory set covers repeat scans
+// and the activity lookup covers a Locorum restart in between.
+func (sm *SiteManager) recordOOMKill(site *types.Site, service string, memoryLimit int64, at time.Time) {
+	plan := "oom:" + site.Slug + ":" + service
+	key := plan + "@" + at.UTC().Format(time.RFC3339Nano)
+	if _, seen := sm.oomSeen.LoadOrStore(key, true); seen {
+		return
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	at = at.UTC()
+
+	recent, err := sm.st.GetActivity(site.ID, oomActivityLookback)
+	if err == nil {
+		for _, ev := range recent {
+			if ev.Kind == storage.ActivityKindOOM && ev.Plan == plan && ev.Time.Equal(at) {
+				return
+			}
+		}
+	}
+
+	msg := service + " was killed for running out of memory"
+	if memoryLimit > 0 {
+		msg += fmt.Sprintf(" (limit %d MB)", memoryLimit>>20)
+	}
+	details, _ := json.Marshal(activityDetails{Error: msg})
+	ev := &storage.ActivityEvent{
+		SiteID:  site.ID,
+		Time:    at,
+		Plan:    plan,
+		Kind:    storage.ActivityKindOOM,
+		Status:  storage.ActivityStatusFailed,
+		Message: msg,
+		Details: details,
+	}
+	if err := sm.st.AppendActivity(ev); err != nil {
+		slog.Warn("activity append failed",
+			"plan", plan, "site", site.Slug, "err", err.Error())
+		return
+	}
+	if sm.OnActivityAppended != nil {
+		sm.OnActivityAppended(site.ID, *ev)
+	}
+}
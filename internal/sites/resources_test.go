@@ -0,0 +1,72 @@
+package sites
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/storage"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func TestSetResourceLimits(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := spxTestSite(t.TempDir())
+	if err := sm.st.AddSite(&site); err != nil {
+		t.Fatalf("AddSite: %v", err)
+	}
+
+	if err := sm.SetResourceLimits(site.ID, types.ResourceLimits{"php": {MemoryMB: 16}}); err == nil {
+		t.Error("expected a memory limit below the floor to be rejected")
+	}
+	if err := sm.SetResourceLimits(site.ID, types.ResourceLimits{
+		"php": {MemoryMB: 1024},
+		"web": {},
+	}); err != nil {
+		t.Fatalf("SetResourceLimits: %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if len(got.Resources) != 1 || got.Resources["php"].MemoryMB != 1024 {
+		t.Errorf("Resources = %+v, want only the php override", got.Resources)
+	}
+
+	got.Started = true
+	if _, err := sm.st.UpdateSite(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetResourceLimits(site.ID, nil); err == nil {
+		t.Error("expected running site to be rejected")
+	}
+}
+
+func TestRecordOOMKill_OncePerExit(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := spxTestSite(t.TempDir())
+	if err := sm.st.AddSite(&site); err != nil {
+		t.Fatalf("AddSite: %v", err)
+	}
+	at := time.Date(2026, 5, 15, 10, 0, 0, 123, time.UTC)
+
+	sm.recordOOMKill(&site, "php", 512<<20, at)
+	sm.recordOOMKill(&site, "php", 512<<20, at)
+	// A fresh SiteManager (Locorum restarted) must not re-record it.
+	(&SiteManager{st: sm.st}).recordOOMKill(&site, "php", 512<<20, at)
+
+	evs, err := sm.st.GetActivity(site.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 {
+		t.Fatalf("got %d activity rows, want 1: %+v", len(evs), evs)
+	}
+	if evs[0].Kind != storage.ActivityKindOOM || evs[0].Status != storage.ActivityStatusFailed {
+		t.Errorf("row = %+v", evs[0])
+	}
+	if evs[0].Message != "php was killed for running out of memory (limit 512 MB)" {
+		t.Errorf("message = %q", evs[0].Message)
+	}
+
+	sm.recordOOMKill(&site, "php", 512<<20, at.Add(time.Minute))
+	if evs, _ := sm.st.GetActivity(site.ID, 0); len(evs) != 2 {
+		t.Errorf("a later kill should add a row; got %d", len(evs))
+	}
+}
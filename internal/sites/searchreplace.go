@@ -0,0 +1,64 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// SearchReplace runs `wp search-replace` for each pair, in order, across
+// every table of a running site. wp-cli unserializes PHP-serialized
+// values before replacing and reserializes them after, so string
+// lengths inside serialized options and meta stay valid — unlike a raw
+// SQL REPLACE. Each pair goes through wpSearchReplace, which skips the
+// guid column and takes the pre_search_replace auto-snapshot.
+//
+// Returns wp-cli's per-table report for every pair applied so far, even
+// when a later pair fails.
+func (sm *SiteManager) SearchReplace(ctx context.Context, siteID string, pairs []SearchReplacePair) (string, error) {
+	site, err := sm.searchReplaceTarget(siteID, pairs)
+	if err != nil {
+		return "", err
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	var out strings.Builder
+	for _, p := range pairs {
+		report, err := sm.wpSearchReplace(ctx, site, p.From, p.To)
+		out.WriteString(report)
+		if err != nil {
+			return out.String(), fmt.Errorf("search-replace %s → %s: %w", p.From, p.To, err)
+		}
+		slog.Info("search-replace applied", "site", site.Slug, "from", p.From, "to", p.To)
+	}
+	return out.String(), nil
+}
+
+// searchReplaceTarget fetches siteID and checks SearchReplace's
+// arguments.
+func (sm *SiteManager) searchReplaceTarget(siteID string, pairs []SearchReplacePair) (*types.Site, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+	if !site.Started {
+		return nil, fmt.Errorf("%w: cannot run search-replace", ErrSiteNotRunning)
+	}
+	if len(pairs) == 0 {
+		return nil, errors.New("at least one search-replace pair is required")
+	}
+	if err := validatePairs(pairs); err != nil {
+		return nil, err
+	}
+	return site, nil
+}
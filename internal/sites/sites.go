@@ -10,12 +10,15 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"log/slog"
+	"maps"
 	"net"
 	"os"
 	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -27,14 +30,17 @@ import (
 	"github.com/gosimple/slug"
 	"github.com/sqweek/dialog"
 
+	"github.com/PeterBooker/locorum/internal/cachebackend"
 	"github.com/PeterBooker/locorum/internal/config"
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/genmark"
 	"github.com/PeterBooker/locorum/internal/git"
 	"github.com/PeterBooker/locorum/internal/hooks"
+	"github.com/PeterBooker/locorum/internal/mail"
 	"github.com/PeterBooker/locorum/internal/orch"
 	"github.com/PeterBooker/locorum/internal/platform"
+	"github.com/PeterBooker/locorum/internal/reqlog"
 	"github.com/PeterBooker/locorum/internal/router"
 	"github.com/PeterBooker/locorum/internal/secrets"
 	"github.com/PeterBooker/locorum/internal/sites/configyaml"
@@ -71,6 +77,11 @@ type SiteManager struct {
 	// in parallel; two lifecycle calls on the same site queue.
 	siteLocks sync.Map // map[string]*sync.Mutex
 
+	// createMu serialises site creation from checking the slug, domain
+	// and directory against the existing rows until the new row is
+	// stored, so two concurrent creates cannot both claim the same slug.
+	createMu sync.Mutex
+
 	// lanCache memoises the detected LAN IPv4 for ~5 minutes so
 	// routeFor (called on every UpsertSite) doesn't repeatedly hammer
 	// net.Interfaces. Cleared on InvalidateLanIP (called from the UI's
@@ -82,6 +93,56 @@ type SiteManager struct {
 	// utils.DetectLANIPv4. Tests inject a stub via SetLANDetector.
 	lanDetect func() (net.IP, error)
 
+	// routerNetCached memoises the router network's gateway and the
+	// Docker provider for the allowlist's always-allowed entries. See
+	// routerNetwork; routerNetDetect is the SetRouterNetworkDetector
+	// test seam.
+	routerNetMu     sync.Mutex
+	routerNetCached *RouterNetwork
+	routerNetUntil  time.Time
+	routerNetDetect func(context.Context) (RouterNetwork, error)
+
+	// configPending marks sites whose config.yaml carries edits the
+	// user has not yet applied or discarded. writeConfigYAML leaves
+	// those files alone so a start or setting change can't silently
+	// overwrite them.
+	configPending sync.Map // map[string]bool
+
+	// hostsPath overrides the system hosts file location. Test seam;
+	// production leaves it empty and SyncHostsFile uses hostsfile.Path.
+	hostsPath string
+
+	// oomSeen holds the OOM kills already recorded as activity rows,
+	// keyed by plan name + exit time. See recordOOMKill.
+	oomSeen sync.Map // map[string]bool
+
+	// requests holds recent router access-log entries per site, fed by
+	// WatchRequests.
+	requests *reqlog.Store
+
+	// mail, when non-nil, replaces the Mailpit client mailbox resolves
+	// from the global mail container. Test seam; see SetMailClient.
+	mail mail.Client
+
+	// relaySend, when non-nil, replaces smtp.SendMail for relay-mode
+	// delivery. Test seam.
+	relaySend relaySendFunc
+
+	// logStream, when non-nil, replaces Docker.ContainerLogStream for
+	// StreamSiteLogs. Test seam.
+	logStream logStreamFunc
+
+	// pushDial, when non-nil, replaces the SSH dial in Push. Test seam.
+	pushDial pushDialFunc
+
+	// wpExec, when non-nil, replaces Docker.ExecInContainer for wpcli.
+	// Test seam.
+	wpExec func(ctx context.Context, container string, cmd []string) (string, error)
+
+	// sqlDump, when non-nil, replaces the database dump behind dumpSQL.
+	// Test seam.
+	sqlDump func(ctx context.Context, site *types.Site, w io.Writer) (int64, error)
+
 	// Callbacks invoked when sites data changes. The UI layer sets these
 	// in ui.New() to trigger redraws.
 	OnSitesUpdated func(sites []types.Site)
@@ -109,6 +170,11 @@ type SiteManager struct {
 	// Activity tab update live without polling. Fired only on a
 	// successful insert; failed writes are logged and silently dropped.
 	OnActivityAppended func(siteID string, ev storage.ActivityEvent)
+
+	// OnConfigDrift fires when WatchConfigYAML finds unapplied edits in
+	// a site's config.yaml, and with a nil drift once they are applied,
+	// discarded or reverted by hand.
+	OnConfigDrift func(siteID string, drift *ConfigDrift)
 }
 
 func NewSiteManager(st *storage.Storage, cli *client.Client, d *docker.Docker, rtr router.Router, tls tlspkg.Provider, runner hooks.Runner, configFS embed.FS, homeDir string, cfg *config.Config) *SiteManager {
@@ -125,16 +191,17 @@ func NewSiteManager(st *storage.Storage, cli *client.Client, d *docker.Docker, r
 	)
 
 	return &SiteManager{
-		st:      st,
-		cli:     cli,
-		d:       d,
-		rtr:     rtr,
-		tls:     tls,
-		hooks:   runner,
-		config:  configFS,
-		homeDir: homeDir,
-		cfg:     cfg,
-		sites:   make(map[string]types.Site),
+		st:       st,
+		cli:      cli,
+		d:        d,
+		rtr:      rtr,
+		tls:      tls,
+		hooks:    runner,
+		config:   configFS,
+		homeDir:  homeDir,
+		cfg:      cfg,
+		sites:    make(map[string]types.Site),
+		requests: reqlog.NewStore(0),
 	}
 }
 
@@ -195,11 +262,16 @@ func (sm *SiteManager) Roots(_ context.Context) []string {
 //
 // Idempotent: WriteIfManaged short-circuits when the rendered bytes
 // match disk, and respects a user-stripped marker so manual edits are
-// preserved.
+// preserved. Sites with unapplied edits (configPending) are skipped
+// until ApplyConfigYAML or DiscardConfigYAML resolves them.
 func (sm *SiteManager) writeConfigYAML(site *types.Site) {
 	if site == nil || site.FilesDir == "" {
 		return
 	}
+	if _, pending := sm.configPending.Load(site.ID); pending {
+		slog.Info("config.yaml: unapplied edits, not regenerating", "site", site.Slug)
+		return
+	}
 	hookList, err := sm.st.ListHooks(site.ID)
 	if err != nil {
 		slog.Warn("config.yaml: list hooks", "site", site.Slug, "err", err.Error())
@@ -476,6 +548,54 @@ func (sm *SiteManager) checkPathBlocking(filesDir string) error {
 }
 
 func (sm *SiteManager) AddSite(site types.Site) error {
+	if err := sm.prepareNewSite(&site); err != nil {
+		return err
+	}
+	return sm.insertNewSite(&site)
+}
+
+// CreateSite is AddSite for scripted callers (daemon RPC, MCP). FilesDir
+// defaults to ~/locorum/sites/<slug> and empty runtime fields take the
+// saved new-site defaults, as for a config.yaml site. A slug, domain or
+// directory that is already taken is refused rather than inserted
+// twice, and the stored row is returned so the caller learns what the
+// defaults resolved to.
+func (sm *SiteManager) CreateSite(site types.Site) (*types.Site, error) {
+	sm.createMu.Lock()
+	defer sm.createMu.Unlock()
+	if err := sm.defaultNewSite(&site); err != nil {
+		return nil, err
+	}
+	if err := sm.prepareNewSite(&site); err != nil {
+		return nil, err
+	}
+	if err := sm.insertNewSite(&site); err != nil {
+		return nil, err
+	}
+	return &site, nil
+}
+
+// defaultNewSite fills CreateSite's defaults and checks the name
+// against the existing rows.
+func (sm *SiteManager) defaultNewSite(site *types.Site) error {
+	s := slug.Make(site.Name)
+	if s == "" {
+		return errors.New("site name is required and must contain letters or digits")
+	}
+	if site.FilesDir == "" {
+		site.FilesDir = filepath.Join(sm.homeDir, "locorum", "sites", s)
+	}
+	sm.fillConfigDefaults(site)
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		return fmt.Errorf("listing sites: %w", err)
+	}
+	return siteConflict(s, s+".localhost", filepath.Clean(site.FilesDir), rows)
+}
+
+// prepareNewSite validates site and resolves every default AddSite
+// applies, without touching disk or storage.
+func (sm *SiteManager) prepareNewSite(site *types.Site) error {
 	// Refuse paths that would breach Windows MAX_PATH on a host without
 	// LongPathsEnabled. The UI's debounced ValidateSitePath already
 	// disables the Create button in that case, but enforce here too so
@@ -514,13 +634,48 @@ func (sm *SiteManager) AddSite(site types.Site) error {
 			site.DBVersion = dbengine.MustFor(dbengine.Kind(site.DBEngine)).DefaultVersion()
 		}
 	}
+	if site.CacheBackend == "" {
+		site.CacheBackend = string(cachebackend.Default)
+	}
+	if !cachebackend.IsValid(cachebackend.Kind(site.CacheBackend)) {
+		return fmt.Errorf("unknown cache backend %q", site.CacheBackend)
+	}
+	if site.CacheVersion == "" {
+		if site.RedisVersion != "" && site.CacheBackend == string(cachebackend.Redis) { //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+			site.CacheVersion = site.RedisVersion //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+		} else {
+			site.CacheVersion = cachebackend.DefaultVersion(cachebackend.Kind(site.CacheBackend))
+		}
+	}
+	site.Resources = docker.CompactResourceLimits(site.Resources)
+	if err := docker.ValidateResourceLimits(site.Resources); err != nil {
+		return err
+	}
+	normalisePHPOverrides(site)
+	if err := ValidatePHPOverrides(site); err != nil {
+		return err
+	}
+	if site.Aliases = NormaliseAliases(site.Aliases); len(site.Aliases) > 0 {
+		all, err := sm.st.GetSites()
+		if err != nil {
+			return fmt.Errorf("listing sites: %w", err)
+		}
+		if err := ValidateAliases(site, all); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// insertNewSite creates the files directory and stores a site that
+// prepareNewSite accepted.
+func (sm *SiteManager) insertNewSite(site *types.Site) error {
 	if err := utils.EnsureDir(site.FilesDir); err != nil {
 		slog.Error("Failed to create site directory: " + err.Error())
 		return err
 	}
 
-	if err := sm.st.AddSite(&site); err != nil {
+	if err := sm.st.AddSite(site); err != nil {
 		return err
 	}
 
@@ -529,7 +684,7 @@ func (sm *SiteManager) AddSite(site types.Site) error {
 	// reply, MCP error body) is automatically scrubbed.
 	secrets.Add(site.DBPassword)
 
-	sm.writeConfigYAML(&site)
+	sm.writeConfigYAML(site)
 	sm.emitSitesUpdate()
 	return nil
 }
@@ -571,6 +726,8 @@ func (sm *SiteManager) StartSite(ctx context.Context, id string) error {
 		Name: "start-site:" + site.Slug,
 		Steps: []orch.Step{
 			&sitesteps.EnsureSPXStep{Site: site, HomeDir: sm.homeDir},
+			&sitesteps.EnsureXdebugStep{Site: site, HomeDir: sm.homeDir},
+			&sitesteps.EnsurePHPIniStep{Site: site, HomeDir: sm.homeDir},
 			&sitesteps.FuncStep{
 				Label: "ensure-wordpress",
 				Do: func(_ context.Context) error {
@@ -589,6 +746,18 @@ func (sm *SiteManager) StartSite(ctx context.Context, id string) error {
 					return installAutoLoginPlugin(site)
 				},
 			},
+			&sitesteps.FuncStep{
+				Label: "ensure-mail-tag-plugin",
+				Do: func(_ context.Context) error {
+					return installMailTagPlugin(site)
+				},
+			},
+			&sitesteps.FuncStep{
+				Label: "ensure-sqlite-dropin",
+				Do: func(_ context.Context) error {
+					return sm.ensureSQLiteDropIn(site)
+				},
+			},
 			&sitesteps.FuncStep{
 				Label: "generate-site-config",
 				Do: func(_ context.Context) error {
@@ -683,6 +852,10 @@ func (sm *SiteManager) routeFor(site *types.Site) router.SiteRoute {
 			}
 		}
 	}
+	hosts, wildcards := splitAliases(site.Aliases)
+	route.ExtraHosts = append(route.ExtraHosts, hosts...)
+	route.ExtraWildcardHosts = append(route.ExtraWildcardHosts, wildcards...)
+	sm.applyAccess(&route, site)
 	return route
 }
 
@@ -851,20 +1024,9 @@ func (sm *SiteManager) RefreshLAN(ctx context.Context, siteID string) error {
 }
 
 func (sm *SiteManager) toggleLAN(ctx context.Context, siteID string, on bool) error {
-	site, err := sm.st.GetSite(siteID)
+	site, err := sm.lanTarget(siteID)
 	if err != nil {
-		return fmt.Errorf("fetch site: %w", err)
-	}
-	if site == nil {
-		return fmt.Errorf("site %q not found", siteID)
-	}
-
-	if utils.IsWSL() {
-		// LAN access on WSL2 needs Windows-side networking work that
-		// is out of scope for v1; refuse rather than silently produce
-		// a non-working URL. The UI gates the toggle button behind a
-		// notice, so this is also a defence-in-depth check.
-		return errors.New("LAN access is not supported on WSL2")
+		return err
 	}
 
 	mu := sm.siteMutex(siteID)
@@ -972,6 +1134,26 @@ func (sm *SiteManager) toggleLAN(ctx context.Context, siteID string, on bool) er
 	return sm.runHooks(ctx, postEvent, site)
 }
 
+// lanTarget fetches siteID for a LAN toggle.
+func (sm *SiteManager) lanTarget(siteID string) (*types.Site, error) {
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch site: %w", err)
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site %q not found", siteID)
+	}
+
+	if utils.IsWSL() {
+		// LAN access on WSL2 needs Windows-side networking work that
+		// is out of scope for v1; refuse rather than silently produce
+		// a non-working URL. The UI gates the toggle button behind a
+		// notice, so this is also a defence-in-depth check.
+		return nil, errors.New("LAN access is not supported on WSL2")
+	}
+	return site, nil
+}
+
 func (sm *SiteManager) generateWebServerConfig(site *types.Site) error {
 	if site.WebServer == "apache" {
 		return sm.generateApacheSiteConfig(site, path.Join(sm.homeDir, ".locorum", "config", "apache", "sites", site.Slug+".conf"))
@@ -979,17 +1161,24 @@ func (sm *SiteManager) generateWebServerConfig(site *types.Site) error {
 	return sm.generateSiteConfig(site, path.Join(sm.homeDir, ".locorum", "config", "nginx", "sites", site.Slug+".conf"))
 }
 
-// serviceSpecs returns the four per-site container specs in the order:
-// web, php, database, redis. Database routing happens through dbengine
-// so MySQL and MariaDB sites resolve to engine-specific specs without
-// branching here.
+// serviceSpecs returns the per-site container specs in the order: web,
+// php, database, cache. Database routing happens through dbengine so
+// MySQL and MariaDB sites resolve to engine-specific specs without
+// branching here; SQLite sites keep their database in the PHP container
+// and get no database spec. The cache spec comes from cachebackend and
+// is omitted for sites on the "none" backend.
 func (sm *SiteManager) serviceSpecs(site *types.Site) []docker.ContainerSpec {
-	return []docker.ContainerSpec{
+	specs := []docker.ContainerSpec{
 		docker.WebSpec(site, sm.homeDir),
 		docker.PHPSpec(site, sm.homeDir),
-		dbengine.Resolve(site).ContainerSpec(site, sm.homeDir),
-		docker.RedisSpec(site),
 	}
+	if eng := dbengine.Resolve(site); eng.Service() == "database" {
+		specs = append(specs, eng.ContainerSpec(site, sm.homeDir))
+	}
+	if spec, ok := cachebackend.ContainerSpec(site); ok {
+		specs = append(specs, spec)
+	}
+	return specs
 }
 
 func specNames(specs []docker.ContainerSpec) []string {
@@ -1294,6 +1483,7 @@ func (sm *SiteManager) DeleteSiteWithOptions(ctx context.Context, id string, opt
 		slog.Warn("post-delete hook run failed", "err", err.Error())
 	}
 
+	sm.forgetRemoteSecrets(site)
 	if err := sm.st.DeleteSite(id); err != nil {
 		return err
 	}
@@ -1302,7 +1492,10 @@ func (sm *SiteManager) DeleteSiteWithOptions(ctx context.Context, id string, opt
 	// nothing should still emit them, and keeping stale entries grows
 	// the redaction pass without bound.
 	secrets.Remove(site.DBPassword)
+	secrets.Remove(site.MailRelayPassword)
+	sm.ClearSiteRequests(site.Slug)
 
+	sm.syncHostsFileAfterDelete(ctx, site)
 	sm.emitSitesUpdate()
 	return nil
 }
@@ -1337,8 +1530,9 @@ func (sm *SiteManager) emitSitesUpdate() {
 
 // ReconcileState marks all sites as stopped in the database. Called on
 // startup after Initialize() has cleaned up all containers. Also seeds
-// the secret-redaction registry with every persisted DB password so any
-// error string surfaced before the first Add/Clone is still scrubbed.
+// the secret-redaction registry with every persisted DB, relay and
+// basic-auth password (and SSH remote password) so any error string
+// surfaced before the first Add/Clone is still scrubbed.
 func (sm *SiteManager) ReconcileState() error {
 	rows, err := sm.st.GetSites()
 	if err != nil {
@@ -1349,6 +1543,12 @@ func (sm *SiteManager) ReconcileState() error {
 		if rows[i].DBPassword != "" {
 			secrets.Add(rows[i].DBPassword)
 		}
+		if rows[i].MailRelayPassword != "" {
+			secrets.Add(rows[i].MailRelayPassword)
+		}
+		if rows[i].AuthPassword != "" {
+			secrets.Add(rows[i].AuthPassword)
+		}
 		if rows[i].Started {
 			rows[i].Started = false
 			if _, err := sm.st.UpdateSite(&rows[i]); err != nil {
@@ -1357,6 +1557,16 @@ func (sm *SiteManager) ReconcileState() error {
 		}
 	}
 
+	if remotes, err := sm.st.ListAllRemotes(); err == nil {
+		for _, r := range remotes {
+			if r.Password != "" {
+				secrets.Add(r.Password)
+			}
+		}
+	} else {
+		slog.Warn("reconcile: listing remotes: " + err.Error())
+	}
+
 	sm.emitSitesUpdate()
 
 	return nil
@@ -1395,10 +1605,10 @@ func (sm *SiteManager) PickDirectory() (string, error) {
 // attempts (each new ContainerLogs call resumes from the last seen
 // timestamp).
 //
-// service is one of "web", "php", "database", "redis" — the per-site
-// service alias. ErrNotFound on the first attach means the container has
-// not been created yet (site never started); the caller should treat
-// that as terminal and not retry.
+// service is one of "web", "php", "database" or the cache service — the
+// per-site service alias. ErrNotFound on the first attach means the
+// container has not been created yet (site never started); the caller
+// should treat that as terminal and not retry.
 func (sm *SiteManager) StreamLogs(ctx context.Context, siteID, service string) (<-chan docker.LogLine, error) {
 	site, err := sm.st.GetSite(siteID)
 	if err != nil {
@@ -1529,8 +1739,7 @@ func (sm *SiteManager) OpenAdminLogin(siteID string) error {
 		return fmt.Errorf("writing auto-login token: %w", err)
 	}
 
-	loginURL := fmt.Sprintf("https://%s/wp-admin/?locorum_token=%s", site.Domain, token)
-	return utils.OpenURL(loginURL)
+	return utils.OpenURL(adminLoginURL(site, token))
 }
 
 // OpenSiteShell opens an interactive terminal session in the site's PHP container.
@@ -1541,11 +1750,11 @@ func (sm *SiteManager) OpenSiteShell(siteID string) error {
 }
 
 // OpenServiceShell opens a terminal exec'd into the named service
-// container for siteID. service is one of "web", "php", "database",
-// "redis". The shell binary is selected from a per-service map (Alpine
-// images only ship sh; everything else has bash) so users don't get a
-// "no such file or directory" when they click Open Shell on the nginx
-// container.
+// container for siteID. service is one of "web", "php", "database" or a
+// cache service ("redis", "valkey", "memcached"). The shell binary is
+// selected from a per-service map (Alpine images only ship sh; everything
+// else has bash) so users don't get a "no such file or directory" when
+// they click Open Shell on the nginx container.
 //
 // Returns ErrSiteNotRunning when the site is stopped, and
 // utils.ErrNoTerminal when no terminal launcher could be found on PATH.
@@ -1574,13 +1783,15 @@ func (sm *SiteManager) OpenServiceShell(siteID, service string) error {
 //   - "php"      → wodby/php:* (Alpine but ships bash)   → bash
 //   - "database" → mysql / mariadb (Debian)              → bash
 //   - "redis"    → redis:*-alpine                        → sh
+//   - "valkey"   → valkey/valkey:*-alpine                → sh
+//   - "memcached" → memcached:*-alpine                   → sh
 //
 // Any unknown service falls back to bash; if it's wrong the user gets a
 // clear "no such file" message inside the terminal rather than a silent
 // no-op.
 func shellBinaryForService(service string) string {
 	switch service {
-	case "web", "redis":
+	case "web", "redis", "valkey", "memcached":
 		return "/bin/sh"
 	default:
 		return "/bin/bash"
@@ -1589,13 +1800,15 @@ func shellBinaryForService(service string) string {
 
 // VersionsChange describes a desired change to a stopped site's runtime
 // versions. The fields are tri-state: empty string = no change, a
-// concrete value = set to that. ChangeEngine flips the engine kind
-// itself, which always requires the migrate flow.
+// concrete value = set to that. DBEngine flips the engine kind itself,
+// which always requires the migrate flow. CacheBackend, by contrast,
+// switches in place: caches hold nothing worth migrating.
 type VersionsChange struct {
 	PHPVersion   string
 	DBEngine     string
 	DBVersion    string
-	RedisVersion string
+	CacheBackend string
+	CacheVersion string
 }
 
 // ErrUnsafeVersionTransition is returned when a requested version change
@@ -1603,16 +1816,16 @@ type VersionsChange struct {
 // MySQL 8 → 5.7) — the caller should route through MigrateEngine instead.
 var ErrUnsafeVersionTransition = errors.New("unsafe version transition; use MigrateEngine")
 
-// UpdateSiteVersions changes PHP/DB/Redis versions for a stopped site and
+// UpdateSiteVersions changes PHP/DB/cache versions for a stopped site and
 // removes old containers so they are recreated on next start with the
 // new images. Same engine, same major version transitions only — engine
 // swaps must go through MigrateEngine which preserves data via
 // snapshot+restore.
-func (sm *SiteManager) UpdateSiteVersions(ctx context.Context, siteID, phpVer, dbVer, redisVer string) error {
+func (sm *SiteManager) UpdateSiteVersions(ctx context.Context, siteID, phpVer, dbVer, cacheVer string) error {
 	return sm.UpdateSiteVersionsWithEngine(ctx, siteID, VersionsChange{
 		PHPVersion:   phpVer,
 		DBVersion:    dbVer,
-		RedisVersion: redisVer,
+		CacheVersion: cacheVer,
 	})
 }
 
@@ -1620,38 +1833,91 @@ func (sm *SiteManager) UpdateSiteVersions(ctx context.Context, siteID, phpVer, d
 // also pass DBEngine — but only the same engine is accepted here; engine
 // swaps return ErrUnsafeVersionTransition pointing at MigrateEngine.
 func (sm *SiteManager) UpdateSiteVersionsWithEngine(ctx context.Context, siteID string, change VersionsChange) error {
+	site, err := sm.versionsTarget(siteID)
+	if err != nil {
+		return err
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Capture container names before mutating: a cache backend switch
+	// renames the cache container, and the old one must go.
+	containers := specNames(sm.serviceSpecs(site))
+
+	changed, err := applyVersionsChange(site, change)
+	if err != nil || !changed {
+		return err
+	}
+
+	if err := sm.runHooks(ctx, hooks.PreVersionsChange, site); err != nil {
+		return err
+	}
+
+	if err := (&sitesteps.RemoveContainersStep{Engine: sm.d, Containers: containers}).Apply(ctx); err != nil {
+		slog.Error("Failed to remove old containers for version swap: " + err.Error())
+	}
+
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	sm.writeConfigYAML(site)
+	return sm.runHooks(ctx, hooks.PostVersionsChange, site)
+}
+
+// versionsTarget fetches siteID for a versions change, which needs the
+// site stopped.
+func (sm *SiteManager) versionsTarget(siteID string) (*types.Site, error) {
 	site, err := sm.st.GetSite(siteID)
 	if err != nil {
-		return fmt.Errorf("fetching site: %w", err)
+		return nil, fmt.Errorf("fetching site: %w", err)
 	}
 	if site == nil {
-		return fmt.Errorf("site %q not found", siteID)
+		return nil, fmt.Errorf("site %q not found", siteID)
 	}
 	if site.Started {
-		return errors.New("site must be stopped to change versions")
+		return nil, errors.New("site must be stopped to change versions")
 	}
+	return site, nil
+}
 
-	mu := sm.siteMutex(siteID)
-	mu.Lock()
-	defer mu.Unlock()
-
+// applyVersionsChange applies change to site in memory and reports
+// whether anything differs. Engine swaps and unsafe DB version moves
+// return ErrUnsafeVersionTransition.
+func applyVersionsChange(site *types.Site, change VersionsChange) (bool, error) {
 	changed := false
 	if change.PHPVersion != "" && change.PHPVersion != site.PHPVersion {
 		site.PHPVersion = change.PHPVersion
 		changed = true
 	}
-	if change.RedisVersion != "" && change.RedisVersion != site.RedisVersion {
-		site.RedisVersion = change.RedisVersion
+	if change.CacheBackend != "" && change.CacheBackend != site.CacheBackend {
+		kind := cachebackend.Kind(change.CacheBackend)
+		if !cachebackend.IsValid(kind) {
+			return false, fmt.Errorf("unknown cache backend %q", change.CacheBackend)
+		}
+		site.CacheBackend = change.CacheBackend
+		if !slices.Contains(cachebackend.KnownVersions(kind), site.CacheVersion) {
+			site.CacheVersion = cachebackend.DefaultVersion(kind)
+		}
+		changed = true
+	}
+	if change.CacheVersion != "" && change.CacheVersion != site.CacheVersion {
+		site.CacheVersion = change.CacheVersion
 		changed = true
 	}
 	if change.DBEngine != "" && change.DBEngine != site.DBEngine {
 		// Engine swap is not in-place — always migrate via snapshot.
-		return ErrUnsafeVersionTransition
+		return false, ErrUnsafeVersionTransition
 	}
 	if change.DBVersion != "" && change.DBVersion != site.DBVersion {
 		eng := dbengine.Resolve(site)
 		if !eng.UpgradeAllowed(site.DBVersion, change.DBVersion) {
-			return ErrUnsafeVersionTransition
+			return false, ErrUnsafeVersionTransition
 		}
 		site.DBVersion = change.DBVersion
 		// Keep the legacy mirror in sync for one minor release.
@@ -1660,29 +1926,7 @@ func (sm *SiteManager) UpdateSiteVersionsWithEngine(ctx context.Context, siteID
 		}
 		changed = true
 	}
-
-	if !changed {
-		return nil
-	}
-
-	if err := sm.runHooks(ctx, hooks.PreVersionsChange, site); err != nil {
-		return err
-	}
-
-	containers := specNames(sm.serviceSpecs(site))
-	if err := (&sitesteps.RemoveContainersStep{Engine: sm.d, Containers: containers}).Apply(ctx); err != nil {
-		slog.Error("Failed to remove old containers for version swap: " + err.Error())
-	}
-
-	if _, err := sm.st.UpdateSite(site); err != nil {
-		return fmt.Errorf("updating site: %w", err)
-	}
-
-	if sm.OnSiteUpdated != nil {
-		sm.OnSiteUpdated(site)
-	}
-	sm.writeConfigYAML(site)
-	return sm.runHooks(ctx, hooks.PostVersionsChange, site)
+	return changed, nil
 }
 
 func (sm *SiteManager) OpenSiteURL(siteID string) error {
@@ -1767,12 +2011,12 @@ func (sm *SiteManager) CloneSite(ctx context.Context, siteID, newName string) er
 
 	var dbDump string
 	if site.Started {
-		// Use the engine's hardened Snapshot path: it injects MYSQL_PWD via
-		// the container env so the password never reaches the process arg
-		// list (where docker top / /proc/<pid>/cmdline would expose it).
+		// dumpSQL uses the engine's hardened Snapshot path: it injects
+		// MYSQL_PWD via the container env so the password never reaches
+		// the process arg list (where docker top / /proc/<pid>/cmdline
+		// would expose it). SQLite sites dump SQL through the drop-in.
 		var dumpBuf bytes.Buffer
-		eng := dbengine.Resolve(site)
-		if _, err := eng.Snapshot(ctx, sm.d, site, &dumpBuf); err != nil {
+		if _, err := sm.dumpSQL(ctx, site, &dumpBuf); err != nil {
 			slog.Warn("Could not dump database during clone: " + err.Error())
 		} else {
 			dbDump = dumpBuf.String()
@@ -1795,11 +2039,17 @@ func (sm *SiteManager) CloneSite(ctx context.Context, siteID, newName string) er
 		DBEngine:      site.DBEngine,
 		DBVersion:     site.DBVersion,
 		MySQLVersion:  site.MySQLVersion, //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
-		RedisVersion:  site.RedisVersion,
+		CacheBackend:  site.CacheBackend,
+		CacheVersion:  site.CacheVersion,
 		WebServer:     site.WebServer,
 		Multisite:     site.Multisite,
 		PublishDBPort: site.PublishDBPort,
+		Resources:     site.Resources,
 		DBPassword:    newPassword,
+
+		PHPIni:               maps.Clone(site.PHPIni),
+		PHPExtensionsEnable:  slices.Clone(site.PHPExtensionsEnable),
+		PHPExtensionsDisable: slices.Clone(site.PHPExtensionsDisable),
 	}
 
 	if err := sm.st.AddSite(&newSite); err != nil {
@@ -1869,7 +2119,10 @@ func (sm *SiteManager) PublishedDBHostPort(ctx context.Context, siteID string) (
 		return 0, nil
 	}
 	eng := dbengine.Resolve(site)
-	return sm.d.PublishedHostPort(ctx, docker.SiteContainerName(site.Slug, "database"), eng.DefaultPort())
+	if eng.DefaultPort() == 0 {
+		return 0, nil
+	}
+	return sm.d.PublishedHostPort(ctx, docker.SiteContainerName(site.Slug, eng.Service()), eng.DefaultPort())
 }
 
 // ConnectionURL returns the engine-formatted connection URL for the
@@ -1914,6 +2167,42 @@ func (sm *SiteManager) SetPublishDBPort(siteID string, on bool) error {
 	return nil
 }
 
+// SetWebServer switches a stopped site between nginx and apache. The
+// web container's spec hash covers the image, so the next start
+// recreates it without an explicit remove.
+func (sm *SiteManager) SetWebServer(siteID, webServer string) error {
+	if webServer != "nginx" && webServer != "apache" {
+		return fmt.Errorf("invalid web server %q (allowed: nginx, apache)", webServer)
+	}
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if site.Started {
+		return errors.New("site must be stopped to change web server")
+	}
+	if site.WebServer == webServer {
+		return nil
+	}
+	site.WebServer = webServer
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	sm.writeConfigYAML(site)
+	return nil
+}
+
 // spxKeyByteLen is the size of the random source for an SPX_KEY before
 // base64-encoding. 32 bytes → 43 chars of RawURLEncoding, well above
 // the brute-force horizon for any local-dev attacker.
@@ -1932,6 +2221,9 @@ func generateSPXKey() (string, error) {
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
+// errSPXSiteRunning is SetSPXEnabled's refusal for a running site.
+var errSPXSiteRunning = errors.New("site must be stopped to change SPX profiling")
+
 // SetSPXEnabled persists the SPX-profiler toggle for siteID. Site must
 // be stopped — same constraint as SetPublishDBPort, for the same
 // reason: toggling SPX changes the PHP container's spec hash and
@@ -1957,7 +2249,7 @@ func (sm *SiteManager) SetSPXEnabled(siteID string, enabled bool) error {
 	defer mu.Unlock()
 
 	if site.Started {
-		return errors.New("site must be stopped to change SPX profiling")
+		return errSPXSiteRunning
 	}
 	if site.SPXEnabled == enabled && (!enabled || site.SPXKey != "") {
 		return nil
@@ -93,7 +93,7 @@ func (s *WriteMarkerStep) Apply(ctx context.Context) error {
 	// Read the existing marker; if it was already written on a prior
 	// run, preserve the Created timestamp so the marker tells the user
 	// when the volume was first initialised, not when it last booted.
-	containerName := docker.SiteContainerName(s.Site.Slug, "database")
+	containerName := docker.SiteContainerName(s.Site.Slug, eng.Service())
 	created := time.Now().UTC()
 	if existing, err := readMarkerFromContainer(ctx, s.Execer, containerName, eng); err == nil && !existing.Created.IsZero() {
 		created = existing.Created
@@ -0,0 +1,61 @@
+package sitesteps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/phpini"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// EnsurePHPIniStep writes the site's php.ini overrides, plus what its
+// mail mode needs (docker.SitePHPINISettings), to
+// ~/.locorum/config/php/sites/<slug>.ini, which PHPSpec mounts as
+// zzzz-site.ini. A site with nothing to write has the file removed, the
+// same as EnsureXdebugStep does for a disabled Xdebug.
+//
+// The user's directives are validated again here: config.yaml edits
+// and imports reach the row through their own paths, and a bad line
+// would otherwise only surface as PHP refusing to start.
+type EnsurePHPIniStep struct {
+	Site    *types.Site
+	HomeDir string
+}
+
+func (s *EnsurePHPIniStep) Name() string { return "ensure-php-ini" }
+
+func (s *EnsurePHPIniStep) Apply(_ context.Context) error {
+	if s.Site == nil {
+		return nil
+	}
+
+	iniPath := docker.SitePHPINIPath(s.HomeDir, s.Site.Slug)
+
+	settings := docker.SitePHPINISettings(s.Site)
+	if len(settings) == 0 {
+		if err := os.Remove(iniPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove stale php ini: %w", err)
+		}
+		return nil
+	}
+	if err := phpini.ValidateSettings(s.Site.PHPIni); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(iniPath), 0o700); err != nil {
+		return fmt.Errorf("ensure php ini dir: %w", err)
+	}
+	if err := os.WriteFile(iniPath, []byte(phpini.Render(settings)), 0o600); err != nil {
+		return fmt.Errorf("write php ini: %w", err)
+	}
+	return nil
+}
+
+// Rollback is a no-op: the file is rewritten on every Apply.
+func (s *EnsurePHPIniStep) Rollback(_ context.Context) error { return nil }
+
+var _ orch.Step = (*EnsurePHPIniStep)(nil)
@@ -310,7 +310,7 @@ func (s *RemoveNetworkStep) Apply(ctx context.Context) error {
 func (s *RemoveNetworkStep) Rollback(_ context.Context) error { return nil }
 
 // RemoveSiteConfigsStep removes the per-site nginx/apache config files
-// and the per-site SPX key INI (if any). Volume-side state (DB data,
+// and the per-site SPX key / Xdebug / php.ini override INIs (if any). Volume-side state (DB data,
 // uploaded files) is handled separately.
 type RemoveSiteConfigsStep struct {
 	HomeDir string
@@ -323,6 +323,8 @@ func (s *RemoveSiteConfigsStep) Apply(_ context.Context) error {
 		filepath.Join(s.HomeDir, ".locorum", "config", "nginx", "sites", s.Site.Slug+".conf"),
 		filepath.Join(s.HomeDir, ".locorum", "config", "apache", "sites", s.Site.Slug+".conf"),
 		docker.SPXKeyINIPath(s.HomeDir, s.Site.Slug),
+		docker.XdebugINIPath(s.HomeDir, s.Site.Slug),
+		docker.SitePHPINIPath(s.HomeDir, s.Site.Slug),
 	)
 }
 func (s *RemoveSiteConfigsStep) Rollback(_ context.Context) error { return nil }
@@ -395,6 +397,8 @@ var (
 	_ orch.Step = (*RemoveSiteConfigsStep)(nil)
 	_ orch.Step = (*PurgeVolumeStep)(nil)
 	_ orch.Step = (*EnsureSPXStep)(nil)
+	_ orch.Step = (*EnsureXdebugStep)(nil)
+	_ orch.Step = (*EnsurePHPIniStep)(nil)
 	_ orch.Step = (*HookStep)(nil)
 	_ orch.Step = (*FuncStep)(nil)
 
@@ -25,7 +25,7 @@ func testSite() *types.Site {
 		DBEngine:     "mysql",
 		DBVersion:    "8.4",
 		MySQLVersion: "8.4",
-		RedisVersion: "7",
+		CacheVersion: "7",
 		WebServer:    "nginx",
 		DBPassword:   "p",
 	}
@@ -257,6 +257,112 @@ func TestEnsureSPXStep_KeyChangeIsPickedUp(t *testing.T) {
 	}
 }
 
+func TestEnsureXdebugStep_Disabled_RemovesINI(t *testing.T) {
+	dir := t.TempDir()
+	home := t.TempDir()
+	site := &types.Site{Slug: "demo", FilesDir: dir, XdebugEnabled: false, XdebugMode: "debug"}
+	iniPath := docker.XdebugINIPath(home, site.Slug)
+	if err := os.MkdirAll(filepath.Dir(iniPath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(iniPath, []byte("xdebug.mode = debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	step := &EnsureXdebugStep{Site: site, HomeDir: home}
+	if err := step.Apply(context.Background()); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if _, err := os.Stat(iniPath); !os.IsNotExist(err) {
+		t.Errorf("xdebug INI not removed on disabled toggle (err=%v)", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".locorum", "xdebug")); !os.IsNotExist(err) {
+		t.Errorf("output dir created when Xdebug disabled (err=%v)", err)
+	}
+}
+
+func TestEnsureXdebugStep_Enabled_WritesModeAndOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	home := t.TempDir()
+	site := &types.Site{Slug: "demo", FilesDir: dir, XdebugEnabled: true, XdebugMode: "profile"}
+	step := &EnsureXdebugStep{Site: site, HomeDir: home}
+	if err := step.Apply(context.Background()); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, ".locorum", "xdebug")); err != nil || !info.IsDir() {
+		t.Errorf("output dir not created: err=%v info=%v", err, info)
+	}
+	body, err := os.ReadFile(docker.XdebugINIPath(home, site.Slug))
+	if err != nil {
+		t.Fatalf("read xdebug INI: %v", err)
+	}
+	for _, want := range []string{
+		"xdebug.mode = profile",
+		"xdebug.client_host = host.docker.internal",
+		"xdebug.output_dir = /var/xdebug/data",
+	} {
+		if !contains(string(body), want) {
+			t.Errorf("xdebug INI missing %q: %q", want, body)
+		}
+	}
+
+	site.XdebugMode = "trace"
+	if err := step.Apply(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	body, _ = os.ReadFile(docker.XdebugINIPath(home, site.Slug))
+	if !contains(string(body), "xdebug.mode = trace") || contains(string(body), "xdebug.mode = profile") {
+		t.Errorf("mode change not picked up: %q", body)
+	}
+}
+
+func TestEnsureXdebugStep_EnabledWithoutMode(t *testing.T) {
+	site := &types.Site{Slug: "demo", FilesDir: t.TempDir(), XdebugEnabled: true}
+	step := &EnsureXdebugStep{Site: site, HomeDir: t.TempDir()}
+	if err := step.Apply(context.Background()); err == nil {
+		t.Fatal("Apply succeeded without a mode; want error")
+	}
+}
+
+func TestEnsurePHPIniStep_WritesAndRemoves(t *testing.T) {
+	home := t.TempDir()
+	site := &types.Site{Slug: "demo", PHPIni: map[string]string{
+		"max_input_vars":              "5000",
+		"opcache.validate_timestamps": "0",
+	}}
+	step := &EnsurePHPIniStep{Site: site, HomeDir: home}
+	if err := step.Apply(context.Background()); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	iniPath := docker.SitePHPINIPath(home, site.Slug)
+	body, err := os.ReadFile(iniPath)
+	if err != nil {
+		t.Fatalf("read site INI: %v", err)
+	}
+	for _, want := range []string{"max_input_vars = 5000", "opcache.validate_timestamps = 0"} {
+		if !contains(string(body), want) {
+			t.Errorf("site INI missing %q: %q", want, body)
+		}
+	}
+
+	site.PHPIni = nil
+	if err := step.Apply(context.Background()); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if _, err := os.Stat(iniPath); !os.IsNotExist(err) {
+		t.Errorf("site INI not removed once overrides cleared (err=%v)", err)
+	}
+}
+
+func TestEnsurePHPIniStep_RejectsInvalid(t *testing.T) {
+	site := &types.Site{Slug: "demo", PHPIni: map[string]string{"extension": "evil.so"}}
+	step := &EnsurePHPIniStep{Site: site, HomeDir: t.TempDir()}
+	if err := step.Apply(context.Background()); err == nil {
+		t.Fatal("Apply wrote an INI with a blocked directive; want error")
+	}
+}
+
 // contains is a tiny string-contains helper so tests stay free of
 // strings.Contains imports per the existing pattern in this file.
 func contains(haystack, needle string) bool {
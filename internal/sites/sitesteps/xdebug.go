@@ -0,0 +1,85 @@
+package sitesteps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// EnsureXdebugStep writes the per-site Xdebug INI before PHP-FPM
+// starts:
+//
+//   - ~/.locorum/config/php/xdebug/<slug>.ini — xdebug.mode plus the
+//     client host/port and output dir, mounted read-only at
+//     /usr/local/etc/php/conf.d/zzzz-xdebug.ini.
+//   - <site.FilesDir>/.locorum/xdebug — profiler / trace output,
+//     bind-mounted at /var/xdebug/data. ChownStep fixes ownership.
+//
+// When Xdebug is disabled the INI is removed so the config dir only
+// ever reflects enabled sites. The output directory is kept: cachegrind
+// and trace files are user artefacts.
+type EnsureXdebugStep struct {
+	Site    *types.Site
+	HomeDir string
+}
+
+func (s *EnsureXdebugStep) Name() string { return "ensure-xdebug" }
+
+func (s *EnsureXdebugStep) Apply(_ context.Context) error {
+	if s.Site == nil {
+		return nil
+	}
+
+	iniPath := docker.XdebugINIPath(s.HomeDir, s.Site.Slug)
+
+	if !s.Site.XdebugEnabled {
+		if err := os.Remove(iniPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove stale xdebug ini: %w", err)
+		}
+		return nil
+	}
+	if s.Site.XdebugMode == "" {
+		return fmt.Errorf("xdebug enabled for %s without a mode", s.Site.Slug)
+	}
+
+	dataDir := filepath.Join(s.Site.FilesDir, ".locorum", "xdebug")
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return fmt.Errorf("ensure xdebug output dir: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(iniPath), 0o700); err != nil {
+		return fmt.Errorf("ensure xdebug ini dir: %w", err)
+	}
+	if err := os.WriteFile(iniPath, []byte(xdebugINI(s.Site.XdebugMode)), 0o600); err != nil {
+		return fmt.Errorf("write xdebug ini: %w", err)
+	}
+	return nil
+}
+
+// Rollback is a no-op: the INI is rewritten on every Apply and the
+// output directory may already hold captures worth keeping.
+func (s *EnsureXdebugStep) Rollback(_ context.Context) error { return nil }
+
+// xdebugINI renders the per-site INI body. start_with_request=trigger
+// keeps every mode opt-in per request (XDEBUG_TRIGGER cookie / query
+// param or a browser extension) so an enabled site doesn't pay the
+// profiler or tracer cost on every page load.
+func xdebugINI(mode string) string {
+	var b strings.Builder
+	b.WriteString("; locorum-generated — DO NOT EDIT.\n")
+	b.WriteString("; Per-site Xdebug settings. Regenerated on each site start.\n")
+	b.WriteString("xdebug.mode = " + mode + "\n")
+	b.WriteString("xdebug.start_with_request = trigger\n")
+	b.WriteString("xdebug.client_host = " + docker.XdebugClientHost + "\n")
+	b.WriteString("xdebug.client_port = " + strconv.Itoa(docker.XdebugClientPort) + "\n")
+	b.WriteString("xdebug.output_dir = /var/xdebug/data\n")
+	return b.String()
+}
+
+var _ orch.Step = (*EnsureXdebugStep)(nil)
@@ -0,0 +1,365 @@
+package sites
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/dbengine"
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/genmark"
+	"github.com/PeterBooker/locorum/internal/types"
+	"github.com/PeterBooker/locorum/internal/utils"
+)
+
+const (
+	sqlitePluginURL     = "https://downloads.wordpress.org/plugin/sqlite-database-integration.latest-stable.zip"
+	sqlitePluginSlug    = "sqlite-database-integration"
+	sqlitePluginMax     = 32 << 20 // 32 MiB cap on the plugin zip.
+	sqliteDropInRelPath = "wp-content/db.php"
+)
+
+// sqliteDropInBody is the wp-content/db.php drop-in for SQLite sites. It
+// loads the SQLite Database Integration plugin's $wpdb replacement from
+// the plugin directory, so the plugin never has to be activated (a fresh
+// site has no database to activate it in). The plugin's own db.copy does
+// the same; ours carries the locorum-generated marker so a MySQL site can
+// have it removed safely after MigrateEngine.
+const sqliteDropInBody = `<?php
+// #locorum-generated — DO NOT remove this line if you want Locorum to keep
+// this file in sync. Removing the signature opts out of all Locorum-
+// managed updates to this file forever.
+//
+// SQLite database drop-in. Locorum writes this file for sites on the
+// SQLite engine and removes it when the site moves to MySQL or MariaDB.
+
+$locorum_sqlite_dir = __DIR__ . '/plugins/sqlite-database-integration';
+if ( ! file_exists( $locorum_sqlite_dir . '/wp-includes/sqlite/db.php' ) ) {
+	die( 'Locorum: the SQLite Database Integration plugin is missing from wp-content/plugins. Restart the site to reinstall it.' );
+}
+if ( ! defined( 'SQLITE_DB_DROPIN_VERSION' ) ) {
+	define( 'SQLITE_DB_DROPIN_VERSION', 'locorum' );
+}
+require_once $locorum_sqlite_dir . '/wp-includes/sqlite/db.php';
+unset( $locorum_sqlite_dir );
+`
+
+// sqliteImportScript replays a MySQL dump through $wpdb, which on a
+// SQLite site is the drop-in's MySQL→SQLite translator. Run with
+// `wp eval-file - <dump>`; statements are split on a trailing `;` the
+// way mysqldump and `wp db export` lay them out.
+//
+// Skipped: comments, conditional-comment directives (SET NAMES and
+// friends), LOCK/UNLOCK TABLES, bare SET statements, and anything inside
+// a DELIMITER block — triggers and routines have no SQLite translation.
+// The whole replay runs in one transaction, which is both faster and
+// leaves the database untouched when a statement fails.
+const sqliteImportScript = `<?php
+global $wpdb;
+$fh = isset( $args[0] ) ? fopen( $args[0], 'rb' ) : false;
+if ( ! $fh ) {
+	fwrite( STDERR, "cannot open dump\n" );
+	exit( 1 );
+}
+$wpdb->suppress_errors( true );
+$wpdb->query( 'START TRANSACTION' );
+$stmt = '';
+$delimiter = ';';
+$count = 0;
+while ( ( $line = fgets( $fh ) ) !== false ) {
+	$t = trim( $line );
+	if ( '' === $stmt ) {
+		if ( preg_match( '/^DELIMITER\s+(\S+)/i', $t, $m ) ) {
+			$delimiter = $m[1];
+			continue;
+		}
+		if ( ';' !== $delimiter || '' === $t || 0 === strpos( $t, '--' ) || 0 === strpos( $t, '/*!' )
+			|| preg_match( '/^(LOCK|UNLOCK)\s+TABLES|^SET\s/i', $t ) ) {
+			continue;
+		}
+	}
+	$stmt .= $line;
+	if ( ';' !== substr( $t, -1 ) ) {
+		continue;
+	}
+	$count++;
+	if ( false === $wpdb->query( $stmt ) ) {
+		$wpdb->query( 'ROLLBACK' );
+		fwrite( STDERR, 'statement ' . $count . ' failed: ' . $wpdb->last_error . "\n" . substr( $stmt, 0, 200 ) . "\n" );
+		exit( 1 );
+	}
+	$stmt = '';
+}
+$wpdb->query( 'COMMIT' );
+echo $count . " statements imported\n";
+`
+
+// sqliteExportScript writes a MySQL-compatible dump of a SQLite site to
+// stdout: DROP + CREATE from the translator's SHOW CREATE TABLE, then
+// rows as batched INSERTs escaped the way mysqldump escapes them. The
+// translator's own bookkeeping tables are skipped.
+const sqliteExportScript = `<?php
+global $wpdb;
+$q = function ( $name ) {
+	return chr( 96 ) . str_replace( chr( 96 ), chr( 96 ) . chr( 96 ), $name ) . chr( 96 );
+};
+$esc = function ( $v ) {
+	if ( null === $v ) {
+		return 'NULL';
+	}
+	return "'" . strtr( (string) $v, array( "\\" => "\\\\", "\0" => "\\0", "\n" => "\\n", "\r" => "\\r", "'" => "\\'", '"' => '\\"', "\x1a" => "\\Z" ) ) . "'";
+};
+echo "-- Locorum SQLite export\n/*!40101 SET NAMES utf8mb4 */;\n\n";
+foreach ( $wpdb->get_col( 'SHOW TABLES' ) as $table ) {
+	if ( preg_match( '/^(_mysql_|_wp_sqlite_|sqlite_)/', $table ) ) {
+		continue;
+	}
+	$create = $wpdb->get_row( 'SHOW CREATE TABLE ' . $q( $table ), ARRAY_N );
+	if ( ! $create ) {
+		fwrite( STDERR, 'SHOW CREATE TABLE ' . $table . ' failed: ' . $wpdb->last_error . "\n" );
+		exit( 1 );
+	}
+	echo 'DROP TABLE IF EXISTS ' . $q( $table ) . ";\n" . $create[1] . ";\n";
+	for ( $offset = 0; ; $offset += 500 ) {
+		$rows = $wpdb->get_results( 'SELECT * FROM ' . $q( $table ) . ' LIMIT 500 OFFSET ' . $offset, ARRAY_N );
+		if ( empty( $rows ) ) {
+			break;
+		}
+		$values = array();
+		$size = 0;
+		foreach ( $rows as $row ) {
+			$v = '(' . implode( ',', array_map( $esc, $row ) ) . ')';
+			$values[] = $v;
+			$size += strlen( $v );
+			if ( $size > 1048576 ) {
+				echo 'INSERT INTO ' . $q( $table ) . ' VALUES ' . implode( ',', $values ) . ";\n";
+				$values = array();
+				$size = 0;
+			}
+		}
+		if ( $values ) {
+			echo 'INSERT INTO ' . $q( $table ) . ' VALUES ' . implode( ',', $values ) . ";\n";
+		}
+		if ( count( $rows ) < 500 ) {
+			break;
+		}
+	}
+	echo "\n";
+}
+`
+
+// isSQLite reports whether site runs on the SQLite engine.
+func isSQLite(site *types.Site) bool {
+	return dbengine.Resolve(site).Kind() == dbengine.SQLite
+}
+
+// ensureSQLiteDropIn installs the SQLite Database Integration plugin and
+// the wp-content/db.php drop-in for SQLite sites. For every other site it
+// removes a Locorum-managed db.php, which is what's left behind when a
+// site migrates from SQLite to MySQL; a user-owned db.php (object cache
+// plugins ship one too) is never touched.
+//
+// The plugin is downloaded once and then left alone — users may update it
+// from wp-admin like any other plugin.
+func (sm *SiteManager) ensureSQLiteDropIn(site *types.Site) error {
+	root := wpDocrootDir(site)
+	dropIn := filepath.Join(root, filepath.FromSlash(sqliteDropInRelPath))
+
+	if !isSQLite(site) {
+		managed, err := genmark.HasMarkerFile(dropIn)
+		if err != nil || !managed {
+			return err
+		}
+		if err := os.Remove(dropIn); err != nil {
+			return fmt.Errorf("removing SQLite drop-in: %w", err)
+		}
+		slog.Info("sqlite: removed db.php drop-in", "site", site.Slug)
+		return nil
+	}
+
+	pluginsDir := filepath.Join(root, "wp-content", "plugins")
+	if _, err := os.Stat(filepath.Join(pluginsDir, sqlitePluginSlug, "load.php")); errors.Is(err, fs.ErrNotExist) {
+		if err := downloadSQLitePlugin(pluginsDir); err != nil {
+			return fmt.Errorf("installing SQLite Database Integration: %w", err)
+		}
+		slog.Info("sqlite: installed SQLite Database Integration", "site", site.Slug)
+	} else if err != nil {
+		return fmt.Errorf("checking SQLite plugin: %w", err)
+	}
+
+	if err := genmark.WriteIfManaged(dropIn, []byte(sqliteDropInBody), 0o644); err != nil &&
+		!errors.Is(err, genmark.ErrUserOwned) {
+		return fmt.Errorf("writing SQLite drop-in: %w", err)
+	}
+	return nil
+}
+
+// downloadSQLitePlugin fetches the plugin zip from wordpress.org and
+// unpacks it into pluginsDir. Extraction goes to a temporary sibling
+// directory first so an interrupted download never leaves a half-written
+// plugin that the existence check above would mistake for complete.
+func downloadSQLitePlugin(pluginsDir string) error {
+	resp, err := httpClientWordPress.Get(sqlitePluginURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, sqlitePluginMax+1))
+	if err != nil {
+		return fmt.Errorf("reading plugin zip: %w", err)
+	}
+	if len(body) > sqlitePluginMax {
+		return fmt.Errorf("plugin zip exceeds %d bytes — refusing to extract", sqlitePluginMax)
+	}
+
+	if err := utils.EnsureDir(pluginsDir); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(pluginsDir, ".locorum-sqlite-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extractPluginZip(body, tmp); err != nil {
+		return err
+	}
+	src := filepath.Join(tmp, sqlitePluginSlug)
+	if _, err := os.Stat(filepath.Join(src, "load.php")); err != nil {
+		return fmt.Errorf("plugin zip has no %s/load.php", sqlitePluginSlug)
+	}
+	dst := filepath.Join(pluginsDir, sqlitePluginSlug)
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
+
+// extractPluginZip unpacks a wordpress.org plugin zip into destDir with
+// the same hardening as extractTarGz: entries must stay inside destDir,
+// only regular files and directories are accepted, and each file is
+// capped at wordpressMaxArchiveSize.
+func extractPluginZip(body []byte, destDir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return fmt.Errorf("zip reader: %w", err)
+	}
+	base := filepath.Clean(destDir) + string(os.PathSeparator)
+	for _, f := range zr.File {
+		target := filepath.Join(destDir, f.Name) //nolint:gosec // G305: traversal guarded by the prefix check below.
+		if !strings.HasPrefix(filepath.Clean(target)+string(os.PathSeparator), base) {
+			return fmt.Errorf("zip entry %q escapes destination", f.Name)
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("mkdir %q: %w", target, err)
+			}
+		case mode.IsRegular():
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("mkdir parent %q: %w", target, err)
+			}
+			if err := writeZipEntry(f, target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("zip entry %q has unsupported mode %s — refusing to extract", f.Name, mode)
+		}
+	}
+	return nil
+}
+
+func writeZipEntry(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open %q: %w", f.Name, err)
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|extraOpenFlags(), 0o644)
+	if err != nil {
+		return fmt.Errorf("create %q: %w", target, err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, io.LimitReader(rc, wordpressMaxArchiveSize)); err != nil {
+		return fmt.Errorf("write %q: %w", target, err)
+	}
+	return nil
+}
+
+// wpEvalStdin pipes a PHP script into `wp eval-file -` in the site's PHP
+// container, with args passed through as the script's $args. Plugins and
+// themes are skipped so nothing but the script writes to stdout.
+func (sm *SiteManager) wpEvalStdin(ctx context.Context, site *types.Site, script string, stdout io.Writer, args ...string) error {
+	cmd := make([]string, 0, len(args)+6)
+	cmd = append(cmd, "wp", "eval-file", "-")
+	cmd = append(cmd, args...)
+	cmd = append(cmd, "--skip-plugins", "--skip-themes", "--path="+inContainerWPPath(site))
+	var stderr bytes.Buffer
+	exit, err := sm.d.ExecInContainerWriterStdin(ctx, docker.SiteContainerName(site.Slug, "php"),
+		docker.ExecOptions{Cmd: cmd}, strings.NewReader(script), stdout, &stderr)
+	if err != nil {
+		return fmt.Errorf("wp eval-file: %w", err)
+	}
+	if exit != 0 {
+		return fmt.Errorf("wp eval-file exited %d: %s", exit, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// importSQLFile loads a MySQL dump already inside the PHP container.
+// MySQL and MariaDB sites use `wp db import`; SQLite sites replay it
+// through the drop-in's translator. No auto-snapshot — see wpDBImport.
+func (sm *SiteManager) importSQLFile(ctx context.Context, site *types.Site, inContainerPath string) (string, error) {
+	if !isSQLite(site) {
+		return sm.wpcli(ctx, site, "db", "import", inContainerPath)
+	}
+	var out bytes.Buffer
+	if err := sm.wpEvalStdin(ctx, site, sqliteImportScript, &out, inContainerPath); err != nil {
+		return out.String(), fmt.Errorf("sqlite import: %w", err)
+	}
+	return out.String(), nil
+}
+
+// dumpSQL writes a MySQL-format dump of the site's database to w. For
+// MySQL and MariaDB that is the engine snapshot; a SQLite snapshot is
+// the raw database file, so SQLite sites go through the export script
+// instead. Callers that move data between engines or hosts use this
+// rather than Engine.Snapshot.
+func (sm *SiteManager) dumpSQL(ctx context.Context, site *types.Site, w io.Writer) (int64, error) {
+	if sm.sqlDump != nil {
+		return sm.sqlDump(ctx, site, w)
+	}
+	eng := dbengine.Resolve(site)
+	if eng.Kind() != dbengine.SQLite {
+		return eng.Snapshot(ctx, sm.d, site, w)
+	}
+	cw := &byteCounter{w: w}
+	if err := sm.wpEvalStdin(ctx, site, sqliteExportScript, cw); err != nil {
+		return cw.n, fmt.Errorf("sqlite export: %w", err)
+	}
+	return cw.n, nil
+}
+
+type byteCounter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
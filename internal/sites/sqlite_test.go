@@ -0,0 +1,95 @@
+package sites
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractPluginZip(t *testing.T) {
+	dir := t.TempDir()
+	body := buildZip(t, map[string]string{
+		"sqlite-database-integration/load.php":                  "<?php // plugin",
+		"sqlite-database-integration/wp-includes/sqlite/db.php": "<?php // driver",
+	})
+	if err := extractPluginZip(body, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sqlite-database-integration", "wp-includes", "sqlite", "db.php")); err != nil {
+		t.Errorf("nested file not extracted: %v", err)
+	}
+
+	evil := buildZip(t, map[string]string{"../escape.php": "<?php"})
+	if err := extractPluginZip(evil, t.TempDir()); err == nil {
+		t.Error("traversal entry should be rejected")
+	}
+}
+
+func TestEnsureSQLiteDropIn(t *testing.T) {
+	sm := &SiteManager{}
+	site := &types.Site{Slug: "lite", FilesDir: t.TempDir(), DBEngine: "sqlite"}
+	dropIn := filepath.Join(site.FilesDir, "wp-content", "db.php")
+
+	// Pre-seed the plugin so the test never reaches wordpress.org.
+	pluginDir := filepath.Join(site.FilesDir, "wp-content", "plugins", sqlitePluginSlug)
+	if err := os.MkdirAll(pluginDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "load.php"), []byte("<?php"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sm.ensureSQLiteDropIn(site); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(dropIn)
+	if err != nil {
+		t.Fatalf("drop-in not written: %v", err)
+	}
+	if !bytes.Contains(got, []byte("wp-includes/sqlite/db.php")) {
+		t.Errorf("drop-in does not load the SQLite driver:\n%s", got)
+	}
+
+	// Moving to MySQL removes our drop-in…
+	site.DBEngine = "mysql"
+	if err := sm.ensureSQLiteDropIn(site); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dropIn); !os.IsNotExist(err) {
+		t.Errorf("managed drop-in should be removed for a MySQL site, stat err = %v", err)
+	}
+
+	// …but never a db.php the user (or another plugin) owns.
+	if err := os.WriteFile(dropIn, []byte("<?php // object cache"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.ensureSQLiteDropIn(site); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dropIn); err != nil {
+		t.Errorf("user-owned db.php was removed: %v", err)
+	}
+}
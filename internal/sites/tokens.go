@@ -0,0 +1,164 @@
+package sites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/PeterBooker/locorum/internal/captoken"
+	"github.com/PeterBooker/locorum/internal/secrets"
+	"github.com/PeterBooker/locorum/internal/storage"
+)
+
+// TokenOptions are the grants of a new capability token. Sites take an
+// id or a slug each; empty means every site. Allow defaults to every
+// method the profile permits, Profile to full. A zero TTL never
+// expires.
+type TokenOptions struct {
+	Label   string
+	Profile string
+	Allow   []string
+	Deny    []string
+	Sites   []string
+	TTL     time.Duration
+}
+
+// CreateToken stores a new capability token and returns its secret,
+// which is not kept and cannot be shown again.
+func (sm *SiteManager) CreateToken(opts TokenOptions) (string, *captoken.Token, error) {
+	if opts.TTL < 0 {
+		return "", nil, fmt.Errorf("%w: negative expiry", captoken.ErrTokenInvalid)
+	}
+	t := &captoken.Token{
+		Label:   opts.Label,
+		Profile: opts.Profile,
+		Allow:   opts.Allow,
+		Deny:    opts.Deny,
+	}
+	if t.Profile == "" {
+		t.Profile = captoken.ProfileFull
+	}
+	if len(t.Allow) == 0 {
+		t.Allow = []string{"*"}
+	}
+	if opts.TTL > 0 {
+		t.ExpiresAt = time.Now().UTC().Add(opts.TTL).Format(time.RFC3339)
+	}
+	if len(opts.Sites) > 0 {
+		rows, err := sm.st.GetSites()
+		if err != nil {
+			return "", nil, fmt.Errorf("listing sites: %w", err)
+		}
+		for _, ref := range opts.Sites {
+			found := false
+			for _, s := range rows {
+				if s.ID == ref || s.Slug == ref {
+					t.Sites = append(t.Sites, captoken.Site{ID: s.ID, Slug: s.Slug})
+					found = true
+					break
+				}
+			}
+			if !found {
+				return "", nil, fmt.Errorf("site %q not found", ref)
+			}
+		}
+	}
+
+	secret, hash, err := captoken.Generate()
+	if err != nil {
+		return "", nil, err
+	}
+	t.Hash = hash
+	if err := sm.st.AddToken(t); err != nil {
+		return "", nil, err
+	}
+	slog.Info("capability token created", "label", t.Label, "profile", t.Profile, "sites", len(t.Sites))
+	return secret, t, nil
+}
+
+// ListTokens returns every capability token. Hashes are never
+// serialised, so the result is safe to hand to a client.
+func (sm *SiteManager) ListTokens() ([]captoken.Token, error) {
+	return sm.st.ListTokens()
+}
+
+// RevokeToken deletes the token called label. Connections already
+// using it are refused from their next call on.
+func (sm *SiteManager) RevokeToken(label string) error {
+	if err := sm.st.DeleteToken(label); err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return fmt.Errorf("token %q not found", label)
+		}
+		return err
+	}
+	slog.Info("capability token revoked", "label", label)
+	return nil
+}
+
+// TokenByHash returns the token whose secret hashes to hash. The
+// daemon calls it on every call a token makes, so a revocation takes
+// effect at once.
+func (sm *SiteManager) TokenByHash(hash string) (*captoken.Token, error) {
+	return sm.st.GetTokenByHash(hash)
+}
+
+// RecordTokenCall audits one call made with a token. Every call goes to
+// the log; mutating calls, refused or not, also get a row in the
+// target site's activity timeline. Reads stay out of the timeline: an
+// agent polling site.describe would otherwise push real lifecycle rows
+// past the per-site retention cap. Best-effort, like recordActivity.
+func (sm *SiteManager) RecordTokenCall(call captoken.Call) {
+	if sm.st == nil {
+		return
+	}
+	if err := sm.st.TouchToken(call.TokenID); err != nil {
+		slog.Debug("token touch failed", "label", call.Label, "err", err.Error())
+	}
+	attrs := []any{"token", call.Label, "method", call.Method, "site", firstNonEmpty(call.Slug, call.SiteID)}
+	if call.Err != nil {
+		slog.Info("token call failed", append(attrs, "err", secrets.RedactString(call.Err.Error()))...)
+	} else {
+		slog.Info("token call", attrs...)
+	}
+	if call.ReadOnly || (call.SiteID == "" && call.Slug == "") {
+		return
+	}
+
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		return
+	}
+	for _, site := range rows {
+		if site.ID != call.SiteID && site.Slug != call.Slug {
+			continue
+		}
+		d := activityDetails{Token: call.Label}
+		status := storage.ActivityStatusSucceeded
+		msg := call.Label + " called " + call.Method
+		if call.Err != nil {
+			status = storage.ActivityStatusFailed
+			errText := secrets.RedactString(call.Err.Error())
+			d.Error = truncateRunes(errText, activityErrorMaxBytes)
+			msg += ": " + errText
+		}
+		details, _ := json.Marshal(d)
+		ev := &storage.ActivityEvent{
+			SiteID:  site.ID,
+			Plan:    "call:" + site.Slug + ":" + call.Method,
+			Kind:    storage.ActivityKindCall,
+			Status:  status,
+			Message: truncateRunes(msg, activityMessageMaxBytes),
+			Details: details,
+		}
+		if err := sm.st.AppendActivity(ev); err != nil {
+			slog.Warn("activity append failed", "plan", ev.Plan, "site", site.Slug, "err", err.Error())
+			return
+		}
+		if sm.OnActivityAppended != nil {
+			sm.OnActivityAppended(site.ID, *ev)
+		}
+		return
+	}
+}
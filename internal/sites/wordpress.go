@@ -234,7 +234,10 @@ func extractTarGz(r io.Reader, destDir string) error {
 			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
 				return fmt.Errorf("mkdir parent %q: %w", target, err)
 			}
-			if err := writeRegularEntry(tr, target); err != nil {
+			// Cap per-file decompression at 256 MiB. WordPress core
+			// tarballs are ~30 MiB; a hostile gzip claiming to be
+			// WordPress shouldn't get to fill the disk.
+			if err := writeRegularEntry(tr, target, wordpressMaxArchiveSize); err != nil {
 				return err
 			}
 		case tar.TypeSymlink, tar.TypeLink, tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
@@ -253,10 +256,11 @@ func extractTarGz(r io.Reader, destDir string) error {
 }
 
 // writeRegularEntry writes a tar regular-file body to target with mode
-// 0o644. O_EXCL refuses to follow a pre-existing symlink at the path; on
-// platforms supporting it we additionally pass O_NOFOLLOW so a malicious
-// dangling symlink cannot redirect the write outside destDir.
-func writeRegularEntry(tr *tar.Reader, target string) error {
+// 0o644, truncated at limit bytes when limit is positive. O_EXCL refuses
+// to follow a pre-existing symlink at the path; on platforms supporting
+// it we additionally pass O_NOFOLLOW so a malicious dangling symlink
+// cannot redirect the write outside destDir.
+func writeRegularEntry(tr *tar.Reader, target string, limit int64) error {
 	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC | os.O_EXCL | extraOpenFlags()
 	f, err := os.OpenFile(target, flags, 0o644)
 	if err != nil {
@@ -278,10 +282,11 @@ func writeRegularEntry(tr *tar.Reader, target string) error {
 	}
 	defer f.Close()
 
-	// Cap per-file decompression at 256 MiB. WordPress core tarballs
-	// are ~30 MiB; a hostile gzip claiming to be WordPress shouldn't
-	// get to fill the disk.
-	if _, err := io.Copy(f, io.LimitReader(tr, wordpressMaxArchiveSize)); err != nil {
+	var body io.Reader = tr
+	if limit > 0 {
+		body = io.LimitReader(tr, limit)
+	}
+	if _, err := io.Copy(f, body); err != nil {
 		return fmt.Errorf("write %q: %w", target, err)
 	}
 	return nil
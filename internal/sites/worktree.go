@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gosimple/slug"
 
+	"github.com/PeterBooker/locorum/internal/cachebackend"
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/git"
 	"github.com/PeterBooker/locorum/internal/secrets"
@@ -49,13 +50,14 @@ type CreateWorktreeOptions struct {
 	// AGENTS-SUPPORT plan.
 	CloneDB bool
 
-	// PHPVersion / DBVersion / DBEngine / RedisVersion, when set,
-	// override the parent site's runtime versions. Empty falls back
-	// to the parent or, when no parent, to engine defaults.
+	// PHPVersion / DBVersion / DBEngine / CacheBackend / CacheVersion,
+	// when set, override the parent site's runtime versions. Empty falls
+	// back to the parent or, when no parent, to engine defaults.
 	PHPVersion   string
 	DBEngine     string
 	DBVersion    string
-	RedisVersion string
+	CacheBackend string
+	CacheVersion string
 
 	// WorktreeRoot, when set, overrides the default placement
 	// (~/locorum/worktrees/<parent-slug>/<branch-slug>). Useful when
@@ -159,12 +161,18 @@ func (sm *SiteManager) CreateWorktreeSite(ctx context.Context, opts CreateWorktr
 		PHPVersion:   firstNonEmpty(opts.PHPVersion, parent.PHPVersion),
 		DBEngine:     firstNonEmpty(opts.DBEngine, parent.DBEngine),
 		DBVersion:    firstNonEmpty(opts.DBVersion, parent.DBVersion),
-		RedisVersion: firstNonEmpty(opts.RedisVersion, parent.RedisVersion),
+		CacheBackend: firstNonEmpty(opts.CacheBackend, parent.CacheBackend),
+		CacheVersion: firstNonEmpty(opts.CacheVersion, parent.CacheVersion),
 		DBPassword:   dbPassword,
 		GitRemote:    opts.GitRemote,
 		GitBranch:    opts.Branch,
 		WorktreePath: worktreePath,
 		ParentSiteID: parent.ID,
+		Resources:    parent.Resources,
+		PHPIni:       parent.PHPIni,
+
+		PHPExtensionsEnable:  parent.PHPExtensionsEnable,
+		PHPExtensionsDisable: parent.PHPExtensionsDisable,
 	}
 	if newSite.DBEngine == "" {
 		newSite.DBEngine = string(dbengine.Default)
@@ -172,6 +180,17 @@ func (sm *SiteManager) CreateWorktreeSite(ctx context.Context, opts CreateWorktr
 	if newSite.DBVersion == "" {
 		newSite.DBVersion = dbengine.MustFor(dbengine.Kind(newSite.DBEngine)).DefaultVersion()
 	}
+	if newSite.CacheBackend == "" {
+		newSite.CacheBackend = string(cachebackend.Default)
+	}
+	if !cachebackend.IsValid(cachebackend.Kind(newSite.CacheBackend)) {
+		return nil, fmt.Errorf("unknown cache backend %q", newSite.CacheBackend)
+	}
+	// A backend override without a version must not inherit the
+	// parent's tag for a different backend.
+	if opts.CacheVersion == "" && newSite.CacheBackend != parent.CacheBackend {
+		newSite.CacheVersion = cachebackend.DefaultVersion(cachebackend.Kind(newSite.CacheBackend))
+	}
 	if newSite.WebServer == "" {
 		newSite.WebServer = "nginx"
 	}
@@ -321,7 +340,8 @@ func (sm *SiteManager) resolveOrCreateParent(opts CreateWorktreeOptions) (types.
 		// care about the parent's PHP version because they'll start
 		// the worktree separately.
 		PHPVersion:   "8.4",
-		RedisVersion: "8.0",
+		CacheBackend: string(cachebackend.Default),
+		CacheVersion: cachebackend.DefaultVersion(cachebackend.Default),
 		DBPassword:   dbPassword,
 	}
 	if err := sm.st.AddSite(&parent); err != nil {
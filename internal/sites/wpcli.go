@@ -49,7 +49,11 @@ func (sm *SiteManager) wpcli(ctx context.Context, site *types.Site, args ...stri
 	cmd = append(cmd, "wp")
 	cmd = append(cmd, args...)
 	cmd = append(cmd, "--path="+inContainerWPPath(site))
-	out, err := sm.d.ExecInContainer(ctx, docker.SiteContainerName(site.Slug, "php"), cmd)
+	exec := sm.wpExec
+	if exec == nil {
+		exec = sm.d.ExecInContainer
+	}
+	out, err := exec(ctx, docker.SiteContainerName(site.Slug, "php"), cmd)
 	if err != nil {
 		return out, fmt.Errorf("wp %s: %w", args[0], err)
 	}
@@ -124,7 +128,8 @@ func (sm *SiteManager) wpSearchReplace(ctx context.Context, site *types.Site, fr
 	)
 }
 
-// wpDBImport runs `wp db import <inContainerPath>`. The caller is
+// wpDBImport runs `wp db import <inContainerPath>` (or the SQLite
+// replay, via importSQLFile). The caller is
 // responsible for placing the dump where wp-cli can read it (typically
 // inside the bind-mounted FilesDir).
 //
@@ -143,7 +148,7 @@ func (sm *SiteManager) wpDBImport(ctx context.Context, site *types.Site, inConta
 			slog.Info("pre-db-import auto-snapshot saved", "path", path)
 		}
 	}
-	return sm.wpcli(ctx, site, "db", "import", inContainerPath)
+	return sm.importSQLFile(ctx, site, inContainerPath)
 }
 
 // wpOptionGet returns the value of a WordPress option (e.g. "siteurl",
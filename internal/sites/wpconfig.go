@@ -14,6 +14,9 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/PeterBooker/locorum/internal/cachebackend"
+	"github.com/PeterBooker/locorum/internal/dbengine"
+	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/genmark"
 	"github.com/PeterBooker/locorum/internal/types"
 )
@@ -90,7 +93,7 @@ func phpSingleQuoteEscape(s string) string {
 // is_ssl() flipped the scheme. The file is regenerated on every site start
 // anyway, so baking the URL in costs nothing.
 //
-// PrimaryHost, LANHostRegex, and DocrootSuffix exist to support
+// PrimaryHost, LANHostRegex, AliasRegex, and DocrootSuffix exist to support
 // multi-domain access (ACCESS.md): the rendered wp-config-locorum.php
 // dynamically maps `$_SERVER['HTTP_HOST']` onto WP_HOME/WP_SITEURL at
 // request time, instead of pinning a single canonical URL. Without
@@ -108,7 +111,22 @@ type wpConfigData struct {
 	WPSiteURL     string
 	PrimaryHost   string
 	LANHostRegex  string // PHP regex incl. delimiters, "" when LAN access disabled / unsupported
+	AliasRegex    string // PHP regex incl. delimiters matching the site's aliases, "" when none
 	DocrootSuffix string // "" or "/web" — appended to WP_HOME to form WP_SITEURL
+
+	// SQLite switches the credentials block to the SQLite drop-in's
+	// DB_DIR / DB_FILE constants. SQLiteDir and SQLiteFile are only read
+	// in that branch.
+	SQLite     bool
+	SQLiteDir  string
+	SQLiteFile string
+
+	// CacheBackend selects the object cache block: "redis" and "valkey"
+	// emit WP_REDIS_* constants, "memcached" emits $memcached_servers,
+	// "none" emits nothing. CacheHost is the container's network alias.
+	CacheBackend string
+	CacheHost    string
+	CachePort    int
 }
 
 // computeWPURLs derives WP_HOME and WP_SITEURL from the site's domain and
@@ -174,6 +192,25 @@ func buildLANHostRegex(site *types.Site, lanDomain string) string {
 		regexp.QuoteMeta(domain) + "$/"
 }
 
+// buildAliasRegex returns the PHP regex (with `/.../` delimiters) that
+// whitelists the site's aliases as request hosts, or "" when it has none.
+// Exact aliases match literally; a wildcard alias matches one label in
+// place of the `*`, as the router does.
+func buildAliasRegex(aliases []string) string {
+	if len(aliases) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(aliases))
+	for _, a := range aliases {
+		if suffix, ok := strings.CutPrefix(a, "*."); ok {
+			parts = append(parts, "[a-z0-9-]+\\."+regexp.QuoteMeta(suffix))
+			continue
+		}
+		parts = append(parts, regexp.QuoteMeta(a))
+	}
+	return "/^(?:" + strings.Join(parts, "|") + ")$/"
+}
+
 // wpDocrootDir resolves the on-disk directory that should contain
 // wp-config.php — the bind-mount root unless the site has an explicit
 // PublicDir subdirectory (e.g. a Bedrock-style "web" docroot).
@@ -215,6 +252,7 @@ func (sm *SiteManager) EnsureWPConfig(site *types.Site) error {
 	if sm.cfg != nil {
 		lanDomain = sm.cfg.LanDomain()
 	}
+	cache := cachebackend.Resolve(site)
 	data := wpConfigData{
 		Salts:         salts,
 		DBPassword:    site.DBPassword,
@@ -224,7 +262,14 @@ func (sm *SiteManager) EnsureWPConfig(site *types.Site) error {
 		WPSiteURL:     wpSiteURL,
 		PrimaryHost:   strings.ToLower(site.Domain),
 		LANHostRegex:  buildLANHostRegex(site, lanDomain),
+		AliasRegex:    buildAliasRegex(site.Aliases),
 		DocrootSuffix: docrootSuffix(site),
+		SQLite:        dbengine.Kind(site.DBEngine) == dbengine.SQLite,
+		SQLiteDir:     docker.SQLiteDataDir,
+		SQLiteFile:    dbengine.SQLiteFile,
+		CacheBackend:  string(cache),
+		CacheHost:     cachebackend.Service(cache),
+		CachePort:     cachebackend.Port(cache),
 	}
 
 	dir := wpDocrootDir(site)
@@ -297,21 +342,30 @@ func (sm *SiteManager) ensureSalts(site *types.Site) error {
 		slog.Warn("site salts JSON is invalid, regenerating", "site", site.Slug)
 	}
 
-	salts, err := generateSalts()
+	encoded, err := newSaltsJSON()
 	if err != nil {
 		return err
 	}
-	encoded, err := json.Marshal(salts)
-	if err != nil {
-		return fmt.Errorf("encode salts: %w", err)
-	}
-	site.Salts = string(encoded)
+	site.Salts = encoded
 	if _, err := sm.st.UpdateSite(site); err != nil {
 		return fmt.Errorf("persist salts: %w", err)
 	}
 	return nil
 }
 
+// newSaltsJSON generates a fresh salt set in the Site.Salts encoding.
+func newSaltsJSON() (string, error) {
+	salts, err := generateSalts()
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(salts)
+	if err != nil {
+		return "", fmt.Errorf("encode salts: %w", err)
+	}
+	return string(encoded), nil
+}
+
 // decodeSalts unmarshals the JSON blob from Site.Salts and validates that
 // every required key is present. Missing keys → error; the caller treats
 // that as "regenerate".
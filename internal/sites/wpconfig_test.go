@@ -374,6 +374,81 @@ func TestEmbeddedWPConfigOmitsMultisiteWhenDisabled(t *testing.T) {
 	}
 }
 
+func TestEmbeddedWPConfigSQLiteCredentials(t *testing.T) {
+	wd, _ := os.Getwd()
+	efs := fileFS{root: filepath.Clean(filepath.Join(wd, "..", ".."))}
+	salts, _ := generateSalts()
+	data := wpConfigData{
+		Salts: salts, DBPassword: "secret", Domain: "x.localhost",
+		SQLite: true, SQLiteDir: "/var/lib/sqlite", SQLiteFile: ".ht.sqlite",
+	}
+
+	out, err := renderTemplate(efs, "config/wordpress/wp-config-locorum.tmpl.php", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"define( 'DB_ENGINE',   'sqlite' )",
+		"define( 'DB_DIR',      '/var/lib/sqlite/' )",
+		"define( 'DB_FILE',     '.ht.sqlite' )",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("missing %q\n%s", want, out)
+		}
+	}
+	for _, reject := range []string{"MYSQL_PASSWORD", "secret", "'database'"} {
+		if strings.Contains(string(out), reject) {
+			t.Errorf("SQLite render should not contain %q", reject)
+		}
+	}
+}
+
+func TestEmbeddedWPConfigCacheBlock(t *testing.T) {
+	wd, _ := os.Getwd()
+	efs := fileFS{root: filepath.Clean(filepath.Join(wd, "..", ".."))}
+	salts, _ := generateSalts()
+	cases := []struct {
+		backend, host string
+		port          int
+		want, reject  []string
+	}{
+		{"redis", "redis", 6379,
+			[]string{"define( 'WP_REDIS_HOST', 'redis' )", "define( 'WP_REDIS_PORT', 6379 )"},
+			[]string{"$memcached_servers"}},
+		{"valkey", "valkey", 6379,
+			[]string{"define( 'WP_REDIS_HOST', 'valkey' )"},
+			[]string{"$memcached_servers"}},
+		{"memcached", "memcached", 11211,
+			[]string{"$memcached_servers = array( array( 'memcached', 11211 ) );"},
+			[]string{"WP_REDIS_HOST"}},
+		{"none", "", 0,
+			nil,
+			[]string{"WP_REDIS_HOST", "$memcached_servers"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.backend, func(t *testing.T) {
+			data := wpConfigData{
+				Salts: salts, DBPassword: "secret", Domain: "x.localhost",
+				CacheBackend: tc.backend, CacheHost: tc.host, CachePort: tc.port,
+			}
+			out, err := renderTemplate(efs, "config/wordpress/wp-config-locorum.tmpl.php", data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("missing %q\n%s", want, out)
+				}
+			}
+			for _, reject := range tc.reject {
+				if strings.Contains(string(out), reject) {
+					t.Errorf("%s render should not contain %q", tc.backend, reject)
+				}
+			}
+		})
+	}
+}
+
 func TestWpDocrootDir(t *testing.T) {
 	cases := []struct {
 		filesDir, publicDir string
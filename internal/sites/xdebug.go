@@ -0,0 +1,99 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// XdebugModeOff is the selector value that disables Xdebug. It is never
+// persisted as Site.XdebugMode — the toggle lives in XdebugEnabled and
+// the last real mode is kept for the next enable.
+const XdebugModeOff = "off"
+
+// XdebugModes lists the selectable Xdebug modes in display order. Each
+// maps 1:1 onto an xdebug.mode value.
+var XdebugModes = []string{XdebugModeOff, "debug", "profile", "trace", "coverage"}
+
+// ValidXdebugMode reports whether mode is one of XdebugModes.
+func ValidXdebugMode(mode string) bool {
+	for _, m := range XdebugModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// SetXdebugMode switches Xdebug for siteID to mode. "off" disables the
+// extension but keeps the stored mode; any other value enables it.
+// Site must be stopped: toggling changes the PHP container's mounts,
+// and a mode switch only lands when the next start rewrites the INI.
+//
+// Emits OnSiteUpdated on success so the GUI redraws.
+func (sm *SiteManager) SetXdebugMode(siteID, mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if !ValidXdebugMode(mode) {
+		return fmt.Errorf("invalid xdebug mode %q (allowed: %s)", mode, strings.Join(XdebugModes, ", "))
+	}
+
+	site, err := sm.st.GetSite(siteID)
+	if err != nil {
+		return fmt.Errorf("fetching site: %w", err)
+	}
+	if site == nil {
+		return fmt.Errorf("site %q not found", siteID)
+	}
+
+	mu := sm.siteMutex(siteID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if site.Started {
+		return errors.New("site must be stopped to change Xdebug mode")
+	}
+
+	enabled := mode != XdebugModeOff
+	if site.XdebugEnabled == enabled && (!enabled || site.XdebugMode == mode) {
+		return nil
+	}
+
+	site.XdebugEnabled = enabled
+	if enabled {
+		site.XdebugMode = mode
+	}
+
+	if _, err := sm.st.UpdateSite(site); err != nil {
+		return fmt.Errorf("updating site: %w", err)
+	}
+
+	sm.writeConfigYAML(site)
+	if sm.OnSiteUpdated != nil {
+		sm.OnSiteUpdated(site)
+	}
+	return nil
+}
+
+// XdebugDebugSites returns the slugs of running sites with Xdebug in
+// step-debug mode — the only mode that dials back to the IDE.
+// Implements health.XdebugSiteLister; errors are logged and swallowed
+// for the same reason as Roots.
+func (sm *SiteManager) XdebugDebugSites(_ context.Context) []string {
+	if sm == nil || sm.st == nil {
+		return nil
+	}
+	rows, err := sm.st.GetSites()
+	if err != nil {
+		slog.Debug("sites: xdebug sites: GetSites failed", "err", err.Error())
+		return nil
+	}
+	var out []string
+	for _, s := range rows {
+		if s.Started && s.XdebugEnabled && s.XdebugMode == "debug" {
+			out = append(out, s.Slug)
+		}
+	}
+	return out
+}
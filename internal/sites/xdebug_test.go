@@ -0,0 +1,79 @@
+package sites
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+func TestSetXdebugMode_EnableAndDisableKeepsMode(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := spxTestSite(t.TempDir())
+	if err := sm.st.AddSite(&site); err != nil {
+		t.Fatalf("AddSite: %v", err)
+	}
+
+	var updated *types.Site
+	sm.OnSiteUpdated = func(s *types.Site) { updated = s }
+
+	if err := sm.SetXdebugMode(site.ID, "profile"); err != nil {
+		t.Fatalf("SetXdebugMode(profile): %v", err)
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if !got.XdebugEnabled || got.XdebugMode != "profile" {
+		t.Errorf("after enable: enabled=%v mode=%q", got.XdebugEnabled, got.XdebugMode)
+	}
+	if updated == nil || !updated.XdebugEnabled {
+		t.Error("OnSiteUpdated callback not fired with the new state")
+	}
+
+	if err := sm.SetXdebugMode(site.ID, XdebugModeOff); err != nil {
+		t.Fatalf("SetXdebugMode(off): %v", err)
+	}
+	got, _ = sm.st.GetSite(site.ID)
+	if got.XdebugEnabled {
+		t.Error("XdebugEnabled still true after off")
+	}
+	if got.XdebugMode != "profile" {
+		t.Errorf("XdebugMode lost on disable: %q", got.XdebugMode)
+	}
+}
+
+func TestSetXdebugMode_RejectsInvalidAndRunning(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	site := spxTestSite(t.TempDir())
+	site.Started = true
+	if err := sm.st.AddSite(&site); err != nil {
+		t.Fatalf("AddSite: %v", err)
+	}
+	if err := sm.SetXdebugMode(site.ID, "develop"); err == nil {
+		t.Error("expected error for unknown mode, got nil")
+	}
+	if err := sm.SetXdebugMode(site.ID, "debug"); err == nil {
+		t.Error("expected error changing Xdebug on a running site, got nil")
+	}
+	got, _ := sm.st.GetSite(site.ID)
+	if got.XdebugEnabled {
+		t.Error("XdebugEnabled flipped on a running site despite the rejection")
+	}
+}
+
+func TestXdebugDebugSites_OnlyRunningDebugMode(t *testing.T) {
+	sm := newSPXSiteManager(t)
+	rows := []types.Site{
+		{ID: "a", Slug: "a", Started: true, XdebugEnabled: true, XdebugMode: "debug"},
+		{ID: "b", Slug: "b", Started: false, XdebugEnabled: true, XdebugMode: "debug"},
+		{ID: "c", Slug: "c", Started: true, XdebugEnabled: true, XdebugMode: "profile"},
+		{ID: "d", Slug: "d", Started: true, XdebugEnabled: false, XdebugMode: "debug"},
+	}
+	for i := range rows {
+		if err := sm.st.AddSite(&rows[i]); err != nil {
+			t.Fatalf("AddSite: %v", err)
+		}
+	}
+	got := sm.XdebugDebugSites(context.Background())
+	if len(got) != 1 || got[0] != "a" {
+		t.Errorf("XdebugDebugSites = %v, want [a]", got)
+	}
+}
@@ -24,6 +24,8 @@ const (
 	ActivityKindImportDB  ActivityKind = "import-db"
 	ActivityKindSnapshot  ActivityKind = "snapshot"
 	ActivityKindRestore   ActivityKind = "restore-snapshot"
+	ActivityKindOOM       ActivityKind = "oom"
+	ActivityKindCall      ActivityKind = "call"
 	ActivityKindOther     ActivityKind = "other"
 )
 
@@ -42,6 +44,8 @@ func (k ActivityKind) Valid() bool {
 		ActivityKindImportDB,
 		ActivityKindSnapshot,
 		ActivityKindRestore,
+		ActivityKindOOM,
+		ActivityKindCall,
 		ActivityKindOther:
 		return true
 	}
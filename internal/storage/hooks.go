@@ -167,6 +167,50 @@ func (s *Storage) DeleteHooksForSite(siteID string) error {
 	return nil
 }
 
+// ReplaceHooks atomically swaps every hook of siteID for list. Positions
+// follow list order within each event. Nothing is written if any hook
+// fails validation or an insert fails.
+func (s *Storage) ReplaceHooks(siteID string, list []hooks.Hook) error {
+	for i := range list {
+		if list[i].SiteID != siteID {
+			return fmt.Errorf("ReplaceHooks: hook for site %q in list for %q", list[i].SiteID, siteID)
+		}
+		if err := list[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("ReplaceHooks: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM site_hooks WHERE site_id = ?", siteID); err != nil {
+		return fmt.Errorf("ReplaceHooks: delete: %w", err)
+	}
+	ts := now()
+	next := make(map[hooks.Event]int)
+	for i := range list {
+		h := &list[i]
+		h.Position = next[h.Event]
+		next[h.Event]++
+		h.CreatedAt, h.UpdatedAt = ts, ts
+		res, err := tx.Exec(
+			"INSERT INTO site_hooks (site_id, event, position, task_type, command, service, run_as_user, enabled, created_at, updated_at)"+
+				" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			h.SiteID, string(h.Event), h.Position, string(h.TaskType), h.Command, h.Service, h.RunAsUser, boolToInt(h.Enabled), h.CreatedAt, h.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("ReplaceHooks: insert: %w", err)
+		}
+		if h.ID, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("ReplaceHooks: last insert id: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
 // ReorderHooks atomically rewrites positions for the given event so they
 // match the supplied id order (first id → position 0, second → 1, …).
 // Hooks with ids not in the list are left untouched, but their positions
@@ -268,3 +268,39 @@ func TestGetHook_NotFound(t *testing.T) {
 		t.Errorf("err = %v, want ErrHookNotFound", err)
 	}
 }
+
+func TestReplaceHooks_AllOrNothing(t *testing.T) {
+	st := newStorage(t)
+	seedSite(t, st, "site-a")
+	if err := st.AddHook(newHook("site-a", hooks.PostStart, "old")); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := []hooks.Hook{*newHook("site-a", hooks.PostStart, "new"), *newHook("site-a", hooks.PostStart, "")}
+	if err := st.ReplaceHooks("site-a", bad); err == nil {
+		t.Fatal("ReplaceHooks accepted an invalid hook")
+	}
+	if got, _ := st.ListHooks("site-a"); len(got) != 1 || got[0].Command != "old" {
+		t.Fatalf("failed replace changed hooks: %+v", got)
+	}
+
+	good := []hooks.Hook{
+		*newHook("site-a", hooks.PostStart, "one"),
+		*newHook("site-a", hooks.PreStop, "two"),
+		*newHook("site-a", hooks.PostStart, "three"),
+	}
+	if err := st.ReplaceHooks("site-a", good); err != nil {
+		t.Fatalf("ReplaceHooks: %v", err)
+	}
+	got, _ := st.ListHooks("site-a")
+	if len(got) != 3 {
+		t.Fatalf("hooks = %+v", got)
+	}
+	pos := map[string]int{}
+	for _, h := range got {
+		pos[h.Command] = h.Position
+	}
+	if pos["one"] != 0 || pos["three"] != 1 || pos["two"] != 0 {
+		t.Errorf("positions = %v", pos)
+	}
+}
@@ -0,0 +1,14 @@
+-- SQLite's DROP COLUMN is unreliable across the supported version
+-- range; recreate the table without the xdebug columns. Mirrors the
+-- pattern used by 20260508000001_add_lan_access.down.sql.
+CREATE TABLE sites_backup AS
+  SELECT id, name, slug, domain, filesDir, publicDir, started,
+         phpVersion, mysqlVersion, redisVersion, dbPassword,
+         webServer, multisite, salts, dbEngine, dbVersion,
+         publishDBPort, spxEnabled, spxKey, lanEnabled,
+         gitRemote, gitBranch, worktreePath, parentSiteID,
+         createdAt, updatedAt
+  FROM sites;
+DROP TABLE sites;
+ALTER TABLE sites_backup RENAME TO sites;
+CREATE INDEX IF NOT EXISTS idx_sites_parent ON sites(parentSiteID) WHERE parentSiteID != '';
@@ -0,0 +1,8 @@
+-- Per-site Xdebug toggle + mode selector. xdebugMode is retained when
+-- the toggle is switched off so the UI can offer the last-used mode on
+-- re-enable (mirrors how spxKey survives toggles).
+--
+-- Defaults keep existing rows identical: Xdebug stays unloaded until
+-- the user opts in.
+ALTER TABLE sites ADD COLUMN xdebugEnabled INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sites ADD COLUMN xdebugMode TEXT NOT NULL DEFAULT '';
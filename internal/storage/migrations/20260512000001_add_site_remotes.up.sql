@@ -0,0 +1,16 @@
+CREATE TABLE site_remotes (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    site_id     TEXT    NOT NULL REFERENCES sites(id) ON DELETE CASCADE,
+    name        TEXT    NOT NULL,
+    host        TEXT    NOT NULL,
+    port        INTEGER NOT NULL DEFAULT 0,
+    user        TEXT    NOT NULL,
+    path        TEXT    NOT NULL,
+    key_path    TEXT    NOT NULL DEFAULT '',
+    password    TEXT    NOT NULL DEFAULT '',
+    host_key    TEXT    NOT NULL DEFAULT '',
+    db_command  TEXT    NOT NULL DEFAULT '',
+    created_at  TEXT    NOT NULL,
+    updated_at  TEXT    NOT NULL,
+    UNIQUE(site_id, name)
+);
@@ -0,0 +1,25 @@
+-- SQLite's DROP COLUMN is unreliable across the supported version
+-- range; rebuild site_remotes without the production flag. Spelled out
+-- in full (rather than CREATE TABLE AS) to keep the FK and UNIQUE.
+CREATE TABLE site_remotes_backup (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    site_id     TEXT    NOT NULL REFERENCES sites(id) ON DELETE CASCADE,
+    name        TEXT    NOT NULL,
+    host        TEXT    NOT NULL,
+    port        INTEGER NOT NULL DEFAULT 0,
+    user        TEXT    NOT NULL,
+    path        TEXT    NOT NULL,
+    key_path    TEXT    NOT NULL DEFAULT '',
+    password    TEXT    NOT NULL DEFAULT '',
+    host_key    TEXT    NOT NULL DEFAULT '',
+    db_command  TEXT    NOT NULL DEFAULT '',
+    created_at  TEXT    NOT NULL,
+    updated_at  TEXT    NOT NULL,
+    UNIQUE(site_id, name)
+);
+INSERT INTO site_remotes_backup
+  SELECT id, site_id, name, host, port, user, path, key_path, password,
+         host_key, db_command, created_at, updated_at
+  FROM site_remotes;
+DROP TABLE site_remotes;
+ALTER TABLE site_remotes_backup RENAME TO site_remotes;
@@ -0,0 +1,2 @@
+-- Remotes flagged production never accept a push; pull is unaffected.
+ALTER TABLE site_remotes ADD COLUMN production INTEGER NOT NULL DEFAULT 0;
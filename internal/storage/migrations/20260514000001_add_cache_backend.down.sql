@@ -0,0 +1,5 @@
+-- site_remotes references sites(id), so the CREATE TABLE AS rebuild used
+-- by earlier down migrations would leave that FK pointing at a table with
+-- no key. The bundled SQLite supports DROP COLUMN; use it directly.
+ALTER TABLE sites DROP COLUMN cacheVersion;
+ALTER TABLE sites DROP COLUMN cacheBackend;
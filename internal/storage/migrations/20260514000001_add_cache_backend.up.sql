@@ -0,0 +1,6 @@
+-- Per-site object cache choice: introduce cacheBackend + cacheVersion
+-- alongside the legacy redisVersion. Existing rows default to "redis"
+-- and copy redisVersion → cacheVersion so they start exactly as before.
+ALTER TABLE sites ADD COLUMN cacheBackend TEXT NOT NULL DEFAULT 'redis';
+ALTER TABLE sites ADD COLUMN cacheVersion TEXT NOT NULL DEFAULT '';
+UPDATE sites SET cacheVersion = COALESCE(NULLIF(cacheVersion, ''), redisVersion);
@@ -0,0 +1,4 @@
+-- Per-site container resource overrides. JSON-encoded
+-- types.ResourceLimits; empty means every container keeps the built-in
+-- per-role caps.
+ALTER TABLE sites ADD COLUMN resourceLimits TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE sites DROP COLUMN phpExtensions;
+ALTER TABLE sites DROP COLUMN phpIni;
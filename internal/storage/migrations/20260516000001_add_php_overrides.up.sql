@@ -0,0 +1,6 @@
+-- Per-site PHP overrides. phpIni is a JSON object of php.ini directives
+-- layered over the shared php.ini; phpExtensions is a JSON object with
+-- "enable" / "disable" lists of wodby/php extensions. Empty means the
+-- site runs with the shared configuration unchanged.
+ALTER TABLE sites ADD COLUMN phpIni TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN phpExtensions TEXT NOT NULL DEFAULT '';
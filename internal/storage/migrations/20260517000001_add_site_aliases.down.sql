@@ -0,0 +1 @@
+ALTER TABLE sites DROP COLUMN aliases;
@@ -0,0 +1,4 @@
+-- Extra hostnames a site answers on besides its domain, as a JSON array
+-- (e.g. ["client-site.test", "*.client-site.test"]). Empty means the
+-- site is only reachable on its domain.
+ALTER TABLE sites ADD COLUMN aliases TEXT NOT NULL DEFAULT '';
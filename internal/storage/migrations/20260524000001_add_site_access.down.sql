@@ -0,0 +1,5 @@
+ALTER TABLE sites DROP COLUMN ipAllowlist;
+ALTER TABLE sites DROP COLUMN authHash;
+ALTER TABLE sites DROP COLUMN authPassword;
+ALTER TABLE sites DROP COLUMN authUser;
+ALTER TABLE sites DROP COLUMN authEnabled;
@@ -0,0 +1,8 @@
+-- Router-level access controls for a site. authPassword / authHash are
+-- generated on first enable and kept when auth is switched off;
+-- ipAllowlist is a JSON array of CIDRs, empty meaning unrestricted.
+ALTER TABLE sites ADD COLUMN authEnabled INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sites ADD COLUMN authUser TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN authPassword TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN authHash TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN ipAllowlist TEXT NOT NULL DEFAULT '';
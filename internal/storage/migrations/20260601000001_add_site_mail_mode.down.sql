@@ -0,0 +1,5 @@
+ALTER TABLE sites DROP COLUMN mailRelayDomains;
+ALTER TABLE sites DROP COLUMN mailRelayPassword;
+ALTER TABLE sites DROP COLUMN mailRelayUser;
+ALTER TABLE sites DROP COLUMN mailRelayHost;
+ALTER TABLE sites DROP COLUMN mailMode;
@@ -0,0 +1,9 @@
+-- Per-site outbound mail handling. mailMode '' means capture (every
+-- message lands in the global mail container). relay forwards mail to
+-- the allowed recipient domains (a JSON array) through the smarthost;
+-- blackhole discards it.
+ALTER TABLE sites ADD COLUMN mailMode TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN mailRelayHost TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN mailRelayUser TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN mailRelayPassword TEXT NOT NULL DEFAULT '';
+ALTER TABLE sites ADD COLUMN mailRelayDomains TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- Named capability tokens for daemon peers. Only the SHA-256 of the
+-- secret is stored. allow, deny and sites are JSON arrays; expires_at
+-- is RFC 3339, '' for a token that never expires.
+CREATE TABLE capability_tokens (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    label        TEXT    NOT NULL UNIQUE,
+    hash         TEXT    NOT NULL UNIQUE,
+    profile      TEXT    NOT NULL,
+    allow        TEXT    NOT NULL,
+    deny         TEXT    NOT NULL DEFAULT '',
+    sites        TEXT    NOT NULL DEFAULT '',
+    expires_at   TEXT    NOT NULL DEFAULT '',
+    created_at   TEXT    NOT NULL,
+    last_used_at TEXT    NOT NULL DEFAULT ''
+);
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/remote"
+)
+
+const remoteColumns = "id, site_id, name, host, port, user, path, key_path, password, host_key, db_command, production, created_at, updated_at"
+
+// ErrRemoteNotFound is returned when no remote matches the lookup.
+var ErrRemoteNotFound = errors.New("remote not found")
+
+// ErrRemoteExists is returned by AddRemote when the site already has a
+// remote with the same name.
+var ErrRemoteExists = errors.New("remote already exists")
+
+// ListRemotes returns every remote for siteID, ordered by name.
+func (s *Storage) ListRemotes(siteID string) ([]remote.Remote, error) {
+	rows, err := s.db.Query(
+		"SELECT "+remoteColumns+" FROM site_remotes WHERE site_id = ? ORDER BY name",
+		siteID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing remotes: %w", err)
+	}
+	defer rows.Close()
+
+	var out []remote.Remote
+	for rows.Next() {
+		r, err := scanRemote(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ListAllRemotes returns every remote across all sites. Used at startup
+// to seed the secrets registry with remote passwords.
+func (s *Storage) ListAllRemotes() ([]remote.Remote, error) {
+	rows, err := s.db.Query("SELECT " + remoteColumns + " FROM site_remotes ORDER BY site_id, name")
+	if err != nil {
+		return nil, fmt.Errorf("listing remotes: %w", err)
+	}
+	defer rows.Close()
+
+	var out []remote.Remote
+	for rows.Next() {
+		r, err := scanRemote(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// GetRemote returns siteID's remote called name, or ErrRemoteNotFound.
+func (s *Storage) GetRemote(siteID, name string) (*remote.Remote, error) {
+	row := s.db.QueryRow(
+		"SELECT "+remoteColumns+" FROM site_remotes WHERE site_id = ? AND name = ?",
+		siteID, name,
+	)
+	r, err := scanRemote(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRemoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// AddRemote validates and inserts r. r.ID and timestamps are populated
+// on success.
+func (s *Storage) AddRemote(r *remote.Remote) error {
+	if r == nil {
+		return errors.New("AddRemote: nil remote")
+	}
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	ts := now()
+	r.CreatedAt = ts
+	r.UpdatedAt = ts
+
+	res, err := s.db.Exec(
+		"INSERT INTO site_remotes (site_id, name, host, port, user, path, key_path, password, host_key, db_command, production, created_at, updated_at)"+
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		r.SiteID, r.Name, r.Host, r.Port, r.User, r.Path, r.KeyPath, r.Password, r.HostKey, r.DBCommand, boolToInt(r.Production), r.CreatedAt, r.UpdatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("%w: %q", ErrRemoteExists, r.Name)
+		}
+		return fmt.Errorf("AddRemote: insert: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("AddRemote: last insert id: %w", err)
+	}
+	r.ID = id
+	return nil
+}
+
+// UpdateRemote persists every mutable field of an existing remote,
+// identified by ID and SiteID.
+func (s *Storage) UpdateRemote(r *remote.Remote) error {
+	if r == nil {
+		return errors.New("UpdateRemote: nil remote")
+	}
+	if r.ID == 0 {
+		return errors.New("UpdateRemote: missing ID")
+	}
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	r.UpdatedAt = now()
+
+	res, err := s.db.Exec(
+		"UPDATE site_remotes SET name = ?, host = ?, port = ?, user = ?, path = ?, key_path = ?, password = ?, host_key = ?, db_command = ?, production = ?, updated_at = ?"+
+			" WHERE id = ? AND site_id = ?",
+		r.Name, r.Host, r.Port, r.User, r.Path, r.KeyPath, r.Password, r.HostKey, r.DBCommand, boolToInt(r.Production), r.UpdatedAt,
+		r.ID, r.SiteID,
+	)
+	if err != nil {
+		return fmt.Errorf("UpdateRemote: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("UpdateRemote: rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrRemoteNotFound
+	}
+	return nil
+}
+
+// DeleteRemote removes siteID's remote called name. Returns
+// ErrRemoteNotFound when nothing matched.
+func (s *Storage) DeleteRemote(siteID, name string) error {
+	res, err := s.db.Exec("DELETE FROM site_remotes WHERE site_id = ? AND name = ?", siteID, name)
+	if err != nil {
+		return fmt.Errorf("DeleteRemote: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("DeleteRemote: rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrRemoteNotFound
+	}
+	return nil
+}
+
+func scanRemote(s hookScanner) (remote.Remote, error) {
+	var (
+		r          remote.Remote
+		production int
+	)
+	if err := s.Scan(
+		&r.ID, &r.SiteID, &r.Name, &r.Host, &r.Port, &r.User, &r.Path,
+		&r.KeyPath, &r.Password, &r.HostKey, &r.DBCommand, &production, &r.CreatedAt, &r.UpdatedAt,
+	); err != nil {
+		return remote.Remote{}, err
+	}
+	r.Production = production != 0
+	return r, nil
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/remote"
+)
+
+func newRemote(siteID, name string) *remote.Remote {
+	return &remote.Remote{
+		SiteID: siteID, Name: name, Host: "staging.example.com",
+		User: "deploy", Path: "/srv/www", Password: "remote-secret",
+	}
+}
+
+func TestRemotes_CRUD(t *testing.T) {
+	st := newStorage(t)
+	seedSite(t, st, "site-a")
+
+	r := newRemote("site-a", "staging")
+	if err := st.AddRemote(r); err != nil {
+		t.Fatalf("AddRemote: %v", err)
+	}
+	if r.ID == 0 || r.CreatedAt == "" {
+		t.Errorf("AddRemote did not populate ID/timestamps: %+v", r)
+	}
+	if err := st.AddRemote(newRemote("site-a", "staging")); !errors.Is(err, ErrRemoteExists) {
+		t.Errorf("duplicate AddRemote = %v, want ErrRemoteExists", err)
+	}
+
+	r.HostKey = "SHA256:abc"
+	r.Production = true
+	if err := st.UpdateRemote(r); err != nil {
+		t.Fatalf("UpdateRemote: %v", err)
+	}
+	got, err := st.GetRemote("site-a", "staging")
+	if err != nil {
+		t.Fatalf("GetRemote: %v", err)
+	}
+	if got.HostKey != "SHA256:abc" || got.Password != "remote-secret" || !got.Production {
+		t.Errorf("GetRemote = %+v", got)
+	}
+
+	if err := st.DeleteRemote("site-a", "staging"); err != nil {
+		t.Fatalf("DeleteRemote: %v", err)
+	}
+	if _, err := st.GetRemote("site-a", "staging"); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("GetRemote after delete = %v, want ErrRemoteNotFound", err)
+	}
+	if err := st.DeleteRemote("site-a", "staging"); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("second DeleteRemote = %v, want ErrRemoteNotFound", err)
+	}
+}
+
+func TestRemotes_CascadeOnSiteDelete(t *testing.T) {
+	st := newStorage(t)
+	seedSite(t, st, "site-a")
+	if err := st.AddRemote(newRemote("site-a", "staging")); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.DeleteSite("site-a"); err != nil {
+		t.Fatal(err)
+	}
+	all, err := st.ListAllRemotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Errorf("remotes survived site delete: %+v", all)
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -122,13 +123,14 @@ func now() string {
 // Keep ordering aligned with the Scan / Exec arg order below — adding a
 // column means editing four call sites; the constant centralises the
 // SELECT/INSERT lists so two of those four stay in lockstep.
-const siteColumns = "id, name, slug, domain, filesDir, publicDir, started, phpVersion, mysqlVersion, redisVersion, dbPassword, webServer, multisite, salts, dbEngine, dbVersion, publishDBPort, spxEnabled, spxKey, lanEnabled, gitRemote, gitBranch, worktreePath, parentSiteID, createdAt, updatedAt"
+const siteColumns = "id, name, slug, domain, filesDir, publicDir, started, phpVersion, mysqlVersion, redisVersion, dbPassword, webServer, multisite, salts, dbEngine, dbVersion, publishDBPort, spxEnabled, spxKey, lanEnabled, xdebugEnabled, xdebugMode, gitRemote, gitBranch, worktreePath, parentSiteID, cacheBackend, cacheVersion, resourceLimits, phpIni, phpExtensions, aliases, authEnabled, authUser, authPassword, authHash, ipAllowlist, mailMode, mailRelayHost, mailRelayUser, mailRelayPassword, mailRelayDomains, createdAt, updatedAt"
 
 // scanSite hydrates a Site from a row scanner. Centralised so GetSite and
 // GetSites stay in lockstep with siteColumns; a missed field here means
 // every caller is half-broken.
 func scanSite(scan func(...any) error) (*types.Site, error) {
 	var site types.Site
+	var resources, phpIni, phpExt, aliases, allowlist, relayDomains string
 	if err := scan(
 		&site.ID, &site.Name, &site.Slug, &site.Domain,
 		&site.FilesDir, &site.PublicDir, &site.Started,
@@ -138,12 +140,41 @@ func scanSite(scan func(...any) error) (*types.Site, error) {
 		&site.DBEngine, &site.DBVersion, &site.PublishDBPort,
 		&site.SPXEnabled, &site.SPXKey,
 		&site.LanEnabled,
+		&site.XdebugEnabled, &site.XdebugMode,
 		&site.GitRemote, &site.GitBranch, &site.WorktreePath, &site.ParentSiteID,
+		&site.CacheBackend, &site.CacheVersion,
+		&resources, &phpIni, &phpExt, &aliases,
+		&site.AuthEnabled, &site.AuthUser, &site.AuthPassword, &site.AuthHash, &allowlist,
+		&site.MailMode, &site.MailRelayHost, &site.MailRelayUser, &site.MailRelayPassword, &relayDomains,
 		&site.CreatedAt, &site.UpdatedAt,
 	); err != nil {
 		return nil, err
 	}
+	if resources != "" {
+		if err := json.Unmarshal([]byte(resources), &site.Resources); err != nil {
+			return nil, fmt.Errorf("site %s: decoding resourceLimits: %w", site.ID, err)
+		}
+	}
+	if err := decodePHPOverrides(&site, phpIni, phpExt); err != nil {
+		return nil, err
+	}
+	if aliases != "" {
+		if err := json.Unmarshal([]byte(aliases), &site.Aliases); err != nil {
+			return nil, fmt.Errorf("site %s: decoding aliases: %w", site.ID, err)
+		}
+	}
+	if allowlist != "" {
+		if err := json.Unmarshal([]byte(allowlist), &site.IPAllowlist); err != nil {
+			return nil, fmt.Errorf("site %s: decoding ipAllowlist: %w", site.ID, err)
+		}
+	}
+	if relayDomains != "" {
+		if err := json.Unmarshal([]byte(relayDomains), &site.MailRelayDomains); err != nil {
+			return nil, fmt.Errorf("site %s: decoding mailRelayDomains: %w", site.ID, err)
+		}
+	}
 	hydrateLegacyDBFields(&site)
+	hydrateLegacyCacheFields(&site)
 	return &site, nil
 }
 
@@ -161,6 +192,103 @@ func hydrateLegacyDBFields(site *types.Site) {
 	}
 }
 
+// hydrateLegacyCacheFields fills CacheBackend / CacheVersion for rows
+// that only carry redisVersion — the cache-backend counterpart of
+// hydrateLegacyDBFields.
+func hydrateLegacyCacheFields(site *types.Site) {
+	if site.CacheBackend == "" {
+		site.CacheBackend = "redis"
+	}
+	if site.CacheVersion == "" && site.CacheBackend == "redis" {
+		site.CacheVersion = site.RedisVersion //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+	}
+}
+
+// mirrorLegacyCacheFields keeps the redisVersion column in step with
+// CacheVersion while the site is on Redis. Other backends clear it so a
+// stale Redis tag never outlives a backend switch.
+func mirrorLegacyCacheFields(site *types.Site) {
+	if site.CacheBackend == "redis" {
+		site.RedisVersion = site.CacheVersion //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+	} else {
+		site.RedisVersion = "" //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+	}
+}
+
+// encodeResourceLimits serialises the per-site resource overrides for the
+// resourceLimits column. No overrides is stored as "" rather than "null"
+// or "{}" so the common case reads back as a nil map.
+func encodeResourceLimits(limits types.ResourceLimits) (string, error) {
+	if len(limits) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(limits)
+	if err != nil {
+		return "", fmt.Errorf("encoding resourceLimits: %w", err)
+	}
+	return string(b), nil
+}
+
+// phpExtensionsColumn is the JSON shape of the phpExtensions column.
+type phpExtensionsColumn struct {
+	Enable  []string `json:"enable,omitempty"`
+	Disable []string `json:"disable,omitempty"`
+}
+
+// encodePHPOverrides serialises the per-site php.ini directives and
+// extension lists for the phpIni / phpExtensions columns. Like
+// encodeResourceLimits, "no overrides" is stored as "".
+func encodePHPOverrides(site *types.Site) (ini, ext string, err error) {
+	if len(site.PHPIni) > 0 {
+		b, err := json.Marshal(site.PHPIni)
+		if err != nil {
+			return "", "", fmt.Errorf("encoding phpIni: %w", err)
+		}
+		ini = string(b)
+	}
+	if len(site.PHPExtensionsEnable) > 0 || len(site.PHPExtensionsDisable) > 0 {
+		b, err := json.Marshal(phpExtensionsColumn{
+			Enable:  site.PHPExtensionsEnable,
+			Disable: site.PHPExtensionsDisable,
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("encoding phpExtensions: %w", err)
+		}
+		ext = string(b)
+	}
+	return ini, ext, nil
+}
+
+func decodePHPOverrides(site *types.Site, ini, ext string) error {
+	if ini != "" {
+		if err := json.Unmarshal([]byte(ini), &site.PHPIni); err != nil {
+			return fmt.Errorf("site %s: decoding phpIni: %w", site.ID, err)
+		}
+	}
+	if ext != "" {
+		var col phpExtensionsColumn
+		if err := json.Unmarshal([]byte(ext), &col); err != nil {
+			return fmt.Errorf("site %s: decoding phpExtensions: %w", site.ID, err)
+		}
+		site.PHPExtensionsEnable = col.Enable
+		site.PHPExtensionsDisable = col.Disable
+	}
+	return nil
+}
+
+// encodeStringList serialises a list column (aliases, ipAllowlist,
+// mailRelayDomains) as a JSON array; an empty list is stored as "".
+func encodeStringList(column string, list []string) (string, error) {
+	if len(list) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(list)
+	if err != nil {
+		return "", fmt.Errorf("encoding %s: %w", column, err)
+	}
+	return string(b), nil
+}
+
 // GetSites returns all sites stored in SQLite.
 func (s *Storage) GetSites() ([]types.Site, error) {
 	rows, err := s.db.Query("SELECT " + siteColumns + " FROM sites")
@@ -205,9 +333,31 @@ func (s *Storage) AddSite(site *types.Site) error {
 	if site.MySQLVersion == "" && site.DBEngine == "mysql" { //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
 		site.MySQLVersion = site.DBVersion //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
 	}
+	hydrateLegacyCacheFields(site)
+	mirrorLegacyCacheFields(site)
+	resources, err := encodeResourceLimits(site.Resources)
+	if err != nil {
+		return err
+	}
+	phpIni, phpExt, err := encodePHPOverrides(site)
+	if err != nil {
+		return err
+	}
+	aliases, err := encodeStringList("aliases", site.Aliases)
+	if err != nil {
+		return err
+	}
+	allowlist, err := encodeStringList("ipAllowlist", site.IPAllowlist)
+	if err != nil {
+		return err
+	}
+	relayDomains, err := encodeStringList("mailRelayDomains", site.MailRelayDomains)
+	if err != nil {
+		return err
+	}
 
-	_, err := s.db.Exec(
-		"INSERT INTO sites ("+siteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+	_, err = s.db.Exec(
+		"INSERT INTO sites ("+siteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 		site.ID, site.Name, site.Slug, site.Domain, site.FilesDir, site.PublicDir, site.Started,
 		//nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
 		site.PHPVersion, site.MySQLVersion, site.RedisVersion, site.DBPassword,
@@ -215,7 +365,12 @@ func (s *Storage) AddSite(site *types.Site) error {
 		site.DBEngine, site.DBVersion, boolToInt(site.PublishDBPort),
 		boolToInt(site.SPXEnabled), site.SPXKey,
 		boolToInt(site.LanEnabled),
+		boolToInt(site.XdebugEnabled), site.XdebugMode,
 		site.GitRemote, site.GitBranch, site.WorktreePath, site.ParentSiteID,
+		site.CacheBackend, site.CacheVersion,
+		resources, phpIni, phpExt, aliases,
+		boolToInt(site.AuthEnabled), site.AuthUser, site.AuthPassword, site.AuthHash, allowlist,
+		site.MailMode, site.MailRelayHost, site.MailRelayUser, site.MailRelayPassword, relayDomains,
 		site.CreatedAt, site.UpdatedAt,
 	)
 	if err != nil {
@@ -232,9 +387,31 @@ func (s *Storage) UpdateSite(site *types.Site) (*types.Site, error) {
 	if site.MySQLVersion == "" && site.DBEngine == "mysql" { //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
 		site.MySQLVersion = site.DBVersion //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
 	}
+	hydrateLegacyCacheFields(site)
+	mirrorLegacyCacheFields(site)
+	resources, err := encodeResourceLimits(site.Resources)
+	if err != nil {
+		return nil, err
+	}
+	phpIni, phpExt, err := encodePHPOverrides(site)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := encodeStringList("aliases", site.Aliases)
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := encodeStringList("ipAllowlist", site.IPAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	relayDomains, err := encodeStringList("mailRelayDomains", site.MailRelayDomains)
+	if err != nil {
+		return nil, err
+	}
 
-	_, err := s.db.Exec(
-		"UPDATE sites SET name = ?, slug = ?, domain = ?, filesDir = ?, publicDir = ?, started = ?, phpVersion = ?, mysqlVersion = ?, redisVersion = ?, dbPassword = ?, webServer = ?, multisite = ?, salts = ?, dbEngine = ?, dbVersion = ?, publishDBPort = ?, spxEnabled = ?, spxKey = ?, lanEnabled = ?, gitRemote = ?, gitBranch = ?, worktreePath = ?, parentSiteID = ?, updatedAt = ? WHERE id = ?",
+	_, err = s.db.Exec(
+		"UPDATE sites SET name = ?, slug = ?, domain = ?, filesDir = ?, publicDir = ?, started = ?, phpVersion = ?, mysqlVersion = ?, redisVersion = ?, dbPassword = ?, webServer = ?, multisite = ?, salts = ?, dbEngine = ?, dbVersion = ?, publishDBPort = ?, spxEnabled = ?, spxKey = ?, lanEnabled = ?, xdebugEnabled = ?, xdebugMode = ?, gitRemote = ?, gitBranch = ?, worktreePath = ?, parentSiteID = ?, cacheBackend = ?, cacheVersion = ?, resourceLimits = ?, phpIni = ?, phpExtensions = ?, aliases = ?, authEnabled = ?, authUser = ?, authPassword = ?, authHash = ?, ipAllowlist = ?, mailMode = ?, mailRelayHost = ?, mailRelayUser = ?, mailRelayPassword = ?, mailRelayDomains = ?, updatedAt = ? WHERE id = ?",
 		site.Name, site.Slug, site.Domain, site.FilesDir, site.PublicDir, site.Started,
 		//nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the DBVersion+DBEngine split
 		site.PHPVersion, site.MySQLVersion, site.RedisVersion, site.DBPassword,
@@ -242,7 +419,12 @@ func (s *Storage) UpdateSite(site *types.Site) (*types.Site, error) {
 		site.DBEngine, site.DBVersion, boolToInt(site.PublishDBPort),
 		boolToInt(site.SPXEnabled), site.SPXKey,
 		boolToInt(site.LanEnabled),
+		boolToInt(site.XdebugEnabled), site.XdebugMode,
 		site.GitRemote, site.GitBranch, site.WorktreePath, site.ParentSiteID,
+		site.CacheBackend, site.CacheVersion,
+		resources, phpIni, phpExt, aliases,
+		boolToInt(site.AuthEnabled), site.AuthUser, site.AuthPassword, site.AuthHash, allowlist,
+		site.MailMode, site.MailRelayHost, site.MailRelayUser, site.MailRelayPassword, relayDomains,
 		site.UpdatedAt, site.ID,
 	)
 	if err != nil {
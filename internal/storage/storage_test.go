@@ -79,6 +79,10 @@ func TestAddAndGetSite(t *testing.T) {
 	if got.DBVersion != "8.0" {
 		t.Errorf("DBVersion = %q, want %q", got.DBVersion, "8.0")
 	}
+	// hydrateLegacyCacheFields does the same for RedisVersion.
+	if got.CacheBackend != "redis" || got.CacheVersion != "7" {
+		t.Errorf("cache = %q/%q, want redis/7", got.CacheBackend, got.CacheVersion)
+	}
 }
 
 func TestAddSite_NewMultiEngineFields(t *testing.T) {
@@ -172,6 +176,231 @@ func TestAddSite_LanEnabled(t *testing.T) {
 	}
 }
 
+func TestAddSite_XdebugFields(t *testing.T) {
+	st := newStorage(t)
+	site := &types.Site{
+		ID: "id-xdebug", Name: "XdebugSite", Slug: "xdebugsite",
+		Domain: "xdebugsite.localhost", FilesDir: "/tmp/xdebugsite", PublicDir: "/",
+		DBEngine: "mysql", DBVersion: "8.0", DBPassword: "pw",
+		XdebugEnabled: true, XdebugMode: "profile",
+	}
+	if err := st.AddSite(site); err != nil {
+		t.Fatalf("AddSite() = %v", err)
+	}
+	got, err := st.GetSite("id-xdebug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.XdebugEnabled {
+		t.Error("XdebugEnabled lost on round-trip")
+	}
+	if got.XdebugMode != "profile" {
+		t.Errorf("XdebugMode = %q, want %q", got.XdebugMode, "profile")
+	}
+
+	got.XdebugEnabled = false
+	if _, err := st.UpdateSite(got); err != nil {
+		t.Fatalf("UpdateSite() = %v", err)
+	}
+	got2, _ := st.GetSite("id-xdebug")
+	if got2.XdebugEnabled {
+		t.Error("XdebugEnabled persisted as true after disable")
+	}
+	if got2.XdebugMode != "profile" {
+		t.Errorf("XdebugMode not preserved on disable: %q", got2.XdebugMode)
+	}
+}
+
+func TestAddSite_CacheBackendFields(t *testing.T) {
+	st := newStorage(t)
+	site := &types.Site{
+		ID: "id-cache", Name: "CacheSite", Slug: "cachesite",
+		Domain: "cachesite.localhost", FilesDir: "/tmp/cachesite", PublicDir: "/",
+		DBEngine: "mysql", DBVersion: "8.0", DBPassword: "pw",
+		CacheBackend: "redis", CacheVersion: "7.4",
+	}
+	if err := st.AddSite(site); err != nil {
+		t.Fatalf("AddSite() = %v", err)
+	}
+	got, err := st.GetSite("id-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CacheBackend != "redis" || got.CacheVersion != "7.4" {
+		t.Errorf("cache = %q/%q, want redis/7.4", got.CacheBackend, got.CacheVersion)
+	}
+	if got.RedisVersion != "7.4" { //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+		t.Errorf("RedisVersion mirror = %q, want 7.4", got.RedisVersion) //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+	}
+
+	got.CacheBackend, got.CacheVersion = "memcached", "1.6"
+	if _, err := st.UpdateSite(got); err != nil {
+		t.Fatalf("UpdateSite() = %v", err)
+	}
+	got2, _ := st.GetSite("id-cache")
+	if got2.CacheBackend != "memcached" || got2.CacheVersion != "1.6" {
+		t.Errorf("cache after switch = %q/%q", got2.CacheBackend, got2.CacheVersion)
+	}
+	if got2.RedisVersion != "" { //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+		t.Errorf("RedisVersion mirror should clear off Redis, got %q", got2.RedisVersion) //nolint:staticcheck // SA1019: legacy mirror, kept for back-compat with rows written before the cache-backend split
+	}
+}
+
+func TestSiteResourceLimitsRoundTrip(t *testing.T) {
+	st := newStorage(t)
+	site := &types.Site{
+		ID: "id-res", Name: "ResSite", Slug: "ressite",
+		Domain: "ressite.localhost", FilesDir: "/tmp/ressite", PublicDir: "/",
+		DBPassword: "pw",
+	}
+	if err := st.AddSite(site); err != nil {
+		t.Fatalf("AddSite() = %v", err)
+	}
+	got, _ := st.GetSite("id-res")
+	if got.Resources != nil {
+		t.Errorf("Resources = %v, want nil for a site without overrides", got.Resources)
+	}
+
+	got.Resources = types.ResourceLimits{
+		"all": {CPUs: 1.5},
+		"php": {MemoryMB: 1024, Pids: 2048},
+	}
+	if _, err := st.UpdateSite(got); err != nil {
+		t.Fatalf("UpdateSite() = %v", err)
+	}
+	got2, _ := st.GetSite("id-res")
+	if got2.Resources["all"].CPUs != 1.5 {
+		t.Errorf("all.cpus = %v, want 1.5", got2.Resources["all"].CPUs)
+	}
+	if php := got2.Resources["php"]; php.MemoryMB != 1024 || php.Pids != 2048 {
+		t.Errorf("php = %+v, want 1024MB / 2048 pids", php)
+	}
+}
+
+func TestSitePHPOverridesRoundTrip(t *testing.T) {
+	st := newStorage(t)
+	site := &types.Site{
+		ID: "id-phpini", Name: "IniSite", Slug: "inisite",
+		Domain: "inisite.localhost", FilesDir: "/tmp/inisite", PublicDir: "/",
+		DBPassword: "pw",
+		PHPIni:     map[string]string{"max_input_vars": "5000"},
+	}
+	if err := st.AddSite(site); err != nil {
+		t.Fatalf("AddSite() = %v", err)
+	}
+	got, _ := st.GetSite("id-phpini")
+	if got.PHPIni["max_input_vars"] != "5000" {
+		t.Errorf("PHPIni = %v, want max_input_vars=5000", got.PHPIni)
+	}
+	if got.PHPExtensionsEnable != nil || got.PHPExtensionsDisable != nil {
+		t.Errorf("extensions = %v / %v, want nil", got.PHPExtensionsEnable, got.PHPExtensionsDisable)
+	}
+
+	got.PHPIni = nil
+	got.PHPExtensionsEnable = []string{"pcov"}
+	got.PHPExtensionsDisable = []string{"newrelic"}
+	if _, err := st.UpdateSite(got); err != nil {
+		t.Fatalf("UpdateSite() = %v", err)
+	}
+	got2, _ := st.GetSite("id-phpini")
+	if got2.PHPIni != nil {
+		t.Errorf("PHPIni = %v, want nil after clearing", got2.PHPIni)
+	}
+	if len(got2.PHPExtensionsEnable) != 1 || got2.PHPExtensionsEnable[0] != "pcov" ||
+		len(got2.PHPExtensionsDisable) != 1 || got2.PHPExtensionsDisable[0] != "newrelic" {
+		t.Errorf("extensions = %v / %v, want [pcov] / [newrelic]", got2.PHPExtensionsEnable, got2.PHPExtensionsDisable)
+	}
+}
+
+func TestSiteAliasesRoundTrip(t *testing.T) {
+	st := newStorage(t)
+	site := &types.Site{
+		ID: "id-alias", Name: "AliasSite", Slug: "aliassite",
+		Domain: "aliassite.localhost", FilesDir: "/tmp/aliassite", PublicDir: "/",
+		DBPassword: "pw",
+		Aliases:    []string{"client-site.test", "*.client-site.test"},
+	}
+	if err := st.AddSite(site); err != nil {
+		t.Fatalf("AddSite() = %v", err)
+	}
+	got, _ := st.GetSite("id-alias")
+	if len(got.Aliases) != 2 || got.Aliases[1] != "*.client-site.test" {
+		t.Errorf("Aliases = %v, want both aliases in order", got.Aliases)
+	}
+
+	got.Aliases = nil
+	if _, err := st.UpdateSite(got); err != nil {
+		t.Fatalf("UpdateSite() = %v", err)
+	}
+	if got2, _ := st.GetSite("id-alias"); got2.Aliases != nil {
+		t.Errorf("Aliases = %v, want nil after clearing", got2.Aliases)
+	}
+}
+
+func TestSiteAccessRoundTrip(t *testing.T) {
+	st := newStorage(t)
+	site := &types.Site{
+		ID: "id-access", Name: "AccessSite", Slug: "accesssite",
+		Domain: "accesssite.localhost", FilesDir: "/tmp/accesssite", PublicDir: "/",
+		DBPassword:  "pw",
+		AuthEnabled: true, AuthUser: "locorum", AuthPassword: "secret", AuthHash: "$2a$04$hash",
+		IPAllowlist: []string{"192.168.1.0/24"},
+	}
+	if err := st.AddSite(site); err != nil {
+		t.Fatalf("AddSite() = %v", err)
+	}
+	got, _ := st.GetSite("id-access")
+	if !got.AuthEnabled || got.AuthUser != "locorum" || got.AuthPassword != "secret" || got.AuthHash != "$2a$04$hash" {
+		t.Errorf("auth = (%v, %q, %q, %q), want the stored credentials", got.AuthEnabled, got.AuthUser, got.AuthPassword, got.AuthHash)
+	}
+	if len(got.IPAllowlist) != 1 || got.IPAllowlist[0] != "192.168.1.0/24" {
+		t.Errorf("IPAllowlist = %v", got.IPAllowlist)
+	}
+
+	got.AuthEnabled = false
+	got.IPAllowlist = nil
+	if _, err := st.UpdateSite(got); err != nil {
+		t.Fatalf("UpdateSite() = %v", err)
+	}
+	got2, _ := st.GetSite("id-access")
+	if got2.AuthEnabled || got2.AuthPassword != "secret" || got2.IPAllowlist != nil {
+		t.Errorf("after disable: auth %v, password %q, allowlist %v; want off, kept, nil", got2.AuthEnabled, got2.AuthPassword, got2.IPAllowlist)
+	}
+}
+
+func TestSiteMailModeRoundTrip(t *testing.T) {
+	st := newStorage(t)
+	site := &types.Site{
+		ID: "id-mail", Name: "MailSite", Slug: "mailsite",
+		Domain: "mailsite.localhost", FilesDir: "/tmp/mailsite", PublicDir: "/",
+		DBPassword: "pw",
+		MailMode:   "relay", MailRelayHost: "smtp.example.com:587",
+		MailRelayUser: "qa", MailRelayPassword: "relay-secret",
+		MailRelayDomains: []string{"example.com", "qa.example.org"},
+	}
+	if err := st.AddSite(site); err != nil {
+		t.Fatalf("AddSite() = %v", err)
+	}
+	got, _ := st.GetSite("id-mail")
+	if got.MailMode != "relay" || got.MailRelayHost != "smtp.example.com:587" ||
+		got.MailRelayUser != "qa" || got.MailRelayPassword != "relay-secret" {
+		t.Errorf("relay = (%q, %q, %q, %q), want the stored settings", got.MailMode, got.MailRelayHost, got.MailRelayUser, got.MailRelayPassword)
+	}
+	if len(got.MailRelayDomains) != 2 || got.MailRelayDomains[1] != "qa.example.org" {
+		t.Errorf("MailRelayDomains = %v", got.MailRelayDomains)
+	}
+
+	got.MailMode = "blackhole"
+	got.MailRelayDomains = nil
+	if _, err := st.UpdateSite(got); err != nil {
+		t.Fatalf("UpdateSite() = %v", err)
+	}
+	got2, _ := st.GetSite("id-mail")
+	if got2.MailMode != "blackhole" || got2.MailRelayDomains != nil || got2.MailRelayPassword != "relay-secret" {
+		t.Errorf("after update: mode %q, domains %v, password %q", got2.MailMode, got2.MailRelayDomains, got2.MailRelayPassword)
+	}
+}
+
 func TestGetSites(t *testing.T) {
 	st := newStorage(t)
 
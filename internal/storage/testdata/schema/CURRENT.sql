@@ -3,49 +3,92 @@
 );
 );
 );
+);
+);
+  aliases TEXT NOT NULL DEFAULT '',
+  allow TEXT NOT NULL,
+  authEnabled INTEGER NOT NULL DEFAULT 0,
+  authHash TEXT NOT NULL DEFAULT '',
+  authPassword TEXT NOT NULL DEFAULT '',
+  authUser TEXT NOT NULL DEFAULT '',
+  cacheBackend TEXT NOT NULL DEFAULT 'redis',
+  cacheVersion TEXT NOT NULL DEFAULT '',
   command TEXT NOT NULL,
   created_at TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  created_at TEXT NOT NULL,
   createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 CREATE INDEX idx_activity_events_site_time ON activity_events(
 CREATE INDEX idx_site_hooks_site_event ON site_hooks(site_id, event);
 CREATE INDEX idx_sites_parent ON sites(parentSiteID) WHERE parentSiteID != '';
 CREATE TABLE activity_events(
+CREATE TABLE capability_tokens(
 CREATE TABLE settings(key TEXT PRIMARY KEY,
 CREATE TABLE site_hooks(
+CREATE TABLE site_remotes(
 CREATE TABLE sites(
 CREATE TABLE sqlite_sequence(name,seq);
+  db_command TEXT NOT NULL DEFAULT '',
   dbEngine TEXT NOT NULL DEFAULT 'mysql',
   dbPassword TEXT NOT NULL DEFAULT 'password',
   dbVersion TEXT NOT NULL DEFAULT '',
+  deny TEXT NOT NULL DEFAULT '',
   details TEXT NOT NULL DEFAULT ''
   domain TEXT NOT NULL,
   duration_ms INTEGER NOT NULL,
   enabled INTEGER NOT NULL DEFAULT 1,
   event TEXT NOT NULL,
+  expires_at TEXT NOT NULL DEFAULT '',
   filesDir TEXT NOT NULL,
   gitBranch TEXT NOT NULL DEFAULT '',
   gitRemote TEXT NOT NULL DEFAULT '',
+  hash TEXT NOT NULL UNIQUE,
+  host_key TEXT NOT NULL DEFAULT '',
+  host TEXT NOT NULL,
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
   id INTEGER PRIMARY KEY AUTOINCREMENT,
   id INTEGER PRIMARY KEY AUTOINCREMENT,
   id TEXT PRIMARY KEY,
+  ipAllowlist TEXT NOT NULL DEFAULT '',
+  key_path TEXT NOT NULL DEFAULT '',
   kind TEXT NOT NULL,
+  label TEXT NOT NULL UNIQUE,
+  lanEnabled INTEGER NOT NULL DEFAULT 0,
+  last_used_at TEXT NOT NULL DEFAULT ''
+  mailMode TEXT NOT NULL DEFAULT '',
+  mailRelayDomains TEXT NOT NULL DEFAULT ''
+  mailRelayHost TEXT NOT NULL DEFAULT '',
+  mailRelayPassword TEXT NOT NULL DEFAULT '',
+  mailRelayUser TEXT NOT NULL DEFAULT '',
   message TEXT NOT NULL,
   multisite TEXT NOT NULL DEFAULT '',
   mysqlVersion TEXT,
   name TEXT NOT NULL,
-  parentSiteID TEXT NOT NULL DEFAULT ''
+  name TEXT NOT NULL,
+  parentSiteID TEXT NOT NULL DEFAULT '',
+  password TEXT NOT NULL DEFAULT '',
+  path TEXT NOT NULL,
+  phpExtensions TEXT NOT NULL DEFAULT '',
+  phpIni TEXT NOT NULL DEFAULT '',
   phpVersion TEXT,
   plan TEXT NOT NULL,
+  port INTEGER NOT NULL DEFAULT 0,
   position INTEGER NOT NULL,
+  production INTEGER NOT NULL DEFAULT 0,
+  profile TEXT NOT NULL,
   publicDir TEXT NOT NULL,
   publishDBPort INTEGER NOT NULL DEFAULT 0,
   redisVersion TEXT,
+  resourceLimits TEXT NOT NULL DEFAULT '',
   run_as_user TEXT NOT NULL DEFAULT '',
   salts TEXT NOT NULL DEFAULT '',
   service TEXT NOT NULL DEFAULT '',
   site_id,
   site_id TEXT NOT NULL REFERENCES sites(id) ON DELETE CASCADE,
   site_id TEXT NOT NULL REFERENCES sites(id) ON DELETE CASCADE,
+  site_id TEXT NOT NULL REFERENCES sites(id) ON DELETE CASCADE,
+  sites TEXT NOT NULL DEFAULT '',
   slug TEXT NOT NULL,
   spxEnabled INTEGER NOT NULL DEFAULT 0,
   spxKey TEXT NOT NULL DEFAULT '',
@@ -55,8 +98,13 @@ CREATE TABLE sqlite_sequence(name,seq);
   time DESC
   time TEXT NOT NULL,
   UNIQUE(site_id, event, position)
+  UNIQUE(site_id, name)
+  updated_at TEXT NOT NULL,
   updated_at TEXT NOT NULL,
   updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  user TEXT NOT NULL,
 value TEXT NOT NULL);
   webServer TEXT NOT NULL DEFAULT 'nginx',
   worktreePath TEXT NOT NULL DEFAULT '',
+  xdebugEnabled INTEGER NOT NULL DEFAULT 0,
+  xdebugMode TEXT NOT NULL DEFAULT '',
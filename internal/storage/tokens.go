@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PeterBooker/locorum/internal/captoken"
+)
+
+const tokenColumns = "id, label, hash, profile, allow, deny, sites, expires_at, created_at, last_used_at"
+
+// ErrTokenNotFound is returned when no capability token matches the
+// lookup.
+var ErrTokenNotFound = errors.New("token not found")
+
+// ErrTokenExists is returned by AddToken when the label is taken.
+var ErrTokenExists = errors.New("token already exists")
+
+// ListTokens returns every capability token, ordered by label.
+func (s *Storage) ListTokens() ([]captoken.Token, error) {
+	rows, err := s.db.Query("SELECT " + tokenColumns + " FROM capability_tokens ORDER BY label")
+	if err != nil {
+		return nil, fmt.Errorf("listing tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var out []captoken.Token
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// GetTokenByHash returns the token whose secret hashes to hash, or
+// ErrTokenNotFound.
+func (s *Storage) GetTokenByHash(hash string) (*captoken.Token, error) {
+	row := s.db.QueryRow("SELECT "+tokenColumns+" FROM capability_tokens WHERE hash = ?", hash)
+	t, err := scanToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// AddToken validates and inserts t. t.ID and t.CreatedAt are populated
+// on success.
+func (s *Storage) AddToken(t *captoken.Token) error {
+	if t == nil {
+		return errors.New("AddToken: nil token")
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	allow, err := encodeStringList("allow", t.Allow)
+	if err != nil {
+		return err
+	}
+	deny, err := encodeStringList("deny", t.Deny)
+	if err != nil {
+		return err
+	}
+	sites := ""
+	if len(t.Sites) > 0 {
+		b, err := json.Marshal(t.Sites)
+		if err != nil {
+			return fmt.Errorf("encoding sites: %w", err)
+		}
+		sites = string(b)
+	}
+	t.CreatedAt = now()
+
+	res, err := s.db.Exec(
+		"INSERT INTO capability_tokens (label, hash, profile, allow, deny, sites, expires_at, created_at, last_used_at)"+
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?, '')",
+		t.Label, t.Hash, t.Profile, allow, deny, sites, t.ExpiresAt, t.CreatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: capability_tokens.label") {
+			return fmt.Errorf("%w: %q", ErrTokenExists, t.Label)
+		}
+		return fmt.Errorf("AddToken: insert: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("AddToken: last insert id: %w", err)
+	}
+	t.ID = id
+	return nil
+}
+
+// TouchToken records a use of the token with id. Best-effort callers
+// may ignore the error.
+func (s *Storage) TouchToken(id int64) error {
+	if _, err := s.db.Exec("UPDATE capability_tokens SET last_used_at = ? WHERE id = ?", now(), id); err != nil {
+		return fmt.Errorf("TouchToken: %w", err)
+	}
+	return nil
+}
+
+// DeleteToken removes the token called label. Returns ErrTokenNotFound
+// when nothing matched.
+func (s *Storage) DeleteToken(label string) error {
+	res, err := s.db.Exec("DELETE FROM capability_tokens WHERE label = ?", label)
+	if err != nil {
+		return fmt.Errorf("DeleteToken: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("DeleteToken: rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func scanToken(s hookScanner) (captoken.Token, error) {
+	var (
+		t                  captoken.Token
+		allow, deny, sites string
+	)
+	if err := s.Scan(
+		&t.ID, &t.Label, &t.Hash, &t.Profile, &allow, &deny, &sites,
+		&t.ExpiresAt, &t.CreatedAt, &t.LastUsedAt,
+	); err != nil {
+		return captoken.Token{}, err
+	}
+	for _, col := range []struct {
+		name string
+		raw  string
+		dst  any
+	}{
+		{"allow", allow, &t.Allow},
+		{"deny", deny, &t.Deny},
+		{"sites", sites, &t.Sites},
+	} {
+		if col.raw == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(col.raw), col.dst); err != nil {
+			return captoken.Token{}, fmt.Errorf("decoding token %s: %w", col.name, err)
+		}
+	}
+	return t, nil
+}
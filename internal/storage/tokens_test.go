@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PeterBooker/locorum/internal/captoken"
+)
+
+func TestTokens_CRUD(t *testing.T) {
+	st := newStorage(t)
+
+	tk := &captoken.Token{
+		Label:   "review-bot",
+		Hash:    captoken.Hash("lct_secret"),
+		Profile: captoken.ProfileFull,
+		Allow:   []string{"site.*"},
+		Deny:    []string{"site.delete"},
+		Sites:   []captoken.Site{{ID: "id1", Slug: "shop"}},
+	}
+	if err := st.AddToken(tk); err != nil {
+		t.Fatalf("AddToken: %v", err)
+	}
+	if tk.ID == 0 || tk.CreatedAt == "" {
+		t.Errorf("AddToken did not populate ID/CreatedAt: %+v", tk)
+	}
+	dup := *tk
+	dup.Hash = captoken.Hash("lct_other")
+	if err := st.AddToken(&dup); !errors.Is(err, ErrTokenExists) {
+		t.Errorf("duplicate label = %v, want ErrTokenExists", err)
+	}
+
+	got, err := st.GetTokenByHash(captoken.Hash("lct_secret"))
+	if err != nil {
+		t.Fatalf("GetTokenByHash: %v", err)
+	}
+	if !reflect.DeepEqual(got.Allow, tk.Allow) || !reflect.DeepEqual(got.Deny, tk.Deny) || !reflect.DeepEqual(got.Sites, tk.Sites) {
+		t.Errorf("GetTokenByHash = %+v", got)
+	}
+	if err := st.TouchToken(got.ID); err != nil {
+		t.Fatal(err)
+	}
+	list, err := st.ListTokens()
+	if err != nil || len(list) != 1 || list[0].LastUsedAt == "" {
+		t.Errorf("ListTokens = %+v, %v", list, err)
+	}
+
+	if err := st.DeleteToken("review-bot"); err != nil {
+		t.Fatalf("DeleteToken: %v", err)
+	}
+	if _, err := st.GetTokenByHash(captoken.Hash("lct_secret")); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("GetTokenByHash after delete = %v, want ErrTokenNotFound", err)
+	}
+	if err := st.DeleteToken("review-bot"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("second DeleteToken = %v, want ErrTokenNotFound", err)
+	}
+}
@@ -194,3 +194,115 @@ func downloadBinary(ctx context.Context, url, dst, expectedHash string) error {
 	}
 	return nil
 }
+
+// localCATrustName is the nickname the built-in root is stored under in
+// NSS databases, so a re-install replaces rather than duplicates it.
+const localCATrustName = "Locorum local CA"
+
+// InstallCA creates the built-in root CA if needed and adds it to the
+// current user's trust stores — the LocalCA counterpart of
+// Mkcert.InstallCA, behind the same "Set up trusted HTTPS" action. Only
+// user-level stores are touched, so no admin rights are needed:
+//
+//   - macOS: the login keychain, via `security add-trusted-cert`.
+//     macOS shows its own password prompt.
+//   - Windows: the CurrentUser Root store, via `certutil -user`.
+//     Windows shows its own confirmation dialog.
+//   - Linux: every NSS database found (~/.pki/nssdb for Chromium,
+//     Firefox profiles), via `certutil` from libnss3-tools — the same
+//     coverage mkcert gets with TRUST_STORES=nss.
+//
+// The trusted marker is written when at least one store accepted the
+// root. Failures carry the tool output so the UI can show what happened;
+// the root stays on disk either way so certs can still be issued.
+func (l *LocalCA) InstallCA(ctx context.Context) error {
+	rootCA, err := l.ensureCA()
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolve home dir: %w", err)
+	}
+	cmds, err := localCATrustCommands(runtime.GOOS, home, rootCA)
+	if err != nil {
+		return err
+	}
+
+	ictx, cancel := context.WithTimeout(ctx, installTimeout)
+	defer cancel()
+
+	var errs []error
+	installed := 0
+	for _, args := range cmds {
+		cmd := exec.CommandContext(ictx, args[0], args[1:]...)
+		utils.HideConsole(cmd)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w; output: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out))))
+			continue
+		}
+		installed++
+	}
+	if installed == 0 {
+		return fmt.Errorf("install local CA: %w", errors.Join(errs...))
+	}
+	if err := os.WriteFile(filepath.Join(l.caDir, localCATrustedMarker), nil, 0o600); err != nil {
+		return fmt.Errorf("record trust: %w", err)
+	}
+	for _, e := range errs {
+		slog.Warn("local CA: trust store install failed", "err", e.Error())
+	}
+	slog.Info("local CA installed", "root", rootCA, "stores", installed)
+	return nil
+}
+
+// localCATrustCommands returns the commands that add rootCA to the
+// user-level trust stores on goos. Split out from InstallCA so the
+// per-platform argument lists are testable without running them.
+func localCATrustCommands(goos, home, rootCA string) ([][]string, error) {
+	switch goos {
+	case "darwin":
+		keychain := filepath.Join(home, "Library", "Keychains", "login.keychain-db")
+		return [][]string{{"security", "add-trusted-cert", "-r", "trustRoot", "-k", keychain, rootCA}}, nil
+	case "windows":
+		return [][]string{{"certutil", "-user", "-addstore", "-f", "Root", rootCA}}, nil
+	case "linux":
+		certutil, err := exec.LookPath("certutil")
+		if err != nil {
+			return nil, fmt.Errorf("certutil not found (install libnss3-tools / nss-tools), or import %s into your browser by hand", rootCA)
+		}
+		dbs := nssDatabases(home)
+		if len(dbs) == 0 {
+			return nil, fmt.Errorf("no browser certificate databases found; open Chrome or Firefox once, or import %s by hand", rootCA)
+		}
+		cmds := make([][]string, 0, len(dbs))
+		for _, db := range dbs {
+			cmds = append(cmds, []string{certutil, "-A", "-d", "sql:" + db, "-t", "C,,", "-n", localCATrustName, "-i", rootCA})
+		}
+		return cmds, nil
+	}
+	return nil, fmt.Errorf("installing the local CA is not supported on %s; import %s by hand", goos, rootCA)
+}
+
+// nssDatabases lists the NSS certificate databases under home that
+// browsers read: the shared ~/.pki/nssdb (Chromium family) and every
+// Firefox profile, including the snap and flatpak installs. Only
+// databases in the sql: (cert9.db) format are returned.
+func nssDatabases(home string) []string {
+	var out []string
+	if _, err := os.Stat(filepath.Join(home, ".pki", "nssdb", "cert9.db")); err == nil {
+		out = append(out, filepath.Join(home, ".pki", "nssdb"))
+	}
+	for _, pattern := range []string{
+		filepath.Join(home, ".mozilla", "firefox", "*", "cert9.db"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox", "*", "cert9.db"),
+		filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox", "*", "cert9.db"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			out = append(out, filepath.Dir(m))
+		}
+	}
+	return out
+}
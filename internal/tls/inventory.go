@@ -0,0 +1,153 @@
+package tls
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// RenewBefore is how long before NotAfter a leaf cert counts as due
+	// for renewal. Issue re-signs certs inside this window, so a site
+	// restart is enough to refresh one.
+	RenewBefore = 30 * 24 * time.Hour
+
+	// RootWarnBefore is the equivalent window for the root CA. Longer
+	// than RenewBefore because replacing a root means re-trusting it in
+	// every browser and device.
+	RootWarnBefore = 90 * 24 * time.Hour
+)
+
+// RootInfo describes the provider's current root CA.
+type RootInfo struct {
+	Path        string    `json:"path"`
+	Fingerprint string    `json:"fingerprint"` // hex SHA-256 of the DER
+	NotAfter    time.Time `json:"notAfter"`
+}
+
+// CertInfo describes one issued cert under the certs directory.
+type CertInfo struct {
+	// Name is the directory name — "site-<slug>" or "svc-<name>".
+	Name     string `json:"name"`
+	CertFile string `json:"certFile"`
+
+	// Hostnames lists the DNS and IP SANs, in certificate order.
+	Hostnames []string  `json:"hostnames,omitempty"`
+	NotAfter  time.Time `json:"notAfter"`
+
+	// IssuerKeyID is the hex authority key identifier — the subject key
+	// ID of whichever CA signed the cert.
+	IssuerKeyID string `json:"issuerKeyId,omitempty"`
+
+	// CurrentRoot reports whether the cert verifies against the current
+	// root. Always true when the root is unknown, so a missing root
+	// doesn't flag every cert as stale.
+	CurrentRoot bool `json:"currentRoot"`
+
+	// Error is set when cert.pem is missing or unparsable.
+	Error string `json:"error,omitempty"`
+}
+
+// Inventory is a point-in-time read of every issued cert.
+type Inventory struct {
+	Root  *RootInfo  `json:"root,omitempty"` // nil when the provider has no root yet
+	Certs []CertInfo `json:"certs"`
+}
+
+// NeedsRenewal reports whether c is unreadable, signed by a root other
+// than the current one, or inside the RenewBefore window at now.
+func (c CertInfo) NeedsRenewal(now time.Time) bool {
+	return c.Error != "" || !c.CurrentRoot || c.NotAfter.Sub(now) < RenewBefore
+}
+
+// Due returns the certs that need renewal at now.
+func (inv Inventory) Due(now time.Time) []CertInfo {
+	var out []CertInfo
+	for _, c := range inv.Certs {
+		if c.NeedsRenewal(now) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ReadInventory parses every <certDir>/<name>/cert.pem and compares each
+// against prov's root. Temp dirs left by an interrupted Issue (dot
+// prefixed) are skipped. A missing certDir is an empty inventory, not an
+// error.
+func ReadInventory(ctx context.Context, prov Provider, certDir string) (Inventory, error) {
+	inv := Inventory{Certs: []CertInfo{}}
+
+	var root *x509.Certificate
+	if path, err := prov.RootCAPath(ctx); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			if cert, err := parseCertPEM(data); err == nil {
+				root = cert
+				sum := sha256.Sum256(cert.Raw)
+				inv.Root = &RootInfo{
+					Path:        path,
+					Fingerprint: hex.EncodeToString(sum[:]),
+					NotAfter:    cert.NotAfter,
+				}
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(certDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return inv, nil
+		}
+		return inv, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		inv.Certs = append(inv.Certs, readCertInfo(e.Name(), filepath.Join(certDir, e.Name(), "cert.pem"), root))
+	}
+	sort.Slice(inv.Certs, func(i, j int) bool { return inv.Certs[i].Name < inv.Certs[j].Name })
+	return inv, nil
+}
+
+func readCertInfo(name, path string, root *x509.Certificate) CertInfo {
+	info := CertInfo{Name: name, CertFile: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	cert, err := parseCertPEM(data)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Hostnames = append(info.Hostnames, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.Hostnames = append(info.Hostnames, ip.String())
+	}
+	info.NotAfter = cert.NotAfter
+	info.IssuerKeyID = hex.EncodeToString(cert.AuthorityKeyId)
+	info.CurrentRoot = root == nil || cert.CheckSignatureFrom(root) == nil
+	return info
+}
+
+// certFresh reports whether the PEM cert at path is still outside the
+// RenewBefore window at now. Unreadable files count as stale.
+func certFresh(path string, now time.Time) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	cert, err := parseCertPEM(data)
+	if err != nil {
+		return false
+	}
+	return cert.NotAfter.Sub(now) >= RenewBefore
+}
@@ -0,0 +1,109 @@
+package tls
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadInventory(t *testing.T) {
+	l := newTestLocalCA(t)
+	ctx := context.Background()
+	if _, err := l.ensureCA(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Issue(ctx, CertSpec{Name: "site-fresh", Hostnames: []string{"fresh.localhost", "10.0.0.5"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A leaf signed long enough ago to sit inside the renewal window.
+	caCert, caKey, err := l.loadCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, oldKey, err := signLeaf(caCert, caKey, []string{"old.localhost"}, time.Now().Add(-localLeafValidity+10*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCertPair(t, filepath.Join(l.certDir, "site-old"), old, oldKey)
+
+	// Leftovers from an interrupted Issue are skipped; a dir without a
+	// cert is reported as unreadable.
+	if err := os.MkdirAll(filepath.Join(l.certDir, ".issue-site-x-123"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(l.certDir, "svc-broken"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := ReadInventory(ctx, l, l.certDir)
+	if err != nil {
+		t.Fatalf("ReadInventory: %v", err)
+	}
+	if inv.Root == nil || len(inv.Root.Fingerprint) != 64 {
+		t.Fatalf("Root = %+v, want a SHA-256 fingerprint", inv.Root)
+	}
+	if len(inv.Certs) != 3 {
+		t.Fatalf("Certs = %+v, want 3 entries", inv.Certs)
+	}
+	fresh, old2, broken := inv.Certs[0], inv.Certs[1], inv.Certs[2]
+	if fresh.Name != "site-fresh" || len(fresh.Hostnames) != 2 || fresh.Hostnames[1] != "10.0.0.5" || !fresh.CurrentRoot {
+		t.Errorf("site-fresh = %+v", fresh)
+	}
+	now := time.Now()
+	if fresh.NeedsRenewal(now) {
+		t.Error("freshly issued cert reported as due")
+	}
+	if old2.Name != "site-old" || !old2.NeedsRenewal(now) {
+		t.Errorf("site-old = %+v, want due for renewal", old2)
+	}
+	if broken.Name != "svc-broken" || broken.Error == "" {
+		t.Errorf("svc-broken = %+v, want an error", broken)
+	}
+	if due := inv.Due(now); len(due) != 2 {
+		t.Errorf("Due = %d certs, want 2", len(due))
+	}
+
+	// Issue re-signs a cert inside the renewal window.
+	if _, err := l.Issue(ctx, CertSpec{Name: "site-old", Hostnames: []string{"old.localhost"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !certFresh(filepath.Join(l.certDir, "site-old", "cert.pem"), now) {
+		t.Error("Issue kept a cert that was due for renewal")
+	}
+
+	// A new root leaves every existing cert on the old one.
+	if err := os.RemoveAll(l.caDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.ensureCA(); err != nil {
+		t.Fatal(err)
+	}
+	inv, _ = ReadInventory(ctx, l, l.certDir)
+	if inv.Certs[0].CurrentRoot {
+		t.Error("cert from the replaced root still reported as current")
+	}
+}
+
+func TestReadInventory_MissingDir(t *testing.T) {
+	l := newTestLocalCA(t)
+	inv, err := ReadInventory(context.Background(), l, l.certDir)
+	if err != nil || inv.Root != nil || len(inv.Certs) != 0 {
+		t.Errorf("ReadInventory on an empty home = %+v, %v", inv, err)
+	}
+}
+
+func writeCertPair(t *testing.T, dir string, cert, key []byte) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "key.pem"), key, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,398 @@
+package tls
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // G505: SubjectKeyId is an identifier, not a security boundary (RFC 5280 §4.2.1.2)
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// On-disk layout of the built-in CA under caDir.
+const (
+	localCACertFile = "rootCA.pem"
+	localCAKeyFile  = "rootCA-key.pem"
+
+	// localCATrustedMarker is written once InstallCA has installed the
+	// root into at least one trust store. There is no cheap, portable
+	// way to ask an OS store "do you trust this root?", so the marker
+	// is the best-effort CATrusted signal — the same guarantee mkcert
+	// gives by checking its rootCA.pem exists.
+	localCATrustedMarker = ".trusted"
+)
+
+// Validity periods. Leaves stay under the 825-day ceiling Apple
+// platforms enforce for TLS server certs; the root outlives many leaf
+// rotations so users are not asked to re-trust it.
+const (
+	localCAValidity   = 10 * 365 * 24 * time.Hour
+	localLeafValidity = 825 * 24 * time.Hour
+)
+
+// LocalCA is a pure-Go Provider. It generates a root CA under caDir
+// (typically ~/.locorum/ca) the first time InstallCA runs and signs leaf
+// certs with crypto/x509, so no mkcert binary is needed. Leaf certs use
+// the same ~/.locorum/certs/<name>/{cert,key}.pem layout as Mkcert, so
+// switching providers only changes who signs the next cert.
+type LocalCA struct {
+	caDir   string
+	certDir string
+
+	mu     sync.Mutex
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+}
+
+// NewLocalCA constructs a provider that keeps its root under caDir and
+// issues leaf certs under certDir.
+func NewLocalCA(caDir, certDir string) *LocalCA {
+	return &LocalCA{caDir: caDir, certDir: certDir}
+}
+
+func (l *LocalCA) rootPath() string { return filepath.Join(l.caDir, localCACertFile) }
+func (l *LocalCA) keyPath() string  { return filepath.Join(l.caDir, localCAKeyFile) }
+
+// Available reports the CA's state from two stats, so it is cheap enough
+// for the UI to poll without a cache. Installed is always true — there is
+// no external dependency to be missing.
+func (l *LocalCA) Available(_ context.Context) (Status, error) {
+	if _, err := os.Stat(l.rootPath()); err != nil {
+		return Status{
+			Installed: true,
+			CARoot:    l.caDir,
+			Message:   "Click ‘Set up trusted HTTPS’ to create Locorum's local certificate authority.",
+		}, nil
+	}
+	if _, err := os.Stat(filepath.Join(l.caDir, localCATrustedMarker)); err != nil {
+		return Status{
+			Installed: true,
+			CARoot:    l.caDir,
+			Message:   "Locorum's local certificate authority is not trusted yet. Click ‘Set up trusted HTTPS’ to install it.",
+		}, nil
+	}
+	return Status{
+		Installed: true,
+		CARoot:    l.caDir,
+		CATrusted: true,
+		Message:   "built-in CA ready",
+	}, nil
+}
+
+// Capabilities returns the zero value. The Java and Firefox-on-Windows
+// notes describe gaps in what `mkcert -install` covers and their
+// remediation is mkcert-specific; the built-in CA's trust-store coverage
+// is reported by InstallCA instead.
+func (l *LocalCA) Capabilities(_ context.Context) Capabilities {
+	return Capabilities{}
+}
+
+// Issue signs (or reuses) a cert covering spec.Hostnames. An existing
+// cert is reused only if it covers every SAN, was signed by this CA and
+// is outside the RenewBefore window, so switching over from mkcert
+// re-issues every site on its next start.
+// Unlike Mkcert, Issue does not wait for the root to be trusted: the CA
+// exists once InstallCA has run, even if a trust store refused it, and
+// the user can still import rootCA.pem by hand.
+func (l *LocalCA) Issue(_ context.Context, spec CertSpec) (CertPath, error) {
+	if len(spec.Hostnames) == 0 {
+		return CertPath{}, errors.New("at least one hostname required")
+	}
+	if !validCertName(spec.Name) {
+		return CertPath{}, fmt.Errorf("invalid cert name %q", spec.Name)
+	}
+
+	caCert, caKey, err := l.loadCA()
+	if err != nil {
+		return CertPath{}, err
+	}
+
+	targetDir := filepath.Join(l.certDir, spec.Name)
+	if err := os.MkdirAll(targetDir, 0o700); err != nil {
+		return CertPath{}, fmt.Errorf("create cert dir: %w", err)
+	}
+	certFile := filepath.Join(targetDir, "cert.pem")
+	keyFile := filepath.Join(targetDir, "key.pem")
+
+	if covered, _ := certCovers(certFile, spec.Hostnames); covered && certFresh(certFile, time.Now()) {
+		if signed, _ := certSignedBy(certFile, caCert); signed {
+			return CertPath{CertFile: certFile, KeyFile: keyFile}, nil
+		}
+	}
+
+	certPEM, keyPEM, err := signLeaf(caCert, caKey, spec.Hostnames, time.Now())
+	if err != nil {
+		return CertPath{}, err
+	}
+
+	// Same temp-dir-then-rename dance as Mkcert.Issue so a watching
+	// router never reads a half-written pair.
+	tmpDir, err := os.MkdirTemp(l.certDir, ".issue-"+spec.Name+"-")
+	if err != nil {
+		return CertPath{}, fmt.Errorf("temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	tmpCert := filepath.Join(tmpDir, "cert.pem")
+	tmpKey := filepath.Join(tmpDir, "key.pem")
+	if err := os.WriteFile(tmpCert, certPEM, 0o644); err != nil {
+		return CertPath{}, fmt.Errorf("write cert: %w", err)
+	}
+	if err := os.WriteFile(tmpKey, keyPEM, 0o600); err != nil {
+		return CertPath{}, fmt.Errorf("write key: %w", err)
+	}
+	if err := os.Rename(tmpCert, certFile); err != nil {
+		return CertPath{}, fmt.Errorf("install cert: %w", err)
+	}
+	if err := os.Rename(tmpKey, keyFile); err != nil {
+		return CertPath{}, fmt.Errorf("install key: %w", err)
+	}
+
+	slog.Info("issued cert", "name", spec.Name, "hosts", spec.Hostnames, "provider", "builtin")
+	return CertPath{CertFile: certFile, KeyFile: keyFile}, nil
+}
+
+func (l *LocalCA) Remove(_ context.Context, name string) error {
+	if !validCertName(name) {
+		return fmt.Errorf("invalid cert name %q", name)
+	}
+	if err := os.RemoveAll(filepath.Join(l.certDir, name)); err != nil {
+		return fmt.Errorf("remove cert dir: %w", err)
+	}
+	return nil
+}
+
+// RootCAPath returns the path of the built-in root certificate. Errors
+// until InstallCA has created it.
+func (l *LocalCA) RootCAPath(_ context.Context) (string, error) {
+	p := l.rootPath()
+	if _, err := os.Stat(p); err != nil {
+		return "", fmt.Errorf("%s not found: use ‘Set up trusted HTTPS’ to create the local CA", p)
+	}
+	return p, nil
+}
+
+// loadCA returns the root cert and key, reading them from disk on first
+// use. It never creates the CA — that is InstallCA's job, so a root only
+// appears after the user asked for trusted HTTPS.
+func (l *LocalCA) loadCA() (*x509.Certificate, crypto.Signer, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.caCert != nil {
+		return l.caCert, l.caKey, nil
+	}
+
+	certPEM, err := os.ReadFile(l.rootPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errors.New("local CA not created yet; use ‘Set up trusted HTTPS’")
+		}
+		return nil, nil, fmt.Errorf("read root CA: %w", err)
+	}
+	keyPEM, err := os.ReadFile(l.keyPath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("read root CA key: %w", err)
+	}
+	cert, err := parseCertPEM(certPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("root CA: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, nil, errors.New("root CA key: not a PEM file")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("root CA key: %w", err)
+	}
+	key, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.New("root CA key: unsupported key type")
+	}
+	l.caCert, l.caKey = cert, key
+	return cert, key, nil
+}
+
+// ensureCA creates the root CA if it does not exist yet and returns its
+// path. Called by InstallCA.
+func (l *LocalCA) ensureCA() (string, error) {
+	if _, err := os.Stat(l.rootPath()); err == nil {
+		return l.rootPath(), nil
+	}
+	if err := os.MkdirAll(l.caDir, 0o700); err != nil {
+		return "", fmt.Errorf("create CA dir: %w", err)
+	}
+	certPEM, keyPEM, err := newRootCA(time.Now())
+	if err != nil {
+		return "", err
+	}
+	// Key first: a root cert without its key would make every Issue fail.
+	if err := os.WriteFile(l.keyPath(), keyPEM, 0o600); err != nil {
+		return "", fmt.Errorf("write root CA key: %w", err)
+	}
+	if err := os.WriteFile(l.rootPath(), certPEM, 0o644); err != nil {
+		return "", fmt.Errorf("write root CA: %w", err)
+	}
+	l.mu.Lock()
+	l.caCert, l.caKey = nil, nil
+	l.mu.Unlock()
+	slog.Info("created local CA", "path", l.rootPath())
+	return l.rootPath(), nil
+}
+
+// newRootCA generates a self-signed root limited to signing leaf certs
+// (MaxPathLen 0). The subject carries user@host so a user looking at
+// their trust store can tell which machine a root came from.
+func newRootCA(now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate root CA key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	skid, err := subjectKeyID(&key.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization:       []string{"Locorum local CA"},
+			OrganizationalUnit: []string{ownerLabel()},
+			CommonName:         "Locorum local CA " + ownerLabel(),
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(localCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		SubjectKeyId:          skid,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create root CA: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode root CA key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// signLeaf issues a server cert for hosts. IP literals become IP SANs,
+// everything else a DNS SAN. The leaf never outlives its root.
+func signLeaf(caCert *x509.Certificate, caKey crypto.Signer, hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	notAfter := now.Add(localLeafValidity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization:       []string{"Locorum development certificate"},
+			OrganizationalUnit: []string{ownerLabel()},
+		},
+		NotBefore:      now.Add(-time.Hour),
+		NotAfter:       notAfter,
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		AuthorityKeyId: caCert.SubjectKeyId,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign cert: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// certSignedBy reports whether the PEM cert at path chains directly to
+// root.
+func certSignedBy(path string, root *x509.Certificate) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	cert, err := parseCertPEM(data)
+	if err != nil {
+		return false, err
+	}
+	return cert.CheckSignatureFrom(root) == nil, nil
+}
+
+func parseCertPEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("not a PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial: %w", err)
+	}
+	return serial, nil
+}
+
+func subjectKeyID(pub *ecdsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("encode public key: %w", err)
+	}
+	sum := sha1.Sum(der) //nolint:gosec // G401: see import comment
+	return sum[:], nil
+}
+
+// ownerLabel is "user@host", best-effort.
+func ownerLabel() string {
+	name := "locorum"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+	return name
+}
+
+var (
+	_ Provider    = (*LocalCA)(nil)
+	_ CAInstaller = (*LocalCA)(nil)
+)
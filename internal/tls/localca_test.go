@@ -0,0 +1,194 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestLocalCA(t *testing.T) *LocalCA {
+	t.Helper()
+	dir := t.TempDir()
+	return NewLocalCA(filepath.Join(dir, "ca"), filepath.Join(dir, "certs"))
+}
+
+func TestLocalCA_NoRootUntilInstall(t *testing.T) {
+	l := newTestLocalCA(t)
+	ctx := context.Background()
+
+	st, err := l.Available(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Installed || st.CATrusted {
+		t.Errorf("Available before install = %+v, want Installed && !CATrusted", st)
+	}
+	if _, err := l.Issue(ctx, CertSpec{Name: "demo", Hostnames: []string{"demo.localhost"}}); err == nil {
+		t.Error("Issue succeeded before the CA exists")
+	}
+	if _, err := l.RootCAPath(ctx); err == nil {
+		t.Error("RootCAPath succeeded before the CA exists")
+	}
+}
+
+func TestLocalCA_IssueVerifiesAgainstRoot(t *testing.T) {
+	l := newTestLocalCA(t)
+	ctx := context.Background()
+	if _, err := l.ensureCA(); err != nil {
+		t.Fatal(err)
+	}
+	rootPath, err := l.RootCAPath(ctx)
+	if err != nil {
+		t.Fatalf("RootCAPath: %v", err)
+	}
+	rootPEM, _ := os.ReadFile(rootPath)
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(rootPEM) {
+		t.Fatal("root CA is not valid PEM")
+	}
+
+	cp, err := l.Issue(ctx, CertSpec{Name: "demo", Hostnames: []string{"demo.localhost", "*.demo.localhost", "192.168.1.20"}})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	certPEM, _ := os.ReadFile(cp.CertFile)
+	leaf, err := parseCertPEM(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"demo.localhost", "shop.demo.localhost", "192.168.1.20"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: pool}); err != nil {
+			t.Errorf("leaf does not verify for %s: %v", host, err)
+		}
+	}
+	if leaf.NotAfter.Sub(leaf.NotBefore) > localLeafValidity+time.Hour {
+		t.Errorf("leaf validity %v exceeds %v", leaf.NotAfter.Sub(leaf.NotBefore), localLeafValidity)
+	}
+	if info, err := os.Stat(cp.KeyFile); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key.pem mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+
+	// Same SANs: reused byte-for-byte. A new SAN: re-issued.
+	again, err := l.Issue(ctx, CertSpec{Name: "demo", Hostnames: []string{"demo.localhost"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := os.ReadFile(again.CertFile); !bytes.Equal(body, certPEM) {
+		t.Error("Issue re-signed a cert that already covered the SANs")
+	}
+	if _, err := l.Issue(ctx, CertSpec{Name: "demo", Hostnames: []string{"demo.localhost", "other.localhost"}}); err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := os.ReadFile(cp.CertFile); bytes.Equal(body, certPEM) {
+		t.Error("Issue kept a cert missing a requested SAN")
+	}
+
+	if err := l.Remove(ctx, "demo"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(cp.CertFile)); !os.IsNotExist(err) {
+		t.Errorf("cert dir still present after Remove (err=%v)", err)
+	}
+}
+
+// TestLocalCA_ReissuesForeignCert covers switching over from mkcert: a
+// cert with the right SANs but a different signer must be replaced.
+func TestLocalCA_ReissuesForeignCert(t *testing.T) {
+	l := newTestLocalCA(t)
+	ctx := context.Background()
+	if _, err := l.ensureCA(); err != nil {
+		t.Fatal(err)
+	}
+
+	otherPEM, otherKeyPEM, err := newRootCA(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := parseCertPEM(otherPEM)
+	otherKey := NewLocalCA(t.TempDir(), t.TempDir())
+	if err := os.MkdirAll(otherKey.caDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(otherKey.rootPath(), otherPEM, 0o644)
+	_ = os.WriteFile(otherKey.keyPath(), otherKeyPEM, 0o600)
+	_, signer, err := otherKey.loadCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, foreignKey, err := signLeaf(other, signer, []string{"demo.localhost"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(l.certDir, "demo")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "cert.pem"), foreign, 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "key.pem"), foreignKey, 0o600)
+
+	cp, err := l.Issue(ctx, CertSpec{Name: "demo", Hostnames: []string{"demo.localhost"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _, _ := l.loadCA()
+	if ok, _ := certSignedBy(cp.CertFile, root); !ok {
+		t.Error("foreign cert was kept instead of being re-issued by the local CA")
+	}
+}
+
+func TestLocalCA_TrustedMarker(t *testing.T) {
+	l := newTestLocalCA(t)
+	if _, err := l.ensureCA(); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := l.Available(context.Background())
+	if st.CATrusted {
+		t.Error("CATrusted before any trust store accepted the root")
+	}
+	if err := os.WriteFile(filepath.Join(l.caDir, localCATrustedMarker), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ = l.Available(context.Background()); !st.CATrusted {
+		t.Errorf("Available after install = %+v, want CATrusted", st)
+	}
+}
+
+func TestLocalCATrustCommands(t *testing.T) {
+	darwin, err := localCATrustCommands("darwin", "/Users/u", "/ca/rootCA.pem")
+	if err != nil || len(darwin) != 1 || darwin[0][0] != "security" || darwin[0][len(darwin[0])-1] != "/ca/rootCA.pem" {
+		t.Errorf("darwin = %v, %v", darwin, err)
+	}
+	windows, err := localCATrustCommands("windows", `C:\Users\u`, `C:\ca\rootCA.pem`)
+	if err != nil || len(windows) != 1 || windows[0][0] != "certutil" || windows[0][1] != "-user" {
+		t.Errorf("windows = %v, %v", windows, err)
+	}
+	if _, err := localCATrustCommands("plan9", "/", "/ca/rootCA.pem"); err == nil {
+		t.Error("expected an unsupported platform to error")
+	}
+}
+
+func TestNSSDatabases(t *testing.T) {
+	home := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join(home, ".pki", "nssdb"),
+		filepath.Join(home, ".mozilla", "firefox", "abc.default"),
+		filepath.Join(home, ".mozilla", "firefox", "legacy.profile"),
+	} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.WriteFile(filepath.Join(home, ".pki", "nssdb", "cert9.db"), nil, 0o600)
+	_ = os.WriteFile(filepath.Join(home, ".mozilla", "firefox", "abc.default", "cert9.db"), nil, 0o600)
+	// legacy.profile only has the old cert8.db format and is skipped.
+	_ = os.WriteFile(filepath.Join(home, ".mozilla", "firefox", "legacy.profile", "cert8.db"), nil, 0o600)
+
+	got := nssDatabases(home)
+	if len(got) != 2 {
+		t.Fatalf("nssDatabases = %v, want 2 entries", got)
+	}
+}
@@ -115,7 +115,8 @@ func (m *Mkcert) detect(ctx context.Context) (string, Status) {
 
 // Issue generates (or reuses) a cert covering spec.Hostnames. Idempotent: if
 // the existing cert at ~/.locorum/certs/<spec.Name>/cert.pem already covers
-// every requested SAN, it is returned unchanged. Otherwise mkcert is invoked,
+// every requested SAN, is outside the RenewBefore window and was signed by
+// the current mkcert root, it is returned unchanged. Otherwise mkcert is invoked,
 // the new cert and key are written to a sibling temp dir, then atomically
 // moved into place so a watching router never reads a half-written file.
 func (m *Mkcert) Issue(ctx context.Context, spec CertSpec) (CertPath, error) {
@@ -157,7 +158,7 @@ func (m *Mkcert) Issue(ctx context.Context, spec CertSpec) (CertPath, error) {
 	certFile := filepath.Join(targetDir, "cert.pem")
 	keyFile := filepath.Join(targetDir, "key.pem")
 
-	if covered, _ := certCovers(certFile, spec.Hostnames); covered {
+	if covered, _ := certCovers(certFile, spec.Hostnames); covered && certFresh(certFile, time.Now()) && m.signedByRoot(certFile, status.CARoot) {
 		return CertPath{CertFile: certFile, KeyFile: keyFile}, nil
 	}
 
@@ -262,6 +263,22 @@ func (m *Mkcert) RootCAPath(ctx context.Context) (string, error) {
 	return rootCA, nil
 }
 
+// signedByRoot reports whether the cert at path was signed by the root in
+// caRoot. An unreadable root gives the cert the benefit of the doubt: the
+// check exists to catch a regenerated CAROOT, not to block issuance.
+func (m *Mkcert) signedByRoot(path, caRoot string) bool {
+	data, err := os.ReadFile(filepath.Join(caRoot, "rootCA.pem"))
+	if err != nil {
+		return true
+	}
+	root, err := parseCertPEM(data)
+	if err != nil {
+		return true
+	}
+	signed, _ := certSignedBy(path, root)
+	return signed
+}
+
 func (m *Mkcert) Remove(_ context.Context, name string) error {
 	if !validCertName(name) {
 		return fmt.Errorf("invalid cert name %q", name)
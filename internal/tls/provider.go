@@ -1,7 +1,8 @@
 // Package tls handles certificate lifecycle for the global routing layer.
 //
 // The Provider interface abstracts cert issuance so the router can run with
-// (mkcert) or without (HTTP-only fallback) trusted certs. Providers must be
+// (mkcert or the built-in LocalCA) or without (HTTP-only fallback) trusted
+// certs. Providers must be
 // safe for concurrent use.
 package tls
 
@@ -72,3 +73,10 @@ type Status struct {
 
 // IsZero reports whether p has no usable file paths.
 func (p CertPath) IsZero() bool { return p.CertFile == "" && p.KeyFile == "" }
+
+// CAInstaller is implemented by providers that can put their root CA into
+// the host trust stores on request. It backs the "Set up trusted HTTPS"
+// action; providers without it leave trust to the user.
+type CAInstaller interface {
+	InstallCA(ctx context.Context) error
+}
@@ -9,11 +9,26 @@ type Site struct {
 	PublicDir string `json:"publicDir"`
 	Started   bool   `json:"started"`
 
-	PHPVersion   string `json:"phpVersion"`
-	RedisVersion string `json:"redisVersion"`
-	DBPassword   string `json:"dbPassword"`
-	WebServer    string `json:"webServer"` // "nginx" or "apache"
-	Multisite    string `json:"multisite"` // "", "subdirectory", or "subdomain"
+	PHPVersion string `json:"phpVersion"`
+	DBPassword string `json:"dbPassword"`
+	WebServer  string `json:"webServer"` // "nginx" or "apache"
+	Multisite  string `json:"multisite"` // "", "subdirectory", or "subdomain"
+
+	// CacheBackend is the object cache service ("redis", "valkey",
+	// "memcached" or "none"). Read through cachebackend.Resolve(site),
+	// which falls back to Redis for legacy rows.
+	CacheBackend string `json:"cacheBackend"`
+
+	// CacheVersion is the backend-specific version tag (e.g. "8.0",
+	// "1.6"). Empty when CacheBackend is "none".
+	CacheVersion string `json:"cacheVersion"`
+
+	// RedisVersion is the pre-cache-backend field. Kept readable for one
+	// minor release and mirrored from CacheVersion while the backend is
+	// Redis, so external tooling reading redisVersion keeps working.
+	//
+	// Deprecated: use CacheVersion + CacheBackend.
+	RedisVersion string `json:"redisVersion,omitempty"`
 
 	// DBEngine is the database engine name ("mysql" or "mariadb"). Read
 	// through dbengine.Resolve(site) which falls back to MySQL for legacy
@@ -40,6 +55,16 @@ type Site struct {
 	// applied at next start; mid-life toggling is rejected upstream.
 	SPXEnabled bool `json:"spxEnabled"`
 
+	// XdebugEnabled opts the site in to the Xdebug extension. Like
+	// SPXEnabled it is applied at next start.
+	XdebugEnabled bool `json:"xdebugEnabled"`
+
+	// XdebugMode is the xdebug.mode value used while XdebugEnabled is
+	// true: "debug", "profile", "trace" or "coverage". Retained when
+	// the toggle is switched off so a re-enable can default to the
+	// last mode the user picked.
+	XdebugMode string `json:"xdebugMode,omitempty"`
+
 	// LanEnabled opts the site in to LAN access (ACCESS.md). When true
 	// and the host has a usable IPv4 LAN address, an additional
 	// `<slug>.<lan-ip-dashed>.<lan-domain>` hostname is added to the
@@ -55,6 +80,32 @@ type Site struct {
 	// never logged.
 	SPXKey string `json:"-"`
 
+	// AuthEnabled puts the site behind HTTP basic auth at the router.
+	// AuthUser / AuthPassword are generated on first enable and kept
+	// when the toggle is switched off, like SPXKey. AuthPassword and
+	// its bcrypt AuthHash (what the router config carries) are
+	// credentials and never serialised.
+	AuthEnabled  bool   `json:"authEnabled"`
+	AuthUser     string `json:"authUser,omitempty"`
+	AuthPassword string `json:"-"`
+	AuthHash     string `json:"-"`
+
+	// IPAllowlist restricts which client networks (CIDRs) the router
+	// lets through. Empty means no restriction. Loopback and Docker's
+	// own ranges are always allowed on top so the host keeps access.
+	IPAllowlist []string `json:"ipAllowlist,omitempty"`
+
+	// MailMode decides where the site's outgoing mail goes: "" or
+	// "capture" keeps it in the global mail container, "relay" also
+	// delivers mail for MailRelayDomains through the MailRelayHost
+	// smarthost, "blackhole" discards it. MailRelayPassword is a
+	// credential and never serialised.
+	MailMode          string   `json:"mailMode,omitempty"`
+	MailRelayHost     string   `json:"mailRelayHost,omitempty"`
+	MailRelayUser     string   `json:"mailRelayUser,omitempty"`
+	MailRelayPassword string   `json:"-"`
+	MailRelayDomains  []string `json:"mailRelayDomains,omitempty"`
+
 	// Salts is a JSON-encoded map[string]string of the eight WordPress
 	// secret keys (AUTH_KEY, SECURE_AUTH_KEY, …, NONCE_SALT). Generated
 	// once at site creation and persisted so wp-config.php regenerates
@@ -84,6 +135,47 @@ type Site struct {
 	// leave it empty.
 	ParentSiteID string `json:"parentSiteID,omitempty"`
 
+	// Resources overrides the per-role container caps (memory, CPU,
+	// pids). Keyed by docker.ResourceKey*; nil means every container
+	// runs with the built-in defaults. Applied at next start — a
+	// change alters the container's ConfigHash and forces a recreate.
+	Resources ResourceLimits `json:"resources,omitempty"`
+
+	// PHPIni holds per-site php.ini directives (directive → value),
+	// rendered to a file mounted after the shared php.ini so they win
+	// over it. Nil means the site uses the shared file unchanged.
+	PHPIni map[string]string `json:"phpIni,omitempty"`
+
+	// PHPExtensionsEnable and PHPExtensionsDisable switch wodby/php
+	// extensions on or off for this site via PHP_EXTENSIONS_ENABLE /
+	// PHP_EXTENSIONS_DISABLE. Xdebug and SPX are excluded — they have
+	// their own toggles. Applied at next start.
+	PHPExtensionsEnable  []string `json:"phpExtensionsEnable,omitempty"`
+	PHPExtensionsDisable []string `json:"phpExtensionsDisable,omitempty"`
+
+	// Aliases are extra hostnames the site answers on besides Domain —
+	// e.g. "client-site.test" or "*.client-site.test". Each becomes a
+	// router rule and a cert SAN, and is whitelisted for WP_HOME.
+	Aliases []string `json:"aliases,omitempty"`
+
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
 }
+
+// ResourceLimit overrides the caps of one container role. A zero field
+// keeps the role's built-in default.
+type ResourceLimit struct {
+	MemoryMB int64   `json:"memoryMB,omitempty"`
+	CPUs     float64 `json:"cpus,omitempty"`
+	Pids     int64   `json:"pids,omitempty"`
+}
+
+// IsZero reports whether l overrides nothing.
+func (l ResourceLimit) IsZero() bool {
+	return l.MemoryMB == 0 && l.CPUs == 0 && l.Pids == 0
+}
+
+// ResourceLimits maps a resource key ("all", "php", "web", "database",
+// "cache") to its override. The "all" entry applies to every container
+// of the site; role entries win field-by-field over it.
+type ResourceLimits map[string]ResourceLimit
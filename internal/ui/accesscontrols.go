@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// AccessControlsEditor manages a site's router-level access controls:
+// HTTP basic auth and the client IP allowlist. Both apply to a running
+// site in place, like the custom-domains editor.
+type AccessControlsEditor struct {
+	state  *UIState
+	sm     *sites.SiteManager
+	toasts *Notifications
+
+	authOnBtn   widget.Clickable
+	authOffBtn  widget.Clickable
+	rotateBtn   widget.Clickable
+	copyPassBtn widget.Clickable
+	passSel     widget.Selectable
+
+	allowlist widget.Editor
+	useLANBtn widget.Clickable
+	saveBtn   widget.Clickable
+
+	busy atomic.Bool
+
+	lastSiteID string
+	initial    string
+}
+
+func NewAccessControlsEditor(state *UIState, sm *sites.SiteManager, toasts *Notifications) *AccessControlsEditor {
+	return &AccessControlsEditor{state: state, sm: sm, toasts: toasts}
+}
+
+// HandleUserInteractions processes the panel's buttons. Must be called
+// once per frame, before Layout.
+func (ae *AccessControlsEditor) HandleUserInteractions(gtx layout.Context, site *types.Site) {
+	if ae.copyPassBtn.Clicked(gtx) && site.AuthPassword != "" {
+		CopyToClipboard(gtx, site.AuthPassword)
+		ae.toasts.ShowInfo("Password copied to clipboard")
+	}
+	if ae.useLANBtn.Clicked(gtx) {
+		if lan := ae.sm.LANAllowlist(); lan != "" {
+			ae.allowlist.SetText(lan)
+		} else {
+			ae.state.ShowError("No LAN address detected; enter the allowed networks by hand.")
+		}
+	}
+	if ae.busy.Load() {
+		return
+	}
+	siteID := site.ID
+	switch {
+	case ae.authOnBtn.Clicked(gtx):
+		ae.run("Basic auth enabled.", func(ctx context.Context) error { return ae.sm.SetBasicAuth(ctx, siteID, true) })
+	case ae.authOffBtn.Clicked(gtx):
+		ae.run("Basic auth disabled.", func(ctx context.Context) error { return ae.sm.SetBasicAuth(ctx, siteID, false) })
+	case ae.rotateBtn.Clicked(gtx):
+		ae.run("Password rotated.", func(ctx context.Context) error { return ae.sm.RotateAuthPassword(ctx, siteID) })
+	case ae.saveBtn.Clicked(gtx) && ae.isDirty():
+		entries := parseAliasList(ae.allowlist.Text())
+		ae.initial = strings.TrimSpace(ae.allowlist.Text())
+		ae.run("Allowlist saved.", func(ctx context.Context) error { return ae.sm.SetIPAllowlist(ctx, siteID, entries) })
+	}
+}
+
+// run performs fn in the background, reporting failure as an error
+// banner and success as a toast.
+func (ae *AccessControlsEditor) run(success string, fn func(ctx context.Context) error) {
+	ae.busy.Store(true)
+	go func() {
+		defer ae.busy.Store(false)
+		defer ae.state.Invalidate()
+		if err := fn(context.Background()); err != nil {
+			ae.state.ShowError("Failed to update access controls: " + err.Error())
+			return
+		}
+		ae.toasts.ShowSuccess(success)
+	}()
+}
+
+func (ae *AccessControlsEditor) Layout(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	if ae.lastSiteID != site.ID {
+		ae.lastSiteID = site.ID
+		ae.allowlist.SetText(strings.Join(site.IPAllowlist, "\n"))
+		ae.initial = strings.TrimSpace(ae.allowlist.Text())
+	}
+	busy := ae.busy.Load()
+
+	body := func(gtx layout.Context, text string) layout.Dimensions {
+		lbl := material.Body2(th.Theme, text)
+		lbl.Color = th.Color.Fg2
+		lbl.TextSize = th.Sizes.Body
+		return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, lbl.Layout)
+	}
+
+	return panel(gtx, th, "Access controls", func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return body(gtx, "Protect this site when it is reachable from other devices. Basic auth asks every browser for a password; "+
+					"\"Open admin\" passes it along automatically.")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return ae.layoutAuth(gtx, th, site, busy)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return body(gtx, "Allowed networks, one per line (CIDR or IP). Leave empty to allow any network. "+
+						"This machine (loopback and the router network's gateway) is always allowed.")
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !ae.sm.ClientsNATed() {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Body2(th.Theme, "Docker Desktop routes LAN clients through its VM ("+sites.DockerDesktopVMNet+"), "+
+						"so the allowlist cannot tell them apart from this machine. Use basic auth to keep them out.")
+					lbl.Color = th.Color.Warn
+					lbl.TextSize = th.Sizes.Body
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return BorderedMonoEditor(gtx, th, &ae.allowlist, "192.168.1.0/24")
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if busy {
+							return Loader(gtx, th, th.Dims.LoaderSizeSM)
+						}
+						return th.PrimaryGated(gtx, &ae.saveBtn, "Save Allowlist", ae.isDirty())
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Inset{Left: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return th.Small(gtx, &ae.useLANBtn, "Use LAN subnet")
+						})
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (ae *AccessControlsEditor) layoutAuth(gtx layout.Context, th *Theme, site *types.Site, busy bool) layout.Dimensions {
+	statusKey, statusLabel := StatusErr, "Basic auth off"
+	if site.AuthEnabled {
+		statusKey, statusLabel = StatusOk, "Basic auth on"
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return spxStatusPill(gtx, th, statusKey, statusLabel)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Left: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						switch {
+						case busy:
+							return Loader(gtx, th, th.Dims.LoaderSizeSM)
+						case site.AuthEnabled:
+							return th.Small(gtx, &ae.authOffBtn, "Disable basic auth")
+						default:
+							return th.Primary(gtx, &ae.authOnBtn, "Enable basic auth")
+						}
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !site.AuthEnabled || busy {
+						return layout.Dimensions{}
+					}
+					return layout.Inset{Left: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return th.Small(gtx, &ae.rotateBtn, "Rotate password")
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !site.AuthEnabled {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Top: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return credentialRow(gtx, th, "User", site.AuthUser, nil, nil)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return credentialRow(gtx, th, "Password", site.AuthPassword, &ae.passSel, &ae.copyPassBtn)
+					}),
+				)
+			})
+		}),
+	)
+}
+
+func (ae *AccessControlsEditor) isDirty() bool {
+	return strings.TrimSpace(ae.allowlist.Text()) != ae.initial
+}
+
+// credentialRow is a Label/value row with an optional Copy button.
+func credentialRow(gtx layout.Context, th *Theme, label, value string, sel *widget.Selectable, copyBtn *widget.Clickable) layout.Dimensions {
+	return layout.Inset{Bottom: th.Spacing.XS}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Dp(th.Dims.LabelColWidth)
+				lbl := material.Body2(th.Theme, label)
+				lbl.Color = th.Color.TextSecondary
+				lbl.TextSize = th.Sizes.Base
+				return lbl.Layout(gtx)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				if sel == nil {
+					lbl := material.Body2(th.Theme, value)
+					lbl.Color = th.Color.Fg
+					lbl.TextSize = th.Sizes.Base
+					lbl.Font = MonoFont
+					return lbl.Layout(gtx)
+				}
+				return SelectableLabel(gtx, th, sel, value, th.Sizes.Base, th.Color.Fg, MonoFont)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if copyBtn == nil {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Left: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return SmallButton(gtx, th, copyBtn, "Copy")
+				})
+			}),
+		)
+	})
+}
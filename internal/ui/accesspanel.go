@@ -19,8 +19,8 @@ import (
 	"github.com/PeterBooker/locorum/internal/utils"
 )
 
-// AccessPanel is the per-site Access tab. It owns the LAN-access toggle,
-// the URL row, two QR-code cards (the site URL and the "install root CA"
+// AccessPanel is the per-site Access tab. It owns the custom-domains
+// editor, the LAN-access toggle, the basic-auth / allowlist controls, the URL row, two QR-code cards (the site URL and the "install root CA"
 // URL served by capairing), and the WSL/IP-detection notices.
 //
 // Long-running operations (toggle, refresh IP, start CA pairing server)
@@ -31,6 +31,9 @@ type AccessPanel struct {
 	sm     *sites.SiteManager
 	toasts *Notifications
 
+	aliases  *AliasEditor
+	controls *AccessControlsEditor
+
 	enableBtn   widget.Clickable
 	disableBtn  widget.Clickable
 	refreshBtn  widget.Clickable
@@ -57,7 +60,13 @@ type AccessPanel struct {
 // NewAccessPanel constructs an AccessPanel. State + SiteManager are
 // required; toasts may be nil.
 func NewAccessPanel(state *UIState, sm *sites.SiteManager, toasts *Notifications) *AccessPanel {
-	return &AccessPanel{state: state, sm: sm, toasts: toasts}
+	return &AccessPanel{
+		state:    state,
+		sm:       sm,
+		toasts:   toasts,
+		aliases:  NewAliasEditor(state, sm, toasts),
+		controls: NewAccessControlsEditor(state, sm, toasts),
+	}
 }
 
 // HandleUserInteractions processes button clicks. Must be called once
@@ -66,10 +75,12 @@ func (ap *AccessPanel) HandleUserInteractions(gtx layout.Context, site *types.Si
 	if site == nil {
 		return
 	}
+	ap.aliases.HandleUserInteractions(gtx, site)
 	if utils.IsWSL() {
 		// LAN access on WSL2 is gated; ignore button clicks defensively.
 		return
 	}
+	ap.controls.HandleUserInteractions(gtx, site)
 
 	if ap.enableBtn.Clicked(gtx) && !ap.state.IsSiteLanToggling(site.ID) {
 		ap.toggleLAN(site.ID, true)
@@ -112,14 +123,26 @@ func (ap *AccessPanel) Layout(gtx layout.Context, th *Theme, site *types.Site) l
 	ap.lastSiteID = site.ID
 	ap.mu.Unlock()
 
+	aliases := func(gtx layout.Context) layout.Dimensions {
+		return ap.aliases.Layout(gtx, th, site)
+	}
 	if utils.IsWSL() {
-		return ap.layoutWSLNotice(gtx, th)
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(aliases),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return ap.layoutWSLNotice(gtx, th)
+			}),
+		)
 	}
 
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(aliases),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return ap.layoutToggleCard(gtx, th, site)
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return ap.controls.Layout(gtx, th, site)
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			if site.Multisite != "subdomain" {
 				return layout.Dimensions{}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// AliasEditor edits the extra hostnames a site answers on. Unlike the
+// stopped-only editors on the Overview tab it works on a running site:
+// SetAliases re-routes and re-issues the cert in place.
+type AliasEditor struct {
+	state  *UIState
+	sm     *sites.SiteManager
+	toasts *Notifications
+
+	aliases widget.Editor
+	saveBtn widget.Clickable
+	saving  atomic.Bool
+
+	lastSiteID string
+	initial    string
+}
+
+func NewAliasEditor(state *UIState, sm *sites.SiteManager, toasts *Notifications) *AliasEditor {
+	return &AliasEditor{state: state, sm: sm, toasts: toasts}
+}
+
+func (ae *AliasEditor) Layout(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	if ae.lastSiteID != site.ID {
+		ae.lastSiteID = site.ID
+		ae.sync(site)
+	}
+	dirty := ae.isDirty()
+
+	return panel(gtx, th, "Custom domains", func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				hint := "Extra hostnames for this site, one per line. Each is added to the router rule and the TLS certificate. " +
+					"Names under .localhost resolve on their own; others need a hosts-file entry (Settings → Network & TLS can manage one) " +
+					"or a DNS record. A leading \"*.\" matches any single subdomain but can't go in a hosts file."
+				lbl := material.Body2(th.Theme, hint)
+				lbl.Color = th.Color.Fg2
+				lbl.TextSize = th.Sizes.Body
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, lbl.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return BorderedMonoEditor(gtx, th, &ae.aliases, "shop.test\nwww.shop.test")
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if ae.saving.Load() {
+					return Loader(gtx, th, th.Dims.LoaderSizeSM)
+				}
+				return th.PrimaryGated(gtx, &ae.saveBtn, "Save Domains", dirty)
+			}),
+		)
+	})
+}
+
+// HandleUserInteractions processes the Save button. Must be called once
+// per frame, before Layout.
+func (ae *AliasEditor) HandleUserInteractions(gtx layout.Context, site *types.Site) {
+	if !ae.saveBtn.Clicked(gtx) || !ae.isDirty() || ae.saving.Load() {
+		return
+	}
+	aliases := sites.NormaliseAliases(parseAliasList(ae.aliases.Text()))
+	ae.initial = strings.TrimSpace(ae.aliases.Text())
+	ae.saving.Store(true)
+	siteID := site.ID
+	go func() {
+		defer ae.saving.Store(false)
+		defer ae.state.Invalidate()
+		if err := ae.sm.SetAliases(context.Background(), siteID, aliases); err != nil {
+			ae.state.ShowError("Failed to update domains: " + err.Error())
+			return
+		}
+		ae.toasts.ShowSuccess("Domains saved.")
+	}()
+}
+
+func (ae *AliasEditor) sync(site *types.Site) {
+	ae.aliases.SetText(strings.Join(site.Aliases, "\n"))
+	ae.initial = strings.TrimSpace(ae.aliases.Text())
+}
+
+func (ae *AliasEditor) isDirty() bool {
+	return strings.TrimSpace(ae.aliases.Text()) != ae.initial
+}
+
+// parseAliasList splits the editor text on newlines, commas and spaces.
+func parseAliasList(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ',' || r == ' ' || r == '\t'
+	})
+}
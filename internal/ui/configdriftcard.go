@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// ConfigDriftCard is the Overview-tab card shown while a site's
+// config.yaml carries hand edits Locorum hasn't applied. It lists the
+// per-field diff and offers to apply the YAML side or keep the current
+// settings. Renders nothing when there is no drift.
+type ConfigDriftCard struct {
+	state  *UIState
+	sm     *sites.SiteManager
+	toasts *Notifications
+
+	applyBtn   widget.Clickable
+	discardBtn widget.Clickable
+	busy       atomic.Bool
+}
+
+// NewConfigDriftCard constructs the card.
+func NewConfigDriftCard(state *UIState, sm *sites.SiteManager, toasts *Notifications) *ConfigDriftCard {
+	return &ConfigDriftCard{state: state, sm: sm, toasts: toasts}
+}
+
+// HandleUserInteractions processes the Apply / Keep buttons.
+func (c *ConfigDriftCard) HandleUserInteractions(gtx layout.Context, site *types.Site) {
+	drift := c.state.ConfigDrift(site.ID)
+	if drift == nil {
+		return
+	}
+	if c.applyBtn.Clicked(gtx) && c.canApply(drift, site) && c.busy.CompareAndSwap(false, true) {
+		id := site.ID
+		go func() {
+			defer c.busy.Store(false)
+			if _, err := c.sm.ApplyConfigYAML(context.Background(), id); err != nil {
+				c.state.ShowError("Could not apply config.yaml: " + err.Error())
+				return
+			}
+			c.toasts.ShowSuccess("Applied config.yaml changes")
+		}()
+	}
+	if c.discardBtn.Clicked(gtx) && c.busy.CompareAndSwap(false, true) {
+		id := site.ID
+		go func() {
+			defer c.busy.Store(false)
+			if err := c.sm.DiscardConfigYAML(id); err != nil {
+				c.state.ShowError("Could not rewrite config.yaml: " + err.Error())
+			}
+		}()
+	}
+}
+
+func (c *ConfigDriftCard) canApply(drift *sites.ConfigDrift, site *types.Site) bool {
+	return drift.Applicable() && !(drift.NeedsStop() && site.Started)
+}
+
+// Layout renders the card.
+func (c *ConfigDriftCard) Layout(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	drift := c.state.ConfigDrift(site.ID)
+	if drift == nil {
+		return layout.Dimensions{}
+	}
+	hint := "config.yaml was edited after Locorum last wrote it. Locorum won't overwrite it until you choose."
+	if drift.NeedsStop() && site.Started {
+		hint = "config.yaml was edited after Locorum last wrote it. Stop the site to apply these changes."
+	}
+
+	rows := make([]layout.FlexChild, 0, len(drift.Changes)+2)
+	rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Body2(th.Theme, hint)
+		lbl.Color = th.Color.Fg2
+		lbl.TextSize = th.Sizes.Body
+		return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, lbl.Layout)
+	}))
+	for _, ch := range drift.Changes {
+		rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return configDriftRow(gtx, th, ch)
+		}))
+	}
+	rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		enabled := !c.busy.Load()
+		return layout.Inset{Top: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return th.PrimaryGated(gtx, &c.applyBtn, "Apply changes", enabled && c.canApply(drift, site))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Left: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return th.SmallGated(gtx, &c.discardBtn, "Keep current settings", enabled)
+					})
+				}),
+			)
+		})
+	}))
+
+	return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return panel(gtx, th, "config.yaml changed", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+		})
+	})
+}
+
+// configDriftRow renders "field: current → proposed", with the reason
+// underneath when the change can't be applied.
+func configDriftRow(gtx layout.Context, th *Theme, ch sites.ConfigFieldChange) layout.Dimensions {
+	return layout.Inset{Bottom: th.Spacing.XS}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Body2(th.Theme, ch.Field+":  "+orDash(ch.Current)+"  →  "+orDash(ch.Proposed))
+				lbl.Color = th.Color.Fg
+				lbl.TextSize = th.Sizes.Body
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if ch.Blocked == "" {
+					return layout.Dimensions{}
+				}
+				lbl := material.Caption(th.Theme, "Not applied: "+ch.Blocked)
+				lbl.Color = th.Color.Warn
+				lbl.TextSize = th.Sizes.XS
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
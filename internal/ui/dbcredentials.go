@@ -9,6 +9,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 
+	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/types"
 )
@@ -51,9 +52,17 @@ func (dc *DBCredentials) Bind(sm *sites.SiteManager, state *UIState, toasts *Not
 // credItems returns the list of credential rows shown in the Database
 // section.
 func (dc *DBCredentials) credItems(site *types.Site) []KV {
+	if dbengine.Resolve(site).Kind() == dbengine.SQLite {
+		// No server, no credentials: the database is a file in the PHP
+		// container, and there is no port to publish.
+		return []KV{
+			{"Database File", dbengine.SQLitePath()},
+			{"Container", "locorum-" + site.Slug + "-php"},
+		}
+	}
 	rows := []KV{
 		{"Hostname", "database"},
-		{"Adminer Host", "locorum-" + site.Slug + "-database"},
+		{"DB UI Host", "locorum-" + site.Slug + "-database"},
 		{"Database", "wordpress"},
 		{"User", "wordpress"},
 		{"Password", site.DBPassword},
@@ -13,7 +13,7 @@ import (
 
 // hookServiceOptions is the dropdown list for task_type=exec. "" maps to
 // "php" by default.
-var hookServiceOptions = []string{"php", "web", "database", "redis"}
+var hookServiceOptions = []string{"php", "web", "database", "redis", "valkey", "memcached"}
 
 // hookTaskTypeOptions presents task types in a stable order.
 var hookTaskTypeOptions = []string{"exec", "exec-host", "wp-cli"}
@@ -81,7 +81,7 @@ func NewLogViewer(state *UIState, sm *sites.SiteManager) *LogViewer {
 	lv := &LogViewer{
 		state:           state,
 		sm:              sm,
-		serviceDropdown: NewDropdown([]string{"web", "php", "database", "redis"}),
+		serviceDropdown: NewDropdown([]string{"web", "php", "database", "redis", "valkey", "memcached"}),
 		output:          NewOutputView(),
 		ring:            make([]logLineCached, logViewerRingSize),
 		highlight:       true,
@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"context"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gioui.org/font"
@@ -10,22 +13,21 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 
+	"github.com/PeterBooker/locorum/internal/cachebackend"
+	"github.com/PeterBooker/locorum/internal/config"
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/platform"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/types"
 )
 
-var (
-	phpVersions   = []string{"8.4", "8.3", "8.2", "8.1", "8.0", "7.4"}
-	redisVersions = []string{"8.0", "7.4", "7.2"}
-)
+var phpVersions = []string{"8.4", "8.3", "8.2", "8.1", "8.0", "7.4"}
 
 // dbEngineOptions / dbEngineKinds are the parallel slices the engine
 // dropdown reads. Display names are user-facing; kinds are persisted.
 var (
-	dbEngineOptions = []string{"MySQL", "MariaDB"}
-	dbEngineKinds   = []dbengine.Kind{dbengine.MySQL, dbengine.MariaDB}
+	dbEngineOptions = []string{"MySQL", "MariaDB", "SQLite"}
+	dbEngineKinds   = []dbengine.Kind{dbengine.MySQL, dbengine.MariaDB, dbengine.SQLite}
 )
 
 // dbVersionsFor returns the version dropdown options for an engine kind.
@@ -35,6 +37,43 @@ func dbVersionsFor(k dbengine.Kind) []string {
 	return dbengine.MustFor(k).KnownVersions()
 }
 
+// cacheBackendOptions / cacheBackendKinds are the parallel slices the
+// object cache dropdown reads, in cachebackend.AllKinds order.
+var (
+	cacheBackendOptions = []string{"Redis", "Valkey", "Memcached", "None"}
+	cacheBackendKinds   = []cachebackend.Kind{cachebackend.Redis, cachebackend.Valkey, cachebackend.Memcached, cachebackend.None}
+)
+
+// indexOfCacheBackend maps a persisted backend name onto its dropdown
+// index, or 0 (Redis) when unknown.
+func indexOfCacheBackend(name string) int {
+	for i, k := range cacheBackendKinds {
+		if string(k) == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// defaultCacheVersionIndex picks the version pre-selected for backend k.
+// The saved Redis default only applies to Redis; the other backends open
+// on their newest version.
+func defaultCacheVersionIndex(cfg *config.Config, k cachebackend.Kind, versions []string) int {
+	if cfg != nil && k == cachebackend.Redis {
+		return indexOfOr(versions, cfg.RedisVersionDefault(), 0)
+	}
+	return 0
+}
+
+// selectedOption returns options[i], or "" when the list is empty — the
+// "None" cache backend has no versions to pick from.
+func selectedOption(options []string, i int) string {
+	if i < 0 || i >= len(options) {
+		return ""
+	}
+	return options[i]
+}
+
 type NewSiteModal struct {
 	state  *UIState
 	sm     *sites.SiteManager
@@ -49,17 +88,21 @@ type NewSiteModal struct {
 	phpDropdown       *Dropdown
 	dbEngineDropdown  *Dropdown
 	dbVersionDropdown *Dropdown
-	redisDropdown     *Dropdown
+	cacheDropdown     *Dropdown
+	cacheVerDropdown  *Dropdown
 	webServerDropdown *Dropdown
 	multisiteDropdown *Dropdown
 
 	// dbVersions tracks the version list currently shown in the
 	// dbVersionDropdown — it changes when the user picks a different
 	// engine, so we cache the visible slice for the click handler.
-	dbVersions []string
+	// cacheVersions does the same for the cache backend.
+	dbVersions    []string
+	cacheVersions []string
 
 	// Buttons
 	browseDirBtn widget.Clickable
+	openProjBtn  widget.Clickable
 	createBtn    widget.Clickable
 	cancelBtn    widget.Clickable
 	closeBtn     widget.Clickable
@@ -67,6 +110,10 @@ type NewSiteModal struct {
 	keys *ModalFocus
 	anim *modalShowState
 
+	// openingProject guards the "Open project folder" flow against a
+	// double click starting two creates for the same directory.
+	openingProject atomic.Bool
+
 	// Path-validation cache. The Layout pass reads notes; HandleUserInteractions
 	// debounces on the FilesDir value and re-runs ValidateSitePath off
 	// the goroutine started by the debounce timer. The mutex covers both.
@@ -90,6 +137,11 @@ func NewNewSiteModal(state *UIState, sm *sites.SiteManager, toasts *Notification
 		engineIdx = indexOfDBEngine(cfg.DBEngineDefault(), dbEngineKinds)
 	}
 	versions := dbVersionsFor(dbEngineKinds[engineIdx])
+	cacheIdx := 0
+	if cfg != nil {
+		cacheIdx = indexOfCacheBackend(cfg.CacheBackendDefault())
+	}
+	cacheVersions := cachebackend.KnownVersions(cacheBackendKinds[cacheIdx])
 
 	m := &NewSiteModal{
 		state:             state,
@@ -99,17 +151,20 @@ func NewNewSiteModal(state *UIState, sm *sites.SiteManager, toasts *Notification
 		dbEngineDropdown:  NewDropdown(dbEngineOptions),
 		dbVersionDropdown: NewDropdown(versions),
 		dbVersions:        versions,
-		redisDropdown:     NewDropdown(redisVersions),
+		cacheDropdown:     NewDropdown(cacheBackendOptions),
+		cacheVerDropdown:  NewDropdown(cacheVersions),
+		cacheVersions:     cacheVersions,
 		webServerDropdown: NewDropdown(webServerOptions),
 		multisiteDropdown: NewDropdown(multisiteOptions),
 		keys:              NewModalFocus(),
 		anim:              NewModalAnim(),
 	}
 	m.dbEngineDropdown.Selected = engineIdx
+	m.cacheDropdown.Selected = cacheIdx
+	m.cacheVerDropdown.Selected = defaultCacheVersionIndex(cfg, cacheBackendKinds[cacheIdx], cacheVersions)
 	if cfg != nil {
 		m.phpDropdown.Selected = indexOfOr(phpVersions, cfg.PHPVersionDefault(), 0)
 		m.dbVersionDropdown.Selected = indexOfOr(versions, cfg.DBVersionDefault(), 0)
-		m.redisDropdown.Selected = indexOfOr(redisVersions, cfg.RedisVersionDefault(), 0)
 		m.webServerDropdown.Selected = indexOfOr(webServerOptions, cfg.WebServerDefault(), 0)
 	}
 
@@ -174,6 +229,10 @@ func (m *NewSiteModal) HandleUserInteractions(gtx layout.Context) {
 		}()
 	}
 
+	if m.openProjBtn.Clicked(gtx) && m.openingProject.CompareAndSwap(false, true) {
+		go m.openProjectFolder()
+	}
+
 	// Sync the version dropdown to the selected engine. Re-running this
 	// each frame is cheap and means the user sees the right options
 	// without an explicit "engine changed" event.
@@ -182,6 +241,12 @@ func (m *NewSiteModal) HandleUserInteractions(gtx layout.Context) {
 		m.dbVersions = wantVersions
 		m.dbVersionDropdown = NewDropdown(wantVersions)
 	}
+	cacheKind := cacheBackendKinds[m.cacheDropdown.Selected]
+	if want := cachebackend.KnownVersions(cacheKind); !slicesEqual(want, m.cacheVersions) {
+		m.cacheVersions = want
+		m.cacheVerDropdown = NewDropdown(want)
+		m.cacheVerDropdown.Selected = defaultCacheVersionIndex(m.sm.Config(), cacheKind, want)
+	}
 
 	if m.createBtn.Clicked(gtx) || keys.Enter {
 		name := m.nameEditor.Text()
@@ -190,7 +255,8 @@ func (m *NewSiteModal) HandleUserInteractions(gtx layout.Context) {
 		phpVer := phpVersions[m.phpDropdown.Selected]
 		dbEngine := dbEngineKinds[m.dbEngineDropdown.Selected]
 		dbVer := m.dbVersions[m.dbVersionDropdown.Selected]
-		redisVer := redisVersions[m.redisDropdown.Selected]
+		cacheBackend := cacheBackendKinds[m.cacheDropdown.Selected]
+		cacheVer := selectedOption(m.cacheVersions, m.cacheVerDropdown.Selected)
 		webServer := []string{"nginx", "apache"}[m.webServerDropdown.Selected]
 		multisiteMap := []string{"", "subdirectory", "subdomain"}
 		multisite := multisiteMap[m.multisiteDropdown.Selected]
@@ -219,7 +285,8 @@ func (m *NewSiteModal) HandleUserInteractions(gtx layout.Context) {
 					PHPVersion:   phpVer,
 					DBEngine:     string(dbEngine),
 					DBVersion:    dbVer,
-					RedisVersion: redisVer,
+					CacheBackend: string(cacheBackend),
+					CacheVersion: cacheVer,
 					WebServer:    webServer,
 					Multisite:    multisite,
 				}
@@ -236,7 +303,10 @@ func (m *NewSiteModal) HandleUserInteractions(gtx layout.Context) {
 					_ = cfg.SetPHPVersionDefault(phpVer)
 					_ = cfg.SetDBEngineDefault(string(dbEngine))
 					_ = cfg.SetDBVersionDefault(dbVer)
-					_ = cfg.SetRedisVersionDefault(redisVer)
+					_ = cfg.SetCacheBackendDefault(string(cacheBackend))
+					if cacheBackend == cachebackend.Redis {
+						_ = cfg.SetRedisVersionDefault(cacheVer)
+					}
 					_ = cfg.SetWebServerDefault(webServer)
 				}
 
@@ -252,7 +322,9 @@ func (m *NewSiteModal) HandleUserInteractions(gtx layout.Context) {
 				m.dbEngineDropdown.Selected = 0
 				m.dbVersions = dbVersionsFor(dbEngineKinds[0])
 				m.dbVersionDropdown = NewDropdown(m.dbVersions)
-				m.redisDropdown.Selected = 0
+				m.cacheDropdown.Selected = 0
+				m.cacheVersions = cachebackend.KnownVersions(cacheBackendKinds[0])
+				m.cacheVerDropdown = NewDropdown(m.cacheVersions)
 				m.webServerDropdown.Selected = 0
 				m.multisiteDropdown.Selected = 0
 
@@ -262,6 +334,41 @@ func (m *NewSiteModal) HandleUserInteractions(gtx layout.Context) {
 	}
 }
 
+// openProjectFolder asks for a directory holding a committed
+// .locorum/config.yaml and creates + starts the site it describes.
+// Every setting comes from the YAML, so the form fields are ignored.
+// Its hooks are left out: they are commands from someone else's repo,
+// so they wait on the Overview tab's config.yaml card until the user
+// has read them and applies them.
+func (m *NewSiteModal) openProjectFolder() {
+	defer m.openingProject.Store(false)
+
+	dir, err := m.sm.PickDirectory()
+	if err != nil || dir == "" {
+		return
+	}
+
+	m.state.SetShowNewSiteModal(false)
+	m.keys.OnHide()
+	m.anim.Hide()
+	m.state.Invalidate()
+
+	res, err := m.sm.CreateSiteFromConfig(context.Background(), dir, sites.CreateFromConfigOptions{Start: true})
+	if res != nil {
+		for _, w := range res.Warnings {
+			m.toasts.ShowInfo(w)
+		}
+	}
+	if err != nil {
+		m.state.ShowError("Open project folder: " + err.Error())
+		return
+	}
+	m.toasts.ShowSuccess("Created " + res.Site.Name + " from .locorum/config.yaml")
+	if n := len(res.SkippedHooks); n > 0 {
+		m.toasts.ShowInfo(strconv.Itoa(n) + " hooks in .locorum/config.yaml were not added. Review them in the file, then apply them from the site's Overview tab.")
+	}
+}
+
 // scheduleValidation arms (or re-arms) the path-debounce timer. Pure-Go
 // validation runs in microseconds, but we still debounce so the UI doesn't
 // feel chatty during a paste. After 250 ms of quiescence the timer fires;
@@ -400,10 +507,19 @@ func (m *NewSiteModal) layoutForm(gtx layout.Context, th *Theme) layout.Dimensio
 				return m.dbVersionDropdown.Layout(gtx, th, "Database Version")
 			})
 		}),
-		// Redis Version
+		// Object Cache
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-				return m.redisDropdown.Layout(gtx, th, "Redis Version")
+				return m.cacheDropdown.Layout(gtx, th, "Object Cache")
+			})
+		}),
+		// Object Cache Version (hidden for "None")
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(m.cacheVersions) == 0 {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return m.cacheVerDropdown.Layout(gtx, th, "Cache Version")
 			})
 		}),
 		// Multisite
@@ -416,6 +532,11 @@ func (m *NewSiteModal) layoutForm(gtx layout.Context, th *Theme) layout.Dimensio
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceStart}.Layout(gtx,
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return SecondaryButton(gtx, th, &m.openProjBtn, "Open project folder...")
+				}),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Dimensions{Size: gtx.Constraints.Min}
+				}), layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					return layout.Inset{Right: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 						return SecondaryButton(gtx, th, &m.cancelBtn, "Cancel")
 					})
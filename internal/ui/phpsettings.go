@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/PeterBooker/locorum/internal/phpini"
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// PHPSettingsEditor edits the site's php.ini overrides and extension
+// switches. Editable only while the site is stopped, like the version and
+// resource editors; a running site shows a read-only summary.
+type PHPSettingsEditor struct {
+	state  *UIState
+	sm     *sites.SiteManager
+	toasts *Notifications
+
+	ini     widget.Editor
+	enable  widget.Editor
+	disable widget.Editor
+	saveBtn widget.Clickable
+
+	lastSiteID string
+	initial    []string // input texts at last sync, for dirty tracking
+}
+
+func NewPHPSettingsEditor(state *UIState, sm *sites.SiteManager, toasts *Notifications) *PHPSettingsEditor {
+	pe := &PHPSettingsEditor{state: state, sm: sm, toasts: toasts}
+	pe.enable.SingleLine = true
+	pe.disable.SingleLine = true
+	return pe
+}
+
+func (pe *PHPSettingsEditor) Layout(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	if site.Started {
+		return KVRows(gtx, th, phpSettingsSummary(site))
+	}
+
+	if pe.lastSiteID != site.ID {
+		pe.lastSiteID = site.ID
+		pe.sync(site)
+	}
+
+	dirty := pe.isDirty()
+	sectionFn := Section
+	title := "PHP settings (editable while stopped)"
+	if dirty {
+		title = "● PHP settings — unsaved changes"
+		sectionFn = SectionDirty
+	}
+
+	return sectionFn(gtx, th, title, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Body2(th.Theme, "One \"directive = value\" per line, applied over the shared php.ini for this site only.")
+				lbl.Color = th.Color.Fg3
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, lbl.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return BorderedMonoEditor(gtx, th, &pe.ini, "max_input_vars = 5000")
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return LabeledInput(gtx, th, "Enable extensions", &pe.enable, "e.g. pcov, xhprof")
+						}),
+						layout.Rigid(layout.Spacer{Width: th.Spacing.SM}.Layout),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return LabeledInput(gtx, th, "Disable extensions", &pe.disable, "e.g. newrelic")
+						}),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return th.PrimaryGated(gtx, &pe.saveBtn, "Save PHP Settings", dirty)
+			}),
+		)
+	})
+}
+
+// HandleUserInteractions processes the Save button. Input is parsed and
+// validated here so mistakes surface before a goroutine is spawned; the
+// SiteManager validates again.
+func (pe *PHPSettingsEditor) HandleUserInteractions(gtx layout.Context, site *types.Site) {
+	if site.Started {
+		return
+	}
+	if !pe.saveBtn.Clicked(gtx) || !pe.isDirty() {
+		return
+	}
+	ini, err := phpini.ParseDirectives(pe.ini.Text())
+	if err == nil {
+		err = phpini.ValidateSettings(ini)
+	}
+	if err != nil {
+		pe.state.ShowError("PHP settings: " + err.Error())
+		return
+	}
+	enable := phpini.ParseExtensionList(pe.enable.Text())
+	disable := phpini.ParseExtensionList(pe.disable.Text())
+	if err := phpini.ValidateExtensions(enable, disable); err != nil {
+		pe.state.ShowError(err.Error())
+		return
+	}
+	pe.initial = pe.texts()
+	siteID := site.ID
+	go func() {
+		if err := pe.sm.SetPHPOverrides(siteID, ini, enable, disable); err != nil {
+			pe.state.ShowError("Failed to update PHP settings: " + err.Error())
+			return
+		}
+		pe.toasts.ShowSuccess("PHP settings saved — start the site to apply.")
+	}()
+}
+
+func (pe *PHPSettingsEditor) sync(site *types.Site) {
+	pe.ini.SetText(phpini.FormatDirectives(site.PHPIni))
+	pe.enable.SetText(strings.Join(site.PHPExtensionsEnable, ", "))
+	pe.disable.SetText(strings.Join(site.PHPExtensionsDisable, ", "))
+	pe.initial = pe.texts()
+}
+
+func (pe *PHPSettingsEditor) texts() []string {
+	return []string{
+		strings.TrimSpace(pe.ini.Text()),
+		strings.TrimSpace(pe.enable.Text()),
+		strings.TrimSpace(pe.disable.Text()),
+	}
+}
+
+func (pe *PHPSettingsEditor) isDirty() bool {
+	return !slicesEqual(pe.texts(), pe.initial)
+}
+
+// phpSettingsSummary renders the overrides as KV rows for the read-only
+// view: one row per directive, then the extension switches.
+func phpSettingsSummary(site *types.Site) []KV {
+	var items []KV
+	for _, k := range phpini.SortedKeys(site.PHPIni) {
+		items = append(items, KV{k, site.PHPIni[k]})
+	}
+	if len(site.PHPExtensionsEnable) > 0 {
+		items = append(items, KV{"Enabled extensions", strings.Join(site.PHPExtensionsEnable, ", ")})
+	}
+	if len(site.PHPExtensionsDisable) > 0 {
+		items = append(items, KV{"Disabled extensions", strings.Join(site.PHPExtensionsDisable, ", ")})
+	}
+	if len(items) == 0 {
+		items = []KV{{"PHP settings", "Shared php.ini"}}
+	}
+	return items
+}
@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/PeterBooker/locorum/internal/remote"
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// RemotesPanel lists a site's SSH remotes with per-row Pull and Push
+// actions. Remotes are added from the CLI (`locorum remote add`); the
+// panel only runs transfers. Push always goes through a confirm dialog
+// naming the target host, and is disabled for production remotes.
+type RemotesPanel struct {
+	state  *UIState
+	sm     *sites.SiteManager
+	toasts *Notifications
+
+	refreshBtn widget.Clickable
+	rows       []remoteRow
+
+	loadedFor string
+	loaded    []remote.Remote
+	loading   bool
+
+	// pushTarget is the remote awaiting confirmation; nil when the
+	// dialog is closed.
+	pushTarget *remote.Remote
+	dialog     ConfirmDialog
+
+	busy atomic.Bool
+}
+
+type remoteRow struct {
+	pull widget.Clickable
+	push widget.Clickable
+}
+
+func NewRemotesPanel(state *UIState, sm *sites.SiteManager, toasts *Notifications) *RemotesPanel {
+	return &RemotesPanel{state: state, sm: sm, toasts: toasts}
+}
+
+// HandleUserInteractions processes button clicks. Called by the parent
+// before Layout when this panel is visible.
+func (p *RemotesPanel) HandleUserInteractions(gtx layout.Context, site *types.Site) {
+	if site == nil {
+		return
+	}
+	if p.loadedFor != site.ID && !p.loading {
+		p.pushTarget = nil
+		p.refresh(site)
+	}
+	if p.refreshBtn.Clicked(gtx) {
+		p.refresh(site)
+	}
+
+	for i := range p.rows {
+		if i >= len(p.loaded) {
+			break
+		}
+		r := p.loaded[i]
+		if p.rows[i].pull.Clicked(gtx) && site.Started && !p.busy.Load() {
+			p.pull(site.ID, r)
+		}
+		if p.rows[i].push.Clicked(gtx) && site.Started && !r.Production && r.HostKey != "" && !p.busy.Load() {
+			p.pushTarget = &r
+		}
+	}
+
+	if p.pushTarget != nil {
+		confirmed, cancelled := p.dialog.HandleUserInteractions(gtx)
+		if cancelled {
+			p.pushTarget = nil
+		}
+		if confirmed {
+			r := *p.pushTarget
+			p.pushTarget = nil
+			p.push(site.ID, r)
+		}
+	}
+}
+
+func (p *RemotesPanel) pull(siteID string, r remote.Remote) {
+	if !p.busy.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer p.busy.Store(false)
+		defer p.state.Invalidate()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		res, err := p.sm.Pull(ctx, siteID, sites.PullOptions{Remote: r.Name})
+		if err != nil {
+			p.state.ShowError("Pull from " + r.Name + " failed: " + err.Error())
+			return
+		}
+		p.toasts.ShowSuccess(fmt.Sprintf("Pulled %s: database and %d uploads", r.Name, res.UploadFiles))
+	}()
+}
+
+func (p *RemotesPanel) push(siteID string, r remote.Remote) {
+	if !p.busy.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer p.busy.Store(false)
+		defer p.state.Invalidate()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		res, err := p.sm.Push(ctx, siteID, sites.PushOptions{Remote: r.Name, ConfirmHost: r.Host})
+		if err != nil {
+			p.state.ShowError("Push to " + r.Host + " failed: " + err.Error())
+			return
+		}
+		p.toasts.ShowSuccess("Pushed database to " + res.Host + " (backup: " + res.BackupPath + ")")
+	}()
+}
+
+func (p *RemotesPanel) refresh(site *types.Site) {
+	p.loading = true
+	go func() {
+		list, err := p.sm.ListRemotes(site.ID)
+		if err != nil {
+			p.state.ShowError("Listing remotes failed: " + err.Error())
+		}
+		p.state.mu.Lock()
+		p.loaded = list
+		p.loadedFor = site.ID
+		p.loading = false
+		p.rows = make([]remoteRow, len(list))
+		p.state.mu.Unlock()
+		p.state.Invalidate()
+	}()
+}
+
+func (p *RemotesPanel) Layout(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	if site == nil {
+		return layout.Dimensions{}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return p.layoutList(gtx, th, site)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if p.busy.Load() {
+					return Loader(gtx, th, th.Dims.LoaderSizeSM)
+				}
+				return SecondaryButton(gtx, th, &p.refreshBtn, "Refresh")
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if p.pushTarget == nil {
+				return layout.Dimensions{}
+			}
+			r := p.pushTarget
+			return p.dialog.Layout(gtx, th, ConfirmDialogStyle{
+				Title: "Push database to " + r.Host + "?",
+				Message: fmt.Sprintf("The database at %s will be replaced with this site's database, "+
+					"and URLs rewritten to the remote's home URL. The remote database is backed up to ~/%s first. "+
+					"Uploads are not pushed. Host key: %s.", r.String(), remote.BackupDir, r.HostKey),
+				ConfirmLabel: "Push to " + r.Host,
+				ConfirmColor: th.Color.Err,
+			})
+		}),
+	)
+}
+
+func (p *RemotesPanel) layoutList(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	if p.loading {
+		lbl := material.Body2(th.Theme, "Loading remotes…")
+		lbl.Color = th.Color.TextSecondary
+		return lbl.Layout(gtx)
+	}
+	if len(p.loaded) == 0 {
+		lbl := material.Body2(th.Theme, "No remotes. Add one with `locorum remote add`.")
+		lbl.Color = th.Color.TextSecondary
+		return lbl.Layout(gtx)
+	}
+
+	children := make([]layout.FlexChild, 0, len(p.loaded))
+	for i, r := range p.loaded {
+		idx := i
+		rm := r
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return p.layoutRow(gtx, th, site, rm, idx)
+			})
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (p *RemotesPanel) layoutRow(gtx layout.Context, th *Theme, site *types.Site, r remote.Remote, idx int) layout.Dimensions {
+	title := r.Name
+	if r.Production {
+		title += " · production"
+	}
+	idle := site.Started && !p.busy.Load()
+	pushLabel := "Push"
+	switch {
+	case r.Production:
+		pushLabel = "Push disabled"
+	case r.HostKey == "":
+		// Push never pins a host key; a pull does.
+		pushLabel = "Pull first"
+	}
+
+	return RoundedFill(gtx, th.Color.Bg1, th.Radii.R2, func(gtx layout.Context) layout.Dimensions {
+		return layout.UniformInset(th.Spacing.SM).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Body1(th.Theme, title)
+							lbl.Color = th.Color.TextStrong
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Body2(th.Theme, r.String())
+							lbl.Color = th.Color.TextSecondary
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Left: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return th.SmallGated(gtx, &p.rows[idx].pull, "Pull", idle)
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Left: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return th.SmallGated(gtx, &p.rows[idx].push, pushLabel, idle && !r.Production && r.HostKey != "")
+					})
+				}),
+			)
+		})
+	})
+}
@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"image/color"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/PeterBooker/locorum/internal/reqlog"
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// requestsRefresh is how often the Requests tab re-reads the site's
+// request log while it is visible.
+const requestsRefresh = time.Second
+
+// requestsShown caps the rows rendered. The store keeps more; filters
+// reach the older ones.
+const requestsShown = 200
+
+// requestStatusFilters are the reqlog status filters, in
+// requestStatusOptions order.
+var (
+	requestStatusFilters = []string{"", "2xx", "3xx", "4xx", "5xx"}
+	requestStatusOptions = []string{"All statuses", "2xx", "3xx", "4xx", "5xx"}
+)
+
+// RequestsTab is the per-site request inspector: the router's access
+// log for this site, newest first, filterable by status class and path.
+// Reads happen in goroutines on a one-second tick; Layout only renders
+// the cached result.
+type RequestsTab struct {
+	state *UIState
+	sm    *sites.SiteManager
+
+	status     *Dropdown
+	pathEditor widget.Editor
+	clearBtn   widget.Clickable
+
+	mu        sync.Mutex
+	key       string // siteID + filters the cached entries answer
+	entries   []reqlog.Entry
+	fetchedAt time.Time
+	loading   bool
+}
+
+func NewRequestsTab(state *UIState, sm *sites.SiteManager) *RequestsTab {
+	rt := &RequestsTab{state: state, sm: sm, status: NewDropdown(requestStatusOptions)}
+	rt.pathEditor.SingleLine = true
+	return rt
+}
+
+// HandleUserInteractions processes the Clear button. Must be called
+// once per frame, before Layout.
+func (rt *RequestsTab) HandleUserInteractions(gtx layout.Context, site *types.Site) {
+	if rt.clearBtn.Clicked(gtx) {
+		rt.sm.ClearSiteRequests(site.Slug)
+		rt.mu.Lock()
+		rt.entries = nil
+		rt.fetchedAt = time.Time{}
+		rt.mu.Unlock()
+	}
+}
+
+func (rt *RequestsTab) filter() reqlog.Filter {
+	return reqlog.Filter{
+		Status: requestStatusFilters[rt.status.Selected],
+		Path:   strings.TrimSpace(rt.pathEditor.Text()),
+		Limit:  requestsShown,
+	}
+}
+
+// refresh starts a background read when the filters changed or the
+// cached result is stale, and schedules the next frame so the list
+// keeps updating while visible.
+func (rt *RequestsTab) refresh(gtx layout.Context, siteID string) {
+	f := rt.filter()
+	key := siteID + "\x00" + f.Status + "\x00" + f.Path
+
+	rt.mu.Lock()
+	stale := key != rt.key || time.Since(rt.fetchedAt) >= requestsRefresh
+	if !stale || rt.loading {
+		rt.mu.Unlock()
+		gtx.Execute(op.InvalidateCmd{At: gtx.Now.Add(requestsRefresh)})
+		return
+	}
+	if key != rt.key {
+		rt.entries = nil
+	}
+	rt.key = key
+	rt.loading = true
+	rt.mu.Unlock()
+
+	go func() {
+		entries, err := rt.sm.SiteRequests(siteID, f)
+		if err != nil {
+			slog.Debug("requests load failed", "site", siteID, "err", err.Error())
+		}
+		rt.mu.Lock()
+		if rt.key == key {
+			rt.entries = entries
+		}
+		rt.fetchedAt = time.Now()
+		rt.loading = false
+		rt.mu.Unlock()
+		rt.state.Invalidate()
+	}()
+	gtx.Execute(op.InvalidateCmd{At: gtx.Now.Add(requestsRefresh)})
+}
+
+func (rt *RequestsTab) Layout(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	rt.refresh(gtx, site.ID)
+
+	rt.mu.Lock()
+	entries := rt.entries
+	rt.mu.Unlock()
+
+	return panel(gtx, th, "Requests", func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return rt.layoutFilters(gtx, th)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if len(entries) > 0 {
+					return layout.Dimensions{}
+				}
+				msg := "No requests yet. Requests to this site appear here as the router serves them."
+				if rt.filter() != (reqlog.Filter{Limit: requestsShown}) {
+					msg = "No requests match these filters."
+				}
+				lbl := material.Body2(th.Theme, msg)
+				lbl.Color = th.Color.Fg3
+				lbl.TextSize = th.Sizes.Body
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				rows := make([]layout.FlexChild, len(entries))
+				for i := range entries {
+					e := entries[i]
+					rows[i] = layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return requestRow(gtx, th, e)
+					})
+				}
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+			}),
+		)
+	})
+}
+
+func (rt *RequestsTab) layoutFilters(gtx layout.Context, th *Theme) layout.Dimensions {
+	return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.End}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = gtx.Dp(unit.Dp(160))
+				return rt.status.Layout(gtx, th, "Status")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Spacer{Width: th.Spacing.SM}.Layout(gtx)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return LabeledInput(gtx, th, "Path contains", &rt.pathEditor, "/wp-admin")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Spacer{Width: th.Spacing.SM}.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return SecondaryButton(gtx, th, &rt.clearBtn, "Clear")
+			}),
+		)
+	})
+}
+
+// requestRow renders one request: time, status, method, host + path,
+// duration.
+func requestRow(gtx layout.Context, th *Theme, e reqlog.Entry) layout.Dimensions {
+	mono := func(text string, col color.NRGBA, width unit.Dp) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(width)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			lbl := material.Body2(th.Theme, text)
+			lbl.Color = col
+			lbl.TextSize = th.Sizes.Mono
+			lbl.Font = MonoFont
+			lbl.MaxLines = 1
+			return lbl.Layout(gtx)
+		})
+	}
+	return layout.Inset{Bottom: th.Spacing.XS}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			mono(e.Time.Local().Format("15:04:05"), th.Color.Fg3, 72),
+			mono(strconv.Itoa(e.Status), requestStatusColor(th, e.Status), 40),
+			mono(e.Method, th.Color.Fg2, 64),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Body2(th.Theme, e.Host+e.Path)
+				lbl.Color = th.Color.Fg
+				lbl.TextSize = th.Sizes.Mono
+				lbl.Font = MonoFont
+				lbl.MaxLines = 1
+				lbl.Truncator = "…"
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Body2(th.Theme, strconv.FormatFloat(e.DurationMS, 'f', 1, 64)+" ms")
+				lbl.Color = th.Color.Fg3
+				lbl.TextSize = th.Sizes.Mono
+				lbl.Font = MonoFont
+				lbl.MaxLines = 1
+				return layout.Inset{Left: unit.Dp(12)}.Layout(gtx, lbl.Layout)
+			}),
+		)
+	})
+}
+
+func requestStatusColor(th *Theme, status int) color.NRGBA {
+	switch {
+	case status >= 500:
+		return th.Color.Err
+	case status >= 400:
+		return th.Color.Warn
+	case status >= 300:
+		return th.Color.Fg2
+	default:
+		return th.Color.Ok
+	}
+}
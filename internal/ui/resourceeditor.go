@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/sites"
+	"github.com/PeterBooker/locorum/internal/types"
+)
+
+// resourceRowLabels is the display label for each docker.ResourceKeys
+// entry, in the same order.
+var resourceRowLabels = []string{"All containers", "PHP", "Web", "Database", "Cache"}
+
+// resourceRow holds the three inputs for one resource key. An empty
+// input means "keep the default".
+type resourceRow struct {
+	memory widget.Editor
+	cpus   widget.Editor
+	pids   widget.Editor
+}
+
+// ResourceEditor edits the site's per-role container limits. Like the
+// version editor it is read-only while the site runs: the limits are part
+// of each container's spec hash, so they apply on the next start.
+type ResourceEditor struct {
+	state  *UIState
+	sm     *sites.SiteManager
+	toasts *Notifications
+
+	rows    []resourceRow
+	saveBtn widget.Clickable
+
+	lastSiteID string
+	initial    []string // flattened input texts at last sync, for dirty tracking
+}
+
+func NewResourceEditor(state *UIState, sm *sites.SiteManager, toasts *Notifications) *ResourceEditor {
+	re := &ResourceEditor{
+		state:  state,
+		sm:     sm,
+		toasts: toasts,
+		rows:   make([]resourceRow, len(docker.ResourceKeys())),
+	}
+	for i := range re.rows {
+		re.rows[i].memory.SingleLine = true
+		re.rows[i].cpus.SingleLine = true
+		re.rows[i].pids.SingleLine = true
+	}
+	return re
+}
+
+func (re *ResourceEditor) Layout(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
+	if site.Started {
+		return KVRows(gtx, th, resourceSummary(site.Resources))
+	}
+
+	if re.lastSiteID != site.ID {
+		re.lastSiteID = site.ID
+		re.sync(site)
+	}
+
+	dirty := re.isDirty()
+	sectionFn := Section
+	title := "Limits (editable while stopped)"
+	if dirty {
+		title = "● Limits — unsaved changes"
+		sectionFn = SectionDirty
+	}
+
+	return sectionFn(gtx, th, title, func(gtx layout.Context) layout.Dimensions {
+		children := make([]layout.FlexChild, 0, len(re.rows)+2)
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Body2(th.Theme, "Leave a field empty to keep Locorum's default. \"All containers\" applies to every service; a service row overrides it.")
+			lbl.Color = th.Color.Fg3
+			return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, lbl.Layout)
+		}))
+		for i := range re.rows {
+			row := &re.rows[i]
+			label := resourceRowLabels[i]
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.End}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							gtx.Constraints.Min.X = gtx.Dp(th.Dims.LabelColWidth)
+							lbl := material.Body2(th.Theme, label)
+							lbl.Color = th.Color.TextSecondary
+							return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, lbl.Layout)
+						}),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return LabeledInput(gtx, th, "Memory (MB)", &row.memory, "default")
+						}),
+						layout.Rigid(layout.Spacer{Width: th.Spacing.SM}.Layout),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return LabeledInput(gtx, th, "CPUs", &row.cpus, "default")
+						}),
+						layout.Rigid(layout.Spacer{Width: th.Spacing.SM}.Layout),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return LabeledInput(gtx, th, "Max processes", &row.pids, "default")
+						}),
+					)
+				})
+			}))
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return th.PrimaryGated(gtx, &re.saveBtn, "Save Limits", dirty)
+		}))
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
+
+// HandleUserInteractions processes the Save button. Parse errors are
+// reported before anything is sent to the SiteManager, which validates
+// the ranges again.
+func (re *ResourceEditor) HandleUserInteractions(gtx layout.Context, site *types.Site) {
+	if site.Started {
+		return
+	}
+	if !re.saveBtn.Clicked(gtx) || !re.isDirty() {
+		return
+	}
+	limits, err := re.limits()
+	if err != nil {
+		re.state.ShowError(err.Error())
+		return
+	}
+	re.initial = re.texts()
+	siteID := site.ID
+	go func() {
+		if err := re.sm.SetResourceLimits(siteID, limits); err != nil {
+			re.state.ShowError("Failed to update resource limits: " + err.Error())
+			return
+		}
+		re.toasts.ShowSuccess("Resource limits saved — start the site to apply.")
+	}()
+}
+
+func (re *ResourceEditor) sync(site *types.Site) {
+	for i, key := range docker.ResourceKeys() {
+		l := site.Resources[key]
+		re.rows[i].memory.SetText(formatLimitInt(l.MemoryMB))
+		re.rows[i].cpus.SetText(formatLimitFloat(l.CPUs))
+		re.rows[i].pids.SetText(formatLimitInt(l.Pids))
+	}
+	re.initial = re.texts()
+}
+
+func (re *ResourceEditor) texts() []string {
+	out := make([]string, 0, len(re.rows)*3)
+	for i := range re.rows {
+		out = append(out,
+			strings.TrimSpace(re.rows[i].memory.Text()),
+			strings.TrimSpace(re.rows[i].cpus.Text()),
+			strings.TrimSpace(re.rows[i].pids.Text()),
+		)
+	}
+	return out
+}
+
+func (re *ResourceEditor) isDirty() bool {
+	return !slicesEqual(re.texts(), re.initial)
+}
+
+// limits parses the inputs into a ResourceLimits map. Empty inputs stay
+// zero; anything else must be a positive number.
+func (re *ResourceEditor) limits() (types.ResourceLimits, error) {
+	out := types.ResourceLimits{}
+	for i, key := range docker.ResourceKeys() {
+		row := &re.rows[i]
+		label := resourceRowLabels[i]
+		var l types.ResourceLimit
+		var err error
+		if l.MemoryMB, err = parseLimitInt(row.memory.Text()); err != nil {
+			return nil, fmt.Errorf("%s memory: %w", label, err)
+		}
+		if l.CPUs, err = parseLimitFloat(row.cpus.Text()); err != nil {
+			return nil, fmt.Errorf("%s CPUs: %w", label, err)
+		}
+		if l.Pids, err = parseLimitInt(row.pids.Text()); err != nil {
+			return nil, fmt.Errorf("%s max processes: %w", label, err)
+		}
+		out[key] = l
+	}
+	return out, nil
+}
+
+// resourceSummary renders the overrides as KV rows for the read-only
+// view. Keys without an override are skipped.
+func resourceSummary(limits types.ResourceLimits) []KV {
+	var items []KV
+	for i, key := range docker.ResourceKeys() {
+		l, ok := limits[key]
+		if !ok || l.IsZero() {
+			continue
+		}
+		var parts []string
+		if l.MemoryMB > 0 {
+			parts = append(parts, formatLimitInt(l.MemoryMB)+" MB")
+		}
+		if l.CPUs > 0 {
+			parts = append(parts, formatLimitFloat(l.CPUs)+" CPU")
+		}
+		if l.Pids > 0 {
+			parts = append(parts, formatLimitInt(l.Pids)+" processes")
+		}
+		items = append(items, KV{resourceRowLabels[i], strings.Join(parts, " · ")})
+	}
+	if len(items) == 0 {
+		items = []KV{{"Limits", "Locorum defaults"}}
+	}
+	return items
+}
+
+func parseLimitInt(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a whole number", s)
+	}
+	return n, nil
+}
+
+func parseLimitFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return f, nil
+}
+
+func formatLimitInt(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func formatLimitFloat(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"strconv"
 
 	"gioui.org/font"
@@ -9,6 +10,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 
+	"github.com/PeterBooker/locorum/internal/cachebackend"
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/sites"
 )
@@ -19,7 +21,8 @@ import (
 //   - System Health:    runner findings + re-check.
 //   - Appearance:       theme picker (System / Light / Dark).
 //   - New site defaults: pre-fill values for the new-site modal.
-//   - Network & TLS:    router HTTP/HTTPS host ports + mkcert path.
+//   - Network & TLS:    router HTTP/HTTPS host ports, certificate provider,
+//     mkcert path, hosts-file management and the embedded DNS server.
 //
 // Each section reads from sm.Config() at construction time and pushes
 // validated changes back through the typed setters. Validation errors
@@ -39,6 +42,7 @@ type SettingsPanel struct {
 	defaultPHP    *Dropdown
 	defaultEngine *Dropdown
 	defaultDBVer  *Dropdown
+	defaultCache  *Dropdown
 	defaultRedis  *Dropdown
 	defaultWeb    *Dropdown
 	publishDBPort widget.Bool
@@ -51,15 +55,51 @@ type SettingsPanel struct {
 	httpPortEditor   widget.Editor
 	httpsPortEditor  widget.Editor
 	mkcertPathEditor widget.Editor
+	dnsTLDEditor     widget.Editor
+	dnsListenEditor  widget.Editor
 	networkSaveBtn   widget.Clickable
 
+	// The certificate provider, router engine and DB UI are fixed
+	// choices, so they save on change like the defaults dropdowns
+	// rather than waiting for Save.
+	tlsProvider  *Dropdown
+	routerEngine *Dropdown
+	dbuiEngine   *Dropdown
+
+	// manageHosts toggles the Locorum block in the system hosts file.
+	// Applied immediately: the sync runs in the background and may show
+	// the OS elevation prompt.
+	manageHosts widget.Bool
+
+	// dnsEnabled toggles the embedded DNS server. Saves on change but,
+	// like the router ports, only takes effect on next launch.
+	dnsEnabled widget.Bool
+
 	// Last-applied values — used to detect a real change before
 	// hitting storage on every frame.
 	lastPHP, lastEngine, lastDBVer string
-	lastRedis, lastWeb             string
+	lastCache, lastRedis, lastWeb  string
 	lastPublishDBPort              bool
+	lastTLSProvider                string
+	lastRouterEngine               string
+	lastDBUIEngine                 string
+	lastManageHosts                bool
+	lastDNSEnabled                 bool
 }
 
+// tlsProviderKinds are the tls.provider values, in tlsProviderOptions
+// order.
+var (
+	tlsProviderKinds   = []string{"mkcert", "builtin"}
+	tlsProviderOptions = []string{"mkcert", "Built-in CA (no mkcert needed)"}
+
+	routerEngineKinds   = []string{"traefik", "caddy"}
+	routerEngineOptions = []string{"Traefik", "Caddy"}
+
+	dbuiEngineKinds   = []string{"adminer", "phpmyadmin"}
+	dbuiEngineOptions = []string{"Adminer", "phpMyAdmin"}
+)
+
 // NewSettingsPanel constructs a SettingsPanel. onThemeChange is invoked
 // whenever the user changes the theme mode (e.g. to apply + persist).
 func NewSettingsPanel(state *UIState, sm *sites.SiteManager, onThemeChange func(ThemeMode)) *SettingsPanel {
@@ -81,8 +121,12 @@ func NewSettingsPanel(state *UIState, sm *sites.SiteManager, onThemeChange func(
 	dbVerOptions := dbengine.MustFor(engineKind).KnownVersions()
 	s.defaultDBVer = NewDropdown(dbVerOptions)
 	s.defaultDBList = dbVerOptions
-	s.defaultRedis = NewDropdown(redisVersions)
+	s.defaultCache = NewDropdown(cacheBackendOptions)
+	s.defaultRedis = NewDropdown(cachebackend.KnownVersions(cachebackend.Redis))
 	s.defaultWeb = NewDropdown([]string{"nginx", "apache"})
+	s.tlsProvider = NewDropdown(tlsProviderOptions)
+	s.routerEngine = NewDropdown(routerEngineOptions)
+	s.dbuiEngine = NewDropdown(dbuiEngineOptions)
 
 	if cfg != nil {
 		s.defaultPHP.Selected = indexOfOr(phpVersions, cfg.PHPVersionDefault(), 0)
@@ -91,27 +135,43 @@ func NewSettingsPanel(state *UIState, sm *sites.SiteManager, onThemeChange func(
 		s.defaultDBVer = NewDropdown(dbVerOptions)
 		s.defaultDBList = dbVerOptions
 		s.defaultDBVer.Selected = indexOfOr(dbVerOptions, cfg.DBVersionDefault(), 0)
-		s.defaultRedis.Selected = indexOfOr(redisVersions, cfg.RedisVersionDefault(), 0)
+		s.defaultCache.Selected = indexOfCacheBackend(cfg.CacheBackendDefault())
+		s.defaultRedis.Selected = indexOfOr(s.defaultRedis.Options, cfg.RedisVersionDefault(), 0)
 		s.defaultWeb.Selected = indexOfOr([]string{"nginx", "apache"}, cfg.WebServerDefault(), 0)
 		s.publishDBPort.Value = cfg.PublishDBPortDefault()
 
 		s.httpPortEditor.SingleLine = true
 		s.httpsPortEditor.SingleLine = true
 		s.mkcertPathEditor.SingleLine = true
+		s.dnsTLDEditor.SingleLine = true
+		s.dnsListenEditor.SingleLine = true
 		s.httpPortEditor.Filter = "0123456789"
 		s.httpsPortEditor.Filter = "0123456789"
 		s.httpPortEditor.SetText(strconv.Itoa(cfg.RouterHTTPPort()))
 		s.httpsPortEditor.SetText(strconv.Itoa(cfg.RouterHTTPSPort()))
 		s.mkcertPathEditor.SetText(cfg.MkcertPath())
+		s.tlsProvider.Selected = indexOfOr(tlsProviderKinds, cfg.TLSProvider(), 0)
+		s.routerEngine.Selected = indexOfOr(routerEngineKinds, cfg.RouterEngine(), 0)
+		s.dbuiEngine.Selected = indexOfOr(dbuiEngineKinds, cfg.DBUIEngine(), 0)
+		s.manageHosts.Value = cfg.HostsFileManaged()
+		s.dnsEnabled.Value = cfg.DNSEnabled()
+		s.dnsTLDEditor.SetText(cfg.DNSTLD())
+		s.dnsListenEditor.SetText(cfg.DNSListen())
 
 		// Seed last-applied so we don't fire spurious Set calls on the
 		// first frame.
 		s.lastPHP = cfg.PHPVersionDefault()
 		s.lastEngine = cfg.DBEngineDefault()
 		s.lastDBVer = cfg.DBVersionDefault()
+		s.lastCache = cfg.CacheBackendDefault()
 		s.lastRedis = cfg.RedisVersionDefault()
 		s.lastWeb = cfg.WebServerDefault()
 		s.lastPublishDBPort = cfg.PublishDBPortDefault()
+		s.lastTLSProvider = cfg.TLSProvider()
+		s.lastRouterEngine = cfg.RouterEngine()
+		s.lastDBUIEngine = cfg.DBUIEngine()
+		s.lastManageHosts = cfg.HostsFileManaged()
+		s.lastDNSEnabled = cfg.DNSEnabled()
 	}
 
 	return s
@@ -202,7 +262,13 @@ func (s *SettingsPanel) persistDefaults(gtx layout.Context) {
 			}
 		}
 	}
-	if redis := redisVersions[s.defaultRedis.Selected]; redis != s.lastRedis {
+	if cache := string(cacheBackendKinds[s.defaultCache.Selected]); cache != s.lastCache {
+		s.lastCache = cache
+		if err := cfg.SetCacheBackendDefault(cache); err != nil {
+			s.state.ShowError("Object cache default: " + err.Error())
+		}
+	}
+	if redis := s.defaultRedis.Options[s.defaultRedis.Selected]; redis != s.lastRedis {
 		s.lastRedis = redis
 		if err := cfg.SetRedisVersionDefault(redis); err != nil {
 			s.state.ShowError("Redis default: " + err.Error())
@@ -222,6 +288,45 @@ func (s *SettingsPanel) persistDefaults(gtx layout.Context) {
 		}
 	}
 
+	if prov := tlsProviderKinds[s.tlsProvider.Selected]; prov != s.lastTLSProvider {
+		s.lastTLSProvider = prov
+		if err := cfg.SetTLSProvider(prov); err != nil {
+			s.state.ShowError("Certificate provider: " + err.Error())
+		}
+	}
+	if eng := routerEngineKinds[s.routerEngine.Selected]; eng != s.lastRouterEngine {
+		s.lastRouterEngine = eng
+		if err := cfg.SetRouterEngine(eng); err != nil {
+			s.state.ShowError("Router: " + err.Error())
+		}
+	}
+	if eng := dbuiEngineKinds[s.dbuiEngine.Selected]; eng != s.lastDBUIEngine {
+		s.lastDBUIEngine = eng
+		if err := cfg.SetDBUIEngine(eng); err != nil {
+			s.state.ShowError("Database UI: " + err.Error())
+		}
+	}
+
+	if s.manageHosts.Update(gtx) && s.manageHosts.Value != s.lastManageHosts {
+		s.lastManageHosts = s.manageHosts.Value
+		if err := cfg.SetHostsFileManaged(s.manageHosts.Value); err != nil {
+			s.state.ShowError("Hosts file: " + err.Error())
+		} else {
+			go func() {
+				if err := s.sm.SyncHostsFile(context.Background()); err != nil {
+					s.state.ShowError("Updating the hosts file failed: " + err.Error())
+				}
+			}()
+		}
+	}
+
+	if s.dnsEnabled.Update(gtx) && s.dnsEnabled.Value != s.lastDNSEnabled {
+		s.lastDNSEnabled = s.dnsEnabled.Value
+		if err := cfg.SetDNSEnabled(s.dnsEnabled.Value); err != nil {
+			s.state.ShowError("DNS server: " + err.Error())
+		}
+	}
+
 	// Text inputs commit on the explicit Save button — see
 	// applyNetworkSettings.
 	if s.networkSaveBtn.Clicked(gtx) {
@@ -260,6 +365,14 @@ func (s *SettingsPanel) applyNetworkSettings(cfg networkSettingsWriter) {
 		s.state.ShowError("mkcert path: " + err.Error())
 		return
 	}
+	if err := cfg.SetDNSTLD(s.dnsTLDEditor.Text()); err != nil {
+		s.state.ShowError("DNS domain: " + err.Error())
+		return
+	}
+	if err := cfg.SetDNSListen(s.dnsListenEditor.Text()); err != nil {
+		s.state.ShowError("DNS listen address: " + err.Error())
+		return
+	}
 	// Note: changing router ports here updates intent only; the
 	// running router container keeps its current bindings until the
 	// app is restarted. We deliberately don't restart the router
@@ -274,6 +387,8 @@ type networkSettingsWriter interface {
 	SetRouterHTTPPort(int) error
 	SetRouterHTTPSPort(int) error
 	SetMkcertPath(string) error
+	SetDNSTLD(string) error
+	SetDNSListen(string) error
 }
 
 func (s *SettingsPanel) Layout(gtx layout.Context, th *Theme) layout.Dimensions {
@@ -342,6 +457,11 @@ func (s *SettingsPanel) layoutDefaults(gtx layout.Context, th *Theme) layout.Dim
 					return s.defaultDBVer.Layout(gtx, th, "Database Version")
 				})
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return s.defaultCache.Layout(gtx, th, "Object Cache")
+				})
+			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					return s.defaultRedis.Layout(gtx, th, "Redis Version")
@@ -364,18 +484,30 @@ func (s *SettingsPanel) layoutDefaults(gtx layout.Context, th *Theme) layout.Dim
 	})
 }
 
-// layoutNetworkAndTLS renders the "Network & TLS" card. Three text
-// inputs and a Save button. Port edits do NOT take effect until the
-// next app restart (see applyNetworkSettings).
+// layoutNetworkAndTLS renders the "Network & TLS" card. Five text
+// inputs and a Save button, plus the router, DB UI and certificate
+// provider dropdowns and two checkboxes. Port, router, DB UI, provider
+// and DNS edits do NOT take effect until the next app restart (see
+// applyNetworkSettings).
 func (s *SettingsPanel) layoutNetworkAndTLS(gtx layout.Context, th *Theme) layout.Dimensions {
 	return panel(gtx, th, "Network & TLS", func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				lbl := material.Body2(th.Theme, "Router host ports and the path to your mkcert binary. Port changes take effect on next launch.")
+				lbl := material.Body2(th.Theme, "Router engine and host ports, the database UI at db.localhost, and how HTTPS certificates are issued. Router, port, database UI, certificate provider and DNS server changes take effect on next launch.")
 				lbl.Color = th.Color.Fg2
 				lbl.TextSize = th.Sizes.Body
 				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, lbl.Layout)
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return s.routerEngine.Layout(gtx, th, "Router")
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return s.dbuiEngine.Layout(gtx, th, "Database UI")
+				})
+			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					return LabeledInput(gtx, th, "HTTP port", &s.httpPortEditor, "80")
@@ -386,11 +518,46 @@ func (s *SettingsPanel) layoutNetworkAndTLS(gtx layout.Context, th *Theme) layou
 					return LabeledInput(gtx, th, "HTTPS port", &s.httpsPortEditor, "443")
 				})
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return s.tlsProvider.Layout(gtx, th, "Certificate Provider")
+				})
+			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					return LabeledInput(gtx, th, "mkcert path (leave blank to autodetect)", &s.mkcertPathEditor, "/usr/local/bin/mkcert")
 				})
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					cb := material.CheckBox(th.Theme, &s.manageHosts, "Add custom site domains to the hosts file (asks for administrator rights)")
+					cb.Color = th.Color.Fg
+					cb.IconColor = th.Color.Accent
+					cb.Size = unit.Dp(20)
+					cb.TextSize = th.Sizes.Body
+					return cb.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					cb := material.CheckBox(th.Theme, &s.dnsEnabled, "Run the built-in DNS server for custom domains and offline LAN access")
+					cb.Color = th.Color.Fg
+					cb.IconColor = th.Color.Accent
+					cb.Size = unit.Dp(20)
+					cb.TextSize = th.Sizes.Body
+					return cb.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return LabeledInput(gtx, th, "DNS domain (answers for *.<domain>)", &s.dnsTLDEditor, "test")
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return LabeledInput(gtx, th, "DNS listen address (use a LAN IP to serve phones and tablets)", &s.dnsListenEditor, "127.0.0.1:5300")
+				})
+			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				return PrimaryButton(gtx, th, &s.networkSaveBtn, "Save")
 			}),
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"image"
+	"net/url"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/sqweek/dialog"
 
 	"github.com/PeterBooker/locorum/internal/docker"
+	"github.com/PeterBooker/locorum/internal/mail"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/storage"
 	"github.com/PeterBooker/locorum/internal/types"
@@ -31,11 +33,12 @@ const (
 	tabActivity  = 4
 	tabMail      = 5
 	tabLogs      = 6
-	tabProfiling = 7
-	tabAccess    = 8
+	tabRequests  = 7
+	tabProfiling = 8
+	tabAccess    = 9
 )
 
-var tabLabels = []string{"Overview", "Database", "Utilities", "Hooks", "Activity", "Mail", "Logs", "Profiling", "Access"}
+var tabLabels = []string{"Overview", "Database", "Utilities", "Hooks", "Activity", "Mail", "Logs", "Requests", "Profiling", "Access"}
 
 // SiteDetail is column 3: a header bar (avatar/name/domain/status pill +
 // action buttons), a tab strip, and the active tab's body content. Hosts
@@ -49,7 +52,7 @@ type SiteDetail struct {
 
 	// Tabs
 	activeTab int
-	tabClicks [9]widget.Clickable
+	tabClicks [10]widget.Clickable
 
 	// Header-bar actions (running-only unless noted)
 	startBtn     widget.Clickable
@@ -82,14 +85,19 @@ type SiteDetail struct {
 	// Sub-components
 	dbCreds        *DBCredentials
 	snapshotsPanel *SnapshotsPanel
+	remotesPanel   *RemotesPanel
 	logViewer      *LogViewer
 	wpcliPanel     *WPCLIPanel
 	versionEditor  *VersionEditor
+	resourceEditor *ResourceEditor
+	phpSettings    *PHPSettingsEditor
 	linkChecker    *LinkChecker
 	hooksPanel     *HooksPanel
 	activityTab    *ActivityTab
+	requestsTab    *RequestsTab
 	profilingPanel *ProfilingPanel
 	accessPanel    *AccessPanel
+	configDrift    *ConfigDriftCard
 
 	// recentActivityLoadedFor records the last site for which we kicked
 	// off a recent-activity load. Stops Layout() from spawning a fresh
@@ -117,14 +125,19 @@ func NewSiteDetail(state *UIState, sm *sites.SiteManager, toasts *Notifications)
 			return c
 		}(),
 		snapshotsPanel: NewSnapshotsPanel(state, sm, toasts),
+		remotesPanel:   NewRemotesPanel(state, sm, toasts),
 		logViewer:      NewLogViewer(state, sm),
 		wpcliPanel:     NewWPCLIPanel(state, sm),
 		versionEditor:  NewVersionEditor(state, sm, toasts),
+		resourceEditor: NewResourceEditor(state, sm, toasts),
+		phpSettings:    NewPHPSettingsEditor(state, sm, toasts),
 		linkChecker:    NewLinkChecker(state, sm),
 		hooksPanel:     NewHooksPanel(state, sm, sm, toasts),
 		activityTab:    NewActivityTab(state, sm),
+		requestsTab:    NewRequestsTab(state, sm),
 		profilingPanel: NewProfilingPanel(state, sm, toasts),
 		accessPanel:    NewAccessPanel(state, sm, toasts),
+		configDrift:    NewConfigDriftCard(state, sm, toasts),
 	}
 	sd.describeCache = NewDescribeCache(sm, state)
 	sd.list.Axis = layout.Vertical
@@ -180,6 +193,7 @@ func (sd *SiteDetail) HandleUserInteractions(gtx layout.Context) {
 		sd.describeCache.Get(site.ID, true)
 		sd.dbCreds.HandleUserInteractions(gtx, site)
 		sd.snapshotsPanel.HandleUserInteractions(gtx, site)
+		sd.remotesPanel.HandleUserInteractions(gtx, site)
 	case tabUtilities:
 		if site.Started {
 			sd.wpcliPanel.HandleUserInteractions(gtx, site.ID)
@@ -193,13 +207,18 @@ func (sd *SiteDetail) HandleUserInteractions(gtx layout.Context) {
 		if site.Started {
 			sd.logViewer.HandleUserInteractions(gtx, site.ID)
 		}
+	case tabRequests:
+		sd.requestsTab.HandleUserInteractions(gtx, site)
 	case tabProfiling:
 		sd.profilingPanel.HandleUserInteractions(gtx, site)
 	case tabAccess:
 		sd.accessPanel.HandleUserInteractions(gtx, site)
 	default: // tabOverview
 		sd.handleOverviewClicks(gtx, site)
+		sd.configDrift.HandleUserInteractions(gtx, site)
 		sd.versionEditor.HandleUserInteractions(gtx, site)
+		sd.resourceEditor.HandleUserInteractions(gtx, site)
+		sd.phpSettings.HandleUserInteractions(gtx, site)
 		if sd.activityViewAllBtn.Clicked(gtx) {
 			sd.activeTab = tabActivity
 		}
@@ -427,11 +446,17 @@ func (sd *SiteDetail) handleHeaderActions(gtx layout.Context, site *types.Site)
 		}()
 	}
 	if sd.databaseBtn.Clicked(gtx) && site.Started {
+		id := site.ID
 		go func() {
-			// Adminer runs at db.localhost in the global router.
-			if err := openInBrowser("https://db.localhost"); err != nil {
-				sd.state.ShowError("Failed to open Database UI: " + err.Error())
+			// The global DB UI at db.localhost, already logged in to
+			// this site's database.
+			u, err := sd.sm.DBUILoginURL(id)
+			if err == nil {
+				err = openInBrowser(u)
 			}
+			SurfaceError(sd.state, "Failed to open Database UI", err, func() {
+				_ = sd.sm.StartSite(context.Background(), id)
+			})
 		}()
 	}
 	if sd.openAdminBtn.Clicked(gtx) && site.Started {
@@ -484,7 +509,8 @@ func (sd *SiteDetail) handleHeaderActions(gtx layout.Context, site *types.Site)
 			if !strings.HasSuffix(dest, ".tar.gz") {
 				dest += ".tar.gz"
 			}
-			if err := sd.sm.ExportSite(context.Background(), id, dest); err != nil {
+			// The save dialog has already asked before overwriting.
+			if err := sd.sm.ExportSiteReplacing(context.Background(), id, dest); err != nil {
 				sd.state.ShowError("Export failed: " + err.Error())
 			}
 		}()
@@ -565,9 +591,11 @@ func (sd *SiteDetail) layoutTabContent(gtx layout.Context, th *Theme, site *type
 	case tabActivity:
 		return sd.activityTab.Layout(gtx, th, site.ID)
 	case tabMail:
-		return sd.layoutMailTab(gtx, th)
+		return sd.layoutMailTab(gtx, th, site)
 	case tabLogs:
 		return sd.layoutLogsTab(gtx, th, site)
+	case tabRequests:
+		return sd.requestsTab.Layout(gtx, th, site)
 	case tabProfiling:
 		return sd.profilingPanel.Layout(gtx, th, site)
 	case tabAccess:
@@ -577,8 +605,8 @@ func (sd *SiteDetail) layoutTabContent(gtx layout.Context, th *Theme, site *type
 	}
 }
 
-// layoutOverviewTab renders the environment grid + activity feed + secondary
-// actions row + version editor.
+// layoutOverviewTab renders the config.yaml drift card (when present) +
+// environment grid + activity feed + secondary actions row + version editor.
 func (sd *SiteDetail) layoutOverviewTab(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
 	if sd.lastSiteID != site.ID {
 		sd.lastSiteID = site.ID
@@ -590,6 +618,9 @@ func (sd *SiteDetail) layoutOverviewTab(gtx layout.Context, th *Theme, site *typ
 	entries := overviewActivityEntries(rows, time.Now())
 
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return sd.configDrift.Layout(gtx, th, site)
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return sd.layoutEnvPanel(gtx, th, site)
 		}),
@@ -607,6 +638,16 @@ func (sd *SiteDetail) layoutOverviewTab(gtx layout.Context, th *Theme, site *typ
 				return sd.versionEditor.Layout(gtx, th, site)
 			})
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return panel(gtx, th, "Resources", func(gtx layout.Context) layout.Dimensions {
+				return sd.resourceEditor.Layout(gtx, th, site)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return panel(gtx, th, "PHP", func(gtx layout.Context) layout.Dimensions {
+				return sd.phpSettings.Layout(gtx, th, site)
+			})
+		}),
 	)
 }
 
@@ -661,7 +702,7 @@ func (sd *SiteDetail) layoutEnvPanel(gtx layout.Context, th *Theme, site *types.
 	pairs := []envCell{
 		{"PHP", site.PHPVersion},
 		{dbLabel, site.DBVersion},
-		{"Redis", site.RedisVersion},
+		{"Object cache", cacheLabel(site)},
 		{"Web server", site.WebServer},
 		{"URL", url},
 		{"Public Dir", site.PublicDir},
@@ -746,6 +787,14 @@ func (sd *SiteDetail) layoutDatabaseTab(gtx layout.Context, th *Theme, site *typ
 				return sd.snapshotsPanel.Layout(gtx, th, site)
 			})
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Spacer{Height: th.Spacing.MD}.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return panel(gtx, th, "Remotes", func(gtx layout.Context) layout.Dimensions {
+				return sd.remotesPanel.Layout(gtx, th, site)
+			})
+		}),
 	)
 }
 
@@ -779,10 +828,12 @@ func (sd *SiteDetail) layoutLogsTab(gtx layout.Context, th *Theme, site *types.S
 	})
 }
 
-func (sd *SiteDetail) layoutMailTab(gtx layout.Context, th *Theme) layout.Dimensions {
+func (sd *SiteDetail) layoutMailTab(gtx layout.Context, th *Theme, site *types.Site) layout.Dimensions {
 	if sd.mailOpenBtn.Clicked(gtx) {
+		// Mailpit's search URL opens the inbox filtered to this site.
+		target := "https://mail.localhost/search?q=" + url.QueryEscape("tag:"+sites.MailTag(site.Slug))
 		go func() {
-			if err := openInBrowser("https://mail.localhost"); err != nil {
+			if err := openInBrowser(target); err != nil {
 				sd.state.ShowError("Failed to open mail UI: " + err.Error())
 			}
 		}()
@@ -790,7 +841,8 @@ func (sd *SiteDetail) layoutMailTab(gtx layout.Context, th *Theme) layout.Dimens
 	return panel(gtx, th, "Mail", func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				lbl := material.Body2(th.Theme, "Locorum captures all outgoing mail in MailHog. The catch-all UI is reachable at https://mail.localhost while the platform is running.")
+				lbl := material.Body2(th.Theme, "Locorum captures all outgoing mail in Mailpit. The catch-all UI is reachable at https://mail.localhost while the platform is running; "+
+					"mail WordPress sends is tagged with the site. Scripts can check it with `locorum mail search --site "+site.Slug+" <query>`.")
 				lbl.Color = th.Color.Fg2
 				lbl.TextSize = th.Sizes.Body
 				return lbl.Layout(gtx)
@@ -799,12 +851,35 @@ func (sd *SiteDetail) layoutMailTab(gtx layout.Context, th *Theme) layout.Dimens
 				return layout.Spacer{Height: th.Spacing.SM}.Layout(gtx)
 			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return iconLabelButton(gtx, th, &sd.mailOpenBtn, IconMail, "Open MailHog", btnPrimary)
+				return KVRows(gtx, th, mailModeRows(site))
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Spacer{Height: th.Spacing.SM}.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return iconLabelButton(gtx, th, &sd.mailOpenBtn, IconMail, "Open Mailpit", btnPrimary)
 			}),
 		)
 	})
 }
 
+// mailModeRows summarises where the site's outgoing mail goes. The
+// mode is changed with `locorum site mail` while the site is stopped.
+func mailModeRows(site *types.Site) []KV {
+	switch sites.MailMode(site) {
+	case mail.ModeRelay:
+		return []KV{
+			{"Mail mode", "Relay (captured, and delivered to allowed domains)"},
+			{"Smarthost", site.MailRelayHost},
+			{"Allowed domains", strings.Join(site.MailRelayDomains, ", ")},
+		}
+	case mail.ModeBlackhole:
+		return []KV{{"Mail mode", "Blackhole (mail is discarded)"}}
+	default:
+		return []KV{{"Mail mode", "Capture"}}
+	}
+}
+
 func (sd *SiteDetail) layoutInitError(gtx layout.Context, th *Theme, errMsg string) layout.Dimensions {
 	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
@@ -11,6 +11,7 @@ import (
 	"github.com/PeterBooker/locorum/internal/health"
 	"github.com/PeterBooker/locorum/internal/hooks"
 	"github.com/PeterBooker/locorum/internal/orch"
+	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/storage"
 	"github.com/PeterBooker/locorum/internal/types"
 )
@@ -129,6 +130,10 @@ type UIState struct {
 	// helpers below so Layout() never holds the mutex while iterating.
 	activityState map[string]*activitySiteCache
 
+	// configDrift holds unapplied config.yaml edits keyed by siteID.
+	// Written by SiteManager.OnConfigDrift; a nil drift deletes.
+	configDrift map[string]*sites.ConfigDrift
+
 	// Aggregate health of the global services (router, mail, adminer).
 	// Polled from the main goroutine; written via SetServicesHealth.
 	servicesHealth ServicesHealth
@@ -242,6 +247,7 @@ func NewUIState() *UIState {
 		hookState:       make(map[string]*hookSiteState),
 		lifecycleState:  make(map[string]*lifecycleSiteState),
 		activityState:   make(map[string]*activitySiteCache),
+		configDrift:     make(map[string]*sites.ConfigDrift),
 		healthSeen:      make(map[string]bool),
 		healthFirstFire: true,
 	}
@@ -1461,3 +1467,26 @@ func prependCap(dst []storage.ActivityEvent, v storage.ActivityEvent, limit int)
 	out = append(out, dst[:keep]...)
 	return out
 }
+
+// ─── config.yaml drift ──────────────────────────────────────────────────────
+
+// SetConfigDrift records (or, with a nil drift, clears) the unapplied
+// config.yaml edits for siteID and triggers a redraw.
+func (s *UIState) SetConfigDrift(siteID string, d *sites.ConfigDrift) {
+	s.mu.Lock()
+	if d == nil {
+		delete(s.configDrift, siteID)
+	} else {
+		s.configDrift[siteID] = d
+	}
+	s.mu.Unlock()
+	s.Invalidate()
+}
+
+// ConfigDrift returns the unapplied config.yaml edits for siteID, or
+// nil. The returned value is shared; callers must not mutate it.
+func (s *UIState) ConfigDrift(siteID string) *sites.ConfigDrift {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.configDrift[siteID]
+}
@@ -115,6 +115,15 @@ func New(sm *sites.SiteManager) *UI {
 		state.AppendActivity(siteID, ev)
 	}
 
+	// config.yaml drift: the Overview card renders from state; a toast
+	// points the user at it when new edits are detected.
+	sm.OnConfigDrift = func(siteID string, d *sites.ConfigDrift) {
+		state.SetConfigDrift(siteID, d)
+		if d != nil {
+			ui.Toasts.ShowInfo("config.yaml for " + d.Slug + " has changes to review")
+		}
+	}
+
 	return ui
 }
 
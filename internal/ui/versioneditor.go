@@ -7,6 +7,7 @@ import (
 	"gioui.org/layout"
 	"gioui.org/widget"
 
+	"github.com/PeterBooker/locorum/internal/cachebackend"
 	"github.com/PeterBooker/locorum/internal/dbengine"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/types"
@@ -38,19 +39,23 @@ type VersionEditor struct {
 	phpDropdown       *Dropdown
 	dbEngineDropdown  *Dropdown
 	dbVersionDropdown *Dropdown
-	redisDropdown     *Dropdown
+	cacheDropdown     *Dropdown
+	cacheVerDropdown  *Dropdown
 	saveBtn           widget.Clickable
 
-	dbVersions []string
+	dbVersions    []string
+	cacheVersions []string
 
 	// Track which site we last synced dropdowns for, and the baseline
 	// values used to compute the dirty flag.
-	lastSiteID                                                string
-	initialPHP, initialEngine, initialDBVersion, initialRedis string
+	lastSiteID                                  string
+	initialPHP, initialEngine, initialDBVersion string
+	initialCacheBackend, initialCacheVersion    string
 }
 
 func NewVersionEditor(state *UIState, sm *sites.SiteManager, toasts *Notifications) *VersionEditor {
 	defaultVersions := dbVersionsFor(dbEngineKinds[0])
+	defaultCacheVersions := cachebackend.KnownVersions(cacheBackendKinds[0])
 	return &VersionEditor{
 		state:             state,
 		sm:                sm,
@@ -59,7 +64,9 @@ func NewVersionEditor(state *UIState, sm *sites.SiteManager, toasts *Notificatio
 		dbEngineDropdown:  NewDropdown(dbEngineOptions),
 		dbVersionDropdown: NewDropdown(defaultVersions),
 		dbVersions:        defaultVersions,
-		redisDropdown:     NewDropdown(redisVersions),
+		cacheDropdown:     NewDropdown(cacheBackendOptions),
+		cacheVerDropdown:  NewDropdown(defaultCacheVersions),
+		cacheVersions:     defaultCacheVersions,
 	}
 }
 
@@ -69,7 +76,7 @@ func (ve *VersionEditor) Layout(gtx layout.Context, th *Theme, site *types.Site)
 			{"PHP", site.PHPVersion},
 			{"DB Engine", titleASCII(site.DBEngine)},
 			{"DB Version", site.DBVersion},
-			{"Redis", site.RedisVersion},
+			{"Object Cache", cacheLabel(site)},
 		})
 	}
 
@@ -85,6 +92,10 @@ func (ve *VersionEditor) Layout(gtx layout.Context, th *Theme, site *types.Site)
 		ve.dbVersions = wantVersions
 		ve.dbVersionDropdown = NewDropdown(wantVersions)
 	}
+	if want := cachebackend.KnownVersions(cacheBackendKinds[ve.cacheDropdown.Selected]); !slicesEqual(want, ve.cacheVersions) {
+		ve.cacheVersions = want
+		ve.cacheVerDropdown = NewDropdown(want)
+	}
 
 	dirty := ve.isDirty()
 	sectionFn := Section
@@ -112,8 +123,16 @@ func (ve *VersionEditor) Layout(gtx layout.Context, th *Theme, site *types.Site)
 				})
 			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: th.Spacing.SM}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return ve.cacheDropdown.Layout(gtx, th, "Object Cache")
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if len(ve.cacheVersions) == 0 {
+					return layout.Dimensions{}
+				}
 				return layout.Inset{Bottom: th.Spacing.MD}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-					return ve.redisDropdown.Layout(gtx, th, "Redis Version")
+					return ve.cacheVerDropdown.Layout(gtx, th, "Cache Version")
 				})
 			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -129,7 +148,8 @@ func (ve *VersionEditor) isDirty() bool {
 	return phpVersions[ve.phpDropdown.Selected] != ve.initialPHP ||
 		string(dbEngineKinds[ve.dbEngineDropdown.Selected]) != ve.initialEngine ||
 		ve.dbVersions[ve.dbVersionDropdown.Selected] != ve.initialDBVersion ||
-		redisVersions[ve.redisDropdown.Selected] != ve.initialRedis
+		string(cacheBackendKinds[ve.cacheDropdown.Selected]) != ve.initialCacheBackend ||
+		selectedOption(ve.cacheVersions, ve.cacheVerDropdown.Selected) != ve.initialCacheVersion
 }
 
 func (ve *VersionEditor) syncDropdowns(site *types.Site) {
@@ -153,16 +173,26 @@ func (ve *VersionEditor) syncDropdowns(site *types.Site) {
 			break
 		}
 	}
-	for i, v := range redisVersions {
-		if v == site.RedisVersion {
-			ve.redisDropdown.Selected = i
-			break
-		}
-	}
+	cacheKind := cachebackend.Resolve(site)
+	ve.cacheDropdown.Selected = indexOfCacheBackend(string(cacheKind))
+	ve.cacheVersions = cachebackend.KnownVersions(cacheKind)
+	ve.cacheVerDropdown = NewDropdown(ve.cacheVersions)
+	ve.cacheVerDropdown.Selected = indexOfOr(ve.cacheVersions, site.CacheVersion, 0)
 	ve.initialPHP = site.PHPVersion
 	ve.initialEngine = site.DBEngine
 	ve.initialDBVersion = site.DBVersion
-	ve.initialRedis = site.RedisVersion
+	ve.initialCacheBackend = string(cacheKind)
+	ve.initialCacheVersion = site.CacheVersion
+}
+
+// cacheLabel renders the site's object cache as "Redis 8.0", or "None".
+func cacheLabel(site *types.Site) string {
+	k := cachebackend.Resolve(site)
+	label := cacheBackendOptions[indexOfCacheBackend(string(k))]
+	if site.CacheVersion != "" {
+		label += " " + site.CacheVersion
+	}
+	return label
 }
 
 // HandleUserInteractions processes the Save button click on the version editor.
@@ -176,14 +206,16 @@ func (ve *VersionEditor) HandleUserInteractions(gtx layout.Context, site *types.
 		phpVer := phpVersions[ve.phpDropdown.Selected]
 		newEngine := string(dbEngineKinds[ve.dbEngineDropdown.Selected])
 		newDBVer := ve.dbVersions[ve.dbVersionDropdown.Selected]
-		redisVer := redisVersions[ve.redisDropdown.Selected]
+		cacheBackend := string(cacheBackendKinds[ve.cacheDropdown.Selected])
+		cacheVer := selectedOption(ve.cacheVersions, ve.cacheVerDropdown.Selected)
 
 		// Capture snapshot baselines optimistically — if the change is
 		// safe in-place, this matches what UpdateSiteVersions writes.
 		ve.initialPHP = phpVer
 		ve.initialEngine = newEngine
 		ve.initialDBVersion = newDBVer
-		ve.initialRedis = redisVer
+		ve.initialCacheBackend = cacheBackend
+		ve.initialCacheVersion = cacheVer
 
 		// Engine swap or unsafe version transition → migrate flow.
 		// Same engine + safe version → in-place update.
@@ -206,12 +238,13 @@ func (ve *VersionEditor) HandleUserInteractions(gtx layout.Context, site *types.
 					return
 				}
 				// Apply the rest of the changes in-place after migrate
-				// (same engine, possibly different PHP/Redis).
+				// (same engine, possibly different PHP/cache).
 				if err := ve.sm.UpdateSiteVersionsWithEngine(context.Background(), siteID, sites.VersionsChange{
 					PHPVersion:   phpVer,
-					RedisVersion: redisVer,
+					CacheBackend: cacheBackend,
+					CacheVersion: cacheVer,
 				}); err != nil {
-					ve.state.ShowError("Failed to update PHP/Redis after migrate: " + err.Error())
+					ve.state.ShowError("Failed to update PHP/cache after migrate: " + err.Error())
 					return
 				}
 				ve.toasts.ShowSuccess("Database engine migrated; start the site to use the new version.")
@@ -220,7 +253,8 @@ func (ve *VersionEditor) HandleUserInteractions(gtx layout.Context, site *types.
 			if err := ve.sm.UpdateSiteVersionsWithEngine(context.Background(), siteID, sites.VersionsChange{
 				PHPVersion:   phpVer,
 				DBVersion:    newDBVer,
-				RedisVersion: redisVer,
+				CacheBackend: cacheBackend,
+				CacheVersion: cacheVer,
 			}); err != nil {
 				if errors.Is(err, sites.ErrUnsafeVersionTransition) {
 					ve.state.ShowError("That version change requires the migrate flow — try again to confirm.")
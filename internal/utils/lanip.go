@@ -123,6 +123,47 @@ func detectLANIPv4(deps LANDeps) (net.IP, error) {
 	return nil, ErrNoLANIP
 }
 
+// LANSubnet returns the network ip sits on, read from the mask of the
+// host interface that carries it, as a CIDR string ("192.168.1.0/24").
+// When no interface carries ip — e.g. a lan.ip_override for a network
+// the host has since left — it falls back to ip's /24, the common home
+// router layout. Returns "" for a nil or non-IPv4 ip.
+func LANSubnet(ip net.IP) string {
+	return lanSubnet(ip, LANDeps{})
+}
+
+func lanSubnet(ip net.IP, deps LANDeps) string {
+	v4 := ip.To4()
+	if v4 == nil {
+		return ""
+	}
+	if deps.Interfaces == nil {
+		deps.Interfaces = net.Interfaces
+	}
+	if deps.Addrs == nil {
+		deps.Addrs = func(iface *net.Interface) ([]net.Addr, error) { return iface.Addrs() }
+	}
+	mask := net.CIDRMask(24, 32)
+	if ifaces, err := deps.Interfaces(); err == nil {
+	search:
+		for _, iface := range ifaces {
+			addrs, err := deps.Addrs(&iface)
+			if err != nil {
+				continue
+			}
+			for _, a := range addrs {
+				if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.Equal(v4) {
+					if ones, bits := ipnet.Mask.Size(); bits == 32 && ones > 0 {
+						mask = ipnet.Mask
+					}
+					break search
+				}
+			}
+		}
+	}
+	return (&net.IPNet{IP: v4.Mask(mask), Mask: mask}).String()
+}
+
 // outboundIPv4 opens a connectionless UDP socket to a public address
 // and reads the resulting LocalAddr. No traffic is sent — Dial only
 // consults the routing table. The destination port is arbitrary; 80 is
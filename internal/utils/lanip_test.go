@@ -199,3 +199,22 @@ func TestIsUsableLAN(t *testing.T) {
 		}
 	}
 }
+
+func TestLANSubnet(t *testing.T) {
+	f := &fakeStack{
+		ifaces: []net.Interface{{Index: 1, Name: "en0", Flags: net.FlagUp}},
+		addrs:  map[string][]net.Addr{"en0": {ipnet("10.20.30.40", 16)}},
+	}
+	cases := map[string]string{
+		"10.20.30.40":  "10.20.0.0/16",   // interface mask wins
+		"192.168.1.42": "192.168.1.0/24", // not on any interface → /24
+	}
+	for ip, want := range cases {
+		if got := lanSubnet(net.ParseIP(ip), f.deps()); got != want {
+			t.Errorf("lanSubnet(%s) = %q, want %q", ip, got, want)
+		}
+	}
+	if got := lanSubnet(nil, f.deps()); got != "" {
+		t.Errorf("lanSubnet(nil) = %q, want empty", got)
+	}
+}
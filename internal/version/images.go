@@ -11,26 +11,40 @@ import (
 const (
 	// renovate: image=traefik versioning=docker
 	TraefikImage = "traefik:v3.5"
+	// renovate: image=caddy versioning=docker
+	// Alpine variant: the router's admin client shells out to its
+	// busybox wget.
+	CaddyImage = "caddy:2.10-alpine"
 	// renovate: image=nginx versioning=docker
 	NginxImage = "nginx:1.28-alpine"
 	// renovate: image=httpd versioning=docker
 	ApacheImage = "httpd:2.4-alpine"
-	// renovate: image=mailhog/mailhog versioning=docker
-	MailhogImage = "mailhog/mailhog"
+	// renovate: image=axllent/mailpit versioning=docker
+	// Replaces the unmaintained mailhog/mailhog. Speaks the same SMTP
+	// (1025) and web (8025) ports, plus the REST API internal/mail uses.
+	MailpitImage = "axllent/mailpit:v1.27"
 	// renovate: image=adminer versioning=docker
 	// Pinned to a major+minor tag (not :latest) so a malicious upstream
 	// re-tag of `:latest` cannot land in our admin DB UI silently. Bump
 	// in lockstep with the renovate PR.
 	AdminerImage = "adminer:5.4.2-standalone"
+	// renovate: image=phpmyadmin versioning=docker
+	// The alternative global DB UI (dbui.engine = phpmyadmin). Same
+	// pinning rationale as AdminerImage.
+	PHPMyAdminImage = "phpmyadmin:5.2.2-apache"
 	// renovate: image=alpine versioning=docker
 	AlpineImage = "alpine:3"
 
 	// Per-site backend images get the user-configurable version suffix appended.
-	WodbyPHPImagePrefix = "wodby/php:"
-	MySQLImagePrefix    = "mysql:"
-	MariaDBImagePrefix  = "mariadb:"
-	RedisImagePrefix    = "redis:"
-	RedisImageSuffix    = "-alpine"
+	WodbyPHPImagePrefix  = "wodby/php:"
+	MySQLImagePrefix     = "mysql:"
+	MariaDBImagePrefix   = "mariadb:"
+	RedisImagePrefix     = "redis:"
+	RedisImageSuffix     = "-alpine"
+	ValkeyImagePrefix    = "valkey/valkey:"
+	ValkeyImageSuffix    = "-alpine"
+	MemcachedImagePrefix = "memcached:"
+	MemcachedImageSuffix = "-alpine"
 )
 
 // WP-CLI is bundled as a phar binary downloaded once at app start and
@@ -20,10 +20,13 @@ import (
 	"github.com/PeterBooker/locorum/internal/applog"
 	settings "github.com/PeterBooker/locorum/internal/config"
 	"github.com/PeterBooker/locorum/internal/daemon"
+	"github.com/PeterBooker/locorum/internal/devdns"
 	"github.com/PeterBooker/locorum/internal/docker"
 	"github.com/PeterBooker/locorum/internal/health"
 	"github.com/PeterBooker/locorum/internal/hooks"
 	"github.com/PeterBooker/locorum/internal/platform"
+	"github.com/PeterBooker/locorum/internal/router"
+	"github.com/PeterBooker/locorum/internal/router/caddy"
 	"github.com/PeterBooker/locorum/internal/router/traefik"
 	"github.com/PeterBooker/locorum/internal/sites"
 	"github.com/PeterBooker/locorum/internal/storage"
@@ -109,25 +112,17 @@ func main() {
 	// safe to call before or after applog.Init.
 	applog.SetDebug(cfg.DebugLogging())
 
-	mkcert := tlspkg.NewMkcert(
-		filepath.Join(homeDir, ".locorum", "certs"),
-		filepath.Join(homeDir, ".locorum", "bin"),
-	)
+	certProvider := newTLSProvider(homeDir, cfg.TLSProvider())
 
-	rtr, err := traefik.New(traefik.Config{
-		HomeDir:    homeDir,
-		AppVersion: version.Version,
-		LogLevel:   os.Getenv("LOCORUM_LOG_LEVEL"),
-		HTTPPort:   cfg.RouterHTTPPort(),
-		HTTPSPort:  cfg.RouterHTTPSPort(),
-	}, d, mkcert, config)
+	rtr, err := newRouter(cfg, homeDir, d, certProvider)
 	if err != nil {
 		log.Fatalln("Error initializing router:", err)
 	}
 
 	a := application.New(config, d, homeDir, rtr)
+	a.SetDBUIEngine(cfg.DBUIEngine())
 
-	hookLogsDir := filepath.Join(homeDir, ".locorum", "hooks", "runs")
+	hookLogsDir := hooks.RunLogsDir(homeDir)
 	if err := utils.EnsureDir(hookLogsDir); err != nil {
 		slog.Warn("hooks: could not create logs dir", "err", err.Error())
 	}
@@ -148,10 +143,17 @@ func main() {
 		log.Fatalln("Error initializing hooks runner:", err)
 	}
 
-	sm := sites.NewSiteManager(st, a.GetClient(), d, rtr, mkcert, hookRunner, config, homeDir, cfg)
+	sm := sites.NewSiteManager(st, a.GetClient(), d, rtr, certProvider, hookRunner, config, homeDir, cfg)
 
 	if daemonMode {
-		runDaemonMode(homeDir, sm, a, d)
+		var runner *health.Runner
+		if cfg.HealthEnabled() {
+			runner = newHealthRunner(plat, d, certProvider, caInstaller(certProvider), sm, cfg, homeDir, nil)
+		}
+		runDaemonMode(homeDir, sm, a, d, runner)
+		if runner != nil {
+			_ = runner.Close()
+		}
 		_ = st.Close()
 		return
 	}
@@ -164,7 +166,12 @@ func main() {
 	if dp := userInterface.Settings.DiagnosticsPanel(); dp != nil {
 		dp.SetResetInfraCard(ui.NewResetInfraCard(
 			userInterface.State, sm, userInterface.Toasts,
-			func(ctx context.Context) error { return a.ResetInfrastructure(ctx) },
+			func(ctx context.Context) error {
+				// The reset recreates the router network, possibly on a
+				// new subnet; re-read its gateway for the allowlists.
+				defer sm.InvalidateRouterNetwork()
+				return a.ResetInfrastructure(ctx)
+			},
 		))
 		dp.SetUpdateBanner(ui.NewUpdateBannerCard(userInterface.State, cfg))
 	}
@@ -186,10 +193,7 @@ func main() {
 	// System Health runner. Wire it now so the UI can subscribe — the
 	// runner's own loop won't tick until Start, which we defer until
 	// Initialize completes.
-	mkcertInstaller := func(ctx context.Context) error {
-		return mkcert.InstallCA(ctx)
-	}
-	runner := newHealthRunner(plat, d, mkcert, mkcertInstaller, sm, cfg, homeDir, userInterface.State)
+	runner := newHealthRunner(plat, d, certProvider, caInstaller(certProvider), sm, cfg, homeDir, userInterface.State)
 	defer func() { _ = runner.Close() }()
 
 	if cfg.HealthEnabled() {
@@ -234,7 +238,11 @@ func main() {
 	// process; the eventLoop teardown releases them.
 	var daemonLock *daemon.Lock
 	var daemonServer *daemon.Server
+	var dnsServer *devdns.Server
 	defer func() {
+		if dnsServer != nil {
+			_ = dnsServer.Close()
+		}
 		if daemonServer != nil {
 			daemonServer.Shutdown(2 * time.Second)
 		}
@@ -258,12 +266,24 @@ func main() {
 		// rest of startup — the user still gets a usable window, just
 		// without CLI/MCP wiring. The daemon owner can be inspected
 		// via the lock-error log.
+		//
+		// The DNS server, the request recorder and the mail relay
+		// start with the lock, in whichever process owns it: a
+		// headless daemon already holding it runs its own, and two
+		// relay loops would each forward the same message.
 		if daemonLock == nil {
-			lock, srv, err := startDaemonServices(context.Background(), homeDir, sm)
+			lock, srv, err := startDaemonServices(context.Background(), homeDir, sm, runner)
 			if err != nil {
 				reportLockError(err)
 			} else {
 				daemonLock, daemonServer = lock, srv
+				dnsServer = startDevDNS(context.Background(), sm)
+
+				// Feed the per-site Requests tab from the router's access log.
+				go sm.WatchRequests(context.Background(), traefik.ContainerName)
+
+				// Forward relay-mode sites' captured mail to their smarthost.
+				go sm.WatchMailRelay(context.Background(), 5*time.Second)
 			}
 		}
 
@@ -271,6 +291,11 @@ func main() {
 			slog.Error("Error reconciling site state: " + err.Error())
 		}
 
+		// Reconcile hand edits to each site's config.yaml — the first
+		// pass catches edits made while Locorum was closed, later
+		// passes pick up new ones within a few seconds.
+		go sm.WatchConfigYAML(context.Background(), 3*time.Second)
+
 		// Best-effort snapshot retention sweep. Logs counts; failures
 		// don't block startup.
 		if _, err := sm.SweepSnapshots(sm.LoadRetentionPolicy()); err != nil {
@@ -285,7 +310,7 @@ func main() {
 			slog.Warn("activity: retention sweep failed", "err", err.Error())
 		}
 
-		refreshTLSNotice(mkcert, userInterface.State)
+		refreshTLSNotice(certProvider, userInterface.State)
 
 		// Start the runner once the docker client is ready. Pre-init
 		// startup would have most checks fail loudly (Ping, ProviderInfo)
@@ -366,7 +391,7 @@ func main() {
 // newHealthRunner builds the production runner with the bundled checks.
 // Cadence and thresholds come from the user's config; missing keys fall
 // back to documented defaults.
-func newHealthRunner(plat *platform.Info, d *docker.Docker, mkcert *tlspkg.Mkcert, mkInstaller func(context.Context) error, sm *sites.SiteManager, cfg *settings.Config, homeDir string, state *ui.UIState) *health.Runner {
+func newHealthRunner(plat *platform.Info, d *docker.Docker, certProvider tlspkg.Provider, trustInstaller func(context.Context) error, sm *sites.SiteManager, cfg *settings.Config, homeDir string, state *ui.UIState) *health.Runner {
 	cadence := time.Duration(cfg.HealthCadenceMinutes()) * time.Minute
 	if cadence <= 0 {
 		cadence = 5 * time.Minute
@@ -382,12 +407,23 @@ func newHealthRunner(plat *platform.Info, d *docker.Docker, mkcert *tlspkg.Mkcer
 		}
 		state.ShowPortHoldersModal(port, text)
 	}
+	var dnsTLD, dnsListen string
+	if cfg.DNSEnabled() {
+		dnsTLD, dnsListen = cfg.DNSTLD(), cfg.DNSListen()
+	}
 	checks := health.Bundled(health.BundledOpts{
 		Platform:            plat,
 		Engine:              d,
-		Mkcert:              mkcert,
-		MkcertInstaller:     mkInstaller,
+		Mkcert:              certProvider,
+		MkcertInstaller:     trustInstaller,
 		Sites:               sm,
+		XdebugSites:         sm,
+		XdebugPort:          docker.XdebugClientPort,
+		ConfigDrift:         sm,
+		OOMKills:            sm,
+		Certs:               sm,
+		DNSTLD:              dnsTLD,
+		DNSListen:           dnsListen,
 		HostStatfsPath:      homeDir,
 		RouterContainerName: traefik.ContainerName,
 		PortHolderSink:      portHolderSink,
@@ -430,25 +466,75 @@ func persistHealthSeen(cfg *settings.Config, state *ui.UIState) {
 	}
 }
 
-// refreshTLSNotice reads the current mkcert status and updates the banner.
-// When the local CA isn't trusted, the banner gets an action button that
-// downloads mkcert (if needed) and runs `mkcert -install` in a goroutine,
-// then re-reads the status. Re-entrant: callers may invoke after every
-// successful or failed install attempt.
-func refreshTLSNotice(mkcert *tlspkg.Mkcert, state *ui.UIState) {
-	status, err := mkcert.Available(context.Background())
+// newTLSProvider builds the certificate provider named by the
+// tls.provider setting. Both variants write leaf certs to the same
+// ~/.locorum/certs tree, so switching providers only needs a site restart
+// for certs to be re-issued.
+func newTLSProvider(homeDir, kind string) tlspkg.Provider {
+	certDir := filepath.Join(homeDir, ".locorum", "certs")
+	if kind == "builtin" {
+		return tlspkg.NewLocalCA(filepath.Join(homeDir, ".locorum", "ca"), certDir)
+	}
+	return tlspkg.NewMkcert(certDir, filepath.Join(homeDir, ".locorum", "bin"))
+}
+
+// newRouter builds the router backend chosen in settings. Both engines
+// share a container name and host ports, so switching between them on
+// restart replaces one container with the other.
+func newRouter(cfg *settings.Config, homeDir string, d *docker.Docker, prov tlspkg.Provider) (router.Router, error) {
+	logLevel := os.Getenv("LOCORUM_LOG_LEVEL")
+	if cfg.RouterEngine() == "caddy" {
+		return caddy.New(caddy.Config{
+			HomeDir:    homeDir,
+			AppVersion: version.Version,
+			LogLevel:   logLevel,
+			HTTPPort:   cfg.RouterHTTPPort(),
+			HTTPSPort:  cfg.RouterHTTPSPort(),
+		}, d, prov)
+	}
+	return traefik.New(traefik.Config{
+		HomeDir:    homeDir,
+		AppVersion: version.Version,
+		LogLevel:   logLevel,
+		HTTPPort:   cfg.RouterHTTPPort(),
+		HTTPSPort:  cfg.RouterHTTPSPort(),
+	}, d, prov, config)
+}
+
+// caInstaller returns the provider's one-click trust-store install, or
+// nil when the provider has none.
+func caInstaller(prov tlspkg.Provider) func(context.Context) error {
+	if inst, ok := prov.(tlspkg.CAInstaller); ok {
+		return inst.InstallCA
+	}
+	return nil
+}
+
+// refreshTLSNotice reads the current provider status and updates the
+// banner. When the local CA isn't trusted, the banner gets an action
+// button that runs the provider's CA install (for mkcert: download the
+// binary if needed, then `mkcert -install`) in a goroutine, then re-reads
+// the status. Re-entrant: callers may invoke after every successful or
+// failed install attempt.
+func refreshTLSNotice(prov tlspkg.Provider, state *ui.UIState) {
+	status, err := prov.Available(context.Background())
 	if err != nil || status.CATrusted {
 		state.SetNotice("")
 		return
 	}
+	install := caInstaller(prov)
+	if install == nil {
+		state.SetNotice(status.Message)
+		return
+	}
 	state.SetNoticeWithAction(status.Message, "Set up trusted HTTPS", func() {
 		go func() {
 			defer state.SetNoticeBusy(false)
-			if err := mkcert.InstallCA(context.Background()); err != nil {
-				slog.Warn("mkcert install failed", "err", err.Error())
+			if err := install(context.Background()); err != nil {
+				slog.Warn("TLS CA install failed", "err", err.Error())
 				state.ShowError("Could not set up trusted HTTPS: " + err.Error())
 			}
-			refreshTLSNotice(mkcert, state)
+			refreshTLSNotice(prov, state)
 		}()
 	})
 }
@@ -458,7 +544,7 @@ func refreshTLSNotice(mkcert *tlspkg.Mkcert, state *ui.UIState) {
 // result into UIState for the top status bar. Runs forever; stopped by
 // process exit.
 func pollServicesHealth(d *docker.Docker, state *ui.UIState) {
-	requiredRoles := []string{docker.RoleRouter, docker.RoleMail, docker.RoleAdminer}
+	requiredRoles := []string{docker.RoleRouter, docker.RoleMail, docker.RoleDBUI}
 	tick := time.NewTicker(5 * time.Second)
 	defer tick.Stop()
 	for {